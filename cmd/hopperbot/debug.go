@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// pprofHandler returns an HTTP handler for the /debug/pprof/ endpoints, in the
+// formats `go tool pprof` and `go tool trace` read:
+//
+//	GET /debug/pprof/                   lists the profiles
+//	GET /debug/pprof/profile?seconds=N  CPU profile (default 10s)
+//	GET /debug/pprof/trace?seconds=N    execution trace (default 10s)
+//	GET /debug/pprof/<name>             heap, allocs, goroutine, block, mutex or threadcreate
+//	                                    (?debug=1 or 2 for text, ?gc=1 to collect garbage before a heap profile)
+//
+// net/http/pprof isn't used: importing it registers unauthenticated handlers
+// on http.DefaultServeMux as a side effect, which anything serving that mux
+// would expose.
+func pprofHandler(logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := strings.TrimPrefix(r.URL.Path, constants.RouteDebugPprof)
+		switch name {
+		case "":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			for _, profile := range pprof.Profiles() {
+				fmt.Fprintf(w, "%s %d\n", profile.Name(), profile.Count())
+			}
+			fmt.Fprintln(w, "profile (CPU, ?seconds=N)")
+			fmt.Fprintln(w, "trace (?seconds=N)")
+
+		case "profile", "trace":
+			duration, err := profileDuration(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			start, stop := pprof.StartCPUProfile, pprof.StopCPUProfile
+			if name == "trace" {
+				start, stop = trace.Start, trace.Stop
+			}
+
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+			if err := start(w); err != nil {
+				// Only one CPU profile or trace can run at a time
+				http.Error(w, fmt.Sprintf("Could not start %s: %v", name, err), http.StatusConflict)
+				return
+			}
+			logger.Info("recording debug profile", zap.String("profile", name), zap.Duration("duration", duration))
+			sleep(r.Context(), duration)
+			stop()
+
+		default:
+			profile := pprof.Lookup(name)
+			if profile == nil {
+				http.Error(w, "Unknown profile", http.StatusNotFound)
+				return
+			}
+			debug, _ := strconv.Atoi(r.URL.Query().Get("debug"))
+			if gc, _ := strconv.ParseBool(r.URL.Query().Get("gc")); gc && name == "heap" {
+				runtime.GC()
+			}
+
+			if debug != 0 {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			} else {
+				w.Header().Set("Content-Type", "application/octet-stream")
+				w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+			}
+			profile.WriteTo(w, debug)
+		}
+	}
+}
+
+// profileDuration reads ?seconds=N for CPU profiles and traces. The recording
+// must finish before the server's write timeout cuts the response off.
+func profileDuration(r *http.Request) (time.Duration, error) {
+	value := r.URL.Query().Get("seconds")
+	if value == "" {
+		return constants.DebugProfileDuration, nil
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("seconds must be a positive integer")
+	}
+	duration := time.Duration(seconds) * time.Second
+	if duration >= constants.ServerWriteTimeout {
+		return 0, fmt.Errorf("seconds must be less than the server write timeout (%s)", constants.ServerWriteTimeout)
+	}
+	return duration, nil
+}
+
+// sleep waits for d or until ctx is done (the client went away).
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// runtimeStats is the GET /debug/runtime response.
+type runtimeStats struct {
+	Goroutines int            `json:"goroutines"`
+	GOMAXPROCS int            `json:"gomaxprocs"`
+	Memory     memoryStats    `json:"memory"`
+	GC         gcStats        `json:"gc"`
+	Caches     map[string]int `json:"caches"` // Entries per in-memory cache
+}
+
+type memoryStats struct {
+	HeapAllocBytes  uint64 `json:"heap_alloc_bytes"`  // Live and not yet collected heap objects
+	HeapInuseBytes  uint64 `json:"heap_inuse_bytes"`  // Heap spans in use
+	HeapIdleBytes   uint64 `json:"heap_idle_bytes"`   // Heap spans free, but not returned to the OS
+	HeapObjects     uint64 `json:"heap_objects"`      // Allocated heap objects
+	StackInuseBytes uint64 `json:"stack_inuse_bytes"` // Goroutine stacks
+	SysBytes        uint64 `json:"sys_bytes"`         // Total obtained from the OS
+	TotalAllocBytes uint64 `json:"total_alloc_bytes"` // Cumulative, including freed objects
+}
+
+type gcStats struct {
+	NumGC        uint32        `json:"num_gc"`
+	LastGC       time.Time     `json:"last_gc"`
+	LastPause    time.Duration `json:"last_pause_ns"`
+	PauseTotal   time.Duration `json:"pause_total_ns"`
+	NextGCBytes  uint64        `json:"next_gc_bytes"` // Heap size that triggers the next collection
+	CPUFraction  float64       `json:"cpu_fraction"`  // Share of CPU time spent in GC since start
+	ForcedCycles uint32        `json:"forced_cycles"`
+}
+
+// runtimeHandler returns an HTTP handler for the /debug/runtime endpoint.
+//
+// GET returns goroutine, heap and GC statistics with the size of each
+// in-memory cache, to tell cache growth apart from leaks elsewhere (compare
+// heap_alloc_bytes to the cache sizes over time; a heap profile from
+// /debug/pprof/heap shows where the rest goes).
+func runtimeHandler(cacheSizes func() map[string]int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		stats := runtimeStats{
+			Goroutines: runtime.NumGoroutine(),
+			GOMAXPROCS: runtime.GOMAXPROCS(0),
+			Memory: memoryStats{
+				HeapAllocBytes:  mem.HeapAlloc,
+				HeapInuseBytes:  mem.HeapInuse,
+				HeapIdleBytes:   mem.HeapIdle,
+				HeapObjects:     mem.HeapObjects,
+				StackInuseBytes: mem.StackInuse,
+				SysBytes:        mem.Sys,
+				TotalAllocBytes: mem.TotalAlloc,
+			},
+			GC: gcStats{
+				NumGC:        mem.NumGC,
+				PauseTotal:   time.Duration(mem.PauseTotalNs),
+				NextGCBytes:  mem.NextGC,
+				CPUFraction:  mem.GCCPUFraction,
+				ForcedCycles: mem.NumForcedGC,
+			},
+			Caches: cacheSizes(),
+		}
+		if mem.NumGC > 0 {
+			stats.GC.LastGC = time.Unix(0, int64(mem.LastGC)).UTC()
+			stats.GC.LastPause = time.Duration(mem.PauseNs[(mem.NumGC+255)%256])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stats)
+	}
+}
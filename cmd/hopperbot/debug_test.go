@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+func TestProfileDuration(t *testing.T) {
+	limit := int(constants.ServerWriteTimeout / time.Second)
+
+	tests := []struct {
+		name    string
+		seconds string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"default", "", constants.DebugProfileDuration, false},
+		{"one second", "1", time.Second, false},
+		{"just under the write timeout", strconv.Itoa(limit - 1), time.Duration(limit-1) * time.Second, false},
+		{"write timeout", strconv.Itoa(limit), 0, true},
+		{"over the write timeout", strconv.Itoa(limit + 60), 0, true},
+		{"zero", "0", 0, true},
+		{"negative", "-5", 0, true},
+		{"not a number", "ten", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, constants.RouteDebugPprof+"profile?seconds="+tt.seconds, nil)
+			got, err := profileDuration(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("profileDuration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("profileDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPprofHandler(t *testing.T) {
+	handler := pprofHandler(zap.NewNop())
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantType   string
+		wantBody   string
+	}{
+		{"index", http.MethodGet, "", http.StatusOK, "text/plain; charset=utf-8", "goroutine "},
+		{"heap as text", http.MethodGet, "heap?debug=1", http.StatusOK, "text/plain; charset=utf-8", "heap profile"},
+		{"goroutine binary", http.MethodGet, "goroutine", http.StatusOK, "application/octet-stream", ""},
+		{"unknown profile", http.MethodGet, "nonsense", http.StatusNotFound, "", "Unknown profile"},
+		{"non-GET", http.MethodPost, "heap", http.StatusMethodNotAllowed, "", ""},
+		{"CPU profile at the write timeout", http.MethodGet, "profile?seconds=" + strconv.Itoa(int(constants.ServerWriteTimeout/time.Second)), http.StatusBadRequest, "", "write timeout"},
+		{"trace with zero seconds", http.MethodGet, "trace?seconds=0", http.StatusBadRequest, "", "positive integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(tt.method, constants.RouteDebugPprof+tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantType != "" && rec.Header().Get("Content-Type") != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), tt.wantType)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body doesn't contain %q:\n%s", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRuntimeHandler(t *testing.T) {
+	handler := runtimeHandler(func() map[string]int {
+		return map[string]int{"customers": 3, "drafts": 0}
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, constants.RouteDebugRuntime, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q isn't JSON: %v", rec.Body.String(), err)
+	}
+	for _, key := range []string{"goroutines", "gomaxprocs", "memory", "gc", "caches"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("response has no %q field: %s", key, rec.Body.String())
+		}
+	}
+
+	var stats runtimeStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("body doesn't decode as runtimeStats: %v", err)
+	}
+	if stats.Goroutines < 1 || stats.GOMAXPROCS < 1 {
+		t.Errorf("goroutines = %d, gomaxprocs = %d, want both positive", stats.Goroutines, stats.GOMAXPROCS)
+	}
+	if stats.Memory.HeapAllocBytes == 0 || stats.Memory.SysBytes == 0 {
+		t.Errorf("memory = %+v, want the heap and system sizes filled in", stats.Memory)
+	}
+	if stats.Caches["customers"] != 3 || len(stats.Caches) != 2 {
+		t.Errorf("caches = %v, want the cache sizes passed through", stats.Caches)
+	}
+
+	var memory map[string]json.RawMessage
+	json.Unmarshal(body["memory"], &memory)
+	for _, key := range []string{"heap_alloc_bytes", "heap_inuse_bytes", "heap_idle_bytes", "heap_objects", "stack_inuse_bytes", "sys_bytes", "total_alloc_bytes"} {
+		if _, ok := memory[key]; !ok {
+			t.Errorf("memory has no %q field", key)
+		}
+	}
+	var gc map[string]json.RawMessage
+	json.Unmarshal(body["gc"], &gc)
+	for _, key := range []string{"num_gc", "last_gc", "last_pause_ns", "pause_total_ns", "next_gc_bytes", "cpu_fraction", "forced_cycles"} {
+		if _, ok := gc[key]; !ok {
+			t.Errorf("gc has no %q field", key)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, constants.RouteDebugRuntime, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", rec.Code)
+	}
+}
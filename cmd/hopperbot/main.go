@@ -3,20 +3,48 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rudderlabs/hopperbot/internal/linear"
+	"github.com/rudderlabs/hopperbot/internal/notion"
 	"github.com/rudderlabs/hopperbot/internal/slack"
+	"github.com/rudderlabs/hopperbot/pkg/analytics"
 	"github.com/rudderlabs/hopperbot/pkg/cache"
 	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/configwatch"
 	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/credentials"
+	"github.com/rudderlabs/hopperbot/pkg/drafts"
+	"github.com/rudderlabs/hopperbot/pkg/fanout"
+	"github.com/rudderlabs/hopperbot/pkg/featureflags"
+	"github.com/rudderlabs/hopperbot/pkg/funnel"
 	"github.com/rudderlabs/hopperbot/pkg/health"
+	"github.com/rudderlabs/hopperbot/pkg/installations"
+	"github.com/rudderlabs/hopperbot/pkg/lifecycle"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
 	"github.com/rudderlabs/hopperbot/pkg/metrics"
-	"github.com/rudderlabs/hopperbot/pkg/middleware"
+	"github.com/rudderlabs/hopperbot/pkg/pgstore"
+	"github.com/rudderlabs/hopperbot/pkg/queue"
+	"github.com/rudderlabs/hopperbot/pkg/redisstore"
+	"github.com/rudderlabs/hopperbot/pkg/reminders"
+	"github.com/rudderlabs/hopperbot/pkg/scheduler"
+	"github.com/rudderlabs/hopperbot/pkg/server"
+	"github.com/rudderlabs/hopperbot/pkg/statuswatch"
+	"github.com/rudderlabs/hopperbot/pkg/store"
+	"github.com/rudderlabs/hopperbot/pkg/tenants"
+	"github.com/rudderlabs/hopperbot/pkg/votes"
+	"github.com/rudderlabs/hopperbot/pkg/workers"
 	"go.uber.org/zap"
 )
 
@@ -37,6 +65,11 @@ type VersionInfo struct {
 }
 
 func main() {
+	// Subcommands that don't start the server
+	if len(os.Args) > 1 && os.Args[1] == "manifest" {
+		os.Exit(runManifestCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	// Create production logger
 	logger, err := zap.NewProduction()
 	if err != nil {
@@ -54,23 +87,513 @@ func main() {
 	m := metrics.Init()
 	logger.Info("metrics initialized")
 
-	// Initialize Slack handler
-	handler := slack.NewHandler(cfg, logger)
+	// Initialize Slack handler, filing ideas in Notion or, with BACKEND=linear, Linear
+	var deps slack.Dependencies
+	var linearClient *linear.Client
+	if cfg.Backend == constants.BackendLinear {
+		linearClient = linear.NewClient(linear.Config{
+			APIKey:                cfg.LinearAPIKey,
+			Team:                  cfg.LinearTeam,
+			ProductAreaTeams:      cfg.LinearProductAreaTeams,
+			ThemeLabelGroup:       cfg.LinearThemeLabelGroup,
+			ProductAreaLabelGroup: cfg.LinearProductAreaLabelGroup,
+		}, logger)
+		deps.Backend = linearClient
+		logger.Info("Linear backend enabled", zap.String("team", cfg.LinearTeam))
+	}
+	handler := slack.NewHandlerWithDependencies(cfg, logger, deps)
 	handler.SetMetrics(m)
+	notionClient := handler.NotionClient() // nil with BACKEND=linear
 
-	logger.Info("initializing bot and fetching client list from Notion")
+	// Load user-facing message catalog (built-in defaults plus optional overrides)
+	catalog, err := messages.LoadCatalog(cfg.MessagesFile)
+	if err != nil {
+		logger.Fatal("failed to load message catalog", zap.Error(err))
+	}
+	handler.SetMessageCatalog(catalog)
+	handler.SetFormRules(slack.CustomerOrgRequiredRule(cfg.CustomerOrgRequiredThemes))
+
+	if len(cfg.SlackAdminUserIDs) == 0 && cfg.SlackAdminUsergroup == "" {
+		logger.Warn("admin subcommands such as /hopperbot refresh-cache are open to every Slack user; set SLACK_ADMIN_USER_IDS or SLACK_ADMIN_USERGROUP to restrict them")
+	}
+
+	// Runtime feature toggles, switched through /admin/flags (not persisted)
+	// and starting with DISABLED_FEATURE_FLAGS off
+	flags := featureflags.New()
+	if err := flags.Set(flagChanges(nil, cfg.DisabledFeatureFlags)); err != nil {
+		logger.Fatal("failed to disable feature flags", zap.Error(err))
+	}
+	handler.SetFeatureFlags(flags)
+	var snapshotClients []*notion.Client
+	var notionClients []*notion.Client     // Default and tenant clients, for config reloads
+	var defaultKeyClients []*notion.Client // Clients using NOTION_API_KEY, for key rotation
+	if notionClient != nil {
+		notionClient.SetAPIVersion(cfg.NotionAPIVersion)
+		notionClient.SetAllowedEmailDomains(cfg.AllowedEmailDomains)
+		notionClient.SetSourceURLProperty(cfg.NotionSourceURLProperty)
+		notionClient.SetCustomerProperties(customerProperties(cfg))
+		notionClient.SetSnapshotFile(cfg.CacheSnapshotFile)
+		snapshotClients = append(snapshotClients, notionClient)
+		notionClients = append(notionClients, notionClient)
+		defaultKeyClients = append(defaultKeyClients, notionClient)
+	}
+
+	// Share the customer and user caches between replicas (optional, CACHE_BACKEND=redis)
+	var redisClient *redisstore.Client
+	if cfg.CacheBackend == constants.CacheBackendRedis {
+		redisClient, err = redisstore.New(cfg.RedisURL, cfg.RedisKeyPrefix)
+		if err != nil {
+			logger.Fatal("failed to configure Redis cache backend", zap.Error(err))
+		}
+		pingCtx, cancel := context.WithTimeout(context.Background(), constants.DefaultHTTPTimeout)
+		if err := redisClient.Ping(pingCtx); err != nil {
+			// Not fatal: caches fall back to Notion until Redis is reachable
+			logger.Warn("Redis is unreachable, fetching caches from Notion until it recovers", zap.Error(err))
+		}
+		cancel()
+		notionClient.SetSharedCache(redisClient, cfg.CacheRefreshInterval)
+		logger.Info("Redis cache backend enabled", zap.String("key_prefix", cfg.RedisKeyPrefix))
+	}
+
+	// Route tenant workspaces to their own Notion databases (optional, TENANTS_FILE)
+	if cfg.TenantsFile != "" {
+		tenantList, err := tenants.LoadFile(cfg.TenantsFile)
+		if err != nil {
+			logger.Fatal("failed to load tenants", zap.Error(err))
+		}
+		backends := make(map[string]slack.SubmissionBackend, len(tenantList))
+		for _, tenant := range tenantList {
+			apiKey := tenant.NotionAPIKey
+			if apiKey == "" {
+				apiKey = cfg.NotionAPIKey
+			}
+			tenantLogger := logger.With(zap.String("team_id", tenant.TeamID), zap.String("tenant", tenant.Name))
+			client := notion.NewClient(apiKey, tenant.NotionDatabaseID, tenant.NotionClientsDBID, tenantLogger)
+			if tenant.NotionAPIKey == "" {
+				defaultKeyClients = append(defaultKeyClients, client)
+			}
+			if redisClient != nil {
+				client.SetSharedCache(redisClient, cfg.CacheRefreshInterval)
+			}
+			if cfg.CacheSnapshotFile != "" {
+				client.SetSnapshotFile(tenantSnapshotFile(cfg.CacheSnapshotFile, tenant.TeamID))
+				snapshotClients = append(snapshotClients, client)
+			}
+			client.SetAPIVersion(cfg.NotionAPIVersion)
+			client.SetAllowedEmailDomains(cfg.AllowedEmailDomains)
+			client.SetSourceURLProperty(cfg.NotionSourceURLProperty)
+			client.SetCustomerProperties(customerProperties(cfg))
+			backends[tenant.TeamID] = client
+			notionClients = append(notionClients, client)
+		}
+		handler.SetTenantBackends(backends)
+		logger.Info("tenant workspaces configured", zap.Int("tenants", len(backends)))
+	}
+
+	logger.Info("initializing bot and fetching client list", zap.String("backend", cfg.Backend))
+	restoredFromSnapshot, lazyStartup := false, false
 	if err := handler.Initialize(); err != nil {
-		logger.Fatal("failed to initialize handler", zap.Error(err))
+		if notionClient != nil {
+			// Missing share permissions are the most common cause; log exactly what to fix
+			notion.LogPermissionReport(logger, notionClient.CheckPermissions())
+			notion.LogCompatibilityReport(logger, notionClient.CheckCompatibility())
+		}
+		switch {
+		case cfg.CacheSnapshotFile != "" && restoreCacheSnapshots(snapshotClients, logger):
+			// Serve the saved caches; the cache manager refreshes them once it starts
+			logger.Warn("failed to initialize from Notion, starting from saved cache snapshots", zap.Error(err))
+			restoredFromSnapshot = true
+		case cfg.LazyStartup:
+			// Serve with empty caches (not ready) while the cache manager retries
+			logger.Warn("failed to initialize from Notion, starting degraded and retrying in the background", zap.Error(err))
+			lazyStartup = true
+		default:
+			logger.Fatal("failed to initialize handler", zap.Error(err))
+		}
+	} else {
+		logger.Info("bot initialization complete")
+	}
+
+	// Detect which Notion API features this workspace supports, to gate dependent
+	// features (Linear supports them all: every issue has a workflow state)
+	compatibility := &notion.CompatibilityReport{}
+	if notionClient != nil {
+		compatibility = notionClient.CheckCompatibility()
+		notion.LogCompatibilityReport(logger, compatibility)
+	}
+
+	// Background components are started in dependency order once everything is
+	// wired, and stopped in reverse on shutdown
+	components := lifecycle.NewManager(logger, constants.ComponentStopTimeout)
+
+	// Cache refreshes read and publish the shared cache, so Redis outlives them
+	var cacheDeps []string
+	if redisClient != nil {
+		components.Add(lifecycle.Component{
+			Name: "redis",
+			Stop: func(context.Context) error { return redisClient.Close() },
+		})
+		cacheDeps = append(cacheDeps, "redis")
 	}
-	logger.Info("bot initialization complete")
+
+	// Scheduled background jobs (periodic cache refresh, status change polling,
+	// submissions digest); started once every job is registered below
+	jobs := scheduler.New(m, logger)
 
 	// Initialize cache manager for periodic and manual cache refresh
 	cacheMgr := cache.NewManager(handler, m, logger, cfg.CacheRefreshInterval)
+	if lazyStartup {
+		cacheMgr.SetInitializer(handler.Initialize)
+	}
+	// Slack users' emails, reloaded with users.list for workspaces with cached users
+	cacheMgr.Register(cache.CacheTypeSlackUsers, handler.RefreshSlackUsers)
+	cacheMgr.Schedule(jobs)
 	handler.SetCacheManager(cacheMgr)
-	cacheMgr.Start()
-	logger.Info("cache manager started",
-		zap.Duration("refresh_interval", cfg.CacheRefreshInterval),
-	)
+	components.Add(lifecycle.Component{
+		Name:      "cache",
+		DependsOn: cacheDeps,
+		Start:     lifecycle.StartFunc(cacheMgr.Start),
+		Stop:      lifecycle.StopFunc(cacheMgr.Stop),
+	})
+
+	// Verify Notion integration permissions now and periodically
+	if notionClient != nil {
+		permissionMonitor := notion.NewPermissionMonitor(notionClient, cfg.PermissionCheckInterval, logger)
+		permissionMonitor.Check()
+		components.Add(lifecycle.Component{
+			Name:  "permissions",
+			Start: lifecycle.StartFunc(permissionMonitor.Start),
+			Stop:  lifecycle.StopFunc(permissionMonitor.Stop),
+		})
+	}
+
+	// Bounded pool for work outliving requests (quick submissions, confirmations,
+	// App Home views) and status polls; drained on shutdown once nothing else
+	// can submit to it
+	background := workers.New("background", cfg.BackgroundWorkers, constants.BackgroundQueueSize, m, logger)
+	handler.SetWorkerPool(background)
+	components.Add(lifecycle.Component{
+		Name:      "workers",
+		DependsOn: []string{"cache"},
+		Stop:      lifecycle.StopFunc(background.Stop),
+	})
+
+	// Components in-flight requests use, so the HTTP server stops before them, and
+	// components queued submissions use, so the queue stops before them
+	serverDeps := []string{"cache", "workers"}
+	queueDeps := []string{"workers"}
+
+	// Enable the OAuth install flow (optional, tokens persisted to SLACK_INSTALLATIONS_FILE when set)
+	if cfg.OAuthEnabled() {
+		installationStore, err := installations.NewFileStore(cfg.SlackInstallationsFile)
+		if err != nil {
+			logger.Fatal("failed to load Slack installations", zap.Error(err))
+		}
+		handler.SetInstallationStore(installationStore)
+		logger.Info("Slack OAuth install flow enabled",
+			zap.Int("installations", installationStore.Len()),
+			zap.Bool("persistent", cfg.SlackInstallationsFile != ""),
+		)
+	}
+
+	// Initialize shared drafts (persisted to DRAFTS_FILE when set)
+	draftStore, err := drafts.NewFileStore(cfg.DraftsFile)
+	if err != nil {
+		logger.Fatal("failed to load shared drafts", zap.Error(err))
+	}
+	handler.SetDraftStore(draftStore)
+	handler.SetMultiStepModal(cfg.MultiStepModal)
+	handler.SetAttachmentsEnabled(cfg.AttachmentsEnabled)
+	handler.SetCommentBlocks(cfg.CommentBlocks)
+
+	// Initialize reaction voting (persisted to VOTES_FILE when set)
+	if cfg.VotingEnabled {
+		voteStore, err := votes.NewFileStore(cfg.VotesFile)
+		if err != nil {
+			logger.Fatal("failed to load votes", zap.Error(err))
+		}
+		handler.SetVoting(voteStore, cfg.VoteReactions)
+	}
+
+	// Initialize follow-up reminders (persisted to REMINDERS_FILE when set)
+	reminderStore, err := reminders.NewFileStore(cfg.RemindersFile)
+	if err != nil {
+		logger.Fatal("failed to load reminders", zap.Error(err))
+	}
+	reminderScheduler := reminders.NewScheduler(reminderStore, handler.SendReminder, m, logger, constants.DefaultReminderCheckInterval)
+	if compatibility.Unavailable(notion.FeatureStatusProperty) {
+		// Reminders report the idea's triage status, which this database doesn't have
+		logger.Warn("follow-up reminders disabled: ideas database has no usable status property",
+			zap.String("property", constants.FieldStatus),
+		)
+	} else {
+		handler.SetReminderScheduler(reminderScheduler)
+		components.Add(lifecycle.Component{
+			Name:  "reminders",
+			Start: lifecycle.StartFunc(reminderScheduler.Start),
+			Stop:  lifecycle.StopFunc(reminderScheduler.Stop),
+		})
+		serverDeps = append(serverDeps, "reminders")
+		queueDeps = append(queueDeps, "reminders")
+	}
+
+	// Initialize idea funnel tracking (optional, persisted to FUNNEL_FILE when set)
+	var funnelTracker *funnel.Tracker
+	if cfg.FunnelTrackingEnabled {
+		if compatibility.Unavailable(notion.FeatureStatusProperty) {
+			// Triage and decisions are read from the idea's status, which this database doesn't have
+			logger.Warn("funnel tracking disabled: ideas database has no usable status property",
+				zap.String("property", constants.FieldStatus),
+			)
+		} else {
+			funnelStore, err := funnel.NewFileStore(cfg.FunnelFile)
+			if err != nil {
+				logger.Fatal("failed to load funnel", zap.Error(err))
+			}
+			funnelTracker = funnel.NewTracker(funnelStore, handler.FunnelStatus, cfg.FunnelClosedStatuses, m, logger, constants.DefaultFunnelCheckInterval)
+			funnelTracker.SetStageFunc(handler.TrackFunnelStage)
+			handler.SetFunnelTracker(funnelTracker)
+			var funnelDeps []string
+			if cfg.AnalyticsEndpoint != "" {
+				funnelDeps = append(funnelDeps, "analytics") // Triage and decisions are exported as analytics events
+			}
+			components.Add(lifecycle.Component{
+				Name:      "funnel",
+				DependsOn: funnelDeps,
+				Start:     lifecycle.StartFunc(funnelTracker.Start),
+				Stop:      lifecycle.StopFunc(funnelTracker.Stop),
+			})
+			serverDeps = append(serverDeps, "funnel")
+			queueDeps = append(queueDeps, "funnel")
+		}
+	}
+
+	// Initialize status change notifications (optional, persisted to STATUS_NOTIFICATIONS_FILE when set)
+	if cfg.StatusNotificationsEnabled {
+		if compatibility.Unavailable(notion.FeatureStatusProperty) {
+			logger.Warn("status notifications disabled: ideas database has no usable status property",
+				zap.String("property", constants.FieldStatus),
+			)
+		} else {
+			watchStore, err := statuswatch.NewFileStore(cfg.StatusNotificationsFile)
+			if err != nil {
+				logger.Fatal("failed to load watched ideas", zap.Error(err))
+			}
+			statusWatcher := statuswatch.NewWatcher(watchStore, handler.WatchedStatus, handler.NotifyStatusChange, cfg.FunnelClosedStatuses, m, logger)
+			statusWatcher.SetPool(background)
+			handler.SetStatusWatcher(statusWatcher)
+			jobs.Add("status-watcher", scheduler.Every(constants.DefaultStatusWatchInterval), statusWatcher.RunCheck)
+		}
+	}
+
+	// One Postgres client serves the archive and the audit log (POSTGRES_URL),
+	// created when the first of them needs it
+	schedulerDeps := []string{"cache", "workers"}
+	var postgresClient *pgstore.Client
+	postgres := func() *pgstore.Client {
+		if postgresClient != nil {
+			return postgresClient
+		}
+		client, err := pgstore.New(cfg.PostgresURL)
+		if err != nil {
+			logger.Fatal("failed to configure Postgres", zap.Error(err))
+		}
+		pingCtx, cancel := context.WithTimeout(context.Background(), constants.DefaultHTTPTimeout)
+		if err := client.Ping(pingCtx); err != nil {
+			// Not fatal: archive writes are retried and audit log writes only logged until Postgres is reachable
+			logger.Warn("Postgres is unreachable, writes will fail until it recovers", zap.Error(err))
+		}
+		cancel()
+
+		// Submissions and retries write to it, so it closes after them
+		components.Add(lifecycle.Component{
+			Name: "postgres",
+			Stop: func(context.Context) error { return client.Close() },
+		})
+		serverDeps = append(serverDeps, "postgres")
+		queueDeps = append(queueDeps, "postgres")
+		schedulerDeps = append(schedulerDeps, "postgres")
+		postgresClient = client
+		return client
+	}
+
+	// Write submissions to secondary backends too (optional, FANOUT_BACKENDS;
+	// writes awaiting a retry persisted to FANOUT_RETRY_FILE when set)
+	if len(cfg.FanoutBackends) > 0 {
+		var targets []fanout.Target
+		for _, backend := range cfg.FanoutBackends {
+			switch backend {
+			case constants.FanoutPostgres:
+				targets = append(targets, fanout.NewPostgresArchive(postgres(), cfg.PostgresTable))
+			}
+		}
+
+		fanoutStore, err := fanout.NewFileStore(cfg.FanoutRetryFile)
+		if err != nil {
+			logger.Fatal("failed to load fan-out retries", zap.Error(err))
+		}
+		submissionFanout := fanout.New(targets, fanoutStore, m, logger)
+		handler.SetFanout(submissionFanout)
+		jobs.Add("fanout-retry", scheduler.Every(constants.FanoutRetryInterval), submissionFanout.RetryDue)
+		logger.Info("submission fan-out enabled",
+			zap.Strings("backends", submissionFanout.Targets()),
+			zap.String("postgres_table", cfg.PostgresTable),
+			zap.Bool("persistent", cfg.FanoutRetryFile != ""),
+		)
+	}
+
+	// Record submission attempts in the audit log (optional, AUDIT_LOG_BACKEND),
+	// pruning those past AUDIT_LOG_RETENTION_DAYS
+	if cfg.AuditLogBackend != "" {
+		var auditLog store.Store
+		switch cfg.AuditLogBackend {
+		case constants.AuditLogPostgres:
+			auditLog = store.NewPostgresStore(postgres(), cfg.AuditLogTable)
+		default:
+			fileStore, err := store.NewFileStore(cfg.AuditLogFile)
+			if err != nil {
+				logger.Fatal("failed to load audit log", zap.Error(err))
+			}
+			auditLog = fileStore
+		}
+		handler.SetAuditLog(auditLog)
+
+		retention := cfg.AuditLogRetention
+		if retention == 0 {
+			retention = constants.DefaultAuditLogRetention
+		}
+		jobs.Add("audit-log-prune", scheduler.Every(constants.AuditLogPruneInterval), func(context.Context) error {
+			pruned, err := auditLog.Prune(time.Now().Add(-retention))
+			if pruned > 0 {
+				logger.Info("pruned audit log", zap.Int("attempts", pruned))
+			}
+			return err
+		})
+		logger.Info("submission audit log enabled",
+			zap.String("backend", cfg.AuditLogBackend),
+			zap.Duration("retention", retention),
+		)
+	}
+
+	// Initialize the submissions digest posted to DIGEST_CHANNEL (optional)
+	if cfg.DigestChannel != "" {
+		digestSchedule, err := scheduler.ParseCron(cfg.DigestSchedule)
+		if err != nil {
+			logger.Fatal("invalid digest schedule", zap.Error(err))
+		}
+		jobs.Add("digest", digestSchedule, func(ctx context.Context) error {
+			return handler.PostDigest(ctx, cfg.DigestChannel)
+		})
+		logger.Info("submissions digest enabled",
+			zap.String("channel", cfg.DigestChannel),
+			zap.String("schedule", cfg.DigestSchedule),
+		)
+	}
+
+	// Scheduled jobs stop after the server and queue, and before the cache
+	// manager whose refreshes they run
+	components.Add(lifecycle.Component{
+		Name:      "scheduler",
+		DependsOn: schedulerDeps,
+		Start:     lifecycle.StartFunc(jobs.Start),
+		Stop:      lifecycle.StopFunc(jobs.Stop),
+	})
+	serverDeps = append(serverDeps, "scheduler")
+	queueDeps = append(queueDeps, "scheduler")
+
+	// Confirmations held while the confirmation channel is busy are posted on shutdown,
+	// once no more submissions can arrive (registered even with batching off, since a
+	// configuration reload can turn it on)
+	components.Add(lifecycle.Component{
+		Name:      "confirmations",
+		DependsOn: []string{"workers"},
+		Stop: func(ctx context.Context) error {
+			handler.FlushConfirmations(ctx)
+			return nil
+		},
+	})
+	serverDeps = append(serverDeps, "confirmations")
+	queueDeps = append(queueDeps, "confirmations")
+
+	// Reload tunable settings on SIGHUP and when CONFIG_FILE changes
+	configWatcher := configwatch.New(cfg, func(previous, cfg *config.Config) {
+		handler.ApplyConfig(cfg)
+		for _, client := range notionClients {
+			client.SetAllowedEmailDomains(cfg.AllowedEmailDomains)
+			client.SetSourceURLProperty(cfg.NotionSourceURLProperty)
+			client.SetCustomerProperties(customerProperties(cfg))
+		}
+		cacheMgr.SetRefreshInterval(cfg.CacheRefreshInterval)
+		if err := flags.Set(flagChanges(previous.DisabledFeatureFlags, cfg.DisabledFeatureFlags)); err != nil {
+			logger.Error("failed to apply DISABLED_FEATURE_FLAGS", zap.Error(err))
+		}
+	}, m, logger)
+	if cfg.ConfigFile != "" {
+		jobs.Add("config-watch", scheduler.Every(constants.ConfigFileCheckInterval), configWatcher.CheckFile)
+	}
+
+	// Rotate SLACK_BOT_TOKEN and NOTION_API_KEY without a restart, through
+	// /admin/credentials or by rewriting their secret files
+	rotator := credentials.New(m, logger)
+	if cfg.SlackBotToken != "" {
+		rotator.Register(credentials.SlackBotToken, cfg.SlackBotToken, cfg.SlackBotTokenFile, handler.RotateBotToken)
+	}
+	if notionClient != nil {
+		rotator.Register(credentials.NotionAPIKey, cfg.NotionAPIKey, cfg.NotionAPIKeyFile, func(ctx context.Context, apiKey string) error {
+			return notion.RotateAPIKey(ctx, apiKey, defaultKeyClients...)
+		})
+	}
+	if cfg.SlackBotTokenFile != "" || cfg.NotionAPIKeyFile != "" {
+		jobs.Add("credentials-watch", scheduler.Every(constants.SecretFileCheckInterval), rotator.CheckFiles)
+	}
+
+	components.Add(lifecycle.Component{
+		Name:  "config-watch",
+		Start: lifecycle.StartFunc(configWatcher.Start),
+		Stop:  lifecycle.StopFunc(configWatcher.Stop),
+	})
+
+	// Initialize analytics exporter (optional); pending events are flushed after
+	// the server and queue stop producing them
+	if cfg.AnalyticsEndpoint != "" {
+		analyticsExporter := analytics.NewExporter(analytics.Config{
+			Endpoint:      cfg.AnalyticsEndpoint,
+			WriteKey:      cfg.AnalyticsWriteKey,
+			BatchSize:     cfg.AnalyticsBatchSize,
+			FlushInterval: cfg.AnalyticsFlushInterval,
+		}, m, logger)
+		handler.SetAnalyticsExporter(analyticsExporter)
+		components.Add(lifecycle.Component{
+			Name:  "analytics",
+			Start: lifecycle.StartFunc(analyticsExporter.Start),
+			Stop:  lifecycle.StopFunc(analyticsExporter.Stop),
+		})
+		serverDeps = append(serverDeps, "analytics")
+		queueDeps = append(queueDeps, "analytics")
+		logger.Info("analytics exporter enabled",
+			zap.Int("batch_size", cfg.AnalyticsBatchSize),
+			zap.Duration("flush_interval", cfg.AnalyticsFlushInterval),
+		)
+	}
+
+	// Initialize the async submission queue (on unless SUBMISSION_QUEUE_ENABLED=false, persisted to SUBMISSION_QUEUE_FILE when set);
+	// it stops once no more submissions can arrive, and pending jobs stay persisted
+	if cfg.SubmissionQueueEnabled {
+		queueStore, err := queue.NewFileStore(cfg.SubmissionQueueFile)
+		if err != nil {
+			logger.Fatal("failed to load submission queue", zap.Error(err))
+		}
+		submissionQueue := queue.NewQueue(queueStore, handler.ProcessQueuedSubmission, handler.FailQueuedSubmission, m, logger, constants.DefaultSubmissionQueueCheckInterval)
+		submissionQueue.SetPool(background)
+		handler.SetSubmissionQueue(submissionQueue)
+		components.Add(lifecycle.Component{
+			Name:      "queue",
+			DependsOn: queueDeps,
+			Start:     lifecycle.StartFunc(submissionQueue.Start),
+			Stop:      lifecycle.StopFunc(submissionQueue.Stop),
+		})
+		serverDeps = append(serverDeps, "queue")
+	}
 
 	// Initialize health manager
 	healthMgr := health.NewManager(logger)
@@ -79,133 +602,173 @@ func main() {
 	healthMgr.RegisterLivenessCheck("server", health.AlwaysHealthyChecker())
 
 	// Register readiness checks (dependencies)
-	healthMgr.RegisterReadinessCheck("notion_api", health.NotionHealthChecker(func(ctx context.Context) error {
-		return handler.NotionClient().HealthCheck(ctx)
-	}))
+	if linearClient != nil {
+		healthMgr.RegisterReadinessCheck("linear_api", health.LinearHealthChecker(linearClient.HealthCheck))
+	} else {
+		// Slow but working Notion reports degraded without failing readiness
+		healthMgr.RegisterDegradableReadinessCheck("notion_api", health.NotionHealthChecker(notionClient.HealthCheck, constants.NotionHealthLatencyThreshold))
+	}
+
+	// Not ready until a lazy startup's background initialization has succeeded
+	healthMgr.RegisterReadinessCheck("startup", health.StartupChecker(cacheMgr.Initialized))
 
 	healthMgr.RegisterReadinessCheck("client_cache", health.ClientCacheChecker(
 		handler.GetClientCount,
 		10, // Expect at least 10 clients as a sanity check
 	))
 
+	if notionClient != nil {
+		healthMgr.RegisterReadinessCheck("notion_permissions", health.NotionPermissionsChecker(func() map[string]string {
+			report := notionClient.LastPermissionReport()
+			if report == nil {
+				return nil
+			}
+			return report.Statuses()
+		}))
+	}
+
 	logger.Info("health checks registered")
 
-	// Setup HTTP handlers with middleware
+	// HTTP routes, registered on the server's own muxes
+	srv, err := server.New(cfg, m, logger)
+	if err != nil {
+		logger.Fatal("failed to configure HTTP server", zap.Error(err))
+	}
+	srv.SetMessageCatalog(catalog)
+
 	// Prometheus metrics endpoint
-	http.Handle("/metrics", promhttp.Handler())
+	srv.Handle(constants.RouteMetrics, promhttp.Handler())
 
 	// Health check endpoints
-	http.HandleFunc("/health", healthMgr.LivenessHandler())
-	http.HandleFunc("/ready", healthMgr.ReadinessHandler())
+	srv.Handle(constants.RouteHealth, healthMgr.LivenessHandler())
+	srv.Handle(constants.RouteReady, healthMgr.ReadinessHandler())
 
 	// Version endpoint
-	http.HandleFunc("/version", versionHandler())
-
-	// Slack endpoints with full middleware stack
-	http.HandleFunc("/slack/command", middleware.Chain(
-		handler.HandleSlashCommand,
-		func(next http.HandlerFunc) http.HandlerFunc {
-			return middleware.WithLogging(logger, next)
-		},
-		func(next http.HandlerFunc) http.HandlerFunc {
-			return middleware.WithTimeout(30*time.Second, logger, m, next)
-		},
-		func(next http.HandlerFunc) http.HandlerFunc {
-			return middleware.WithMetrics("/slack/command", m, next)
-		},
-		func(next http.HandlerFunc) http.HandlerFunc {
-			return middleware.WithRecovery(logger, m, next)
-		},
-	))
+	srv.Handle(constants.RouteVersion, versionHandler())
 
-	http.HandleFunc("/slack/interactive", middleware.Chain(
-		handler.HandleInteractive,
-		func(next http.HandlerFunc) http.HandlerFunc {
-			return middleware.WithLogging(logger, next)
-		},
-		func(next http.HandlerFunc) http.HandlerFunc {
-			return middleware.WithTimeout(30*time.Second, logger, m, next)
-		},
-		func(next http.HandlerFunc) http.HandlerFunc {
-			return middleware.WithMetrics("/slack/interactive", m, next)
-		},
-		func(next http.HandlerFunc) http.HandlerFunc {
-			return middleware.WithRecovery(logger, m, next)
-		},
-	))
+	// Admin endpoints (only when ADMIN_TOKEN or admin mTLS is configured). With
+	// mTLS they are served on their own listener instead.
+	if cfg.AdminEnabled() {
+		if notionClient != nil {
+			srv.HandleAdmin(constants.RouteAdminPermissions, permissionsHandler(notionClient))
+			srv.HandleAdmin(constants.RouteAdminDatabases, databasesHandler(notionClient, logger))
+			srv.HandleAdmin(constants.RouteAdminCache, cacheEntryHandler(notionClient, logger))
+		}
+		srv.HandleAdmin(constants.RouteAdminCacheRefresh, cacheRefreshHandler(cacheMgr, logger))
+		srv.HandleAdmin(constants.RouteAdminConfig, configHandler(configWatcher.Config))
+		srv.HandleAdmin(constants.RouteAdminFlags, flagsHandler(flags, logger))
+		srv.HandleAdmin(constants.RouteAdminCredentials, credentialsHandler(rotator))
+		if funnelTracker != nil {
+			srv.HandleAdmin(constants.RouteAdminFunnel, funnelHandler(funnelTracker, logger))
+		}
+		srv.HandleAdmin(constants.RouteDebugPprof, pprofHandler(logger))
+		srv.HandleAdmin(constants.RouteDebugRuntime, runtimeHandler(handler.CacheSizes))
+	} else {
+		logger.Info("admin endpoints disabled (neither ADMIN_TOKEN nor ADMIN_PORT set)")
+	}
 
-	http.HandleFunc("/slack/options", middleware.Chain(
-		handler.HandleOptionsRequest,
-		func(next http.HandlerFunc) http.HandlerFunc {
-			return middleware.WithLogging(logger, next)
-		},
-		func(next http.HandlerFunc) http.HandlerFunc {
-			return middleware.WithTimeout(30*time.Second, logger, m, next)
-		},
-		func(next http.HandlerFunc) http.HandlerFunc {
-			return middleware.WithMetrics("/slack/options", m, next)
-		},
-		func(next http.HandlerFunc) http.HandlerFunc {
-			return middleware.WithRecovery(logger, m, next)
-		},
-	))
+	// Slack endpoints with full middleware stack; per-user rate limiting only on
+	// the ones users trigger (Events API callbacks come from Slack, not users)
+	srv.HandleSlack(constants.RouteSlackCommand, handler.HandleSlashCommand, server.RateLimited())
+	srv.HandleSlack(constants.RouteSlackInteractive, handler.HandleInteractive, server.RateLimited())
+	srv.HandleSlack(constants.RouteSlackOptions, handler.HandleOptionsRequest, server.RateLimited(), server.Compressed())
+	srv.HandleSlack(constants.RouteSlackEvents, handler.HandleEvents)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = constants.DefaultPort
-	}
+	// OAuth install endpoints are opened in a browser, so they carry no Slack signature
+	srv.HandleBrowser(constants.RouteSlackOAuthInstall, handler.HandleOAuthInstall)
+	srv.HandleBrowser(constants.RouteSlackOAuthCallback, handler.HandleOAuthCallback)
 
-	// Configure server with explicit timeouts
-	server := &http.Server{
-		Addr:         ":" + port,
-		Handler:      nil, // uses DefaultServeMux
-		ReadTimeout:  constants.ServerReadTimeout,
-		WriteTimeout: constants.ServerWriteTimeout,
-		IdleTimeout:  constants.ServerIdleTimeout,
+	// The web intake form is public, so its submissions pass the CAPTCHA_* check (when configured)
+	if cfg.WebFormEnabled {
+		srv.HandleForm(constants.RouteWebSubmit, handler.HandleWebForm)
+		logger.Info("web intake form enabled",
+			zap.String("route", cfg.BasePath+constants.RouteWebSubmit),
+			zap.String("captcha_provider", cfg.CaptchaProvider),
+		)
 	}
 
 	// Setup graceful shutdown handling
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 
-	// Run server in a goroutine
-	go func() {
-		logger.Info("starting Hopperbot server",
-			zap.String("version", version),
-			zap.String("commit", commit),
-			zap.String("build_time", buildTime),
-			zap.String("port", port),
-			zap.String("metrics_endpoint", "/metrics"),
-			zap.String("health_endpoint", "/health"),
-			zap.String("readiness_endpoint", "/ready"),
-			zap.String("version_endpoint", "/version"),
-			zap.String("options_endpoint", "/slack/options"),
-		)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("server failed to start", zap.Error(err))
-		}
-	}()
+	// The server starts after, and stops before, everything it uses
+	components.Add(lifecycle.Component{
+		Name:      "server",
+		DependsOn: serverDeps,
+		Start: func() error {
+			logger.Info("starting Hopperbot server",
+				zap.String("version", version),
+				zap.String("commit", commit),
+				zap.String("build_time", buildTime),
+				zap.String("port", cfg.Port),
+				zap.String("base_path", cfg.BasePath),
+				zap.String("metrics_endpoint", constants.RouteMetrics),
+				zap.String("health_endpoint", constants.RouteHealth),
+				zap.String("readiness_endpoint", constants.RouteReady),
+				zap.String("version_endpoint", constants.RouteVersion),
+				zap.String("options_endpoint", constants.RouteSlackOptions),
+			)
+			return srv.Start()
+		},
+		// Allows in-flight requests to complete before forcing shutdown
+		Stop:        srv.Shutdown,
+		StopTimeout: constants.GracefulShutdownTimeout,
+	})
+
+	if err := components.Start(); err != nil {
+		logger.Fatal("failed to start components", zap.Error(err))
+	}
+	if restoredFromSnapshot {
+		// Revalidate the restored caches now rather than at the next interval
+		cacheMgr.ManualRefresh()
+	}
 
 	// Block until shutdown signal
 	<-stop
 	logger.Info("shutdown signal received, initiating graceful shutdown")
 
-	// Stop cache manager
-	cacheMgr.Stop()
-	logger.Info("cache manager stopped")
-
-	// Create context with timeout for graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), constants.GracefulShutdownTimeout)
-	defer cancel()
-
-	// Attempt graceful shutdown
-	if err := server.Shutdown(ctx); err != nil {
+	// Stop the server first so in-flight requests drain, then its dependencies
+	if err := components.Stop(); err != nil {
 		logger.Error("error during graceful shutdown", zap.Error(err))
 	} else {
-		logger.Info("server shutdown complete")
+		logger.Info("shutdown complete")
 	}
 }
 
+// restoreCacheSnapshots loads the saved caches of every client and reports
+// whether all of them were restored.
+func restoreCacheSnapshots(clients []*notion.Client, logger *zap.Logger) bool {
+	for _, client := range clients {
+		savedAt, err := client.RestoreSnapshotFile()
+		if err != nil {
+			logger.Error("failed to restore cache snapshot", zap.Error(err))
+			return false
+		}
+		logger.Info("restored cache snapshot", zap.Duration("age", time.Since(savedAt).Round(time.Second)))
+	}
+	return true
+}
+
+// customerProperties returns the Customers database properties configured
+// for customer selects.
+func customerProperties(cfg *config.Config) notion.CustomerProperties {
+	return notion.CustomerProperties{
+		Name:     cfg.NotionCustomerNameProperty,
+		Aliases:  cfg.NotionCustomerAliasesProperty,
+		Details:  cfg.NotionCustomerDetailProperties,
+		Status:   cfg.NotionCustomerStatusProperty,
+		Statuses: cfg.NotionCustomerStatuses,
+		Filter:   json.RawMessage(cfg.NotionCustomersFilter),
+	}
+}
+
+// tenantSnapshotFile returns a tenant's cache snapshot file next to the
+// default workspace's, e.g. /var/lib/hopperbot/cache.T0123.json.
+func tenantSnapshotFile(path, teamID string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + teamID + ext
+}
+
 // versionHandler returns an HTTP handler for the /version endpoint.
 // Returns build information including version, commit hash, and build time.
 func versionHandler() http.HandlerFunc {
@@ -227,3 +790,359 @@ func versionHandler() http.HandlerFunc {
 		json.NewEncoder(w).Encode(info)
 	}
 }
+
+// permissionsHandler returns an HTTP handler for the /admin/permissions endpoint.
+// Returns the latest Notion permission report; ?refresh=true re-runs the probes first.
+func permissionsHandler(client *notion.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		report := client.LastPermissionReport()
+		if report == nil || r.URL.Query().Get("refresh") == "true" {
+			report = client.CheckPermissions()
+		}
+
+		statusCode := http.StatusOK
+		if len(report.Missing()) > 0 {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// switchDatabasesRequest is the body of POST /admin/databases.
+// Empty fields keep the current database.
+type switchDatabasesRequest struct {
+	DatabaseID          string `json:"database_id"`
+	CustomersDatabaseID string `json:"customers_database_id"`
+}
+
+// databasesHandler returns an HTTP handler for the /admin/databases endpoint.
+//
+// GET lists the data sources shared with the integration, marking the ones in use.
+// POST switches the ideas and/or Customers database at runtime after validating
+// access and schema, then re-checks permissions. The switch is not persisted;
+// update NOTION_DATABASE_ID / NOTION_CLIENTS_DB_ID to keep it across restarts.
+func databasesHandler(client *notion.Client, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			databases, err := client.ListAccessibleDatabases()
+			if err != nil {
+				logger.Error("failed to list notion databases", zap.Error(err))
+				w.WriteHeader(http.StatusBadGateway)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"databases": databases})
+
+		case http.MethodPost:
+			var req switchDatabasesRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid JSON body"})
+				return
+			}
+			if req.DatabaseID == "" && req.CustomersDatabaseID == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "database_id or customers_database_id is required"})
+				return
+			}
+
+			if err := client.SwitchDatabases(req.DatabaseID, req.CustomersDatabaseID); err != nil {
+				logger.Warn("rejected notion database switch", zap.Error(err))
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+
+			databaseID, customersDBID := client.CurrentDatabases()
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"database_id":           databaseID,
+				"customers_database_id": customersDBID,
+				"permissions":           client.CheckPermissions(),
+			})
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// refreshCacheEntryRequest is the body of POST /admin/cache. Exactly one field must be set.
+type refreshCacheEntryRequest struct {
+	Customer string `json:"customer"`
+	Email    string `json:"email"`
+}
+
+// cacheEntryHandler returns an HTTP handler for the /admin/cache endpoint.
+//
+// GET returns the cache version, sizes and content checksums; replicas that
+// report the same checksums have converged to the same customers and users.
+// With ?contents=true it also returns every cached customer, user and
+// excluded guest.
+//
+// POST {"customer": "Acme"} or {"email": "jane@example.com"} looks up that one
+// customer or user in Notion and adds, updates or removes its cache entry,
+// without waiting for (or triggering) a full cache refresh.
+func cacheEntryHandler(client *notion.Client, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			if contents, _ := strconv.ParseBool(r.URL.Query().Get("contents")); contents {
+				json.NewEncoder(w).Encode(client.Snapshot().Contents())
+				return
+			}
+			json.NewEncoder(w).Encode(client.Snapshot().Summary())
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		var req refreshCacheEntryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid JSON body"})
+			return
+		}
+		if (req.Customer == "") == (req.Email == "") {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "exactly one of customer or email is required"})
+			return
+		}
+
+		var result *notion.CacheEntryResult
+		var err error
+		if req.Customer != "" {
+			result, err = client.RefreshCustomer(req.Customer)
+		} else {
+			result, err = client.RefreshUser(req.Email)
+		}
+		if err != nil {
+			logger.Error("failed to refresh cache entry", zap.String("customer", req.Customer), zap.String("email", req.Email), zap.Error(err))
+			w.WriteHeader(http.StatusBadGateway)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// cacheRefreshHandler returns an HTTP handler for the /admin/cache/refresh endpoint.
+//
+// POST refreshes every cache and returns each cache's outcome. If the refresh
+// takes longer than constants.AdminCacheRefreshTimeout, it answers 202 and the
+// refresh finishes in the background.
+func cacheRefreshHandler(cacheMgr *cache.Manager, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		ctx, cancel := context.WithTimeout(r.Context(), constants.AdminCacheRefreshTimeout)
+		defer cancel()
+		report, err := cacheMgr.ManualRefreshAndWait(ctx)
+		switch {
+		case errors.Is(err, cache.ErrStopped):
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		case err != nil:
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]string{"status": "running"})
+			return
+		}
+
+		type cacheResult struct {
+			Name       string `json:"name"`
+			DurationMS int64  `json:"duration_ms"`
+			Error      string `json:"error,omitempty"`
+		}
+		caches := make([]cacheResult, 0, len(report.Caches))
+		for _, result := range report.Caches {
+			entry := cacheResult{Name: result.Name, DurationMS: result.Duration.Milliseconds()}
+			if result.Err != nil {
+				entry.Error = result.Err.Error()
+			}
+			caches = append(caches, entry)
+		}
+		if failed := report.Failed(); len(failed) > 0 {
+			logger.Warn("admin cache refresh finished with failures", zap.Int("failed", len(failed)))
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"duration_ms": report.Duration.Milliseconds(),
+			"caches":      caches,
+		})
+	}
+}
+
+// configHandler returns an HTTP handler for the /admin/config endpoint.
+//
+// GET returns the running configuration, including reloaded settings, with
+// secrets redacted.
+func configHandler(current func() *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(current().Redacted())
+	}
+}
+
+// credentialsHandler returns an HTTP handler for the /admin/credentials endpoint.
+//
+// GET lists the credentials that can be rotated. POST {"slack_bot_token": "xoxb-...",
+// "notion_api_key": "secret_..."} checks each listed credential against its API
+// and switches to it, returning {"rotated": [...], "unchanged": [...]}. Unknown
+// names are rejected with 400 and nothing is rotated; a rejected value is 422,
+// and credentials before it (in name order) stay rotated.
+func credentialsHandler(rotator *credentials.Rotator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(map[string][]string{"credentials": rotator.Names()})
+
+		case http.MethodPost:
+			var values map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&values); err != nil || len(values) == 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid JSON body, expected {\"credential\": \"new value\"}"})
+				return
+			}
+			names := make([]string, 0, len(values))
+			for name := range values {
+				if !slices.Contains(rotator.Names(), name) {
+					w.WriteHeader(http.StatusBadRequest)
+					json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("unknown credential %q (known: %v)", name, rotator.Names())})
+					return
+				}
+				names = append(names, name)
+			}
+			slices.Sort(names)
+
+			rotated, unchanged := []string{}, []string{}
+			for _, name := range names {
+				changed, err := rotator.Rotate(r.Context(), name, values[name], credentials.SourceAdmin)
+				if err != nil {
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error(), "rotated": rotated})
+					return
+				}
+				if changed {
+					rotated = append(rotated, name)
+				} else {
+					unchanged = append(unchanged, name)
+				}
+			}
+			json.NewEncoder(w).Encode(map[string][]string{"rotated": rotated, "unchanged": unchanged})
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// flagChanges returns the feature flag changes that take DISABLED_FEATURE_FLAGS
+// from previous to disabled: newly listed flags are switched off and flags no
+// longer listed back on. Flags toggled through /admin/flags are left alone
+// unless their DISABLED_FEATURE_FLAGS entry changed.
+func flagChanges(previous, disabled []string) map[string]bool {
+	changes := make(map[string]bool)
+	for _, name := range previous {
+		if !slices.Contains(disabled, name) {
+			changes[name] = true
+		}
+	}
+	for _, name := range disabled {
+		if !slices.Contains(previous, name) {
+			changes[name] = false
+		}
+	}
+	return changes
+}
+
+// flagsHandler returns an HTTP handler for the /admin/flags endpoint.
+//
+// GET returns every feature flag. POST {"reminders": false} switches the
+// listed flags and returns them all; unknown flags are rejected with 400 and
+// nothing is changed. Changes last until the next restart.
+func flagsHandler(flags *featureflags.Flags, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodPost {
+			var changes map[string]bool
+			if err := json.NewDecoder(r.Body).Decode(&changes); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid JSON body, expected {\"flag\": true|false}"})
+				return
+			}
+			if err := flags.Set(changes); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+			logger.Info("feature flags changed", zap.Any("changes", changes))
+		}
+
+		json.NewEncoder(w).Encode(flags.All())
+	}
+}
+
+// funnelHandler returns an HTTP handler for the /admin/funnel endpoint.
+//
+// GET returns idea counts per funnel stage and time-to-triage and
+// time-to-decision statistics (in hours). The optional days query parameter
+// limits the summary to ideas submitted in the last N days.
+func funnelHandler(tracker *funnel.Tracker, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		var since time.Time
+		if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+			days, err := strconv.Atoi(daysStr)
+			if err != nil || days <= 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "days must be a positive number"})
+				return
+			}
+			since = time.Now().UTC().AddDate(0, 0, -days)
+		}
+
+		summary, err := tracker.Summary(since)
+		if err != nil {
+			logger.Error("failed to summarize idea funnel", zap.Error(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(summary)
+	}
+}
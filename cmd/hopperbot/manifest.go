@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/manifest"
+)
+
+// runManifestCommand implements `hopperbot manifest`, which prints the Slack app
+// manifest for this deployment to stdout.
+//
+// It does not load the server configuration, so it can run without Slack or
+// Notion credentials (e.g., in CI or on a laptop before the first deploy).
+//
+// Example:
+//
+//	hopperbot manifest --base-url https://hopperbot.example.com > manifest.json
+func runManifestCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("manifest", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	baseURL := flags.String("base-url", os.Getenv("PUBLIC_BASE_URL"), "public HTTPS origin of the deployment (default: $PUBLIC_BASE_URL)")
+	basePath := flags.String("base-path", os.Getenv("BASE_PATH"), "route prefix behind a reverse proxy, e.g. /hopperbot (default: $BASE_PATH)")
+	name := flags.String("name", "Hopperbot", "Slack app display name")
+	command := flags.String("command", constants.SlashCommand, "slash command to register")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	m, err := manifest.Build(manifest.Options{
+		BaseURL:    *baseURL,
+		BasePath:   *basePath,
+		AppName:    *name,
+		Command:    *command,
+		EventsPath: constants.RouteSlackEvents,
+		BotEvents:  []string{manifest.EventAppHomeOpened, manifest.EventReactionAdded, manifest.EventReactionRemoved},
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "manifest: %v\n", err)
+		return 1
+	}
+
+	data, err := m.JSON()
+	if err != nil {
+		fmt.Fprintf(stderr, "manifest: failed to render JSON: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintln(stdout, string(data))
+	return 0
+}
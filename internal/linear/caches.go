@@ -0,0 +1,109 @@
+package linear
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"go.uber.org/zap"
+)
+
+// InitializeCustomers loads the workspace's Linear customers (name -> ID) into the cache.
+func (c *Client) InitializeCustomers() error {
+	customers := make(map[string]string)
+	err := c.paginate(context.Background(), `query Customers($first: Int!, $after: String) {
+  customers(first: $first, after: $after) { nodes { id name } pageInfo { hasNextPage endCursor } }
+}`, "customers", nil, func(raw json.RawMessage) error {
+		var nodes []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(raw, &nodes); err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			if name := strings.TrimSpace(node.Name); name != "" {
+				customers[name] = node.ID
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch customers: %w", err)
+	}
+
+	snapshot := c.publish(customers, nil)
+	if c.metrics != nil {
+		c.metrics.ClientCacheSize.Set(float64(snapshot.CustomerCount()))
+	}
+	c.logger.Info("initialized Linear customers cache", zap.Int("count", snapshot.CustomerCount()))
+	return nil
+}
+
+// InitializeUsers loads the workspace's active Linear users (email -> ID) into
+// the cache, to map Slack submitters to Linear users.
+func (c *Client) InitializeUsers() error {
+	users := make(map[string]string)
+	err := c.paginate(context.Background(), `query Users($first: Int!, $after: String) {
+  users(first: $first, after: $after) { nodes { id email active } pageInfo { hasNextPage endCursor } }
+}`, "users", nil, func(raw json.RawMessage) error {
+		var nodes []struct {
+			ID     string `json:"id"`
+			Email  string `json:"email"`
+			Active bool   `json:"active"`
+		}
+		if err := json.Unmarshal(raw, &nodes); err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			if node.Active && node.Email != "" {
+				users[strings.ToLower(strings.TrimSpace(node.Email))] = node.ID
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch users: %w", err)
+	}
+
+	snapshot := c.publish(nil, users)
+	if c.metrics != nil {
+		c.metrics.UserCacheSize.Set(float64(snapshot.UserCount()))
+	}
+	c.logger.Info("initialized Linear users cache", zap.Int("count", snapshot.UserCount()))
+	return nil
+}
+
+// publish replaces the cached customers or users (whichever is non-nil) and
+// publishes a new snapshot with both.
+func (c *Client) publish(customers, users map[string]string) *notion.CacheSnapshot {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
+	if customers != nil {
+		c.customers = customers
+	}
+	if users != nil {
+		c.users = users
+	}
+	snapshot := notion.NewCacheSnapshot(c.customers, c.users)
+	c.cache.Store(snapshot)
+	return snapshot
+}
+
+// Snapshot returns the current customer and user caches.
+func (c *Client) Snapshot() *notion.CacheSnapshot {
+	return c.cache.Load()
+}
+
+// GetUserCacheSize returns the number of users in the cache.
+func (c *Client) GetUserCacheSize() int {
+	return c.Snapshot().UserCount()
+}
+
+// GetCachedUserEmails returns the cached (normalized) user emails.
+func (c *Client) GetCachedUserEmails() []string {
+	return c.Snapshot().UserEmails()
+}
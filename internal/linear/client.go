@@ -0,0 +1,255 @@
+// Package linear files ideas as issues in Linear, as an alternative to the
+// Notion backend (BACKEND=linear).
+//
+// The client implements the Slack handler's SubmissionBackend with Linear's
+// GraphQL API, so the modal, validation and follow-up flows are the same as
+// with Notion:
+//
+// - An idea is an issue in the configured team, or in the team its product area is mapped to
+// - Theme and product area are labels, the children of two label groups whose labels become the modal's options
+// - Customers are Linear customers, linked to the issue as customer requests
+// - Submitters are Linear users matched by email and subscribed to their issues
+// - The triage status is the issue's workflow state
+//
+// Results are returned in the notion package's types (CreatedPage,
+// IdeaQueryResult, CacheSnapshot, ...), whose "page" is an issue here.
+package linear
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/httpclient"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/requestid"
+	"go.uber.org/zap"
+)
+
+// Config selects where the client files ideas.
+type Config struct {
+	APIKey string // Personal API key (lin_api_...) or OAuth access token (lin_oauth_...)
+	Team   string // Key of the team issues are created in, e.g. "PRD"
+
+	// ProductAreaTeams routes ideas of a product area to another team, by team key.
+	ProductAreaTeams map[string]string
+
+	// Label groups whose child labels are the theme and product area options
+	// (default: the Notion property names, constants.FieldThemeCategory and constants.FieldProductArea).
+	ThemeLabelGroup       string
+	ProductAreaLabelGroup string
+}
+
+// Client files ideas in Linear and caches its customers and users.
+type Client struct {
+	config     Config
+	endpoint   string // GraphQL endpoint; replaced in tests
+	httpClient *http.Client
+	transport  *httpclient.Transport // httpClient's retrying, circuit-breaking transport
+
+	teams     atomic.Pointer[map[string]string] // Team key -> team ID; nil until InitializeDataSources
+	labels    atomic.Pointer[labelSet]          // Theme and product area labels; nil until the first SyncSchema
+	schemaErr atomic.Pointer[error]             // Why submissions are blocked (see SchemaError)
+
+	cache     atomic.Pointer[notion.CacheSnapshot] // Current customer and user caches
+	cacheMu   sync.Mutex                           // Serializes snapshot replacement and guards the maps below
+	customers map[string]string                    // Customer name -> Linear customer ID
+	users     map[string]string                    // Email -> Linear user ID
+
+	logger  *zap.Logger
+	metrics *metrics.Metrics
+}
+
+// NewClient returns a client for cfg. InitializeDataSources, SyncSchema and
+// the cache initializers must run before it accepts submissions.
+func NewClient(cfg Config, logger *zap.Logger) *Client {
+	if cfg.ThemeLabelGroup == "" {
+		cfg.ThemeLabelGroup = constants.FieldThemeCategory
+	}
+	if cfg.ProductAreaLabelGroup == "" {
+		cfg.ProductAreaLabelGroup = constants.FieldProductArea
+	}
+	httpClient, transport := httpclient.NewClient("linear", constants.DefaultHTTPTimeout, logger)
+	c := &Client{
+		config:     cfg,
+		endpoint:   constants.LinearAPIURL,
+		httpClient: httpClient,
+		transport:  transport,
+		logger:     logger,
+	}
+	c.cache.Store(notion.NewCacheSnapshot(nil, nil))
+	return c
+}
+
+// SetMetrics sets the metrics instance for the client.
+func (c *Client) SetMetrics(m *metrics.Metrics) {
+	c.metrics = m
+	c.transport.SetMetrics(m)
+	if m != nil {
+		m.ClientCacheSize.Set(float64(c.Snapshot().CustomerCount()))
+	}
+}
+
+// HealthCheck reads the API key's user, to check Linear is reachable and the key works.
+func (c *Client) HealthCheck(ctx context.Context) error {
+	var data struct {
+		Viewer struct {
+			ID string `json:"id"`
+		} `json:"viewer"`
+	}
+	return c.query(ctx, `query Viewer { viewer { id } }`, nil, &data)
+}
+
+// APIError is a failed Linear API request: a non-200 response or GraphQL errors.
+type APIError struct {
+	Status  int    // HTTP status
+	Code    string // Code of the first GraphQL error (extensions.code), e.g. "AUTHENTICATION_ERROR"
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("linear API error (status %d, %s): %s", e.Status, e.Code, e.Message)
+	}
+	return fmt.Sprintf("linear API error (status %d): %s", e.Status, e.Message)
+}
+
+// graphQLResponse is the envelope of every GraphQL response.
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string `json:"message"`
+		Extensions struct {
+			Code                   string `json:"code"`
+			UserPresentableMessage string `json:"userPresentableMessage"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// query runs a GraphQL query, which only reads, so the transport may retry it.
+func (c *Client) query(ctx context.Context, query string, variables map[string]any, out any) error {
+	return c.do(httpclient.Idempotent(ctx), query, variables, out)
+}
+
+// mutate runs a GraphQL mutation. It isn't retried by the transport.
+func (c *Client) mutate(ctx context.Context, mutation string, variables map[string]any, out any) error {
+	return c.do(ctx, mutation, variables, out)
+}
+
+// do posts a GraphQL operation and decodes its data into out. The request ID
+// in ctx, if any, is sent as the X-Request-ID header.
+func (c *Client) do(ctx context.Context, operation string, variables map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{"query": operation, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", authorization(c.config.APIKey))
+	req.Header.Set("Content-Type", "application/json")
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("linear API error (status %d): failed to read response body: %w", resp.StatusCode, err)
+	}
+	var envelope graphQLResponse
+	if err := json.Unmarshal(respBody, &envelope); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return &APIError{Status: resp.StatusCode, Message: strings.TrimSpace(string(respBody))}
+		}
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(envelope.Errors) > 0 {
+		first := envelope.Errors[0]
+		message := first.Message
+		if first.Extensions.UserPresentableMessage != "" {
+			message = first.Extensions.UserPresentableMessage
+		}
+		return &APIError{Status: resp.StatusCode, Code: first.Extensions.Code, Message: message}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{Status: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return nil
+}
+
+// authorization returns the Authorization header for an API key: personal
+// keys are sent as is, OAuth access tokens as bearer tokens.
+func authorization(apiKey string) string {
+	if strings.HasPrefix(apiKey, "lin_oauth_") {
+		return "Bearer " + apiKey
+	}
+	return apiKey
+}
+
+// pageInfo is the pagination state of a GraphQL connection.
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// paginate runs a query over the connection named field, following its pages
+// and passing each page's nodes to fn. The query takes $first and $after in
+// addition to variables.
+func (c *Client) paginate(ctx context.Context, query, field string, variables map[string]any, fn func(nodes json.RawMessage) error) error {
+	vars := map[string]any{"first": constants.LinearPageSize}
+	for name, value := range variables {
+		vars[name] = value
+	}
+	for {
+		var data map[string]struct {
+			Nodes    json.RawMessage `json:"nodes"`
+			PageInfo pageInfo        `json:"pageInfo"`
+		}
+		if err := c.query(ctx, query, vars, &data); err != nil {
+			return err
+		}
+		connection, ok := data[field]
+		if !ok {
+			return fmt.Errorf("response has no %s", field)
+		}
+		if err := fn(connection.Nodes); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", field, err)
+		}
+		if !connection.PageInfo.HasNextPage || connection.PageInfo.EndCursor == "" {
+			return nil
+		}
+		vars["after"] = connection.PageInfo.EndCursor
+	}
+}
+
+// newIssueID returns a random UUID v4. Issues are created with an ID chosen
+// up front, so a create whose response was lost can be found again.
+func newIssueID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,544 @@
+package linear
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// operationPattern extracts the operation name from a GraphQL document.
+var operationPattern = regexp.MustCompile(`^\s*(?:query|mutation)\s+(\w+)`)
+
+// graphQLRequest is an operation the fake API received.
+type graphQLRequest struct {
+	Operation string
+	Variables map[string]any
+	Header    http.Header
+}
+
+// graphQLTransport fakes the Linear API: each operation name maps to a
+// function returning the response's data (or an error to send as GraphQL errors).
+type graphQLTransport struct {
+	mu         sync.Mutex
+	operations map[string]func(variables map[string]any) (any, error)
+	requests   []graphQLRequest
+	uploads    map[string][]byte // PUT URL -> uploaded content
+}
+
+func (f *graphQLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if req.Method == http.MethodPut {
+		if f.uploads == nil {
+			f.uploads = make(map[string][]byte)
+		}
+		f.uploads[req.URL.String()] = body
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Header: make(http.Header)}, nil
+	}
+
+	var payload struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	match := operationPattern.FindStringSubmatch(payload.Query)
+	if match == nil {
+		return nil, fmt.Errorf("no operation name in %q", payload.Query)
+	}
+	f.requests = append(f.requests, graphQLRequest{Operation: match[1], Variables: payload.Variables, Header: req.Header.Clone()})
+
+	respond, ok := f.operations[match[1]]
+	if !ok {
+		return nil, fmt.Errorf("unexpected operation %s", match[1])
+	}
+	data, err := respond(payload.Variables)
+	envelope := map[string]any{"data": data}
+	if err != nil {
+		envelope = map[string]any{"errors": []any{map[string]any{"message": err.Error(), "extensions": map[string]any{"code": "INVALID_INPUT"}}}}
+	}
+	encoded, _ := json.Marshal(envelope)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewReader(encoded)),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+	}, nil
+}
+
+// sent returns the requests made for an operation.
+func (f *graphQLTransport) sent(operation string) []graphQLRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var requests []graphQLRequest
+	for _, r := range f.requests {
+		if r.Operation == operation {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+// nodes wraps items in a single-page connection.
+func nodes(items ...any) map[string]any {
+	if items == nil {
+		items = []any{}
+	}
+	return map[string]any{"nodes": items, "pageInfo": map[string]any{"hasNextPage": false, "endCursor": ""}}
+}
+
+// newTestClient returns a client for team PRD, routing "Infrastructure" ideas
+// to team INFRA, whose teams and labels are loaded from the fake API.
+func newTestClient(t *testing.T, fake *graphQLTransport) *Client {
+	t.Helper()
+	if fake.operations == nil {
+		fake.operations = make(map[string]func(map[string]any) (any, error))
+	}
+	defaults := map[string]func(map[string]any) (any, error){
+		"Teams": func(map[string]any) (any, error) {
+			return map[string]any{"teams": nodes(
+				map[string]any{"id": "team-prd", "key": "PRD"},
+				map[string]any{"id": "team-infra", "key": "INFRA"},
+				map[string]any{"id": "team-other", "key": "OTHER"},
+			)}, nil
+		},
+		"Labels": func(map[string]any) (any, error) {
+			return map[string]any{"issueLabels": nodes(
+				map[string]any{"id": "label-feature", "name": "New Feature Idea", "parent": map[string]any{"name": "Theme/Category"}},
+				map[string]any{"id": "label-bug", "name": "Bug", "parent": map[string]any{"name": "Theme/Category"}},
+				map[string]any{"id": "label-ai", "name": "AI/ML", "parent": map[string]any{"name": "Product Area"}},
+				map[string]any{"id": "label-infra", "name": "Infrastructure", "parent": map[string]any{"name": "Product Area"}},
+				map[string]any{"id": "label-infra-team", "name": "Infrastructure", "parent": map[string]any{"name": "Product Area"}, "team": map[string]any{"id": "team-infra"}},
+			)}, nil
+		},
+	}
+	for name, respond := range defaults {
+		if _, ok := fake.operations[name]; !ok {
+			fake.operations[name] = respond
+		}
+	}
+
+	client := NewClient(Config{
+		APIKey:           "lin_api_test",
+		Team:             "PRD",
+		ProductAreaTeams: map[string]string{"Infrastructure": "infra"},
+	}, zap.NewNop())
+	client.httpClient = &http.Client{Transport: fake}
+	if err := client.InitializeDataSources(); err != nil {
+		t.Fatalf("InitializeDataSources() error = %v", err)
+	}
+	if _, err := client.SyncSchema(); err != nil {
+		t.Fatalf("SyncSchema() error = %v", err)
+	}
+	return client
+}
+
+// TestSubmitSubmission tests creating an issue with its labels, subscriber and customers
+func TestSubmitSubmission(t *testing.T) {
+	fake := &graphQLTransport{operations: map[string]func(map[string]any) (any, error){
+		"IssueCreate": func(vars map[string]any) (any, error) {
+			input := vars["input"].(map[string]any)
+			return map[string]any{"issueCreate": map[string]any{"success": true, "issue": map[string]any{
+				"id": input["id"], "url": "https://linear.app/acme/issue/PRD-1", "createdAt": "2026-10-16T09:00:00Z",
+			}}}, nil
+		},
+		"CustomerNeedCreate": func(map[string]any) (any, error) {
+			return map[string]any{"customerNeedCreate": map[string]any{"success": true}}, nil
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	page, err := client.SubmitSubmission(t.Context(), submission.Submission{
+		Title:             "Bulk export",
+		Theme:             "New Feature Idea",
+		ProductArea:       "AI/ML",
+		Comments:          "Customers want CSV exports.",
+		CustomerOrgs:      []string{"Acme"},
+		CustomerOrgIDs:    []string{"customer-acme"},
+		SubmitterNotionID: "user-1",
+		Source:            submission.Source{SubmitterEmail: "pm@example.com", SlackPermalink: "https://acme.slack.com/archives/C1/p1"},
+	})
+	if err != nil {
+		t.Fatalf("SubmitSubmission() error = %v", err)
+	}
+	if page.URL != "https://linear.app/acme/issue/PRD-1" || page.Recovered {
+		t.Errorf("page = %+v, want the created issue", page)
+	}
+
+	created := fake.sent("IssueCreate")
+	if len(created) != 1 {
+		t.Fatalf("IssueCreate requests = %d, want 1", len(created))
+	}
+	input := created[0].Variables["input"].(map[string]any)
+	if input["teamId"] != "team-prd" || input["title"] != "Bulk export" || input["id"] != page.ID {
+		t.Errorf("input = %v, want the PRD team, the title and the chosen ID", input)
+	}
+	if labels := fmt.Sprint(input["labelIds"]); labels != "[label-feature label-ai]" {
+		t.Errorf("labelIds = %s, want the theme and product area labels", labels)
+	}
+	if subscribers := fmt.Sprint(input["subscriberIds"]); subscribers != "[user-1]" {
+		t.Errorf("subscriberIds = %s, want the submitter", subscribers)
+	}
+	description := input["description"].(string)
+	for _, want := range []string{"Customers want CSV exports.", "**Customers:** Acme", "[message](https://acme.slack.com/archives/C1/p1)", "Submitted by pm@example.com"} {
+		if !strings.Contains(description, want) {
+			t.Errorf("description = %q, want it to contain %q", description, want)
+		}
+	}
+
+	needs := fake.sent("CustomerNeedCreate")
+	if len(needs) != 1 || needs[0].Variables["input"].(map[string]any)["customerId"] != "customer-acme" {
+		t.Errorf("CustomerNeedCreate requests = %+v, want one linking Acme", needs)
+	}
+}
+
+// TestSubmitSubmission_RoutesProductArea tests that a routed product area uses its team and the team's own label
+func TestSubmitSubmission_RoutesProductArea(t *testing.T) {
+	fake := &graphQLTransport{operations: map[string]func(map[string]any) (any, error){
+		"IssueCreate": func(vars map[string]any) (any, error) {
+			input := vars["input"].(map[string]any)
+			return map[string]any{"issueCreate": map[string]any{"success": true, "issue": map[string]any{"id": input["id"], "url": "u", "createdAt": "2026-10-16T09:00:00Z"}}}, nil
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	_, err := client.SubmitSubmission(t.Context(), submission.Submission{Title: "Faster builds", Theme: "Bug", ProductArea: "Infrastructure"})
+	if err != nil {
+		t.Fatalf("SubmitSubmission() error = %v", err)
+	}
+	input := fake.sent("IssueCreate")[0].Variables["input"].(map[string]any)
+	if input["teamId"] != "team-infra" {
+		t.Errorf("teamId = %v, want team-infra", input["teamId"])
+	}
+	if labels := fmt.Sprint(input["labelIds"]); labels != "[label-bug label-infra-team]" {
+		t.Errorf("labelIds = %s, want the INFRA team's product area label", labels)
+	}
+	if _, ok := input["subscriberIds"]; ok {
+		t.Error("subscriberIds set without a mapped submitter")
+	}
+}
+
+// TestSubmitSubmission_InvalidLabel tests that an unknown theme is rejected without a request
+func TestSubmitSubmission_InvalidLabel(t *testing.T) {
+	fake := &graphQLTransport{}
+	client := newTestClient(t, fake)
+
+	_, err := client.SubmitSubmission(t.Context(), submission.Submission{Title: "Idea", Theme: "Unknown", ProductArea: "AI/ML"})
+	if err == nil || !strings.Contains(err.Error(), `invalid theme "Unknown"`) {
+		t.Errorf("SubmitSubmission() error = %v, want an invalid theme", err)
+	}
+	if len(fake.sent("IssueCreate")) != 0 {
+		t.Error("an issue was created for an invalid submission")
+	}
+}
+
+// TestSubmitSubmission_RecoversLostResponse tests that an issue created despite a failed response is returned
+func TestSubmitSubmission_RecoversLostResponse(t *testing.T) {
+	var createdID string
+	fake := &graphQLTransport{operations: map[string]func(map[string]any) (any, error){
+		"Issue": func(vars map[string]any) (any, error) {
+			return map[string]any{"issue": map[string]any{"id": vars["id"], "url": "https://linear.app/acme/issue/PRD-2", "createdAt": "2026-10-16T09:00:00Z"}}, nil
+		},
+	}}
+	client := newTestClient(t, fake)
+	client.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		if strings.Contains(string(body), "IssueCreate") {
+			var payload struct {
+				Variables struct {
+					Input map[string]any `json:"input"`
+				} `json:"variables"`
+			}
+			json.Unmarshal(body, &payload)
+			createdID = payload.Variables.Input["id"].(string)
+			return nil, errors.New("connection reset")
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return fake.RoundTrip(req)
+	})}
+
+	page, err := client.SubmitSubmission(t.Context(), submission.Submission{Title: "Idea", Theme: "Bug", ProductArea: "AI/ML"})
+	if err != nil {
+		t.Fatalf("SubmitSubmission() error = %v", err)
+	}
+	if !page.Recovered || page.ID != createdID {
+		t.Errorf("page = %+v, want the issue %s recovered", page, createdID)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// TestSyncSchema tests the form properties and the submission guard of an empty label group
+func TestSyncSchema(t *testing.T) {
+	fake := &graphQLTransport{}
+	client := newTestClient(t, fake)
+
+	properties, err := client.SyncSchema()
+	if err != nil {
+		t.Fatalf("SyncSchema() error = %v", err)
+	}
+	options := notion.SelectOptionsFromSchema(properties)
+	if fmt.Sprint(options.Themes) != "[New Feature Idea Bug]" || fmt.Sprint(options.ProductAreas) != "[AI/ML Infrastructure]" {
+		t.Errorf("options = %+v, want the labels of each group once", options)
+	}
+	if err := client.SchemaError(); err != nil {
+		t.Errorf("SchemaError() = %v, want nil", err)
+	}
+
+	fake.operations["Labels"] = func(map[string]any) (any, error) {
+		return map[string]any{"issueLabels": nodes(
+			map[string]any{"id": "label-bug", "name": "Bug", "parent": map[string]any{"name": "Theme/Category"}},
+		)}, nil
+	}
+	if _, err := client.SyncSchema(); err != nil {
+		t.Fatalf("SyncSchema() error = %v", err)
+	}
+	if err := client.SchemaError(); err == nil || !strings.Contains(err.Error(), `"Product Area" has no labels`) {
+		t.Errorf("SchemaError() = %v, want the empty product area group", err)
+	}
+	if _, err := client.SubmitSubmission(t.Context(), submission.Submission{Title: "Idea", Theme: "Bug"}); err == nil || !strings.Contains(err.Error(), "submissions are paused") {
+		t.Errorf("SubmitSubmission() error = %v, want submissions paused", err)
+	}
+}
+
+// TestInitializeDataSources_UnknownTeam tests that a team key the API key can't see is an error
+func TestInitializeDataSources_UnknownTeam(t *testing.T) {
+	fake := &graphQLTransport{}
+	client := newTestClient(t, fake)
+	client.config.Team = "MISSING"
+
+	if err := client.InitializeDataSources(); err == nil || !strings.Contains(err.Error(), `team "MISSING" not found`) {
+		t.Errorf("InitializeDataSources() error = %v, want the missing team", err)
+	}
+}
+
+// TestInitializeCaches tests loading customers and users across pages
+func TestInitializeCaches(t *testing.T) {
+	fake := &graphQLTransport{operations: map[string]func(map[string]any) (any, error){
+		"Customers": func(vars map[string]any) (any, error) {
+			if vars["after"] == nil {
+				return map[string]any{"customers": map[string]any{
+					"nodes":    []any{map[string]any{"id": "customer-acme", "name": "Acme"}},
+					"pageInfo": map[string]any{"hasNextPage": true, "endCursor": "page-2"},
+				}}, nil
+			}
+			return map[string]any{"customers": nodes(map[string]any{"id": "customer-globex", "name": " Globex "})}, nil
+		},
+		"Users": func(map[string]any) (any, error) {
+			return map[string]any{"users": nodes(
+				map[string]any{"id": "user-1", "email": "PM@Example.com", "active": true},
+				map[string]any{"id": "user-2", "email": "former@example.com", "active": false},
+			)}, nil
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	if err := client.InitializeCustomers(); err != nil {
+		t.Fatalf("InitializeCustomers() error = %v", err)
+	}
+	if err := client.InitializeUsers(); err != nil {
+		t.Fatalf("InitializeUsers() error = %v", err)
+	}
+
+	snapshot := client.Snapshot()
+	if snapshot.CustomerCount() != 2 {
+		t.Errorf("CustomerCount() = %d, want 2", snapshot.CustomerCount())
+	}
+	if id, ok := snapshot.CustomerPageID("Globex"); !ok || id != "customer-globex" {
+		t.Errorf("CustomerID(Globex) = %q, %v, want the second page's customer", id, ok)
+	}
+	if emails := client.GetCachedUserEmails(); fmt.Sprint(emails) != "[pm@example.com]" {
+		t.Errorf("GetCachedUserEmails() = %v, want the active user's normalized email", emails)
+	}
+}
+
+// TestQueryIdeas tests the issue filter and skipping archived issues
+func TestQueryIdeas(t *testing.T) {
+	fake := &graphQLTransport{operations: map[string]func(map[string]any) (any, error){
+		"Issues": func(map[string]any) (any, error) {
+			return map[string]any{"issues": nodes(
+				map[string]any{"id": "issue-1", "title": "Bulk export", "createdAt": "2026-10-15T09:00:00Z", "state": map[string]any{"name": "Triage"},
+					"labels": nodes(map[string]any{"name": "Bug", "parent": map[string]any{"name": "Theme/Category"}}, map[string]any{"name": "AI/ML", "parent": map[string]any{"name": "Product Area"}})},
+				map[string]any{"id": "issue-2", "title": "Archived", "createdAt": "2026-10-14T09:00:00Z", "archivedAt": "2026-10-15T09:00:00Z"},
+			)}, nil
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	after := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	result, err := client.QueryIdeas(notion.IdeasFilter{Theme: "Bug", SubmitterID: "user-1", CreatedAfter: after}, notion.NewestFirst, "")
+	if err != nil {
+		t.Fatalf("QueryIdeas() error = %v", err)
+	}
+	if result.Total != 1 || result.Truncated || len(result.Ideas) != 1 {
+		t.Fatalf("result = %+v, want the one live issue", result)
+	}
+	idea := result.Ideas[0]
+	if idea.Status != "Triage" || fmt.Sprint(idea.Themes) != "[Bug]" || idea.ProductArea != "AI/ML" {
+		t.Errorf("idea = %+v, want its state and labels", idea)
+	}
+
+	vars := fake.sent("Issues")[0].Variables
+	filter, _ := json.Marshal(vars["filter"])
+	for _, want := range []string{
+		`{"team":{"id":{"in":["team-infra","team-prd"]}}}`,
+		`{"subscribers":{"some":{"id":{"eq":"user-1"}}}}`,
+		`{"labels":{"some":{"name":{"eq":"Bug"}}}}`,
+		`{"createdAt":{"gte":"2026-10-01T00:00:00Z"}}`,
+	} {
+		if !strings.Contains(string(filter), want) {
+			t.Errorf("filter = %s, want it to contain %s", filter, want)
+		}
+	}
+	if sort, _ := json.Marshal(vars["sort"]); string(sort) != `[{"createdAt":{"order":"Descending"}}]` {
+		t.Errorf("sort = %s, want newest first", sort)
+	}
+
+	if _, err := client.QueryIdeas(notion.IdeasFilter{}, notion.Sort{Property: "Votes"}, ""); err == nil {
+		t.Error("QueryIdeas() sorted by a property, want an error")
+	}
+}
+
+// TestQueryIdeas_Truncated tests that reading stops after maxIdeaQueryPages pages with a cursor to continue
+func TestQueryIdeas_Truncated(t *testing.T) {
+	page := 0
+	fake := &graphQLTransport{operations: map[string]func(map[string]any) (any, error){
+		"Issues": func(map[string]any) (any, error) {
+			page++
+			return map[string]any{"issues": map[string]any{
+				"nodes":    []any{map[string]any{"id": fmt.Sprintf("issue-%d", page), "createdAt": "2026-10-15T09:00:00Z"}},
+				"pageInfo": map[string]any{"hasNextPage": true, "endCursor": fmt.Sprintf("cursor-%d", page)},
+			}}, nil
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	result, err := client.QueryIdeas(notion.IdeasFilter{}, notion.NewestFirst, "")
+	if err != nil {
+		t.Fatalf("QueryIdeas() error = %v", err)
+	}
+	if !result.Truncated || result.NextCursor != fmt.Sprintf("cursor-%d", maxIdeaQueryPages) || result.Total != maxIdeaQueryPages {
+		t.Errorf("result = Truncated %v, NextCursor %q, Total %d, want truncated after %d pages", result.Truncated, result.NextCursor, result.Total, maxIdeaQueryPages)
+	}
+
+	if _, err := client.QueryIdeas(notion.IdeasFilter{}, notion.NewestFirst, result.NextCursor); err != nil {
+		t.Fatalf("QueryIdeas() error = %v", err)
+	}
+	requests := fake.sent("Issues")
+	if after := requests[maxIdeaQueryPages].Variables["after"]; after != result.NextCursor {
+		t.Errorf("continued query after = %v, want %q", after, result.NextCursor)
+	}
+}
+
+// TestGetSubmission tests reading an issue back for editing, without the details added after the comments
+func TestGetSubmission(t *testing.T) {
+	fake := &graphQLTransport{operations: map[string]func(map[string]any) (any, error){
+		"Issue": func(map[string]any) (any, error) {
+			return map[string]any{"issue": map[string]any{
+				"id":          "issue-1",
+				"url":         "https://linear.app/acme/issue/PRD-1",
+				"title":       "Bulk export",
+				"description": "Customers want CSV.\n\n---\nAlso markdown rules.\n\n---\n**Customers:** Acme",
+				"labels":      nodes(map[string]any{"name": "Bug", "parent": map[string]any{"name": "Theme/Category"}}, map[string]any{"name": "AI/ML", "parent": map[string]any{"name": "Product Area"}}),
+				"subscribers": nodes(map[string]any{"id": "user-1"}),
+				"needs":       nodes(map[string]any{"customer": map[string]any{"id": "customer-acme"}}),
+			}}, nil
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	page, err := client.GetSubmission("issue-1")
+	if err != nil {
+		t.Fatalf("GetSubmission() error = %v", err)
+	}
+	sub := page.Submission
+	if sub.Comments != "Customers want CSV.\n\n---\nAlso markdown rules." {
+		t.Errorf("Comments = %q, want the description up to the last footer", sub.Comments)
+	}
+	if sub.Theme != "Bug" || sub.ProductArea != "AI/ML" || fmt.Sprint(sub.CustomerOrgIDs) != "[customer-acme]" || fmt.Sprint(page.Submitters) != "[user-1]" {
+		t.Errorf("page = %+v, want the labels, customers and subscribers", page)
+	}
+}
+
+// TestAttachFile tests uploading a file and attaching its asset URL
+func TestAttachFile(t *testing.T) {
+	fake := &graphQLTransport{operations: map[string]func(map[string]any) (any, error){
+		"FileUpload": func(map[string]any) (any, error) {
+			return map[string]any{"fileUpload": map[string]any{"success": true, "uploadFile": map[string]any{
+				"uploadUrl": "https://uploads.linear.app/signed", "assetUrl": "https://uploads.linear.app/asset.png",
+				"headers": []any{map[string]any{"key": "x-goog-meta", "value": "1"}},
+			}}}, nil
+		},
+		"AttachmentCreate": func(map[string]any) (any, error) {
+			return map[string]any{"attachmentCreate": map[string]any{"success": true}}, nil
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	err := client.AttachFile("issue-1", notion.FileAttachment{Name: "screenshot.png", ContentType: "image/png", Content: []byte("png")})
+	if err != nil {
+		t.Fatalf("AttachFile() error = %v", err)
+	}
+	if string(fake.uploads["https://uploads.linear.app/signed"]) != "png" {
+		t.Errorf("uploads = %v, want the content PUT to the signed URL", fake.uploads)
+	}
+	input := fake.sent("AttachmentCreate")[0].Variables["input"].(map[string]any)
+	if input["url"] != "https://uploads.linear.app/asset.png" || input["issueId"] != "issue-1" {
+		t.Errorf("attachment = %v, want the asset attached to the issue", input)
+	}
+}
+
+// TestCommentBody tests the context comment added to submitted issues
+func TestCommentBody(t *testing.T) {
+	body := commentBody(notion.PageComments{
+		Text:        "Already in the description",
+		SubmittedBy: "pm@example.com",
+		Channel:     "modal",
+		SubmittedAt: time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC),
+		ThreadURL:   "https://acme.slack.com/archives/C1/p1",
+	})
+	want := "_Submitted by pm@example.com via modal on 2026-10-16 09:30 UTC · [Slack thread](https://acme.slack.com/archives/C1/p1)_"
+	if body != want {
+		t.Errorf("commentBody() = %q, want %q", body, want)
+	}
+	if body := commentBody(notion.PageComments{Text: "only text"}); body != "" {
+		t.Errorf("commentBody() = %q, want no comment without context", body)
+	}
+}
+
+// TestAPIError tests that GraphQL errors are returned as *APIError and the key is sent as is
+func TestAPIError(t *testing.T) {
+	fake := &graphQLTransport{operations: map[string]func(map[string]any) (any, error){
+		"Viewer": func(map[string]any) (any, error) { return nil, errors.New("bad input") },
+	}}
+	client := newTestClient(t, fake)
+
+	err := client.HealthCheck(t.Context())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "INVALID_INPUT" || apiErr.Message != "bad input" {
+		t.Errorf("HealthCheck() error = %v, want an *APIError", err)
+	}
+	if auth := fake.sent("Viewer")[0].Header.Get("Authorization"); auth != "lin_api_test" {
+		t.Errorf("Authorization = %q, want the personal API key", auth)
+	}
+	if auth := authorization("lin_oauth_abc"); auth != "Bearer lin_oauth_abc" {
+		t.Errorf("authorization(OAuth token) = %q, want a bearer token", auth)
+	}
+}
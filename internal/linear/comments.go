@@ -0,0 +1,69 @@
+package linear
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"go.uber.org/zap"
+)
+
+// AppendComments adds a comment to an issue saying who submitted it, where
+// and when, linking the Slack thread. The comments' text itself is already
+// the issue's description (see SubmitSubmission), so it isn't repeated.
+func (c *Client) AppendComments(pageID string, comments notion.PageComments) error {
+	body := commentBody(comments)
+	if body == "" {
+		return nil
+	}
+
+	var data struct {
+		CommentCreate struct {
+			Success bool `json:"success"`
+		} `json:"commentCreate"`
+	}
+	err := c.mutate(context.Background(), `mutation CommentCreate($input: CommentCreateInput!) {
+  commentCreate(input: $input) { success }
+}`, map[string]any{"input": map[string]any{"issueId": pageID, "body": body}}, &data)
+	if err != nil {
+		return fmt.Errorf("failed to add comment: %w", err)
+	}
+	if !data.CommentCreate.Success {
+		return fmt.Errorf("linear didn't add the comment to issue %s", pageID)
+	}
+
+	c.logger.Debug("added comment to linear issue", zap.String("issue_id", pageID))
+	return nil
+}
+
+// commentBody returns the Markdown context line of comments, or "" when there's no context.
+func commentBody(comments notion.PageComments) string {
+	var context []string
+	if comments.SubmittedBy != "" {
+		context = append(context, "Submitted by "+comments.SubmittedBy)
+	}
+	if comments.Channel != "" {
+		context = append(context, "via "+comments.Channel)
+	}
+	if !comments.SubmittedAt.IsZero() {
+		context = append(context, "on "+comments.SubmittedAt.UTC().Format("2006-01-02 15:04 MST"))
+	}
+	line := strings.Join(context, " ")
+	if comments.ThreadURL != "" {
+		if line != "" {
+			line += " · "
+		}
+		line += fmt.Sprintf("[Slack thread](%s)", comments.ThreadURL)
+	}
+	if line == "" {
+		return ""
+	}
+	return "_" + line + "_"
+}
+
+// SetVotes does nothing: Linear counts demand with customer requests, which
+// are linked when an idea is submitted or edited, rather than with a number.
+func (c *Client) SetVotes(pageID string, votes int) error {
+	return nil
+}
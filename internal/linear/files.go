@@ -0,0 +1,96 @@
+package linear
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"go.uber.org/zap"
+)
+
+// AttachFile attaches a file to an issue: uploaded to Linear's storage when
+// file.Content is set, otherwise linking file.ExternalURL.
+func (c *Client) AttachFile(pageID string, file notion.FileAttachment) error {
+	ctx := context.Background()
+	url := file.ExternalURL
+	if file.Content != nil {
+		assetURL, err := c.uploadFile(ctx, file)
+		if err != nil {
+			return err
+		}
+		url = assetURL
+	}
+
+	var data struct {
+		AttachmentCreate struct {
+			Success bool `json:"success"`
+		} `json:"attachmentCreate"`
+	}
+	err := c.mutate(ctx, `mutation AttachmentCreate($input: AttachmentCreateInput!) {
+  attachmentCreate(input: $input) { success }
+}`, map[string]any{"input": map[string]any{"issueId": pageID, "title": file.Name, "url": url}}, &data)
+	if err != nil {
+		return fmt.Errorf("failed to attach file: %w", err)
+	}
+	if !data.AttachmentCreate.Success {
+		return fmt.Errorf("linear didn't attach %s", file.Name)
+	}
+
+	c.logger.Debug("attached file to linear issue",
+		zap.String("issue_id", pageID),
+		zap.String("name", file.Name),
+		zap.Bool("uploaded", file.Content != nil),
+	)
+	return nil
+}
+
+// uploadFile uploads file.Content with a signed upload URL from the
+// fileUpload mutation and returns the URL of the stored asset.
+func (c *Client) uploadFile(ctx context.Context, file notion.FileAttachment) (string, error) {
+	var data struct {
+		FileUpload struct {
+			Success    bool `json:"success"`
+			UploadFile struct {
+				UploadURL string `json:"uploadUrl"`
+				AssetURL  string `json:"assetUrl"`
+				Headers   []struct {
+					Key   string `json:"key"`
+					Value string `json:"value"`
+				} `json:"headers"`
+			} `json:"uploadFile"`
+		} `json:"fileUpload"`
+	}
+	err := c.mutate(ctx, `mutation FileUpload($contentType: String!, $filename: String!, $size: Int!) {
+  fileUpload(contentType: $contentType, filename: $filename, size: $size) {
+    success uploadFile { uploadUrl assetUrl headers { key value } }
+  }
+}`, map[string]any{"contentType": file.ContentType, "filename": file.Name, "size": len(file.Content)}, &data)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file upload: %w", err)
+	}
+	upload := data.FileUpload.UploadFile
+	if !data.FileUpload.Success || upload.UploadURL == "" {
+		return "", fmt.Errorf("linear didn't accept the upload of %s", file.Name)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, upload.UploadURL, bytes.NewReader(file.Content))
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", file.ContentType)
+	req.Header.Set("Cache-Control", "public, max-age=31536000")
+	for _, header := range upload.Headers {
+		req.Header.Set(header.Key, header.Value)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload file: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("failed to upload file: status %d", resp.StatusCode)
+	}
+	return upload.AssetURL, nil
+}
@@ -0,0 +1,363 @@
+package linear
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// descriptionFooter separates the comments in an issue's description from
+// the details the bot adds after them (customers, artifacts, Slack link).
+const descriptionFooter = "\n\n---\n"
+
+// issueFields are the issue fields read by GetSubmission, GetPageStatus and QueryIdeas.
+const issueFields = `id url title description createdAt updatedAt archivedAt trashed
+  state { name }
+  labels { nodes { name parent { name } } }
+  subscribers { nodes { id } }
+  needs { nodes { customer { id } } }`
+
+// issue is an issue as read with issueFields.
+type issue struct {
+	ID          string     `json:"id"`
+	URL         string     `json:"url"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	ArchivedAt  *time.Time `json:"archivedAt"`
+	Trashed     bool       `json:"trashed"`
+	State       struct {
+		Name string `json:"name"`
+	} `json:"state"`
+	Labels struct {
+		Nodes []struct {
+			Name   string `json:"name"`
+			Parent *struct {
+				Name string `json:"name"`
+			} `json:"parent"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Subscribers struct {
+		Nodes []struct {
+			ID string `json:"id"`
+		} `json:"nodes"`
+	} `json:"subscribers"`
+	Needs struct {
+		Nodes []struct {
+			Customer *struct {
+				ID string `json:"id"`
+			} `json:"customer"`
+		} `json:"nodes"`
+	} `json:"needs"`
+}
+
+// removed reports whether the issue was archived or deleted.
+func (i *issue) removed() bool {
+	return i.ArchivedAt != nil || i.Trashed
+}
+
+// labelsIn returns the names of the issue's labels in group.
+func (i *issue) labelsIn(group string) []string {
+	var names []string
+	for _, l := range i.Labels.Nodes {
+		if l.Parent != nil && l.Parent.Name == group {
+			names = append(names, l.Name)
+		}
+	}
+	return names
+}
+
+// customerIDs returns the customers linked to the issue.
+func (i *issue) customerIDs() []string {
+	var ids []string
+	for _, need := range i.Needs.Nodes {
+		if need.Customer != nil {
+			ids = append(ids, need.Customer.ID)
+		}
+	}
+	return ids
+}
+
+// summary converts the issue to the notion package's query result.
+func (c *Client) summary(i *issue) notion.IdeaSummary {
+	summary := notion.IdeaSummary{
+		ID:          i.ID,
+		URL:         i.URL,
+		Title:       i.Title,
+		Status:      i.State.Name,
+		Themes:      i.labelsIn(c.config.ThemeLabelGroup),
+		CreatedTime: i.CreatedAt,
+	}
+	if areas := i.labelsIn(c.config.ProductAreaLabelGroup); len(areas) > 0 {
+		summary.ProductArea = areas[0]
+	}
+	return summary
+}
+
+// getIssue reads an issue by ID.
+func (c *Client) getIssue(ctx context.Context, id string) (*issue, error) {
+	var data struct {
+		Issue *issue `json:"issue"`
+	}
+	err := c.query(ctx, `query Issue($id: String!) { issue(id: $id) { `+issueFields+` } }`, map[string]any{"id": id}, &data)
+	if err != nil {
+		return nil, err
+	}
+	if data.Issue == nil {
+		return nil, fmt.Errorf("issue %s not found", id)
+	}
+	return data.Issue, nil
+}
+
+// SubmitSubmission creates an issue for a submission: its title, the comments
+// as the description followed by the submission's details, the theme and
+// product area labels, and the submitter as subscriber. Customers are linked
+// to the issue afterwards; failures to link one are only logged.
+//
+// The issue ID is chosen up front, so when the create fails ambiguously (e.g.
+// a timeout) an issue that was created anyway is found and returned as Recovered.
+func (c *Client) SubmitSubmission(ctx context.Context, sub submission.Submission) (*notion.CreatedPage, error) {
+	if err := c.SchemaError(); err != nil {
+		return nil, fmt.Errorf("submissions are paused: %w", err)
+	}
+	input, err := c.issueInput(sub)
+	if err != nil {
+		return nil, err
+	}
+	id := newIssueID()
+	input["id"] = id
+
+	var data struct {
+		IssueCreate struct {
+			Success bool `json:"success"`
+			Issue   struct {
+				ID        string    `json:"id"`
+				URL       string    `json:"url"`
+				CreatedAt time.Time `json:"createdAt"`
+			} `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	err = c.mutate(ctx, `mutation IssueCreate($input: IssueCreateInput!) {
+  issueCreate(input: $input) { success issue { id url createdAt } }
+}`, map[string]any{"input": input}, &data)
+
+	var page *notion.CreatedPage
+	var apiErr *APIError
+	switch {
+	case err == nil && data.IssueCreate.Success:
+		page = &notion.CreatedPage{ID: data.IssueCreate.Issue.ID, URL: data.IssueCreate.Issue.URL, CreatedTime: data.IssueCreate.Issue.CreatedAt}
+	case err == nil:
+		return nil, errors.New("linear didn't create the issue")
+	case errors.As(err, &apiErr):
+		return nil, err // Linear answered, so nothing was created
+	default:
+		existing, getErr := c.getIssue(ctx, id)
+		if getErr != nil {
+			return nil, err
+		}
+		page = &notion.CreatedPage{ID: existing.ID, URL: existing.URL, CreatedTime: existing.CreatedAt, Recovered: true}
+	}
+
+	c.linkCustomers(ctx, page.ID, sub.CustomerOrgIDs, nil)
+	logging.FromContext(ctx, c.logger).Debug("created linear issue",
+		zap.String("issue_id", page.ID),
+		zap.String("issue_url", page.URL),
+		zap.Bool("recovered", page.Recovered),
+	)
+	return page, nil
+}
+
+// issueInput builds the issue fields of a submission, shared by
+// IssueCreateInput and IssueUpdateInput.
+func (c *Client) issueInput(sub submission.Submission) (map[string]any, error) {
+	if strings.TrimSpace(sub.Title) == "" {
+		return nil, errors.New("title is required")
+	}
+	set := c.labels.Load()
+	if set == nil {
+		return nil, errors.New("linear labels aren't loaded yet (SyncSchema)")
+	}
+	teamID, err := c.teamID(sub.ProductArea)
+	if err != nil {
+		return nil, err
+	}
+
+	themeID, ok := set.id(c.config.ThemeLabelGroup, sub.Theme, teamID)
+	if !ok {
+		return nil, fmt.Errorf("invalid theme %q: no such label in %q", sub.Theme, c.config.ThemeLabelGroup)
+	}
+	areaID, ok := set.id(c.config.ProductAreaLabelGroup, sub.ProductArea, teamID)
+	if !ok {
+		return nil, fmt.Errorf("invalid product area %q: no such label in %q", sub.ProductArea, c.config.ProductAreaLabelGroup)
+	}
+
+	input := map[string]any{
+		"teamId":      teamID,
+		"title":       sub.Title,
+		"description": description(sub),
+		"labelIds":    []string{themeID, areaID},
+	}
+	if sub.SubmitterNotionID != "" {
+		input["subscriberIds"] = []string{sub.SubmitterNotionID}
+	}
+	return input, nil
+}
+
+// description returns the Markdown description of a submission's issue: the
+// comments, then the details after descriptionFooter.
+func description(sub submission.Submission) string {
+	var details []string
+	if len(sub.CustomerOrgs) > 0 {
+		details = append(details, "**Customers:** "+strings.Join(sub.CustomerOrgs, ", "))
+	}
+	for _, artifact := range sub.Artifacts {
+		title := artifact.Title
+		if title == "" {
+			title = artifact.URL
+		}
+		details = append(details, fmt.Sprintf("**Artifact:** [%s](%s)", title, artifact.URL))
+	}
+	for _, name := range slices.Sorted(maps.Keys(sub.Extra)) {
+		value := sub.Extra[name]
+		text := value.Text
+		switch value.Type {
+		case submission.TypeDate:
+			text = value.Date
+		case submission.TypeSelect, submission.TypeMultiSelect:
+			text = strings.Join(value.Values, ", ")
+		}
+		if text != "" {
+			details = append(details, fmt.Sprintf("**%s:** %s", name, text))
+		}
+	}
+	if sub.Source.SlackPermalink != "" {
+		details = append(details, fmt.Sprintf("**Slack:** [message](%s)", sub.Source.SlackPermalink))
+	}
+	if sub.Source.SubmitterEmail != "" {
+		details = append(details, fmt.Sprintf("Submitted by %s via %s", sub.Source.SubmitterEmail, sub.Source.ChannelName()))
+	}
+	if len(details) == 0 {
+		return sub.Comments
+	}
+	return sub.Comments + descriptionFooter + strings.Join(details, "\n\n")
+}
+
+// comments returns the comments part of a description written by description.
+func comments(description string) string {
+	if i := strings.LastIndex(description, descriptionFooter); i >= 0 {
+		return description[:i]
+	}
+	return description
+}
+
+// linkCustomers links customers to an issue as customer requests, skipping
+// those in linked. Failures are only logged: the issue already exists.
+func (c *Client) linkCustomers(ctx context.Context, issueID string, customerIDs, linked []string) {
+	for _, customerID := range customerIDs {
+		if slices.Contains(linked, customerID) {
+			continue
+		}
+		err := c.mutate(ctx, `mutation CustomerNeedCreate($input: CustomerNeedCreateInput!) {
+  customerNeedCreate(input: $input) { success }
+}`, map[string]any{"input": map[string]any{"issueId": issueID, "customerId": customerID}}, nil)
+		if err != nil {
+			logging.FromContext(ctx, c.logger).Warn("failed to link customer to linear issue",
+				zap.String("issue_id", issueID),
+				zap.String("customer_id", customerID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// GetSubmission reads an issue back into a submission, so it can be edited.
+func (c *Client) GetSubmission(pageID string) (*notion.SubmissionPage, error) {
+	i, err := c.getIssue(context.Background(), pageID)
+	if err != nil {
+		return nil, err
+	}
+	page := &notion.SubmissionPage{
+		ID:       i.ID,
+		URL:      i.URL,
+		Archived: i.removed(),
+		Submission: submission.Submission{
+			Title:          i.Title,
+			Comments:       comments(i.Description),
+			CustomerOrgIDs: i.customerIDs(),
+		},
+	}
+	if themes := i.labelsIn(c.config.ThemeLabelGroup); len(themes) > 0 {
+		page.Submission.Theme = themes[0]
+	}
+	if areas := i.labelsIn(c.config.ProductAreaLabelGroup); len(areas) > 0 {
+		page.Submission.ProductArea = areas[0]
+	}
+	for _, subscriber := range i.Subscribers.Nodes {
+		page.Submitters = append(page.Submitters, subscriber.ID)
+	}
+	return page, nil
+}
+
+// UpdateSubmission rewrites an issue's title, description, labels and team
+// from an edited submission and links newly selected customers. Customers
+// removed in the edit stay linked, since Linear tracks their requests separately.
+func (c *Client) UpdateSubmission(ctx context.Context, pageID string, sub submission.Submission) (*notion.CreatedPage, error) {
+	input, err := c.issueInput(sub)
+	if err != nil {
+		return nil, err
+	}
+	delete(input, "subscriberIds") // The submitter subscribed when creating it
+	current, err := c.getIssue(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		IssueUpdate struct {
+			Success bool `json:"success"`
+			Issue   struct {
+				ID        string    `json:"id"`
+				URL       string    `json:"url"`
+				CreatedAt time.Time `json:"createdAt"`
+			} `json:"issue"`
+		} `json:"issueUpdate"`
+	}
+	err = c.mutate(ctx, `mutation IssueUpdate($id: String!, $input: IssueUpdateInput!) {
+  issueUpdate(id: $id, input: $input) { success issue { id url createdAt } }
+}`, map[string]any{"id": pageID, "input": input}, &data)
+	if err != nil {
+		return nil, err
+	}
+	if !data.IssueUpdate.Success {
+		return nil, errors.New("linear didn't update the issue")
+	}
+
+	c.linkCustomers(ctx, pageID, sub.CustomerOrgIDs, current.customerIDs())
+	return &notion.CreatedPage{ID: data.IssueUpdate.Issue.ID, URL: data.IssueUpdate.Issue.URL, CreatedTime: data.IssueUpdate.Issue.CreatedAt}, nil
+}
+
+// GetPageStatus returns an issue's workflow state, used for follow-ups.
+func (c *Client) GetPageStatus(pageID string) (*notion.PageStatus, error) {
+	i, err := c.getIssue(context.Background(), pageID)
+	if err != nil {
+		return nil, err
+	}
+	return &notion.PageStatus{
+		ID:             i.ID,
+		URL:            i.URL,
+		Title:          i.Title,
+		Status:         i.State.Name,
+		Archived:       i.removed(),
+		LastEditedTime: i.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,127 @@
+package linear
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+)
+
+// maxIdeaQueryPages bounds how many result pages one QueryIdeas call reads,
+// like the Notion backend's limit.
+const maxIdeaQueryPages = 10
+
+// QueryIdeas lists the issues of the configured teams matching filter, in
+// creation order. Pagination is followed from cursor for up to
+// maxIdeaQueryPages pages; if more matches remain, the result is Truncated
+// and NextCursor continues the query. Archived issues are skipped.
+//
+// Only creation order is supported: sorting by another field is an error.
+func (c *Client) QueryIdeas(filter notion.IdeasFilter, sort notion.Sort, cursor string) (*notion.IdeaQueryResult, error) {
+	if sort.Property != "" {
+		return nil, errors.New("the Linear backend only sorts ideas by creation time")
+	}
+	order := "Descending"
+	if sort.Ascending {
+		order = "Ascending"
+	}
+
+	variables := map[string]any{
+		"first":  constants.LinearPageSize,
+		"filter": c.issueFilter(filter),
+		"sort":   []any{map[string]any{"createdAt": map[string]any{"order": order}}},
+	}
+
+	result := &notion.IdeaQueryResult{}
+	for page := 0; page < maxIdeaQueryPages; page++ {
+		if cursor != "" {
+			variables["after"] = cursor
+		}
+		var data struct {
+			Issues struct {
+				Nodes    []issue  `json:"nodes"`
+				PageInfo pageInfo `json:"pageInfo"`
+			} `json:"issues"`
+		}
+		if err := c.query(context.Background(), issuesQuery, variables, &data); err != nil {
+			return nil, fmt.Errorf("failed to query issues: %w", err)
+		}
+		for i := range data.Issues.Nodes {
+			if data.Issues.Nodes[i].removed() {
+				continue
+			}
+			result.Ideas = append(result.Ideas, c.summary(&data.Issues.Nodes[i]))
+		}
+		cursor = data.Issues.PageInfo.EndCursor
+		if !data.Issues.PageInfo.HasNextPage || cursor == "" {
+			result.Total = len(result.Ideas)
+			return result, nil
+		}
+	}
+	result.Total = len(result.Ideas)
+	result.Truncated = true
+	result.NextCursor = cursor
+	return result, nil
+}
+
+// issuesQuery lists issues matching a filter.
+const issuesQuery = `query Issues($first: Int!, $after: String, $filter: IssueFilter, $sort: [IssueSortInput!]) {
+  issues(first: $first, after: $after, filter: $filter, sort: $sort) {
+    nodes { ` + issueFields + ` }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+
+// issueFilter converts an ideas filter to a Linear IssueFilter on the configured teams.
+func (c *Client) issueFilter(f notion.IdeasFilter) map[string]any {
+	filters := []any{map[string]any{"team": map[string]any{"id": map[string]any{"in": c.teamIDs()}}}}
+	if f.SubmitterID != "" {
+		filters = append(filters, map[string]any{"subscribers": map[string]any{"some": map[string]any{"id": map[string]any{"eq": f.SubmitterID}}}})
+	}
+	for _, name := range []string{f.Theme, f.ProductArea} {
+		if name != "" {
+			filters = append(filters, map[string]any{"labels": map[string]any{"some": map[string]any{"name": map[string]any{"eq": name}}}})
+		}
+	}
+	if f.CustomerOrgID != "" {
+		filters = append(filters, map[string]any{"needs": map[string]any{"some": map[string]any{"customer": map[string]any{"id": map[string]any{"eq": f.CustomerOrgID}}}}})
+	}
+	if !f.CreatedAfter.IsZero() {
+		filters = append(filters, map[string]any{"createdAt": map[string]any{"gte": f.CreatedAfter.UTC().Format(time.RFC3339)}})
+	}
+	if !f.CreatedBefore.IsZero() {
+		filters = append(filters, map[string]any{"createdAt": map[string]any{"lt": f.CreatedBefore.UTC().Format(time.RFC3339)}})
+	}
+	return map[string]any{"and": filters}
+}
+
+// CustomerIdeas returns the issues a customer is linked to, newest first (see
+// QueryIdeas). Only the first limit ideas are returned (limit <= 0 returns all).
+func (c *Client) CustomerIdeas(customerPageID string, limit int) (*notion.IdeaQueryResult, error) {
+	result, err := c.QueryIdeas(notion.IdeasFilter{CustomerOrgID: customerPageID}, notion.NewestFirst, "")
+	if err != nil {
+		return nil, err
+	}
+	return limitIdeas(result, limit), nil
+}
+
+// SubmitterIdeas returns the issues a Linear user submitted (is subscribed
+// to), newest first, like CustomerIdeas.
+func (c *Client) SubmitterIdeas(notionUserID string, limit int) (*notion.IdeaQueryResult, error) {
+	result, err := c.QueryIdeas(notion.IdeasFilter{SubmitterID: notionUserID}, notion.NewestFirst, "")
+	if err != nil {
+		return nil, err
+	}
+	return limitIdeas(result, limit), nil
+}
+
+// limitIdeas trims a result to its first n ideas (n <= 0 keeps all). Total is unchanged.
+func limitIdeas(result *notion.IdeaQueryResult, n int) *notion.IdeaQueryResult {
+	if n > 0 && len(result.Ideas) > n {
+		result.Ideas = result.Ideas[:n]
+	}
+	return result
+}
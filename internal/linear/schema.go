@@ -0,0 +1,207 @@
+package linear
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// label is a child label of the theme or product area label group.
+type label struct {
+	ID     string
+	Name   string
+	Group  string // Name of the parent label group
+	TeamID string // Team the label belongs to; empty for workspace labels
+}
+
+// labelSet holds the theme and product area labels.
+type labelSet struct {
+	themes       []string           // Theme options, in the order Linear lists them
+	productAreas []string           // Product area options
+	byName       map[string][]label // Label name -> labels with that name (one per team at most)
+}
+
+// id returns the ID of the label named name in group that teamID can use:
+// the team's own label, otherwise a workspace label.
+func (s *labelSet) id(group, name, teamID string) (string, bool) {
+	var workspaceID string
+	for _, l := range s.byName[name] {
+		if l.Group != group {
+			continue
+		}
+		if l.TeamID == teamID {
+			return l.ID, true
+		}
+		if l.TeamID == "" {
+			workspaceID = l.ID
+		}
+	}
+	return workspaceID, workspaceID != ""
+}
+
+// InitializeDataSources resolves the configured team keys to team IDs.
+func (c *Client) InitializeDataSources() error {
+	var data struct {
+		Teams struct {
+			Nodes []struct {
+				ID  string `json:"id"`
+				Key string `json:"key"`
+			} `json:"nodes"`
+		} `json:"teams"`
+	}
+	if err := c.query(context.Background(), `query Teams { teams(first: 250) { nodes { id key } } }`, nil, &data); err != nil {
+		return fmt.Errorf("failed to fetch teams: %w", err)
+	}
+
+	byKey := make(map[string]string, len(data.Teams.Nodes))
+	for _, team := range data.Teams.Nodes {
+		byKey[strings.ToUpper(team.Key)] = team.ID
+	}
+	teams := make(map[string]string)
+	for _, key := range c.teamKeys() {
+		id, ok := byKey[strings.ToUpper(key)]
+		if !ok {
+			return fmt.Errorf("team %q not found (or the API key can't access it)", key)
+		}
+		teams[strings.ToUpper(key)] = id
+	}
+	c.teams.Store(&teams)
+
+	c.logger.Info("resolved Linear teams", zap.Any("teams", teams))
+	return nil
+}
+
+// teamKeys returns the default team and the teams product areas are routed to.
+func (c *Client) teamKeys() []string {
+	keys := []string{c.config.Team}
+	for _, key := range c.config.ProductAreaTeams {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// teamID returns the ID of the team ideas of a product area are created in.
+func (c *Client) teamID(productArea string) (string, error) {
+	teams := c.teams.Load()
+	if teams == nil {
+		return "", errors.New("linear teams aren't resolved yet (InitializeDataSources)")
+	}
+	key := c.config.Team
+	if routed, ok := c.config.ProductAreaTeams[productArea]; ok {
+		key = routed
+	}
+	return (*teams)[strings.ToUpper(key)], nil
+}
+
+// teamIDs returns the IDs of every configured team, whose issues are queried.
+func (c *Client) teamIDs() []string {
+	teams := c.teams.Load()
+	if teams == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(*teams))
+	for _, id := range *teams {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// SyncSchema loads the theme and product area labels and returns them as the
+// form's Theme and Product Area selects. While either group has no labels,
+// submissions are rejected (see SchemaError). On failure the current labels are kept.
+func (c *Client) SyncSchema() ([]notion.FormProperty, error) {
+	groups := []string{c.config.ThemeLabelGroup, c.config.ProductAreaLabelGroup}
+	set := &labelSet{byName: make(map[string][]label)}
+	err := c.paginate(context.Background(), `query Labels($first: Int!, $after: String, $groups: [String!]) {
+  issueLabels(first: $first, after: $after, filter: { parent: { name: { in: $groups } } }) {
+    nodes { id name parent { name } team { id } }
+    pageInfo { hasNextPage endCursor }
+  }
+}`, "issueLabels", map[string]any{"groups": groups}, func(raw json.RawMessage) error {
+		var nodes []struct {
+			ID     string `json:"id"`
+			Name   string `json:"name"`
+			Parent *struct {
+				Name string `json:"name"`
+			} `json:"parent"`
+			Team *struct {
+				ID string `json:"id"`
+			} `json:"team"`
+		}
+		if err := json.Unmarshal(raw, &nodes); err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			if node.Parent == nil {
+				continue
+			}
+			l := label{ID: node.ID, Name: node.Name, Group: node.Parent.Name}
+			if node.Team != nil {
+				l.TeamID = node.Team.ID
+			}
+			set.byName[l.Name] = append(set.byName[l.Name], l)
+			switch {
+			case l.Group == c.config.ThemeLabelGroup && !slices.Contains(set.themes, l.Name):
+				set.themes = append(set.themes, l.Name)
+			case l.Group == c.config.ProductAreaLabelGroup && !slices.Contains(set.productAreas, l.Name):
+				set.productAreas = append(set.productAreas, l.Name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch labels: %w", err)
+	}
+
+	var problems []error
+	if len(set.themes) == 0 {
+		problems = append(problems, fmt.Errorf("label group %q has no labels", c.config.ThemeLabelGroup))
+	}
+	if len(set.productAreas) == 0 {
+		problems = append(problems, fmt.Errorf("label group %q has no labels", c.config.ProductAreaLabelGroup))
+	}
+	c.labels.Store(set)
+	c.setSchemaError(errors.Join(problems...))
+
+	return []notion.FormProperty{
+		{Name: constants.FieldArtifacts, Type: submission.TypeRichText},
+		{Name: constants.FieldProductArea, Type: submission.TypeSelect, Options: set.productAreas},
+		{Name: constants.FieldThemeCategory, Type: submission.TypeMultiSelect, Options: set.themes},
+	}, nil
+}
+
+// SchemaError returns why submissions are rejected (a label group without
+// labels), or nil. Before the first SyncSchema, submissions are accepted.
+func (c *Client) SchemaError() error {
+	if err := c.schemaErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// setSchemaError updates the submission guard, logging when it flips.
+func (c *Client) setSchemaError(err error) {
+	var previous *error
+	if err == nil {
+		previous = c.schemaErr.Swap(nil)
+	} else {
+		previous = c.schemaErr.Swap(&err)
+	}
+	switch {
+	case err != nil && previous == nil:
+		c.logger.Error("linear labels are incomplete, rejecting submissions until they are fixed", zap.Error(err))
+	case err == nil && previous != nil:
+		c.logger.Info("linear labels are complete again, accepting submissions")
+	}
+}
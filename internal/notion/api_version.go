@@ -0,0 +1,51 @@
+package notion
+
+import (
+	"fmt"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+)
+
+// SetAPIVersion sets the Notion-Version the client sends:
+// constants.NotionAPIVersion (the default), or constants.NotionAPIVersionLegacy
+// for integrations still pinned to the API before multi-source databases.
+//
+// With the legacy version, databases have a single schema and are queried and
+// created in directly: InitializeDataSources resolves each database to itself,
+// so the data source IDs the client keeps everywhere else are database IDs,
+// and dataSourceEndpoint and pageParent route them to the database endpoints.
+//
+// Call it before InitializeDataSources; like SetMetrics, it isn't synchronized
+// with requests in flight.
+func (c *Client) SetAPIVersion(version string) {
+	c.apiVersion = version
+}
+
+// APIVersion returns the Notion-Version the client sends.
+func (c *Client) APIVersion() string {
+	return c.apiVersion
+}
+
+// legacyAPI reports whether the client speaks the Notion API before data
+// sources (constants.NotionAPIVersionLegacy).
+func (c *Client) legacyAPI() bool {
+	return c.apiVersion == constants.NotionAPIVersionLegacy
+}
+
+// dataSourceEndpoint returns the endpoint of a data source followed by path
+// (e.g. "/query"), or of the database standing in for it with the legacy API.
+func (c *Client) dataSourceEndpoint(dataSourceID, path string) string {
+	if c.legacyAPI() {
+		return fmt.Sprintf("%s/databases/%s%s", constants.NotionAPIBaseURL, dataSourceID, path)
+	}
+	return fmt.Sprintf("%s/data_sources/%s%s", constants.NotionAPIBaseURL, dataSourceID, path)
+}
+
+// pageParent returns the parent of pages created in a data source, or in the
+// database standing in for it with the legacy API.
+func (c *Client) pageParent(dataSourceID string) Parent {
+	if c.legacyAPI() {
+		return Parent{Type: "database_id", DatabaseID: dataSourceID}
+	}
+	return Parent{Type: "data_source_id", DataSourceID: dataSourceID}
+}
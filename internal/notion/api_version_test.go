@@ -0,0 +1,154 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// versionTransport records the Notion-Version header of each request.
+type versionTransport struct {
+	next     http.RoundTripper
+	versions []string
+}
+
+func (v *versionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	v.versions = append(v.versions, req.Header.Get("Notion-Version"))
+	return v.next.RoundTrip(req)
+}
+
+// TestLegacyAPIVersion tests that with the legacy API, databases stand in for
+// their data sources: they are queried and created in directly
+func TestLegacyAPIVersion(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	if client.APIVersion() != constants.NotionAPIVersion {
+		t.Errorf("APIVersion() = %q, want the default %q", client.APIVersion(), constants.NotionAPIVersion)
+	}
+	client.SetAPIVersion(constants.NotionAPIVersionLegacy)
+
+	sequence := &sequenceTransport{results: []func() (*http.Response, error){
+		respond(http.StatusOK, `{"object":"database","id":"ideas-db","properties":{}}`),
+		respond(http.StatusOK, `{"object":"database","id":"customers-db","properties":{}}`),
+		respond(http.StatusOK, customersQueryResponse([2]string{"Acme", "page-acme"})),
+		respond(http.StatusOK, `{"id":"page-1","url":"https://notion.so/page-1"}`),
+	}}
+	transport := &versionTransport{next: sequence}
+	client.httpClient = &http.Client{Transport: transport}
+
+	if err := client.InitializeDataSources(); err != nil {
+		t.Fatalf("InitializeDataSources() error = %v", err)
+	}
+	if err := client.InitializeCustomers(); err != nil {
+		t.Fatalf("InitializeCustomers() error = %v", err)
+	}
+	if _, err := client.createNotionPage(context.Background(), map[string]Property{}); err != nil {
+		t.Fatalf("createNotionPage() error = %v", err)
+	}
+
+	wantPaths := []string{
+		"GET /v1/databases/ideas-db",
+		"GET /v1/databases/customers-db",
+		"POST /v1/databases/customers-db/query",
+		"POST /v1/pages",
+	}
+	if !reflect.DeepEqual(sequence.paths, wantPaths) {
+		t.Errorf("requests = %v, want %v", sequence.paths, wantPaths)
+	}
+	for i, version := range transport.versions {
+		if version != constants.NotionAPIVersionLegacy {
+			t.Errorf("request %d sent Notion-Version %q, want %q", i, version, constants.NotionAPIVersionLegacy)
+		}
+	}
+
+	var created CreatePageRequest
+	if err := json.Unmarshal([]byte(sequence.bodies[3]), &created); err != nil {
+		t.Fatalf("invalid create page request: %v", err)
+	}
+	if want := (Parent{Type: "database_id", DatabaseID: "ideas-db"}); created.Parent != want {
+		t.Errorf("parent = %+v, want %+v", created.Parent, want)
+	}
+	if names := client.GetValidCustomers(); !reflect.DeepEqual(names, []string{"Acme"}) {
+		t.Errorf("GetValidCustomers() = %v, want [Acme]", names)
+	}
+}
+
+// TestPageParent tests the parent of created pages for each API version
+func TestPageParent(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	if got, want := client.pageParent("ideas-ds"), (Parent{Type: "data_source_id", DataSourceID: "ideas-ds"}); got != want {
+		t.Errorf("pageParent() = %+v, want %+v", got, want)
+	}
+	body, _ := json.Marshal(client.pageParent("ideas-ds"))
+	if string(body) != `{"type":"data_source_id","data_source_id":"ideas-ds"}` {
+		t.Errorf("parent JSON = %s, want only the data source ID", body)
+	}
+
+	client.SetAPIVersion(constants.NotionAPIVersionLegacy)
+	body, _ = json.Marshal(client.pageParent("ideas-db"))
+	if string(body) != `{"type":"database_id","database_id":"ideas-db"}` {
+		t.Errorf("legacy parent JSON = %s, want only the database ID", body)
+	}
+}
+
+// TestListAccessibleDatabases_LegacyAPI tests that the legacy API searches for
+// databases, each its own data source
+func TestListAccessibleDatabases_LegacyAPI(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.SetAPIVersion(constants.NotionAPIVersionLegacy)
+	sequence := &sequenceTransport{results: []func() (*http.Response, error){
+		respond(http.StatusOK, `{"results":[
+			{"object":"database","id":"ideas-db","title":[{"plain_text":"Ideas"}],"parent":{"type":"workspace","workspace":true}}
+		],"has_more":false}`),
+	}}
+	client.httpClient = &http.Client{Transport: sequence}
+
+	databases, err := client.ListAccessibleDatabases()
+	if err != nil {
+		t.Fatalf("ListAccessibleDatabases() error = %v", err)
+	}
+	if len(databases) != 1 || databases[0].DatabaseID != "ideas-db" || databases[0].DataSourceID != "ideas-db" || databases[0].Role != DatabaseRoleIdeas {
+		t.Errorf("databases = %+v, want the ideas database as its own data source", databases)
+	}
+
+	var request struct {
+		Filter struct {
+			Value string `json:"value"`
+		} `json:"filter"`
+	}
+	if err := json.Unmarshal([]byte(sequence.bodies[0]), &request); err != nil || request.Filter.Value != "database" {
+		t.Errorf("search filter = %q (%v), want database", request.Filter.Value, err)
+	}
+}
+
+// TestGetFormSchema_LegacyAPI tests that relations to the customers database
+// are recognized by database ID with the legacy API
+func TestGetFormSchema_LegacyAPI(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.SetAPIVersion(constants.NotionAPIVersionLegacy)
+	client.dataSourceID = "ideas-db"
+	client.customersDataSourceID = "1f2e3d4c5b6a79808f9e0d1c2b3a4958"
+	client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+		"GET /v1/databases/ideas-db": jsonResponse(http.StatusOK, `{"object":"database","properties":{
+			"Customer Org": {"type":"relation","relation":{"database_id":"1f2e3d4c-5b6a-7980-8f9e-0d1c2b3a4958"}},
+			"Related Epic": {"type":"relation","relation":{"database_id":"epics-db"}}
+		}}`),
+	}}}
+
+	schema, err := client.GetFormSchema()
+	if err != nil {
+		t.Fatalf("GetFormSchema() error = %v", err)
+	}
+
+	want := []FormProperty{
+		{Name: "Customer Org", Type: "relation", RelatesToCustomers: true},
+		{Name: "Related Epic", Type: "relation"},
+	}
+	if !reflect.DeepEqual(schema, want) {
+		t.Errorf("GetFormSchema() = %+v, want %+v", schema, want)
+	}
+}
@@ -0,0 +1,309 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// CacheEntryStatus is the outcome of a targeted cache entry refresh.
+type CacheEntryStatus string
+
+const (
+	CacheEntryAdded     CacheEntryStatus = "added"     // Found in Notion, wasn't cached
+	CacheEntryUpdated   CacheEntryStatus = "updated"   // Found in Notion, cached with a different name or ID
+	CacheEntryUnchanged CacheEntryStatus = "unchanged" // Found in Notion, already cached as is
+	CacheEntryRemoved   CacheEntryStatus = "removed"   // Not found in Notion, dropped from the cache
+	CacheEntryNotFound  CacheEntryStatus = "not_found" // Neither in Notion nor in the cache
+)
+
+// CacheEntryResult describes a targeted cache entry refresh.
+type CacheEntryResult struct {
+	Status  CacheEntryStatus `json:"status"`
+	Key     string           `json:"key"`             // Customer name or normalized email as cached
+	ID      string           `json:"id,omitempty"`    // Customer page ID or Notion user ID
+	Guest   bool             `json:"guest,omitempty"` // User is outside ALLOWED_EMAIL_DOMAINS
+	Version uint64           `json:"version"`         // Snapshot version after the refresh
+}
+
+// RefreshCustomer looks up a single customer by name in the Customers database
+// and upserts it into the cache, or drops it if it no longer exists. It is the
+// targeted alternative to a full refresh, e.g. right after adding a customer
+// page so it shows up in the customer selects immediately.
+//
+// Names match exactly first, then case-insensitively. A cached entry for the
+// same page under another name (a renamed customer) is replaced.
+func (c *Client) RefreshCustomer(name string) (*CacheEntryResult, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("customer name is required")
+	}
+
+	start := time.Now()
+	found, err := c.findCustomerPages(name)
+	c.recordNotionRequest("refresh_customer", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up customer %q: %w", name, err)
+	}
+
+	canonical, pageID := "", ""
+	if id, ok := found.customers[name]; ok {
+		canonical, pageID = name, id
+	} else {
+		for candidate, id := range found.customers {
+			if strings.EqualFold(candidate, name) {
+				canonical, pageID = candidate, id
+				break
+			}
+		}
+	}
+
+	var next *CacheSnapshot
+	c.cacheMu.Lock()
+	defer func() {
+		c.cacheMu.Unlock()
+		// Saved after unlocking: the save takes targetMu, which database switches hold while replacing the cache
+		if next != nil {
+			c.saveSnapshotFile()
+		}
+	}()
+
+	current := c.cache.Load()
+	customers, details := maps.Clone(current.customers), maps.Clone(current.customerDetails)
+	result := &CacheEntryResult{Key: canonical, ID: pageID}
+
+	if pageID == "" {
+		// Only by name: an alias missing from the titles doesn't mean its customer is gone
+		cached, cachedID, cachedFound := current.findCustomerByName(name)
+		if !cachedFound {
+			result.Status, result.Key, result.Version = CacheEntryNotFound, name, current.Version
+			return result, nil
+		}
+		delete(customers, cached)
+		delete(details, cachedID)
+		result.Status, result.Key = CacheEntryRemoved, cached
+	} else {
+		cachedID, cachedFound := customers[canonical]
+		switch {
+		case !cachedFound:
+			result.Status = CacheEntryAdded
+		case cachedID != pageID:
+			result.Status = CacheEntryUpdated
+		case !customerDetailsEqual(details[pageID], found.details[pageID]):
+			result.Status = CacheEntryUpdated
+		default:
+			result.Status = CacheEntryUnchanged
+		}
+		// Drop the page's old name if it was renamed
+		for cachedName, id := range customers {
+			if id == pageID && cachedName != canonical {
+				delete(customers, cachedName)
+				result.Status = CacheEntryUpdated
+			}
+		}
+		customers[canonical] = pageID
+		if customer, ok := found.details[pageID]; ok {
+			details[pageID] = customer
+		} else {
+			delete(details, pageID)
+		}
+	}
+
+	if result.Status == CacheEntryUnchanged {
+		result.Version = current.Version
+		return result, nil
+	}
+
+	next = newCacheSnapshot(customers, details, current.users, current.guests, current.Version+1)
+	c.cache.Store(next)
+	result.Version = next.Version
+	if c.metrics != nil {
+		c.metrics.ClientCacheSize.Set(float64(next.CustomerCount()))
+		c.recordCacheChecksums(next)
+	}
+
+	c.logger.Info("refreshed customer cache entry",
+		zap.String("customer", result.Key),
+		zap.String("status", string(result.Status)),
+		zap.Uint64("version", next.Version),
+	)
+	return result, nil
+}
+
+// foundCustomers are the customer pages returned by findCustomerPages.
+type foundCustomers struct {
+	customers map[string]string          // Customer name -> Notion page ID
+	details   map[string]CustomerDetails // Notion page ID -> aliases and description, for customers that have any
+}
+
+// findCustomerPages queries the Customers database for pages whose name
+// property (the title unless set with SetCustomerProperties) contains name.
+// Notion's text filters are case-insensitive, so callers pick the exact match
+// from the results.
+func (c *Client) findCustomerPages(name string) (*foundCustomers, error) {
+	dataSourceID := c.customersSourceID()
+
+	schema, err := c.getDataSourceSchema(dataSourceID)
+	if err != nil {
+		return nil, err
+	}
+	customerProperties := c.customerSettings()
+	nameProperty, nameType := customerProperties.Name, ""
+	if nameProperty != "" {
+		nameType = schema[nameProperty]
+	} else {
+		for property, propertyType := range schema {
+			if propertyType == "title" {
+				nameProperty, nameType = property, propertyType
+			}
+		}
+	}
+	switch {
+	case nameProperty == "":
+		return nil, fmt.Errorf("customers database has no title property")
+	case nameType != "title" && nameType != "rich_text":
+		return nil, fmt.Errorf("customer name property %q is not a title or rich_text property", nameProperty)
+	}
+
+	body, err := customerProperties.queryBody("", map[string]interface{}{
+		"property": nameProperty,
+		nameType:   map[string]interface{}{"contains": name},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := c.dataSourceEndpoint(dataSourceID, "/query")
+	resp, err := c.makeNotionRequest("POST", endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var queryResponse struct {
+		Results []map[string]interface{} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&queryResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Unlisted customers aren't found, so a customer whose status changed is dropped
+	found := &foundCustomers{customers: make(map[string]string), details: make(map[string]CustomerDetails)}
+	for _, page := range queryResponse.Results {
+		pageID, _ := page["id"].(string)
+		properties, _ := page["properties"].(map[string]interface{})
+		if customerName, customer, hasDetails := customerProperties.extractCustomer(properties); customerName != "" && pageID != "" {
+			found.customers[customerName] = pageID
+			if hasDetails {
+				found.details[pageID] = customer
+			}
+		}
+	}
+	return found, nil
+}
+
+// customerDetailsEqual reports whether two customers' details are the same.
+func customerDetailsEqual(a, b CustomerDetails) bool {
+	return a.Description == b.Description && slices.Equal(a.Aliases, b.Aliases)
+}
+
+// RefreshUser looks up a single Notion user by email and upserts it into the
+// user cache, or drops it if it no longer exists.
+//
+// The Notion API can't look users up by email, so this pages through the
+// workspace users and stops at the first match; it only reads the whole user
+// list when the email isn't in Notion. Users outside ALLOWED_EMAIL_DOMAINS are
+// cached as external guests, like a full refresh does.
+func (c *Client) RefreshUser(email string) (*CacheEntryResult, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, fmt.Errorf("email is required")
+	}
+
+	start := time.Now()
+	userID, err := c.findUserByEmail(email)
+	c.recordNotionRequest("refresh_user", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user %q: %w", email, err)
+	}
+
+	var next *CacheSnapshot
+	c.cacheMu.Lock()
+	defer func() {
+		c.cacheMu.Unlock()
+		// Saved after unlocking: the save takes targetMu, which database switches hold while replacing the cache
+		if next != nil {
+			c.saveSnapshotFile()
+		}
+	}()
+
+	current := c.cache.Load()
+	users, guests := maps.Clone(current.users), maps.Clone(current.guests)
+	cachedID, cachedFound := users[email]
+	if !cachedFound {
+		cachedID, cachedFound = guests[email]
+	}
+	result := &CacheEntryResult{Key: email, ID: userID}
+
+	delete(users, email)
+	delete(guests, email)
+	switch {
+	case userID == "" && !cachedFound:
+		result.Status, result.Version = CacheEntryNotFound, current.Version
+		return result, nil
+	case userID == "":
+		result.Status = CacheEntryRemoved
+	default:
+		members, excluded := c.partitionUsers(map[string]string{email: userID})
+		maps.Copy(users, members)
+		if len(excluded) > 0 {
+			guests[email] = userID
+			result.Guest = true
+		}
+		switch {
+		case !cachedFound:
+			result.Status = CacheEntryAdded
+		case cachedID != userID || current.IsExternalGuest(email) != result.Guest:
+			result.Status = CacheEntryUpdated
+		default:
+			result.Status, result.Version = CacheEntryUnchanged, current.Version
+			return result, nil
+		}
+	}
+
+	next = newCacheSnapshot(current.customers, current.customerDetails, users, guests, current.Version+1)
+	c.cache.Store(next)
+	result.Version = next.Version
+	if c.metrics != nil {
+		c.metrics.UserCacheSize.Set(float64(next.UserCount()))
+		c.metrics.UserCacheGuests.Set(float64(next.GuestCount()))
+		c.recordCacheChecksums(next)
+	}
+
+	c.logger.Info("refreshed user cache entry",
+		zap.String("email", email),
+		zap.String("status", string(result.Status)),
+		zap.Uint64("version", next.Version),
+	)
+	return result, nil
+}
+
+// findUserByEmail pages through the workspace users until it finds email
+// (normalized). Returns an empty ID if no user has that email.
+func (c *Client) findUserByEmail(email string) (string, error) {
+	userID := ""
+	err := paginate(context.Background(), c, "find_user", c.fetchUsersPage, func(users map[string]string) bool {
+		userID = users[email]
+		return userID == ""
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch users page: %w", err)
+	}
+	return userID, nil
+}
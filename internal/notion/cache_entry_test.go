@@ -0,0 +1,206 @@
+package notion
+
+import (
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+const customersSchemaResponse = `{"properties":{"Name":{"type":"title"},"Tier":{"type":"select"}}}`
+
+func customersQueryResponse(pages ...[2]string) string {
+	body := `{"results":[`
+	for i, page := range pages {
+		if i > 0 {
+			body += ","
+		}
+		body += `{"id":"` + page[1] + `","properties":{"Name":{"type":"title","title":[{"text":{"content":"` + page[0] + `"}}]}}}`
+	}
+	return body + `],"has_more":false}`
+}
+
+func newCacheEntryTestClient(transport *sequenceTransport) *Client {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.customersDataSourceID = "customers-ds"
+	client.httpClient = &http.Client{Transport: transport}
+	client.replaceCustomers(map[string]string{"Acme": "page-acme", "Old Name": "page-renamed"}, nil)
+	client.replaceUsers(map[string]string{"alice@example.com": "user-alice"}, nil)
+	return client
+}
+
+// TestRefreshCustomer tests upserting and removing a single customer entry
+func TestRefreshCustomer(t *testing.T) {
+	tests := []struct {
+		name        string
+		lookup      string
+		results     [][2]string
+		wantStatus  CacheEntryStatus
+		wantKey     string
+		wantCached  map[string]string // name -> page ID, "" if it must not be cached
+		wantVersion uint64
+	}{
+		{
+			name:        "new customer",
+			lookup:      "Globex",
+			results:     [][2]string{{"Globex Europe", "page-globex-eu"}, {"Globex", "page-globex"}},
+			wantStatus:  CacheEntryAdded,
+			wantKey:     "Globex",
+			wantCached:  map[string]string{"Globex": "page-globex", "Globex Europe": "", "Acme": "page-acme"},
+			wantVersion: 3,
+		},
+		{
+			name:        "case-insensitive match",
+			lookup:      "globex",
+			results:     [][2]string{{"Globex", "page-globex"}},
+			wantStatus:  CacheEntryAdded,
+			wantKey:     "Globex",
+			wantCached:  map[string]string{"Globex": "page-globex"},
+			wantVersion: 3,
+		},
+		{
+			name:        "renamed customer",
+			lookup:      "New Name",
+			results:     [][2]string{{"New Name", "page-renamed"}},
+			wantStatus:  CacheEntryUpdated,
+			wantKey:     "New Name",
+			wantCached:  map[string]string{"New Name": "page-renamed", "Old Name": ""},
+			wantVersion: 3,
+		},
+		{
+			name:        "unchanged",
+			lookup:      "Acme",
+			results:     [][2]string{{"Acme", "page-acme"}},
+			wantStatus:  CacheEntryUnchanged,
+			wantKey:     "Acme",
+			wantCached:  map[string]string{"Acme": "page-acme"},
+			wantVersion: 2,
+		},
+		{
+			name:        "deleted customer",
+			lookup:      "acme",
+			wantStatus:  CacheEntryRemoved,
+			wantKey:     "Acme",
+			wantCached:  map[string]string{"Acme": ""},
+			wantVersion: 3,
+		},
+		{
+			name:        "unknown customer",
+			lookup:      "Initech",
+			wantStatus:  CacheEntryNotFound,
+			wantKey:     "Initech",
+			wantVersion: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &sequenceTransport{results: []func() (*http.Response, error){
+				respond(http.StatusOK, customersSchemaResponse),
+				respond(http.StatusOK, customersQueryResponse(tt.results...)),
+			}}
+			client := newCacheEntryTestClient(transport)
+
+			result, err := client.RefreshCustomer(tt.lookup)
+			if err != nil {
+				t.Fatalf("RefreshCustomer() error = %v", err)
+			}
+			if result.Status != tt.wantStatus || result.Key != tt.wantKey || result.Version != tt.wantVersion {
+				t.Errorf("result = %+v, want status %s, key %s, version %d", result, tt.wantStatus, tt.wantKey, tt.wantVersion)
+			}
+
+			snapshot := client.Snapshot()
+			if snapshot.Version != tt.wantVersion {
+				t.Errorf("snapshot version = %d, want %d", snapshot.Version, tt.wantVersion)
+			}
+			for name, wantID := range tt.wantCached {
+				pageID, found := snapshot.CustomerPageID(name)
+				if wantID == "" && found {
+					t.Errorf("%s is still cached", name)
+				}
+				if wantID != "" && pageID != wantID {
+					t.Errorf("%s page ID = %q, want %q", name, pageID, wantID)
+				}
+			}
+			if _, found := snapshot.NotionUserIDByEmail("alice@example.com"); !found {
+				t.Error("users were dropped")
+			}
+		})
+	}
+
+	t.Run("lookup error keeps the cache", func(t *testing.T) {
+		client := newCacheEntryTestClient(&sequenceTransport{results: []func() (*http.Response, error){
+			respond(http.StatusOK, customersSchemaResponse),
+			respond(http.StatusTooManyRequests, `{"code":"rate_limited"}`),
+		}})
+		if _, err := client.RefreshCustomer("Acme"); err == nil {
+			t.Fatal("expected error")
+		}
+		if client.Snapshot().Version != 2 {
+			t.Errorf("snapshot was replaced")
+		}
+	})
+}
+
+// TestRefreshUser tests upserting and removing a single user entry
+func TestRefreshUser(t *testing.T) {
+	firstPage := `{"results":[{"object":"user","id":"user-alice","type":"person","person":{"email":"alice@example.com"}}],"has_more":true,"next_cursor":"c2"}`
+	secondPage := `{"results":[{"object":"user","id":"user-bob","type":"person","person":{"email":"Bob@Partner.io"}}],"has_more":false}`
+
+	tests := []struct {
+		name         string
+		email        string
+		domains      []string
+		pages        []string
+		wantStatus   CacheEntryStatus
+		wantGuest    bool
+		wantRequests int
+	}{
+		{name: "found on the first page", email: "Alice@Example.com", pages: []string{firstPage}, wantStatus: CacheEntryUnchanged, wantRequests: 1},
+		{name: "new user", email: "bob@partner.io", pages: []string{firstPage, secondPage}, wantStatus: CacheEntryAdded, wantRequests: 2},
+		{name: "external guest", email: "bob@partner.io", domains: []string{"example.com"}, pages: []string{firstPage, secondPage}, wantStatus: CacheEntryAdded, wantGuest: true, wantRequests: 2},
+		{name: "removed user", email: "alice@example.com", pages: []string{secondPage}, wantStatus: CacheEntryRemoved, wantRequests: 1},
+		{name: "unknown user", email: "carol@example.com", pages: []string{secondPage}, wantStatus: CacheEntryNotFound, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &sequenceTransport{}
+			for _, page := range tt.pages {
+				transport.results = append(transport.results, respond(http.StatusOK, page))
+			}
+			client := newCacheEntryTestClient(transport)
+			client.SetAllowedEmailDomains(tt.domains)
+
+			result, err := client.RefreshUser(tt.email)
+			if err != nil {
+				t.Fatalf("RefreshUser() error = %v", err)
+			}
+			if result.Status != tt.wantStatus || result.Guest != tt.wantGuest {
+				t.Errorf("result = %+v, want status %s, guest %v", result, tt.wantStatus, tt.wantGuest)
+			}
+			if len(transport.paths) != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", len(transport.paths), tt.wantRequests)
+			}
+
+			snapshot := client.Snapshot()
+			userID, member := snapshot.NotionUserIDByEmail(tt.email)
+			switch tt.wantStatus {
+			case CacheEntryRemoved, CacheEntryNotFound:
+				if member || snapshot.IsExternalGuest(tt.email) {
+					t.Errorf("%s is still cached", tt.email)
+				}
+			default:
+				if tt.wantGuest != snapshot.IsExternalGuest(tt.email) || member == tt.wantGuest {
+					t.Errorf("member = %v, guest = %v, want guest %v", member, snapshot.IsExternalGuest(tt.email), tt.wantGuest)
+				}
+				if !tt.wantGuest && userID != result.ID {
+					t.Errorf("cached ID = %q, want %q", userID, result.ID)
+				}
+			}
+			if snapshot.CustomerCount() != 2 {
+				t.Error("customers were dropped")
+			}
+		})
+	}
+}
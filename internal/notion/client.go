@@ -18,16 +18,27 @@ package notion
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/httpclient"
+	"github.com/rudderlabs/hopperbot/pkg/logging"
 	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/requestid"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
 	"go.uber.org/zap"
 )
 
@@ -35,25 +46,44 @@ import (
 // and caching of valid customer organization names and workspace users.
 //
 // The client maintains two in-memory caches:
-// 1. customerMap: Mapping of customer organization names to Notion page IDs (for relations)
-// 2. validUsers: Mapping of email addresses to Notion user UUIDs
+// 1. Customers: Mapping of customer organization names to Notion page IDs (for relations)
+// 2. Users: Mapping of email addresses to Notion user UUIDs
 //
 // Both caches are populated during initialization and used for validation
-// and mapping in form submissions. Access to the caches is protected by a mutex
-// for thread safety.
+// and mapping in form submissions. They are held in an immutable CacheSnapshot
+// that refreshes replace atomically (see Snapshot), so readers never lock.
 //
 // Note: With Notion API v2025-09-03, databases are containers that can have multiple
 // data sources. The client discovers and uses data source IDs for all operations.
 type Client struct {
-	apiKey              string
+	apiKey              atomic.Pointer[string] // Integration secret; swapped by RotateAPIKey
+	workspaceKey        string            // Hash of the key the client was created with (see sharedCacheKey)
 	databaseID          string            // Database container ID (for discovery)
 	customersDBID       string            // Customers database container ID (for discovery)
 	dataSourceID        string            // Primary data source ID for main database
 	customersDataSourceID string          // Primary data source ID for customers database
 	httpClient          *http.Client
-	customerMap         map[string]string // Cached mapping of customer name -> Notion page ID
-	validUsers          map[string]string // Cached mapping of email -> Notion user UUID
-	cacheMu             sync.RWMutex      // Protects customerMap and validUsers
+	transport           *httpclient.Transport // httpClient's retrying, circuit-breaking transport
+	cache               atomic.Pointer[CacheSnapshot] // Current customer and user caches
+	selectOptions       atomic.Pointer[SelectOptions] // Synced core select options; nil until the first SyncSchema
+	schemaErr           atomic.Pointer[error]         // Why submissions are blocked (see SchemaError); nil accepts them
+	cacheMu             sync.Mutex        // Serializes snapshot replacement (readers don't lock)
+	allowedDomains      []string          // Email domains mapped as submitters (empty allows all)
+	sourceURLProperty   string            // URL property Slack permalinks are written to (see SetSourceURLProperty); empty disables
+	customerProperties  CustomerProperties // Customers database properties making up customers (see SetCustomerProperties)
+	settingsMu          sync.RWMutex      // Protects allowedDomains, sourceURLProperty and customerProperties (reloadable)
+	targetMu            sync.RWMutex      // Protects database and data source IDs (switchable at runtime)
+	createBackoff       time.Duration     // Initial backoff between page creation retries
+	apiVersion          string            // Notion-Version sent with requests (see SetAPIVersion)
+	lastPermissions     *PermissionReport // Most recent CheckPermissions result
+	lastCompatibility   *CompatibilityReport // Most recent CheckCompatibility result
+	permissionsMu       sync.RWMutex      // Protects lastPermissions and lastCompatibility
+	sharedCache         SharedCache       // Caches shared with other replicas; nil when CACHE_BACKEND=memory
+	sharedCacheMaxAge   time.Duration     // How long a shared cache is used before it's refreshed from Notion
+	sharedCacheWait     time.Duration     // How long to wait for another replica's refresh
+	sharedCachePoll     time.Duration     // How often to check for another replica's refresh
+	snapshotPath        string            // Caches are saved here after refreshes (CACHE_SNAPSHOT_FILE); empty disables
+	snapshotFileMu      sync.Mutex        // Serializes snapshot file writes
 	logger              *zap.Logger
 	metrics             *metrics.Metrics
 }
@@ -69,17 +99,20 @@ type Client struct {
 // The client must call InitializeCustomers() and InitializeUsers() before accepting
 // form submissions to populate the caches.
 func NewClient(apiKey, databaseID, customersDBID string, logger *zap.Logger) *Client {
-	return &Client{
-		apiKey:        apiKey,
+	httpClient, transport := httpclient.NewClient("notion", constants.DefaultHTTPTimeout, logger)
+	c := &Client{
 		databaseID:    databaseID,
 		customersDBID: customersDBID,
-		httpClient: &http.Client{
-			Timeout: constants.DefaultHTTPTimeout,
-		},
-		customerMap: make(map[string]string),
-		validUsers:  make(map[string]string),
-		logger:      logger,
-	}
+		httpClient:    httpClient,
+		transport:     transport,
+		createBackoff: constants.NotionCreateInitialBackoff,
+		apiVersion:    constants.NotionAPIVersion,
+		logger:        logger,
+	}
+	c.apiKey.Store(&apiKey)
+	c.workspaceKey = hashAPIKey(apiKey)
+	c.cache.Store(newCacheSnapshot(nil, nil, nil, nil, 0))
+	return c
 }
 
 // discoverDataSourceID fetches the data source ID for a given database container.
@@ -93,7 +126,8 @@ func NewClient(apiKey, databaseID, customersDBID string, logger *zap.Logger) *Cl
 // - dbName: A descriptive name for logging (e.g., "main database", "customers database")
 //
 // Returns the first data source ID, or an error if the database has no data sources.
-// Logs a warning if the database has multiple data sources.
+// Logs a warning if the database has multiple data sources. With the legacy API
+// (see SetAPIVersion) the database, once read, stands in for its data source.
 func (c *Client) discoverDataSourceID(databaseID, dbName string) (string, error) {
 	endpoint := fmt.Sprintf("%s/databases/%s", constants.NotionAPIBaseURL, databaseID)
 	resp, err := c.makeNotionRequest("GET", endpoint, nil)
@@ -107,6 +141,15 @@ func (c *Client) discoverDataSourceID(databaseID, dbName string) (string, error)
 		return "", fmt.Errorf("failed to decode database response: %w", err)
 	}
 
+	if c.legacyAPI() {
+		c.logger.Info("using database as its data source with the legacy Notion API",
+			zap.String("database_id", databaseID),
+			zap.String("database_name", dbName),
+			zap.String("api_version", c.apiVersion),
+		)
+		return databaseID, nil
+	}
+
 	if len(dbResponse.DataSources) == 0 {
 		return "", fmt.Errorf("database %s has no data sources", databaseID)
 	}
@@ -147,26 +190,37 @@ func (c *Client) discoverDataSourceID(databaseID, dbName string) (string, error)
 //
 // Returns an error if the Notion API call fails or the response cannot be parsed.
 func (c *Client) InitializeCustomers() error {
+	if shared := c.loadSharedCache("customers"); shared != nil {
+		c.publishCustomers(shared.Entries, shared.Details)
+		return nil
+	}
+
 	start := time.Now()
 
-	customerMap, err := c.fetchCustomersFromDatabase()
+	customerMap, details, err := c.fetchCustomersFromDatabase()
 	c.recordNotionRequest("initialize_customers", start, err)
 
 	if err != nil {
 		return fmt.Errorf("failed to fetch customers: %w", err)
 	}
 
-	c.cacheMu.Lock()
-	c.customerMap = customerMap
-	mapSize := len(c.customerMap)
-	c.cacheMu.Unlock()
+	c.saveSharedCache("customers", sharedEntry{Entries: customerMap, Details: details})
+	c.publishCustomers(customerMap, details)
+	return nil
+}
+
+// publishCustomers replaces the cached customers and updates the cache metrics.
+func (c *Client) publishCustomers(customers map[string]string, details map[string]CustomerDetails) {
+	snapshot := c.replaceCustomers(customers, details)
+	mapSize := snapshot.CustomerCount()
 
 	// Update customer cache size metric
 	if c.metrics != nil {
 		c.metrics.ClientCacheSize.Set(float64(mapSize))
+		c.recordCacheChecksums(snapshot)
 	}
 
-	return nil
+	c.saveSnapshotFile()
 }
 
 // InitializeDataSources discovers the data source IDs for both the main and customers databases.
@@ -178,31 +232,30 @@ func (c *Client) InitializeCustomers() error {
 // Returns an error if either data source discovery fails.
 func (c *Client) InitializeDataSources() error {
 	// Discover main database data source
-	mainDataSourceID, err := c.discoverDataSourceID(c.databaseID, "main database")
+	databaseID, customersDBID := c.CurrentDatabases()
+
+	mainDataSourceID, err := c.discoverDataSourceID(databaseID, "main database")
 	if err != nil {
 		return fmt.Errorf("failed to discover main database data source: %w", err)
 	}
-	c.dataSourceID = mainDataSourceID
 
 	// Discover customers database data source
-	customersDataSourceID, err := c.discoverDataSourceID(c.customersDBID, "customers database")
+	customersDataSourceID, err := c.discoverDataSourceID(customersDBID, "customers database")
 	if err != nil {
 		return fmt.Errorf("failed to discover customers database data source: %w", err)
 	}
+
+	c.targetMu.Lock()
+	c.dataSourceID = mainDataSourceID
 	c.customersDataSourceID = customersDataSourceID
+	c.targetMu.Unlock()
 
 	return nil
 }
 
-// GetValidCustomers returns the list of valid customer names for dropdown options
+// GetValidCustomers returns the sorted list of valid customer names for dropdown options
 func (c *Client) GetValidCustomers() []string {
-	c.cacheMu.RLock()
-	customerNames := make([]string, 0, len(c.customerMap))
-	for name := range c.customerMap {
-		customerNames = append(customerNames, name)
-	}
-	c.cacheMu.RUnlock()
-	return customerNames
+	return slices.Clone(c.Snapshot().CustomerNames())
 }
 
 // InitializeUsers fetches all workspace users from Notion and builds the email-to-UUID mapping.
@@ -216,6 +269,11 @@ func (c *Client) GetValidCustomers() []string {
 //
 // Returns an error if the Notion API call fails or the response cannot be parsed.
 func (c *Client) InitializeUsers() error {
+	if shared := c.loadSharedCache("users"); shared != nil {
+		c.publishUsers(shared.Entries, shared.Guests)
+		return nil
+	}
+
 	start := time.Now()
 
 	userMap, err := c.fetchUsersFromWorkspace()
@@ -225,29 +283,36 @@ func (c *Client) InitializeUsers() error {
 		return fmt.Errorf("failed to fetch users: %w", err)
 	}
 
-	c.cacheMu.Lock()
-	c.validUsers = userMap
+	// Keep external guests (outside ALLOWED_EMAIL_DOMAINS) out of the submitter mapping
+	members, guests := c.partitionUsers(userMap)
+	c.saveSharedCache("users", sharedEntry{Entries: members, Guests: guests})
+	c.publishUsers(members, guests)
+	return nil
+}
+
+// publishUsers replaces the cached users and guests and updates the cache metrics.
+func (c *Client) publishUsers(members, guests map[string]string) {
+	snapshot := c.replaceUsers(members, guests)
 
 	// Update user cache size metric
-	mapSize := len(c.validUsers)
+	mapSize := snapshot.UserCount()
 
 	// Log the loaded users (emails only, not UUIDs for brevity)
-	emails := make([]string, 0, len(c.validUsers))
-	for email := range c.validUsers {
-		emails = append(emails, email)
-	}
-	c.cacheMu.Unlock()
+	emails := snapshot.UserEmails()
 
 	if c.metrics != nil {
 		c.metrics.UserCacheSize.Set(float64(mapSize))
+		c.metrics.UserCacheGuests.Set(float64(snapshot.GuestCount()))
+		c.recordCacheChecksums(snapshot)
 	}
 
 	c.logger.Info("initialized Notion users cache",
 		zap.Int("count", mapSize),
+		zap.Int("excluded_guests", snapshot.GuestCount()),
 		zap.Strings("cached_emails", emails),
 	)
 
-	return nil
+	c.saveSnapshotFile()
 }
 
 // GetNotionUserIDByEmail looks up a Notion user UUID by email address.
@@ -255,32 +320,18 @@ func (c *Client) InitializeUsers() error {
 // Returns the Notion user UUID and true if found, or empty string and false if not found.
 // The lookup is case-insensitive to handle email variations.
 func (c *Client) GetNotionUserIDByEmail(email string) (string, bool) {
-	// Normalize email to lowercase for case-insensitive lookup
-	normalizedEmail := strings.ToLower(strings.TrimSpace(email))
-	c.cacheMu.RLock()
-	userID, found := c.validUsers[normalizedEmail]
-	c.cacheMu.RUnlock()
-	return userID, found
+	return c.Snapshot().NotionUserIDByEmail(email)
 }
 
 // GetUserCacheSize returns the number of users in the cache.
 func (c *Client) GetUserCacheSize() int {
-	c.cacheMu.RLock()
-	size := len(c.validUsers)
-	c.cacheMu.RUnlock()
-	return size
+	return c.Snapshot().UserCount()
 }
 
 // GetCachedUserEmails returns a list of all cached email addresses (for debugging).
 // Returns emails in their normalized (lowercase) form as stored in the cache.
 func (c *Client) GetCachedUserEmails() []string {
-	c.cacheMu.RLock()
-	emails := make([]string, 0, len(c.validUsers))
-	for email := range c.validUsers {
-		emails = append(emails, email)
-	}
-	c.cacheMu.RUnlock()
-	return emails
+	return c.Snapshot().UserEmails()
 }
 
 // Property represents a Notion database property with its value.
@@ -301,6 +352,9 @@ type Property struct {
 	MultiSelect []Select       `json:"multi_select,omitempty"`
 	People      []NotionUser   `json:"people,omitempty"`
 	Relation    []RelationPage `json:"relation,omitempty"`
+	Date        *Date          `json:"date,omitempty"`
+	URL         *string        `json:"url,omitempty"`
+	Number      *int           `json:"number,omitempty"`
 }
 
 // RichText represents formatted text content in Notion.
@@ -312,6 +366,12 @@ type RichText struct {
 // Text represents the plain text content within a RichText object.
 type Text struct {
 	Content string `json:"content"`
+	Link    *Link  `json:"link,omitempty"`
+}
+
+// Link is the URL a Text links to.
+type Link struct {
+	URL string `json:"url"`
 }
 
 // Select represents a single selection option in Notion.
@@ -334,6 +394,11 @@ type RelationPage struct {
 	ID string `json:"id"` // Notion page UUID
 }
 
+// Date represents the value of a Date property.
+type Date struct {
+	Start string `json:"start"` // ISO 8601 date (YYYY-MM-DD)
+}
+
 // DataSource represents a data source within a Notion database container.
 // Introduced in API v2025-09-03 to support multi-source databases.
 type DataSource struct {
@@ -359,10 +424,11 @@ type CreatePageRequest struct {
 }
 
 // Parent identifies the parent container for a new Notion page.
-// With API v2025-09-03, DataSourceID is used instead of DatabaseID.
+// With API v2025-09-03, DataSourceID is used instead of DatabaseID (see pageParent).
 type Parent struct {
-	Type         string `json:"type"`           // "data_source_id" for v2025-09-03
-	DataSourceID string `json:"data_source_id"` // Data source ID for the page
+	Type         string `json:"type"`                     // "data_source_id" for v2025-09-03, "database_id" for v2022-06-28
+	DataSourceID string `json:"data_source_id,omitempty"` // Data source ID for the page
+	DatabaseID   string `json:"database_id,omitempty"`    // Database ID for the page (legacy API)
 }
 
 // multiSelectConfig defines validation rules for multi-select fields.
@@ -513,35 +579,44 @@ func buildMultiSelectProperty(value string, config multiSelectConfig) (Property,
 	}, nil
 }
 
+// buildSingleMultiSelectProperty creates a multi-select property holding exactly one
+// option, validated against validValues.
+//
+// Equivalent to buildMultiSelectProperty with maxItems 1 for an already-single
+// value, without splitting the value into an intermediate slice.
+func buildSingleMultiSelectProperty(value string, validValues []string, fieldName string) (Property, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return Property{}, fmt.Errorf("%s cannot be empty", fieldName)
+	}
+
+	if !contains(validValues, trimmed) {
+		return Property{}, fmt.Errorf("invalid %s value: '%s' (must be one of: %s)",
+			fieldName, trimmed, strings.Join(validValues, ", "))
+	}
+
+	return Property{
+		MultiSelect: []Select{{Name: trimmed}},
+	}, nil
+}
+
 // buildRelationProperty creates and validates a relation property.
 //
 // Relation properties link to pages in another database.
 // Used for Customer Org field to link to customer pages.
 //
-// The value parameter should be a comma-separated string of customer names.
-// The customerMap is used to look up page IDs for the selected names.
+// The pageIDs parameter holds Notion page IDs that were already resolved from
+// customer names (see resolveCustomerIDs). Empty IDs are skipped.
 //
-// Validates:
-// - Maximum number of relations (e.g., max 10 customers)
-// - Each customer name exists in the customerMap
-func buildRelationProperty(value string, customerMap map[string]string, maxItems int, fieldName string) (Property, error) {
-	// Parse comma-separated customer names
-	customerNames := strings.Split(value, ",")
-	relations := make([]RelationPage, 0, len(customerNames))
-
-	for _, name := range customerNames {
-		trimmed := strings.TrimSpace(name)
+// Validates the maximum number of relations (e.g., max 10 customers).
+func buildRelationProperty(pageIDs []string, maxItems int, fieldName string) (Property, error) {
+	relations := make([]RelationPage, 0, len(pageIDs))
+	for _, pageID := range pageIDs {
+		trimmed := strings.TrimSpace(pageID)
 		if trimmed == "" {
 			continue // Skip empty values
 		}
-
-		// Look up the page ID for this customer name
-		pageID, found := customerMap[trimmed]
-		if !found {
-			return Property{}, fmt.Errorf("invalid %s value: '%s' (not found in customer database)", fieldName, trimmed)
-		}
-
-		relations = append(relations, RelationPage{ID: pageID})
+		relations = append(relations, RelationPage{ID: trimmed})
 	}
 
 	// Validate max items constraint
@@ -577,108 +652,15 @@ func buildPeopleProperty(notionUserID string) (Property, error) {
 	}, nil
 }
 
-// buildProperties converts form fields into Notion properties with comprehensive validation.
-//
-// Maps form field names (including aliases) to Notion database property names and validates
-// each field according to its type and business rules:
-//
-// - Title (Idea/Topic): Required, max 2000 chars
-// - Theme/Category: Required, single-select, predefined values
-// - Product Area: Required, single-select, predefined values
-// - Submitted By: Required, People property with Notion user UUID
-// - Comments: Optional, rich text, max 2000 chars
-// - Customer Org: Optional, multi-select, max 10 selections, validated against Customers database
-//
-// Empty values (after trimming) are skipped. Field aliases are supported for flexibility.
-// Returns a map of Notion property names to Property objects, or an error if validation fails.
+// buildProperties converts a legacy field map into Notion properties, resolving
+// customer names against the current cache snapshot. See SubmissionFromFields
+// and buildSubmissionProperties.
 func (c *Client) buildProperties(fields map[string]string) (map[string]Property, error) {
-	properties := make(map[string]Property)
-
-	// Create a thread-safe copy of customerMap for this request
-	c.cacheMu.RLock()
-	customerMapCopy := make(map[string]string, len(c.customerMap))
-	for k, v := range c.customerMap {
-		customerMapCopy[k] = v
-	}
-	c.cacheMu.RUnlock()
-
-	for key, value := range fields {
-		// Trim whitespace from value before checking if empty
-		trimmedValue := strings.TrimSpace(value)
-		if trimmedValue == "" {
-			continue // Skip empty values
-		}
-
-		var prop Property
-		var err error
-
-		switch key {
-		case constants.FieldIdeaTopic, constants.AliasTitle, constants.AliasIdea, constants.AliasTopic:
-			// Validate and build title property with length limit
-			prop, err = buildTitleProperty(trimmedValue)
-			if err != nil {
-				return nil, fmt.Errorf("title validation failed: %w", err)
-			}
-			properties[constants.FieldIdeaTopic] = prop
-
-		case constants.FieldThemeCategory, constants.AliasTheme, constants.AliasCategory:
-			// Validate theme selection against valid values
-			// Note: Notion expects multi_select, but Slack form only allows one selection
-			prop, err = buildMultiSelectProperty(trimmedValue, multiSelectConfig{
-				maxItems:    1,
-				validValues: constants.ValidThemeCategories,
-				fieldName:   constants.FieldThemeCategory,
-			})
-			if err != nil {
-				return nil, err
-			}
-			properties[constants.FieldThemeCategory] = prop
-
-		case constants.FieldProductArea, constants.AliasProductArea, constants.AliasArea:
-			// Validate product area against valid values
-			prop, err = buildSelectProperty(trimmedValue, constants.ValidProductAreas, constants.FieldProductArea)
-			if err != nil {
-				return nil, err
-			}
-			properties[constants.FieldProductArea] = prop
-
-		case constants.FieldComments, constants.AliasComments, constants.AliasComment:
-			// Validate comments with length limit
-			prop, err = buildRichTextProperty(trimmedValue, constants.FieldComments)
-			if err != nil {
-				return nil, fmt.Errorf("comments validation failed: %w", err)
-			}
-			properties[constants.FieldComments] = prop
-
-		case constants.FieldCustomerOrg, constants.AliasCustomerOrg, constants.AliasCustomer, constants.AliasOrg:
-			// Validate customer org selections against fetched customer list and max count
-			// Use relation property to link to customer database pages
-			prop, err = buildRelationProperty(
-				trimmedValue,
-				customerMapCopy,
-				constants.MaxCustomerOrgSelections,
-				constants.FieldCustomerOrg,
-			)
-			if err != nil {
-				return nil, err
-			}
-			properties[constants.FieldCustomerOrg] = prop
-
-		case constants.FieldSubmittedBy, constants.AliasSubmittedBy:
-			// Build People property with Notion user UUID
-			// The value should already be a Notion user UUID (mapped from Slack user email)
-			prop, err = buildPeopleProperty(trimmedValue)
-			if err != nil {
-				return nil, fmt.Errorf("submitted by validation failed: %w", err)
-			}
-			properties[constants.FieldSubmittedBy] = prop
-
-		default:
-			return nil, fmt.Errorf("unknown field: %s", key)
-		}
+	sub, err := SubmissionFromFields(c.Snapshot(), fields)
+	if err != nil {
+		return nil, err
 	}
-
-	return properties, nil
+	return buildSubmissionProperties(sub, c.SelectOptions())
 }
 
 // validateRequiredFields ensures all required fields are present and valid.
@@ -718,94 +700,355 @@ func (c *Client) validateRequiredFields(properties map[string]Property) error {
 	return nil
 }
 
+// CreatedPage is the parsed result of a successful page creation.
+type CreatedPage struct {
+	ID          string    `json:"id"`           // Notion page UUID
+	URL         string    `json:"url"`          // Public notion.so URL of the page
+	CreatedTime time.Time `json:"created_time"` // Creation timestamp (Notion rounds to the minute)
+
+	// Recovered is true when the page was found by the idempotency check after an
+	// ambiguous failure (e.g., a timeout), rather than returned by the create call.
+	Recovered bool `json:"-"`
+}
+
 // createNotionPage makes the API call to create a page in the Notion database.
 //
 // Constructs a CreatePageRequest with the validated properties and sends it to
-// the Notion API. The page is created in the database specified by c.databaseID.
+// the Notion API. The page is created in the data source discovered for c.databaseID.
 //
-// Returns nil on success, or an error if the API call fails.
+// Returns the created page's ID and URL on success, or an error if the API call fails.
 // API errors include details from the Notion response for debugging.
-func (c *Client) createNotionPage(properties map[string]Property) error {
+func (c *Client) createNotionPage(ctx context.Context, properties map[string]Property) (*CreatedPage, error) {
 	request := CreatePageRequest{
-		Parent: Parent{
-			Type:         "data_source_id",
-			DataSourceID: c.dataSourceID,
-		},
+		Parent:     c.pageParent(c.ideasDataSourceID()),
 		Properties: properties,
 	}
 
 	body, err := json.Marshal(request)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	endpoint := fmt.Sprintf("%s/pages", constants.NotionAPIBaseURL)
-	resp, err := c.makeNotionRequest("POST", endpoint, body)
+	resp, err := c.makeNotionRequestContext(ctx, "POST", endpoint, body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	return nil
+	var page CreatedPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode page creation response: %w", err)
+	}
+	if page.ID == "" {
+		return nil, fmt.Errorf("page creation response is missing the page ID")
+	}
+
+	return &page, nil
+}
+
+// createNotionPageWithRetry creates a page, retrying transient failures without
+// creating duplicates.
+//
+// Retry rules:
+// - 429 and 5xx responses and network errors (including timeouts) are retried
+// - Other API errors (validation, permissions) are returned immediately
+// - Before retrying an ambiguous failure (network error or 5xx), where Notion may
+//   have created the page even though we never saw the response, the data source
+//   is queried for a matching page created since the first attempt. If one exists
+//   it is returned (with Recovered set) instead of creating a duplicate.
+//
+// Up to constants.NotionCreateMaxAttempts attempts are made with exponential backoff.
+// Connection failures, 429 and 503 are also retried by the HTTP transport first
+// (see pkg/httpclient), since Notion can't have created the page. While the
+// transport's circuit breaker is open the error is returned at once (IsUnavailable).
+func (c *Client) createNotionPageWithRetry(ctx context.Context, properties map[string]Property) (*CreatedPage, error) {
+	firstAttempt := time.Now()
+	backoff := c.createBackoff
+	logger := logging.FromContext(ctx, c.logger)
+
+	for attempt := 1; ; attempt++ {
+		page, err := c.createNotionPage(ctx, properties)
+		if err == nil {
+			return page, nil
+		}
+
+		retryable, ambiguous := classifyCreateError(err)
+		if !retryable || attempt >= constants.NotionCreateMaxAttempts {
+			return nil, err
+		}
+
+		if ambiguous {
+			existing, findErr := c.findCreatedPage(ctx, properties, firstAttempt)
+			if findErr != nil {
+				logger.Warn("idempotency check failed after ambiguous page creation error",
+					zap.Int("attempt", attempt),
+					zap.Error(findErr),
+				)
+			} else if existing != nil {
+				logger.Info("page was created despite error, skipping retry",
+					zap.Int("attempt", attempt),
+					zap.String("page_id", existing.ID),
+					zap.Error(err),
+				)
+				existing.Recovered = true
+				return existing, nil
+			}
+		}
+
+		logger.Warn("page creation failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Bool("ambiguous", ambiguous),
+			zap.Error(err),
+		)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
 }
 
-// SubmitForm creates a new entry in the Notion database with the provided fields.
+// classifyCreateError reports whether a page creation error is worth retrying, and
+// whether the page might have been created anyway (so a retry could duplicate it).
+func classifyCreateError(err error) (retryable, ambiguous bool) {
+	if IsUnavailable(err) {
+		// Never sent; retrying before the circuit closes would only fail again
+		return false, false
+	}
+
+	var apiErr *NotionAPIError
+	if !errors.As(err, &apiErr) {
+		// Network error or timeout: the request may or may not have reached Notion
+		return true, true
+	}
+
+	switch {
+	case apiErr.Status == http.StatusTooManyRequests:
+		// Rate limited before processing; nothing was created
+		return true, false
+	case apiErr.Status >= http.StatusInternalServerError:
+		// Gateway errors can be returned after Notion processed the request
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// IsRetryableError reports whether a SubmitSubmission error is transient, i.e. a
+// later attempt could succeed: network errors, timeouts, rate limits, and 5xx
+// responses. Validation errors and other API errors (permissions, bad
+// requests) are not retryable.
+func IsRetryableError(err error) bool {
+	if errors.Is(err, ErrSchemaMismatch) {
+		return true // Submissions resume once the database is fixed
+	}
+	if IsUnavailable(err) {
+		return true // Notion is expected to recover
+	}
+	var apiErr *NotionAPIError
+	if errors.As(err, &apiErr) {
+		retryable, _ := classifyCreateError(err)
+		return retryable
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// IsUnavailable reports whether a request failed fast because Notion has been
+// failing and the client's circuit breaker is open (see pkg/httpclient). Nothing
+// was sent, so the request can be queued and made again later.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, httpclient.ErrCircuitOpen)
+}
+
+// findCreatedPage looks for a page matching properties that was created at or after since.
+//
+// Matches on title and submitter, which together identify a submission closely
+// enough for a retry window of a few seconds. Notion's created_time has minute
+// precision, so the window starts one minute before since.
+//
+// Returns nil (without error) if no matching page exists.
+func (c *Client) findCreatedPage(ctx context.Context, properties map[string]Property, since time.Time) (*CreatedPage, error) {
+	title, hasTitle := properties[constants.FieldIdeaTopic]
+	submitter, hasSubmitter := properties[constants.FieldSubmittedBy]
+	if !hasTitle || len(title.Title) == 0 || !hasSubmitter || len(submitter.People) == 0 {
+		return nil, fmt.Errorf("cannot identify page without title and submitter")
+	}
+
+	requestBody := map[string]interface{}{
+		"filter": map[string]interface{}{
+			"and": []interface{}{
+				map[string]interface{}{
+					"property": constants.FieldIdeaTopic,
+					"title":    map[string]interface{}{"equals": title.Title[0].Text.Content},
+				},
+				map[string]interface{}{
+					"property": constants.FieldSubmittedBy,
+					"people":   map[string]interface{}{"contains": submitter.People[0].ID},
+				},
+				map[string]interface{}{
+					"timestamp":    "created_time",
+					"created_time": map[string]interface{}{"on_or_after": since.Add(-time.Minute).UTC().Format(time.RFC3339)},
+				},
+			},
+		},
+		"sorts": []interface{}{
+			map[string]interface{}{"timestamp": "created_time", "direction": "descending"},
+		},
+		"page_size": 1,
+	}
+
+	body, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := c.dataSourceEndpoint(c.ideasDataSourceID(), "/query")
+	resp, err := c.makeNotionRequestContext(ctx, "POST", endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var queryResponse struct {
+		Results []CreatedPage `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&queryResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(queryResponse.Results) == 0 {
+		return nil, nil
+	}
+	return &queryResponse.Results[0], nil
+}
+
+// SubmitForm creates a new entry in the Notion database from a legacy field map.
+//
+// Field names (or aliases) are converted with SubmissionFromFields against the
+// current cache snapshot and then submitted with SubmitSubmission. New callers
+// should build a submission.Submission directly.
+func (c *Client) SubmitForm(fields map[string]string) (*CreatedPage, error) {
+	return c.SubmitFormWithSnapshot(c.Snapshot(), fields)
+}
+
+// SubmitFormWithSnapshot is SubmitForm resolving customer names against the given snapshot.
+func (c *Client) SubmitFormWithSnapshot(snapshot *CacheSnapshot, fields map[string]string) (*CreatedPage, error) {
+	sub, err := SubmissionFromFields(snapshot, fields)
+	if err != nil {
+		c.recordNotionRequest("submit_form", time.Now(), err)
+		return nil, err
+	}
+	return c.SubmitSubmission(context.Background(), sub)
+}
+
+// SubmitSubmission creates a new entry in the Notion database for a submission.
 //
 // This is the main entry point for form submissions. It orchestrates the entire flow:
-// 1. Converts and validates form fields to Notion properties
+// 1. Converts and validates the submission to Notion properties
 // 2. Ensures all required fields are present
-// 3. Creates the page in the Notion database
+// 3. Creates the page in the Notion database (retrying transient failures idempotently)
 // 4. Records metrics for monitoring
 //
-// Parameters:
-// - fields: Map of field names (or aliases) to their string values
+// Customer orgs must already be resolved to page IDs (sub.CustomerOrgIDs).
 //
-// Returns nil on success, or an error describing what went wrong (validation or API error).
-// All errors are recorded in metrics for observability.
-func (c *Client) SubmitForm(fields map[string]string) error {
+// Returns the created page (ID and URL parsed from the Notion response) on success,
+// or an error describing what went wrong (validation or API error). All errors are
+// recorded in metrics for observability. A request ID in ctx (see pkg/requestid)
+// is sent to Notion and logged with retries.
+func (c *Client) SubmitSubmission(ctx context.Context, sub submission.Submission) (*CreatedPage, error) {
 	start := time.Now()
 
-	properties, err := c.buildProperties(fields)
+	if err := c.SchemaError(); err != nil {
+		c.recordNotionRequest("submit_form", start, err)
+		return nil, fmt.Errorf("submissions are paused: %w", err)
+	}
+
+	options := c.SelectOptions()
+	properties, err := buildSubmissionProperties(sub, options)
 	if err != nil {
 		c.recordNotionRequest("submit_form", start, err)
-		return err
+		return nil, err
 	}
+	c.addSourceURL(properties, sub, options)
 
 	if err := c.validateRequiredFields(properties); err != nil {
 		c.recordNotionRequest("submit_form", start, err)
-		return err
+		return nil, err
 	}
 
-	err = c.createNotionPage(properties)
+	page, err := c.createNotionPageWithRetry(ctx, properties)
 	c.recordNotionRequest("submit_form", start, err)
-	return err
+	if err != nil {
+		var apiErr *NotionAPIError
+		if errors.As(err, &apiErr) && apiErr.Code == ErrorCodeValidation {
+			// Likely schema drift (a property was renamed or deleted); resync so
+			// the guard engages now rather than at the next cache refresh
+			go func() {
+				if _, syncErr := c.SyncSchema(); syncErr != nil {
+					c.logger.Warn("failed to resync schema after validation error", zap.Error(syncErr))
+				}
+			}()
+		}
+		return nil, err
+	}
+
+	logging.FromContext(ctx, c.logger).Debug("created notion page",
+		zap.String("page_id", page.ID),
+		zap.String("page_url", page.URL),
+		zap.Bool("recovered", page.Recovered),
+	)
+	return page, nil
 }
 
 // makeNotionRequest creates and executes an HTTP request to the Notion API.
 //
 // Handles authentication, versioning, and error handling for all Notion API calls.
+// Transient failures are retried by the transport (see pkg/httpclient); reads
+// are marked idempotent so ambiguous failures are retried too.
 // Sets required headers:
 // - Authorization: Bearer token for API authentication
 // - Notion-Version: API version for request compatibility
 // - Content-Type: application/json for request body
 //
 // Returns the HTTP response on success (status 200), or an error with details.
-// Non-200 responses include the full response body in the error message for debugging.
+// Non-200 responses are returned as *NotionAPIError, parsed from Notion's error object.
 func (c *Client) makeNotionRequest(method, endpoint string, body []byte) (*http.Response, error) {
+	return c.makeNotionRequestContext(context.Background(), method, endpoint, body)
+}
+
+// makeNotionRequestContext is makeNotionRequest on behalf of an inbound request:
+// the request ID in ctx, if any, is sent as the X-Request-ID header.
+func (c *Client) makeNotionRequestContext(ctx context.Context, method, endpoint string, body []byte) (*http.Response, error) {
+	return c.makeNotionRequestWithContentType(ctx, method, endpoint, "application/json", body)
+}
+
+// makeNotionRequestWithContentType is makeNotionRequestContext for bodies that
+// aren't JSON, such as multipart file uploads.
+func (c *Client) makeNotionRequestWithContentType(ctx context.Context, method, endpoint, contentType string, body []byte) (*http.Response, error) {
 	var bodyReader io.Reader
 	if body != nil {
 		bodyReader = bytes.NewBuffer(body)
 	}
 
-	req, err := http.NewRequest(method, endpoint, bodyReader)
+	if isReadEndpoint(method, endpoint) {
+		ctx = httpclient.Idempotent(ctx)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Notion-Version", constants.NotionAPIVersion)
+	if trace := c.connectionTrace(); trace != nil {
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	}
+
+	req.Header.Set("Authorization", "Bearer "+*c.apiKey.Load())
+	req.Header.Set("Notion-Version", c.apiVersion)
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
 	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Type", contentType)
 	}
 
 	resp, err := c.httpClient.Do(req)
@@ -819,12 +1062,83 @@ func (c *Client) makeNotionRequest(method, endpoint string, body []byte) (*http.
 		if err != nil {
 			return nil, fmt.Errorf("notion API error (status %d): failed to read response body: %w", resp.StatusCode, err)
 		}
-		return nil, fmt.Errorf("notion API error (status %d): %s", resp.StatusCode, string(bodyBytes))
+		return nil, newNotionAPIError(resp.StatusCode, bodyBytes, resp.Header.Get("X-Request-Id"))
 	}
 
 	return resp, nil
 }
 
+// isReadEndpoint reports whether a POST only reads (queries and search), so
+// the transport may retry it like a GET. Page creation isn't: it has its own
+// duplicate-safe retries (see createNotionPageWithRetry).
+func isReadEndpoint(method, endpoint string) bool {
+	return method == http.MethodPost && (strings.HasSuffix(endpoint, "/query") || strings.HasSuffix(endpoint, "/search"))
+}
+
+// Notion error codes callers branch on. See https://developers.notion.com/reference/status-codes
+const (
+	ErrorCodeValidation     = "validation_error"
+	ErrorCodeUnauthorized   = "unauthorized"
+	ErrorCodeRestricted     = "restricted_resource"
+	ErrorCodeObjectNotFound = "object_not_found"
+	ErrorCodeConflict       = "conflict_error"
+	ErrorCodeRateLimited    = "rate_limited"
+)
+
+// NotionAPIError is returned by makeNotionRequest for non-200 responses.
+//
+// Notion error bodies look like:
+//
+//	{"object": "error", "status": 404, "code": "object_not_found", "message": "...", "request_id": "..."}
+//
+// Callers that need to distinguish failure modes (e.g., permission probes, or
+// the message shown on the modal) use errors.As to inspect Status and Code.
+type NotionAPIError struct {
+	Status    int    // HTTP status code
+	Code      string // Notion error code (e.g., "validation_error"); empty if the body is not a Notion error object
+	Message   string // Notion's human-readable explanation
+	RequestID string // Notion request ID, for support tickets
+	Body      string // Raw response body, kept when it is not a Notion error object (e.g., a proxy's HTML page)
+}
+
+// newNotionAPIError parses a non-200 response body. requestID is the
+// X-Request-Id header, used when the body doesn't carry one.
+func newNotionAPIError(status int, body []byte, requestID string) *NotionAPIError {
+	apiErr := &NotionAPIError{Status: status, RequestID: requestID}
+
+	var parsed struct {
+		Object    string `json:"object"`
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		RequestID string `json:"request_id"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil || parsed.Code == "" {
+		apiErr.Body = string(body)
+		return apiErr
+	}
+
+	apiErr.Code = parsed.Code
+	apiErr.Message = parsed.Message
+	if parsed.RequestID != "" {
+		apiErr.RequestID = parsed.RequestID
+	}
+	return apiErr
+}
+
+// Error implements the error interface.
+func (e *NotionAPIError) Error() string {
+	var msg string
+	if e.Code != "" {
+		msg = fmt.Sprintf("notion API error (status %d, %s): %s", e.Status, e.Code, e.Message)
+	} else {
+		msg = fmt.Sprintf("notion API error (status %d): %s", e.Status, e.Body)
+	}
+	if e.RequestID != "" {
+		msg += " (request_id: " + e.RequestID + ")"
+	}
+	return msg
+}
+
 // contains checks if a string is in a slice.
 // Used for validating selections against allowed values.
 func contains(slice []string, item string) bool {
@@ -862,7 +1176,12 @@ func parseMultiSelect(value string) []Select {
 //
 // Returns a map of property names to property types (e.g., "title", "rich_text", "select").
 func (c *Client) GetDatabaseSchema() (map[string]string, error) {
-	endpoint := fmt.Sprintf("%s/data_sources/%s", constants.NotionAPIBaseURL, c.dataSourceID)
+	return c.getDataSourceSchema(c.ideasDataSourceID())
+}
+
+// getDataSourceSchema retrieves property names and types for the given data source.
+func (c *Client) getDataSourceSchema(dataSourceID string) (map[string]string, error) {
+	endpoint := c.dataSourceEndpoint(dataSourceID, "")
 	resp, err := c.makeNotionRequest("GET", endpoint, nil)
 	if err != nil {
 		return nil, err
@@ -899,47 +1218,52 @@ func (c *Client) GetDatabaseSchema() (map[string]string, error) {
 //
 // Returns:
 // - customers: Map of customer name -> Notion page ID from this page
+// - details: Map of Notion page ID -> aliases and description, for customers that have any (see SetCustomerProperties)
 // - nextCursor: Cursor for fetching the next page
 // - hasMore: Whether more pages are available
 // - err: Any error that occurred during the fetch
-func (c *Client) fetchCustomersPage(cursor string) (customers map[string]string, nextCursor string, hasMore bool, err error) {
-	requestBody := map[string]interface{}{
-		"page_size": constants.NotionPageSize,
-	}
-	if cursor != "" {
-		requestBody["start_cursor"] = cursor
-	}
+func (c *Client) fetchCustomersPage(cursor string) (customers map[string]string, details map[string]CustomerDetails, nextCursor string, hasMore bool, err error) {
+	return c.fetchCustomersPageFrom(c.customersSourceID(), cursor)
+}
 
-	body, err := json.Marshal(requestBody)
+// fetchCustomersPageFrom fetches a single page of customers from the given data source.
+// Used directly when validating a replacement Customers database before switching to it.
+func (c *Client) fetchCustomersPageFrom(dataSourceID, cursor string) (customers map[string]string, details map[string]CustomerDetails, nextCursor string, hasMore bool, err error) {
+	customerProperties := c.customerSettings()
+	body, err := customerProperties.queryBody(cursor, nil)
 	if err != nil {
-		return nil, "", false, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, nil, "", false, err
 	}
 
-	endpoint := fmt.Sprintf("%s/data_sources/%s/query", constants.NotionAPIBaseURL, c.customersDataSourceID)
+	endpoint := c.dataSourceEndpoint(dataSourceID, "/query")
 	resp, err := c.makeNotionRequest("POST", endpoint, body)
 	if err != nil {
-		return nil, "", false, err
+		return nil, nil, "", false, err
 	}
 	defer resp.Body.Close()
 
 	var queryResponse map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&queryResponse); err != nil {
-		return nil, "", false, fmt.Errorf("failed to decode response: %w", err)
+		return nil, nil, "", false, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Extract customer names and page IDs from the results
+	// Extract customer names, page IDs and details from the results
 	customers = make(map[string]string)
+	details = make(map[string]CustomerDetails)
 	if results, ok := queryResponse["results"].([]interface{}); ok {
 		for _, pageInterface := range results {
 			if page, ok := pageInterface.(map[string]interface{}); ok {
 				// Extract page ID
 				pageID, _ := page["id"].(string)
 
-				// Extract customer name from properties
+				// Extract customer name from properties, skipping unlisted customers
 				if properties, ok := page["properties"].(map[string]interface{}); ok {
-					customerName := extractTitleFromProperties(properties)
+					customerName, customer, hasDetails := customerProperties.extractCustomer(properties)
 					if customerName != "" && pageID != "" {
 						customers[customerName] = pageID
+						if hasDetails {
+							details[pageID] = customer
+						}
 					}
 				}
 			}
@@ -950,37 +1274,41 @@ func (c *Client) fetchCustomersPage(cursor string) (customers map[string]string,
 	hasMore, _ = queryResponse["has_more"].(bool)
 	nextCursor, _ = queryResponse["next_cursor"].(string)
 
-	return customers, nextCursor, hasMore, nil
+	return customers, details, nextCursor, hasMore, nil
 }
 
-// fetchCustomersFromDatabase queries the Customers database and extracts all customer names and page IDs.
+// fetchCustomersFromDatabase queries the Customers database and extracts all customer names and page IDs,
+// with their details (see SetCustomerProperties).
 //
 // Automatically handles pagination to fetch all customers regardless of total count.
 // Continues fetching pages until hasMore is false.
 //
 // Returns a complete map of customer organization names to their Notion page IDs.
 // These are used to populate dropdown options, validate selections, and build relation properties.
-func (c *Client) fetchCustomersFromDatabase() (map[string]string, error) {
-	allCustomers := make(map[string]string)
-	cursor := ""
-	hasMore := true
-
-	for hasMore {
-		customers, nextCursor, more, err := c.fetchCustomersPage(cursor)
-		if err != nil {
-			return allCustomers, fmt.Errorf("failed to fetch customers page: %w", err)
-		}
-
-		// Merge customers from this page into the map
-		for name, pageID := range customers {
-			allCustomers[name] = pageID
-		}
+func (c *Client) fetchCustomersFromDatabase() (map[string]string, map[string]CustomerDetails, error) {
+	return c.fetchCustomersFrom(c.customersSourceID())
+}
 
-		cursor = nextCursor
-		hasMore = more
+// fetchCustomersFrom fetches all customers from the given data source, following pagination.
+func (c *Client) fetchCustomersFrom(dataSourceID string) (map[string]string, map[string]CustomerDetails, error) {
+	allCustomers := make(map[string]string)
+	allDetails := make(map[string]CustomerDetails)
+
+	fetchPage := func(cursor string) (foundCustomers, string, bool, error) {
+		customers, details, nextCursor, hasMore, err := c.fetchCustomersPageFrom(dataSourceID, cursor)
+		return foundCustomers{customers: customers, details: details}, nextCursor, hasMore, err
+	}
+	err := paginate(context.Background(), c, "fetch_customers", fetchPage, func(page foundCustomers) bool {
+		// Merge customers from this page into the maps
+		maps.Copy(allCustomers, page.customers)
+		maps.Copy(allDetails, page.details)
+		return true
+	})
+	if err != nil {
+		return allCustomers, allDetails, fmt.Errorf("failed to fetch customers page: %w", err)
 	}
 
-	return allCustomers, nil
+	return allCustomers, allDetails, nil
 }
 
 // extractTitleFromProperties extracts the title field from page properties.
@@ -1034,22 +1362,14 @@ func extractTitleFromProperties(properties map[string]interface{}) string {
 // Returns a map of normalized email addresses to Notion user UUIDs.
 func (c *Client) fetchUsersFromWorkspace() (map[string]string, error) {
 	userMap := make(map[string]string)
-	cursor := ""
-	hasMore := true
-
-	for hasMore {
-		users, nextCursor, more, err := c.fetchUsersPage(cursor)
-		if err != nil {
-			return userMap, fmt.Errorf("failed to fetch users page: %w", err)
-		}
 
+	err := paginate(context.Background(), c, "fetch_users", c.fetchUsersPage, func(users map[string]string) bool {
 		// Add all users to the map
-		for email, userID := range users {
-			userMap[email] = userID
-		}
-
-		cursor = nextCursor
-		hasMore = more
+		maps.Copy(userMap, users)
+		return true
+	})
+	if err != nil {
+		return userMap, fmt.Errorf("failed to fetch users page: %w", err)
 	}
 
 	return userMap, nil
@@ -2,13 +2,20 @@ package notion
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/httpclient"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
 	"go.uber.org/zap"
 )
 
@@ -390,7 +397,7 @@ func TestBuildMultiSelectProperty(t *testing.T) {
 func TestBuildProperties(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	client := NewClient("test-key", "db-id", "clients-db-id", logger)
-	client.customerMap = map[string]string{"Customer A": "page-id-1", "Customer B": "page-id-2"}
+	client.replaceCustomers(map[string]string{"Customer A": "page-id-1", "Customer B": "page-id-2"}, nil)
 
 	tests := []struct {
 		name      string
@@ -650,13 +657,13 @@ func TestGetValidCustomers(t *testing.T) {
 		t.Errorf("expected empty clients initially, got %d", len(clients))
 	}
 
-	// Set customers via customerMap
+	// Set customers via the cache snapshot
 	expectedCustomerNames := []string{"Customer A", "Customer B", "Customer C"}
-	client.customerMap = map[string]string{
+	client.replaceCustomers(map[string]string{
 		"Customer A": "page-id-1",
 		"Customer B": "page-id-2",
 		"Customer C": "page-id-3",
-	}
+	}, nil)
 
 	clients = client.GetValidCustomers()
 	if len(clients) != len(expectedCustomerNames) {
@@ -685,8 +692,8 @@ func TestNewClient(t *testing.T) {
 
 	client := NewClient(apiKey, dbID, clientsDBID, logger)
 
-	if client.apiKey != apiKey {
-		t.Errorf("apiKey = %s, want %s", client.apiKey, apiKey)
+	if got := *client.apiKey.Load(); got != apiKey {
+		t.Errorf("apiKey = %s, want %s", got, apiKey)
 	}
 	if client.databaseID != dbID {
 		t.Errorf("databaseID = %s, want %s", client.databaseID, dbID)
@@ -697,8 +704,8 @@ func TestNewClient(t *testing.T) {
 	if client.httpClient == nil {
 		t.Error("httpClient should not be nil")
 	}
-	if len(client.customerMap) != 0 {
-		t.Errorf("customerMap should be empty initially, got %d", len(client.customerMap))
+	if client.Snapshot().CustomerCount() != 0 {
+		t.Errorf("customer cache should be empty initially, got %d", client.Snapshot().CustomerCount())
 	}
 }
 
@@ -809,7 +816,7 @@ func TestFetchClientsPage(t *testing.T) {
 
 	client.httpClient = mockHTTPClient
 
-	customersMap, _, hasMore, err := client.fetchCustomersPage("")
+	customersMap, _, _, hasMore, err := client.fetchCustomersPage("")
 
 	if err == nil && len(customersMap) > 0 {
 		// Check that "Customer A" exists in the map
@@ -885,11 +892,11 @@ func TestGetNotionUserIDByEmail(t *testing.T) {
 	client := NewClient("test-key", "db-id", "clients-db-id", logger)
 
 	// Populate test user cache
-	client.validUsers = map[string]string{
+	client.replaceUsers(map[string]string{
 		"user1@example.com": "user-uuid-1",
 		"user2@example.com": "user-uuid-2",
 		"admin@test.com":    "admin-uuid",
-	}
+	}, nil)
 
 	tests := []struct {
 		name          string
@@ -1033,3 +1040,219 @@ type mockTransport struct {
 func (m *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return m.resp, nil
 }
+
+// sequenceTransport returns queued results in order, recording each request path
+type sequenceTransport struct {
+	results []func() (*http.Response, error)
+	paths   []string
+	bodies  []string // Request bodies, "" for requests without one
+}
+
+func (s *sequenceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.paths = append(s.paths, req.Method+" "+req.URL.Path)
+	body := ""
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		body = string(data)
+	}
+	s.bodies = append(s.bodies, body)
+	if len(s.results) == 0 {
+		return nil, errors.New("unexpected request")
+	}
+	next := s.results[0]
+	s.results = s.results[1:]
+	return next()
+}
+
+func respond(statusCode int, body string) func() (*http.Response, error) {
+	return func() (*http.Response, error) {
+		return &http.Response{
+			StatusCode: statusCode,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+		}, nil
+	}
+}
+
+func fail(err error) func() (*http.Response, error) {
+	return func() (*http.Response, error) { return nil, err }
+}
+
+// testSubmissionProperties returns the minimal properties used by the idempotency check
+func testSubmissionProperties() map[string]Property {
+	return map[string]Property{
+		constants.FieldIdeaTopic:   {Title: []RichText{{Text: Text{Content: "Better exports"}}}},
+		constants.FieldSubmittedBy: {People: []NotionUser{{Object: "user", ID: "user-uuid"}}},
+	}
+}
+
+// TestCreateNotionPageWithRetry tests response parsing, retries, and idempotency recovery
+func TestCreateNotionPageWithRetry(t *testing.T) {
+	created := `{"object":"page","id":"page-1","url":"https://www.notion.so/page-1","created_time":"2025-11-05T17:30:00.000Z"}`
+
+	tests := []struct {
+		name          string
+		results       []func() (*http.Response, error)
+		wantErr       bool
+		wantRecovered bool
+		wantPaths     []string
+	}{
+		{
+			name:      "success parses page",
+			results:   []func() (*http.Response, error){respond(http.StatusOK, created)},
+			wantPaths: []string{"POST /v1/pages"},
+		},
+		{
+			name: "rate limited is retried without idempotency check",
+			results: []func() (*http.Response, error){
+				respond(http.StatusTooManyRequests, `{"code":"rate_limited"}`),
+				respond(http.StatusOK, created),
+			},
+			wantPaths: []string{"POST /v1/pages", "POST /v1/pages"},
+		},
+		{
+			name: "timeout with page already created is recovered",
+			results: []func() (*http.Response, error){
+				fail(errors.New("context deadline exceeded")),
+				respond(http.StatusOK, `{"results":[`+created+`]}`),
+			},
+			wantRecovered: true,
+			wantPaths:     []string{"POST /v1/pages", "POST /v1/data_sources/ds-id/query"},
+		},
+		{
+			name: "timeout without page is retried",
+			results: []func() (*http.Response, error){
+				fail(errors.New("context deadline exceeded")),
+				respond(http.StatusOK, `{"results":[]}`),
+				respond(http.StatusOK, created),
+			},
+			wantPaths: []string{"POST /v1/pages", "POST /v1/data_sources/ds-id/query", "POST /v1/pages"},
+		},
+		{
+			name: "validation error is not retried",
+			results: []func() (*http.Response, error){
+				respond(http.StatusBadRequest, `{"code":"validation_error"}`),
+			},
+			wantErr:   true,
+			wantPaths: []string{"POST /v1/pages"},
+		},
+		{
+			name: "open circuit fails fast",
+			results: []func() (*http.Response, error){
+				fail(httpclient.ErrCircuitOpen),
+			},
+			wantErr:   true,
+			wantPaths: []string{"POST /v1/pages"},
+		},
+		{
+			name: "gives up after max attempts",
+			results: []func() (*http.Response, error){
+				respond(http.StatusTooManyRequests, `{}`),
+				respond(http.StatusTooManyRequests, `{}`),
+				respond(http.StatusTooManyRequests, `{}`),
+			},
+			wantErr:   true,
+			wantPaths: []string{"POST /v1/pages", "POST /v1/pages", "POST /v1/pages"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &sequenceTransport{results: tt.results}
+			client := NewClient("test-key", "db-id", "clients-db-id", zap.NewNop())
+			client.dataSourceID = "ds-id"
+			client.createBackoff = time.Millisecond
+			client.httpClient = &http.Client{Transport: transport}
+
+			page, err := client.createNotionPageWithRetry(context.Background(), testSubmissionProperties())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				if page.ID != "page-1" || page.URL != "https://www.notion.so/page-1" {
+					t.Errorf("page = %+v, want ID page-1 with URL", page)
+				}
+				if page.Recovered != tt.wantRecovered {
+					t.Errorf("Recovered = %v, want %v", page.Recovered, tt.wantRecovered)
+				}
+			}
+			if strings.Join(transport.paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("requests = %v, want %v", transport.paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+// TestIsRetryableError tests which submission errors are worth retrying later
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "rate limited", err: &NotionAPIError{Status: http.StatusTooManyRequests}, want: true},
+		{name: "server error", err: fmt.Errorf("wrapped: %w", &NotionAPIError{Status: http.StatusBadGateway}), want: true},
+		{name: "bad request", err: &NotionAPIError{Status: http.StatusBadRequest}, want: false},
+		{name: "forbidden", err: &NotionAPIError{Status: http.StatusForbidden}, want: false},
+		{name: "network error", err: fmt.Errorf("failed to send request: %w", &url.Error{Op: "Post", URL: "https://api.notion.com", Err: errors.New("connection refused")}), want: true},
+		{name: "validation error", err: errors.New("required field 'title' is missing"), want: false},
+		{name: "circuit open", err: fmt.Errorf("failed to send request: %w", &url.Error{Op: "Post", URL: "https://api.notion.com", Err: httpclient.ErrCircuitOpen}), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIsReadEndpoint tests which POSTs the transport may retry like reads
+func TestIsReadEndpoint(t *testing.T) {
+	base := "https://api.notion.com/v1"
+	tests := []struct {
+		method, endpoint string
+		want             bool
+	}{
+		{http.MethodPost, base + "/data_sources/ds-1/query", true},
+		{http.MethodPost, base + "/search", true},
+		{http.MethodPost, base + "/pages", false},
+		{http.MethodPost, base + "/file_uploads", false},
+		{http.MethodPatch, base + "/pages/page-1", false},
+	}
+
+	for _, tt := range tests {
+		if got := isReadEndpoint(tt.method, tt.endpoint); got != tt.want {
+			t.Errorf("isReadEndpoint(%s %s) = %v, want %v", tt.method, tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+// TestErrorCategory tests mapping Notion client errors to bounded metric label values
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "unauthorized", err: &NotionAPIError{Status: http.StatusUnauthorized, Code: "unauthorized"}, want: metrics.ErrorCategoryAuth},
+		{name: "not shared", err: &NotionAPIError{Status: http.StatusNotFound, Code: "object_not_found"}, want: metrics.ErrorCategoryAuth},
+		{name: "rate limited", err: fmt.Errorf("wrapped: %w", &NotionAPIError{Status: http.StatusTooManyRequests}), want: metrics.ErrorCategoryRateLimit},
+		{name: "validation", err: &NotionAPIError{Status: http.StatusBadRequest, Code: "validation_error"}, want: metrics.ErrorCategoryValidation},
+		{name: "conflict", err: &NotionAPIError{Status: http.StatusConflict, Code: "conflict_error"}, want: metrics.ErrorCategoryValidation},
+		{name: "server error", err: &NotionAPIError{Status: http.StatusServiceUnavailable, Body: "<html>"}, want: metrics.ErrorCategoryBackend5xx},
+		{name: "schema mismatch", err: fmt.Errorf("%w: Product Area is missing", ErrSchemaMismatch), want: metrics.ErrorCategoryValidation},
+		{name: "deadline", err: fmt.Errorf("failed to send request: %w", context.DeadlineExceeded), want: metrics.ErrorCategoryTimeout},
+		{name: "network error", err: &url.Error{Op: "Post", URL: "https://api.notion.com", Err: errors.New("connection refused")}, want: metrics.ErrorCategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorCategory(tt.err); got != tt.want {
+				t.Errorf("errorCategory() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package notion
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// PageComments are a submission's comments, written to the body of its page
+// where they aren't limited to a single rich text property.
+type PageComments struct {
+	Text string // Blank lines separate paragraphs
+
+	// Context about the submission, added in a gray line after the comments.
+	// Empty values are left out.
+	SubmittedBy string    // The submitter, e.g. their email
+	Channel     string    // The intake channel (submission.Channel*)
+	SubmittedAt time.Time // When the submission was made
+	ThreadURL   string    // Slack thread where the idea is discussed
+}
+
+// AppendComments appends the comments to the end of a page as paragraph
+// blocks, followed by a line describing who submitted them and where.
+// Requires the integration's "Insert content" capability.
+func (c *Client) AppendComments(pageID string, comments PageComments) error {
+	start := time.Now()
+	blocks := commentBlocks(comments)
+	err := c.appendBlocks(pageID, blocks)
+	c.recordNotionRequest("append_comments", start, err)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Debug("appended comments to notion page",
+		zap.String("page_id", pageID),
+		zap.Int("blocks", len(blocks)),
+	)
+	return nil
+}
+
+// commentBlocks converts comments to paragraph blocks. Paragraphs longer than
+// Notion's 2000 character limit per text object are split over several blocks.
+func commentBlocks(comments PageComments) []map[string]interface{} {
+	var blocks []map[string]interface{}
+	for _, paragraph := range strings.Split(comments.Text, "\n\n") {
+		runes := []rune(strings.TrimSpace(paragraph))
+		for len(runes) > 0 {
+			n := min(len(runes), constants.MaxCommentLength)
+			blocks = append(blocks, paragraphBlock(map[string]interface{}{
+				"type": "text",
+				"text": Text{Content: string(runes[:n])},
+			}))
+			runes = runes[n:]
+		}
+	}
+
+	var context []string
+	if comments.SubmittedBy != "" {
+		context = append(context, "Submitted by "+comments.SubmittedBy)
+	}
+	if comments.Channel != "" {
+		context = append(context, "via "+comments.Channel)
+	}
+	if !comments.SubmittedAt.IsZero() {
+		context = append(context, "on "+comments.SubmittedAt.UTC().Format("2006-01-02 15:04 MST"))
+	}
+	gray := map[string]interface{}{"italic": true, "color": "gray"}
+	var line []interface{}
+	if len(context) > 0 {
+		line = append(line, map[string]interface{}{
+			"type": "text", "text": Text{Content: strings.Join(context, " ")}, "annotations": gray,
+		})
+	}
+	if comments.ThreadURL != "" {
+		if len(line) > 0 {
+			line = append(line, map[string]interface{}{"type": "text", "text": Text{Content: " · "}, "annotations": gray})
+		}
+		line = append(line, map[string]interface{}{
+			"type": "text", "text": Text{Content: "Slack thread", Link: &Link{URL: comments.ThreadURL}}, "annotations": gray,
+		})
+	}
+	if len(line) > 0 {
+		blocks = append(blocks, paragraphBlock(line...))
+	}
+	return blocks
+}
+
+// paragraphBlock returns a paragraph block with the given rich text objects.
+func paragraphBlock(richText ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"object":    "block",
+		"type":      "paragraph",
+		"paragraph": map[string]interface{}{"rich_text": richText},
+	}
+}
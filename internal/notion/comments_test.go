@@ -0,0 +1,81 @@
+package notion
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TestAppendComments tests writing comments as paragraphs followed by the submission context
+func TestAppendComments(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	recorder := &bodyRecorder{bodies: make(map[string][]byte), next: &routeTransport{routes: map[string]*http.Response{
+		"PATCH /v1/blocks/page-1/children": jsonResponse(http.StatusOK, `{"object":"list","results":[]}`),
+	}}}
+	client.httpClient = &http.Client{Transport: recorder}
+
+	err := client.AppendComments("page-1", PageComments{
+		Text:        "Exports time out.\n\n" + strings.Repeat("a", 2500),
+		SubmittedBy: "alice@example.com",
+		Channel:     "slack_modal",
+		SubmittedAt: time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC),
+		ThreadURL:   "https://acme.slack.com/archives/C1/p1",
+	})
+	if err != nil {
+		t.Fatalf("AppendComments() error = %v", err)
+	}
+
+	var body struct {
+		Children []struct {
+			Type      string `json:"type"`
+			Paragraph struct {
+				RichText []struct {
+					Text Text `json:"text"`
+				} `json:"rich_text"`
+			} `json:"paragraph"`
+		} `json:"children"`
+	}
+	if err := json.Unmarshal(recorder.bodies["PATCH /v1/blocks/page-1/children"], &body); err != nil {
+		t.Fatalf("invalid children body: %v", err)
+	}
+	// One paragraph, the long one split in two, and the context line
+	if len(body.Children) != 4 {
+		t.Fatalf("appended %d blocks, want 4", len(body.Children))
+	}
+	if text := body.Children[0].Paragraph.RichText[0].Text.Content; text != "Exports time out." {
+		t.Errorf("first paragraph = %q", text)
+	}
+	if n := len(body.Children[1].Paragraph.RichText[0].Text.Content); n != 2000 {
+		t.Errorf("split paragraph has %d characters, want 2000", n)
+	}
+
+	context := body.Children[3].Paragraph.RichText
+	if len(context) != 3 || context[0].Text.Content != "Submitted by alice@example.com via slack_modal on 2026-10-16 09:30 UTC" {
+		t.Fatalf("context = %+v, want the submitter, channel and time", context)
+	}
+	if link := context[2].Text.Link; link == nil || link.URL != "https://acme.slack.com/archives/C1/p1" {
+		t.Errorf("context link = %+v, want the Slack thread", link)
+	}
+}
+
+// TestAppendComments_ManyParagraphs tests that more blocks than Notion accepts at once are appended in batches
+func TestAppendComments_ManyParagraphs(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	transport := &sequenceTransport{results: []func() (*http.Response, error){
+		respond(http.StatusOK, `{"object":"list","results":[]}`),
+		respond(http.StatusOK, `{"object":"list","results":[]}`),
+	}}
+	client.httpClient = &http.Client{Transport: transport}
+
+	text := strings.Repeat("Another point.\n\n", maxAppendBlocks+10)
+	if err := client.AppendComments("page-1", PageComments{Text: text}); err != nil {
+		t.Fatalf("AppendComments() error = %v", err)
+	}
+	if len(transport.paths) != 2 {
+		t.Errorf("sent %d requests, want 2", len(transport.paths))
+	}
+}
@@ -0,0 +1,267 @@
+package notion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// Feature identifies Notion API behavior that some bot features depend on.
+type Feature string
+
+// Features probed by CheckCompatibility.
+//
+// The bot pins constants.NotionAPIVersion, but what a workspace actually
+// exposes still varies: databases created before multi-source support, an
+// ideas database without a Status column, or an integration without the
+// comment capabilities. Probing at startup lets dependent features be turned
+// off with a clear log line instead of failing on first use.
+const (
+	// FeatureDataSources means databases expose data sources (API 2025-09-03). Required.
+	FeatureDataSources Feature = "data_sources"
+	// FeatureStatusProperty means the ideas database has a constants.FieldStatus status or select property.
+	// Follow-up reminders depend on it.
+	FeatureStatusProperty Feature = "status_property"
+	// FeatureCommentsAPI means the integration can read page comments.
+	FeatureCommentsAPI Feature = "comments_api"
+)
+
+// FeatureStatus is the outcome of a single feature probe.
+type FeatureStatus string
+
+const (
+	// FeatureAvailable means the probe proved the feature works
+	FeatureAvailable FeatureStatus = "available"
+	// FeatureUnavailable means the probe proved the feature does not work for this workspace
+	FeatureUnavailable FeatureStatus = "unavailable"
+	// FeatureUnknown means the probe was inconclusive (network error, rate limit, 5xx, nothing to probe)
+	FeatureUnknown FeatureStatus = "unknown"
+)
+
+// FeatureCheck is the result of probing one feature.
+type FeatureCheck struct {
+	Feature Feature       `json:"feature"`
+	Status  FeatureStatus `json:"status"`
+	Error   string        `json:"error,omitempty"`
+	Hint    string        `json:"hint,omitempty"`
+}
+
+// CompatibilityReport summarizes which Notion API features work for the configured workspace.
+type CompatibilityReport struct {
+	APIVersion string         `json:"api_version"`
+	CheckedAt  time.Time      `json:"checked_at"`
+	Checks     []FeatureCheck `json:"checks"`
+}
+
+// Unavailable reports whether the probe proved the feature does not work.
+//
+// Inconclusive probes count as available, so a transient Notion error at
+// startup doesn't disable a feature for the lifetime of the process.
+func (r *CompatibilityReport) Unavailable(feature Feature) bool {
+	for _, check := range r.Checks {
+		if check.Feature == feature {
+			return check.Status == FeatureUnavailable
+		}
+	}
+	return false
+}
+
+// Statuses returns a feature -> status map, suitable for health check metadata.
+func (r *CompatibilityReport) Statuses() map[string]string {
+	statuses := make(map[string]string, len(r.Checks))
+	for _, check := range r.Checks {
+		statuses[string(check.Feature)] = string(check.Status)
+	}
+	return statuses
+}
+
+// CheckCompatibility probes the Notion API features the bot depends on and returns a report.
+//
+// Like CheckPermissions, probes are read-only and work before
+// InitializeDataSources has run; the status and comments probes need the
+// discovered ideas data source and report "unknown" until then.
+//
+// The latest report is stored on the client and available via LastCompatibilityReport.
+func (c *Client) CheckCompatibility() *CompatibilityReport {
+	report := &CompatibilityReport{
+		APIVersion: c.apiVersion,
+		CheckedAt:  time.Now().UTC(),
+		Checks: []FeatureCheck{
+			c.probeDataSources(),
+			c.probeStatusProperty(),
+			c.probeCommentsAPI(),
+		},
+	}
+
+	c.permissionsMu.Lock()
+	c.lastCompatibility = report
+	c.permissionsMu.Unlock()
+
+	return report
+}
+
+// LastCompatibilityReport returns the most recent compatibility report, or nil if
+// CheckCompatibility has not run yet.
+func (c *Client) LastCompatibilityReport() *CompatibilityReport {
+	c.permissionsMu.RLock()
+	defer c.permissionsMu.RUnlock()
+	return c.lastCompatibility
+}
+
+// probeDataSources verifies the ideas database is exposed as a data source
+// container, or with the legacy API (see SetAPIVersion), that it is readable
+// as the single-source database that API expects.
+func (c *Client) probeDataSources() FeatureCheck {
+	databaseID, _ := c.CurrentDatabases()
+	endpoint := fmt.Sprintf("%s/databases/%s", constants.NotionAPIBaseURL, databaseID)
+	hint := fmt.Sprintf("The workspace must support Notion API %s (multi-source databases). Check that NOTION_DATABASE_ID is a database shared with the integration.", constants.NotionAPIVersion)
+	if c.legacyAPI() {
+		hint = "Check that NOTION_DATABASE_ID is a database shared with the integration, not a page or a linked view."
+	}
+
+	resp, err := c.makeNotionRequest("GET", endpoint, nil)
+	if err != nil {
+		return classifyFeatureProbe(FeatureDataSources, err, hint)
+	}
+	defer resp.Body.Close()
+
+	var dbResponse DatabaseResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dbResponse); err != nil {
+		return FeatureCheck{Feature: FeatureDataSources, Status: FeatureUnknown, Error: fmt.Sprintf("failed to decode database response: %v", err)}
+	}
+	if c.legacyAPI() {
+		if dbResponse.Object != "database" {
+			return FeatureCheck{Feature: FeatureDataSources, Status: FeatureUnavailable, Error: fmt.Sprintf("expected a database, got %q", dbResponse.Object), Hint: hint}
+		}
+		return FeatureCheck{Feature: FeatureDataSources, Status: FeatureAvailable}
+	}
+	if len(dbResponse.DataSources) == 0 {
+		return FeatureCheck{Feature: FeatureDataSources, Status: FeatureUnavailable, Error: "database response has no data sources", Hint: hint}
+	}
+
+	return FeatureCheck{Feature: FeatureDataSources, Status: FeatureAvailable}
+}
+
+// probeStatusProperty verifies the ideas data source has a usable Status property.
+func (c *Client) probeStatusProperty() FeatureCheck {
+	if c.ideasDataSourceID() == "" {
+		return FeatureCheck{Feature: FeatureStatusProperty, Status: FeatureUnknown, Error: "ideas data source not discovered yet"}
+	}
+
+	hint := fmt.Sprintf("Add a %q property of type status or select to the ideas database.", constants.FieldStatus)
+
+	schema, err := c.GetFormSchema()
+	if err != nil {
+		return classifyFeatureProbe(FeatureStatusProperty, err, hint)
+	}
+
+	for _, property := range schema {
+		if property.Name != constants.FieldStatus {
+			continue
+		}
+		if property.Type != "status" && property.Type != "select" {
+			return FeatureCheck{
+				Feature: FeatureStatusProperty,
+				Status:  FeatureUnavailable,
+				Error:   fmt.Sprintf("%s property has type %q", constants.FieldStatus, property.Type),
+				Hint:    hint,
+			}
+		}
+		return FeatureCheck{Feature: FeatureStatusProperty, Status: FeatureAvailable}
+	}
+
+	return FeatureCheck{
+		Feature: FeatureStatusProperty,
+		Status:  FeatureUnavailable,
+		Error:   fmt.Sprintf("ideas database has no %s property", constants.FieldStatus),
+		Hint:    hint,
+	}
+}
+
+// probeCommentsAPI verifies the integration can read comments on an idea page.
+//
+// Comments are listed per page, so the probe needs an existing idea; with an
+// empty database the result is unknown.
+func (c *Client) probeCommentsAPI() FeatureCheck {
+	if c.ideasDataSourceID() == "" {
+		return FeatureCheck{Feature: FeatureCommentsAPI, Status: FeatureUnknown, Error: "ideas data source not discovered yet"}
+	}
+
+	hint := "Enable the \"Read comments\" capability for the integration at https://www.notion.so/my-integrations."
+
+	result, err := c.QueryIdeas(IdeasFilter{}, NewestFirst, "")
+	if err != nil {
+		// Reading ideas is a permission problem (see CheckPermissions), not a comments one
+		return FeatureCheck{Feature: FeatureCommentsAPI, Status: FeatureUnknown, Error: err.Error()}
+	}
+	if len(result.Ideas) == 0 {
+		return FeatureCheck{Feature: FeatureCommentsAPI, Status: FeatureUnknown, Error: "no idea pages to probe"}
+	}
+
+	endpoint := fmt.Sprintf("%s/comments?block_id=%s&page_size=1", constants.NotionAPIBaseURL, result.Ideas[0].ID)
+	return classifyFeatureProbe(FeatureCommentsAPI, c.probe("GET", endpoint, nil), hint)
+}
+
+// classifyFeatureProbe converts a probe error into a FeatureCheck.
+//
+// 400/403/404 mean the workspace or integration doesn't support the request
+// (unavailable); anything else is inconclusive (unknown), as in classifyProbe.
+func classifyFeatureProbe(feature Feature, err error, unavailableHint string) FeatureCheck {
+	check := FeatureCheck{Feature: feature, Status: FeatureAvailable}
+	if err == nil {
+		return check
+	}
+
+	check.Error = err.Error()
+
+	var apiErr *NotionAPIError
+	if !errors.As(err, &apiErr) {
+		check.Status = FeatureUnknown
+		return check
+	}
+
+	switch apiErr.Status {
+	case http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound:
+		check.Status = FeatureUnavailable
+		check.Hint = unavailableHint
+	default:
+		check.Status = FeatureUnknown
+	}
+
+	return check
+}
+
+// LogCompatibilityReport logs each unavailable or inconclusive feature with its hint.
+func LogCompatibilityReport(logger *zap.Logger, report *CompatibilityReport) {
+	problems := 0
+	for _, check := range report.Checks {
+		switch check.Status {
+		case FeatureUnavailable:
+			problems++
+			logger.Warn("notion API feature unavailable for this workspace",
+				zap.String("feature", string(check.Feature)),
+				zap.String("api_version", report.APIVersion),
+				zap.String("error", check.Error),
+				zap.String("hint", check.Hint),
+			)
+		case FeatureUnknown:
+			problems++
+			logger.Info("could not determine notion API feature availability",
+				zap.String("feature", string(check.Feature)),
+				zap.String("error", check.Error),
+			)
+		}
+	}
+
+	if problems == 0 {
+		logger.Info("notion API features verified",
+			zap.String("api_version", report.APIVersion),
+			zap.Int("features", len(report.Checks)),
+		)
+	}
+}
@@ -0,0 +1,114 @@
+package notion
+
+import (
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// TestCheckCompatibility tests feature classification from Notion responses
+func TestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		routes   map[string]*http.Response
+		expected map[Feature]FeatureStatus
+	}{
+		{
+			name: "all features available",
+			routes: map[string]*http.Response{
+				"GET /v1/databases/ideas-db": jsonResponse(http.StatusOK, `{"data_sources":[{"id":"ideas-ds","name":"Ideas"}]}`),
+				"GET /v1/data_sources/ideas-ds": jsonResponse(http.StatusOK,
+					`{"properties":{"Status":{"type":"status"},"Idea/Topic":{"type":"title"}}}`),
+				"POST /v1/data_sources/ideas-ds/query": jsonResponse(http.StatusOK,
+					`{"results":[{"id":"page-1","properties":{}}],"has_more":false}`),
+				"GET /v1/comments": jsonResponse(http.StatusOK, `{"results":[],"has_more":false}`),
+			},
+			expected: map[Feature]FeatureStatus{
+				FeatureDataSources:    FeatureAvailable,
+				FeatureStatusProperty: FeatureAvailable,
+				FeatureCommentsAPI:    FeatureAvailable,
+			},
+		},
+		{
+			name: "older workspace behavior",
+			routes: map[string]*http.Response{
+				"GET /v1/databases/ideas-db": jsonResponse(http.StatusOK, `{"object":"database"}`),
+				"GET /v1/data_sources/ideas-ds": jsonResponse(http.StatusOK,
+					`{"properties":{"Status":{"type":"rich_text"}}}`),
+				"POST /v1/data_sources/ideas-ds/query": jsonResponse(http.StatusOK,
+					`{"results":[{"id":"page-1","properties":{}}],"has_more":false}`),
+				"GET /v1/comments": jsonResponse(http.StatusForbidden,
+					`{"object":"error","status":403,"code":"restricted_resource","message":"Insufficient permissions"}`),
+			},
+			expected: map[Feature]FeatureStatus{
+				FeatureDataSources:    FeatureUnavailable,
+				FeatureStatusProperty: FeatureUnavailable,
+				FeatureCommentsAPI:    FeatureUnavailable,
+			},
+		},
+		{
+			name: "inconclusive probes",
+			routes: map[string]*http.Response{
+				// GET /v1/databases/ideas-db has no route, simulating a network failure
+				"GET /v1/data_sources/ideas-ds": jsonResponse(http.StatusOK, `{"properties":{}}`),
+				"POST /v1/data_sources/ideas-ds/query": jsonResponse(http.StatusOK,
+					`{"results":[],"has_more":false}`),
+			},
+			expected: map[Feature]FeatureStatus{
+				FeatureDataSources:    FeatureUnknown,
+				FeatureStatusProperty: FeatureUnavailable,
+				FeatureCommentsAPI:    FeatureUnknown,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+			client.dataSourceID = "ideas-ds"
+			client.customersDataSourceID = "customers-ds"
+			client.httpClient = &http.Client{Transport: &routeTransport{routes: tt.routes}}
+
+			report := client.CheckCompatibility()
+
+			statuses := report.Statuses()
+			for feature, want := range tt.expected {
+				if statuses[string(feature)] != string(want) {
+					t.Errorf("%s status = %q, want %q", feature, statuses[string(feature)], want)
+				}
+				if report.Unavailable(feature) != (want == FeatureUnavailable) {
+					t.Errorf("Unavailable(%s) = %v with status %q", feature, report.Unavailable(feature), want)
+				}
+			}
+
+			for _, check := range report.Checks {
+				if check.Status == FeatureUnavailable && check.Hint == "" {
+					t.Errorf("%s is unavailable but has no hint", check.Feature)
+				}
+			}
+
+			if client.LastCompatibilityReport() != report {
+				t.Error("LastCompatibilityReport() should return the latest report")
+			}
+		})
+	}
+}
+
+// TestCheckCompatibility_BeforeDiscovery verifies data source dependent probes are inconclusive without a data source
+func TestCheckCompatibility_BeforeDiscovery(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+		"GET /v1/databases/ideas-db": jsonResponse(http.StatusOK, `{"data_sources":[{"id":"ideas-ds","name":"Ideas"}]}`),
+	}}}
+
+	statuses := client.CheckCompatibility().Statuses()
+	if statuses[string(FeatureDataSources)] != string(FeatureAvailable) {
+		t.Errorf("data_sources status = %q, want available", statuses[string(FeatureDataSources)])
+	}
+	for _, feature := range []Feature{FeatureStatusProperty, FeatureCommentsAPI} {
+		if statuses[string(feature)] != string(FeatureUnknown) {
+			t.Errorf("%s status = %q, want unknown", feature, statuses[string(feature)])
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package notion
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/httpclient"
+)
+
+// RotateAPIKey switches clients sharing an integration to its new secret
+// without a restart, e.g. while rotating NOTION_API_KEY. The key is checked
+// first by reading every client's ideas and customers databases with it, so a
+// key that can't reach one of them is rejected and all clients keep the
+// current key. Requests in flight finish with the key they started with.
+func RotateAPIKey(ctx context.Context, apiKey string, clients ...*Client) error {
+	for _, c := range clients {
+		databaseID, customersDBID := c.CurrentDatabases()
+		for _, id := range []string{databaseID, customersDBID} {
+			if err := c.checkAPIKey(ctx, apiKey, id); err != nil {
+				return fmt.Errorf("new API key can't read database %s: %w", id, err)
+			}
+		}
+	}
+
+	for _, c := range clients {
+		c.apiKey.Store(&apiKey)
+	}
+	return nil
+}
+
+// checkAPIKey reads a database container with apiKey instead of the client's key.
+func (c *Client) checkAPIKey(ctx context.Context, apiKey, databaseID string) error {
+	endpoint := fmt.Sprintf("%s/databases/%s", constants.NotionAPIBaseURL, databaseID)
+	req, err := http.NewRequestWithContext(httpclient.Idempotent(ctx), http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Notion-Version", c.apiVersion)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("notion API error (status %d): failed to read response body: %w", resp.StatusCode, err)
+		}
+		return newNotionAPIError(resp.StatusCode, body, resp.Header.Get("X-Request-Id"))
+	}
+	return nil
+}
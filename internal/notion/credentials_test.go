@@ -0,0 +1,66 @@
+package notion
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// keyTransport answers database reads for the keys allowed to read them
+type keyTransport struct {
+	allowed map[string]bool // Authorization header -> can read every database
+	seen    []string        // Authorization headers of every request
+}
+
+func (kt *keyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	auth := req.Header.Get("Authorization")
+	kt.seen = append(kt.seen, auth)
+	if !kt.allowed[auth] {
+		return jsonResponse(http.StatusUnauthorized, `{"object":"error","status":401,"code":"unauthorized","message":"API token is invalid."}`), nil
+	}
+	return jsonResponse(http.StatusOK, `{"object":"database","data_sources":[{"id":"ds"}]}`), nil
+}
+
+// TestRotateAPIKey tests that a key is only swapped in once it can read every client's databases
+func TestRotateAPIKey(t *testing.T) {
+	client := NewClient("secret_old", "ideas-db", "customers-db", zap.NewNop())
+	transport := &keyTransport{allowed: map[string]bool{"Bearer secret_old": true, "Bearer secret_new": true}}
+	client.httpClient = &http.Client{Transport: transport}
+	usersKey := client.sharedCacheKey("users")
+
+	// The second client's integration doesn't accept the new key, so neither switches
+	tenant := NewClient("secret_old", "tenant-ideas-db", "tenant-customers-db", zap.NewNop())
+	tenant.httpClient = &http.Client{Transport: &keyTransport{allowed: map[string]bool{"Bearer secret_old": true}}}
+	if err := RotateAPIKey(context.Background(), "secret_new", client, tenant); err == nil {
+		t.Fatal("RotateAPIKey() succeeded with a key one client can't use")
+	}
+	if *client.apiKey.Load() != "secret_old" || *tenant.apiKey.Load() != "secret_old" {
+		t.Fatal("a rejected rotation switched a client's key")
+	}
+
+	err := RotateAPIKey(context.Background(), "secret_revoked", client)
+	var apiErr *NotionAPIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+		t.Fatalf("RotateAPIKey() with a rejected key error = %v, want a 401 NotionAPIError", err)
+	}
+	if got := *client.apiKey.Load(); got != "secret_old" {
+		t.Fatalf("apiKey = %q after a rejected rotation, want secret_old", got)
+	}
+
+	if err := RotateAPIKey(context.Background(), "secret_new", client); err != nil {
+		t.Fatalf("RotateAPIKey() error = %v", err)
+	}
+	transport.seen = nil
+	if _, err := client.discoverDataSourceID("ideas-db", "main database"); err != nil {
+		t.Fatalf("request after rotation failed: %v", err)
+	}
+	if len(transport.seen) != 1 || transport.seen[0] != "Bearer secret_new" {
+		t.Errorf("Authorization after rotation = %v, want the new key", transport.seen)
+	}
+	if client.sharedCacheKey("users") != usersKey {
+		t.Error("rotating the key changed the shared users cache key")
+	}
+}
@@ -0,0 +1,210 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+)
+
+// descriptionSeparator joins the values of the customer detail properties.
+const descriptionSeparator = " · "
+
+// CustomerDetails is what the Customers database says about a customer
+// besides its name, read from the properties set with SetCustomerProperties.
+type CustomerDetails struct {
+	Aliases     []string `json:"aliases,omitempty"`     // Other names the customer is found by, e.g. a ticker or legal name
+	Description string   `json:"description,omitempty"` // Detail property values, e.g. "EMEA · Enterprise"
+}
+
+// CustomerProperties maps the Customers database's properties to the
+// customers listed in customer selects (see SetCustomerProperties).
+type CustomerProperties struct {
+	Name     string   // title or rich_text property holding the customer name; empty uses the title
+	Aliases  string   // rich_text (comma-, semicolon- or line-separated) or multi_select property of other names; empty disables
+	Details  []string // select, multi_select, status or rich_text properties joined into the description, e.g. Region and Tier
+	Status   string   // Property deciding which customers are listed, of the same types as Details; empty lists all
+	Statuses []string // Values of Status that are listed (case-insensitive), e.g. Active
+
+	// Filter limits the customers query to the pages it matches, in Notion's
+	// query filter syntax, e.g. {"property":"Status","status":{"equals":"Active"}}.
+	// Unlike Status, Notion applies it, so filtered-out pages aren't fetched
+	// at all. Empty queries every page.
+	Filter json.RawMessage
+}
+
+// SetCustomerProperties sets which Customers database properties make up the
+// customers: the property the name is read from, the aliases customer selects
+// also find a customer by, the values (e.g. Region and Tier) joined into the
+// description shown under each option, and the status property and values of
+// the customers listed at all (e.g. only "Active" ones). Rows with another
+// status, or without a name, are left out of the customer cache.
+//
+// Takes effect on the next customer cache refresh.
+func (c *Client) SetCustomerProperties(properties CustomerProperties) {
+	properties.Details = slices.Clone(properties.Details)
+	properties.Statuses = slices.Clone(properties.Statuses)
+	properties.Filter = slices.Clone(properties.Filter)
+	c.settingsMu.Lock()
+	c.customerProperties = properties
+	c.settingsMu.Unlock()
+}
+
+// customerSettings returns the properties set with SetCustomerProperties.
+func (c *Client) customerSettings() CustomerProperties {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.customerProperties
+}
+
+// queryBody builds the body of a Customers database query for the page after
+// cursor ("" for the first), limited to the pages matching the Filter and
+// filter (e.g. a name filter; nil for none).
+func (p CustomerProperties) queryBody(cursor string, filter map[string]interface{}) ([]byte, error) {
+	requestBody := map[string]interface{}{
+		"page_size": constants.NotionPageSize,
+	}
+	if cursor != "" {
+		requestBody["start_cursor"] = cursor
+	}
+
+	switch {
+	case len(p.Filter) > 0 && filter != nil:
+		requestBody["filter"] = map[string]interface{}{"and": []interface{}{filter, p.Filter}}
+	case len(p.Filter) > 0:
+		requestBody["filter"] = p.Filter
+	case filter != nil:
+		requestBody["filter"] = filter
+	}
+
+	body, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return body, nil
+}
+
+// customerName returns the name of the customer a Customers database page's
+// properties describe, or "" if it has none or its status isn't listed.
+func (p CustomerProperties) customerName(properties map[string]interface{}) string {
+	if !p.listed(properties) {
+		return ""
+	}
+	if p.Name == "" {
+		return extractTitleFromProperties(properties)
+	}
+	if values := extractPropertyValues(properties[p.Name]); len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
+
+// listed reports whether the customer with properties has one of the listed
+// statuses, or whether all customers are listed.
+func (p CustomerProperties) listed(properties map[string]interface{}) bool {
+	if p.Status == "" {
+		return true
+	}
+	for _, value := range extractPropertyValues(properties[p.Status]) {
+		for _, status := range p.Statuses {
+			if strings.EqualFold(strings.TrimSpace(value), status) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// extractCustomer reads a Customers database page's name and details. name
+// is "" if the page isn't a listed customer; hasDetails is false when it has
+// neither aliases nor a description.
+func (p CustomerProperties) extractCustomer(properties map[string]interface{}) (name string, details CustomerDetails, hasDetails bool) {
+	if name = p.customerName(properties); name == "" {
+		return "", CustomerDetails{}, false
+	}
+	details, hasDetails = extractCustomerDetails(name, properties, p.Aliases, p.Details)
+	return name, details, hasDetails
+}
+
+// extractCustomerDetails reads a customer's aliases and description from its
+// page properties. ok is false when it has neither.
+func extractCustomerDetails(name string, properties map[string]interface{}, aliasesProperty string, detailProperties []string) (details CustomerDetails, ok bool) {
+	if aliasesProperty != "" {
+		seen := map[string]bool{strings.ToLower(name): true}
+		for _, value := range extractPropertyValues(properties[aliasesProperty]) {
+			for _, alias := range strings.FieldsFunc(value, isAliasSeparator) {
+				alias = strings.TrimSpace(alias)
+				if normalized := strings.ToLower(alias); alias != "" && !seen[normalized] {
+					seen[normalized] = true
+					details.Aliases = append(details.Aliases, alias)
+				}
+			}
+		}
+	}
+
+	var values []string
+	for _, property := range detailProperties {
+		for _, value := range extractPropertyValues(properties[property]) {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	details.Description = strings.Join(values, descriptionSeparator)
+
+	return details, len(details.Aliases) > 0 || details.Description != ""
+}
+
+// isAliasSeparator reports whether r separates aliases in a rich_text property.
+func isAliasSeparator(r rune) bool {
+	return r == ',' || r == ';' || r == '\n'
+}
+
+// extractPropertyValues returns the text values of a select, multi_select,
+// status, title or rich_text property value.
+//
+// Example property value:
+//
+//	{"type": "status", "status": {"name": "Active"}}
+func extractPropertyValues(value interface{}) []string {
+	prop, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	switch prop["type"] {
+	case "rich_text":
+		if text := extractRichTextFromProperty(prop); text != "" {
+			return []string{text}
+		}
+	case "title":
+		if text := extractPlainText(prop["title"]); text != "" {
+			return []string{text}
+		}
+	case "status":
+		status, _ := prop["status"].(map[string]interface{})
+		if name, _ := status["name"].(string); name != "" {
+			return []string{name}
+		}
+	default:
+		return extractOptionNames(prop)
+	}
+	return nil
+}
+
+// extractPlainText joins the plain_text of rich text segments, such as a
+// title property's.
+func extractPlainText(value interface{}) string {
+	segments, _ := value.([]interface{})
+
+	var text strings.Builder
+	for _, segment := range segments {
+		if obj, ok := segment.(map[string]interface{}); ok {
+			plain, _ := obj["plain_text"].(string)
+			text.WriteString(plain)
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,289 @@
+package notion
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// customerWithDetailsResponse is a Customers query response with one page
+// that has aliases and region and tier properties
+const customerWithDetailsResponse = `{"results":[{"id":"page-ibm","properties":{
+	"Name":{"type":"title","title":[{"text":{"content":"International Business Machines"}}]},
+	"Aliases":{"type":"rich_text","rich_text":[{"plain_text":"IBM, Big Blue;"},{"plain_text":"\nibm\ninternational business machines"}]},
+	"Region":{"type":"select","select":{"name":"NA"}},
+	"Tier":{"type":"multi_select","multi_select":[{"name":"Enterprise"},{"name":"Strategic"}]},
+	"Stage":{"type":"status","status":{"name":"Active"}}
+}}],"has_more":false}`
+
+// TestExtractCustomerDetails tests reading aliases and descriptions from customer properties
+func TestExtractCustomerDetails(t *testing.T) {
+	properties := map[string]interface{}{
+		"Aliases": map[string]interface{}{"type": "multi_select", "multi_select": []interface{}{
+			map[string]interface{}{"name": "ACME"}, map[string]interface{}{"name": "acme corp"}, map[string]interface{}{"name": "Road Runner Supplies"},
+		}},
+		"Region": map[string]interface{}{"type": "select", "select": map[string]interface{}{"name": "EMEA"}},
+		"Notes":  map[string]interface{}{"type": "rich_text", "rich_text": []interface{}{map[string]interface{}{"plain_text": " Renewal in Q3 "}}},
+		"Empty":  map[string]interface{}{"type": "select", "select": nil},
+	}
+
+	tests := []struct {
+		name             string
+		aliasesProperty  string
+		detailProperties []string
+		want             CustomerDetails
+		wantOK           bool
+	}{
+		{name: "nothing configured"},
+		{name: "missing properties", aliasesProperty: "Other names", detailProperties: []string{"Tier"}},
+		{name: "empty property", detailProperties: []string{"Empty"}},
+		{
+			name:            "aliases without the name itself",
+			aliasesProperty: "Aliases",
+			want:            CustomerDetails{Aliases: []string{"ACME", "Road Runner Supplies"}},
+			wantOK:          true,
+		},
+		{
+			name:             "description in property order",
+			detailProperties: []string{"Region", "Empty", "Notes"},
+			want:             CustomerDetails{Description: "EMEA · Renewal in Q3"},
+			wantOK:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := extractCustomerDetails("Acme Corp", properties, tt.aliasesProperty, tt.detailProperties)
+			if ok != tt.wantOK || !customerDetailsEqual(got, tt.want) {
+				t.Errorf("extractCustomerDetails() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestInitializeCustomers_Details tests that customer details are fetched,
+// searchable and saved with the caches
+func TestInitializeCustomers_Details(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.customersDataSourceID = "customers-ds"
+	client.httpClient = &http.Client{Transport: &sequenceTransport{results: []func() (*http.Response, error){
+		respond(http.StatusOK, customerWithDetailsResponse),
+	}}}
+	client.SetSnapshotFile(path)
+	client.SetCustomerProperties(CustomerProperties{Aliases: "Aliases", Details: []string{"Region", "Tier", "Stage"}})
+
+	if err := client.InitializeCustomers(); err != nil {
+		t.Fatalf("InitializeCustomers() error = %v", err)
+	}
+	want := CustomerDetails{Aliases: []string{"IBM", "Big Blue"}, Description: "NA · Enterprise · Strategic · Active"}
+	if got := client.Snapshot().CustomerDetails("International Business Machines"); !customerDetailsEqual(got, want) {
+		t.Errorf("CustomerDetails() = %+v, want %+v", got, want)
+	}
+	if got := client.Snapshot().SearchCustomers("big bl", 10); !slices.Equal(got, []string{"International Business Machines"}) {
+		t.Errorf("SearchCustomers(big bl) = %q", got)
+	}
+
+	restarted := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	restarted.SetSnapshotFile(path)
+	if _, err := restarted.RestoreSnapshotFile(); err != nil {
+		t.Fatalf("RestoreSnapshotFile() error = %v", err)
+	}
+	if got := restarted.Snapshot().CustomerDetails("International Business Machines"); !customerDetailsEqual(got, want) {
+		t.Errorf("restored CustomerDetails() = %+v, want %+v", got, want)
+	}
+	if restarted.Snapshot().CustomersChecksum != client.Snapshot().CustomersChecksum {
+		t.Error("restored customers differ from the saved ones")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("snapshot file not saved: %v", err)
+	}
+}
+
+// TestCacheSnapshot_Aliases tests finding customers by alias
+func TestCacheSnapshot_Aliases(t *testing.T) {
+	customers := map[string]string{"Acme Corp": "page-acme", "Acme Labs": "page-labs", "Globex": "page-globex"}
+	details := map[string]CustomerDetails{
+		"page-acme":   {Aliases: []string{"ACME", "Road Runner Supplies"}, Description: "EMEA"},
+		"page-labs":   {Aliases: []string{"acme"}},
+		"page-globex": {Aliases: []string{"Globex Corporation", "GLBX"}},
+	}
+	snapshot := NewCacheSnapshotWithDetails(customers, details, nil)
+
+	tests := []struct {
+		name          string
+		wantCanonical string
+		wantFound     bool
+	}{
+		{name: "glbx", wantCanonical: "Globex", wantFound: true},
+		{name: " road runner supplies ", wantCanonical: "Acme Corp", wantFound: true},
+		{name: "acme labs", wantCanonical: "Acme Labs", wantFound: true}, // Names before aliases
+		{name: "ACME"}, // Alias of two customers
+	}
+	for _, tt := range tests {
+		canonical, _, found := snapshot.FindCustomer(tt.name)
+		if canonical != tt.wantCanonical || found != tt.wantFound {
+			t.Errorf("FindCustomer(%q) = %q, %v, want %q, %v", tt.name, canonical, found, tt.wantCanonical, tt.wantFound)
+		}
+	}
+
+	if got := snapshot.SearchCustomers("acme", 10); !slices.Equal(got, []string{"Acme Corp", "Acme Labs"}) {
+		t.Errorf("SearchCustomers(acme) = %q, want each customer once", got)
+	}
+	if got := snapshot.CustomerDetails("Acme Corp").Description; got != "EMEA" {
+		t.Errorf("CustomerDetails(Acme Corp).Description = %q", got)
+	}
+	if NewCacheSnapshot(customers, nil).CustomersChecksum == snapshot.CustomersChecksum {
+		t.Error("details don't change the customers checksum")
+	}
+}
+
+// TestCustomerProperties_ExtractCustomer tests reading customer names from
+// the configured property and skipping customers with unlisted statuses
+func TestCustomerProperties_ExtractCustomer(t *testing.T) {
+	properties := map[string]interface{}{
+		"Name":         map[string]interface{}{"type": "title", "title": []interface{}{map[string]interface{}{"text": map[string]interface{}{"content": "ACME-001"}}}},
+		"Display Name": map[string]interface{}{"type": "rich_text", "rich_text": []interface{}{map[string]interface{}{"plain_text": " Acme Corp "}}},
+		"Stage":        map[string]interface{}{"type": "status", "status": map[string]interface{}{"name": "Active"}},
+		"Region":       map[string]interface{}{"type": "select", "select": map[string]interface{}{"name": "EMEA"}},
+	}
+
+	tests := []struct {
+		name       string
+		properties CustomerProperties
+		wantName   string
+	}{
+		{name: "title by default", wantName: "ACME-001"},
+		{name: "name property", properties: CustomerProperties{Name: "Display Name"}, wantName: "Acme Corp"},
+		{name: "missing name property", properties: CustomerProperties{Name: "Legal Name"}},
+		{name: "listed status", properties: CustomerProperties{Status: "Stage", Statuses: []string{"Onboarding", "active"}}, wantName: "ACME-001"},
+		{name: "unlisted status", properties: CustomerProperties{Status: "Stage", Statuses: []string{"Churned"}}},
+		{name: "missing status property", properties: CustomerProperties{Status: "Lifecycle", Statuses: []string{"Active"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.properties.Details = []string{"Region"}
+			name, details, hasDetails := tt.properties.extractCustomer(properties)
+			if name != tt.wantName {
+				t.Errorf("extractCustomer() name = %q, want %q", name, tt.wantName)
+			}
+			if wantDetails := tt.wantName != ""; hasDetails != wantDetails || (wantDetails && details.Description != "EMEA") {
+				t.Errorf("extractCustomer() details = %+v, %v", details, hasDetails)
+			}
+		})
+	}
+}
+
+// TestRefreshCustomer_CustomerProperties tests that single customer lookups
+// filter on the name property and drop customers whose status isn't listed
+func TestRefreshCustomer_CustomerProperties(t *testing.T) {
+	const schema = `{"properties":{"Name":{"type":"title"},"Display Name":{"type":"rich_text"},"Stage":{"type":"status"}}}`
+	const churned = `{"results":[{"id":"page-acme","properties":{
+		"Display Name":{"type":"rich_text","rich_text":[{"plain_text":"Acme"}]},
+		"Stage":{"type":"status","status":{"name":"Churned"}}
+	}}],"has_more":false}`
+
+	transport := &sequenceTransport{results: []func() (*http.Response, error){
+		respond(http.StatusOK, schema),
+		respond(http.StatusOK, churned),
+	}}
+	client := newCacheEntryTestClient(transport)
+	client.SetCustomerProperties(CustomerProperties{Name: "Display Name", Status: "Stage", Statuses: []string{"Active"}})
+
+	result, err := client.RefreshCustomer("Acme")
+	if err != nil {
+		t.Fatalf("RefreshCustomer() error = %v", err)
+	}
+	if result.Status != CacheEntryRemoved {
+		t.Errorf("RefreshCustomer() status = %s, want the churned customer removed", result.Status)
+	}
+
+	var query struct {
+		Filter map[string]interface{} `json:"filter"`
+	}
+	if err := json.Unmarshal([]byte(transport.bodies[1]), &query); err != nil {
+		t.Fatalf("invalid query body %q: %v", transport.bodies[1], err)
+	}
+	if query.Filter["property"] != "Display Name" || query.Filter["rich_text"] == nil {
+		t.Errorf("query filter = %v, want a rich_text filter on Display Name", query.Filter)
+	}
+
+	t.Run("unsupported name property", func(t *testing.T) {
+		client := newCacheEntryTestClient(&sequenceTransport{results: []func() (*http.Response, error){
+			respond(http.StatusOK, schema),
+		}})
+		client.SetCustomerProperties(CustomerProperties{Name: "Stage"})
+		if _, err := client.RefreshCustomer("Acme"); err == nil {
+			t.Error("expected an error for a status name property")
+		}
+	})
+}
+
+// TestCustomerProperties_QueryBody tests building customers query bodies with
+// the configured filter
+func TestCustomerProperties_QueryBody(t *testing.T) {
+	active := json.RawMessage(`{"property":"Status","status":{"equals":"Active"}}`)
+	byName := map[string]interface{}{"property": "Name", "title": map[string]interface{}{"contains": "acme"}}
+
+	tests := []struct {
+		name       string
+		filter     json.RawMessage
+		cursor     string
+		nameFilter map[string]interface{}
+		want       string
+	}{
+		{name: "every page", want: `{"page_size":100}`},
+		{name: "next page", cursor: "c2", want: `{"page_size":100,"start_cursor":"c2"}`},
+		{name: "filter", filter: active, want: `{"filter":{"property":"Status","status":{"equals":"Active"}},"page_size":100}`},
+		{name: "name filter", nameFilter: byName, want: `{"filter":{"property":"Name","title":{"contains":"acme"}},"page_size":100}`},
+		{
+			name:       "both filters",
+			filter:     active,
+			nameFilter: byName,
+			want:       `{"filter":{"and":[{"property":"Name","title":{"contains":"acme"}},{"property":"Status","status":{"equals":"Active"}}]},"page_size":100}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := CustomerProperties{Filter: tt.filter}.queryBody(tt.cursor, tt.nameFilter)
+			if err != nil {
+				t.Fatalf("queryBody() error = %v", err)
+			}
+			if string(body) != tt.want {
+				t.Errorf("queryBody() = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
+
+// TestInitializeCustomers_Filter tests that every customers page is queried
+// with the configured filter
+func TestInitializeCustomers_Filter(t *testing.T) {
+	transport := &sequenceTransport{results: []func() (*http.Response, error){
+		respond(http.StatusOK, `{"results":[{"id":"page-acme","properties":{"Name":{"type":"title","title":[{"text":{"content":"Acme"}}]}}}],"has_more":true,"next_cursor":"c2"}`),
+		respond(http.StatusOK, customersQueryResponse([2]string{"Globex", "page-globex"})),
+	}}
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.customersDataSourceID = "customers-ds"
+	client.httpClient = &http.Client{Transport: transport}
+	client.SetCustomerProperties(CustomerProperties{Filter: json.RawMessage(`{"property":"Status","status":{"equals":"Active"}}`)})
+
+	if err := client.InitializeCustomers(); err != nil {
+		t.Fatalf("InitializeCustomers() error = %v", err)
+	}
+	if got := client.Snapshot().CustomerCount(); got != 2 {
+		t.Errorf("CustomerCount() = %d, want 2", got)
+	}
+	for i, body := range transport.bodies {
+		var query struct {
+			Filter map[string]interface{} `json:"filter"`
+		}
+		if err := json.Unmarshal([]byte(body), &query); err != nil || query.Filter["property"] != "Status" {
+			t.Errorf("query %d body = %s, want the Status filter", i, body)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package notion
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/pkg/fuzzy"
+)
+
+// trigramLength is the length, in bytes, of the substrings the contains index is keyed by.
+const trigramLength = 3
+
+// CustomerIndex is a search index over customer names, and their aliases,
+// for the customer selects. It is built once per cache snapshot, so options
+// requests neither lowercase nor scan every customer on each keystroke:
+//   - Exact and prefix matches are a range of the names ordered by their
+//     lowercased form, found with a binary search
+//   - Contains matches are looked up by trigram (queries of 3 bytes or more),
+//     then checked, in alphabetical order so the search stops once it has enough
+//   - Fuzzy matches (pkg/fuzzy) are only looked for when the other tiers come
+//     up short; they need a pass over the names
+//
+// Matching is case-insensitive and ranks like slack.FilterCustomerOptions:
+// exact matches, then prefix matches, then contains matches, each in
+// alphabetical order, then fuzzy matches, fewest edits first. A customer
+// matching through an alias ranks as if its name matched, and is returned
+// once, at its best rank. It is safe for concurrent use.
+type CustomerIndex struct {
+	names    []string           // Customer names in sort.Strings order
+	terms    []string           // Lowercased names and aliases, grouped by customer in names order
+	owners   []int32            // Index into names of each term's customer, parallel to terms
+	byPrefix []int32            // Indexes into terms, ordered by term
+	trigrams map[string][]int32 // Trigram of a term -> ascending indexes into terms
+}
+
+// NewCustomerIndex builds an index over the given customer names, which are copied.
+func NewCustomerIndex(names []string) *CustomerIndex {
+	sorted := slices.Clone(names)
+	sort.Strings(sorted)
+	return newCustomerIndex(sorted, nil)
+}
+
+// newCustomerIndex builds an index over names already in sort.Strings order,
+// which it takes ownership of, and the aliases of each name (nil when none
+// have any).
+func newCustomerIndex(names []string, aliases map[string][]string) *CustomerIndex {
+	index := &CustomerIndex{
+		names:    names,
+		terms:    make([]string, 0, len(names)),
+		owners:   make([]int32, 0, len(names)),
+		trigrams: make(map[string][]int32),
+	}
+	for owner, name := range names {
+		index.addTerm(name, int32(owner))
+		for _, alias := range aliases[name] {
+			index.addTerm(alias, int32(owner))
+		}
+	}
+
+	index.byPrefix = make([]int32, len(index.terms))
+	for n := range index.byPrefix {
+		index.byPrefix[n] = int32(n)
+	}
+	sort.SliceStable(index.byPrefix, func(a, b int) bool {
+		return index.terms[index.byPrefix[a]] < index.terms[index.byPrefix[b]]
+	})
+	return index
+}
+
+// addTerm indexes a name or alias of the customer at names[owner].
+func (i *CustomerIndex) addTerm(term string, owner int32) {
+	n := int32(len(i.terms))
+	normalized := strings.ToLower(term)
+	i.terms = append(i.terms, normalized)
+	i.owners = append(i.owners, owner)
+	for _, trigram := range trigramsOf(normalized) {
+		// Terms are added in order, so each posting list stays ascending; a
+		// trigram repeated in a term is only listed once
+		postings := i.trigrams[trigram]
+		if len(postings) == 0 || postings[len(postings)-1] != n {
+			i.trigrams[trigram] = append(postings, n)
+		}
+	}
+}
+
+// Search returns up to limit customer names matching query (see
+// CustomerIndex). An empty query returns the first names alphabetically.
+func (i *CustomerIndex) Search(query string, limit int) []string {
+	if limit <= 0 {
+		return nil
+	}
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return slices.Clone(i.names[:min(limit, len(i.names))])
+	}
+
+	// Terms starting with the query are contiguous in byPrefix, exact matches first
+	start := sort.Search(len(i.byPrefix), func(n int) bool { return i.terms[i.byPrefix[n]] >= query })
+	end := start + sort.Search(len(i.byPrefix)-start, func(n int) bool {
+		return !strings.HasPrefix(i.terms[i.byPrefix[start+n]], query)
+	})
+	exactEnd := start
+	for exactEnd < end && i.terms[i.byPrefix[exactEnd]] == query {
+		exactEnd++
+	}
+
+	search := customerSearch{index: i, limit: limit, results: make([]string, 0, min(limit, len(i.names)))}
+	for _, owner := range i.smallestOwners(i.byPrefix[start:exactEnd], limit, nil) {
+		search.add(owner)
+	}
+	for _, owner := range i.smallestOwners(i.byPrefix[exactEnd:end], limit-len(search.results), search.found) {
+		search.add(owner)
+	}
+	search.addContains(query)
+	search.addFuzzy(query)
+	return search.results
+}
+
+// customerSearch collects the results of a CustomerIndex search, each
+// customer once.
+type customerSearch struct {
+	index   *CustomerIndex
+	limit   int
+	results []string
+	found   map[int32]bool // Customers in results
+}
+
+// add appends the customer at names[owner] unless it was found already, and
+// reports whether results has room for more.
+func (s *customerSearch) add(owner int32) bool {
+	if len(s.results) < s.limit && !s.found[owner] {
+		if s.found == nil {
+			s.found = make(map[int32]bool)
+		}
+		s.found[owner] = true
+		s.results = append(s.results, s.index.names[owner])
+	}
+	return len(s.results) < s.limit
+}
+
+// addContains adds customers with a term containing query, but not starting
+// with it.
+func (s *customerSearch) addContains(query string) {
+	if len(s.results) == s.limit {
+		return
+	}
+	check := func(n int32) bool {
+		term := s.index.terms[n]
+		if strings.Contains(term, query) && !strings.HasPrefix(term, query) {
+			return s.add(s.index.owners[n])
+		}
+		return true
+	}
+
+	// Queries too short for a trigram are checked against every term
+	if len(query) < trigramLength {
+		for n := range s.index.terms {
+			if !check(int32(n)) {
+				break
+			}
+		}
+		return
+	}
+
+	// Every term containing the query is in the posting list of each of its
+	// trigrams, so checking the shortest one finds them all
+	var candidates []int32
+	for _, trigram := range trigramsOf(query) {
+		postings, found := s.index.trigrams[trigram]
+		if !found {
+			return
+		}
+		if candidates == nil || len(postings) < len(candidates) {
+			candidates = postings
+		}
+	}
+	for _, n := range candidates {
+		if !check(n) {
+			break
+		}
+	}
+}
+
+// addFuzzy adds customers with a term matching query with typos (see
+// pkg/fuzzy), fewest edits first. Terms containing query were matched by the
+// other tiers already.
+func (s *customerSearch) addFuzzy(query string) {
+	maxEdits := fuzzy.MaxEdits(query)
+	if len(s.results) == s.limit || maxEdits == 0 {
+		return
+	}
+
+	// Terms are grouped by customer in alphabetical order, so each bucket is
+	// in order; a customer goes in the bucket of its closest term
+	byEdits := make([][]int32, maxEdits+1)
+	best, bestOwner := maxEdits+1, int32(-1)
+	flush := func() {
+		if best <= maxEdits {
+			byEdits[best] = append(byEdits[best], bestOwner)
+		}
+	}
+	for n, term := range s.index.terms {
+		if owner := s.index.owners[n]; owner != bestOwner {
+			flush()
+			best, bestOwner = maxEdits+1, owner
+		}
+		if edits, ok := fuzzy.Match(query, term); ok && edits > 0 {
+			best = min(best, edits)
+		}
+	}
+	flush()
+
+	for _, bucket := range byEdits {
+		for _, owner := range bucket {
+			if !s.add(owner) {
+				return
+			}
+		}
+	}
+}
+
+// smallestOwners returns the count smallest customers owning the given terms,
+// in ascending order and each once, skipping those in exclude, without
+// sorting all of them. Prefix matches come in lowercased order, which is
+// mostly alphabetical already, so most are rejected with one comparison.
+func (i *CustomerIndex) smallestOwners(terms []int32, count int, exclude map[int32]bool) []int32 {
+	if count <= 0 {
+		return nil
+	}
+	best := make([]int32, 0, min(count, len(terms)))
+	for _, n := range terms {
+		owner := i.owners[n]
+		if len(best) == count && owner >= best[len(best)-1] {
+			continue
+		}
+		at, found := slices.BinarySearch(best, owner)
+		if found || exclude[owner] {
+			continue
+		}
+		if len(best) == count {
+			best = best[:count-1]
+		}
+		best = slices.Insert(best, at, owner)
+	}
+	return best
+}
+
+// trigramsOf returns the byte trigrams of s, in order.
+func trigramsOf(s string) []string {
+	if len(s) < trigramLength {
+		return nil
+	}
+	trigrams := make([]string, 0, len(s)-trigramLength+1)
+	for n := 0; n+trigramLength <= len(s); n++ {
+		trigrams = append(trigrams, s[n:n+trigramLength])
+	}
+	return trigrams
+}
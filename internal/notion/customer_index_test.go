@@ -0,0 +1,99 @@
+package notion
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// TestCustomerIndex_Search tests the ranking of exact, prefix and contains matches
+func TestCustomerIndex_Search(t *testing.T) {
+	index := NewCustomerIndex([]string{"Pineapple Inc", "Apple", "Applied Systems", "apple", "Microsoft", "Snapple", "Application Corp", "Ñandú SA"})
+
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		{"", 3, []string{"Apple", "Application Corp", "Applied Systems"}},
+		{"  APPLE ", 10, []string{"Apple", "apple", "Pineapple Inc", "Snapple", "Application Corp", "Applied Systems"}},
+		{"app", 10, []string{"Apple", "Application Corp", "Applied Systems", "apple", "Pineapple Inc", "Snapple"}},
+		{"app", 2, []string{"Apple", "Application Corp"}},
+		{"pl", 10, []string{"Apple", "Application Corp", "Applied Systems", "Pineapple Inc", "Snapple", "apple"}},
+		{"inc", 10, []string{"Pineapple Inc"}},
+		{"ndú", 10, []string{"Ñandú SA"}},
+		{"xyz", 10, nil},
+		{"snaple", 10, []string{"Snapple"}}, // Typos
+		{"aple", 10, []string{"Apple", "Pineapple Inc", "Snapple", "apple"}},
+		{"aplied", 10, []string{"Applied Systems"}},
+		{"apple", 0, nil},
+		{"apple", 2, []string{"Apple", "apple"}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q/%d", tt.query, tt.limit), func(t *testing.T) {
+			if got := index.Search(tt.query, tt.limit); !slices.Equal(got, tt.want) {
+				t.Errorf("Search(%q, %d) = %q, want %q", tt.query, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCustomerIndex_SearchAliases tests that customers rank by their best
+// matching name or alias, each once
+func TestCustomerIndex_SearchAliases(t *testing.T) {
+	index := newCustomerIndex([]string{"Alphabet Inc", "International Business Machines", "Meta Platforms", "Microsoft"}, map[string][]string{
+		"Alphabet Inc":                    {"Google", "GOOGL"},
+		"International Business Machines": {"IBM", "Big Blue"},
+		"Meta Platforms":                  {"Facebook", "META"},
+	})
+
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		{"ibm", 10, []string{"International Business Machines"}},
+		{"goog", 10, []string{"Alphabet Inc"}},
+		{"meta", 10, []string{"Meta Platforms"}}, // Name and alias match exactly
+		{"m", 10, []string{"Meta Platforms", "Microsoft", "International Business Machines"}},
+		{"i", 10, []string{"International Business Machines", "Alphabet Inc", "Microsoft"}}, // Prefix of a name and an alias
+		{"blue", 10, []string{"International Business Machines"}},
+		{"facebok", 10, []string{"Meta Platforms"}}, // Typo in an alias
+		{"i", 1, []string{"International Business Machines"}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q/%d", tt.query, tt.limit), func(t *testing.T) {
+			if got := index.Search(tt.query, tt.limit); !slices.Equal(got, tt.want) {
+				t.Errorf("Search(%q, %d) = %q, want %q", tt.query, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCacheSnapshot_SearchCustomers tests that snapshots are searched through
+// their own customers
+func TestCacheSnapshot_SearchCustomers(t *testing.T) {
+	snapshot := NewCacheSnapshot(map[string]string{"Acme Corp": "page-a", "Beta Acme": "page-b"}, nil)
+	if got := snapshot.SearchCustomers("acme", 10); !slices.Equal(got, []string{"Acme Corp", "Beta Acme"}) {
+		t.Errorf("SearchCustomers() = %q", got)
+	}
+	if got := NewCacheSnapshot(nil, nil).SearchCustomers("acme", 10); len(got) != 0 {
+		t.Errorf("SearchCustomers() of an empty snapshot = %q", got)
+	}
+}
+
+func BenchmarkCustomerIndexSearch(b *testing.B) {
+	names := make([]string, 50000)
+	for i := range names {
+		names[i] = fmt.Sprintf("Customer %05d Holdings", i)
+	}
+	index := NewCustomerIndex(names)
+
+	for _, query := range []string{"cu", "customer 4", "4999", "holdings", "custmer 4999", "nothing like it"} {
+		b.Run(query, func(b *testing.B) {
+			for b.Loop() {
+				index.Search(query, 100)
+			}
+		})
+	}
+}
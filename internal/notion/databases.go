@@ -0,0 +1,257 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// Database roles reported by ListAccessibleDatabases.
+const (
+	DatabaseRoleIdeas     = "ideas"
+	DatabaseRoleCustomers = "customers"
+)
+
+// requiredIdeasSchema lists the properties (name -> Notion type) the ideas database must have.
+// SwitchDatabases rejects a target missing any of these, since every submission would fail.
+var requiredIdeasSchema = map[string]string{
+	constants.FieldIdeaTopic:     "title",
+	constants.FieldThemeCategory: "multi_select",
+	constants.FieldProductArea:   "select",
+	constants.FieldComments:      "rich_text",
+	constants.FieldCustomerOrg:   "relation",
+	constants.FieldSubmittedBy:   "people",
+}
+
+// DatabaseInfo describes a Notion data source the integration can access.
+type DatabaseInfo struct {
+	DatabaseID   string `json:"database_id"`    // Database container ID (what NOTION_DATABASE_ID expects)
+	DataSourceID string `json:"data_source_id"` // Data source ID within the container
+	Title        string `json:"title"`          // Data source title
+	URL          string `json:"url,omitempty"`  // notion.so URL
+	Role         string `json:"role,omitempty"` // "ideas" or "customers" if currently in use
+}
+
+// CurrentDatabases returns the database container IDs currently in use.
+func (c *Client) CurrentDatabases() (databaseID, customersDBID string) {
+	c.targetMu.RLock()
+	defer c.targetMu.RUnlock()
+	return c.databaseID, c.customersDBID
+}
+
+// ideasDataSourceID returns the data source ID submissions are written to.
+func (c *Client) ideasDataSourceID() string {
+	c.targetMu.RLock()
+	defer c.targetMu.RUnlock()
+	return c.dataSourceID
+}
+
+// customersSourceID returns the data source ID customers are read from.
+func (c *Client) customersSourceID() string {
+	c.targetMu.RLock()
+	defer c.targetMu.RUnlock()
+	return c.customersDataSourceID
+}
+
+// ListAccessibleDatabases lists every data source shared with the integration.
+//
+// Uses the search API filtered to data sources and follows pagination. Databases
+// in use are marked with their role, making it easy to spot the right IDs when
+// setting up a new environment or migrating to a new database.
+//
+// Results are sorted by title.
+func (c *Client) ListAccessibleDatabases() ([]DatabaseInfo, error) {
+	start := time.Now()
+	databaseID, customersDBID := c.CurrentDatabases()
+
+	var databases []DatabaseInfo
+	err := paginate(context.Background(), c, "list_databases", c.searchDataSourcesPage, func(page []DatabaseInfo) bool {
+		databases = append(databases, page...)
+		return true
+	})
+	c.recordNotionRequest("list_databases", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range databases {
+		switch normalizeNotionID(databases[i].DatabaseID) {
+		case normalizeNotionID(databaseID):
+			databases[i].Role = DatabaseRoleIdeas
+		case normalizeNotionID(customersDBID):
+			databases[i].Role = DatabaseRoleCustomers
+		}
+	}
+
+	sort.Slice(databases, func(i, j int) bool {
+		return strings.ToLower(databases[i].Title) < strings.ToLower(databases[j].Title)
+	})
+
+	return databases, nil
+}
+
+// searchDataSourcesPage fetches one page of data sources from the search API,
+// or of databases with the legacy API, where each is its own data source.
+func (c *Client) searchDataSourcesPage(cursor string) (databases []DatabaseInfo, nextCursor string, hasMore bool, err error) {
+	object := "data_source"
+	if c.legacyAPI() {
+		object = "database"
+	}
+	requestBody := map[string]interface{}{
+		"filter":    map[string]interface{}{"property": "object", "value": object},
+		"page_size": constants.NotionPageSize,
+	}
+	if cursor != "" {
+		requestBody["start_cursor"] = cursor
+	}
+
+	body, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/search", constants.NotionAPIBaseURL)
+	resp, err := c.makeNotionRequest("POST", endpoint, body)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+
+	var searchResponse struct {
+		Results []struct {
+			ID    string `json:"id"`
+			URL   string `json:"url"`
+			Title []struct {
+				PlainText string `json:"plain_text"`
+			} `json:"title"`
+			Parent struct {
+				DatabaseID string `json:"database_id"`
+			} `json:"parent"`
+		} `json:"results"`
+		HasMore    bool   `json:"has_more"`
+		NextCursor string `json:"next_cursor"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&searchResponse); err != nil {
+		return nil, "", false, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, result := range searchResponse.Results {
+		var title strings.Builder
+		for _, part := range result.Title {
+			title.WriteString(part.PlainText)
+		}
+		databaseID := result.Parent.DatabaseID
+		if c.legacyAPI() {
+			databaseID = result.ID
+		}
+		databases = append(databases, DatabaseInfo{
+			DatabaseID:   databaseID,
+			DataSourceID: result.ID,
+			Title:        title.String(),
+			URL:          result.URL,
+		})
+	}
+
+	return databases, searchResponse.NextCursor, searchResponse.HasMore, nil
+}
+
+// SwitchDatabases points the client at different ideas and/or Customers databases at runtime.
+//
+// Empty arguments keep the current database. The new targets are fully validated
+// before anything changes:
+// - Each database must be accessible and have a data source
+// - The ideas data source must have every property in requiredIdeasSchema with the right type
+// - The Customers data source must be readable; its customers are fetched up front
+//
+// On success the IDs and customer cache are swapped atomically, so in-flight
+// submissions see either the old or the new configuration, never a mix.
+// The change is not persisted: update NOTION_DATABASE_ID / NOTION_CLIENTS_DB_ID
+// to keep it across restarts.
+func (c *Client) SwitchDatabases(databaseID, customersDBID string) error {
+	currentDatabaseID, currentCustomersDBID := c.CurrentDatabases()
+	if databaseID == "" {
+		databaseID = currentDatabaseID
+	}
+	if customersDBID == "" {
+		customersDBID = currentCustomersDBID
+	}
+
+	dataSourceID, err := c.discoverDataSourceID(databaseID, "main database")
+	if err != nil {
+		return fmt.Errorf("ideas database %s: %w", databaseID, err)
+	}
+	schema, err := c.getDataSourceSchema(dataSourceID)
+	if err != nil {
+		return fmt.Errorf("ideas database %s: failed to read schema: %w", databaseID, err)
+	}
+	if err := validateIdeasSchema(schema); err != nil {
+		return fmt.Errorf("ideas database %s: %w", databaseID, err)
+	}
+
+	customersDataSourceID, err := c.discoverDataSourceID(customersDBID, "customers database")
+	if err != nil {
+		return fmt.Errorf("customers database %s: %w", customersDBID, err)
+	}
+	customers, details, err := c.fetchCustomersFrom(customersDataSourceID)
+	if err != nil {
+		return fmt.Errorf("customers database %s: %w", customersDBID, err)
+	}
+
+	c.targetMu.Lock()
+	c.databaseID = databaseID
+	c.dataSourceID = dataSourceID
+	c.customersDBID = customersDBID
+	c.customersDataSourceID = customersDataSourceID
+	snapshot := c.replaceCustomers(customers, details)
+	c.targetMu.Unlock()
+
+	// The new ideas database was validated above
+	c.setSchemaError(nil)
+
+	if c.metrics != nil {
+		c.metrics.ClientCacheSize.Set(float64(len(customers)))
+		c.recordCacheChecksums(snapshot)
+	}
+	c.saveSnapshotFile()
+
+	c.logger.Info("switched notion databases",
+		zap.String("database_id", databaseID),
+		zap.String("data_source_id", dataSourceID),
+		zap.String("customers_database_id", customersDBID),
+		zap.String("customers_data_source_id", customersDataSourceID),
+		zap.Int("customers", len(customers)),
+	)
+
+	return nil
+}
+
+// validateIdeasSchema checks that schema contains every required property with the expected type.
+// Reports all problems at once so a misconfigured database can be fixed in one pass.
+func validateIdeasSchema(schema map[string]string) error {
+	var problems []string
+	for name, wantType := range requiredIdeasSchema {
+		gotType, ok := schema[name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("missing property %q (%s)", name, wantType))
+		case gotType != wantType:
+			problems = append(problems, fmt.Sprintf("property %q is %s, want %s", name, gotType, wantType))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("%w: %s", ErrSchemaMismatch, strings.Join(problems, "; "))
+}
+
+// normalizeNotionID strips dashes so IDs copied from URLs (no dashes) match API IDs (dashed).
+func normalizeNotionID(id string) string {
+	return strings.ToLower(strings.ReplaceAll(id, "-", ""))
+}
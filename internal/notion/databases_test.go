@@ -0,0 +1,115 @@
+package notion
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+const validIdeasSchemaResponse = `{"object":"data_source","properties":{
+	"Idea/Topic":{"type":"title"},
+	"Theme/Category":{"type":"multi_select"},
+	"Product Area":{"type":"select"},
+	"Comments":{"type":"rich_text"},
+	"Customer Organization":{"type":"relation"},
+	"Submitted by":{"type":"people"}}}`
+
+// TestListAccessibleDatabases tests search parsing, role marking, and sorting
+func TestListAccessibleDatabases(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+		"POST /v1/search": jsonResponse(http.StatusOK, `{"results":[
+			{"object":"data_source","id":"ds-2","title":[{"plain_text":"Ideas"}],"parent":{"type":"database_id","database_id":"ideas-db"}},
+			{"object":"data_source","id":"ds-3","title":[{"plain_text":"Archive"}],"parent":{"type":"database_id","database_id":"archive-db"}},
+			{"object":"data_source","id":"ds-1","title":[{"plain_text":"Customers"}],"parent":{"type":"database_id","database_id":"customers-db"}}
+		],"has_more":false}`),
+	}}}
+
+	databases, err := client.ListAccessibleDatabases()
+	if err != nil {
+		t.Fatalf("ListAccessibleDatabases() returned unexpected error: %v", err)
+	}
+
+	if len(databases) != 3 {
+		t.Fatalf("got %d databases, want 3", len(databases))
+	}
+
+	wantTitles := []string{"Archive", "Customers", "Ideas"}
+	wantRoles := []string{"", DatabaseRoleCustomers, DatabaseRoleIdeas}
+	for i, db := range databases {
+		if db.Title != wantTitles[i] || db.Role != wantRoles[i] {
+			t.Errorf("databases[%d] = %+v, want title %q role %q", i, db, wantTitles[i], wantRoles[i])
+		}
+	}
+}
+
+// TestSwitchDatabases tests validation and the atomic swap
+func TestSwitchDatabases(t *testing.T) {
+	t.Run("valid target", func(t *testing.T) {
+		client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+		client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+			"GET /v1/databases/new-ideas-db": jsonResponse(http.StatusOK, `{"object":"database","data_sources":[{"id":"new-ds","name":"Ideas"}]}`),
+			"GET /v1/data_sources/new-ds":    jsonResponse(http.StatusOK, validIdeasSchemaResponse),
+			"GET /v1/databases/customers-db": jsonResponse(http.StatusOK, `{"object":"database","data_sources":[{"id":"customers-ds","name":"Customers"}]}`),
+			"POST /v1/data_sources/customers-ds/query": jsonResponse(http.StatusOK, `{"results":[
+				{"id":"page-1","properties":{"Name":{"type":"title","title":[{"text":{"content":"Acme"}}]}}}
+			],"has_more":false}`),
+		}}}
+
+		if err := client.SwitchDatabases("new-ideas-db", ""); err != nil {
+			t.Fatalf("SwitchDatabases() returned unexpected error: %v", err)
+		}
+
+		databaseID, customersDBID := client.CurrentDatabases()
+		if databaseID != "new-ideas-db" || customersDBID != "customers-db" {
+			t.Errorf("CurrentDatabases() = %q, %q", databaseID, customersDBID)
+		}
+		if client.ideasDataSourceID() != "new-ds" {
+			t.Errorf("data source = %q, want new-ds", client.ideasDataSourceID())
+		}
+		if customers := client.GetValidCustomers(); len(customers) != 1 || customers[0] != "Acme" {
+			t.Errorf("customers = %v, want [Acme]", customers)
+		}
+	})
+
+	t.Run("schema mismatch leaves configuration unchanged", func(t *testing.T) {
+		client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+		client.dataSourceID = "ideas-ds"
+		client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+			"GET /v1/databases/other-db": jsonResponse(http.StatusOK, `{"object":"database","data_sources":[{"id":"other-ds"}]}`),
+			"GET /v1/data_sources/other-ds": jsonResponse(http.StatusOK,
+				`{"properties":{"Name":{"type":"title"},"Product Area":{"type":"multi_select"}}}`),
+		}}}
+
+		err := client.SwitchDatabases("other-db", "")
+		if err == nil {
+			t.Fatal("expected schema mismatch error")
+		}
+		if !strings.Contains(err.Error(), `missing property "Idea/Topic"`) || !strings.Contains(err.Error(), `"Product Area" is multi_select`) {
+			t.Errorf("error should list all schema problems, got %v", err)
+		}
+
+		databaseID, _ := client.CurrentDatabases()
+		if databaseID != "ideas-db" || client.ideasDataSourceID() != "ideas-ds" {
+			t.Error("configuration should be unchanged after a rejected switch")
+		}
+	})
+}
+
+// TestValidateIdeasSchema verifies the required schema matches the field constants
+func TestValidateIdeasSchema(t *testing.T) {
+	if _, ok := requiredIdeasSchema[constants.FieldIdeaTopic]; !ok {
+		t.Error("required schema should include the title field")
+	}
+	schema := make(map[string]string, len(requiredIdeasSchema))
+	for name, propType := range requiredIdeasSchema {
+		schema[name] = propType
+	}
+	schema["Extra"] = "checkbox"
+	if err := validateIdeasSchema(schema); err != nil {
+		t.Errorf("extra properties should be allowed, got %v", err)
+	}
+}
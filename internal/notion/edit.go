@@ -0,0 +1,279 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// SubmissionPage is an existing idea page read back into a submission, so it can be edited.
+type SubmissionPage struct {
+	ID         string
+	URL        string
+	Archived   bool     // True if the page was deleted (moved to trash) in Notion
+	Submitters []string // Notion user IDs in constants.FieldSubmittedBy
+
+	// Submission holds the core fields. CustomerOrgIDs holds the related
+	// customer page IDs; CustomerOrgs, Extra and Source are not populated.
+	Submission submission.Submission
+}
+
+// GetSubmission fetches an idea page and reads its core properties into a submission.
+func (c *Client) GetSubmission(pageID string) (*SubmissionPage, error) {
+	start := time.Now()
+	page, err := c.getSubmission(pageID)
+	c.recordNotionRequest("get_submission", start, err)
+	return page, err
+}
+
+func (c *Client) getSubmission(pageID string) (*SubmissionPage, error) {
+	endpoint := fmt.Sprintf("%s/pages/%s", constants.NotionAPIBaseURL, pageID)
+	resp, err := c.makeNotionRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var page pageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode page response: %w", err)
+	}
+
+	submitters := extractPropertyIDs(page.Properties[constants.FieldSubmittedBy], "people")
+	sub := submission.Submission{
+		Title:          extractTitleFromProperties(page.Properties),
+		Comments:       extractRichTextFromProperty(page.Properties[constants.FieldComments]),
+		CustomerOrgIDs: extractPropertyIDs(page.Properties[constants.FieldCustomerOrg], "relation"),
+	}
+	if themes := extractOptionNames(page.Properties[constants.FieldThemeCategory]); len(themes) > 0 {
+		sub.Theme = themes[0]
+	}
+	if areas := extractOptionNames(page.Properties[constants.FieldProductArea]); len(areas) > 0 {
+		sub.ProductArea = areas[0]
+	}
+	if len(submitters) > 0 {
+		sub.SubmitterNotionID = submitters[0]
+	}
+
+	return &SubmissionPage{
+		ID:         page.ID,
+		URL:        page.URL,
+		Archived:   page.Archived || page.InTrash,
+		Submitters: submitters,
+		Submission: sub,
+	}, nil
+}
+
+// UpdateSubmission overwrites the core properties of an existing idea page with
+// a submission (PATCH /pages/{id}).
+//
+// The submission is validated like in SubmitSubmission. Optional core fields
+// left empty (comments, customer orgs) are cleared on the page; properties the
+// submission doesn't carry (Status, generated fields without a value) are left
+// untouched, and so are Submitted By, Submitted by (Slack) and Source: the page
+// keeps its original submitters and intake channel.
+// Updates are idempotent, so failures are not retried here.
+//
+// The returned page has the ID and URL of the updated page.
+func (c *Client) UpdateSubmission(ctx context.Context, pageID string, sub submission.Submission) (*CreatedPage, error) {
+	start := time.Now()
+
+	if err := c.SchemaError(); err != nil {
+		c.recordNotionRequest("update_submission", start, err)
+		return nil, fmt.Errorf("submissions are paused: %w", err)
+	}
+
+	properties, err := buildSubmissionProperties(sub, c.SelectOptions())
+	if err != nil {
+		c.recordNotionRequest("update_submission", start, err)
+		return nil, err
+	}
+	if err := c.validateRequiredFields(properties); err != nil {
+		c.recordNotionRequest("update_submission", start, err)
+		return nil, err
+	}
+	delete(properties, constants.FieldSubmittedBy)
+	delete(properties, constants.FieldSubmittedBySlack)
+	delete(properties, constants.FieldSource)
+
+	page, err := c.updateNotionPage(ctx, pageID, properties)
+	c.recordNotionRequest("update_submission", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, c.logger).Debug("updated notion page",
+		zap.String("page_id", page.ID),
+		zap.String("page_url", page.URL),
+	)
+	return page, nil
+}
+
+// UpdatePage overwrites the core properties of an idea page from a legacy field
+// map. Fields are converted with SubmissionFromFields and validated like in
+// SubmitForm, so the map must carry every required field, including
+// submitted_by; see UpdateSubmission for what is changed on the page.
+func (c *Client) UpdatePage(pageID string, fields map[string]string) error {
+	sub, err := SubmissionFromFields(c.Snapshot(), fields)
+	if err != nil {
+		c.recordNotionRequest("update_submission", time.Now(), err)
+		return err
+	}
+	_, err = c.UpdateSubmission(context.Background(), pageID, sub)
+	return err
+}
+
+// ArchivePage archives an idea page (PATCH /pages/{id} with "archived": true).
+// Archived pages move to the Notion trash, where they can still be restored.
+func (c *Client) ArchivePage(pageID string) error {
+	start := time.Now()
+	err := c.archivePage(pageID)
+	c.recordNotionRequest("archive_page", start, err)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Debug("archived notion page", zap.String("page_id", pageID))
+	return nil
+}
+
+func (c *Client) archivePage(pageID string) error {
+	body, err := json.Marshal(map[string]interface{}{"archived": true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/pages/%s", constants.NotionAPIBaseURL, pageID)
+	resp, err := c.makeNotionRequest("PATCH", endpoint, body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// clearedSubmissionFields are the optional core properties UpdateSubmission
+// clears when the submission leaves them empty, with their empty Notion values.
+// Property can't express these, since its fields omit empty values.
+var clearedSubmissionFields = map[string]interface{}{
+	constants.FieldComments:    map[string]interface{}{"rich_text": []interface{}{}},
+	constants.FieldCustomerOrg: map[string]interface{}{"relation": []interface{}{}},
+}
+
+// updateNotionPage makes the API call updating the given properties of a page.
+func (c *Client) updateNotionPage(ctx context.Context, pageID string, properties map[string]Property) (*CreatedPage, error) {
+	update := make(map[string]interface{}, len(properties)+len(clearedSubmissionFields))
+	for name, cleared := range clearedSubmissionFields {
+		update[name] = cleared
+	}
+	for name, prop := range properties {
+		update[name] = prop
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"properties": update})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/pages/%s", constants.NotionAPIBaseURL, pageID)
+	resp, err := c.makeNotionRequestContext(ctx, "PATCH", endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var page CreatedPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode page update response: %w", err)
+	}
+	if page.ID == "" {
+		return nil, errors.New("page update response is missing the page ID")
+	}
+
+	return &page, nil
+}
+
+// extractRichTextFromProperty returns the plain text of a rich_text property value.
+//
+// Example property value:
+//
+//	{"type": "rich_text", "rich_text": [{"plain_text": "Needed for "}, {"plain_text": "EU customers"}]}
+func extractRichTextFromProperty(value interface{}) string {
+	prop, ok := value.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	segments, _ := prop["rich_text"].([]interface{})
+
+	var text strings.Builder
+	for _, segment := range segments {
+		if obj, ok := segment.(map[string]interface{}); ok {
+			plain, _ := obj["plain_text"].(string)
+			text.WriteString(plain)
+		}
+	}
+	return text.String()
+}
+
+// extractOptionNames returns the option names of a select or multi_select property value.
+//
+// Example property values:
+//
+//	{"type": "select", "select": {"name": "AI/ML"}}
+//	{"type": "multi_select", "multi_select": [{"name": "Customer Pain Point"}]}
+func extractOptionNames(value interface{}) []string {
+	prop, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	switch prop["type"] {
+	case "select":
+		option, _ := prop["select"].(map[string]interface{})
+		if name, _ := option["name"].(string); name != "" {
+			return []string{name}
+		}
+	case "multi_select":
+		var names []string
+		options, _ := prop["multi_select"].([]interface{})
+		for _, option := range options {
+			obj, _ := option.(map[string]interface{})
+			if name, _ := obj["name"].(string); name != "" {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// extractPropertyIDs returns the IDs of the objects in a people or relation property value.
+//
+// Example property values:
+//
+//	{"type": "people", "people": [{"object": "user", "id": "user-1"}]}
+//	{"type": "relation", "relation": [{"id": "page-1"}, {"id": "page-2"}]}
+func extractPropertyIDs(value interface{}, propType string) []string {
+	prop, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var ids []string
+	items, _ := prop[propType].([]interface{})
+	for _, item := range items {
+		obj, _ := item.(map[string]interface{})
+		if id, _ := obj["id"].(string); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,209 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/requestid"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// bodyRecorder records request bodies (and the last request's headers) before delegating to another transport
+type bodyRecorder struct {
+	next   http.RoundTripper
+	bodies map[string][]byte
+	header http.Header
+}
+
+func (r *bodyRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.header = req.Header
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		r.bodies[req.Method+" "+req.URL.Path] = body
+	}
+	return r.next.RoundTrip(req)
+}
+
+// TestGetSubmission tests reading an idea page back into a submission
+func TestGetSubmission(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+		"GET /v1/pages/page-1": jsonResponse(http.StatusOK, `{
+			"id": "page-1",
+			"url": "https://www.notion.so/page-1",
+			"properties": {
+				"Idea/Topic": {"type": "title", "title": [{"text": {"content": "Dark mode"}, "plain_text": "Dark mode"}]},
+				"Theme/Category": {"type": "multi_select", "multi_select": [{"name": "New Feature Idea"}]},
+				"Product Area": {"type": "select", "select": {"name": "AI/ML"}},
+				"Comments": {"type": "rich_text", "rich_text": [{"plain_text": "Needed for "}, {"plain_text": "EU customers"}]},
+				"Customer Organization": {"type": "relation", "relation": [{"id": "customer-1"}, {"id": "customer-2"}]},
+				"Submitted by": {"type": "people", "people": [{"object": "user", "id": "user-1"}]}
+			}
+		}`),
+	}}}
+
+	page, err := client.GetSubmission("page-1")
+	if err != nil {
+		t.Fatalf("GetSubmission() error = %v", err)
+	}
+
+	sub := page.Submission
+	if page.ID != "page-1" || page.URL != "https://www.notion.so/page-1" || page.Archived {
+		t.Errorf("page = %+v", page)
+	}
+	if sub.Title != "Dark mode" || sub.Theme != "New Feature Idea" || sub.ProductArea != "AI/ML" {
+		t.Errorf("submission = %+v", sub)
+	}
+	if sub.Comments != "Needed for EU customers" {
+		t.Errorf("Comments = %q, want %q", sub.Comments, "Needed for EU customers")
+	}
+	if len(sub.CustomerOrgIDs) != 2 || sub.CustomerOrgIDs[1] != "customer-2" {
+		t.Errorf("CustomerOrgIDs = %v, want [customer-1 customer-2]", sub.CustomerOrgIDs)
+	}
+	if sub.SubmitterNotionID != "user-1" || len(page.Submitters) != 1 {
+		t.Errorf("SubmitterNotionID = %q, Submitters = %v", sub.SubmitterNotionID, page.Submitters)
+	}
+}
+
+// TestUpdateSubmission tests the PATCH request built from a submission
+func TestUpdateSubmission(t *testing.T) {
+	recorder := &bodyRecorder{
+		bodies: make(map[string][]byte),
+		next: &routeTransport{routes: map[string]*http.Response{
+			"PATCH /v1/pages/page-1": jsonResponse(http.StatusOK, `{"id":"page-1","url":"https://www.notion.so/page-1"}`),
+		}},
+	}
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.httpClient = &http.Client{Transport: recorder}
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	page, err := client.UpdateSubmission(ctx, "page-1", submission.Submission{
+		Title:             "Dark mode everywhere",
+		Theme:             "New Feature Idea",
+		ProductArea:       "AI/ML",
+		SubmitterNotionID: "user-1",
+	})
+	if err != nil {
+		t.Fatalf("UpdateSubmission() error = %v", err)
+	}
+	if page.ID != "page-1" {
+		t.Errorf("page ID = %q, want page-1", page.ID)
+	}
+	if got := recorder.header.Get(requestid.Header); got != "req-1" {
+		t.Errorf("%s header = %q, want the request ID from the context", requestid.Header, got)
+	}
+
+	var request struct {
+		Properties map[string]map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(recorder.bodies["PATCH /v1/pages/page-1"], &request); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+
+	if _, ok := request.Properties[constants.FieldIdeaTopic]["title"]; !ok {
+		t.Error("title should be updated")
+	}
+	if _, ok := request.Properties[constants.FieldSubmittedBy]; ok {
+		t.Error("Submitted By should not be changed")
+	}
+	if got := string(request.Properties[constants.FieldComments]["rich_text"]); got != "[]" {
+		t.Errorf("empty comments should be cleared, got rich_text = %s", got)
+	}
+	if got := string(request.Properties[constants.FieldCustomerOrg]["relation"]); got != "[]" {
+		t.Errorf("empty customer orgs should be cleared, got relation = %s", got)
+	}
+}
+
+// TestUpdateSubmission_Validation tests that invalid submissions are rejected without an API call
+func TestUpdateSubmission_Validation(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.httpClient = &http.Client{Transport: &routeTransport{}}
+
+	_, err := client.UpdateSubmission(context.Background(), "page-1", submission.Submission{
+		Title:             "Dark mode",
+		Theme:             "Not a theme",
+		ProductArea:       "AI/ML",
+		SubmitterNotionID: "user-1",
+	})
+	if err == nil {
+		t.Error("expected an error for an invalid theme")
+	}
+}
+
+// TestUpdatePage tests updating a page from a legacy field map
+func TestUpdatePage(t *testing.T) {
+	recorder := &bodyRecorder{
+		bodies: make(map[string][]byte),
+		next: &routeTransport{routes: map[string]*http.Response{
+			"PATCH /v1/pages/page-1": jsonResponse(http.StatusOK, `{"id":"page-1","url":"https://www.notion.so/page-1"}`),
+		}},
+	}
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.httpClient = &http.Client{Transport: recorder}
+
+	err := client.UpdatePage("page-1", map[string]string{
+		"title":        "Dark mode everywhere",
+		"theme":        "New Feature Idea",
+		"product_area": "AI/ML",
+		"comments":     "Please",
+		"submitted_by": "user-1",
+	})
+	if err != nil {
+		t.Fatalf("UpdatePage() error = %v", err)
+	}
+
+	var request struct {
+		Properties map[string]map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(recorder.bodies["PATCH /v1/pages/page-1"], &request); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	if got := string(request.Properties[constants.FieldComments]["rich_text"]); got == "[]" {
+		t.Error("comments should be updated")
+	}
+
+	// Missing required fields and unknown fields are rejected without an API call
+	for _, fields := range []map[string]string{
+		{"title": "Dark mode", "theme": "New Feature Idea", "product_area": "AI/ML"},
+		{"title": "Dark mode", "unknown": "value"},
+	} {
+		if err := client.UpdatePage("page-2", fields); err == nil {
+			t.Errorf("expected an error for fields %v", fields)
+		}
+	}
+}
+
+// TestArchivePage tests that archiving sends archived: true and surfaces API errors
+func TestArchivePage(t *testing.T) {
+	recorder := &bodyRecorder{
+		bodies: make(map[string][]byte),
+		next: &routeTransport{routes: map[string]*http.Response{
+			"PATCH /v1/pages/page-1": jsonResponse(http.StatusOK, `{"id":"page-1","archived":true}`),
+			"PATCH /v1/pages/page-2": jsonResponse(http.StatusNotFound, `{"object":"error","code":"object_not_found"}`),
+		}},
+	}
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.httpClient = &http.Client{Transport: recorder}
+
+	if err := client.ArchivePage("page-1"); err != nil {
+		t.Fatalf("ArchivePage() error = %v", err)
+	}
+	if got := string(recorder.bodies["PATCH /v1/pages/page-1"]); got != `{"archived":true}` {
+		t.Errorf("request body = %s, want {\"archived\":true}", got)
+	}
+
+	err := client.ArchivePage("page-2")
+	var apiErr *NotionAPIError
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrorCodeObjectNotFound {
+		t.Errorf("ArchivePage() error = %v, want object_not_found API error", err)
+	}
+}
@@ -0,0 +1,160 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// FileAttachment is a file to add to the body of a page.
+type FileAttachment struct {
+	Name        string
+	ContentType string // MIME type; images are shown inline and PDFs embedded
+
+	// Content is uploaded with the Notion file upload API. When it is nil,
+	// ExternalURL is linked instead (e.g. the file's Slack permalink).
+	Content     []byte
+	ExternalURL string
+}
+
+// maxAppendBlocks is the most blocks Notion accepts in one append request.
+const maxAppendBlocks = 100
+
+// fileUpload is the part of a Notion file upload object used here.
+type fileUpload struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// AttachFile appends a file block to the end of a page: uploaded to Notion when
+// file.Content is set, otherwise linking file.ExternalURL.
+//
+// Uploads are single-part, so Content must be at most 20 MB. Requires the
+// integration's "Insert content" capability.
+func (c *Client) AttachFile(pageID string, file FileAttachment) error {
+	start := time.Now()
+	err := c.attachFile(pageID, file)
+	c.recordNotionRequest("attach_file", start, err)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Debug("attached file to notion page",
+		zap.String("page_id", pageID),
+		zap.String("name", file.Name),
+		zap.Bool("uploaded", file.Content != nil),
+	)
+	return nil
+}
+
+func (c *Client) attachFile(pageID string, file FileAttachment) error {
+	source := map[string]interface{}{"type": "external", "external": map[string]string{"url": file.ExternalURL}}
+	if file.Content != nil {
+		uploadID, err := c.uploadFile(file)
+		if err != nil {
+			return err
+		}
+		source = map[string]interface{}{"type": "file_upload", "file_upload": map[string]string{"id": uploadID}}
+	}
+
+	blockType := fileBlockType(file.ContentType)
+	if blockType == "file" {
+		source["name"] = file.Name
+	}
+	return c.appendBlocks(pageID, []map[string]interface{}{{
+		"object":  "block",
+		"type":    blockType,
+		blockType: source,
+	}})
+}
+
+// appendBlocks appends blocks to the end of a page with the block children
+// endpoint, which accepts up to maxAppendBlocks blocks per request.
+func (c *Client) appendBlocks(pageID string, children []map[string]interface{}) error {
+	endpoint := fmt.Sprintf("%s/blocks/%s/children", constants.NotionAPIBaseURL, pageID)
+	for start := 0; start < len(children); start += maxAppendBlocks {
+		body, err := json.Marshal(map[string]interface{}{
+			"children": children[start:min(start+maxAppendBlocks, len(children))],
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		resp, err := c.makeNotionRequest("PATCH", endpoint, body)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// uploadFile creates a single-part file upload and sends the file's content,
+// returning the upload ID to reference from a block.
+func (c *Client) uploadFile(file FileAttachment) (string, error) {
+	body, err := json.Marshal(map[string]string{"filename": file.Name, "content_type": file.ContentType})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	resp, err := c.makeNotionRequest("POST", constants.NotionAPIBaseURL+"/file_uploads", body)
+	if err != nil {
+		return "", err
+	}
+	var upload fileUpload
+	err = json.NewDecoder(resp.Body).Decode(&upload)
+	resp.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to decode file upload response: %w", err)
+	}
+	if upload.ID == "" {
+		return "", fmt.Errorf("file upload response is missing the upload ID")
+	}
+
+	var content bytes.Buffer
+	writer := multipart.NewWriter(&content)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, file.Name))
+	header.Set("Content-Type", file.ContentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to build file upload: %w", err)
+	}
+	part.Write(file.Content)
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to build file upload: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/file_uploads/%s/send", constants.NotionAPIBaseURL, upload.ID)
+	resp, err = c.makeNotionRequestWithContentType(context.Background(), "POST", endpoint, writer.FormDataContentType(), content.Bytes())
+	if err != nil {
+		return "", err
+	}
+	err = json.NewDecoder(resp.Body).Decode(&upload)
+	resp.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to decode file upload response: %w", err)
+	}
+	if upload.Status != "uploaded" {
+		return "", fmt.Errorf("file upload %s is %q after sending its content", upload.ID, upload.Status)
+	}
+	return upload.ID, nil
+}
+
+// fileBlockType returns the Notion block type for a file: images are shown
+// inline, PDFs embedded, and anything else as a downloadable file.
+func fileBlockType(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return "image"
+	case contentType == "application/pdf":
+		return "pdf"
+	}
+	return "file"
+}
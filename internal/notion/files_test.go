@@ -0,0 +1,83 @@
+package notion
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// TestAttachFile tests uploading a file and appending it to the page as a block
+func TestAttachFile(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	recorder := &bodyRecorder{bodies: make(map[string][]byte), next: &routeTransport{routes: map[string]*http.Response{
+		"POST /v1/file_uploads":               jsonResponse(http.StatusOK, `{"id":"upload-1","status":"pending"}`),
+		"POST /v1/file_uploads/upload-1/send": jsonResponse(http.StatusOK, `{"id":"upload-1","status":"uploaded"}`),
+		"PATCH /v1/blocks/page-1/children":    jsonResponse(http.StatusOK, `{"object":"list","results":[]}`),
+	}}}
+	client.httpClient = &http.Client{Transport: recorder}
+
+	err := client.AttachFile("page-1", FileAttachment{Name: "screenshot.png", ContentType: "image/png", Content: []byte("png")})
+	if err != nil {
+		t.Fatalf("AttachFile() error = %v", err)
+	}
+
+	sent := string(recorder.bodies["POST /v1/file_uploads/upload-1/send"])
+	if !strings.Contains(sent, `filename="screenshot.png"`) || !strings.Contains(sent, "Content-Type: image/png") {
+		t.Errorf("multipart body = %q, want the file with its name and type", sent)
+	}
+	var children struct {
+		Children []struct {
+			Type  string `json:"type"`
+			Image struct {
+				Type       string            `json:"type"`
+				FileUpload map[string]string `json:"file_upload"`
+			} `json:"image"`
+		} `json:"children"`
+	}
+	if err := json.Unmarshal(recorder.bodies["PATCH /v1/blocks/page-1/children"], &children); err != nil {
+		t.Fatalf("invalid children body: %v", err)
+	}
+	if len(children.Children) != 1 || children.Children[0].Type != "image" || children.Children[0].Image.FileUpload["id"] != "upload-1" {
+		t.Errorf("children = %+v, want an image block referencing the upload", children)
+	}
+}
+
+// TestAttachFile_External tests linking a file that wasn't uploaded
+func TestAttachFile_External(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	recorder := &bodyRecorder{bodies: make(map[string][]byte), next: &routeTransport{routes: map[string]*http.Response{
+		"PATCH /v1/blocks/page-1/children": jsonResponse(http.StatusOK, `{"object":"list","results":[]}`),
+	}}}
+	client.httpClient = &http.Client{Transport: recorder}
+
+	err := client.AttachFile("page-1", FileAttachment{Name: "notes.docx", ContentType: "application/msword", ExternalURL: "https://acme.slack.com/files/U1/F1/notes.docx"})
+	if err != nil {
+		t.Fatalf("AttachFile() error = %v", err)
+	}
+	body := string(recorder.bodies["PATCH /v1/blocks/page-1/children"])
+	if !strings.Contains(body, `"type":"file"`) || !strings.Contains(body, `"url":"https://acme.slack.com/files/U1/F1/notes.docx"`) || !strings.Contains(body, `"name":"notes.docx"`) {
+		t.Errorf("children = %s, want a named file block linking the URL", body)
+	}
+	if _, uploaded := recorder.bodies["POST /v1/file_uploads"]; uploaded {
+		t.Error("a file without content was uploaded")
+	}
+}
+
+// TestAttachFile_UploadFails tests that nothing is appended when the upload fails
+func TestAttachFile_UploadFails(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+		"POST /v1/file_uploads": jsonResponse(http.StatusForbidden,
+			`{"object":"error","status":403,"code":"restricted_resource","message":"Insufficient permissions"}`),
+	}}}
+
+	err := client.AttachFile("page-1", FileAttachment{Name: "report.pdf", ContentType: "application/pdf", Content: []byte("%PDF")})
+	var apiErr *NotionAPIError
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrorCodeRestricted {
+		t.Errorf("AttachFile() error = %v, want the Notion API error", err)
+	}
+}
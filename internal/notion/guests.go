@@ -0,0 +1,60 @@
+package notion
+
+import (
+	"slices"
+	"strings"
+)
+
+// SetAllowedEmailDomains restricts submitter mapping to users whose email domain is
+// in domains (e.g., "example.com"). Users outside the allowlist, typically external
+// guests invited to individual pages, are kept out of the user cache and reported
+// as guests instead. An empty list (the default) allows every domain.
+//
+// Takes effect on the next user cache refresh.
+func (c *Client) SetAllowedEmailDomains(domains []string) {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		if domain = normalizeDomain(domain); domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	c.settingsMu.Lock()
+	c.allowedDomains = normalized
+	c.settingsMu.Unlock()
+}
+
+// partitionUsers splits fetched users into workspace members (allowed domains) and external guests.
+// With no allowlist every user is a member.
+func (c *Client) partitionUsers(all map[string]string) (members, guests map[string]string) {
+	c.settingsMu.RLock()
+	allowed := c.allowedDomains
+	c.settingsMu.RUnlock()
+	if len(allowed) == 0 {
+		return all, nil
+	}
+
+	members = make(map[string]string, len(all))
+	guests = make(map[string]string)
+	for email, userID := range all {
+		if slices.Contains(allowed, emailDomain(email)) {
+			members[email] = userID
+		} else {
+			guests[email] = userID
+		}
+	}
+	return members, guests
+}
+
+// emailDomain returns the lowercase domain of an email address, or "" if it has none.
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return normalizeDomain(email[at+1:])
+}
+
+// normalizeDomain lowercases a domain and strips surrounding whitespace and a leading "@".
+func normalizeDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+}
@@ -0,0 +1,86 @@
+package notion
+
+import (
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// TestInitializeUsers_DomainAllowlist tests that users outside allowed domains are mapped as guests
+func TestInitializeUsers_DomainAllowlist(t *testing.T) {
+	tests := []struct {
+		name        string
+		domains     []string
+		wantMembers []string
+		wantGuests  []string
+	}{
+		{
+			name:        "no allowlist",
+			domains:     nil,
+			wantMembers: []string{"alice@example.com", "bob@partner.io"},
+		},
+		{
+			name:        "allowlist excludes partner",
+			domains:     []string{" @Example.com "},
+			wantMembers: []string{"alice@example.com"},
+			wantGuests:  []string{"bob@partner.io"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("test-key", "db-id", "clients-db-id", zap.NewNop())
+			client.SetAllowedEmailDomains(tt.domains)
+			client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+				"GET /v1/users": jsonResponse(http.StatusOK, `{
+					"results": [
+						{"object": "user", "id": "u-alice", "type": "person", "person": {"email": "Alice@Example.com"}},
+						{"object": "user", "id": "u-bob", "type": "person", "person": {"email": "bob@partner.io"}},
+						{"object": "user", "id": "u-bot", "type": "bot", "bot": {}}
+					],
+					"has_more": false
+				}`),
+			}}}
+
+			if err := client.InitializeUsers(); err != nil {
+				t.Fatalf("InitializeUsers() error = %v", err)
+			}
+
+			snapshot := client.Snapshot()
+			if snapshot.UserCount() != len(tt.wantMembers) {
+				t.Errorf("UserCount() = %d, want %d", snapshot.UserCount(), len(tt.wantMembers))
+			}
+			for _, email := range tt.wantMembers {
+				if _, found := snapshot.NotionUserIDByEmail(email); !found {
+					t.Errorf("%s should be mapped", email)
+				}
+			}
+			for _, email := range tt.wantGuests {
+				if _, found := snapshot.NotionUserIDByEmail(email); found {
+					t.Errorf("%s should not be mapped", email)
+				}
+				if !snapshot.IsExternalGuest(email) {
+					t.Errorf("%s should be reported as an external guest", email)
+				}
+			}
+			if snapshot.GuestCount() != len(tt.wantGuests) {
+				t.Errorf("GuestCount() = %d, want %d", snapshot.GuestCount(), len(tt.wantGuests))
+			}
+		})
+	}
+}
+
+// TestEmailDomain tests domain extraction from emails
+func TestEmailDomain(t *testing.T) {
+	tests := map[string]string{
+		"user@Example.COM":     "example.com",
+		"first.last@a.b.co.uk": "a.b.co.uk",
+		"no-at-sign":           "",
+	}
+	for email, want := range tests {
+		if got := emailDomain(email); got != want {
+			t.Errorf("emailDomain(%q) = %q, want %q", email, got, want)
+		}
+	}
+}
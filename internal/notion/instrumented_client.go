@@ -2,20 +2,38 @@ package notion
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"net/http/httptrace"
+	"strconv"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rudderlabs/hopperbot/pkg/metrics"
 )
 
 // SetMetrics sets the metrics instance for the client
 func (c *Client) SetMetrics(m *metrics.Metrics) {
 	c.metrics = m
+	if c.transport != nil {
+		c.transport.SetMetrics(m)
+	}
 	// Update customer cache size metric
 	if m != nil {
-		c.cacheMu.RLock()
-		size := len(c.customerMap)
-		c.cacheMu.RUnlock()
-		m.ClientCacheSize.Set(float64(size))
+		m.ClientCacheSize.Set(float64(c.Snapshot().CustomerCount()))
+		c.recordCacheChecksums(c.Snapshot())
+	}
+}
+
+// recordCacheChecksums exports the snapshot's content checksums, replacing the
+// previous ones. Cache labels match the cache manager's (cache.CacheType*).
+func (c *Client) recordCacheChecksums(snapshot *CacheSnapshot) {
+	if c.metrics == nil {
+		return
+	}
+	for cacheType, sum := range map[string]string{"customers": snapshot.CustomersChecksum, "users": snapshot.UsersChecksum} {
+		c.metrics.CacheChecksum.DeletePartialMatch(prometheus.Labels{"cache": cacheType})
+		c.metrics.CacheChecksum.WithLabelValues(cacheType, sum).Set(1)
 	}
 }
 
@@ -31,21 +49,112 @@ func (c *Client) recordNotionRequest(operation string, startTime time.Time, err
 	status := "success"
 	if err != nil {
 		status = "error"
-		errorType := "unknown"
-		switch err {
-		case context.DeadlineExceeded:
-			errorType = "timeout"
-		case context.Canceled:
-			errorType = "canceled"
-		default:
-			errorType = "api_error"
-		}
-		c.metrics.NotionAPIErrors.WithLabelValues(operation, errorType).Inc()
+		c.metrics.NotionAPIErrors.WithLabelValues(operation, errorCategory(err)).Inc()
 	}
 
 	c.metrics.NotionAPIRequestsTotal.WithLabelValues(operation, status).Inc()
 }
 
+// recordPaginatedPage counts a page of a paginated list read by operation.
+func (c *Client) recordPaginatedPage(operation string) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.NotionPaginatedPages.WithLabelValues(operation).Inc()
+}
+
+// recordPageLimitReached counts a paginated list that stopped at its page limit.
+func (c *Client) recordPageLimitReached(operation string) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.NotionPageLimitReached.WithLabelValues(operation).Inc()
+}
+
+// errorCategory maps a Notion client error to one of metrics.ErrorCategories.
+// API errors are categorized by their Notion error code, falling back to the
+// HTTP status; object_not_found counts as auth because Notion returns it for
+// pages and databases that aren't shared with the integration.
+func errorCategory(err error) string {
+	if metrics.IsTimeoutError(err) {
+		return metrics.ErrorCategoryTimeout
+	}
+	if errors.Is(err, ErrSchemaMismatch) {
+		return metrics.ErrorCategoryValidation
+	}
+
+	var apiErr *NotionAPIError
+	if !errors.As(err, &apiErr) {
+		return metrics.ErrorCategoryUnknown
+	}
+	switch apiErr.Code {
+	case ErrorCodeUnauthorized, ErrorCodeRestricted, ErrorCodeObjectNotFound:
+		return metrics.ErrorCategoryAuth
+	case ErrorCodeRateLimited:
+		return metrics.ErrorCategoryRateLimit
+	case ErrorCodeValidation, "invalid_json", "invalid_request", "invalid_request_url", "missing_version":
+		return metrics.ErrorCategoryValidation
+	}
+	return metrics.StatusErrorCategory(apiErr.Status)
+}
+
+// recordSchemaValid updates the ideas database schema gauge.
+func (c *Client) recordSchemaValid(valid bool) {
+	if c.metrics == nil {
+		return
+	}
+	value := 0.0
+	if valid {
+		value = 1
+	}
+	c.metrics.NotionSchemaValid.Set(value)
+}
+
+// recordPermissions updates the per-capability permission gauge.
+// Granted capabilities are 1, missing are 0; inconclusive probes leave the previous value.
+func (c *Client) recordPermissions(report *PermissionReport) {
+	if c.metrics == nil {
+		return
+	}
+
+	for _, check := range report.Checks {
+		switch check.Status {
+		case PermissionGranted:
+			c.metrics.NotionPermissionGranted.WithLabelValues(string(check.Capability)).Set(1)
+		case PermissionMissing:
+			c.metrics.NotionPermissionGranted.WithLabelValues(string(check.Capability)).Set(0)
+		}
+	}
+}
+
+// connectionTrace returns an httptrace.ClientTrace that records connection reuse
+// and the duration of DNS, TCP connect and TLS handshake phases for new
+// connections. Returns nil if metrics are disabled.
+func (c *Client) connectionTrace() *httptrace.ClientTrace {
+	if c.metrics == nil {
+		return nil
+	}
+
+	var dnsStart, connectStart, tlsStart time.Time
+	observe := func(phase string, start time.Time) {
+		if !start.IsZero() {
+			c.metrics.NotionConnectionPhaseDuration.WithLabelValues(phase).Observe(time.Since(start).Seconds())
+		}
+	}
+
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			c.metrics.NotionConnectionsTotal.WithLabelValues(strconv.FormatBool(info.Reused)).Inc()
+		},
+		DNSStart:          func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { observe("dns", dnsStart) },
+		ConnectStart:      func(string, string) { connectStart = time.Now() },
+		ConnectDone:       func(string, string, error) { observe("connect", connectStart) },
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { observe("tls", tlsStart) },
+	}
+}
+
 // HealthCheck performs a lightweight health check to verify Notion API connectivity
 func (c *Client) HealthCheck(ctx context.Context) error {
 	start := time.Now()
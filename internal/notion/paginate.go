@@ -0,0 +1,58 @@
+package notion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// maxPaginatedPages is how many pages paginate follows before giving up. At
+// constants.NotionPageSize results per page that is 100,000 customers or
+// users, beyond any workspace, so reaching it means the API keeps handing out
+// cursors rather than that the list is that long.
+const maxPaginatedPages = 1000
+
+// errPageLimit is returned when pages remain after the page limit.
+var errPageLimit = errors.New("page limit reached")
+
+// pageFetcher fetches the page of a paginated Notion list at cursor ("" for
+// the first page), returning its items and the cursor of the next page.
+type pageFetcher[T any] func(cursor string) (items T, nextCursor string, hasMore bool, err error)
+
+// paginate follows a paginated Notion list from its first page, passing the
+// items of each page to visit until visit returns false or the last page is
+// read. It fails with errPageLimit after maxPaginatedPages pages and with the
+// context's error once ctx is done; fetch errors are returned as they are.
+func paginate[T any](ctx context.Context, c *Client, operation string, fetchPage pageFetcher[T], visit func(items T) bool) error {
+	_, err := paginateFrom(ctx, c, operation, "", maxPaginatedPages, fetchPage, visit)
+	return err
+}
+
+// paginateFrom is paginate starting at cursor and reading at most maxPages
+// pages. When it stops at the limit, the returned cursor continues the list.
+//
+// Pages read are counted by operation in
+// hopperbot_notion_paginated_pages_total, and stops at the limit in
+// hopperbot_notion_page_limit_reached_total.
+func paginateFrom[T any](ctx context.Context, c *Client, operation, cursor string, maxPages int, fetchPage pageFetcher[T], visit func(items T) bool) (string, error) {
+	for page := 0; ; page++ {
+		if page == maxPages {
+			c.recordPageLimitReached(operation)
+			return cursor, fmt.Errorf("%w: more than %d pages", errPageLimit, maxPages)
+		}
+		if err := ctx.Err(); err != nil {
+			return cursor, err
+		}
+
+		items, nextCursor, hasMore, err := fetchPage(cursor)
+		if err != nil {
+			return cursor, err
+		}
+		c.recordPaginatedPage(operation)
+
+		if !visit(items) || !hasMore || nextCursor == "" {
+			return "", nil
+		}
+		cursor = nextCursor
+	}
+}
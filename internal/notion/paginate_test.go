@@ -0,0 +1,106 @@
+package notion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// fakeList serves a paginated list of pages, one item per page; an empty
+// cursor is the first page
+type fakeList struct {
+	pages   int
+	cursors []string // Cursors fetched, in order
+	failAt  int      // Page returning an error; 0 for none
+}
+
+func (l *fakeList) fetchPage(cursor string) ([]int, string, bool, error) {
+	l.cursors = append(l.cursors, cursor)
+	page := 0
+	if cursor != "" {
+		fmt.Sscanf(cursor, "cursor-%d", &page)
+	}
+	if l.failAt != 0 && page == l.failAt {
+		return nil, "", false, errors.New("rate limited")
+	}
+	more := page+1 < l.pages
+	next := ""
+	if more {
+		next = fmt.Sprintf("cursor-%d", page+1)
+	}
+	return []int{page}, next, more, nil
+}
+
+// TestPaginate tests following cursors, stopping early, errors and the page limit
+func TestPaginate(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	collect := func(items *[]int) func([]int) bool {
+		return func(page []int) bool {
+			*items = append(*items, page...)
+			return true
+		}
+	}
+
+	t.Run("every page", func(t *testing.T) {
+		list := &fakeList{pages: 3}
+		var items []int
+		if err := paginate(context.Background(), client, "test", list.fetchPage, collect(&items)); err != nil {
+			t.Fatalf("paginate() error = %v", err)
+		}
+		if !slices.Equal(items, []int{0, 1, 2}) || !slices.Equal(list.cursors, []string{"", "cursor-1", "cursor-2"}) {
+			t.Errorf("items = %v, cursors = %q", items, list.cursors)
+		}
+	})
+
+	t.Run("visit stops", func(t *testing.T) {
+		list := &fakeList{pages: 5}
+		if err := paginate(context.Background(), client, "test", list.fetchPage, func(page []int) bool { return page[0] < 1 }); err != nil {
+			t.Fatalf("paginate() error = %v", err)
+		}
+		if len(list.cursors) != 2 {
+			t.Errorf("fetched %d pages, want 2", len(list.cursors))
+		}
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		list := &fakeList{pages: 5, failAt: 2}
+		var items []int
+		err := paginate(context.Background(), client, "test", list.fetchPage, collect(&items))
+		if err == nil || err.Error() != "rate limited" || !slices.Equal(items, []int{0, 1}) {
+			t.Errorf("paginate() error = %v with items %v, want the fetch error after 2 pages", err, items)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		list := &fakeList{pages: 5}
+		if err := paginate(ctx, client, "test", list.fetchPage, collect(new([]int))); !errors.Is(err, context.Canceled) || len(list.cursors) != 0 {
+			t.Errorf("paginate() error = %v after %d pages, want context.Canceled before fetching", err, len(list.cursors))
+		}
+	})
+
+	t.Run("page limit", func(t *testing.T) {
+		list := &fakeList{pages: 10}
+		var items []int
+		next, err := paginateFrom(context.Background(), client, "test", "cursor-2", 3, list.fetchPage, collect(&items))
+		if !errors.Is(err, errPageLimit) || next != "cursor-5" || !slices.Equal(items, []int{2, 3, 4}) {
+			t.Errorf("paginateFrom() = %q, %v with items %v, want the cursor after 3 pages and errPageLimit", next, err, items)
+		}
+	})
+
+	t.Run("endless cursors", func(t *testing.T) {
+		fetches := 0
+		endless := func(string) ([]int, string, bool, error) {
+			fetches++
+			return nil, "same-cursor", true, nil
+		}
+		if err := paginate(context.Background(), client, "test", endless, collect(new([]int))); !errors.Is(err, errPageLimit) || fetches != maxPaginatedPages {
+			t.Errorf("paginate() error = %v after %d pages, want errPageLimit after %d", err, fetches, maxPaginatedPages)
+		}
+	})
+}
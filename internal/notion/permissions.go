@@ -0,0 +1,336 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// Capability identifies something the integration must be able to do in Notion.
+type Capability string
+
+// Capabilities probed by CheckPermissions.
+//
+// Missing share permissions are the most common setup failure: the integration
+// exists and the API key is valid, but a database was never shared with it via
+// "••• → Connections", or a capability was left unchecked in the integration
+// settings. Each probe maps to one of those settings so the report can say
+// exactly what to fix.
+const (
+	// CapabilityReadIdeasDatabase requires the ideas database to be shared with the integration
+	CapabilityReadIdeasDatabase Capability = "read_ideas_database"
+	// CapabilityInsertIdeas requires the "Insert content" capability on the ideas database
+	CapabilityInsertIdeas Capability = "insert_ideas"
+	// CapabilityReadCustomersDatabase requires the Customers database to be shared with the integration
+	CapabilityReadCustomersDatabase Capability = "read_customers_database"
+	// CapabilityReadUsers requires the "Read user information including email addresses" capability
+	CapabilityReadUsers Capability = "read_users"
+)
+
+// PermissionStatus is the outcome of a single capability probe.
+type PermissionStatus string
+
+const (
+	// PermissionGranted means the probe proved the integration has the capability
+	PermissionGranted PermissionStatus = "granted"
+	// PermissionMissing means Notion rejected the probe for lack of access
+	PermissionMissing PermissionStatus = "missing"
+	// PermissionUnknown means the probe could not determine access (network error, rate limit, 5xx)
+	PermissionUnknown PermissionStatus = "unknown"
+)
+
+// probePropertyName is a property name that never exists in the ideas database.
+//
+// The insert probe creates a page using only this property. Notion checks access
+// before validating properties, so a validation_error proves the integration may
+// insert pages, while the request itself can never succeed and create a page.
+const probePropertyName = "hopperbot_permission_probe"
+
+// CapabilityCheck is the result of probing one capability.
+type CapabilityCheck struct {
+	Capability Capability       `json:"capability"`
+	Status     PermissionStatus `json:"status"`
+	Error      string           `json:"error,omitempty"`
+	Hint       string           `json:"hint,omitempty"`
+}
+
+// PermissionReport summarizes which capabilities the integration has.
+type PermissionReport struct {
+	CheckedAt time.Time         `json:"checked_at"`
+	Checks    []CapabilityCheck `json:"checks"`
+}
+
+// Missing returns the capabilities that Notion explicitly denied.
+func (r *PermissionReport) Missing() []Capability {
+	var missing []Capability
+	for _, check := range r.Checks {
+		if check.Status == PermissionMissing {
+			missing = append(missing, check.Capability)
+		}
+	}
+	return missing
+}
+
+// Statuses returns a capability -> status map, suitable for health check metadata.
+func (r *PermissionReport) Statuses() map[string]string {
+	statuses := make(map[string]string, len(r.Checks))
+	for _, check := range r.Checks {
+		statuses[string(check.Capability)] = string(check.Status)
+	}
+	return statuses
+}
+
+// CheckPermissions probes each capability the bot depends on and returns a report.
+//
+// Probes are read-only or guaranteed to fail validation, so they never modify the
+// workspace. They work before InitializeDataSources has run (database containers
+// are probed directly), which makes the report useful for diagnosing startup
+// failures; the insert probe needs the discovered data source and reports
+// "unknown" until then.
+//
+// The latest report is stored on the client and available via LastPermissionReport.
+func (c *Client) CheckPermissions() *PermissionReport {
+	report := &PermissionReport{
+		CheckedAt: time.Now().UTC(),
+		Checks: []CapabilityCheck{
+			c.probeReadIdeasDatabase(),
+			c.probeInsertIdeas(),
+			c.probeReadCustomersDatabase(),
+			c.probeReadUsers(),
+		},
+	}
+
+	c.permissionsMu.Lock()
+	c.lastPermissions = report
+	c.permissionsMu.Unlock()
+
+	c.recordPermissions(report)
+
+	return report
+}
+
+// LastPermissionReport returns the most recent permission report, or nil if
+// CheckPermissions has not run yet.
+func (c *Client) LastPermissionReport() *PermissionReport {
+	c.permissionsMu.RLock()
+	defer c.permissionsMu.RUnlock()
+	return c.lastPermissions
+}
+
+// probeReadIdeasDatabase verifies the ideas database container is shared with the integration.
+func (c *Client) probeReadIdeasDatabase() CapabilityCheck {
+	databaseID, _ := c.CurrentDatabases()
+	endpoint := fmt.Sprintf("%s/databases/%s", constants.NotionAPIBaseURL, databaseID)
+	err := c.probe("GET", endpoint, nil)
+	return classifyProbe(CapabilityReadIdeasDatabase, err,
+		"Share the ideas database (NOTION_DATABASE_ID) with the integration: open it in Notion → ••• → Connections → add the integration.")
+}
+
+// probeInsertIdeas verifies the integration may create pages in the ideas data source.
+func (c *Client) probeInsertIdeas() CapabilityCheck {
+	dataSourceID := c.ideasDataSourceID()
+	if dataSourceID == "" {
+		return CapabilityCheck{
+			Capability: CapabilityInsertIdeas,
+			Status:     PermissionUnknown,
+			Error:      "ideas data source not discovered yet",
+			Hint:       "Resolve read_ideas_database first; insert access is checked once the data source is discovered.",
+		}
+	}
+
+	request := map[string]interface{}{
+		"parent": c.pageParent(dataSourceID),
+		"properties": map[string]interface{}{
+			probePropertyName: map[string]interface{}{"rich_text": []interface{}{}},
+		},
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return CapabilityCheck{Capability: CapabilityInsertIdeas, Status: PermissionUnknown, Error: err.Error()}
+	}
+
+	endpoint := fmt.Sprintf("%s/pages", constants.NotionAPIBaseURL)
+	err = c.probe("POST", endpoint, body)
+
+	// A validation error means access checks passed and only the bogus property was rejected
+	var apiErr *NotionAPIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusBadRequest && apiErr.Code == ErrorCodeValidation {
+		err = nil
+	}
+
+	return classifyProbe(CapabilityInsertIdeas, err,
+		"Enable the \"Insert content\" capability for the integration at https://www.notion.so/my-integrations and make sure the ideas database connection allows editing.")
+}
+
+// probeReadCustomersDatabase verifies the Customers database is shared with the integration.
+func (c *Client) probeReadCustomersDatabase() CapabilityCheck {
+	_, customersDBID := c.CurrentDatabases()
+	endpoint := fmt.Sprintf("%s/databases/%s", constants.NotionAPIBaseURL, customersDBID)
+	var body []byte
+	method := "GET"
+	if customersDataSourceID := c.customersSourceID(); customersDataSourceID != "" {
+		// Query a single row so row-level read access is verified, not just the container
+		endpoint = c.dataSourceEndpoint(customersDataSourceID, "/query")
+		body = []byte(`{"page_size":1}`)
+		method = "POST"
+	}
+
+	err := c.probe(method, endpoint, body)
+	return classifyProbe(CapabilityReadCustomersDatabase, err,
+		"Share the Customers database (NOTION_CLIENTS_DB_ID) with the integration: open it in Notion → ••• → Connections → add the integration.")
+}
+
+// probeReadUsers verifies the integration can list workspace users.
+func (c *Client) probeReadUsers() CapabilityCheck {
+	endpoint := fmt.Sprintf("%s/users?page_size=1", constants.NotionAPIBaseURL)
+	err := c.probe("GET", endpoint, nil)
+	return classifyProbe(CapabilityReadUsers, err,
+		"Enable the \"Read user information including email addresses\" capability for the integration at https://www.notion.so/my-integrations.")
+}
+
+// probe sends a request and discards the response body.
+func (c *Client) probe(method, endpoint string, body []byte) error {
+	resp, err := c.makeNotionRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// classifyProbe converts a probe error into a CapabilityCheck.
+//
+// 401/403/404 are access denials (missing); anything else (rate limits, 5xx,
+// network errors) is inconclusive (unknown) so transient failures don't get
+// reported as misconfiguration.
+func classifyProbe(capability Capability, err error, missingHint string) CapabilityCheck {
+	check := CapabilityCheck{Capability: capability, Status: PermissionGranted}
+	if err == nil {
+		return check
+	}
+
+	check.Error = err.Error()
+
+	var apiErr *NotionAPIError
+	if !errors.As(err, &apiErr) {
+		check.Status = PermissionUnknown
+		return check
+	}
+
+	switch apiErr.Status {
+	case http.StatusUnauthorized:
+		check.Status = PermissionMissing
+		check.Hint = "NOTION_API_KEY is invalid or the integration was removed from the workspace."
+	case http.StatusForbidden, http.StatusNotFound:
+		check.Status = PermissionMissing
+		check.Hint = missingHint
+	default:
+		check.Status = PermissionUnknown
+	}
+
+	return check
+}
+
+// PermissionChecker runs capability probes (satisfied by *Client).
+type PermissionChecker interface {
+	CheckPermissions() *PermissionReport
+}
+
+// PermissionMonitor periodically re-checks integration permissions.
+//
+// Share settings can change after startup (a database gets moved to a private
+// teamspace, a capability gets unchecked), so the report is refreshed on an
+// interval and any missing capability is logged with a remediation hint.
+type PermissionMonitor struct {
+	checker  PermissionChecker
+	interval time.Duration
+	logger   *zap.Logger
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewPermissionMonitor creates a monitor in a stopped state. Call Start() to begin checking.
+// A non-positive interval uses constants.DefaultPermissionCheckInterval.
+func NewPermissionMonitor(checker PermissionChecker, interval time.Duration, logger *zap.Logger) *PermissionMonitor {
+	if interval <= 0 {
+		interval = constants.DefaultPermissionCheckInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PermissionMonitor{
+		checker:  checker,
+		interval: interval,
+		logger:   logger,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start begins the background check loop. The first check runs after one interval;
+// call Check directly for an immediate result.
+func (m *PermissionMonitor) Start() {
+	ticker := time.NewTicker(m.interval)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.Check()
+			case <-m.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background check loop and waits for it to exit.
+func (m *PermissionMonitor) Stop() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// Check runs the probes now and logs any problems.
+func (m *PermissionMonitor) Check() *PermissionReport {
+	report := m.checker.CheckPermissions()
+	LogPermissionReport(m.logger, report)
+	return report
+}
+
+// LogPermissionReport logs each missing or inconclusive capability with its hint.
+func LogPermissionReport(logger *zap.Logger, report *PermissionReport) {
+	problems := 0
+	for _, check := range report.Checks {
+		switch check.Status {
+		case PermissionMissing:
+			problems++
+			logger.Error("notion integration is missing a required permission",
+				zap.String("capability", string(check.Capability)),
+				zap.String("error", check.Error),
+				zap.String("hint", check.Hint),
+			)
+		case PermissionUnknown:
+			problems++
+			logger.Warn("could not verify notion integration permission",
+				zap.String("capability", string(check.Capability)),
+				zap.String("error", check.Error),
+			)
+		}
+	}
+
+	if problems == 0 {
+		logger.Info("notion integration permissions verified",
+			zap.Int("capabilities", len(report.Checks)),
+		)
+	}
+}
@@ -0,0 +1,143 @@
+package notion
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// routeTransport returns canned responses keyed by "METHOD path" for testing probes
+type routeTransport struct {
+	routes map[string]*http.Response
+}
+
+func (rt *routeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if resp, ok := rt.routes[req.Method+" "+req.URL.Path]; ok {
+		return resp, nil
+	}
+	return nil, errors.New("connection refused")
+}
+
+func jsonResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}
+}
+
+// TestCheckPermissions tests capability classification from Notion responses
+func TestCheckPermissions(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.dataSourceID = "ideas-ds"
+	client.customersDataSourceID = "customers-ds"
+	client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+		"GET /v1/databases/ideas-db": jsonResponse(http.StatusOK, `{"object":"database"}`),
+		"POST /v1/pages": jsonResponse(http.StatusBadRequest,
+			`{"object":"error","status":400,"code":"validation_error","message":"hopperbot_permission_probe is not a property that exists."}`),
+		"POST /v1/data_sources/customers-ds/query": jsonResponse(http.StatusNotFound,
+			`{"object":"error","status":404,"code":"object_not_found","message":"Could not find data_source"}`),
+		// GET /v1/users has no route, simulating a network failure
+	}}}
+
+	report := client.CheckPermissions()
+
+	expected := map[string]string{
+		string(CapabilityReadIdeasDatabase):     string(PermissionGranted),
+		string(CapabilityInsertIdeas):           string(PermissionGranted),
+		string(CapabilityReadCustomersDatabase): string(PermissionMissing),
+		string(CapabilityReadUsers):             string(PermissionUnknown),
+	}
+	statuses := report.Statuses()
+	for capability, want := range expected {
+		if statuses[capability] != want {
+			t.Errorf("%s status = %q, want %q", capability, statuses[capability], want)
+		}
+	}
+
+	missing := report.Missing()
+	if len(missing) != 1 || missing[0] != CapabilityReadCustomersDatabase {
+		t.Errorf("Missing() = %v, want [%s]", missing, CapabilityReadCustomersDatabase)
+	}
+
+	for _, check := range report.Checks {
+		if check.Status == PermissionMissing && check.Hint == "" {
+			t.Errorf("%s is missing but has no hint", check.Capability)
+		}
+	}
+
+	if client.LastPermissionReport() != report {
+		t.Error("LastPermissionReport() should return the latest report")
+	}
+}
+
+// TestCheckPermissions_BeforeDiscovery verifies the insert probe is inconclusive without a data source
+func TestCheckPermissions_BeforeDiscovery(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+		"GET /v1/databases/ideas-db": jsonResponse(http.StatusNotFound, `{"object":"error","code":"object_not_found"}`),
+	}}}
+
+	statuses := client.CheckPermissions().Statuses()
+
+	if statuses[string(CapabilityReadIdeasDatabase)] != string(PermissionMissing) {
+		t.Errorf("read_ideas_database = %q, want missing", statuses[string(CapabilityReadIdeasDatabase)])
+	}
+	if statuses[string(CapabilityInsertIdeas)] != string(PermissionUnknown) {
+		t.Errorf("insert_ideas = %q, want unknown", statuses[string(CapabilityInsertIdeas)])
+	}
+}
+
+// TestClassifyProbe tests mapping of API errors to permission statuses
+func TestClassifyProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status PermissionStatus
+	}{
+		{name: "success", err: nil, status: PermissionGranted},
+		{name: "unauthorized", err: &NotionAPIError{Status: http.StatusUnauthorized}, status: PermissionMissing},
+		{name: "restricted", err: &NotionAPIError{Status: http.StatusForbidden}, status: PermissionMissing},
+		{name: "not shared", err: &NotionAPIError{Status: http.StatusNotFound}, status: PermissionMissing},
+		{name: "rate limited", err: &NotionAPIError{Status: http.StatusTooManyRequests}, status: PermissionUnknown},
+		{name: "server error", err: &NotionAPIError{Status: http.StatusBadGateway}, status: PermissionUnknown},
+		{name: "network error", err: errors.New("timeout"), status: PermissionUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := classifyProbe(CapabilityReadUsers, tt.err, "hint")
+			if check.Status != tt.status {
+				t.Errorf("status = %q, want %q", check.Status, tt.status)
+			}
+		})
+	}
+}
+
+// TestNewNotionAPIError tests parsing of Notion error responses
+func TestNewNotionAPIError(t *testing.T) {
+	err := newNotionAPIError(404, []byte(`{"object":"error","status":404,"code":"object_not_found","message":"Could not find database.","request_id":"req-body"}`), "req-header")
+	want := NotionAPIError{Status: 404, Code: ErrorCodeObjectNotFound, Message: "Could not find database.", RequestID: "req-body"}
+	if *err != want {
+		t.Errorf("newNotionAPIError() = %+v, want %+v", *err, want)
+	}
+	for _, part := range []string{"status 404", "object_not_found", "Could not find database.", "request_id: req-body"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("Error() = %q, should include %q", err.Error(), part)
+		}
+	}
+
+	// Bodies that aren't Notion error objects are kept as-is, with the header's request ID
+	err = newNotionAPIError(502, []byte("<html>bad gateway</html>"), "req-header")
+	want = NotionAPIError{Status: 502, RequestID: "req-header", Body: "<html>bad gateway</html>"}
+	if *err != want {
+		t.Errorf("newNotionAPIError() = %+v, want %+v", *err, want)
+	}
+	if !strings.Contains(err.Error(), "<html>bad gateway</html>") {
+		t.Errorf("Error() = %q, should include the raw body", err.Error())
+	}
+}
@@ -0,0 +1,271 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+)
+
+// maxIdeaQueryPages bounds how many result pages one QueryIdeas call reads, so a
+// broad filter can't turn a slash command into hundreds of API calls. Callers
+// that need more continue from IdeaQueryResult.NextCursor.
+const maxIdeaQueryPages = 10
+
+// Filter is a Notion data source query filter object.
+// See https://developers.notion.com/reference/post-database-query-filter
+type Filter map[string]interface{}
+
+// RelationContains matches pages whose relation property links to pageID.
+func RelationContains(property, pageID string) Filter {
+	return Filter{
+		"property": property,
+		"relation": map[string]interface{}{"contains": pageID},
+	}
+}
+
+// PeopleContains matches pages whose people property includes the Notion user.
+func PeopleContains(property, userID string) Filter {
+	return Filter{
+		"property": property,
+		"people":   map[string]interface{}{"contains": userID},
+	}
+}
+
+// And matches pages that satisfy every filter.
+func And(filters ...Filter) Filter {
+	conditions := make([]interface{}, len(filters))
+	for i, f := range filters {
+		conditions[i] = f
+	}
+	return Filter{"and": conditions}
+}
+
+// MultiSelectContains matches pages whose multi_select property includes the option.
+func MultiSelectContains(property, option string) Filter {
+	return Filter{
+		"property":     property,
+		"multi_select": map[string]interface{}{"contains": option},
+	}
+}
+
+// SelectEquals matches pages whose select property is the option.
+func SelectEquals(property, option string) Filter {
+	return Filter{
+		"property": property,
+		"select":   map[string]interface{}{"equals": option},
+	}
+}
+
+// CreatedTime matches pages by creation time; condition is a Notion date
+// condition such as "on_or_after" or "before".
+func CreatedTime(condition string, t time.Time) Filter {
+	return Filter{
+		"timestamp":    "created_time",
+		"created_time": map[string]interface{}{condition: t.UTC().Format(time.RFC3339)},
+	}
+}
+
+// IdeasFilter selects ideas for QueryIdeas. Empty fields don't filter; the
+// others must all match.
+type IdeasFilter struct {
+	SubmitterID   string    // Notion user ID in constants.FieldSubmittedBy
+	Theme         string    // Option of constants.FieldThemeCategory
+	ProductArea   string    // Option of constants.FieldProductArea
+	CustomerOrgID string    // Customer page ID in the constants.FieldCustomerOrg relation
+	CreatedAfter  time.Time // Created at or after this time
+	CreatedBefore time.Time // Created before this time
+}
+
+// notionFilter converts the filter to a Notion filter object (nil matches every idea).
+func (f IdeasFilter) notionFilter() Filter {
+	var filters []Filter
+	if f.SubmitterID != "" {
+		filters = append(filters, PeopleContains(constants.FieldSubmittedBy, f.SubmitterID))
+	}
+	if f.Theme != "" {
+		filters = append(filters, MultiSelectContains(constants.FieldThemeCategory, f.Theme))
+	}
+	if f.ProductArea != "" {
+		filters = append(filters, SelectEquals(constants.FieldProductArea, f.ProductArea))
+	}
+	if f.CustomerOrgID != "" {
+		filters = append(filters, RelationContains(constants.FieldCustomerOrg, f.CustomerOrgID))
+	}
+	if !f.CreatedAfter.IsZero() {
+		filters = append(filters, CreatedTime("on_or_after", f.CreatedAfter))
+	}
+	if !f.CreatedBefore.IsZero() {
+		filters = append(filters, CreatedTime("before", f.CreatedBefore))
+	}
+
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return filters[0]
+	default:
+		return And(filters...)
+	}
+}
+
+// Sort orders QueryIdeas results by a property, or by creation time if Property is empty.
+type Sort struct {
+	Property  string
+	Ascending bool
+}
+
+// NewestFirst sorts ideas by creation time, most recent first.
+var NewestFirst = Sort{}
+
+// notionSorts converts the sort to the Notion sorts array.
+func (s Sort) notionSorts() []interface{} {
+	direction := "descending"
+	if s.Ascending {
+		direction = "ascending"
+	}
+	if s.Property == "" {
+		return []interface{}{map[string]interface{}{"timestamp": "created_time", "direction": direction}}
+	}
+	return []interface{}{map[string]interface{}{"property": s.Property, "direction": direction}}
+}
+
+// IdeaSummary is an idea page as listed by QueryIdeas.
+type IdeaSummary struct {
+	ID          string
+	URL         string
+	Title       string
+	Status      string   // Value of constants.FieldStatus; empty if unset
+	Themes      []string // Options of constants.FieldThemeCategory
+	ProductArea string   // Option of constants.FieldProductArea; empty if unset
+	CreatedTime time.Time
+}
+
+// IdeaQueryResult holds the matches of an ideas query.
+type IdeaQueryResult struct {
+	Total      int           // Number of matching ideas (a lower bound if Truncated)
+	Truncated  bool          // True if reading stopped after maxIdeaQueryPages pages
+	NextCursor string        // Continues a truncated query (see QueryIdeas)
+	Ideas      []IdeaSummary // In the query's sort order; at most the requested limit, if any
+}
+
+// limit trims Ideas to the first n ideas (n <= 0 keeps all). Total is unchanged.
+func (r *IdeaQueryResult) limit(n int) *IdeaQueryResult {
+	if n > 0 && len(r.Ideas) > n {
+		r.Ideas = r.Ideas[:n]
+	}
+	return r
+}
+
+// QueryIdeas queries the ideas data source for ideas matching filter, in the
+// given order.
+//
+// Pagination is followed automatically, starting at cursor ("" for the first
+// page), for up to maxIdeaQueryPages pages. If more matches remain, the result
+// is Truncated and NextCursor continues the query where it stopped. Pages in
+// the trash are skipped.
+func (c *Client) QueryIdeas(filter IdeasFilter, sort Sort, cursor string) (*IdeaQueryResult, error) {
+	start := time.Now()
+	result, err := c.queryIdeas(filter.notionFilter(), sort, cursor)
+	c.recordNotionRequest("query_ideas", start, err)
+	return result, err
+}
+
+func (c *Client) queryIdeas(filter Filter, sort Sort, cursor string) (*IdeaQueryResult, error) {
+	endpoint := c.dataSourceEndpoint(c.ideasDataSourceID(), "/query")
+	result := &IdeaQueryResult{}
+
+	fetchPage := func(cursor string) ([]pageResponse, string, bool, error) {
+		requestBody := map[string]interface{}{
+			"sorts":     sort.notionSorts(),
+			"page_size": constants.NotionPageSize,
+		}
+		if len(filter) > 0 {
+			requestBody["filter"] = filter
+		}
+		if cursor != "" {
+			requestBody["start_cursor"] = cursor
+		}
+
+		body, err := json.Marshal(requestBody)
+		if err != nil {
+			return nil, "", false, fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		resp, err := c.makeNotionRequest("POST", endpoint, body)
+		if err != nil {
+			return nil, "", false, err
+		}
+
+		var queryResponse struct {
+			Results    []pageResponse `json:"results"`
+			HasMore    bool           `json:"has_more"`
+			NextCursor string         `json:"next_cursor"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&queryResponse)
+		resp.Body.Close()
+		if err != nil {
+			return nil, "", false, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return queryResponse.Results, queryResponse.NextCursor, queryResponse.HasMore, nil
+	}
+
+	nextCursor, err := paginateFrom(context.Background(), c, "query_ideas", cursor, maxIdeaQueryPages, fetchPage, func(pages []pageResponse) bool {
+		for _, p := range pages {
+			if p.Archived || p.InTrash {
+				continue
+			}
+			result.Total++
+			result.Ideas = append(result.Ideas, IdeaSummary{
+				ID:          p.ID,
+				URL:         p.URL,
+				Title:       extractTitleFromProperties(p.Properties),
+				Status:      extractStatusFromProperty(p.Properties[constants.FieldStatus]),
+				Themes:      extractOptionNames(p.Properties[constants.FieldThemeCategory]),
+				ProductArea: productArea(p.Properties[constants.FieldProductArea]),
+				CreatedTime: p.CreatedTime,
+			})
+		}
+		return true
+	})
+	switch {
+	case errors.Is(err, errPageLimit):
+		result.Truncated = true
+		result.NextCursor = nextCursor
+	case err != nil:
+		return nil, err
+	}
+	return result, nil
+}
+
+// productArea returns the option of a product area select property value.
+func productArea(value interface{}) string {
+	if names := extractOptionNames(value); len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// CustomerIdeas returns the ideas linked to a customer page through the
+// Customer Org relation, newest first (see QueryIdeas). Total counts every
+// match read; only the first limit ideas are returned (limit <= 0 returns all).
+func (c *Client) CustomerIdeas(customerPageID string, limit int) (*IdeaQueryResult, error) {
+	result, err := c.QueryIdeas(IdeasFilter{CustomerOrgID: customerPageID}, NewestFirst, "")
+	if err != nil {
+		return nil, err
+	}
+	return result.limit(limit), nil
+}
+
+// SubmitterIdeas returns the ideas submitted by a Notion user (the Submitted By
+// people property), newest first, like CustomerIdeas.
+func (c *Client) SubmitterIdeas(notionUserID string, limit int) (*IdeaQueryResult, error) {
+	result, err := c.QueryIdeas(IdeasFilter{SubmitterID: notionUserID}, NewestFirst, "")
+	if err != nil {
+		return nil, err
+	}
+	return result.limit(limit), nil
+}
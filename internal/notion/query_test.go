@@ -0,0 +1,172 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TestRelationContains tests the relation filter body sent to Notion
+func TestRelationContains(t *testing.T) {
+	got, err := json.Marshal(And(RelationContains("Customer Org", "page-a")))
+	if err != nil {
+		t.Fatalf("failed to marshal filter: %v", err)
+	}
+	want := `{"and":[{"property":"Customer Org","relation":{"contains":"page-a"}}]}`
+	if string(got) != want {
+		t.Errorf("filter = %s, want %s", got, want)
+	}
+}
+
+// TestPeopleContains tests the people filter body sent to Notion
+func TestPeopleContains(t *testing.T) {
+	got, err := json.Marshal(PeopleContains("Submitted By", "user-1"))
+	if err != nil {
+		t.Fatalf("failed to marshal filter: %v", err)
+	}
+	want := `{"people":{"contains":"user-1"},"property":"Submitted By"}`
+	if string(got) != want {
+		t.Errorf("filter = %s, want %s", got, want)
+	}
+}
+
+// TestQueryIdeas tests counting across pages, limiting the list, and skipping trashed pages
+func TestQueryIdeas(t *testing.T) {
+	transport := &sequenceTransport{results: []func() (*http.Response, error){
+		respond(http.StatusOK, `{
+			"results": [
+				{"id": "page-3", "url": "https://www.notion.so/page-3", "created_time": "2025-11-12T10:00:00.000Z",
+				 "properties": {"Idea/Topic": {"type": "title", "title": [{"text": {"content": "Dark mode"}}]},
+				                "Status": {"type": "status", "status": {"name": "Planned"}},
+				                "Theme/Category": {"type": "multi_select", "multi_select": [{"name": "New Feature Idea"}]},
+				                "Product Area": {"type": "select", "select": {"name": "AI/ML"}}}},
+				{"id": "page-2", "in_trash": true, "properties": {}}
+			],
+			"has_more": true,
+			"next_cursor": "cursor-1"
+		}`),
+		respond(http.StatusOK, `{
+			"results": [
+				{"id": "page-1", "url": "https://www.notion.so/page-1", "created_time": "2025-11-01T10:00:00.000Z",
+				 "properties": {"Idea/Topic": {"type": "title", "title": [{"text": {"content": "SSO"}}]}}}
+			],
+			"has_more": false,
+			"next_cursor": null
+		}`),
+	}}
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.dataSourceID = "ideas-ds"
+	client.httpClient = &http.Client{Transport: transport}
+
+	result, err := client.CustomerIdeas("customer-page", 1)
+	if err != nil {
+		t.Fatalf("CustomerIdeas() error = %v", err)
+	}
+	if result.Total != 2 || result.Truncated {
+		t.Errorf("Total = %d, Truncated = %v, want 2, false", result.Total, result.Truncated)
+	}
+	if len(result.Ideas) != 1 {
+		t.Fatalf("expected 1 idea, got %d", len(result.Ideas))
+	}
+	if idea := result.Ideas[0]; idea.Title != "Dark mode" || idea.Status != "Planned" || idea.URL != "https://www.notion.so/page-3" || idea.CreatedTime.IsZero() {
+		t.Errorf("idea = %+v", idea)
+	}
+	if idea := result.Ideas[0]; len(idea.Themes) != 1 || idea.Themes[0] != "New Feature Idea" || idea.ProductArea != "AI/ML" {
+		t.Errorf("idea themes = %v, product area = %q", idea.Themes, idea.ProductArea)
+	}
+	if len(transport.paths) != 2 || transport.paths[0] != "POST /v1/data_sources/ideas-ds/query" {
+		t.Errorf("requests = %v", transport.paths)
+	}
+}
+
+// TestIdeasFilter tests the Notion filter built from typed filter fields
+func TestIdeasFilter(t *testing.T) {
+	after := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		filter IdeasFilter
+		want   string
+	}{
+		{name: "empty", filter: IdeasFilter{}, want: `null`},
+		{
+			name:   "single condition",
+			filter: IdeasFilter{ProductArea: "AI/ML"},
+			want:   `{"property":"Product Area","select":{"equals":"AI/ML"}}`,
+		},
+		{
+			name:   "combined",
+			filter: IdeasFilter{SubmitterID: "user-1", Theme: "New Feature Idea", CustomerOrgID: "customer-1", CreatedAfter: after},
+			want: `{"and":[` +
+				`{"people":{"contains":"user-1"},"property":"Submitted by"},` +
+				`{"multi_select":{"contains":"New Feature Idea"},"property":"Theme/Category"},` +
+				`{"property":"Customer Organization","relation":{"contains":"customer-1"}},` +
+				`{"created_time":{"on_or_after":"2025-11-01T00:00:00Z"},"timestamp":"created_time"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.filter.notionFilter())
+			if err != nil {
+				t.Fatalf("failed to marshal filter: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("filter = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// endlessIdeasTransport answers every ideas query with one idea and another page
+type endlessIdeasTransport struct {
+	requests []map[string]interface{}
+}
+
+func (e *endlessIdeasTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	e.requests = append(e.requests, body)
+	n := len(e.requests)
+	return jsonResponse(http.StatusOK, fmt.Sprintf(
+		`{"results": [{"id": "page-%d", "properties": {}}], "has_more": true, "next_cursor": "cursor-%d"}`, n, n)), nil
+}
+
+// TestQueryIdeas_CursorAndSort tests that truncated queries return a cursor to continue from
+func TestQueryIdeas_CursorAndSort(t *testing.T) {
+	transport := &endlessIdeasTransport{}
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.dataSourceID = "ideas-ds"
+	client.httpClient = &http.Client{Transport: transport}
+
+	result, err := client.QueryIdeas(IdeasFilter{Theme: "New Feature Idea"}, Sort{Property: "Idea/Topic", Ascending: true}, "cursor-start")
+	if err != nil {
+		t.Fatalf("QueryIdeas() error = %v", err)
+	}
+	if !result.Truncated || result.Total != maxIdeaQueryPages || len(result.Ideas) != maxIdeaQueryPages {
+		t.Errorf("Truncated = %v, Total = %d, ideas = %d", result.Truncated, result.Total, len(result.Ideas))
+	}
+	if want := fmt.Sprintf("cursor-%d", maxIdeaQueryPages); result.NextCursor != want {
+		t.Errorf("NextCursor = %q, want %q", result.NextCursor, want)
+	}
+
+	first := transport.requests[0]
+	if first["start_cursor"] != "cursor-start" {
+		t.Errorf("first start_cursor = %v, want cursor-start", first["start_cursor"])
+	}
+	sorts, _ := json.Marshal(first["sorts"])
+	if string(sorts) != `[{"direction":"ascending","property":"Idea/Topic"}]` {
+		t.Errorf("sorts = %s", sorts)
+	}
+	if _, ok := first["filter"]; !ok {
+		t.Error("filter should be sent")
+	}
+	if got := transport.requests[1]["start_cursor"]; got != "cursor-1" {
+		t.Errorf("second start_cursor = %v, want cursor-1", got)
+	}
+}
@@ -0,0 +1,236 @@
+package notion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// SelectOptions are the valid options of the core select fields.
+//
+// They are synced from the ideas database schema (see SyncSchema), so adding
+// a theme or product area in Notion needs no code change. The constants in
+// pkg/constants are the fallback until the first sync and for fields whose
+// property is missing or has no options.
+type SelectOptions struct {
+	Themes       []string // Options of constants.FieldThemeCategory
+	ProductAreas []string // Options of constants.FieldProductArea
+
+	// HasSource is true when the database has a constants.FieldSource select,
+	// so submissions record their intake channel. Notion adds missing options.
+	HasSource bool
+
+	// URLProperties are the names of the database's url properties, one of
+	// which may receive Slack permalinks (see Client.SetSourceURLProperty).
+	URLProperties []string
+
+	// HasVotes is true when the database has a constants.FieldVotes number,
+	// so Slack reactions can be counted on pages (see Client.SetVotes).
+	HasVotes bool
+
+	// HasSlackSubmitter is true when the database has a
+	// constants.FieldSubmittedBySlack rich_text, so new pages record the Slack
+	// user who submitted them.
+	HasSlackSubmitter bool
+}
+
+// DefaultSelectOptions returns the built-in options from pkg/constants.
+func DefaultSelectOptions() SelectOptions {
+	return SelectOptions{
+		Themes:       constants.ValidThemeCategories,
+		ProductAreas: constants.ValidProductAreas,
+	}
+}
+
+// SelectOptionsFromSchema reads the core select options from a schema, falling
+// back to DefaultSelectOptions for each field without options.
+func SelectOptionsFromSchema(schema []FormProperty) SelectOptions {
+	options := DefaultSelectOptions()
+	for _, property := range schema {
+		if property.Name == constants.FieldSource && property.Type == "select" {
+			options.HasSource = true
+		}
+		if property.Name == constants.FieldVotes && property.Type == "number" {
+			options.HasVotes = true
+		}
+		if property.Name == constants.FieldSubmittedBySlack && property.Type == "rich_text" {
+			options.HasSlackSubmitter = true
+		}
+		if property.Type == "url" {
+			options.URLProperties = append(options.URLProperties, property.Name)
+		}
+		if len(property.Options) == 0 {
+			continue
+		}
+		switch property.Name {
+		case constants.FieldThemeCategory:
+			options.Themes = property.Options
+		case constants.FieldProductArea:
+			options.ProductAreas = property.Options
+		}
+	}
+	return options
+}
+
+// SelectOptions returns the current core select options. It is never nil.
+func (c *Client) SelectOptions() SelectOptions {
+	if options := c.selectOptions.Load(); options != nil {
+		return *options
+	}
+	return DefaultSelectOptions()
+}
+
+// SyncSchema fetches the ideas database schema, updates the select options
+// used to validate submissions, and returns the schema for building forms.
+// On failure the current options are kept.
+func (c *Client) SyncSchema() ([]FormProperty, error) {
+	start := time.Now()
+	schema, err := c.GetFormSchema()
+	c.recordNotionRequest("sync_schema", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	options := SelectOptionsFromSchema(schema)
+	if previous := c.SelectOptions(); !slices.Equal(previous.Themes, options.Themes) || !slices.Equal(previous.ProductAreas, options.ProductAreas) {
+		c.logger.Info("select options synced from database schema",
+			zap.Strings("themes", options.Themes),
+			zap.Strings("product_areas", options.ProductAreas),
+		)
+	}
+	c.selectOptions.Store(&options)
+	if property := c.SourceURLProperty(); property != "" && !slices.Contains(options.URLProperties, property) {
+		c.logger.Warn("source URL property is not a url property of the ideas database, Slack permalinks won't be recorded",
+			zap.String("property", property),
+		)
+	}
+
+	types := make(map[string]string, len(schema))
+	for _, property := range schema {
+		types[property.Name] = property.Type
+	}
+	c.setSchemaError(validateIdeasSchema(types))
+
+	return schema, nil
+}
+
+// ErrSchemaMismatch is returned (wrapped) when the ideas database is missing
+// required properties or has them with the wrong type (see requiredIdeasSchema).
+var ErrSchemaMismatch = errors.New("schema mismatch")
+
+// SchemaError returns why submissions are blocked, or nil if they are accepted.
+//
+// Each SyncSchema validates the ideas database against requiredIdeasSchema.
+// While it fails, SubmitSubmission rejects submissions up front instead of
+// creating malformed pages or surfacing Notion's validation errors; the guard
+// lifts on the first sync that passes. Before the first sync, submissions are accepted.
+func (c *Client) SchemaError() error {
+	if err := c.schemaErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// setSchemaError updates the submission guard, alerting when it flips.
+func (c *Client) setSchemaError(err error) {
+	var previous *error
+	if err == nil {
+		previous = c.schemaErr.Swap(nil)
+	} else {
+		previous = c.schemaErr.Swap(&err)
+	}
+	c.recordSchemaValid(err == nil)
+
+	switch {
+	case err != nil && previous == nil:
+		c.logger.Error("ideas database schema is invalid, rejecting submissions until it is fixed",
+			zap.Error(err),
+		)
+	case err == nil && previous != nil:
+		c.logger.Info("ideas database schema is valid again, accepting submissions")
+	}
+}
+
+// FormProperty describes a property of the ideas data source for building forms.
+type FormProperty struct {
+	Name    string
+	Type    string   // Notion property type (e.g., "select", "rich_text", "relation")
+	Options []string // Option names of select and multi_select properties, in schema order
+
+	// RelatesToCustomers is true for relation properties targeting the Customers data source.
+	RelatesToCustomers bool
+}
+
+// GetFormSchema retrieves the ideas data source properties with the details
+// needed to generate form inputs (select options, relation targets).
+//
+// Properties are sorted by name, since Notion doesn't expose column order.
+func (c *Client) GetFormSchema() ([]FormProperty, error) {
+	endpoint := c.dataSourceEndpoint(c.ideasDataSourceID(), "")
+	resp, err := c.makeNotionRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var dsResponse struct {
+		Properties map[string]schemaProperty `json:"properties"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&dsResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	customersSourceID := c.customersSourceID()
+	properties := make([]FormProperty, 0, len(dsResponse.Properties))
+	for name, prop := range dsResponse.Properties {
+		property := FormProperty{Name: name, Type: prop.Type}
+		switch prop.Type {
+		case "select":
+			property.Options = prop.Select.optionNames()
+		case "multi_select":
+			property.Options = prop.MultiSelect.optionNames()
+		case "relation":
+			target := prop.Relation.DataSourceID
+			if target == "" {
+				target = prop.Relation.DatabaseID // Legacy API, where customersSourceID is the database ID
+			}
+			property.RelatesToCustomers = target != "" && normalizeNotionID(target) == normalizeNotionID(customersSourceID)
+		}
+		properties = append(properties, property)
+	}
+	sort.Slice(properties, func(i, j int) bool { return properties[i].Name < properties[j].Name })
+
+	return properties, nil
+}
+
+// schemaProperty is the subset of a data source property definition read by GetFormSchema.
+type schemaProperty struct {
+	Type        string        `json:"type"`
+	Select      schemaOptions `json:"select"`
+	MultiSelect schemaOptions `json:"multi_select"`
+	Relation    struct {
+		DataSourceID string `json:"data_source_id"`
+		DatabaseID   string `json:"database_id"`
+	} `json:"relation"`
+}
+
+// schemaOptions is the options list of a select or multi_select property definition.
+type schemaOptions struct {
+	Options []struct {
+		Name string `json:"name"`
+	} `json:"options"`
+}
+
+func (o schemaOptions) optionNames() []string {
+	names := make([]string, 0, len(o.Options))
+	for _, option := range o.Options {
+		names = append(names, option.Name)
+	}
+	return names
+}
@@ -0,0 +1,128 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// TestGetFormSchema tests reading property types, select options, and relation targets
+func TestGetFormSchema(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.dataSourceID = "ideas-ds"
+	client.customersDataSourceID = "customers-ds"
+	client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+		"GET /v1/data_sources/ideas-ds": jsonResponse(http.StatusOK, `{"object":"data_source","properties":{
+			"Priority":     {"type":"select","select":{"options":[{"name":"High"},{"name":"Low"}]}},
+			"Customer Org": {"type":"relation","relation":{"data_source_id":"customers-ds"}},
+			"Related Epic": {"type":"relation","relation":{"data_source_id":"epics-ds"}},
+			"Due":          {"type":"date","date":{}}
+		}}`),
+	}}}
+
+	schema, err := client.GetFormSchema()
+	if err != nil {
+		t.Fatalf("GetFormSchema() error = %v", err)
+	}
+
+	want := []FormProperty{
+		{Name: "Customer Org", Type: "relation", RelatesToCustomers: true},
+		{Name: "Due", Type: "date"},
+		{Name: "Priority", Type: "select", Options: []string{"High", "Low"}},
+		{Name: "Related Epic", Type: "relation"},
+	}
+	if !reflect.DeepEqual(schema, want) {
+		t.Errorf("GetFormSchema() = %+v, want %+v", schema, want)
+	}
+}
+
+// TestSyncSchema tests that select options are synced from the schema with per-field fallbacks
+func TestSyncSchema(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.dataSourceID = "ideas-ds"
+	client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+		"GET /v1/data_sources/ideas-ds": jsonResponse(http.StatusOK, `{"object":"data_source","properties":{
+			"Theme/Category": {"type":"multi_select","multi_select":{"options":[{"name":"New Feature Idea"},{"name":"Tech Debt"}]}},
+			"Product Area":   {"type":"select","select":{"options":[]}}
+		}}`),
+	}}}
+
+	if got := client.SelectOptions(); !reflect.DeepEqual(got, DefaultSelectOptions()) {
+		t.Errorf("SelectOptions() before sync = %+v, want defaults", got)
+	}
+
+	if _, err := client.SyncSchema(); err != nil {
+		t.Fatalf("SyncSchema() error = %v", err)
+	}
+	options := client.SelectOptions()
+	if !reflect.DeepEqual(options.Themes, []string{"New Feature Idea", "Tech Debt"}) {
+		t.Errorf("Themes = %v", options.Themes)
+	}
+	if !reflect.DeepEqual(options.ProductAreas, constants.ValidProductAreas) {
+		t.Errorf("ProductAreas = %v, want the built-in defaults", options.ProductAreas)
+	}
+
+	// Synced options are used to validate submissions
+	sub := submission.Submission{Title: "Idea", Theme: "Tech Debt", ProductArea: "AI/ML", SubmitterNotionID: "user-1"}
+	if _, err := buildSubmissionProperties(sub, options); err != nil {
+		t.Errorf("buildSubmissionProperties() with synced theme error = %v", err)
+	}
+}
+
+// TestSyncSchema_SubmissionGuard tests that submissions are blocked while required properties are missing
+func TestSyncSchema_SubmissionGuard(t *testing.T) {
+	properties := make(map[string]map[string]string, len(requiredIdeasSchema))
+	for name, propertyType := range requiredIdeasSchema {
+		properties[name] = map[string]string{"type": propertyType}
+	}
+	body, err := json.Marshal(map[string]interface{}{"properties": properties})
+	if err != nil {
+		t.Fatalf("failed to marshal schema: %v", err)
+	}
+	validSchema := string(body)
+	driftedSchema := fmt.Sprintf(`{"properties":{%q:{"type":"title"}}}`, constants.FieldIdeaTopic)
+
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.dataSourceID = "ideas-ds"
+	sync := func(schema string) {
+		t.Helper()
+		client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+			"GET /v1/data_sources/ideas-ds": jsonResponse(http.StatusOK, schema),
+		}}}
+		if _, err := client.SyncSchema(); err != nil {
+			t.Fatalf("SyncSchema() error = %v", err)
+		}
+	}
+
+	if err := client.SchemaError(); err != nil {
+		t.Fatalf("SchemaError() before sync = %v, want nil", err)
+	}
+
+	sync(driftedSchema)
+	if err := client.SchemaError(); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("SchemaError() = %v, want ErrSchemaMismatch", err)
+	}
+
+	// Rejected before any request is made (the transport has no page route)
+	sub := submission.Submission{Title: "Idea", Theme: "New Feature Idea", ProductArea: "AI/ML", SubmitterNotionID: "user-1"}
+	_, err = client.SubmitSubmission(context.Background(), sub)
+	if !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("SubmitSubmission() error = %v, want ErrSchemaMismatch", err)
+	}
+	if !IsRetryableError(err) {
+		t.Error("schema mismatch should be retryable so queued submissions wait for the fix")
+	}
+
+	sync(validSchema)
+	if err := client.SchemaError(); err != nil {
+		t.Errorf("SchemaError() after fix = %v, want nil", err)
+	}
+}
@@ -0,0 +1,180 @@
+package notion
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// sharedCacheTimeout bounds each shared cache operation.
+	sharedCacheTimeout = 2 * time.Second
+
+	// sharedCacheLockTTL is how long a replica may take to fetch a cache from
+	// Notion before another replica may try.
+	sharedCacheLockTTL = 30 * time.Second
+
+	// sharedCacheWait is how long a replica waits for another one to publish
+	// a cache before fetching it from Notion itself.
+	sharedCacheWait = 15 * time.Second
+
+	// sharedCachePollInterval is how often a waiting replica checks for the cache.
+	sharedCachePollInterval = 500 * time.Millisecond
+)
+
+// SharedCache is a key-value store shared by bot replicas (CACHE_BACKEND=redis,
+// see pkg/redisstore). Customer and user caches are published to it so only
+// one replica fetches them from Notion per refresh interval; the rest load
+// them from the store.
+type SharedCache interface {
+	// Get returns the value of key, or nil if it doesn't exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value at key, expiring after ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX stores value at key only if it doesn't exist, and reports whether it did.
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+}
+
+// sharedEntry is a cache as stored in the shared cache.
+type sharedEntry struct {
+	Entries   map[string]string          `json:"entries"`           // Customer name or email -> Notion ID
+	Guests    map[string]string          `json:"guests,omitempty"`  // Users only: external guests
+	Details   map[string]CustomerDetails `json:"details,omitempty"` // Customers only: page ID -> aliases and description
+	UpdatedAt time.Time                  `json:"updated_at"`        // When it was fetched from Notion
+}
+
+// SetSharedCache makes InitializeCustomers and InitializeUsers load caches
+// from store while they are younger than maxAge (the cache refresh
+// interval), and publish them there when they fetch from Notion. A replica
+// that finds the cache stale claims the refresh; the others wait briefly for
+// it to publish.
+//
+// The shared cache is best-effort: if the store is unreachable, caches are
+// fetched from Notion as without it. Targeted entry refreshes
+// (RefreshCustomer, RefreshUser) only update this replica.
+func (c *Client) SetSharedCache(store SharedCache, maxAge time.Duration) {
+	c.sharedCache = store
+	c.sharedCacheMaxAge = maxAge
+	c.sharedCacheWait = sharedCacheWait
+	c.sharedCachePoll = sharedCachePollInterval
+}
+
+// sharedCacheKey returns the key for a cache. Customers are keyed by their
+// database and users by their workspace (the hash of the API key the client
+// was created with, kept when the key is rotated), so tenants and database
+// switches never share entries.
+func (c *Client) sharedCacheKey(cacheType string) string {
+	if cacheType == "customers" {
+		_, customersDBID := c.CurrentDatabases()
+		return "cache:customers:" + customersDBID
+	}
+	return "cache:users:" + c.workspaceKey
+}
+
+// hashAPIKey identifies a workspace by its API key without exposing the key.
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+// loadSharedCache returns the shared cache of cacheType if it is fresh. It
+// returns nil when the caller should fetch from Notion: there is no shared
+// cache, this replica claimed the refresh, waiting for another replica timed
+// out, or the store failed.
+func (c *Client) loadSharedCache(cacheType string) *sharedEntry {
+	if c.sharedCache == nil {
+		return nil
+	}
+	key := c.sharedCacheKey(cacheType)
+	deadline := time.Now().Add(c.sharedCacheWait)
+
+	for {
+		entry, err := c.getSharedEntry(key)
+		if err != nil {
+			c.logger.Warn("failed to read shared cache, fetching from Notion",
+				zap.String("cache", cacheType),
+				zap.Error(err),
+			)
+			return nil
+		}
+		if entry != nil && time.Since(entry.UpdatedAt) < c.sharedCacheMaxAge {
+			c.logger.Info("loaded cache from shared cache",
+				zap.String("cache", cacheType),
+				zap.Int("count", len(entry.Entries)),
+				zap.Time("updated_at", entry.UpdatedAt),
+			)
+			return entry
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), sharedCacheTimeout)
+		claimed, err := c.sharedCache.SetNX(ctx, key+":refresh", []byte(hostname()), sharedCacheLockTTL)
+		cancel()
+		if err != nil {
+			c.logger.Warn("failed to claim shared cache refresh, fetching from Notion",
+				zap.String("cache", cacheType),
+				zap.Error(err),
+			)
+			return nil
+		}
+		if claimed {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			c.logger.Warn("timed out waiting for another replica to refresh the shared cache, fetching from Notion",
+				zap.String("cache", cacheType),
+			)
+			return nil
+		}
+		time.Sleep(c.sharedCachePoll)
+	}
+}
+
+func (c *Client) getSharedEntry(key string) (*sharedEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sharedCacheTimeout)
+	defer cancel()
+
+	data, err := c.sharedCache.Get(ctx, key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var entry sharedEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("invalid shared cache entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// saveSharedCache publishes a cache freshly fetched from Notion. It expires
+// after twice the max age, so a stopped deployment doesn't leave it behind.
+func (c *Client) saveSharedCache(cacheType string, entry sharedEntry) {
+	if c.sharedCache == nil {
+		return
+	}
+	entry.UpdatedAt = time.Now()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		c.logger.Warn("failed to encode shared cache", zap.String("cache", cacheType), zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sharedCacheTimeout)
+	defer cancel()
+	if err := c.sharedCache.Set(ctx, c.sharedCacheKey(cacheType), data, 2*c.sharedCacheMaxAge); err != nil {
+		c.logger.Warn("failed to publish shared cache", zap.String("cache", cacheType), zap.Error(err))
+	}
+}
+
+// hostname identifies the replica holding a refresh claim, for debugging.
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return name
+}
@@ -0,0 +1,179 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// memorySharedCache is a SharedCache in memory (expiry is ignored).
+type memorySharedCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	err  error
+}
+
+func newMemorySharedCache() *memorySharedCache {
+	return &memorySharedCache{data: make(map[string][]byte)}
+}
+
+func (m *memorySharedCache) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data[key], m.err
+}
+
+func (m *memorySharedCache) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return m.err
+}
+
+func (m *memorySharedCache) SetNX(_ context.Context, key string, value []byte, _ time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return false, m.err
+	}
+	if _, ok := m.data[key]; ok {
+		return false, nil
+	}
+	m.data[key] = value
+	return true, nil
+}
+
+func newSharedCacheTestClient(store SharedCache, transport *sequenceTransport) *Client {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.customersDataSourceID = "customers-ds"
+	client.httpClient = &http.Client{Transport: transport}
+	client.SetSharedCache(store, time.Hour)
+	client.sharedCacheWait, client.sharedCachePoll = 20*time.Millisecond, time.Millisecond
+	return client
+}
+
+// TestSharedCache_Customers tests that one replica fetches customers from Notion and the others load them from the shared cache
+func TestSharedCache_Customers(t *testing.T) {
+	store := newMemorySharedCache()
+
+	first := newSharedCacheTestClient(store, &sequenceTransport{results: []func() (*http.Response, error){
+		respond(http.StatusOK, customersQueryResponse([2]string{"Acme", "page-acme"})),
+	}})
+	if err := first.InitializeCustomers(); err != nil {
+		t.Fatalf("first InitializeCustomers() error = %v", err)
+	}
+
+	// No Notion responses: the second replica must not call Notion
+	second := newSharedCacheTestClient(store, &sequenceTransport{})
+	if err := second.InitializeCustomers(); err != nil {
+		t.Fatalf("second InitializeCustomers() error = %v", err)
+	}
+	if id, ok := second.Snapshot().CustomerPageID("Acme"); !ok || id != "page-acme" {
+		t.Errorf("second replica CustomerPageID(Acme) = %q, %v, want page-acme", id, ok)
+	}
+	if first.Snapshot().CustomersChecksum != second.Snapshot().CustomersChecksum {
+		t.Error("replicas have different customer caches")
+	}
+}
+
+// TestSharedCache_Users tests that users and external guests round-trip through the shared cache
+func TestSharedCache_Users(t *testing.T) {
+	store := newMemorySharedCache()
+	entry, _ := json.Marshal(sharedEntry{
+		Entries:   map[string]string{"alice@example.com": "user-alice"},
+		Guests:    map[string]string{"bob@partner.com": "user-bob"},
+		UpdatedAt: time.Now().Add(-time.Minute),
+	})
+	client := newSharedCacheTestClient(store, &sequenceTransport{})
+	store.data[client.sharedCacheKey("users")] = entry
+
+	if err := client.InitializeUsers(); err != nil {
+		t.Fatalf("InitializeUsers() error = %v", err)
+	}
+	snapshot := client.Snapshot()
+	if id, ok := snapshot.NotionUserIDByEmail("alice@example.com"); !ok || id != "user-alice" {
+		t.Errorf("NotionUserIDByEmail(alice) = %q, %v, want user-alice", id, ok)
+	}
+	if !snapshot.IsExternalGuest("bob@partner.com") {
+		t.Error("bob@partner.com is not an external guest, want loaded from the shared cache")
+	}
+}
+
+// TestSharedCache_Fallback tests that stale entries, stuck refreshes and store errors fall back to Notion
+func TestSharedCache_Fallback(t *testing.T) {
+	stale, _ := json.Marshal(sharedEntry{
+		Entries:   map[string]string{"Stale": "page-stale"},
+		UpdatedAt: time.Now().Add(-2 * time.Hour),
+	})
+
+	tests := []struct {
+		name      string
+		setup     func(store *memorySharedCache, key string)
+		wantSaved bool
+	}{
+		{
+			name:      "stale entry",
+			setup:     func(store *memorySharedCache, key string) { store.data[key] = stale },
+			wantSaved: true,
+		},
+		{
+			name: "another replica never publishes",
+			setup: func(store *memorySharedCache, key string) {
+				store.data[key] = stale
+				store.data[key+":refresh"] = []byte("other-replica")
+			},
+			wantSaved: true,
+		},
+		{
+			name:  "store unavailable",
+			setup: func(store *memorySharedCache, _ string) { store.err = errors.New("connection refused") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemorySharedCache()
+			client := newSharedCacheTestClient(store, &sequenceTransport{results: []func() (*http.Response, error){
+				respond(http.StatusOK, customersQueryResponse([2]string{"Acme", "page-acme"})),
+			}})
+			key := client.sharedCacheKey("customers")
+			tt.setup(store, key)
+
+			if err := client.InitializeCustomers(); err != nil {
+				t.Fatalf("InitializeCustomers() error = %v", err)
+			}
+			if _, ok := client.Snapshot().CustomerPageID("Acme"); !ok {
+				t.Error("Acme not cached, want fetched from Notion")
+			}
+
+			if tt.wantSaved {
+				saved, err := client.getSharedEntry(key)
+				if err != nil || saved == nil || saved.Entries["Acme"] != "page-acme" {
+					t.Errorf("shared cache = %+v, %v, want the customers fetched from Notion", saved, err)
+				}
+			}
+		})
+	}
+}
+
+// TestSharedCacheKey tests that caches of different databases and workspaces don't collide
+func TestSharedCacheKey(t *testing.T) {
+	a := NewClient("key-a", "ideas-db", "customers-a", zap.NewNop())
+	b := NewClient("key-b", "ideas-db", "customers-b", zap.NewNop())
+
+	if a.sharedCacheKey("customers") == b.sharedCacheKey("customers") {
+		t.Error("customers of different databases share a key")
+	}
+	if a.sharedCacheKey("users") == b.sharedCacheKey("users") {
+		t.Error("users of different workspaces share a key")
+	}
+	if key := a.sharedCacheKey("users"); len(key) == 0 || key == "cache:users:key-a" {
+		t.Errorf("users key = %q, want a hash rather than the API key", key)
+	}
+}
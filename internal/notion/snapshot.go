@@ -0,0 +1,315 @@
+package notion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CacheSnapshot is an immutable view of the customer and user caches.
+//
+// Refreshes build a new snapshot and swap it in atomically, so a handler that
+// takes one snapshot at the start of a request sees a consistent set of
+// customers and users for the whole request, even if a refresh completes
+// midway. Snapshots are never modified after they are published; the maps and
+// slices they hold must not be mutated by callers.
+type CacheSnapshot struct {
+	customers       map[string]string          // Customer name -> Notion page ID
+	customerDetails map[string]CustomerDetails // Notion page ID -> aliases and description, for customers that have any
+	customerNames   []string                   // Sorted customer names (precomputed for option menus)
+	customerIndex   *CustomerIndex             // Search index over customerNames and their aliases for external selects
+	aliases         map[string]string          // Lowercased alias -> customer name, for aliases of a single customer
+	users           map[string]string          // Normalized email -> Notion user UUID
+	guests          map[string]string          // Normalized email -> Notion user UUID for users outside the domain allowlist
+
+	// BuiltAt is when this snapshot was published.
+	BuiltAt time.Time
+
+	// Version increases by one with every published snapshot (0 is the empty startup snapshot).
+	Version uint64
+
+	// CustomersChecksum and UsersChecksum identify the cached content (users
+	// include excluded guests). They depend only on the entries, not on when or
+	// in which order they were loaded, so replicas that converged to the same
+	// view report the same checksums even if their Versions differ.
+	CustomersChecksum string
+	UsersChecksum     string
+}
+
+// newCacheSnapshot builds a snapshot from the given maps, which it takes ownership of.
+func newCacheSnapshot(customers map[string]string, details map[string]CustomerDetails, users, guests map[string]string, version uint64) *CacheSnapshot {
+	if customers == nil {
+		customers = make(map[string]string)
+	}
+	if details == nil {
+		details = make(map[string]CustomerDetails)
+	}
+	if users == nil {
+		users = make(map[string]string)
+	}
+	if guests == nil {
+		guests = make(map[string]string)
+	}
+
+	names := make([]string, 0, len(customers))
+	for name := range customers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Aliases shared by several customers are searchable but don't identify one
+	var aliases map[string][]string
+	owners := make(map[string]string)
+	for name, pageID := range customers {
+		for _, alias := range details[pageID].Aliases {
+			if aliases == nil {
+				aliases = make(map[string][]string)
+			}
+			aliases[name] = append(aliases[name], alias)
+			normalized := strings.ToLower(alias)
+			if owner, found := owners[normalized]; found && owner != name {
+				owners[normalized] = ""
+			} else {
+				owners[normalized] = name
+			}
+		}
+	}
+	maps.DeleteFunc(owners, func(_, name string) bool { return name == "" })
+
+	return &CacheSnapshot{
+		customers:         customers,
+		customerDetails:   details,
+		customerNames:     names,
+		customerIndex:     newCustomerIndex(slices.Clone(names), aliases),
+		aliases:           owners,
+		users:             users,
+		guests:            guests,
+		BuiltAt:           time.Now().UTC(),
+		Version:           version,
+		CustomersChecksum: checksum(customers, detailEntries(details)),
+		UsersChecksum:     checksum(users, guests),
+	}
+}
+
+// detailEntries flattens customer details into checksummable entries.
+func detailEntries(details map[string]CustomerDetails) map[string]string {
+	entries := make(map[string]string, len(details))
+	for pageID, customer := range details {
+		entries[pageID] = strings.Join(customer.Aliases, "\x00") + "\x00" + customer.Description
+	}
+	return entries
+}
+
+// checksumLength is the number of hex characters kept from the SHA-256 digest.
+const checksumLength = 16
+
+// checksum hashes the entries of the given maps in key order. Each map is
+// delimited, so moving an entry from one map to another changes the checksum.
+func checksum(entries ...map[string]string) string {
+	hash := sha256.New()
+	for _, m := range entries {
+		for _, key := range slices.Sorted(maps.Keys(m)) {
+			hash.Write([]byte(key))
+			hash.Write([]byte{0})
+			hash.Write([]byte(m[key]))
+			hash.Write([]byte{'\n'})
+		}
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:checksumLength]
+}
+
+// NewCacheSnapshot builds a standalone snapshot from customer (name -> page ID)
+// and user (email -> Notion user ID) maps, for alternative cache stores and tests.
+// The maps are copied; emails are normalized like cached Notion users.
+func NewCacheSnapshot(customers, users map[string]string) *CacheSnapshot {
+	return NewCacheSnapshotWithDetails(customers, nil, users)
+}
+
+// NewCacheSnapshotWithDetails is NewCacheSnapshot with customer details
+// (page ID -> aliases and description), which are copied too.
+func NewCacheSnapshotWithDetails(customers map[string]string, details map[string]CustomerDetails, users map[string]string) *CacheSnapshot {
+	normalized := make(map[string]string, len(users))
+	for email, userID := range users {
+		normalized[strings.ToLower(strings.TrimSpace(email))] = userID
+	}
+	return newCacheSnapshot(maps.Clone(customers), maps.Clone(details), normalized, nil, 0)
+}
+
+// CustomerNames returns the sorted customer names. The slice is shared and must not be modified.
+func (s *CacheSnapshot) CustomerNames() []string {
+	return s.customerNames
+}
+
+// SearchCustomers returns up to limit customer names matching query by name
+// or alias, ranked exact, prefix, contains, then fuzzy matches (see CustomerIndex).
+func (s *CacheSnapshot) SearchCustomers(query string, limit int) []string {
+	return s.customerIndex.Search(query, limit)
+}
+
+// CustomerPageID returns the Notion page ID for a customer name.
+func (s *CacheSnapshot) CustomerPageID(name string) (string, bool) {
+	pageID, found := s.customers[name]
+	return pageID, found
+}
+
+// FindCustomer looks up a customer by name, falling back to a case-insensitive
+// match, then to an alias of a single customer. Returns the customer's
+// canonical name and page ID.
+func (s *CacheSnapshot) FindCustomer(name string) (canonical, pageID string, found bool) {
+	name = strings.TrimSpace(name)
+	if canonical, pageID, found := s.findCustomerByName(name); found {
+		return canonical, pageID, true
+	}
+	if canonical, found := s.aliases[strings.ToLower(name)]; found {
+		return canonical, s.customers[canonical], true
+	}
+	return "", "", false
+}
+
+// findCustomerByName is FindCustomer without the alias fallback.
+func (s *CacheSnapshot) findCustomerByName(name string) (canonical, pageID string, found bool) {
+	if pageID, found := s.customers[name]; found {
+		return name, pageID, true
+	}
+	for _, candidate := range s.customerNames {
+		if strings.EqualFold(candidate, name) {
+			return candidate, s.customers[candidate], true
+		}
+	}
+	return "", "", false
+}
+
+// CustomerDetails returns the aliases and description of a customer by name.
+// The aliases are shared and must not be modified.
+func (s *CacheSnapshot) CustomerDetails(name string) CustomerDetails {
+	return s.customerDetails[s.customers[name]]
+}
+
+// CustomerName returns the name of the customer with the given page ID.
+// This is a linear scan; it is only used to pre-fill forms from existing pages.
+func (s *CacheSnapshot) CustomerName(pageID string) (string, bool) {
+	for name, id := range s.customers {
+		if id == pageID {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// CustomerCount returns the number of cached customers.
+func (s *CacheSnapshot) CustomerCount() int {
+	return len(s.customers)
+}
+
+// NotionUserIDByEmail looks up a Notion user UUID by email (case-insensitive).
+func (s *CacheSnapshot) NotionUserIDByEmail(email string) (string, bool) {
+	userID, found := s.users[strings.ToLower(strings.TrimSpace(email))]
+	return userID, found
+}
+
+// UserCount returns the number of cached users.
+func (s *CacheSnapshot) UserCount() int {
+	return len(s.users)
+}
+
+// IsExternalGuest reports whether email belongs to a Notion user excluded by the
+// email domain allowlist (see Client.SetAllowedEmailDomains).
+func (s *CacheSnapshot) IsExternalGuest(email string) bool {
+	_, found := s.guests[strings.ToLower(strings.TrimSpace(email))]
+	return found
+}
+
+// GuestCount returns the number of users excluded by the email domain allowlist.
+func (s *CacheSnapshot) GuestCount() int {
+	return len(s.guests)
+}
+
+// UserEmails returns the cached (normalized) user emails in sorted order.
+func (s *CacheSnapshot) UserEmails() []string {
+	emails := make([]string, 0, len(s.users))
+	for email := range s.users {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+	return emails
+}
+
+// CacheSummary describes a snapshot's content without the entries, for
+// comparing replicas (see GET /admin/cache).
+type CacheSummary struct {
+	Version           uint64    `json:"version"`
+	BuiltAt           time.Time `json:"built_at"`
+	Customers         int       `json:"customers"`
+	CustomersChecksum string    `json:"customers_checksum"`
+	Users             int       `json:"users"`
+	ExcludedGuests    int       `json:"excluded_guests"`
+	UsersChecksum     string    `json:"users_checksum"`
+}
+
+// Summary returns the snapshot's sizes and checksums.
+func (s *CacheSnapshot) Summary() CacheSummary {
+	return CacheSummary{
+		Version:           s.Version,
+		BuiltAt:           s.BuiltAt,
+		Customers:         s.CustomerCount(),
+		CustomersChecksum: s.CustomersChecksum,
+		Users:             s.UserCount(),
+		ExcludedGuests:    s.GuestCount(),
+		UsersChecksum:     s.UsersChecksum,
+	}
+}
+
+// CacheContents is a snapshot's summary and entries, for inspecting what a
+// replica has cached (see GET /admin/cache?contents=true).
+type CacheContents struct {
+	CacheSummary
+	CustomerPages   map[string]string          `json:"customer_pages"`             // Customer name -> Notion page ID
+	CustomerDetails map[string]CustomerDetails `json:"customer_details,omitempty"` // Notion page ID -> aliases and description
+	UserIDs         map[string]string          `json:"user_ids"`                   // Normalized email -> Notion user UUID
+	GuestUserIDs    map[string]string          `json:"excluded_guest_ids"`         // Normalized email -> Notion user UUID
+}
+
+// Contents returns the snapshot's summary with copies of its entries.
+func (s *CacheSnapshot) Contents() CacheContents {
+	return CacheContents{
+		CacheSummary:    s.Summary(),
+		CustomerPages:   maps.Clone(s.customers),
+		CustomerDetails: maps.Clone(s.customerDetails),
+		UserIDs:         maps.Clone(s.users),
+		GuestUserIDs:    maps.Clone(s.guests),
+	}
+}
+
+// Snapshot returns the current cache snapshot. It is never nil.
+//
+// Take one snapshot per request and use it for all lookups in that request.
+func (c *Client) Snapshot() *CacheSnapshot {
+	return c.cache.Load()
+}
+
+// replaceCustomers publishes a new snapshot with the given customers and their details and the current users.
+func (c *Client) replaceCustomers(customers map[string]string, details map[string]CustomerDetails) *CacheSnapshot {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
+	current := c.cache.Load()
+	next := newCacheSnapshot(customers, details, current.users, current.guests, current.Version+1)
+	c.cache.Store(next)
+	return next
+}
+
+// replaceUsers publishes a new snapshot with the given users and guests and the current customers.
+func (c *Client) replaceUsers(users, guests map[string]string) *CacheSnapshot {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
+	current := c.cache.Load()
+	next := newCacheSnapshot(current.customers, current.customerDetails, users, guests, current.Version+1)
+	c.cache.Store(next)
+	return next
+}
@@ -0,0 +1,152 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// snapshotFileVersion is bumped when the snapshot file format changes incompatibly.
+const snapshotFileVersion = 1
+
+// snapshotFile is the on-disk form of the caches (CACHE_SNAPSHOT_FILE).
+//
+// The data source IDs are kept with the databases they were discovered for,
+// so a restored client can create pages without rediscovering them.
+type snapshotFile struct {
+	Version               int                        `json:"version"`
+	SavedAt               time.Time                  `json:"saved_at"`
+	DatabaseID            string                     `json:"database_id"`
+	CustomersDatabaseID   string                     `json:"customers_database_id"`
+	DataSourceID          string                     `json:"data_source_id,omitempty"`
+	CustomersDataSourceID string                     `json:"customers_data_source_id,omitempty"`
+	Customers             map[string]string          `json:"customers"`
+	CustomerDetails       map[string]CustomerDetails `json:"customer_details,omitempty"`
+	Users                 map[string]string          `json:"users"`
+	Guests                map[string]string          `json:"guests,omitempty"`
+}
+
+// SetSnapshotFile makes the client save its caches to path after every
+// refresh, so RestoreSnapshotFile can serve them after a restart while
+// Notion is unavailable. Empty disables it.
+func (c *Client) SetSnapshotFile(path string) {
+	c.snapshotPath = path
+}
+
+// RestoreSnapshotFile loads the caches saved in the snapshot file and returns
+// when they were saved. It is the startup fallback for when the initial fetch
+// from Notion fails: the bot serves the saved (possibly stale) caches until
+// the next successful refresh replaces them.
+//
+// Snapshots saved for other databases are rejected.
+func (c *Client) RestoreSnapshotFile() (time.Time, error) {
+	if c.snapshotPath == "" {
+		return time.Time{}, fmt.Errorf("no cache snapshot file configured")
+	}
+	data, err := os.ReadFile(c.snapshotPath)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read cache snapshot: %w", err)
+	}
+	var file snapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse cache snapshot: %w", err)
+	}
+	if file.Version != snapshotFileVersion {
+		return time.Time{}, fmt.Errorf("unsupported cache snapshot version %d", file.Version)
+	}
+
+	c.targetMu.Lock()
+	if file.DatabaseID != c.databaseID || file.CustomersDatabaseID != c.customersDBID {
+		c.targetMu.Unlock()
+		return time.Time{}, fmt.Errorf("cache snapshot is for databases %s and %s, not %s and %s",
+			file.DatabaseID, file.CustomersDatabaseID, c.databaseID, c.customersDBID)
+	}
+	if c.dataSourceID == "" {
+		c.dataSourceID = file.DataSourceID
+	}
+	if c.customersDataSourceID == "" {
+		c.customersDataSourceID = file.CustomersDataSourceID
+	}
+	c.targetMu.Unlock()
+
+	c.cacheMu.Lock()
+	current := c.cache.Load()
+	snapshot := newCacheSnapshot(file.Customers, file.CustomerDetails, file.Users, file.Guests, current.Version+1)
+	c.cache.Store(snapshot)
+	c.cacheMu.Unlock()
+
+	if c.metrics != nil {
+		c.metrics.ClientCacheSize.Set(float64(snapshot.CustomerCount()))
+		c.metrics.UserCacheSize.Set(float64(snapshot.UserCount()))
+		c.metrics.UserCacheGuests.Set(float64(snapshot.GuestCount()))
+		c.recordCacheChecksums(snapshot)
+	}
+
+	c.logger.Info("restored caches from snapshot file",
+		zap.String("path", c.snapshotPath),
+		zap.Time("saved_at", file.SavedAt),
+		zap.Int("customers", snapshot.CustomerCount()),
+		zap.Int("users", snapshot.UserCount()),
+	)
+	return file.SavedAt, nil
+}
+
+// saveSnapshotFile writes the current caches to the snapshot file, if one is
+// configured. Failures are logged; the in-memory caches are unaffected.
+func (c *Client) saveSnapshotFile() {
+	if c.snapshotPath == "" {
+		return
+	}
+	// Serialize writers so the latest snapshot is the one left on disk
+	c.snapshotFileMu.Lock()
+	defer c.snapshotFileMu.Unlock()
+
+	if err := c.writeSnapshotFile(); err != nil {
+		c.logger.Warn("failed to save cache snapshot file", zap.String("path", c.snapshotPath), zap.Error(err))
+	}
+}
+
+func (c *Client) writeSnapshotFile() error {
+	snapshot := c.Snapshot()
+	c.targetMu.RLock()
+	file := snapshotFile{
+		Version:               snapshotFileVersion,
+		SavedAt:               time.Now(),
+		DatabaseID:            c.databaseID,
+		CustomersDatabaseID:   c.customersDBID,
+		DataSourceID:          c.dataSourceID,
+		CustomersDataSourceID: c.customersDataSourceID,
+		Customers:             snapshot.customers,
+		CustomerDetails:       snapshot.customerDetails,
+		Users:                 snapshot.users,
+		Guests:                snapshot.guests,
+	}
+	c.targetMu.RUnlock()
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.snapshotPath), filepath.Base(c.snapshotPath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp cache snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache snapshot file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache snapshot file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.snapshotPath); err != nil {
+		return fmt.Errorf("failed to replace cache snapshot file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package notion
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// TestSnapshotFile_SaveAndRestore tests that refreshed caches are saved and restored after a restart
+func TestSnapshotFile_SaveAndRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.dataSourceID, client.customersDataSourceID = "ideas-ds", "customers-ds"
+	client.httpClient = &http.Client{Transport: &sequenceTransport{results: []func() (*http.Response, error){
+		respond(http.StatusOK, customersQueryResponse([2]string{"Acme", "page-acme"})),
+	}}}
+	client.SetSnapshotFile(path)
+	client.replaceUsers(map[string]string{"alice@example.com": "user-alice"}, map[string]string{"bob@partner.com": "user-bob"})
+
+	if err := client.InitializeCustomers(); err != nil {
+		t.Fatalf("InitializeCustomers() error = %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("snapshot file not saved: %v", err)
+	}
+
+	restarted := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	restarted.SetSnapshotFile(path)
+	if _, err := restarted.RestoreSnapshotFile(); err != nil {
+		t.Fatalf("RestoreSnapshotFile() error = %v", err)
+	}
+
+	snapshot := restarted.Snapshot()
+	if id, ok := snapshot.CustomerPageID("Acme"); !ok || id != "page-acme" {
+		t.Errorf("CustomerPageID(Acme) = %q, %v, want page-acme", id, ok)
+	}
+	if id, ok := snapshot.NotionUserIDByEmail("alice@example.com"); !ok || id != "user-alice" {
+		t.Errorf("NotionUserIDByEmail(alice) = %q, %v, want user-alice", id, ok)
+	}
+	if !snapshot.IsExternalGuest("bob@partner.com") {
+		t.Error("bob@partner.com is not an external guest after restore")
+	}
+	if snapshot.CustomersChecksum != client.Snapshot().CustomersChecksum || snapshot.UsersChecksum != client.Snapshot().UsersChecksum {
+		t.Error("restored caches differ from the saved ones")
+	}
+	if restarted.ideasDataSourceID() != "ideas-ds" {
+		t.Errorf("data source ID = %q, want restored ideas-ds", restarted.ideasDataSourceID())
+	}
+}
+
+// TestSnapshotFile_RestoreErrors tests that unusable snapshot files are rejected
+func TestSnapshotFile_RestoreErrors(t *testing.T) {
+	dir := t.TempDir()
+	saved := filepath.Join(dir, "cache.json")
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.SetSnapshotFile(saved)
+	client.replaceCustomers(map[string]string{"Acme": "page-acme"}, nil)
+	client.saveSnapshotFile()
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		customersDBID string
+		wantErr       string
+	}{
+		{name: "not configured", customersDBID: "customers-db", wantErr: "no cache snapshot file"},
+		{name: "missing file", path: filepath.Join(dir, "missing.json"), customersDBID: "customers-db", wantErr: "failed to read"},
+		{name: "corrupt file", path: corrupt, customersDBID: "customers-db", wantErr: "failed to parse"},
+		{name: "other databases", path: saved, customersDBID: "other-customers-db", wantErr: "is for databases"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restarted := NewClient("test-key", "ideas-db", tt.customersDBID, zap.NewNop())
+			restarted.SetSnapshotFile(tt.path)
+			_, err := restarted.RestoreSnapshotFile()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("RestoreSnapshotFile() error = %v, want containing %q", err, tt.wantErr)
+			}
+			if restarted.Snapshot().CustomerCount() != 0 {
+				t.Error("caches changed despite the error")
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package notion
+
+import (
+	"maps"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// TestCacheSnapshot_Replace tests that refreshes publish new versions without touching old snapshots
+func TestCacheSnapshot_Replace(t *testing.T) {
+	client := NewClient("test-key", "db-id", "clients-db-id", zap.NewNop())
+
+	initial := client.Snapshot()
+	if initial == nil || initial.Version != 0 {
+		t.Fatalf("initial snapshot = %+v, want empty version 0", initial)
+	}
+
+	client.replaceCustomers(map[string]string{"Beta": "page-b", "Acme": "page-a"}, nil)
+	client.replaceUsers(map[string]string{"user@example.com": "user-1"}, nil)
+
+	current := client.Snapshot()
+	if current.Version != 2 {
+		t.Errorf("Version = %d, want 2", current.Version)
+	}
+	if names := current.CustomerNames(); len(names) != 2 || names[0] != "Acme" || names[1] != "Beta" {
+		t.Errorf("CustomerNames() = %v, want [Acme Beta]", names)
+	}
+	if id, found := current.NotionUserIDByEmail(" User@Example.com "); !found || id != "user-1" {
+		t.Errorf("NotionUserIDByEmail() = %q, %v; want user-1, true", id, found)
+	}
+	if _, found := current.CustomerPageID("Acme"); !found {
+		t.Error("replacing users should keep the current customers")
+	}
+
+	// A snapshot held by an in-flight request is unaffected by later refreshes
+	client.replaceCustomers(map[string]string{"Gamma": "page-g"}, nil)
+	if _, found := current.CustomerPageID("Gamma"); found {
+		t.Error("held snapshot should not see customers published after it")
+	}
+	if initial.CustomerCount() != 0 || initial.UserCount() != 0 {
+		t.Error("initial snapshot should stay empty")
+	}
+}
+
+// TestCacheSnapshot_ConcurrentRefresh tests that concurrent refreshes never lose an update
+func TestCacheSnapshot_ConcurrentRefresh(t *testing.T) {
+	client := NewClient("test-key", "db-id", "clients-db-id", zap.NewNop())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.replaceCustomers(map[string]string{"Acme": "page-a"}, nil)
+		}()
+		go func() {
+			defer wg.Done()
+			client.replaceUsers(map[string]string{"user@example.com": "user-1"}, nil)
+			_ = client.Snapshot().CustomerNames()
+		}()
+	}
+	wg.Wait()
+
+	snapshot := client.Snapshot()
+	if snapshot.Version != 100 {
+		t.Errorf("Version = %d, want 100", snapshot.Version)
+	}
+	if snapshot.CustomerCount() != 1 || snapshot.UserCount() != 1 {
+		t.Errorf("snapshot lost an update: %d customers, %d users", snapshot.CustomerCount(), snapshot.UserCount())
+	}
+}
+
+// TestCacheSnapshot_FindCustomer tests exact and case-insensitive customer lookups
+func TestCacheSnapshot_FindCustomer(t *testing.T) {
+	snapshot := NewCacheSnapshot(map[string]string{"Acme Corp": "page-acme"}, nil)
+
+	for _, name := range []string{"Acme Corp", " acme corp "} {
+		canonical, pageID, found := snapshot.FindCustomer(name)
+		if !found || canonical != "Acme Corp" || pageID != "page-acme" {
+			t.Errorf("FindCustomer(%q) = %q, %q, %v", name, canonical, pageID, found)
+		}
+	}
+	if _, _, found := snapshot.FindCustomer("Acme"); found {
+		t.Error("expected partial name not to match")
+	}
+}
+
+// TestCacheSnapshot_Checksums tests that checksums depend only on the cached content
+func TestCacheSnapshot_Checksums(t *testing.T) {
+	customers := map[string]string{"Acme": "page-a", "Beta": "page-b"}
+	users := map[string]string{"alice@example.com": "user-1"}
+
+	a := newCacheSnapshot(maps.Clone(customers), nil, maps.Clone(users), nil, 1)
+	b := NewCacheSnapshot(map[string]string{"Beta": "page-b", "Acme": "page-a"}, map[string]string{"Alice@Example.com": "user-1"})
+	if a.CustomersChecksum != b.CustomersChecksum || a.UsersChecksum != b.UsersChecksum {
+		t.Errorf("checksums differ for the same content: %+v vs %+v", a.Summary(), b.Summary())
+	}
+	if len(a.CustomersChecksum) != checksumLength || a.CustomersChecksum == a.UsersChecksum {
+		t.Errorf("checksums = %q, %q", a.CustomersChecksum, a.UsersChecksum)
+	}
+
+	changed := []*CacheSnapshot{
+		newCacheSnapshot(map[string]string{"Acme": "page-a", "Beta": "page-c"}, nil, maps.Clone(users), nil, 1),
+		newCacheSnapshot(map[string]string{"Acme": "page-a"}, nil, maps.Clone(users), nil, 1),
+	}
+	for _, s := range changed {
+		if s.CustomersChecksum == a.CustomersChecksum {
+			t.Errorf("customers %v have the same checksum as %v", s.CustomerNames(), a.CustomerNames())
+		}
+	}
+
+	// Excluding a user as a guest changes the users checksum
+	guest := newCacheSnapshot(maps.Clone(customers), nil, nil, maps.Clone(users), 1)
+	if guest.UsersChecksum == a.UsersChecksum {
+		t.Error("moving a user to the excluded guests should change the users checksum")
+	}
+
+	summary := a.Summary()
+	if summary.Version != 1 || summary.Customers != 2 || summary.Users != 1 || summary.CustomersChecksum != a.CustomersChecksum {
+		t.Errorf("Summary() = %+v", summary)
+	}
+}
+
+// TestCacheSnapshot_Contents tests that contents are copies of the snapshot's entries
+func TestCacheSnapshot_Contents(t *testing.T) {
+	snapshot := newCacheSnapshot(
+		map[string]string{"Acme": "page-a"},
+		nil,
+		map[string]string{"alice@example.com": "user-1"},
+		map[string]string{"guest@partner.io": "user-2"},
+		3,
+	)
+
+	contents := snapshot.Contents()
+	if contents.Version != 3 || contents.CustomerPages["Acme"] != "page-a" ||
+		contents.UserIDs["alice@example.com"] != "user-1" || contents.GuestUserIDs["guest@partner.io"] != "user-2" {
+		t.Errorf("Contents() = %+v", contents)
+	}
+
+	contents.CustomerPages["Beta"] = "page-b"
+	if snapshot.CustomerCount() != 1 {
+		t.Error("Contents() exposed the snapshot's maps")
+	}
+}
@@ -0,0 +1,35 @@
+package notion
+
+import (
+	"slices"
+
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+)
+
+// SetSourceURLProperty sets the url property of the ideas database that new
+// pages get their Slack permalink in (submission.Source.SlackPermalink), so
+// the product team can trace an idea back to the discussion it came from.
+// Permalinks are only written once SyncSchema has seen the property with the
+// url type. Empty (the default) disables it.
+func (c *Client) SetSourceURLProperty(name string) {
+	c.settingsMu.Lock()
+	c.sourceURLProperty = name
+	c.settingsMu.Unlock()
+}
+
+// SourceURLProperty returns the property set with SetSourceURLProperty.
+func (c *Client) SourceURLProperty() string {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.sourceURLProperty
+}
+
+// addSourceURL adds the submission's Slack permalink to properties when the
+// source URL property is configured and exists in the database.
+func (c *Client) addSourceURL(properties map[string]Property, sub submission.Submission, options SelectOptions) {
+	permalink, property := sub.Source.SlackPermalink, c.SourceURLProperty()
+	if permalink == "" || property == "" || !slices.Contains(options.URLProperties, property) {
+		return
+	}
+	properties[property] = Property{URL: &permalink}
+}
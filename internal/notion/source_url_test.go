@@ -0,0 +1,46 @@
+package notion
+
+import (
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// TestAddSourceURL tests that the permalink is only written to a configured url property of the database
+func TestAddSourceURL(t *testing.T) {
+	options := SelectOptionsFromSchema([]FormProperty{
+		{Name: "Slack Thread", Type: "url"},
+		{Name: "Notes", Type: "rich_text"},
+	})
+	sub := submission.Submission{Source: submission.Source{SlackPermalink: "https://acme.slack.com/archives/C1/p1700000000000100"}}
+
+	tests := []struct {
+		name     string
+		property string
+		sub      submission.Submission
+		want     bool
+	}{
+		{name: "configured", property: "Slack Thread", sub: sub, want: true},
+		{name: "not configured", sub: sub},
+		{name: "not a url property", property: "Notes", sub: sub},
+		{name: "missing from the database", property: "Thread", sub: sub},
+		{name: "no permalink", property: "Slack Thread"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+			client.SetSourceURLProperty(tt.property)
+
+			properties := map[string]Property{}
+			client.addSourceURL(properties, tt.sub, options)
+			prop, found := properties[tt.property]
+			if found != tt.want {
+				t.Fatalf("properties = %+v, want the permalink written: %v", properties, tt.want)
+			}
+			if found && (prop.URL == nil || *prop.URL != sub.Source.SlackPermalink) {
+				t.Errorf("URL = %v, want the permalink", prop.URL)
+			}
+		})
+	}
+}
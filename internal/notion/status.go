@@ -0,0 +1,85 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+)
+
+// PageStatus is the current state of an idea page, used for follow-up reminders.
+type PageStatus struct {
+	ID             string
+	URL            string
+	Title          string
+	Status         string // Value of constants.FieldStatus; empty if unset or the property doesn't exist
+	Archived       bool   // True if the page was deleted (moved to trash) in Notion
+	LastEditedTime time.Time
+}
+
+// pageResponse is the subset of a Notion page object read by GetPageStatus and QueryIdeas.
+type pageResponse struct {
+	ID             string                 `json:"id"`
+	URL            string                 `json:"url"`
+	Archived       bool                   `json:"archived"`
+	InTrash        bool                   `json:"in_trash"`
+	CreatedTime    time.Time              `json:"created_time"`
+	LastEditedTime time.Time              `json:"last_edited_time"`
+	Properties     map[string]interface{} `json:"properties"`
+}
+
+// GetPageStatus fetches an idea page and returns its title and triage status.
+//
+// The status is read from the constants.FieldStatus property, which may be a
+// Notion "status" or "select" property. Databases without that property are
+// supported; Status is then empty.
+func (c *Client) GetPageStatus(pageID string) (*PageStatus, error) {
+	endpoint := fmt.Sprintf("%s/pages/%s", constants.NotionAPIBaseURL, pageID)
+	resp, err := c.makeNotionRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var page pageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode page response: %w", err)
+	}
+
+	return &PageStatus{
+		ID:             page.ID,
+		URL:            page.URL,
+		Title:          extractTitleFromProperties(page.Properties),
+		Status:         extractStatusFromProperty(page.Properties[constants.FieldStatus]),
+		Archived:       page.Archived || page.InTrash,
+		LastEditedTime: page.LastEditedTime,
+	}, nil
+}
+
+// extractStatusFromProperty returns the option name of a status or select property value.
+//
+// Example property values:
+//
+//	{"type": "status", "status": {"name": "In progress"}}
+//	{"type": "select", "select": {"name": "Planned"}}
+//	{"type": "select", "select": null}  // Returns ""
+func extractStatusFromProperty(value interface{}) string {
+	prop, ok := value.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	propType, _ := prop["type"].(string)
+	if propType != "status" && propType != "select" {
+		return ""
+	}
+
+	option, ok := prop[propType].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	name, _ := option["name"].(string)
+	return name
+}
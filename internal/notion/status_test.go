@@ -0,0 +1,66 @@
+package notion
+
+import (
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// TestGetPageStatus tests reading title and status from a page
+func TestGetPageStatus(t *testing.T) {
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.httpClient = &http.Client{Transport: &routeTransport{routes: map[string]*http.Response{
+		"GET /v1/pages/page-1": jsonResponse(http.StatusOK, `{
+			"object": "page",
+			"id": "page-1",
+			"url": "https://www.notion.so/page-1",
+			"archived": false,
+			"last_edited_time": "2025-11-12T10:00:00.000Z",
+			"properties": {
+				"Idea/Topic": {"type": "title", "title": [{"text": {"content": "Dark mode"}}]},
+				"Status": {"type": "status", "status": {"name": "In progress"}}
+			}
+		}`),
+	}}}
+
+	status, err := client.GetPageStatus("page-1")
+	if err != nil {
+		t.Fatalf("GetPageStatus() error = %v", err)
+	}
+	if status.Title != "Dark mode" {
+		t.Errorf("Title = %q, want Dark mode", status.Title)
+	}
+	if status.Status != "In progress" {
+		t.Errorf("Status = %q, want In progress", status.Status)
+	}
+	if status.URL != "https://www.notion.so/page-1" {
+		t.Errorf("URL = %q", status.URL)
+	}
+	if status.Archived {
+		t.Error("Archived = true, want false")
+	}
+}
+
+// TestExtractStatusFromProperty tests status and select property parsing
+func TestExtractStatusFromProperty(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "status", value: map[string]interface{}{"type": "status", "status": map[string]interface{}{"name": "Shipped"}}, want: "Shipped"},
+		{name: "select", value: map[string]interface{}{"type": "select", "select": map[string]interface{}{"name": "Planned"}}, want: "Planned"},
+		{name: "empty select", value: map[string]interface{}{"type": "select", "select": nil}, want: ""},
+		{name: "other type", value: map[string]interface{}{"type": "rich_text", "rich_text": []interface{}{}}, want: ""},
+		{name: "missing", value: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractStatusFromProperty(tt.value); got != tt.want {
+				t.Errorf("extractStatusFromProperty() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package notion
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+)
+
+// buildSubmissionProperties converts a submission into Notion properties with comprehensive validation.
+//
+// Maps each submission field to its Notion database property and validates it
+// according to its type and business rules:
+//
+// - Title (Idea/Topic): Required, max 2000 chars
+// - Theme/Category: Required, single selection, one of options.Themes
+// - Product Area: Required, single-select, one of options.ProductAreas
+// - Submitted By: Required, People property with Notion user UUID
+// - Comments: Optional, rich text, max 2000 chars
+// - Customer Org: Optional, relation to customer pages, max 10 selections
+// - Artifacts: Optional, rich text with one link per artifact, max 10
+// - Source: The intake channel, only if options.HasSource
+// - Submitted by (Slack): The submitting Slack user, only if options.HasSlackSubmitter
+// - Extra: Optional additional properties, converted by buildExtraProperty
+//
+// Empty values (after trimming) are skipped; validateRequiredFields reports missing required ones.
+func buildSubmissionProperties(sub submission.Submission, options SelectOptions) (map[string]Property, error) {
+	properties := make(map[string]Property, len(coreSubmissionFields)+len(sub.Extra))
+
+	if strings.TrimSpace(sub.Title) != "" {
+		prop, err := buildTitleProperty(sub.Title)
+		if err != nil {
+			return nil, fmt.Errorf("title validation failed: %w", err)
+		}
+		properties[constants.FieldIdeaTopic] = prop
+	}
+
+	if strings.TrimSpace(sub.Theme) != "" {
+		// Notion expects multi_select, but a submission has exactly one theme
+		prop, err := buildSingleMultiSelectProperty(sub.Theme, options.Themes, constants.FieldThemeCategory)
+		if err != nil {
+			return nil, err
+		}
+		properties[constants.FieldThemeCategory] = prop
+	}
+
+	if strings.TrimSpace(sub.ProductArea) != "" {
+		prop, err := buildSelectProperty(sub.ProductArea, options.ProductAreas, constants.FieldProductArea)
+		if err != nil {
+			return nil, err
+		}
+		properties[constants.FieldProductArea] = prop
+	}
+
+	if strings.TrimSpace(sub.Comments) != "" {
+		prop, err := buildRichTextProperty(sub.Comments, constants.FieldComments)
+		if err != nil {
+			return nil, fmt.Errorf("comments validation failed: %w", err)
+		}
+		properties[constants.FieldComments] = prop
+	}
+
+	if len(sub.CustomerOrgIDs) > 0 {
+		prop, err := buildRelationProperty(sub.CustomerOrgIDs, constants.MaxCustomerOrgSelections, constants.FieldCustomerOrg)
+		if err != nil {
+			return nil, err
+		}
+		if len(prop.Relation) > 0 {
+			properties[constants.FieldCustomerOrg] = prop
+		}
+	}
+
+	if len(sub.Artifacts) > 0 {
+		prop, err := buildArtifactsProperty(sub.Artifacts)
+		if err != nil {
+			return nil, err
+		}
+		properties[constants.FieldArtifacts] = prop
+	}
+
+	if channel := sub.Source.ChannelName(); channel != "" && options.HasSource {
+		properties[constants.FieldSource] = Property{Select: &Select{Name: channel}}
+	}
+
+	if submitter := sub.Source.SlackSubmitter(); submitter != "" && options.HasSlackSubmitter {
+		properties[constants.FieldSubmittedBySlack] = Property{RichText: []RichText{{Text: Text{Content: submitter}}}}
+	}
+
+	if strings.TrimSpace(sub.SubmitterNotionID) != "" {
+		prop, err := buildPeopleProperty(sub.SubmitterNotionID)
+		if err != nil {
+			return nil, fmt.Errorf("submitted by validation failed: %w", err)
+		}
+		properties[constants.FieldSubmittedBy] = prop
+	}
+
+	if len(sub.Extra) == 0 {
+		return properties, nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(sub.Extra)) {
+		if _, isCore := properties[name]; isCore || coreSubmissionFields[name] {
+			return nil, fmt.Errorf("additional property %s conflicts with a form field", name)
+		}
+		prop, err := buildExtraProperty(name, sub.Extra[name])
+		if err != nil {
+			return nil, err
+		}
+		properties[name] = prop
+	}
+
+	return properties, nil
+}
+
+// coreSubmissionFields are the properties written from the typed Submission fields.
+var coreSubmissionFields = map[string]bool{
+	constants.FieldIdeaTopic:        true,
+	constants.FieldThemeCategory:    true,
+	constants.FieldProductArea:      true,
+	constants.FieldComments:         true,
+	constants.FieldCustomerOrg:      true,
+	constants.FieldSubmittedBy:      true,
+	constants.FieldSubmittedBySlack: true,
+	constants.FieldArtifacts:        true,
+	constants.FieldSource:           true,
+}
+
+// buildArtifactsProperty creates the Artifacts rich text: one line per
+// artifact, showing its title (or URL if it has none) linked to its URL.
+func buildArtifactsProperty(artifacts []submission.Artifact) (Property, error) {
+	if len(artifacts) > constants.MaxArtifacts {
+		return Property{}, fmt.Errorf("too many artifacts (max: %d, got: %d)", constants.MaxArtifacts, len(artifacts))
+	}
+
+	richText := make([]RichText, 0, 2*len(artifacts)-1)
+	for i, artifact := range artifacts {
+		if !strings.HasPrefix(artifact.URL, "https://") {
+			return Property{}, fmt.Errorf("artifact link %q must be an https URL", artifact.URL)
+		}
+		text := cmp.Or(strings.TrimSpace(artifact.Title), artifact.URL)
+		if runes := []rune(text); len(runes) > constants.MaxCommentLength {
+			text = string(runes[:constants.MaxCommentLength])
+		}
+		if i > 0 {
+			richText = append(richText, RichText{Text: Text{Content: "\n"}})
+		}
+		richText = append(richText, RichText{Text: Text{Content: text, Link: &Link{URL: artifact.URL}}})
+	}
+	return Property{RichText: richText}, nil
+}
+
+// buildExtraProperty converts an additional property value (see submission.Value) into a Notion property.
+//
+// Option names aren't validated here: they come from the schema the modal was
+// built from, and Notion rejects options that no longer exist.
+func buildExtraProperty(name string, value submission.Value) (Property, error) {
+	switch value.Type {
+	case submission.TypeSelect:
+		if len(value.Values) != 1 {
+			return Property{}, fmt.Errorf("%s must have exactly one option, got %d", name, len(value.Values))
+		}
+		return Property{Select: &Select{Name: value.Values[0]}}, nil
+	case submission.TypeMultiSelect:
+		selections := make([]Select, 0, len(value.Values))
+		for _, option := range value.Values {
+			selections = append(selections, Select{Name: option})
+		}
+		return Property{MultiSelect: selections}, nil
+	case submission.TypeRichText:
+		return buildRichTextProperty(value.Text, name)
+	case submission.TypePeople:
+		people := make([]NotionUser, 0, len(value.Values))
+		for _, userID := range value.Values {
+			people = append(people, NotionUser{Object: "user", ID: userID})
+		}
+		return Property{People: people}, nil
+	case submission.TypeDate:
+		if _, err := time.Parse(time.DateOnly, value.Date); err != nil {
+			return Property{}, fmt.Errorf("%s must be a YYYY-MM-DD date: %w", name, err)
+		}
+		return Property{Date: &Date{Start: value.Date}}, nil
+	case submission.TypeRelation:
+		return buildRelationProperty(value.Values, constants.MaxCustomerOrgSelections, name)
+	default:
+		return Property{}, fmt.Errorf("%s has unsupported type %q", name, value.Type)
+	}
+}
+
+// SubmissionFromFields converts a legacy field map into a submission.
+//
+// Keys may be Notion field names or any of their aliases (see the Alias*
+// constants); unknown keys are rejected. Customer Org is a comma-separated
+// list of customer names, resolved to page IDs against the given snapshot.
+//
+// This is the conversion layer for callers that still speak in field maps;
+// the Slack handler builds submissions directly.
+func SubmissionFromFields(snapshot *CacheSnapshot, fields map[string]string) (submission.Submission, error) {
+	var sub submission.Submission
+
+	for key, value := range fields {
+		trimmed := strings.TrimSpace(value)
+		if trimmed == "" {
+			continue // Skip empty values
+		}
+
+		field, ok := fieldAliases[key]
+		if !ok {
+			return submission.Submission{}, fmt.Errorf("unknown field: %s", key)
+		}
+		switch field {
+		case constants.FieldIdeaTopic:
+			sub.Title = trimmed
+		case constants.FieldThemeCategory:
+			sub.Theme = trimmed
+		case constants.FieldProductArea:
+			sub.ProductArea = trimmed
+		case constants.FieldComments:
+			sub.Comments = trimmed
+		case constants.FieldCustomerOrg:
+			sub.CustomerOrgs = splitNames(trimmed)
+		case constants.FieldSubmittedBy:
+			sub.SubmitterNotionID = trimmed
+		}
+	}
+
+	ids, err := resolveCustomerIDs(snapshot, sub.CustomerOrgs)
+	if err != nil {
+		return submission.Submission{}, err
+	}
+	sub.CustomerOrgIDs = ids
+
+	return sub, nil
+}
+
+// fieldAliases maps every accepted field map key (Notion field names and their
+// aliases) to its Notion field name, so SubmissionFromFields resolves a key with
+// a single lookup.
+var fieldAliases = map[string]string{
+	constants.FieldIdeaTopic:     constants.FieldIdeaTopic,
+	constants.AliasTitle:         constants.FieldIdeaTopic,
+	constants.AliasIdea:          constants.FieldIdeaTopic,
+	constants.AliasTopic:         constants.FieldIdeaTopic,
+	constants.FieldThemeCategory: constants.FieldThemeCategory,
+	constants.AliasTheme:         constants.FieldThemeCategory,
+	constants.AliasCategory:      constants.FieldThemeCategory,
+	constants.FieldProductArea:   constants.FieldProductArea,
+	constants.AliasProductArea:   constants.FieldProductArea,
+	constants.AliasArea:          constants.FieldProductArea,
+	constants.FieldComments:      constants.FieldComments,
+	constants.AliasComments:      constants.FieldComments,
+	constants.AliasComment:       constants.FieldComments,
+	constants.FieldCustomerOrg:   constants.FieldCustomerOrg,
+	constants.AliasCustomerOrg:   constants.FieldCustomerOrg,
+	constants.AliasCustomer:      constants.FieldCustomerOrg,
+	constants.AliasOrg:           constants.FieldCustomerOrg,
+	constants.FieldSubmittedBy:   constants.FieldSubmittedBy,
+	constants.AliasSubmittedBy:   constants.FieldSubmittedBy,
+}
+
+// resolveCustomerIDs looks up the Notion page ID of each customer name in the snapshot.
+func resolveCustomerIDs(snapshot *CacheSnapshot, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		pageID, found := snapshot.CustomerPageID(name)
+		if !found {
+			return nil, fmt.Errorf("invalid %s value: '%s' (not found in customer database)", constants.FieldCustomerOrg, name)
+		}
+		ids = append(ids, pageID)
+	}
+	return ids, nil
+}
+
+// splitNames splits a comma-separated list, trimming whitespace and dropping empty entries.
+func splitNames(value string) []string {
+	names := make([]string, 0, strings.Count(value, ",")+1)
+	for part := range strings.SplitSeq(value, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			names = append(names, trimmed)
+		}
+	}
+	return names
+}
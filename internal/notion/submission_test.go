@@ -0,0 +1,419 @@
+package notion
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+)
+
+// TestSubmissionFromFields tests converting legacy alias maps into submissions
+func TestSubmissionFromFields(t *testing.T) {
+	snapshot := newCacheSnapshot(map[string]string{"Customer A": "page-a", "Customer B": "page-b"}, nil, nil, nil, 1)
+
+	tests := []struct {
+		name      string
+		fields    map[string]string
+		want      submission.Submission
+		wantError bool
+	}{
+		{
+			name: "aliases",
+			fields: map[string]string{
+				constants.AliasIdea:        " Test Idea ",
+				constants.AliasCategory:    "New Feature Idea",
+				constants.AliasArea:        "AI/ML",
+				constants.AliasComment:     "context",
+				constants.AliasOrg:         "Customer B, Customer A,",
+				constants.AliasSubmittedBy: "user-1",
+			},
+			want: submission.Submission{
+				Title:             "Test Idea",
+				Theme:             "New Feature Idea",
+				ProductArea:       "AI/ML",
+				Comments:          "context",
+				CustomerOrgs:      []string{"Customer B", "Customer A"},
+				CustomerOrgIDs:    []string{"page-b", "page-a"},
+				SubmitterNotionID: "user-1",
+			},
+		},
+		{
+			name: "notion field names",
+			fields: map[string]string{
+				constants.FieldIdeaTopic:   "Test Idea",
+				constants.FieldComments:    "   ",
+				constants.FieldCustomerOrg: "",
+			},
+			want: submission.Submission{Title: "Test Idea"},
+		},
+		{
+			name:      "unknown customer",
+			fields:    map[string]string{constants.AliasCustomerOrg: "Unknown"},
+			wantError: true,
+		},
+		{
+			name:      "unknown field",
+			fields:    map[string]string{"priority": "high"},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SubmissionFromFields(snapshot, tt.fields)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("SubmissionFromFields() error = %v, wantError %v", err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+			if got.Title != tt.want.Title || got.Theme != tt.want.Theme || got.ProductArea != tt.want.ProductArea ||
+				got.Comments != tt.want.Comments || got.SubmitterNotionID != tt.want.SubmitterNotionID {
+				t.Errorf("SubmissionFromFields() = %+v, want %+v", got, tt.want)
+			}
+			if !slices.Equal(got.CustomerOrgs, tt.want.CustomerOrgs) || !slices.Equal(got.CustomerOrgIDs, tt.want.CustomerOrgIDs) {
+				t.Errorf("customers = %v / %v, want %v / %v", got.CustomerOrgs, got.CustomerOrgIDs, tt.want.CustomerOrgs, tt.want.CustomerOrgIDs)
+			}
+		})
+	}
+}
+
+// TestBuildSubmissionProperties tests converting a submission into Notion properties
+func TestBuildSubmissionProperties(t *testing.T) {
+	valid := submission.Submission{
+		Title:             "Test Idea",
+		Theme:             "New Feature Idea",
+		ProductArea:       "AI/ML",
+		Comments:          "context",
+		CustomerOrgs:      []string{"Customer A"},
+		CustomerOrgIDs:    []string{"page-a"},
+		SubmitterNotionID: "user-1",
+		Source:            submission.Source{Channel: submission.ChannelSlack, SlackUserID: "U123"},
+	}
+
+	props, err := buildSubmissionProperties(valid, DefaultSelectOptions())
+	if err != nil {
+		t.Fatalf("buildSubmissionProperties() error = %v", err)
+	}
+	if len(props) != 6 {
+		t.Errorf("expected 6 properties, got %d", len(props))
+	}
+	if relation := props[constants.FieldCustomerOrg].Relation; len(relation) != 1 || relation[0].ID != "page-a" {
+		t.Errorf("customer relation = %+v, want page-a", relation)
+	}
+	if people := props[constants.FieldSubmittedBy].People; len(people) != 1 || people[0].ID != "user-1" {
+		t.Errorf("submitted by = %+v, want user-1", people)
+	}
+
+	// Optional fields are omitted when empty
+	required := valid
+	required.Comments = ""
+	required.CustomerOrgs, required.CustomerOrgIDs = nil, nil
+	props, err = buildSubmissionProperties(required, DefaultSelectOptions())
+	if err != nil {
+		t.Fatalf("buildSubmissionProperties() error = %v", err)
+	}
+	if len(props) != 4 {
+		t.Errorf("expected 4 properties, got %d", len(props))
+	}
+
+	// Invalid values are rejected
+	invalidTheme := valid
+	invalidTheme.Theme = "Not A Theme"
+	if _, err := buildSubmissionProperties(invalidTheme, DefaultSelectOptions()); err == nil {
+		t.Error("expected error for invalid theme")
+	}
+
+	tooMany := valid
+	tooMany.CustomerOrgIDs = make([]string, constants.MaxCustomerOrgSelections+1)
+	for i := range tooMany.CustomerOrgIDs {
+		tooMany.CustomerOrgIDs[i] = "page"
+	}
+	if _, err := buildSubmissionProperties(tooMany, DefaultSelectOptions()); err == nil {
+		t.Error("expected error for too many customer orgs")
+	}
+}
+
+// TestBuildSubmissionProperties_Artifacts tests that artifacts become one link per line
+func TestBuildSubmissionProperties_Artifacts(t *testing.T) {
+	sub := submission.Submission{
+		Title:             "Test Idea",
+		Theme:             "New Feature Idea",
+		ProductArea:       "AI/ML",
+		SubmitterNotionID: "user-1",
+		Artifacts: []submission.Artifact{
+			{URL: "https://acme.slack.com/docs/T1/F1", Title: "Export plan"},
+			{URL: "https://app.slack.com/huddle/T1/C1"},
+		},
+	}
+
+	props, err := buildSubmissionProperties(sub, DefaultSelectOptions())
+	if err != nil {
+		t.Fatalf("buildSubmissionProperties() error = %v", err)
+	}
+	richText := props[constants.FieldArtifacts].RichText
+	if len(richText) != 3 {
+		t.Fatalf("expected 3 rich text items, got %+v", richText)
+	}
+	if text := richText[0].Text; text.Content != "Export plan" || text.Link == nil || text.Link.URL != "https://acme.slack.com/docs/T1/F1" {
+		t.Errorf("first artifact = %+v", text)
+	}
+	if text := richText[1].Text; text.Content != "\n" || text.Link != nil {
+		t.Errorf("separator = %+v", text)
+	}
+	if text := richText[2].Text; text.Content != "https://app.slack.com/huddle/T1/C1" || text.Link == nil {
+		t.Errorf("untitled artifact = %+v, want its URL as text", text)
+	}
+
+	insecure := sub
+	insecure.Artifacts = []submission.Artifact{{URL: "javascript:alert(1)"}}
+	if _, err := buildSubmissionProperties(insecure, DefaultSelectOptions()); err == nil {
+		t.Error("expected error for non-https artifact")
+	}
+
+	tooMany := sub
+	tooMany.Artifacts = make([]submission.Artifact, constants.MaxArtifacts+1)
+	for i := range tooMany.Artifacts {
+		tooMany.Artifacts[i] = submission.Artifact{URL: "https://app.slack.com/huddle/T1/C1"}
+	}
+	if _, err := buildSubmissionProperties(tooMany, DefaultSelectOptions()); err == nil {
+		t.Error("expected error for too many artifacts")
+	}
+}
+
+// TestBuildSubmissionProperties_Source tests recording the intake channel
+func TestBuildSubmissionProperties_Source(t *testing.T) {
+	sub := submission.Submission{
+		Title:             "Test Idea",
+		Theme:             "New Feature Idea",
+		ProductArea:       "AI/ML",
+		SubmitterNotionID: "user-1",
+		Source:            submission.Source{Channel: submission.ChannelAPI},
+	}
+
+	// Databases without a Source select don't get the property
+	props, err := buildSubmissionProperties(sub, DefaultSelectOptions())
+	if err != nil {
+		t.Fatalf("buildSubmissionProperties() error = %v", err)
+	}
+	if _, ok := props[constants.FieldSource]; ok {
+		t.Errorf("Source set without a Source property: %+v", props[constants.FieldSource])
+	}
+
+	options := SelectOptionsFromSchema([]FormProperty{{Name: constants.FieldSource, Type: "select"}})
+	if !options.HasSource {
+		t.Fatal("SelectOptionsFromSchema() HasSource = false, want true")
+	}
+	props, err = buildSubmissionProperties(sub, options)
+	if err != nil {
+		t.Fatalf("buildSubmissionProperties() error = %v", err)
+	}
+	if got := props[constants.FieldSource].Select; got == nil || got.Name != submission.ChannelAPI {
+		t.Errorf("Source = %+v, want %s", got, submission.ChannelAPI)
+	}
+
+	// Submissions queued before the Slack channels were split are recorded as the modal
+	sub.Source.Channel = submission.ChannelSlack
+	props, err = buildSubmissionProperties(sub, options)
+	if err != nil {
+		t.Fatalf("buildSubmissionProperties() error = %v", err)
+	}
+	if got := props[constants.FieldSource].Select; got == nil || got.Name != submission.ChannelSlackModal {
+		t.Errorf("Source = %+v, want %s", got, submission.ChannelSlackModal)
+	}
+
+	// Extra values can't overwrite it
+	sub.Extra = map[string]submission.Value{constants.FieldSource: {Type: submission.TypeSelect, Values: []string{"web"}}}
+	if _, err := buildSubmissionProperties(sub, options); err == nil {
+		t.Error("expected error for an additional Source property")
+	}
+}
+
+// TestBuildSubmissionProperties_SlackSubmitter tests recording the Slack user
+// who submitted the idea
+func TestBuildSubmissionProperties_SlackSubmitter(t *testing.T) {
+	sub := submission.Submission{
+		Title:             "Test Idea",
+		Theme:             "New Feature Idea",
+		ProductArea:       "AI/ML",
+		SubmitterNotionID: "fallback-user",
+		Source: submission.Source{
+			Channel:        submission.ChannelSlackModal,
+			SlackUserID:    "U123",
+			SubmitterEmail: "jane@contractor.example.com",
+			SubmitterName:  "Jane Doe",
+		},
+	}
+
+	// Databases without a Submitted by (Slack) property don't get it
+	props, err := buildSubmissionProperties(sub, DefaultSelectOptions())
+	if err != nil {
+		t.Fatalf("buildSubmissionProperties() error = %v", err)
+	}
+	if _, ok := props[constants.FieldSubmittedBySlack]; ok {
+		t.Errorf("Submitted by (Slack) set without the property: %+v", props[constants.FieldSubmittedBySlack])
+	}
+
+	options := SelectOptionsFromSchema([]FormProperty{{Name: constants.FieldSubmittedBySlack, Type: "rich_text"}})
+	if !options.HasSlackSubmitter {
+		t.Fatal("SelectOptionsFromSchema() HasSlackSubmitter = false, want true")
+	}
+	props, err = buildSubmissionProperties(sub, options)
+	if err != nil {
+		t.Fatalf("buildSubmissionProperties() error = %v", err)
+	}
+	want := "Jane Doe <jane@contractor.example.com> (Slack U123)"
+	if got := props[constants.FieldSubmittedBySlack].RichText; len(got) != 1 || got[0].Text.Content != want {
+		t.Errorf("Submitted by (Slack) = %+v, want %q", got, want)
+	}
+
+	// API submissions have no Slack submitter
+	sub.Source = submission.Source{Channel: submission.ChannelAPI}
+	props, err = buildSubmissionProperties(sub, options)
+	if err != nil {
+		t.Fatalf("buildSubmissionProperties() error = %v", err)
+	}
+	if _, ok := props[constants.FieldSubmittedBySlack]; ok {
+		t.Errorf("Submitted by (Slack) set for an API submission: %+v", props[constants.FieldSubmittedBySlack])
+	}
+}
+
+// TestBuildSubmissionProperties_Extra tests converting additional property values
+func TestBuildSubmissionProperties_Extra(t *testing.T) {
+	base := submission.Submission{
+		Title:             "Test Idea",
+		Theme:             "New Feature Idea",
+		ProductArea:       "AI/ML",
+		SubmitterNotionID: "user-1",
+	}
+
+	tests := []struct {
+		name      string
+		value     submission.Value
+		check     func(Property) bool
+		wantError bool
+	}{
+		{
+			name:  "select",
+			value: submission.Value{Type: submission.TypeSelect, Values: []string{"High"}},
+			check: func(p Property) bool { return p.Select != nil && p.Select.Name == "High" },
+		},
+		{
+			name:  "multi select",
+			value: submission.Value{Type: submission.TypeMultiSelect, Values: []string{"A", "B"}},
+			check: func(p Property) bool { return len(p.MultiSelect) == 2 && p.MultiSelect[1].Name == "B" },
+		},
+		{
+			name:  "rich text",
+			value: submission.Value{Type: submission.TypeRichText, Text: "notes"},
+			check: func(p Property) bool { return len(p.RichText) == 1 && p.RichText[0].Text.Content == "notes" },
+		},
+		{
+			name:  "people",
+			value: submission.Value{Type: submission.TypePeople, Values: []string{"user-2"}},
+			check: func(p Property) bool { return len(p.People) == 1 && p.People[0].ID == "user-2" },
+		},
+		{
+			name:  "date",
+			value: submission.Value{Type: submission.TypeDate, Date: "2025-12-01"},
+			check: func(p Property) bool { return p.Date != nil && p.Date.Start == "2025-12-01" },
+		},
+		{
+			name:  "relation",
+			value: submission.Value{Type: submission.TypeRelation, Values: []string{"page-a"}},
+			check: func(p Property) bool { return len(p.Relation) == 1 && p.Relation[0].ID == "page-a" },
+		},
+		{name: "invalid date", value: submission.Value{Type: submission.TypeDate, Date: "tomorrow"}, wantError: true},
+		{name: "select without option", value: submission.Value{Type: submission.TypeSelect}, wantError: true},
+		{name: "unsupported type", value: submission.Value{Type: "formula"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := base
+			sub.Extra = map[string]submission.Value{"Extra": tt.value}
+
+			props, err := buildSubmissionProperties(sub, DefaultSelectOptions())
+			if (err != nil) != tt.wantError {
+				t.Fatalf("buildSubmissionProperties() error = %v, wantError %v", err, tt.wantError)
+			}
+			if !tt.wantError && !tt.check(props["Extra"]) {
+				t.Errorf("property = %+v", props["Extra"])
+			}
+		})
+	}
+
+	// Additional properties can't overwrite form fields
+	conflict := base
+	conflict.Extra = map[string]submission.Value{constants.FieldComments: {Type: submission.TypeRichText, Text: "x"}}
+	if _, err := buildSubmissionProperties(conflict, DefaultSelectOptions()); err == nil {
+		t.Error("expected error for additional property named like a form field")
+	}
+}
+
+// benchmarkFields is a typical legacy field map with every field set
+var benchmarkFields = map[string]string{
+	constants.AliasTitle:       "Support exporting dashboards to PDF",
+	constants.AliasTheme:       "Customer Pain Point",
+	constants.AliasProductArea: "AI/ML",
+	constants.AliasComments:    "Several enterprise customers asked for this during QBRs.",
+	constants.AliasCustomerOrg: "Customer A, Customer B",
+	constants.AliasSubmittedBy: "user-1",
+}
+
+// TestSubmissionAllocations guards the allocations of the submission hot path
+// measured by the benchmarks below, so a regression fails `make test` instead
+// of waiting for someone to compare `make bench` output. Raise a budget only
+// alongside the change that needs it.
+func TestSubmissionAllocations(t *testing.T) {
+	snapshot := newCacheSnapshot(map[string]string{"Customer A": "page-a", "Customer B": "page-b"}, nil, nil, nil, 1)
+	sub, err := SubmissionFromFields(snapshot, benchmarkFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := DefaultSelectOptions()
+
+	tests := []struct {
+		name   string
+		budget float64
+		run    func()
+	}{
+		{"SubmissionFromFields", 2, func() { SubmissionFromFields(snapshot, benchmarkFields) }},
+		{"buildSubmissionProperties", 16, func() { buildSubmissionProperties(sub, options) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if allocs := testing.AllocsPerRun(100, tt.run); allocs > tt.budget {
+				t.Errorf("%s allocates %v times per call, budget %v", tt.name, allocs, tt.budget)
+			}
+		})
+	}
+}
+
+func BenchmarkSubmissionFromFields(b *testing.B) {
+	snapshot := newCacheSnapshot(map[string]string{"Customer A": "page-a", "Customer B": "page-b"}, nil, nil, nil, 1)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := SubmissionFromFields(snapshot, benchmarkFields); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildSubmissionProperties(b *testing.B) {
+	snapshot := newCacheSnapshot(map[string]string{"Customer A": "page-a", "Customer B": "page-b"}, nil, nil, nil, 1)
+	sub, err := SubmissionFromFields(snapshot, benchmarkFields)
+	if err != nil {
+		b.Fatal(err)
+	}
+	options := DefaultSelectOptions()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := buildSubmissionProperties(sub, options); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// SetVotes sets the Votes number property of an idea page. The count is
+// absolute, so a retried or reordered update can't drift from the reactions
+// on the card. Fails without a request when SyncSchema hasn't seen the
+// property with the number type.
+func (c *Client) SetVotes(pageID string, votes int) error {
+	start := time.Now()
+	err := c.setVotes(pageID, votes)
+	c.recordNotionRequest("set_votes", start, err)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Debug("updated notion page votes", zap.String("page_id", pageID), zap.Int("votes", votes))
+	return nil
+}
+
+func (c *Client) setVotes(pageID string, votes int) error {
+	if !c.SelectOptions().HasVotes {
+		return fmt.Errorf("ideas database has no %q number property", constants.FieldVotes)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"properties": map[string]Property{constants.FieldVotes: {Number: &votes}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/pages/%s", constants.NotionAPIBaseURL, pageID)
+	resp, err := c.makeNotionRequest("PATCH", endpoint, body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,41 @@
+package notion
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// TestSetVotes tests that votes are written as a number, including zero, only when the database has the property
+func TestSetVotes(t *testing.T) {
+	recorder := &bodyRecorder{
+		bodies: make(map[string][]byte),
+		next: &routeTransport{routes: map[string]*http.Response{
+			"PATCH /v1/pages/page-1": jsonResponse(http.StatusOK, `{"id":"page-1"}`),
+		}},
+	}
+	client := NewClient("test-key", "ideas-db", "customers-db", zap.NewNop())
+	client.httpClient = &http.Client{Transport: recorder}
+
+	if err := client.SetVotes("page-1", 3); err == nil {
+		t.Fatal("expected error before the schema has a Votes property")
+	}
+	if len(recorder.bodies) != 0 {
+		t.Fatalf("sent %d requests without a Votes property", len(recorder.bodies))
+	}
+
+	options := SelectOptionsFromSchema([]FormProperty{{Name: constants.FieldVotes, Type: "number"}})
+	if !options.HasVotes {
+		t.Fatal("HasVotes = false for a Votes number property")
+	}
+	client.selectOptions.Store(&options)
+
+	if err := client.SetVotes("page-1", 0); err != nil {
+		t.Fatalf("SetVotes() error = %v", err)
+	}
+	if got := string(recorder.bodies["PATCH /v1/pages/page-1"]); got != `{"properties":{"Votes":{"number":0}}}` {
+		t.Errorf("request body = %s, want the Votes number", got)
+	}
+}
@@ -0,0 +1,117 @@
+package slack
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// adminAccess holds who may run admin subcommands: the users in
+// SLACK_ADMIN_USER_IDS and the members of SLACK_ADMIN_USERGROUP. Group members
+// are looked up per workspace and cached for constants.SlackAdminUsergroupTTL.
+type adminAccess struct {
+	userIDs   map[string]bool
+	usergroup string
+
+	mu      sync.Mutex
+	members map[string]usergroupMembers // Team ID -> cached user group members
+}
+
+type usergroupMembers struct {
+	userIDs   map[string]bool
+	fetchedAt time.Time
+}
+
+func newAdminAccess(userIDs []string, usergroup string) *adminAccess {
+	access := &adminAccess{
+		userIDs:   make(map[string]bool, len(userIDs)),
+		usergroup: usergroup,
+		members:   make(map[string]usergroupMembers),
+	}
+	for _, userID := range userIDs {
+		access.userIDs[userID] = true
+	}
+	return access
+}
+
+// restricted reports whether admin subcommands are limited at all. Without
+// SLACK_ADMIN_USER_IDS or SLACK_ADMIN_USERGROUP everyone may run them.
+func (a *adminAccess) restricted() bool {
+	return a != nil && (len(a.userIDs) > 0 || a.usergroup != "")
+}
+
+// authorizeAdmin reports whether a user may run an admin subcommand. Denied
+// attempts are logged and counted. A failed user group lookup denies access
+// unless the user is listed in SLACK_ADMIN_USER_IDS.
+func (h *Handler) authorizeAdmin(ctx context.Context, teamID, userID, subcommand string) bool {
+	if !h.admins.restricted() || h.isAdmin(ctx, teamID, userID) {
+		return true
+	}
+
+	h.logger.Warn("admin subcommand denied",
+		zap.String("subcommand", subcommand),
+		zap.String("user_id", userID),
+		zap.String("team_id", teamID),
+	)
+	h.recordAdminCommandDenied(subcommand)
+	return false
+}
+
+// isAdmin reports whether a user is listed in SLACK_ADMIN_USER_IDS or is a
+// member of SLACK_ADMIN_USERGROUP. Unlike authorizeAdmin, nobody is an admin
+// when neither is set. A failed user group lookup is logged and counts as not
+// a member.
+func (h *Handler) isAdmin(ctx context.Context, teamID, userID string) bool {
+	if !h.admins.restricted() {
+		return false
+	}
+	if h.admins.userIDs[userID] {
+		return true
+	}
+	if h.admins.usergroup == "" {
+		return false
+	}
+	member, err := h.usergroupMember(ctx, teamID, userID)
+	if err != nil {
+		h.recordSlackAPIError("usergroups.users.list", err)
+		h.logger.Error("failed to look up admin user group members",
+			zap.String("usergroup", h.admins.usergroup),
+			zap.String("team_id", teamID),
+			zap.Error(err),
+		)
+	}
+	return member
+}
+
+// usergroupMember reports whether a user is in SLACK_ADMIN_USERGROUP, using
+// the workspace's cached member list while it is fresh.
+func (h *Handler) usergroupMember(ctx context.Context, teamID, userID string) (bool, error) {
+	a := h.admins
+	now := h.clock.Now()
+
+	a.mu.Lock()
+	cached, ok := a.members[teamID]
+	a.mu.Unlock()
+	if ok && now.Sub(cached.fetchedAt) < constants.SlackAdminUsergroupTTL {
+		return cached.userIDs[userID], nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, constants.SlackAdminCheckTimeout)
+	defer cancel()
+	members, err := h.slackFor(teamID).GetUserGroupMembersContext(ctx, a.usergroup)
+	if err != nil {
+		return false, err
+	}
+
+	cached = usergroupMembers{userIDs: make(map[string]bool, len(members)), fetchedAt: now}
+	for _, member := range members {
+		cached.userIDs[member] = true
+	}
+	a.mu.Lock()
+	a.members[teamID] = cached
+	a.mu.Unlock()
+	return cached.userIDs[userID], nil
+}
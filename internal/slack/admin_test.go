@@ -0,0 +1,93 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"go.uber.org/zap"
+)
+
+func newAdminTestHandler(slackAPI *fakeSlack, clock Clock) *Handler {
+	return NewHandlerWithDependencies(&config.Config{
+		SlackSigningSecret:  "secret",
+		SlackAdminUserIDs:   []string{"U-admin"},
+		SlackAdminUsergroup: "S-ops",
+	}, zap.NewNop(), Dependencies{Backend: &fakeBackend{}, Slack: slackAPI, Clock: clock})
+}
+
+// TestHandleSlashCommand_AdminDenied tests that admin subcommands are refused to other users
+func TestHandleSlashCommand_AdminDenied(t *testing.T) {
+	handler := newAdminTestHandler(&fakeSlack{usergroups: map[string][]string{"S-ops": {"U-oncall"}}}, fixedClock(time.Now()))
+
+	run := func(userID string) *httptest.ResponseRecorder {
+		body := url.Values{
+			"command": {"/hopperbot"},
+			"text":    {"refresh-cache"},
+			"team_id": {"T456"},
+			"user_id": {userID},
+		}.Encode()
+		w := httptest.NewRecorder()
+		handler.HandleSlashCommand(w, createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+		return w
+	}
+
+	w := run("U-someone")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "limited to Hopperbot admins") {
+		t.Errorf("response = %d %q, want the ephemeral denial", w.Code, w.Body.String())
+	}
+
+	// Admins get past the check (to the missing cache manager's 500)
+	for _, userID := range []string{"U-admin", "U-oncall"} {
+		if w := run(userID); w.Code != http.StatusInternalServerError {
+			t.Errorf("%s: status = %d, want the refresh attempted", userID, w.Code)
+		}
+	}
+}
+
+// TestAuthorizeAdmin tests user group lookups, their caching and failures
+func TestAuthorizeAdmin(t *testing.T) {
+	slackAPI := &fakeSlack{usergroups: map[string][]string{"S-ops": {"U-oncall"}}}
+	clock := &mutableClock{now: time.Now()}
+	handler := newAdminTestHandler(slackAPI, clock)
+	ctx := context.Background()
+
+	if !handler.authorizeAdmin(ctx, "T1", "U-oncall", "refresh-cache") {
+		t.Error("user group member denied")
+	}
+
+	// Members are cached until the TTL passes
+	slackAPI.usergroups["S-ops"] = nil
+	if !handler.authorizeAdmin(ctx, "T1", "U-oncall", "refresh-cache") {
+		t.Error("cached user group member denied")
+	}
+	clock.now = clock.now.Add(10 * time.Minute)
+	if handler.authorizeAdmin(ctx, "T1", "U-oncall", "refresh-cache") {
+		t.Error("removed user group member still allowed after the TTL")
+	}
+
+	// Lookup failures deny everyone but the listed users
+	delete(slackAPI.usergroups, "S-ops")
+	if handler.authorizeAdmin(ctx, "T2", "U-oncall", "refresh-cache") {
+		t.Error("allowed although the user group lookup failed")
+	}
+	if !handler.authorizeAdmin(ctx, "T2", "U-admin", "refresh-cache") {
+		t.Error("listed admin denied")
+	}
+
+	// Without admins configured, everyone may run admin subcommands
+	open := newInteractiveTestHandler(&fakeBackend{}, &fakeSlack{})
+	if !open.authorizeAdmin(ctx, "T1", "U-someone", "refresh-cache") {
+		t.Error("denied although no admins are configured")
+	}
+}
+
+// mutableClock is a Clock tests can move forward
+type mutableClock struct{ now time.Time }
+
+func (c *mutableClock) Now() time.Time { return c.now }
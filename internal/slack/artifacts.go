@@ -0,0 +1,101 @@
+package slack
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// artifactTitleTimeout bounds the Slack API calls that expand artifact titles,
+// which run before the modal submission is acknowledged.
+const artifactTitleTimeout = 1500 * time.Millisecond
+
+// artifactHuddleTitle is the link text of huddle artifacts, which have no title.
+const artifactHuddleTitle = "Slack huddle"
+
+// buildArtifactsBlock creates the optional "Artifacts" field, where users paste
+// Slack canvas and huddle links. It is only added to the modal when the ideas
+// database has a constants.FieldArtifacts property (see ModalFieldsFromSchema).
+func buildArtifactsBlock() *slack.InputBlock {
+	block := createTextInputBlock(
+		BlockIDArtifacts,
+		ActionIDArtifactsInput,
+		LabelArtifacts,
+		PlaceholderArtifacts,
+		false,
+		true,
+	)
+	block.Hint = newPlainText(HintArtifacts)
+	return block
+}
+
+// parseArtifactLinks splits the Artifacts input on whitespace and parses each
+// link. It returns the first link that isn't a Slack canvas or huddle link.
+//
+// Accepted links:
+//   - Canvases: https://<workspace>.slack.com/docs/<team ID>/<file ID>
+//   - Huddles: https://app.slack.com/huddle/<team ID>/<channel ID>
+func parseArtifactLinks(input string) (artifacts []submission.Artifact, invalid string) {
+	for _, link := range strings.Fields(input) {
+		link = strings.Trim(link, "<>,")
+		if _, _, ok := parseArtifactLink(link); !ok {
+			return nil, link
+		}
+		artifacts = append(artifacts, submission.Artifact{URL: link})
+	}
+	return artifacts, ""
+}
+
+// parseArtifactLink reports whether link is a Slack canvas or huddle link, and
+// returns its kind ("canvas" or "huddle") and the canvas file or huddle channel ID.
+func parseArtifactLink(link string) (kind, id string, ok bool) {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "https" || (u.Host != "slack.com" && !strings.HasSuffix(u.Host, ".slack.com")) {
+		return "", "", false
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) != 3 || segments[1] == "" || segments[2] == "" {
+		return "", "", false
+	}
+	switch segments[0] {
+	case "docs":
+		return "canvas", segments[2], true
+	case "huddle":
+		return "huddle", segments[2], true
+	}
+	return "", "", false
+}
+
+// expandArtifactTitles sets the title of each artifact: canvas titles come
+// from files.info, huddles get a fixed label. Lookups that fail or run out of
+// time leave the title empty, so the link shows its URL instead.
+func (h *Handler) expandArtifactTitles(teamID string, artifacts []submission.Artifact) {
+	if len(artifacts) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), artifactTitleTimeout)
+	defer cancel()
+
+	for i, artifact := range artifacts {
+		kind, id, _ := parseArtifactLink(artifact.URL)
+		if kind == "huddle" {
+			artifacts[i].Title = artifactHuddleTitle
+			continue
+		}
+
+		file, _, _, err := h.slackFor(teamID).GetFileInfoContext(ctx, id, 0, 0)
+		if err != nil {
+			h.recordSlackAPIError("files.info", err)
+			h.logger.Debug("failed to expand canvas title", zap.String("file_id", id), zap.Error(err))
+			continue
+		}
+		artifacts[i].Title = strings.TrimSpace(file.Title)
+	}
+}
@@ -0,0 +1,168 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+)
+
+const (
+	testCanvasLink = "https://acme.slack.com/docs/T456/F0CANVAS1"
+	testHuddleLink = "https://app.slack.com/huddle/T456/C0HUDDLE1"
+)
+
+// TestParseArtifactLinks tests which links are accepted as artifacts
+func TestParseArtifactLinks(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantURLs    []string
+		wantInvalid string
+	}{
+		{name: "empty", input: "  "},
+		{name: "canvas and huddle", input: testCanvasLink + "\n<" + testHuddleLink + ">,", wantURLs: []string{testCanvasLink, testHuddleLink}},
+		{name: "other host", input: "https://slack.com.evil.io/docs/T456/F1", wantInvalid: "https://slack.com.evil.io/docs/T456/F1"},
+		{name: "http", input: "http://acme.slack.com/docs/T456/F1", wantInvalid: "http://acme.slack.com/docs/T456/F1"},
+		{name: "message link", input: testCanvasLink + " https://acme.slack.com/archives/C1/p123", wantInvalid: "https://acme.slack.com/archives/C1/p123"},
+		{name: "missing file", input: "https://acme.slack.com/docs/T456", wantInvalid: "https://acme.slack.com/docs/T456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifacts, invalid := parseArtifactLinks(tt.input)
+			if invalid != tt.wantInvalid {
+				t.Errorf("invalid = %q, want %q", invalid, tt.wantInvalid)
+			}
+			var urls []string
+			for _, artifact := range artifacts {
+				urls = append(urls, artifact.URL)
+			}
+			if !reflect.DeepEqual(urls, tt.wantURLs) {
+				t.Errorf("URLs = %v, want %v", urls, tt.wantURLs)
+			}
+		})
+	}
+}
+
+// TestExpandArtifactTitles tests that canvas titles are looked up and huddles get a fixed label
+func TestExpandArtifactTitles(t *testing.T) {
+	slackAPI := &fakeSlack{files: map[string]*slack.File{"F0CANVAS1": {ID: "F0CANVAS1", Title: " Q3 export plan "}}}
+	handler := newInteractiveTestHandler(&fakeBackend{}, slackAPI)
+
+	artifacts := []submission.Artifact{
+		{URL: testCanvasLink},
+		{URL: testHuddleLink},
+		{URL: "https://acme.slack.com/docs/T456/F0DELETED"},
+	}
+	handler.expandArtifactTitles("T456", artifacts)
+
+	want := []submission.Artifact{
+		{URL: testCanvasLink, Title: "Q3 export plan"},
+		{URL: testHuddleLink, Title: artifactHuddleTitle},
+		{URL: "https://acme.slack.com/docs/T456/F0DELETED"},
+	}
+	if !reflect.DeepEqual(artifacts, want) {
+		t.Errorf("artifacts = %+v, want %+v", artifacts, want)
+	}
+}
+
+// TestModalFieldsFromSchema_Artifacts tests that the Artifacts field is only offered when the database has it
+func TestModalFieldsFromSchema_Artifacts(t *testing.T) {
+	hasArtifacts := func(schema []notion.FormProperty) bool {
+		for _, field := range ModalFieldsFromSchema(schema) {
+			if field.Property == constants.FieldArtifacts {
+				return field.BlockID == BlockIDArtifacts && field.IsCore()
+			}
+		}
+		return false
+	}
+
+	if hasArtifacts(testFormSchema) {
+		t.Error("Artifacts field offered without an Artifacts property")
+	}
+	if !hasArtifacts(append(testFormSchema, notion.FormProperty{Name: constants.FieldArtifacts, Type: "rich_text"})) {
+		t.Error("Artifacts field not offered for an Artifacts rich_text property")
+	}
+	if hasArtifacts(append(testFormSchema, notion.FormProperty{Name: constants.FieldArtifacts, Type: "url"})) {
+		t.Error("Artifacts field offered for a non rich_text Artifacts property")
+	}
+}
+
+// TestHandleInteractive_Artifacts tests that artifact links are validated and submitted with their titles
+func TestHandleInteractive_Artifacts(t *testing.T) {
+	newValues := func(links string) map[string]map[string]StateValue {
+		title := "Exports are slow"
+		return map[string]map[string]StateValue{
+			BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+			BlockIDTheme:       {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "New Feature Idea"}}},
+			BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+			BlockIDComments:    {ActionIDCommentsInput: {Type: "plain_text_input"}},
+			BlockIDCustomerOrg: {ActionIDCustomerOrgSelect: {Type: "multi_external_select"}},
+			BlockIDArtifacts:   {ActionIDArtifactsInput: {Type: "plain_text_input", Value: &links}},
+		}
+	}
+	newHandler := func() (*Handler, *fakeBackend) {
+		backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(nil, map[string]string{"alice@example.com": "notion-alice"})}
+		slackAPI := &fakeSlack{
+			users:  map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+			posted: make(chan string, 1),
+			files:  map[string]*slack.File{"F0CANVAS1": {ID: "F0CANVAS1", Title: "Q3 export plan"}},
+		}
+		return newInteractiveTestHandler(backend, slackAPI), backend
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		handler, backend := newHandler()
+		w := httptest.NewRecorder()
+		handler.HandleInteractive(w, submissionRequest(t, newValues(testCanvasLink+"\n"+testHuddleLink)))
+
+		if w.Code != http.StatusOK || w.Body.String() != "{}" {
+			t.Fatalf("response = %d %q, want 200 {}", w.Code, w.Body.String())
+		}
+		if len(backend.submissions) != 1 {
+			t.Fatalf("expected 1 submission, got %d", len(backend.submissions))
+		}
+		want := []submission.Artifact{
+			{URL: testCanvasLink, Title: "Q3 export plan"},
+			{URL: testHuddleLink, Title: artifactHuddleTitle},
+		}
+		if got := backend.submissions[0].Artifacts; !reflect.DeepEqual(got, want) {
+			t.Errorf("Artifacts = %+v, want %+v", got, want)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		links     string
+		wantError string
+	}{
+		{name: "invalid link", links: "https://example.com/doc", wantError: "https://example.com/doc is not a Slack canvas or huddle link"},
+		{name: "too many", links: strings.Repeat(testHuddleLink+" ", constants.MaxArtifacts+1), wantError: "Too many links"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, backend := newHandler()
+			w := httptest.NewRecorder()
+			handler.HandleInteractive(w, submissionRequest(t, newValues(tt.links)))
+
+			var response ViewSubmissionResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !strings.Contains(response.Errors[BlockIDArtifacts], tt.wantError) {
+				t.Errorf("errors = %v, want %q on the artifacts block", response.Errors, tt.wantError)
+			}
+			if len(backend.submissions) != 0 {
+				t.Errorf("expected no submissions, got %d", len(backend.submissions))
+			}
+		})
+	}
+}
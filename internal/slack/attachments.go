@@ -0,0 +1,137 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// SetAttachmentsEnabled adds the optional "Attachments" file input to the
+// submission modal. Attached files are copied to the body of the created page,
+// which needs the Slack files:read scope and Notion's "Insert content" capability.
+func (h *Handler) SetAttachmentsEnabled(enabled bool) {
+	h.attachments = enabled
+}
+
+// buildAttachmentsBlock creates the optional "Attachments" file input, limited
+// to constants.AttachmentFileTypes and constants.MaxAttachments files.
+func buildAttachmentsBlock() *slack.InputBlock {
+	element := slack.NewFileInputBlockElement(ActionIDAttachmentsInput).
+		WithFileTypes(constants.AttachmentFileTypes...).
+		WithMaxFiles(constants.MaxAttachments)
+
+	block := slack.NewInputBlock(
+		BlockIDAttachments,
+		newPlainText(LabelAttachments),
+		newPlainText(fmt.Sprintf(HintAttachments, constants.MaxAttachments, constants.MaxAttachmentBytes>>20)),
+		element,
+	)
+	block.Optional = true
+	return block
+}
+
+// validateAttachments returns the files uploaded in the Attachments input, or
+// the error to show on it if there are too many, one is too large or its type
+// isn't accepted. Slack enforces the count and types in the modal too, but not
+// the size.
+func (h *Handler) validateAttachments(state ViewState) ([]submission.Attachment, string) {
+	stateValue, found := state.Values[BlockIDAttachments][ActionIDAttachmentsInput]
+	if !found || len(stateValue.Files) == 0 {
+		return nil, ""
+	}
+	if len(stateValue.Files) > constants.MaxAttachments {
+		return nil, h.messages.Format(messages.KeyTooManyAttachments, messages.Params{
+			"max": constants.MaxAttachments, "count": len(stateValue.Files),
+		})
+	}
+
+	attachments := make([]submission.Attachment, 0, len(stateValue.Files))
+	for _, file := range stateValue.Files {
+		extension := strings.ToLower(strings.TrimPrefix(path.Ext(file.Name), "."))
+		if !slices.Contains(constants.AttachmentFileTypes, extension) {
+			return nil, h.messages.Format(messages.KeyAttachmentType, messages.Params{
+				"name": file.Name, "types": strings.Join(constants.AttachmentFileTypes, ", "),
+			})
+		}
+		if file.Size > constants.MaxAttachmentBytes {
+			return nil, h.messages.Format(messages.KeyAttachmentTooLarge, messages.Params{
+				"name": file.Name, "max": constants.MaxAttachmentBytes >> 20,
+			})
+		}
+		attachments = append(attachments, submission.Attachment{
+			SlackFileID: file.ID,
+			Name:        file.Name,
+			MimeType:    file.Mimetype,
+			Size:        file.Size,
+		})
+	}
+	return attachments, ""
+}
+
+// attachFiles copies a submission's attachments from Slack to the body of its
+// created page. Files that can't be downloaded or uploaded are linked by their
+// Slack permalink instead. Failures are only logged: the page already exists.
+func (h *Handler) attachFiles(ctx context.Context, sub submission.Submission, page *notion.CreatedPage) {
+	teamID := sub.Source.SlackTeamID
+	for _, attachment := range sub.Attachments {
+		logger := h.logger.With(zap.String("page_id", page.ID), zap.String("slack_file_id", attachment.SlackFileID))
+
+		info, _, _, err := h.slackFor(teamID).GetFileInfoContext(ctx, attachment.SlackFileID, 0, 0)
+		if err != nil {
+			h.recordSlackAPIError("files.info", err)
+			h.recordAttachment("failed")
+			logger.Error("failed to look up attached file", zap.Error(err))
+			continue
+		}
+
+		file := notion.FileAttachment{Name: attachment.Name, ContentType: attachment.MimeType, ExternalURL: info.Permalink}
+		if content, err := h.downloadFile(ctx, teamID, info); err != nil {
+			logger.Warn("failed to download attached file, linking it instead", zap.Error(err))
+		} else {
+			file.Content = content
+		}
+
+		err = h.backendFor(teamID).AttachFile(page.ID, file)
+		if err != nil && file.Content != nil && file.ExternalURL != "" {
+			logger.Warn("failed to upload attached file to Notion, linking it instead", zap.Error(err))
+			file.Content = nil
+			err = h.backendFor(teamID).AttachFile(page.ID, file)
+		}
+		switch {
+		case err != nil:
+			h.recordAttachment("failed")
+			logger.Error("failed to attach file to Notion page", zap.Error(err))
+		case file.Content != nil:
+			h.recordAttachment("uploaded")
+		default:
+			h.recordAttachment("linked")
+		}
+	}
+}
+
+// downloadFile downloads a Slack file, refusing files over constants.MaxAttachmentBytes.
+func (h *Handler) downloadFile(ctx context.Context, teamID string, file *slack.File) ([]byte, error) {
+	if file.Size > constants.MaxAttachmentBytes {
+		return nil, fmt.Errorf("file is %d bytes (max: %d)", file.Size, constants.MaxAttachmentBytes)
+	}
+	if file.URLPrivateDownload == "" {
+		return nil, fmt.Errorf("file has no download URL")
+	}
+
+	var content bytes.Buffer
+	if err := h.slackFor(teamID).GetFileContext(ctx, file.URLPrivateDownload, &content); err != nil {
+		h.recordSlackAPIError("files.download", err)
+		return nil, err
+	}
+	return content.Bytes(), nil
+}
@@ -0,0 +1,122 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+)
+
+func TestValidateAttachments(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []StateFile
+		want    int
+		wantErr string
+	}{
+		{name: "none"},
+		{
+			name:  "screenshot and document",
+			files: []StateFile{{ID: "F1", Name: "Screenshot.PNG", Mimetype: "image/png", Size: 2048}, {ID: "F2", Name: "spec.pdf", Size: 4096}},
+			want:  2,
+		},
+		{
+			name:    "too large",
+			files:   []StateFile{{ID: "F1", Name: "recording.png", Size: constants.MaxAttachmentBytes + 1}},
+			wantErr: "recording.png is larger than 10 MB",
+		},
+		{
+			name:    "type not accepted",
+			files:   []StateFile{{ID: "F1", Name: "setup.exe", Size: 10}},
+			wantErr: "setup.exe can't be attached",
+		},
+		{
+			name:    "too many",
+			files:   make([]StateFile, constants.MaxAttachments+1),
+			wantErr: "Too many files",
+		},
+	}
+
+	handler := newInteractiveTestHandler(&fakeBackend{}, &fakeSlack{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := ViewState{Values: map[string]map[string]StateValue{
+				BlockIDAttachments: {ActionIDAttachmentsInput: {Type: "file_input", Files: tt.files}},
+			}}
+			attachments, message := handler.validateAttachments(state)
+			if tt.wantErr != "" {
+				if !strings.Contains(message, tt.wantErr) {
+					t.Errorf("message = %q, want %q", message, tt.wantErr)
+				}
+				return
+			}
+			if message != "" || len(attachments) != tt.want {
+				t.Errorf("validateAttachments() = %+v, %q; want %d attachments", attachments, message, tt.want)
+			}
+		})
+	}
+}
+
+// TestAttachFiles tests that files are uploaded, linked when the upload fails, and skipped when they can't be found
+func TestAttachFiles(t *testing.T) {
+	slackAPI := &fakeSlack{
+		files: map[string]*slack.File{
+			"F1": {ID: "F1", Size: 3, URLPrivateDownload: "https://files.slack.com/F1", Permalink: "https://acme.slack.com/files/F1"},
+			"F2": {ID: "F2", Size: 3, URLPrivateDownload: "https://files.slack.com/F2", Permalink: "https://acme.slack.com/files/F2"},
+		},
+		downloads: map[string]string{"https://files.slack.com/F1": "png"},
+	}
+	backend := &fakeBackend{}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+
+	sub := submission.Submission{Attachments: []submission.Attachment{
+		{SlackFileID: "F1", Name: "screenshot.png", MimeType: "image/png"},
+		{SlackFileID: "F2", Name: "spec.pdf", MimeType: "application/pdf"}, // Download fails
+		{SlackFileID: "F3", Name: "gone.txt"},                              // Deleted from Slack
+	}}
+	handler.attachFiles(context.Background(), sub, &notion.CreatedPage{ID: "page-1"})
+
+	if len(backend.attached) != 2 {
+		t.Fatalf("attached %d files, want 2", len(backend.attached))
+	}
+	if uploaded := backend.attached[0]; string(uploaded.Content) != "png" || uploaded.ContentType != "image/png" {
+		t.Errorf("first file = %+v, want it uploaded", uploaded)
+	}
+	if linked := backend.attached[1]; linked.Content != nil || linked.ExternalURL != "https://acme.slack.com/files/F2" {
+		t.Errorf("second file = %+v, want it linked", linked)
+	}
+
+	// Uploads rejected by Notion fall back to the link
+	backend.attached = nil
+	backend.attachErr = errors.New("restricted_resource")
+	handler.attachFiles(context.Background(), submission.Submission{Attachments: sub.Attachments[:1]}, &notion.CreatedPage{ID: "page-1"})
+	if len(backend.attached) != 1 || backend.attached[0].Content != nil || backend.attached[0].ExternalURL == "" {
+		t.Errorf("attached = %+v, want the file linked after the upload failed", backend.attached)
+	}
+}
+
+// TestSubmissionModal_Attachments tests that the file input is only shown when enabled
+func TestSubmissionModal_Attachments(t *testing.T) {
+	handler := newInteractiveTestHandler(&fakeBackend{}, &fakeSlack{})
+	hasAttachments := func() bool {
+		for _, block := range handler.submissionModal("T456").Blocks.BlockSet {
+			if input, ok := block.(*slack.InputBlock); ok && input.BlockID == BlockIDAttachments {
+				return input.Optional
+			}
+		}
+		return false
+	}
+
+	if hasAttachments() {
+		t.Error("file input shown without SetAttachmentsEnabled")
+	}
+	handler.SetAttachmentsEnabled(true)
+	if !hasAttachments() {
+		t.Error("optional file input missing with attachments enabled")
+	}
+}
@@ -0,0 +1,131 @@
+package slack
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/store"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// SetAuditLog records every attempt to create or edit an idea in log
+// (AUDIT_LOG_BACKEND). /hopperbot list and stats then read from it, and the
+// same user submitting an idea they just created is rejected as a duplicate.
+func (h *Handler) SetAuditLog(log store.Store) {
+	h.auditLog = log
+}
+
+// recordAttempt records a request to the backend to create or edit an idea.
+// page is the created or edited page, or nil when the request failed before
+// one was known. Failures to record are only logged.
+func (h *Handler) recordAttempt(action string, sub submission.Submission, page *notion.CreatedPage, err error, latency time.Duration) {
+	if h.auditLog == nil {
+		return
+	}
+
+	attempt := store.Attempt{
+		Time:       h.clock.Now().UTC(),
+		TeamID:     sub.Source.SlackTeamID,
+		UserID:     sub.Source.SlackUserID,
+		Channel:    sub.Source.ChannelName(),
+		Action:     action,
+		Title:      sub.Title,
+		FieldsHash: store.FieldsHash(sub),
+		Outcome:    store.OutcomeSuccess,
+		Latency:    latency,
+	}
+	if page != nil {
+		attempt.PageID, attempt.PageURL = page.ID, page.URL
+	}
+	if err != nil {
+		attempt.Outcome, attempt.Error = store.OutcomeError, err.Error()
+	}
+	if err := h.auditLog.Record(attempt); err != nil {
+		h.logger.Warn("failed to record submission attempt",
+			zap.String("action", action),
+			zap.String("page_id", attempt.PageID),
+			zap.Error(err),
+		)
+	}
+}
+
+// duplicateMessage returns the message rejecting a submission whose fields
+// match an idea the same user created within constants.DuplicateSubmissionWindow,
+// or "" to let it through. Without an audit log nothing is a duplicate.
+func (h *Handler) duplicateMessage(sub submission.Submission) string {
+	if h.auditLog == nil {
+		return ""
+	}
+
+	since := h.clock.Now().Add(-constants.DuplicateSubmissionWindow)
+	previous, found, err := h.auditLog.Duplicate(sub.Source.SlackTeamID, sub.Source.SlackUserID, store.FieldsHash(sub), since)
+	if err != nil {
+		h.logger.Warn("failed to check the audit log for a duplicate submission", zap.Error(err))
+		return ""
+	}
+	if !found {
+		return ""
+	}
+	return h.messages.Format(messages.KeyDuplicateSubmission, messages.Params{
+		"submitted": h.FormatTimeForUser(sub.Source.SlackUserID, previous.Time),
+		"url":       previous.PageURL,
+	})
+}
+
+// auditIdeaLines lists the user's most recent submissions from the audit log.
+// It returns false when there is no audit log, it can't be read, or it has no
+// ideas from the user (who may have submitted before it was enabled), so the
+// caller asks the backend instead.
+func (h *Handler) auditIdeaLines(teamID, userID string) ([]string, bool) {
+	if h.auditLog == nil {
+		return nil, false
+	}
+
+	ideas, total, err := h.auditLog.Ideas(teamID, userID, homeIdeasLimit)
+	if err != nil {
+		h.logger.Warn("failed to list ideas from the audit log", zap.String("user_id", userID), zap.Error(err))
+		return nil, false
+	}
+	if total == 0 {
+		return nil, false
+	}
+
+	lines := []string{h.messages.Format(messages.KeyHomeIdeas, messages.Params{
+		"count": strconv.Itoa(total),
+		"shown": len(ideas),
+	})}
+	for _, idea := range ideas {
+		lines = append(lines, h.messages.Format(messages.KeyListIdeaLine, messages.Params{
+			"title":     idea.Title,
+			"url":       idea.PageURL,
+			"submitted": h.FormatTimeForUser(userID, idea.CreatedAt),
+		}))
+	}
+	return lines, true
+}
+
+// auditStatsLine summarizes the team's recent attempts for /hopperbot stats,
+// or returns "" without an audit log.
+func (h *Handler) auditStatsLine(teamID string) string {
+	if h.auditLog == nil {
+		return ""
+	}
+
+	stats, err := h.auditLog.Stats(teamID, h.clock.Now().Add(-constants.AuditStatsPeriod))
+	if err != nil {
+		h.logger.Warn("failed to summarize the audit log", zap.Error(err))
+		return ""
+	}
+	return h.messages.Format(messages.KeyAuditStats, messages.Params{
+		"days":       int(constants.AuditStatsPeriod / (24 * time.Hour)),
+		"created":    stats.Created,
+		"edited":     stats.Edited,
+		"submitters": stats.Submitters,
+		"failed":     stats.Failed,
+		"latency":    stats.AvgLatency.Round(time.Millisecond).String(),
+	})
+}
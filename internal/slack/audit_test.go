@@ -0,0 +1,80 @@
+package slack
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/store"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// TestAuditLog tests that submission attempts are recorded, that the same
+// idea submitted again right away is rejected, and that list and stats read
+// from the log
+func TestAuditLog(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(
+		map[string]string{"Acme Corp": "customer-page-acme"},
+		map[string]string{"alice@example.com": "notion-user-alice"},
+	)}
+	slackAPI := &fakeSlack{
+		users:     map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+		posted:    make(chan string, 2),
+		responded: make(chan string, 2),
+	}
+	handler := NewHandlerWithDependencies(&config.Config{
+		SlackSigningSecret: "secret",
+		SlackAdminUserIDs:  []string{"U123"},
+	}, zap.NewNop(), Dependencies{Backend: backend, Slack: slackAPI, Responder: slackAPI, Clock: fixedClock(time.Now())})
+	auditLog, _ := store.NewFileStore("")
+	handler.SetAuditLog(auditLog)
+
+	// A failed attempt is recorded and doesn't count as a duplicate
+	backend.submitErr = errors.New("validation_error")
+	handler.HandleSlashCommand(httptest.NewRecorder(), quickSubmitRequest(`"Exports are slow" theme:"customer pain point" area:ai/ml customer:"acme corp"`))
+	<-slackAPI.responded
+	backend.submitErr = nil
+	handler.HandleSlashCommand(httptest.NewRecorder(), quickSubmitRequest(`"Exports are slow" theme:"customer pain point" area:ai/ml customer:"acme corp"`))
+	<-slackAPI.responded
+
+	stats, _ := auditLog.Stats("T456", time.Now().Add(-time.Hour))
+	if stats.Attempts != 2 || stats.Failed != 1 || stats.Created != 1 {
+		t.Fatalf("recorded stats = %+v, want a failed and a successful attempt", stats)
+	}
+
+	// Submitting the same idea again is rejected, however it's typed
+	w := httptest.NewRecorder()
+	handler.HandleSlashCommand(w, quickSubmitRequest(`"exports are  SLOW" theme:"customer pain point" area:ai/ml customer:"acme corp"`))
+	if !strings.Contains(w.Body.String(), "You already submitted this idea") || !strings.Contains(w.Body.String(), "https://www.notion.so/page-1") {
+		t.Errorf("response = %s, want the duplicate rejected", w.Body.String())
+	}
+	backend.mu.Lock()
+	submitted := len(backend.submissions)
+	backend.mu.Unlock()
+	if submitted != 2 {
+		t.Errorf("backend submissions = %d, want the duplicate not submitted", submitted)
+	}
+
+	run := func(text string) string {
+		body := url.Values{"command": {"/hopperbot"}, "text": {text}, "team_id": {"T456"}, "user_id": {"U123"}}.Encode()
+		w := httptest.NewRecorder()
+		handler.HandleSlashCommand(w, createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+		return w.Body.String()
+	}
+
+	// The list comes from the log, without querying the backend
+	backend.ideasErr = errors.New("Notion should not be queried")
+	if list := run("list"); !strings.Contains(list, "You have submitted *1* ideas") || !strings.Contains(list, "https://www.notion.so/page-1|Exports are slow") {
+		t.Errorf("list = %s, want the idea from the audit log", list)
+	}
+	if stats := run("stats"); !strings.Contains(stats, "Last 7 days: 1 ideas created and 0 edited by 1 people, 1 failed attempts") {
+		t.Errorf("stats = %s, want the audit summary", stats)
+	}
+}
@@ -0,0 +1,121 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/drafts"
+	"github.com/rudderlabs/hopperbot/pkg/featureflags"
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// autosaveDraftID is the ID of a user's autosaved draft. Each user has at most
+// one, replaced every time they close the submission modal.
+func autosaveDraftID(teamID, userID string) string {
+	return "autosave:" + teamID + ":" + userID
+}
+
+// autosaveEnabled reports whether closed submission modals are saved as drafts.
+func (h *Handler) autosaveEnabled() bool {
+	return h.drafts != nil && h.flags.Enabled(featureflags.Autosave)
+}
+
+// newSubmissionModal builds the modal opened by /hopperbot and the App Home
+// button, restoring the user's autosaved draft if they have one.
+func (h *Handler) newSubmissionModal(teamID, userID string) slack.ModalViewRequest {
+	if !h.autosaveEnabled() {
+		return h.openingModal(teamID, "", ModalValues{})
+	}
+
+	draft, found, err := h.drafts.Get(autosaveDraftID(teamID, userID), h.clock.Now())
+	if err != nil {
+		h.logger.Warn("failed to drop expired autosaved draft", zap.String("user_id", userID), zap.Error(err))
+	}
+	if !found {
+		return h.openingModal(teamID, "", ModalValues{})
+	}
+
+	modal := h.openingModal(teamID, draft.ID, ModalValues{
+		Title:       draft.Title,
+		Theme:       draft.Theme,
+		ProductArea: draft.ProductArea,
+		Comments:    draft.Comments,
+		Customers:   draft.CustomerOrgs,
+	})
+	notice := h.messages.Format(messages.KeyAutosaveRestored, messages.Params{
+		"saved": h.FormatTimeForUser(userID, draft.CreatedAt),
+	})
+	restored := []slack.Block{
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, notice, false, false)),
+		slack.NewActionBlock(BlockIDAutosaveActions,
+			slack.NewButtonBlockElement(ActionIDDiscardDraft, draft.ID, newPlainText(ButtonDiscardDraft)),
+		),
+	}
+	// After the info block, so the notice is the first thing the user reads
+	modal.Blocks.BlockSet = append(modal.Blocks.BlockSet[:1], append(restored, modal.Blocks.BlockSet[1:]...)...)
+	return modal
+}
+
+// handleViewClosed autosaves what the user entered in a submission modal they
+// closed without submitting. Closing an empty modal discards the saved draft.
+func (h *Handler) handleViewClosed(w http.ResponseWriter, payload *InteractionPayload) {
+	w.WriteHeader(http.StatusOK)
+	if !h.autosaveEnabled() || payload.View.CallbackID != ModalCallbackIDSubmitForm {
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "ignored")
+		return
+	}
+
+	teamID, userID := payload.Team.ID, payload.User.ID
+	id := autosaveDraftID(teamID, userID)
+	draft := draftFromState(payload.View.State)
+	if isEmptyDraft(draft) {
+		if err := h.drafts.Delete(id); err != nil {
+			h.logger.Warn("failed to delete autosaved draft", zap.String("user_id", userID), zap.Error(err))
+		}
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "empty")
+		return
+	}
+
+	now := h.clock.Now()
+	draft.ID = id
+	draft.SlackTeamID = teamID
+	draft.AuthorID = userID
+	draft.RecipientID = userID
+	draft.CreatedAt = now
+	draft.ExpiresAt = now.Add(constants.AutosaveDraftTTL)
+	if err := h.drafts.Save(draft); err != nil {
+		h.logger.Error("failed to autosave draft", zap.String("user_id", userID), zap.Error(err))
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "error")
+		return
+	}
+	h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "autosaved")
+}
+
+// discardAutosavedDraft deletes the user's autosaved draft and replaces the
+// restored modal with an empty one.
+func (h *Handler) discardAutosavedDraft(ctx context.Context, payload *InteractionPayload) {
+	teamID, userID := payload.Team.ID, payload.User.ID
+	logger := logging.FromContext(ctx, h.logger)
+	if err := h.drafts.Delete(autosaveDraftID(teamID, userID)); err != nil {
+		logger.Warn("failed to delete autosaved draft", zap.String("user_id", userID), zap.Error(err))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, constants.DefaultHTTPTimeout)
+	defer cancel()
+	if _, err := h.slackFor(teamID).UpdateViewContext(ctx, h.openingModal(teamID, "", ModalValues{}), "", payload.View.Hash, payload.View.ID); err != nil {
+		h.recordSlackAPIError("views.update", err)
+		logger.Error("failed to clear discarded draft from the modal", zap.String("user_id", userID), zap.Error(err))
+		h.recordSlackInteraction(payload.Type, ActionIDDiscardDraft, "error")
+		return
+	}
+	h.recordSlackInteraction(payload.Type, ActionIDDiscardDraft, "success")
+}
+
+// isEmptyDraft reports whether no field of a draft was filled in.
+func isEmptyDraft(draft drafts.Draft) bool {
+	return draft.Title == "" && draft.Theme == "" && draft.ProductArea == "" && draft.Comments == "" && len(draft.CustomerOrgs) == 0
+}
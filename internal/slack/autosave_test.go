@@ -0,0 +1,127 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/featureflags"
+	"github.com/slack-go/slack"
+)
+
+func viewClosedRequest(t *testing.T, values map[string]map[string]StateValue) *http.Request {
+	t.Helper()
+	payload, err := json.Marshal(InteractionPayload{
+		Type: InteractionTypeViewClosed,
+		User: User{ID: "U123"},
+		Team: Team{ID: "T456"},
+		View: View{CallbackID: ModalCallbackIDSubmitForm, State: ViewState{Values: values}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	return createValidSlackRequest(http.MethodPost, "/slack/interactive", []byte(body), "secret")
+}
+
+func openModal(t *testing.T, handler *Handler, slackAPI *fakeSlack) slack.ModalViewRequest {
+	t.Helper()
+	body := url.Values{"command": {"/hopperbot"}, "team_id": {"T456"}, "user_id": {"U123"}, "trigger_id": {"trigger"}}.Encode()
+	handler.HandleSlashCommand(httptest.NewRecorder(), createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+	select {
+	case modal := <-slackAPI.opened:
+		return modal
+	default:
+		t.Fatal("modal was not opened")
+		return slack.ModalViewRequest{}
+	}
+}
+
+// TestAutosave tests that closing the modal saves its values and the next /hopperbot restores them
+func TestAutosave(t *testing.T) {
+	slackAPI := &fakeSlack{opened: make(chan slack.ModalViewRequest, 1)}
+	handler, store := newDraftTestHandler(t, slackAPI)
+
+	if modal := openModal(t, handler, slackAPI); !modal.NotifyOnClose {
+		t.Error("modal doesn't ask Slack for view_closed")
+	}
+
+	title := "Exports are slow"
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, viewClosedRequest(t, map[string]map[string]StateValue{
+		BlockIDTitle: {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+		BlockIDTheme: {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "Feature Improvement"}}},
+	}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	draft, found, _ := store.Get(autosaveDraftID("T456", "U123"), time.Now())
+	if !found || draft.Title != title || !draft.CanOpen("T456", "U123") || draft.CanOpen("T456", "U789") {
+		t.Fatalf("autosaved draft = %+v, found = %v", draft, found)
+	}
+
+	modal := openModal(t, handler, slackAPI)
+	data, _ := json.Marshal(modal)
+	if !strings.Contains(string(data), title) || !strings.Contains(string(data), ActionIDDiscardDraft) {
+		t.Errorf("restored modal = %s, want the title and a Discard draft button", data)
+	}
+	if decodeModalMetadata(modal.PrivateMetadata).ID != draft.ID {
+		t.Errorf("PrivateMetadata = %q, want the autosaved draft's ID so submitting deletes it", modal.PrivateMetadata)
+	}
+
+	// Closing an empty modal discards the draft
+	handler.HandleInteractive(httptest.NewRecorder(), viewClosedRequest(t, nil))
+	if _, found, _ := store.Get(draft.ID, time.Now()); found {
+		t.Error("closing an empty modal kept the autosaved draft")
+	}
+}
+
+// TestAutosave_Discard tests that "Discard draft" deletes the draft and clears the modal
+func TestAutosave_Discard(t *testing.T) {
+	slackAPI := &fakeSlack{updated: make(chan slack.ModalViewRequest, 1)}
+	handler, store := newDraftTestHandler(t, slackAPI)
+	title := "Exports are slow"
+	handler.HandleInteractive(httptest.NewRecorder(), viewClosedRequest(t, map[string]map[string]StateValue{
+		BlockIDTitle: {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+	}))
+
+	handler.HandleInteractive(httptest.NewRecorder(), draftActionRequest(t, "U123",
+		View{ID: "V1", CallbackID: ModalCallbackIDSubmitForm},
+		Action{ActionID: ActionIDDiscardDraft, BlockID: BlockIDAutosaveActions},
+	))
+	select {
+	case modal := <-slackAPI.updated:
+		if data, _ := json.Marshal(modal); strings.Contains(string(data), title) {
+			t.Error("modal still shows the discarded draft")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("modal was not updated")
+	}
+	if _, found, _ := store.Get(autosaveDraftID("T456", "U123"), time.Now()); found {
+		t.Error("discarded draft was not deleted")
+	}
+}
+
+// TestAutosave_FlagOff tests that the autosave flag stops saving and restoring
+func TestAutosave_FlagOff(t *testing.T) {
+	slackAPI := &fakeSlack{opened: make(chan slack.ModalViewRequest, 1)}
+	handler, store := newDraftTestHandler(t, slackAPI)
+	flags := featureflags.New()
+	flags.Set(map[string]bool{featureflags.Autosave: false})
+	handler.SetFeatureFlags(flags)
+
+	title := "Exports are slow"
+	handler.HandleInteractive(httptest.NewRecorder(), viewClosedRequest(t, map[string]map[string]StateValue{
+		BlockIDTitle: {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+	}))
+	if store.Len() != 0 {
+		t.Error("draft autosaved with the autosave flag off")
+	}
+	if modal := openModal(t, handler, slackAPI); modal.NotifyOnClose {
+		t.Error("modal asks for view_closed with the autosave flag off")
+	}
+}
@@ -0,0 +1,286 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// Subcommand names
+const (
+	SubcommandSubmit       = "submit"
+	SubcommandEdit         = "edit"
+	SubcommandList         = "list"
+	SubcommandCustomer     = "customer"
+	SubcommandStats        = "stats"
+	SubcommandRefreshCache = "refresh-cache"
+	SubcommandResetQuota   = "reset-quota"
+	SubcommandHelp         = "help"
+)
+
+// newCommandRouter registers the /hopperbot subcommands in help order.
+// A new subcommand only needs an entry here: dispatch, argument checks, admin
+// checks, metrics and /hopperbot help all come from the router.
+func (h *Handler) newCommandRouter() *CommandRouter {
+	router := NewCommandRouter(SubcommandSubmit)
+	router.NotFound = h.handleUnknownSubcommand
+	router.BadUsage = h.handleSubcommandUsage
+	router.Use(h.instrumentSubcommand, h.requireAdmin)
+
+	router.Register(Subcommand{
+		Name:        SubcommandSubmit,
+		Usage:       `["<title>" theme:<theme> area:<product area> customer:<name> -- <comments>]`,
+		Description: "Open the idea submission form (also plain /hopperbot), or submit directly from the text",
+		MaxArgs:     AnyArgs,
+		Handler: func(ctx context.Context, w http.ResponseWriter, cmd SlashCommand) {
+			if cmd.ArgText != "" {
+				h.handleQuickSubmit(ctx, w, cmd)
+				return
+			}
+			h.handleOpenModalCommand(ctx, w, cmd.TeamID, cmd.UserID, cmd.TriggerID, cmd.Command)
+		},
+	})
+	router.Register(Subcommand{
+		Name:        SubcommandEdit,
+		Description: "Edit one of your recent submissions",
+		Handler: func(_ context.Context, w http.ResponseWriter, cmd SlashCommand) {
+			h.handleEditCommand(w, cmd.TeamID, cmd.UserID, cmd.TriggerID, cmd.Command)
+		},
+	})
+	router.Register(Subcommand{
+		Name:        SubcommandList,
+		Aliases:     []string{"mine"},
+		Description: "List the ideas you submitted most recently",
+		Handler:     h.handleListCommand,
+	})
+	router.Register(Subcommand{
+		Name:        SubcommandCustomer,
+		Usage:       "<customer name>",
+		Description: "Show how many ideas are linked to a customer, and the most recent ones",
+		MinArgs:     1,
+		MaxArgs:     AnyArgs,
+		Handler: func(_ context.Context, w http.ResponseWriter, cmd SlashCommand) {
+			h.handleCustomerCommand(w, cmd.TeamID, cmd.UserID, cmd.Command, cmd.ArgText)
+		},
+	})
+	router.Register(Subcommand{
+		Name:        SubcommandStats,
+		Description: "Show what the bot has cached from Notion, and recent submission numbers",
+		Admin:       true,
+		Handler:     h.handleStatsCommand,
+	})
+	router.Register(Subcommand{
+		Name:        SubcommandRefreshCache,
+		Description: "Reload customers and users from Notion and report the outcome",
+		Admin:       true,
+		Handler: func(ctx context.Context, w http.ResponseWriter, cmd SlashCommand) {
+			h.handleRefreshCacheCommand(ctx, w, cmd.ResponseURL)
+		},
+	})
+	router.Register(Subcommand{
+		Name:        SubcommandResetQuota,
+		Usage:       "<@user>",
+		Description: "Let a user who reached the submission limits submit new ideas again",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Admin:       true,
+		Handler: func(_ context.Context, w http.ResponseWriter, cmd SlashCommand) {
+			h.handleResetQuotaCommand(w, cmd)
+		},
+	})
+	router.Register(Subcommand{
+		Name:        SubcommandHelp,
+		Description: "Show this overview",
+		Handler: func(_ context.Context, w http.ResponseWriter, cmd SlashCommand) {
+			h.handleHelpCommand(w, cmd)
+		},
+	})
+	return router
+}
+
+// instrumentSubcommand counts invocations of each subcommand.
+func (h *Handler) instrumentSubcommand(sub Subcommand, next CommandHandler) CommandHandler {
+	return func(ctx context.Context, w http.ResponseWriter, cmd SlashCommand) {
+		h.recordSubcommand(sub.Name)
+		next(ctx, w, cmd)
+	}
+}
+
+// requireAdmin refuses admin subcommands to users who aren't admins.
+func (h *Handler) requireAdmin(sub Subcommand, next CommandHandler) CommandHandler {
+	if !sub.Admin {
+		return next
+	}
+	return func(ctx context.Context, w http.ResponseWriter, cmd SlashCommand) {
+		if !h.authorizeAdmin(ctx, cmd.TeamID, cmd.UserID, sub.Name) {
+			h.recordSlackCommand(cmd.Command, "forbidden")
+			respondToSlack(w, h.messages.Format(messages.KeyAdminCommandDenied, messages.Params{"subcommand": sub.Name}))
+			return
+		}
+		next(ctx, w, cmd)
+	}
+}
+
+// handleUnknownSubcommand replies to an unknown subcommand, suggesting the
+// closest one when there is one.
+func (h *Handler) handleUnknownSubcommand(_ context.Context, w http.ResponseWriter, cmd SlashCommand, suggestion string) {
+	h.recordSubcommand("unknown")
+	h.recordSlackCommand(cmd.Command, "unknown")
+
+	key := messages.KeyUnknownSubcommand
+	if suggestion != "" {
+		key = messages.KeyUnknownSubcommandSuggestion
+	}
+	respondToSlack(w, h.messages.Format(key, messages.Params{
+		"command":    commandName(cmd),
+		"subcommand": cmd.Name,
+		"suggestion": suggestion,
+	}))
+}
+
+// handleSubcommandUsage replies with a subcommand's usage when it got too few
+// or too many arguments.
+func (h *Handler) handleSubcommandUsage(_ context.Context, w http.ResponseWriter, cmd SlashCommand, sub Subcommand) {
+	h.recordSlackCommand(cmd.Command, "error")
+	respondToSlack(w, h.messages.Format(messages.KeySubcommandUsage, messages.Params{
+		"usage": subcommandUsage(commandName(cmd), sub),
+	}))
+}
+
+// handleListCommand handles /hopperbot list, replying with the user's most
+// recent submissions, from the audit log when it has them.
+func (h *Handler) handleListCommand(_ context.Context, w http.ResponseWriter, cmd SlashCommand) {
+	lines, ok := h.auditIdeaLines(cmd.TeamID, cmd.UserID)
+	if !ok {
+		lines = h.submittedIdeaLines(cmd.TeamID, cmd.UserID, messages.KeyListNoIdeas, messages.KeyListLookupFailed)
+	}
+	h.recordSlackCommand(cmd.Command, "success")
+	respondToSlack(w, strings.Join(lines, "\n"))
+}
+
+// handleStatsCommand handles /hopperbot stats, replying with the team's cache
+// version, age and sizes, and a summary of recent submissions when the audit
+// log is enabled.
+func (h *Handler) handleStatsCommand(_ context.Context, w http.ResponseWriter, cmd SlashCommand) {
+	summary := h.cacheFor(cmd.TeamID).Snapshot().Summary()
+	text := h.messages.Format(messages.KeyStats, messages.Params{
+		"version":   summary.Version,
+		"built":     h.FormatTimeForUser(cmd.UserID, summary.BuiltAt),
+		"customers": summary.Customers,
+		"users":     summary.Users,
+		"guests":    summary.ExcludedGuests,
+	})
+	if line := h.auditStatsLine(cmd.TeamID); line != "" {
+		text += "\n" + line
+	}
+	h.recordSlackCommand(cmd.Command, "success")
+	respondToSlack(w, text)
+}
+
+// handleHelpCommand handles /hopperbot help, replying ephemerally with the
+// subcommands, the form's required fields and a link to the ideas board.
+func (h *Handler) handleHelpCommand(w http.ResponseWriter, cmd SlashCommand) {
+	// The text is the notification fallback; Slack shows the blocks
+	respondWithBlocks(w, h.messages.Format(messages.KeyHelpIntro, nil), h.buildHelpBlocks(cmd.TeamID, commandName(cmd)))
+	h.recordSlackCommand(cmd.Command, "success")
+}
+
+// buildHelpBlocks renders the help overview from the registered subcommands
+// and the team's current submission modal.
+func (h *Handler) buildHelpBlocks(teamID, command string) []slack.Block {
+	lines := []string{h.messages.Format(messages.KeyHelpIntro, nil)}
+	for _, sub := range h.commands.Subcommands() {
+		key := messages.KeyHelpCommandLine
+		if sub.Admin && h.admins.restricted() {
+			key = messages.KeyHelpAdminCommandLine
+		}
+		lines = append(lines, h.messages.Format(key, messages.Params{
+			"usage":       mrkdwnEscaper.Replace(subcommandUsage(command, sub)),
+			"description": sub.Description,
+		}))
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(newPlainText(HelpHeaderText)),
+		newMarkdownSection(strings.Join(lines, "\n")),
+	}
+
+	// Required fields come from the modal itself, so schema-generated fields and form rules are included
+	var required, conditional []string
+	for _, block := range h.submissionModal(teamID).Blocks.BlockSet {
+		input, ok := block.(*slack.InputBlock)
+		if !ok || input.Label == nil {
+			continue
+		}
+		if !input.Optional {
+			required = append(required, input.Label.Text)
+		} else if hint := ruleHint(h.currentFormRules(), input.BlockID); hint != "" {
+			conditional = append(conditional, h.messages.Format(messages.KeyHelpConditionalField, messages.Params{
+				"field": input.Label.Text,
+				"hint":  hint,
+			}))
+		}
+	}
+	fields := []string{h.messages.Format(messages.KeyHelpRequiredFields, messages.Params{"fields": strings.Join(required, ", ")})}
+	blocks = append(blocks, slack.NewDividerBlock(), newMarkdownSection(strings.Join(append(fields, conditional...), "\n")))
+
+	if url := h.notionBoardURL(teamID); url != "" {
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, h.messages.Format(messages.KeyHelpBoard, messages.Params{"url": url}), false, false),
+		))
+	}
+	return blocks
+}
+
+// commandName returns the slash command as invoked, defaulting to /hopperbot.
+func commandName(cmd SlashCommand) string {
+	if cmd.Command == "" {
+		return "/hopperbot"
+	}
+	return cmd.Command
+}
+
+// subcommandUsage returns how to invoke a subcommand, e.g. "/hopperbot customer <customer name>".
+func subcommandUsage(command string, sub Subcommand) string {
+	return strings.Join(strings.Fields(command+" "+sub.Name+" "+sub.Usage), " ")
+}
+
+// notionBoardURL returns the notion.so URL of the team's ideas database, or ""
+// when the backend isn't a Notion client (e.g. a fake in tests).
+func (h *Handler) notionBoardURL(teamID string) string {
+	client, ok := h.backendFor(teamID).(*notion.Client)
+	if !ok {
+		return ""
+	}
+	databaseID, _ := client.CurrentDatabases()
+	if databaseID == "" {
+		return ""
+	}
+	return "https://www.notion.so/" + strings.ReplaceAll(databaseID, "-", "")
+}
+
+// mrkdwnEscaper escapes the characters Slack's mrkdwn reserves for links and mentions.
+var mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// newMarkdownSection returns a section block with mrkdwn text.
+func newMarkdownSection(text string) *slack.SectionBlock {
+	return slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)
+}
+
+// logSlashCommand logs a received slash command.
+func (h *Handler) logSlashCommand(ctx context.Context, cmd SlashCommand, userName string) {
+	logging.FromContext(ctx, h.logger).Info("received slash command",
+		zap.String("command", cmd.Command),
+		zap.String("subcommand", cmd.Name),
+		zap.String("args", cmd.ArgText),
+		zap.String("user", userName),
+		zap.String("trigger_id", cmd.TriggerID),
+		zap.Int("trigger_id_length", len(cmd.TriggerID)),
+	)
+}
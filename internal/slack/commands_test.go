@@ -0,0 +1,129 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// TestHandleSlashCommand_Help tests that help lists every registered subcommand and the required fields
+func TestHandleSlashCommand_Help(t *testing.T) {
+	handler := newAdminTestHandler(&fakeSlack{}, fixedClock(time.Now()))
+	handler.SetFormRules(CustomerOrgRequiredRule([]string{"Customer Pain Point"}))
+
+	body := url.Values{"command": {"/hopperbot"}, "text": {"help"}, "team_id": {"T456"}, "user_id": {"U123"}}.Encode()
+	w := httptest.NewRecorder()
+	handler.HandleSlashCommand(w, createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		ResponseType string `json:"response_type"`
+		Text         string `json:"text"`
+		Blocks       []struct {
+			Text struct {
+				Text string `json:"text"`
+			} `json:"text"`
+		} `json:"blocks"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if response.ResponseType != "ephemeral" || response.Text == "" || len(response.Blocks) == 0 {
+		t.Fatalf("response = %s, want an ephemeral Block Kit reply", w.Body.String())
+	}
+
+	var texts []string
+	for _, block := range response.Blocks {
+		texts = append(texts, block.Text.Text)
+	}
+	help := strings.Join(texts, "\n")
+	for _, subcommand := range handler.commands.Subcommands() {
+		if !strings.Contains(help, subcommand.Description) {
+			t.Errorf("help does not describe %q", subcommand.Name)
+		}
+	}
+	for _, want := range []string{
+		"`/hopperbot customer &lt;customer name&gt;`",
+		"`/hopperbot refresh-cache`: Reload customers and users from Notion and report the outcome _(admins only)_",
+		"*Required fields:* Title, Theme/Category, Product Area",
+		"*Client Organization:* Required for Customer Pain Point",
+	} {
+		if !strings.Contains(help, want) {
+			t.Errorf("help = %s, want it to contain %q", help, want)
+		}
+	}
+}
+
+// TestHandleSlashCommand_Unknown tests that unknown subcommands get a suggestion instead of the form
+func TestHandleSlashCommand_Unknown(t *testing.T) {
+	slackAPI := &fakeSlack{opened: make(chan slack.ModalViewRequest, 1)}
+	handler := newAdminTestHandler(slackAPI, fixedClock(time.Now()))
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"refesh-cache", "Did you mean `/hopperbot refresh-cache`?"},
+		{"hlep", "Did you mean `/hopperbot help`?"},
+		{"my idea", "Unknown command `/hopperbot my`. Try `/hopperbot help`."},
+		{"customer", "Usage: /hopperbot customer <customer name>"},
+	}
+	for _, tt := range tests {
+		body := url.Values{"command": {"/hopperbot"}, "text": {tt.text}, "team_id": {"T456"}, "user_id": {"U123"}, "trigger_id": {"trigger"}}.Encode()
+		w := httptest.NewRecorder()
+		handler.HandleSlashCommand(w, createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+
+		var response struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%q: invalid JSON response: %v", tt.text, err)
+		}
+		if !strings.Contains(response.Text, tt.want) {
+			t.Errorf("%q: response = %q, want it to contain %q", tt.text, response.Text, tt.want)
+		}
+	}
+	if len(slackAPI.opened) > 0 {
+		t.Error("an unknown subcommand opened the form")
+	}
+}
+
+// TestHandleSlashCommand_Stats tests that stats reports the cache summary to admins
+func TestHandleSlashCommand_Stats(t *testing.T) {
+	handler := NewHandlerWithDependencies(&config.Config{
+		SlackSigningSecret: "secret",
+		SlackAdminUserIDs:  []string{"U-admin"},
+	}, zap.NewNop(), Dependencies{
+		Backend: &fakeBackend{snapshot: notion.NewCacheSnapshot(map[string]string{"Acme": "page-1"}, nil)},
+		Slack:   &fakeSlack{},
+		Clock:   fixedClock(time.Now()),
+	})
+
+	body := url.Values{"command": {"/hopperbot"}, "text": {"STATS"}, "team_id": {"T456"}, "user_id": {"U-admin"}}.Encode()
+	w := httptest.NewRecorder()
+	handler.HandleSlashCommand(w, createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+	if !strings.Contains(w.Body.String(), "1 customers, 0 users") {
+		t.Errorf("response = %s, want the cache summary", w.Body.String())
+	}
+}
+
+// TestBuildHelpBlocks_OpenAdmin tests that admin subcommands aren't marked when everyone may run them
+func TestBuildHelpBlocks_OpenAdmin(t *testing.T) {
+	open := NewHandlerWithDependencies(&config.Config{}, zap.NewNop(), Dependencies{Backend: &fakeBackend{}, Slack: &fakeSlack{}})
+	help := open.buildHelpBlocks("T1", "/hopperbot")
+	data, _ := json.Marshal(help)
+	if strings.Contains(string(data), "admins only") {
+		t.Error("help marks admin subcommands although they are open to everyone")
+	}
+}
@@ -0,0 +1,102 @@
+package slack
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/store"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// SetCommentBlocks writes comments to the body of created pages as paragraphs,
+// followed by the submitter and a link to the confirmation thread. Comments
+// may then be up to constants.MaxCommentBodyLength characters; the Comments
+// property keeps a preview of longer ones. Needs Notion's "Insert content"
+// capability.
+func (h *Handler) SetCommentBlocks(enabled bool) {
+	h.commentBlocks = enabled
+}
+
+// fieldLimits returns the limits to build the modal and validate submissions
+// with, allowing longer comments when they are written to the page body
+// (unless SetFieldLimits tightened the text limit).
+func (h *Handler) fieldLimits() FieldLimits {
+	limits := h.limits
+	if h.commentBlocks && limits.TextMaxLength == constants.MaxCommentLength {
+		limits.commentsMaxLength = constants.MaxCommentBodyLength
+	}
+	return limits
+}
+
+// createPage creates the Notion page for a submission. With comment blocks,
+// comments too long for the Comments property are shortened to a preview
+// there; appendComments writes them in full once the page exists. Every
+// attempt is recorded in the audit log, if enabled.
+func (h *Handler) createPage(ctx context.Context, teamID string, sub submission.Submission) (*notion.CreatedPage, error) {
+	if h.commentBlocks {
+		sub.Comments = commentsPreview(sub.Comments)
+	}
+	start := time.Now()
+	page, err := h.backendFor(teamID).SubmitSubmission(ctx, sub)
+	h.recordAttempt(store.ActionCreate, sub, page, err, time.Since(start))
+	return page, err
+}
+
+// commentsPreview shortens comments to fit the Comments property.
+func commentsPreview(comments string) string {
+	return shortenText(comments, constants.MaxCommentLength)
+}
+
+// shortenText shortens text to at most maxLength bytes without splitting a
+// character, ending shortened text with an ellipsis.
+func shortenText(text string, maxLength int) string {
+	if len(text) <= maxLength {
+		return text
+	}
+	const ellipsis = "…"
+	end := max(maxLength-len(ellipsis), 0)
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[:end] + ellipsis
+}
+
+// appendComments writes a submission's comments to the body of its created
+// page when comment blocks are enabled, linking the message the idea was
+// submitted from, or else the thread of the confirmation posted to the
+// confirmation channel (nil when there is none). Failures are only logged: the
+// page already exists.
+func (h *Handler) appendComments(ctx context.Context, sub submission.Submission, page *notion.CreatedPage, thread *slack.PermalinkParameters) {
+	if !h.commentBlocks || sub.Comments == "" {
+		return
+	}
+	teamID := sub.Source.SlackTeamID
+	logger := h.logger.With(zap.String("page_id", page.ID))
+
+	comments := notion.PageComments{
+		Text:        sub.Comments,
+		SubmittedBy: sub.Source.SubmitterEmail,
+		Channel:     sub.Source.ChannelName(),
+		SubmittedAt: sub.Source.SubmittedAt,
+	}
+	if sub.Source.SlackPermalink != "" {
+		// Submitted from a message: that's where the idea is discussed
+		comments.ThreadURL = sub.Source.SlackPermalink
+	} else if thread != nil {
+		permalink, err := h.slackFor(teamID).GetPermalinkContext(ctx, thread)
+		if err != nil {
+			h.recordSlackAPIError("chat.getPermalink", err)
+			logger.Warn("failed to get confirmation permalink, adding comments without it", zap.Error(err))
+		}
+		comments.ThreadURL = permalink
+	}
+
+	if err := h.backendFor(teamID).AppendComments(page.ID, comments); err != nil {
+		logger.Error("failed to append comments to Notion page", zap.Error(err))
+	}
+}
@@ -0,0 +1,97 @@
+package slack
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+func TestCommentsPreview(t *testing.T) {
+	if got := commentsPreview("Exports time out."); got != "Exports time out." {
+		t.Errorf("commentsPreview() = %q, want short comments unchanged", got)
+	}
+
+	got := commentsPreview(strings.Repeat("é", constants.MaxCommentLength))
+	if len(got) > constants.MaxCommentLength || !utf8.ValidString(got) || !strings.HasSuffix(got, "…") {
+		t.Errorf("commentsPreview() = %d bytes, want at most %d valid UTF-8 ending in an ellipsis", len(got), constants.MaxCommentLength)
+	}
+}
+
+// TestCommentBlocks tests that long comments are accepted, previewed in the property and written to the page with the confirmation thread
+func TestCommentBlocks(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(nil, map[string]string{"alice@example.com": "notion-user-alice"})}
+	slackAPI := &fakeSlack{
+		users:  map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+		posted: make(chan string, 1),
+	}
+	handler := NewHandlerWithDependencies(&config.Config{SlackSigningSecret: "secret", ConfirmationChannel: "C-ideas"}, zap.NewNop(), Dependencies{
+		Backend: backend,
+		Slack:   slackAPI,
+		Clock:   fixedClock(time.Now()),
+	})
+	handler.SetCommentBlocks(true)
+
+	for _, block := range handler.submissionModal("T456").Blocks.BlockSet {
+		if input, ok := block.(*slack.InputBlock); ok && input.BlockID == BlockIDComments {
+			if element := input.Element.(*slack.PlainTextInputBlockElement); element.MaxLength != constants.MaxCommentBodyLength {
+				t.Errorf("comments max length = %d, want %d", element.MaxLength, constants.MaxCommentBodyLength)
+			}
+		}
+	}
+
+	title := "Exports are slow"
+	comments := strings.Repeat("a", 2500)
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, submissionRequest(t, map[string]map[string]StateValue{
+		BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+		BlockIDTheme:       {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "Feature Improvement"}}},
+		BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+		BlockIDComments:    {ActionIDCommentsInput: {Type: "plain_text_input", Value: &comments}},
+	}))
+	if w.Body.String() != "{}" {
+		t.Fatalf("response = %q, want the modal closed", w.Body.String())
+	}
+	if len(backend.submissions) != 1 || len(backend.submissions[0].Comments) > constants.MaxCommentLength {
+		t.Fatalf("submissions = %d, want one with the comments shortened for the property", len(backend.submissions))
+	}
+
+	<-slackAPI.posted
+	var appended []notion.PageComments
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		backend.mu.Lock()
+		appended = backend.comments
+		backend.mu.Unlock()
+		if len(appended) > 0 {
+			break
+		}
+	}
+	if len(appended) != 1 {
+		t.Fatalf("appended comments %d times, want once", len(appended))
+	}
+	if got := appended[0]; got.Text != comments || got.SubmittedBy != "alice@example.com" || !strings.Contains(got.ThreadURL, "/archives/C-ideas/") {
+		t.Errorf("appended = %+v, want the full comments, submitter and confirmation thread", got)
+	}
+}
+
+// TestCommentBlocks_Disabled tests that comments stay limited to the property by default
+func TestCommentBlocks_Disabled(t *testing.T) {
+	backend := &fakeBackend{}
+	handler := newInteractiveTestHandler(backend, &fakeSlack{})
+
+	if got := handler.fieldLimits().commentsLength(); got != constants.MaxCommentLength {
+		t.Errorf("comments limit = %d, want %d", got, constants.MaxCommentLength)
+	}
+	handler.appendComments(t.Context(), submission.Submission{Comments: "Exports time out."}, &notion.CreatedPage{ID: "page-1"}, nil)
+	if len(backend.comments) != 0 {
+		t.Errorf("appended %d comments without SetCommentBlocks", len(backend.comments))
+	}
+}
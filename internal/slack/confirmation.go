@@ -0,0 +1,112 @@
+package slack
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/fanout"
+	"github.com/rudderlabs/hopperbot/pkg/featureflags"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// confirmationTimeout bounds posting the confirmation, which happens after the
+// modal has already been answered.
+const confirmationTimeout = 10 * time.Second
+
+// postConfirmation posts a Block Kit confirmation for a created idea to the
+// submitter's DMs, or to the configured confirmation channel when set. During
+// a burst, channel confirmations are batched instead (see batchConfirmation).
+//
+// copies are the results of writing the idea to secondary backends, shown
+// under the confirmation (not in batched ones). Nothing is posted while the
+// confirmations feature flag is off. Failures are logged but never fail the
+// submission, which has already succeeded.
+//
+// Returns the message posted to the confirmation channel, whose thread is where
+// the idea gets discussed, or nil when none was posted there.
+func (h *Handler) postConfirmation(ctx context.Context, sub submission.Submission, page *notion.CreatedPage, copies []fanout.Result) *slack.PermalinkParameters {
+	// The confirmation channel belongs to the SLACK_BOT_TOKEN workspace; OAuth-installed workspaces get DMs
+	if !h.flags.Enabled(featureflags.Confirmations) {
+		return nil
+	}
+	confirmationChannel := h.config.Load().ConfirmationChannel
+	channel := confirmationChannel
+	if _, installed := h.installation(sub.Source.SlackTeamID); channel == "" || installed {
+		channel = sub.Source.SlackUserID
+	}
+	if channel == "" {
+		return nil
+	}
+	if channel == confirmationChannel && h.batchConfirmation(sub, page) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, confirmationTimeout)
+	defer cancel()
+
+	text := h.messages.Format(messages.KeySubmissionConfirmation, messages.Params{
+		"title": sub.Title, "url": page.URL,
+	})
+	blocks := h.copyStatusBlocks(buildConfirmationBlocks(text, sub, page), copies)
+	if channel == confirmationChannel {
+		blocks = h.voteHintBlocks(blocks)
+	}
+	postedChannel, ts, err := h.slackFor(sub.Source.SlackTeamID).PostMessageContext(ctx, channel,
+		slack.MsgOptionText(text, false), // Notification and fallback text
+		slack.MsgOptionBlocks(blocks...),
+	)
+	if err != nil {
+		h.recordSlackAPIError("chat.postMessage", err)
+		h.logger.Error("failed to post submission confirmation",
+			zap.String("channel", channel),
+			zap.String("page_id", page.ID),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	h.logger.Debug("submission confirmation posted",
+		zap.String("channel", channel),
+		zap.String("page_id", page.ID),
+	)
+	if channel != confirmationChannel {
+		return nil
+	}
+	h.recordVoteCard(sub, page, postedChannel, ts)
+	return &slack.PermalinkParameters{Channel: postedChannel, Ts: ts}
+}
+
+// buildConfirmationBlocks renders the confirmation message: a headline, the
+// submission's fields, and a button linking to the Notion page.
+func buildConfirmationBlocks(headline string, sub submission.Submission, page *notion.CreatedPage) []slack.Block {
+	fields := []*slack.TextBlockObject{
+		slack.NewTextBlockObject(slack.MarkdownType, "*"+LabelThemeCategory+"*\n"+sub.Theme, false, false),
+		slack.NewTextBlockObject(slack.MarkdownType, "*"+LabelProductArea+"*\n"+sub.ProductArea, false, false),
+	}
+	if len(sub.CustomerOrgs) > 0 {
+		fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType,
+			"*"+LabelCustomerOrg+"*\n"+strings.Join(sub.CustomerOrgs, ", "), false, false))
+	}
+	if sub.Source.SlackUserID != "" {
+		fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType,
+			"*Submitted by*\n<@"+sub.Source.SlackUserID+">", false, false))
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, headline, false, false), nil, nil),
+		slack.NewSectionBlock(nil, fields, nil),
+	}
+
+	if page.URL != "" {
+		button := slack.NewButtonBlockElement("", "", newPlainText(ButtonOpenInNotion))
+		button.URL = page.URL
+		blocks = append(blocks, slack.NewActionBlock(BlockIDConfirmationActions, button))
+	}
+
+	return blocks
+}
@@ -0,0 +1,158 @@
+package slack
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// maxBatchSummaryLines caps the ideas listed in a batch summary; every idea
+// still gets its own reply in the summary's thread.
+const maxBatchSummaryLines = 50
+
+// confirmationBatch throttles confirmations to the confirmation channel.
+//
+// Up to Config.ConfirmationBatchThreshold confirmations within
+// Config.ConfirmationBatchWindow are posted as usual. During a burst (e.g. a
+// bulk import) the rest are held and posted once per window as a single
+// summary message listing the ideas, with each idea's full confirmation as a
+// reply in its thread. DMs to submitters are never batched.
+type confirmationBatch struct {
+	mu      sync.Mutex
+	recent  []time.Time // When confirmations were accepted, within the last window
+	pending []pendingConfirmation
+	timer   *time.Timer // Flushes pending; nil when nothing is pending
+}
+
+type pendingConfirmation struct {
+	sub  submission.Submission
+	page *notion.CreatedPage
+}
+
+// batchConfirmation holds a channel confirmation for the next batch summary if
+// the channel is in a burst, and reports whether it did. Confirmations that
+// aren't held must be posted by the caller.
+func (h *Handler) batchConfirmation(sub submission.Submission, page *notion.CreatedPage) bool {
+	cfg := h.config.Load()
+	threshold, window := cfg.ConfirmationBatchThreshold, cfg.ConfirmationBatchWindow
+	if threshold <= 0 || window <= 0 {
+		return false
+	}
+
+	b := &h.confirmationBatch
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := h.clock.Now()
+	cutoff := now.Add(-window)
+	kept := b.recent[:0]
+	for _, at := range b.recent {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	b.recent = append(kept, now)
+
+	// Once a batch is open, later confirmations join it to keep the channel in order
+	if len(b.pending) == 0 && len(b.recent) <= threshold {
+		return false
+	}
+
+	b.pending = append(b.pending, pendingConfirmation{sub: sub, page: page})
+	if b.timer == nil {
+		b.timer = time.AfterFunc(window, func() { h.FlushConfirmations(context.Background()) })
+		h.logger.Info("confirmation channel is busy, batching confirmations",
+			zap.Int("threshold", threshold),
+			zap.Duration("window", window),
+		)
+	}
+	return true
+}
+
+// FlushConfirmations posts the pending batch summary now. It runs at the end
+// of each batch window and on shutdown, so held confirmations aren't lost.
+func (h *Handler) FlushConfirmations(ctx context.Context) {
+	b := &h.confirmationBatch
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+	h.postConfirmationBatch(ctx, pending)
+}
+
+// postConfirmationBatch posts the summary of a batch, then each confirmation as a reply in its thread.
+// Batched confirmations are only for the SLACK_BOT_TOKEN workspace (see postConfirmation).
+func (h *Handler) postConfirmationBatch(ctx context.Context, pending []pendingConfirmation) {
+	cfg := h.config.Load()
+	channel := cfg.ConfirmationChannel
+
+	lines := make([]string, 0, min(len(pending), maxBatchSummaryLines)+2)
+	lines = append(lines, h.messages.Format(messages.KeyConfirmationBatch, messages.Params{
+		"count":   len(pending),
+		"minutes": int(cfg.ConfirmationBatchWindow.Minutes()),
+	}))
+	for i, item := range pending {
+		if i == maxBatchSummaryLines {
+			lines = append(lines, h.messages.Format(messages.KeyConfirmationBatchMore, messages.Params{"count": len(pending) - i}))
+			break
+		}
+		lines = append(lines, h.messages.Format(messages.KeyConfirmationBatchLine, messages.Params{
+			"title": item.sub.Title, "url": item.page.URL,
+		}))
+	}
+
+	postCtx, cancel := context.WithTimeout(ctx, confirmationTimeout)
+	_, ts, err := h.defaultSlackClient().PostMessageContext(postCtx, channel, slack.MsgOptionText(strings.Join(lines, "\n"), false))
+	cancel()
+	if err != nil {
+		h.recordSlackAPIError("chat.postMessage", err)
+		h.logger.Error("failed to post confirmation batch",
+			zap.String("channel", channel),
+			zap.Int("confirmations", len(pending)),
+			zap.Error(err),
+		)
+		return
+	}
+
+	for _, item := range pending {
+		text := h.messages.Format(messages.KeySubmissionConfirmation, messages.Params{
+			"title": item.sub.Title, "url": item.page.URL,
+		})
+		postCtx, cancel := context.WithTimeout(ctx, confirmationTimeout)
+		_, _, err := h.defaultSlackClient().PostMessageContext(postCtx, channel,
+			slack.MsgOptionText(text, false),
+			slack.MsgOptionBlocks(buildConfirmationBlocks(text, item.sub, item.page)...),
+			slack.MsgOptionTS(ts),
+		)
+		cancel()
+		if err != nil {
+			h.recordSlackAPIError("chat.postMessage", err)
+			h.logger.Warn("failed to post batched confirmation details",
+				zap.String("channel", channel),
+				zap.String("page_id", item.page.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	h.logger.Info("confirmation batch posted",
+		zap.String("channel", channel),
+		zap.Int("confirmations", len(pending)),
+		zap.String("thread_ts", ts),
+	)
+}
@@ -0,0 +1,106 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// TestConfirmationBatching tests that a burst of channel confirmations is summarized with threaded details
+func TestConfirmationBatching(t *testing.T) {
+	slackAPI := &fakeSlack{posted: make(chan string, 10), sent: make(chan url.Values, 10)}
+	handler := NewHandlerWithDependencies(&config.Config{
+		ConfirmationChannel:        "C-ideas",
+		ConfirmationBatchThreshold: 2,
+		ConfirmationBatchWindow:    time.Hour, // Flushed explicitly below
+	}, zap.NewNop(), Dependencies{Backend: &fakeBackend{}, Slack: slackAPI, Clock: fixedClock(time.Now())})
+
+	confirm := func(i int) {
+		handler.postConfirmation(context.Background(),
+			submission.Submission{Title: fmt.Sprintf("Idea %d", i), Theme: "New Feature Idea", ProductArea: "AI/ML"},
+			&notion.CreatedPage{ID: fmt.Sprintf("page-%d", i), URL: fmt.Sprintf("https://www.notion.so/page-%d", i)},
+			nil,
+		)
+	}
+	drain := func() []url.Values {
+		var sent []url.Values
+		for len(slackAPI.sent) > 0 {
+			<-slackAPI.posted
+			sent = append(sent, <-slackAPI.sent)
+		}
+		return sent
+	}
+
+	// Up to the threshold, confirmations are posted right away
+	confirm(1)
+	confirm(2)
+	if sent := drain(); len(sent) != 2 || sent[0].Get("thread_ts") != "" {
+		t.Fatalf("posted %v before the threshold, want 2 top-level messages", sent)
+	}
+
+	// The rest of the burst is held until the batch is flushed
+	confirm(3)
+	confirm(4)
+	if sent := drain(); len(sent) != 0 {
+		t.Fatalf("posted %v during the burst, want nothing", sent)
+	}
+
+	handler.FlushConfirmations(context.Background())
+	sent := drain()
+	if len(sent) != 3 {
+		t.Fatalf("posted %d messages on flush, want a summary and 2 replies", len(sent))
+	}
+	summary := sent[0]
+	if summary.Get("channel") != "C-ideas" || summary.Get("thread_ts") != "" {
+		t.Errorf("summary = %v, want a top-level message in C-ideas", summary)
+	}
+	for _, want := range []string{"minutes: *2*", "<https://www.notion.so/page-3|Idea 3>", "<https://www.notion.so/page-4|Idea 4>"} {
+		if !strings.Contains(summary.Get("text"), want) {
+			t.Errorf("summary text = %q, want containing %q", summary.Get("text"), want)
+		}
+	}
+	for i, reply := range sent[1:] {
+		if reply.Get("thread_ts") != "1700000000.000100" {
+			t.Errorf("reply %d thread_ts = %q, want the summary's ts", i, reply.Get("thread_ts"))
+		}
+		if title := fmt.Sprintf("Idea %d", i+3); !strings.Contains(reply.Get("text"), title) {
+			t.Errorf("reply %d text = %q, want %s", i, reply.Get("text"), title)
+		}
+	}
+
+	// Still within the window, so the burst keeps being batched; flushing twice posts nothing new
+	confirm(5)
+	handler.FlushConfirmations(context.Background())
+	handler.FlushConfirmations(context.Background())
+	if sent := drain(); len(sent) != 2 || !strings.Contains(sent[0].Get("text"), "minutes: *1*") {
+		t.Errorf("posted %v, want one more summary with a reply", sent)
+	}
+}
+
+// TestConfirmationBatching_DMsNotBatched tests that submitter DMs are never held
+func TestConfirmationBatching_DMsNotBatched(t *testing.T) {
+	slackAPI := &fakeSlack{posted: make(chan string, 10)}
+	handler := NewHandlerWithDependencies(&config.Config{
+		ConfirmationBatchThreshold: 1,
+		ConfirmationBatchWindow:    time.Hour,
+	}, zap.NewNop(), Dependencies{Backend: &fakeBackend{}, Slack: slackAPI, Clock: fixedClock(time.Now())})
+
+	for i := range 3 {
+		handler.postConfirmation(context.Background(),
+			submission.Submission{Title: "Idea", Source: submission.Source{SlackUserID: "U123"}},
+			&notion.CreatedPage{ID: fmt.Sprintf("page-%d", i)},
+			nil,
+		)
+	}
+	if got := len(slackAPI.posted); got != 3 {
+		t.Errorf("posted %d DMs, want 3", got)
+	}
+}
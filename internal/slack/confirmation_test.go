@@ -0,0 +1,62 @@
+package slack
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+)
+
+// TestBuildConfirmationBlocks tests the confirmation message layout
+func TestBuildConfirmationBlocks(t *testing.T) {
+	sub := submission.Submission{
+		Title:        "Exports are slow",
+		Theme:        "Customer Pain Point",
+		ProductArea:  "AI/ML",
+		CustomerOrgs: []string{"Acme", "Globex"},
+		Source:       submission.Source{Channel: submission.ChannelSlack, SlackUserID: "U123"},
+	}
+	page := &notion.CreatedPage{ID: "page-1", URL: "https://www.notion.so/page-1"}
+
+	blocks := buildConfirmationBlocks("headline", sub, page)
+	if len(blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(blocks))
+	}
+
+	fields := blocks[1].(*slack.SectionBlock).Fields
+	if len(fields) != 4 {
+		t.Fatalf("expected 4 fields, got %d", len(fields))
+	}
+	for i, want := range []string{"Customer Pain Point", "AI/ML", "Acme, Globex", "<@U123>"} {
+		if !strings.Contains(fields[i].Text, want) {
+			t.Errorf("field %d = %q, want it to contain %q", i, fields[i].Text, want)
+		}
+	}
+
+	actions := blocks[2].(*slack.ActionBlock)
+	button := actions.Elements.ElementSet[0].(*slack.ButtonBlockElement)
+	if button.URL != page.URL || button.Text.Text != ButtonOpenInNotion {
+		t.Errorf("button = %+v, want link to %s", button, page.URL)
+	}
+
+	// Blocks must serialize for chat.postMessage
+	if _, err := json.Marshal(slack.Blocks{BlockSet: blocks}); err != nil {
+		t.Errorf("failed to marshal blocks: %v", err)
+	}
+}
+
+// TestBuildConfirmationBlocks_Minimal tests optional parts are omitted
+func TestBuildConfirmationBlocks_Minimal(t *testing.T) {
+	sub := submission.Submission{Title: "Idea", Theme: "New Feature Idea", ProductArea: "AI/ML"}
+
+	blocks := buildConfirmationBlocks("headline", sub, &notion.CreatedPage{ID: "page-1"})
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks without a page URL, got %d", len(blocks))
+	}
+	if fields := blocks[1].(*slack.SectionBlock).Fields; len(fields) != 2 {
+		t.Errorf("expected 2 fields without customers or submitter, got %d", len(fields))
+	}
+}
@@ -3,8 +3,19 @@ package slack
 // Modal callback IDs
 const (
 	ModalCallbackIDSubmitForm = "submit_form_modal"
+
+	// /hopperbot edit: the first modal picks one of the user's ideas, the second edits it
+	ModalCallbackIDEditSelect = "edit_select_modal"
+	ModalCallbackIDEditForm   = "edit_form_modal"
+
+	// Multi-step submission: the first modal collects the title and theme, then
+	// pushes the submit form with the remaining fields
+	ModalCallbackIDSubmitStepOne = "submit_step_one_modal"
 )
 
+// HomeCallbackID identifies the App Home view in block_actions payloads
+const HomeCallbackID = "app_home"
+
 // Block IDs for modal form fields
 const (
 	BlockIDTitle       = "title_block"
@@ -12,6 +23,28 @@ const (
 	BlockIDProductArea = "product_area_block"
 	BlockIDComments    = "comments_block"
 	BlockIDCustomerOrg = "client_org_block" // Keep original ID for Slack compatibility
+	BlockIDArtifacts   = "artifacts_block"
+	BlockIDAttachments = "attachments_block"
+	BlockIDRemindMe    = "remind_me_block"
+	BlockIDEditIdea    = "edit_idea_block"
+
+	// BlockIDSchemaPrefix prefixes the property name in block IDs of fields generated from the database schema
+	BlockIDSchemaPrefix = "notion_property:"
+
+	// BlockIDConfirmationActions holds the "Open in Notion" button on confirmation messages
+	BlockIDConfirmationActions = "confirmation_actions"
+
+	// BlockIDHomeActions holds the "Submit an idea" and "Refresh" buttons on the App Home tab
+	BlockIDHomeActions = "home_actions"
+
+	// BlockIDShareDraft holds the "Share draft" user select in the submission modal
+	BlockIDShareDraft = "share_draft_block"
+
+	// BlockIDDraftActions holds the "Open draft" button on shared draft messages
+	BlockIDDraftActions = "draft_actions"
+
+	// BlockIDAutosaveActions holds the "Discard draft" button of a modal restored from an autosaved draft
+	BlockIDAutosaveActions = "autosave_actions"
 )
 
 // Action IDs for modal form fields
@@ -21,12 +54,74 @@ const (
 	ActionIDProductAreaSelect = "product_area_select"
 	ActionIDCommentsInput     = "comments_input"
 	ActionIDCustomerOrgSelect = "client_org_select" // Keep original ID for Slack compatibility
+	ActionIDArtifactsInput    = "artifacts_input"
+	ActionIDAttachmentsInput  = "attachments_input"
+	ActionIDRemindMeSelect    = "remind_me_select"
+	ActionIDEditIdeaSelect    = "edit_idea_select"
+
+	// Action IDs of fields generated from the database schema, by property type
+	ActionIDSchemaSelect      = "notion_property_select"
+	ActionIDSchemaMultiSelect = "notion_property_multi_select"
+	ActionIDSchemaText        = "notion_property_text"
+	ActionIDSchemaPeople      = "notion_property_people"
+	ActionIDSchemaDate        = "notion_property_date"
+	ActionIDSchemaCustomers   = "notion_property_customers"
+)
+
+// Action IDs of App Home buttons
+const (
+	ActionIDHomeSubmitIdea = "home_submit_idea"
+	ActionIDHomeRefresh    = "home_refresh"
+)
+
+// Action IDs of drafts: sharing from the modal, opening from the DM and
+// discarding an autosaved draft restored in the modal
+const (
+	ActionIDShareDraft   = "share_draft_select"
+	ActionIDOpenDraft    = "open_draft"
+	ActionIDDiscardDraft = "discard_draft"
 )
 
 // Modal UI text
 const (
 	ModalSubmitText = "Submit"
 	ModalCancelText = "Cancel"
+
+	// Edit flow (titles must be under 25 characters)
+	ModalTitleEdit = "Edit Your Idea"
+	ModalNextText  = "Next"
+	ModalSaveText  = "Save"
+	EditInfoText   = "Your changes will be saved to the existing Notion page."
+
+	// Second step of the multi-step submission; its close button returns to the first
+	ModalTitleStepTwo = "Add the Details"
+	ModalBackText     = "Back"
+
+	// Shown instead of the form when a submission is queued because Notion is unavailable
+	ModalTitleQueued = "Submission Queued"
+	ModalCloseText   = "Close"
+
+	// Shown instead of the form when an idea is sent to GUEST_TRIAGE_CHANNEL
+	ModalTitleTriaged = "Sent for Review"
+)
+
+// ButtonOpenInNotion is the link button text on confirmation messages
+const ButtonOpenInNotion = "Open in Notion"
+
+// ButtonOpenDraft is the button text on shared draft messages
+const ButtonOpenDraft = "Open draft"
+
+// ButtonDiscardDraft is the button text on a modal restored from an autosaved draft
+const ButtonDiscardDraft = "Discard draft"
+
+// HelpHeaderText is the header of the /hopperbot help response
+const HelpHeaderText = "Hopperbot help"
+
+// App Home UI text
+const (
+	HomeHeaderText   = "Your ideas"
+	ButtonSubmitIdea = "Submit an idea"
+	ButtonRefresh    = "Refresh"
 )
 
 // ModalTitles contains a list of witty titles that rotate each time the modal is opened.
@@ -52,6 +147,10 @@ const (
 	LabelProductArea   = "Product Area"
 	LabelComments      = "Comments"
 	LabelCustomerOrg   = "Client Organization" // Keep original label - Slack may have this cached
+	LabelArtifacts     = "Artifacts"
+	LabelAttachments   = "Attachments"
+	LabelRemindMe      = "Remind me to follow up"
+	LabelEditIdea      = "Which idea do you want to edit?"
 )
 
 // Field placeholders
@@ -61,11 +160,25 @@ const (
 	PlaceholderProductArea = "Select product area..."
 	PlaceholderComments    = "Add any additional context or details..."
 	PlaceholderCustomerOrg = "Select customers..."
+	PlaceholderArtifacts   = "Paste Slack canvas or huddle links, one per line"
+	PlaceholderRemindMe    = "No reminder"
+	PlaceholderSelect      = "Select..."
+	PlaceholderPeople      = "Select people..."
+	PlaceholderEditIdea    = "Select an idea..."
+	PlaceholderShareDraft  = "Share draft with a teammate..."
 )
 
 // Field hints
 const (
-	HintCustomerOrg = "Select up to 10 customer organizations"
+	HintRemindMe  = "Hopperbot will DM you the idea's current status and a link"
+	HintArtifacts = "Canvas titles are looked up and saved as links in Notion"
+
+	// Formatted with constants.MaxAttachments and the size limit in MB
+	HintAttachments = "Screenshots or documents, up to %d files of %d MB each, added to the Notion page"
+
+	// Limit hints, formatted with the effective FieldLimits
+	HintMaxLength       = "Max %d characters"
+	HintMaxCustomerOrgs = "Select up to %d customer organizations"
 )
 
 // Slack request headers
@@ -74,6 +187,13 @@ const (
 	HeaderSlackSignature        = "X-Slack-Signature"
 )
 
+// HeaderCacheVersion is set on responses to report the cache snapshot version used
+const HeaderCacheVersion = "X-Hopperbot-Cache-Version"
+
+// HeaderOptionsCache is set on options responses to report whether they were
+// served from the options cache ("hit") or built for the request ("miss")
+const HeaderOptionsCache = "X-Hopperbot-Options-Cache"
+
 // Slack signature components
 const (
 	SignatureVersion = "v0"
@@ -83,4 +203,16 @@ const (
 // Interaction types
 const (
 	InteractionTypeViewSubmission = "view_submission"
+	InteractionTypeViewClosed     = "view_closed"
+	InteractionTypeBlockActions   = "block_actions"
+	InteractionTypeMessageAction  = "message_action"
+)
+
+// Events API request and event types
+const (
+	EventRequestURLVerification = "url_verification"
+	EventRequestCallback        = "event_callback"
+	EventTypeAppHomeOpened      = "app_home_opened"
+	EventTypeReactionAdded      = "reaction_added"
+	EventTypeReactionRemoved    = "reaction_removed"
 )
@@ -0,0 +1,33 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+)
+
+// defaultSlackClient returns the client for SLACK_BOT_TOKEN, used for the
+// default workspace and for posts that aren't tied to one (digests, batched
+// confirmations).
+func (h *Handler) defaultSlackClient() SlackAPI {
+	return *h.slackClient.Load()
+}
+
+// RotateBotToken switches the default workspace to a new bot token without a
+// restart, e.g. while rotating SLACK_BOT_TOKEN. The token is checked with
+// auth.test first, so an invalid one is rejected and the current client kept.
+// Calls in flight finish with the client they started with. Workspaces
+// installed through OAuth keep their own tokens.
+func (h *Handler) RotateBotToken(ctx context.Context, token string) error {
+	client := h.newTeamClient(token)
+	if _, err := client.AuthTestContext(ctx); err != nil {
+		return fmt.Errorf("new bot token rejected by auth.test: %w", err)
+	}
+
+	h.configMu.Lock()
+	defer h.configMu.Unlock()
+	h.slackClient.Store(&client)
+	next := *h.config.Load()
+	next.BotToken = token
+	h.config.Store(&next)
+	return nil
+}
@@ -0,0 +1,49 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"go.uber.org/zap"
+)
+
+// TestRotateBotToken tests that a valid token replaces the default client and an invalid one is rejected
+func TestRotateBotToken(t *testing.T) {
+	original := &fakeSlack{posted: make(chan string, 1)}
+	handler := NewHandlerWithDependencies(&config.Config{SlackBotToken: "xoxb-old"}, zap.NewNop(), Dependencies{
+		Backend: &fakeBackend{ideas: &notion.IdeaQueryResult{}},
+		Slack:   original,
+		Clock:   fixedClock(time.Now()),
+	})
+	clients := map[string]*fakeSlack{
+		"xoxb-new":     {posted: make(chan string, 1)},
+		"xoxb-revoked": {authErr: errors.New("invalid_auth")},
+	}
+	handler.newTeamClient = func(botToken string) SlackAPI { return clients[botToken] }
+
+	if err := handler.RotateBotToken(context.Background(), "xoxb-revoked"); err == nil {
+		t.Fatal("RotateBotToken() with a rejected token succeeded")
+	}
+	if handler.defaultSlackClient() != SlackAPI(original) || handler.config.Load().BotToken != "xoxb-old" {
+		t.Fatal("a rejected token replaced the default client")
+	}
+
+	if err := handler.RotateBotToken(context.Background(), "xoxb-new"); err != nil {
+		t.Fatalf("RotateBotToken() error = %v", err)
+	}
+	if handler.config.Load().BotToken != "xoxb-new" {
+		t.Errorf("BotToken = %q, want xoxb-new", handler.config.Load().BotToken)
+	}
+	if err := handler.PostDigest(context.Background(), "C-digest"); err != nil {
+		t.Fatalf("PostDigest() error = %v", err)
+	}
+	select {
+	case <-clients["xoxb-new"].posted:
+	default:
+		t.Error("digest wasn't posted with the rotated token's client")
+	}
+}
@@ -0,0 +1,82 @@
+package slack
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"go.uber.org/zap"
+)
+
+// customerIdeasLimit is how many recent ideas /hopperbot customer lists.
+const customerIdeasLimit = 10
+
+// handleCustomerCommand handles /hopperbot customer <name>, replying with the
+// number of ideas linked to the customer and the most recent ones with their status.
+//
+// The customer is resolved against the cache snapshot (case-insensitive, or by
+// alias), then
+// ideas are queried through the Customer Org relation.
+func (h *Handler) handleCustomerCommand(w http.ResponseWriter, teamID, userID, command, name string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		h.recordSlackCommand(command, "error")
+		respondToSlack(w, h.messages.Format(messages.KeyCustomerUsage, nil))
+		return
+	}
+
+	customer, pageID, found := h.cacheFor(teamID).Snapshot().FindCustomer(name)
+	if !found {
+		h.recordSlackCommand(command, "error")
+		respondToSlack(w, h.messages.Format(messages.KeyCustomerNotFound, messages.Params{"name": name}))
+		return
+	}
+
+	result, err := h.backendFor(teamID).CustomerIdeas(pageID, customerIdeasLimit)
+	if err != nil {
+		h.logger.Error("failed to query customer ideas",
+			zap.String("customer", customer),
+			zap.String("customer_page_id", pageID),
+			zap.Error(err),
+		)
+		h.recordSlackCommand(command, "error")
+		respondToSlack(w, h.messages.Format(messages.KeyCustomerLookupFailed, messages.Params{"customer": customer}))
+		return
+	}
+
+	h.logger.Info("customer ideas listed",
+		zap.String("customer", customer),
+		zap.Int("total", result.Total),
+		zap.Bool("truncated", result.Truncated),
+	)
+	h.recordSlackCommand(command, "success")
+
+	if result.Total == 0 {
+		respondToSlack(w, h.messages.Format(messages.KeyCustomerNoIdeas, messages.Params{"customer": customer}))
+		return
+	}
+
+	count := strconv.Itoa(result.Total)
+	if result.Truncated {
+		count += "+"
+	}
+	lines := []string{h.messages.Format(messages.KeyCustomerIdeas, messages.Params{
+		"customer": customer,
+		"count":    count,
+		"shown":    len(result.Ideas),
+	})}
+	for _, idea := range result.Ideas {
+		status := idea.Status
+		if status == "" {
+			status = h.messages.Format(messages.KeyReminderNoStatus, nil)
+		}
+		lines = append(lines, h.messages.Format(messages.KeyCustomerIdeaLine, messages.Params{
+			"title":     idea.Title,
+			"url":       idea.URL,
+			"status":    status,
+			"submitted": h.FormatTimeForUser(userID, idea.CreatedTime),
+		}))
+	}
+	respondToSlack(w, strings.Join(lines, "\n"))
+}
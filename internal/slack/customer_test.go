@@ -0,0 +1,99 @@
+package slack
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"go.uber.org/zap"
+)
+
+// TestHandleCustomerCommand tests the /hopperbot customer replies
+func TestHandleCustomerCommand(t *testing.T) {
+	created := time.Date(2025, 11, 12, 10, 0, 0, 0, time.UTC)
+	snapshot := notion.NewCacheSnapshot(map[string]string{"Acme Corp": "page-acme"}, nil)
+
+	tests := []struct {
+		name         string
+		text         string
+		ideas        *notion.IdeaQueryResult
+		ideasErr     error
+		wantContains []string
+	}{
+		{
+			name:         "usage",
+			text:         "customer",
+			wantContains: []string{"Usage: /hopperbot customer"},
+		},
+		{
+			name:         "unknown customer",
+			text:         "customer Globex",
+			wantContains: []string{`No customer named "Globex"`},
+		},
+		{
+			name:         "no ideas",
+			text:         "customer acme corp",
+			ideas:        &notion.IdeaQueryResult{},
+			wantContains: []string{"No ideas are linked to *Acme Corp*"},
+		},
+		{
+			name: "ideas",
+			text: "customer Acme Corp",
+			ideas: &notion.IdeaQueryResult{Total: 12, Ideas: []notion.IdeaSummary{
+				{Title: "Dark mode", URL: "https://www.notion.so/page-1", Status: "Planned", CreatedTime: created},
+				{Title: "SSO", URL: "https://www.notion.so/page-2", CreatedTime: created},
+			}},
+			wantContains: []string{
+				"*12* ideas linked to *Acme Corp*. Most recent 2:",
+				"• <https://www.notion.so/page-1|Dark mode> – Planned",
+				"• <https://www.notion.so/page-2|SSO> – not triaged yet",
+			},
+		},
+		{
+			name:         "truncated count",
+			text:         "customer Acme Corp",
+			ideas:        &notion.IdeaQueryResult{Total: 1000, Truncated: true, Ideas: []notion.IdeaSummary{{Title: "Dark mode"}}},
+			wantContains: []string{"*1000+* ideas"},
+		},
+		{
+			name:         "query error",
+			text:         "customer Acme Corp",
+			ideasErr:     errors.New("notion unavailable"),
+			wantContains: []string{"Failed to look up ideas for Acme Corp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fakeBackend{snapshot: snapshot, ideas: tt.ideas, ideasErr: tt.ideasErr}
+			handler := NewHandlerWithDependencies(&config.Config{SlackSigningSecret: "secret"}, zap.NewNop(), Dependencies{
+				Backend: backend,
+				Slack:   &fakeSlack{},
+			})
+
+			body := url.Values{"command": {"/hopperbot"}, "text": {tt.text}, "user_id": {"U123"}}.Encode()
+			w := httptest.NewRecorder()
+			handler.HandleSlashCommand(w, createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+
+			var response map[string]string
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response["response_type"] != "ephemeral" {
+				t.Errorf("response_type = %q, want ephemeral", response["response_type"])
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(response["text"], want) {
+					t.Errorf("text = %q, want it to contain %q", response["text"], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package slack
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/responseurl"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+)
+
+// CacheStore provides the customer and user cache snapshot pinned per request.
+type CacheStore interface {
+	Snapshot() *notion.CacheSnapshot
+}
+
+// SubmissionBackend is what the handler needs from the tracker ideas are filed
+// in: creating and editing pages, adding comments and files, reading their
+// status, querying ideas, and loading the caches and schema. *notion.Client
+// and *linear.Client implement it; another tracker plugs in by implementing it
+// with the notion package's types and being passed as Dependencies.Backend.
+// Handler tests use a fake.
+type SubmissionBackend interface {
+	CacheStore
+	SubmitSubmission(ctx context.Context, sub submission.Submission) (*notion.CreatedPage, error)
+	GetSubmission(pageID string) (*notion.SubmissionPage, error)
+	UpdateSubmission(ctx context.Context, pageID string, sub submission.Submission) (*notion.CreatedPage, error)
+	GetPageStatus(pageID string) (*notion.PageStatus, error)
+	CustomerIdeas(customerPageID string, limit int) (*notion.IdeaQueryResult, error)
+	SubmitterIdeas(notionUserID string, limit int) (*notion.IdeaQueryResult, error)
+	QueryIdeas(filter notion.IdeasFilter, sort notion.Sort, cursor string) (*notion.IdeaQueryResult, error)
+	SyncSchema() ([]notion.FormProperty, error)
+	SchemaError() error
+	AttachFile(pageID string, file notion.FileAttachment) error
+	AppendComments(pageID string, comments notion.PageComments) error
+	SetVotes(pageID string, votes int) error
+	InitializeDataSources() error
+	InitializeCustomers() error
+	InitializeUsers() error
+	GetCachedUserEmails() []string
+	GetUserCacheSize() int
+	SetMetrics(m *metrics.Metrics)
+}
+
+// SlackAPI is the subset of the Slack Web API the handler calls. *slack.Client implements it.
+type SlackAPI interface {
+	GetUserInfo(user string) (*slack.User, error)
+	GetUsersContext(ctx context.Context, options ...slack.GetUsersOption) ([]slack.User, error)
+	OpenView(triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error)
+	UpdateViewContext(ctx context.Context, view slack.ModalViewRequest, externalID, hash, viewID string) (*slack.ViewResponse, error)
+	PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error)
+	PublishViewContext(ctx context.Context, req slack.PublishViewContextRequest) (*slack.ViewResponse, error)
+	GetFileInfoContext(ctx context.Context, fileID string, count, page int) (*slack.File, []slack.Comment, *slack.Paging, error)
+	GetFileContext(ctx context.Context, downloadURL string, writer io.Writer) error
+	GetPermalinkContext(ctx context.Context, params *slack.PermalinkParameters) (string, error)
+	GetUserGroupMembersContext(ctx context.Context, userGroup string, options ...slack.GetUserGroupMembersOption) ([]string, error)
+	AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error)
+}
+
+// Responder posts delayed messages to the response_url of a slash command or
+// interaction. *responseurl.Client implements it.
+type Responder interface {
+	Post(ctx context.Context, responseURL string, msg responseurl.Message) error
+}
+
+// Clock returns the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the default Clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Dependencies overrides the handler's collaborators. Nil fields get the
+// default wiring built from the config (see NewHandlerWithDependencies).
+type Dependencies struct {
+	// Backend creates pages and loads caches (default: a *notion.Client for the configured databases).
+	Backend SubmissionBackend
+
+	// Slack calls the Slack Web API (default: a *slack.Client using the bot token).
+	Slack SlackAPI
+
+	// Responder posts to response URLs (default: a *responseurl.Client sharing the Slack transport).
+	Responder Responder
+
+	// Clock supplies the current time (default: the system clock).
+	Clock Clock
+
+	// Store supplies cache snapshots (default: Backend).
+	Store CacheStore
+}
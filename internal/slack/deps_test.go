@@ -0,0 +1,453 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/httpclient"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/requestid"
+	"github.com/rudderlabs/hopperbot/pkg/responseurl"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// fakeBackend is an in-memory SubmissionBackend
+type fakeBackend struct {
+	snapshot  *notion.CacheSnapshot
+	submitErr error
+	ideas     *notion.IdeaQueryResult
+	ideasErr  error
+	schema    []notion.FormProperty
+	schemaErr error
+	pages     map[string]*notion.SubmissionPage
+	attachErr error // Returned for uploaded files; linked files always succeed
+
+	mu          sync.Mutex
+	submissions []submission.Submission
+	updates     map[string]submission.Submission
+	attached    []notion.FileAttachment
+	comments    []notion.PageComments
+	votes       map[string]int
+	queries     []notion.IdeasFilter
+}
+
+func (b *fakeBackend) Snapshot() *notion.CacheSnapshot { return b.snapshot }
+
+func (b *fakeBackend) SubmitSubmission(_ context.Context, sub submission.Submission) (*notion.CreatedPage, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.submissions = append(b.submissions, sub)
+	if b.submitErr != nil {
+		return nil, b.submitErr
+	}
+	return &notion.CreatedPage{ID: "page-1", URL: "https://www.notion.so/page-1"}, nil
+}
+
+func (b *fakeBackend) GetSubmission(pageID string) (*notion.SubmissionPage, error) {
+	if page, ok := b.pages[pageID]; ok {
+		return page, nil
+	}
+	return nil, errors.New("object_not_found")
+}
+
+func (b *fakeBackend) UpdateSubmission(_ context.Context, pageID string, sub submission.Submission) (*notion.CreatedPage, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.updates == nil {
+		b.updates = make(map[string]submission.Submission)
+	}
+	b.updates[pageID] = sub
+	return &notion.CreatedPage{ID: pageID, URL: "https://www.notion.so/" + pageID}, nil
+}
+
+func (b *fakeBackend) GetPageStatus(pageID string) (*notion.PageStatus, error) {
+	return &notion.PageStatus{ID: pageID}, nil
+}
+func (b *fakeBackend) CustomerIdeas(string, int) (*notion.IdeaQueryResult, error) {
+	return b.ideas, b.ideasErr
+}
+func (b *fakeBackend) SubmitterIdeas(string, int) (*notion.IdeaQueryResult, error) {
+	return b.ideas, b.ideasErr
+}
+func (b *fakeBackend) QueryIdeas(filter notion.IdeasFilter, _ notion.Sort, _ string) (*notion.IdeaQueryResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.queries = append(b.queries, filter)
+	return b.ideas, b.ideasErr
+}
+func (b *fakeBackend) SyncSchema() ([]notion.FormProperty, error) {
+	return b.schema, nil
+}
+func (b *fakeBackend) SchemaError() error            { return b.schemaErr }
+func (b *fakeBackend) InitializeDataSources() error  { return nil }
+func (b *fakeBackend) InitializeCustomers() error    { return nil }
+func (b *fakeBackend) InitializeUsers() error        { return nil }
+func (b *fakeBackend) GetCachedUserEmails() []string { return b.snapshot.UserEmails() }
+func (b *fakeBackend) GetUserCacheSize() int         { return b.snapshot.UserCount() }
+func (b *fakeBackend) SetMetrics(*metrics.Metrics)   {}
+
+func (b *fakeBackend) AttachFile(_ string, file notion.FileAttachment) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if file.Content != nil && b.attachErr != nil {
+		return b.attachErr
+	}
+	b.attached = append(b.attached, file)
+	return nil
+}
+
+func (b *fakeBackend) AppendComments(_ string, comments notion.PageComments) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.comments = append(b.comments, comments)
+	return nil
+}
+
+func (b *fakeBackend) SetVotes(pageID string, votes int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.votes == nil {
+		b.votes = make(map[string]int)
+	}
+	b.votes[pageID] = votes
+	return nil
+}
+
+// fakeSlack is an in-memory SlackAPI
+type fakeSlack struct {
+	users      map[string]*slack.User
+	posted     chan string                   // Channels messages were posted to
+	published  chan slack.HomeTabViewRequest // Home views published (optional)
+	opened     chan slack.ModalViewRequest   // Modals opened (optional)
+	updated    chan slack.ModalViewRequest   // Modals updated (optional)
+	files      map[string]*slack.File        // Files returned by files.info
+	downloads  map[string]string             // Download URL -> file content
+	sent       chan url.Values               // Parameters of posted messages (optional)
+	responded  chan string                   // response_urls messages were posted to (optional)
+	usergroups map[string][]string           // User group ID -> member user IDs
+	authErr    error                         // Returned by auth.test (nil accepts the token)
+}
+
+func (s *fakeSlack) GetUserInfo(user string) (*slack.User, error) {
+	if u, ok := s.users[user]; ok {
+		return u, nil
+	}
+	return nil, errors.New("user_not_found")
+}
+
+func (s *fakeSlack) GetUsersContext(context.Context, ...slack.GetUsersOption) ([]slack.User, error) {
+	users := make([]slack.User, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, *u)
+	}
+	return users, nil
+}
+
+func (s *fakeSlack) OpenView(_ string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	if s.opened != nil {
+		s.opened <- view
+	}
+	return &slack.ViewResponse{}, nil
+}
+
+func (s *fakeSlack) UpdateViewContext(_ context.Context, view slack.ModalViewRequest, _, _, _ string) (*slack.ViewResponse, error) {
+	if s.updated != nil {
+		s.updated <- view
+	}
+	return &slack.ViewResponse{}, nil
+}
+
+func (s *fakeSlack) PostMessageContext(_ context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	_, values, _ := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	if s.sent != nil {
+		s.sent <- values
+	}
+	s.posted <- channelID
+	return channelID, "1700000000.000100", nil
+}
+
+// Post implements Responder, recording the response_url and the message as
+// PostMessageContext does
+func (s *fakeSlack) Post(_ context.Context, responseURL string, msg responseurl.Message) error {
+	if s.sent != nil {
+		s.sent <- url.Values{"text": {msg.Text}, "response_type": {msg.ResponseType}}
+	}
+	if s.responded != nil {
+		s.responded <- responseURL
+	}
+	s.posted <- responseURL
+	return nil
+}
+
+func (s *fakeSlack) PublishViewContext(_ context.Context, req slack.PublishViewContextRequest) (*slack.ViewResponse, error) {
+	if s.published != nil {
+		s.published <- req.View
+	}
+	return &slack.ViewResponse{}, nil
+}
+
+func (s *fakeSlack) GetFileInfoContext(_ context.Context, fileID string, _, _ int) (*slack.File, []slack.Comment, *slack.Paging, error) {
+	if file, ok := s.files[fileID]; ok {
+		return file, nil, nil, nil
+	}
+	return nil, nil, nil, errors.New("file_not_found")
+}
+
+func (s *fakeSlack) GetFileContext(_ context.Context, downloadURL string, writer io.Writer) error {
+	if content, ok := s.downloads[downloadURL]; ok {
+		_, err := io.WriteString(writer, content)
+		return err
+	}
+	return errors.New("file_not_found")
+}
+
+func (s *fakeSlack) GetPermalinkContext(_ context.Context, params *slack.PermalinkParameters) (string, error) {
+	return "https://acme.slack.com/archives/" + params.Channel + "/p" + strings.ReplaceAll(params.Ts, ".", ""), nil
+}
+
+func (s *fakeSlack) GetUserGroupMembersContext(_ context.Context, userGroup string, _ ...slack.GetUserGroupMembersOption) ([]string, error) {
+	if members, ok := s.usergroups[userGroup]; ok {
+		return members, nil
+	}
+	return nil, errors.New("no_such_subteam")
+}
+
+func (s *fakeSlack) AuthTestContext(context.Context) (*slack.AuthTestResponse, error) {
+	if s.authErr != nil {
+		return nil, s.authErr
+	}
+	return &slack.AuthTestResponse{TeamID: "T123"}, nil
+}
+
+// fixedClock is a Clock that always returns the same time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func newInteractiveTestHandler(backend *fakeBackend, slackAPI *fakeSlack) *Handler {
+	return NewHandlerWithDependencies(&config.Config{SlackSigningSecret: "secret"}, zap.NewNop(), Dependencies{
+		Backend:   backend,
+		Slack:     slackAPI,
+		Responder: slackAPI,
+		Clock:     fixedClock(time.Now()),
+	})
+}
+
+func submissionRequest(t *testing.T, values map[string]map[string]StateValue) *http.Request {
+	t.Helper()
+	payload, err := json.Marshal(InteractionPayload{
+		Type: InteractionTypeViewSubmission,
+		User: User{ID: "U123", Username: "alice"},
+		Team: Team{ID: "T456"},
+		View: View{CallbackID: ModalCallbackIDSubmitForm, State: ViewState{Values: values}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	return createValidSlackRequest(http.MethodPost, "/slack/interactive", []byte(body), "secret")
+}
+
+// TestHandleInteractive_Submission tests the full submission flow against injected fakes
+func TestHandleInteractive_Submission(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(
+		map[string]string{"Acme": "customer-page-acme"},
+		map[string]string{"Alice@Example.com": "notion-user-alice"},
+	)}
+	slackAPI := &fakeSlack{
+		users:  map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+		posted: make(chan string, 1),
+	}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+
+	title := "Exports are slow"
+	req := submissionRequest(t, map[string]map[string]StateValue{
+		BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+		BlockIDTheme:       {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "Customer Pain Point"}}},
+		BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+		BlockIDComments:    {ActionIDCommentsInput: {Type: "plain_text_input"}},
+		BlockIDCustomerOrg: {ActionIDCustomerOrgSelect: {Type: "multi_external_select", SelectedOptions: []SelectedOption{{Value: "Acme"}}}},
+	})
+	w := httptest.NewRecorder()
+
+	handler.HandleInteractive(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "{}" {
+		t.Fatalf("response = %d %q, want 200 {}", w.Code, w.Body.String())
+	}
+	if len(backend.submissions) != 1 {
+		t.Fatalf("expected 1 submission, got %d", len(backend.submissions))
+	}
+	sub := backend.submissions[0]
+	if sub.Title != title || sub.SubmitterNotionID != "notion-user-alice" {
+		t.Errorf("submission = %+v", sub)
+	}
+	if len(sub.CustomerOrgIDs) != 1 || sub.CustomerOrgIDs[0] != "customer-page-acme" {
+		t.Errorf("CustomerOrgIDs = %v, want [customer-page-acme]", sub.CustomerOrgIDs)
+	}
+	if sub.Source.SlackTeamID != "T456" || sub.Source.SubmitterEmail != "alice@example.com" {
+		t.Errorf("Source = %+v", sub.Source)
+	}
+
+	select {
+	case channel := <-slackAPI.posted:
+		if channel != "U123" {
+			t.Errorf("confirmation posted to %s, want DM to U123", channel)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("confirmation was not posted")
+	}
+}
+
+// TestHandleInteractive_UnknownUser tests that unmapped submitters get an error without a Notion call
+func TestHandleInteractive_UnknownUser(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}
+	slackAPI := &fakeSlack{
+		users:  map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+		posted: make(chan string, 1),
+	}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, submissionRequest(t, nil))
+
+	var response ViewSubmissionResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.ResponseAction != ResponseActionErrors || response.Errors[BlockIDTitle] == "" {
+		t.Errorf("response = %+v, want error on title block", response)
+	}
+	if len(backend.submissions) != 0 {
+		t.Errorf("expected no submissions, got %d", len(backend.submissions))
+	}
+}
+
+// TestHandleInteractive_SchemaInvalid tests that submissions are rejected while the database schema is invalid
+func TestHandleInteractive_SchemaInvalid(t *testing.T) {
+	backend := &fakeBackend{
+		snapshot:  notion.NewCacheSnapshot(nil, map[string]string{"alice@example.com": "notion-alice"}),
+		schemaErr: fmt.Errorf("%w: missing property %q (people)", notion.ErrSchemaMismatch, "Submitted By"),
+	}
+	slackAPI := &fakeSlack{
+		users:  map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+		posted: make(chan string, 1),
+	}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, submissionRequest(t, nil))
+
+	var response ViewSubmissionResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !strings.Contains(response.Errors[BlockIDTitle], "being reconfigured") {
+		t.Errorf("title error = %q, want the reconfiguration message", response.Errors[BlockIDTitle])
+	}
+	if len(backend.submissions) != 0 {
+		t.Errorf("expected no submissions, got %d", len(backend.submissions))
+	}
+}
+
+// TestVerifySlackRequest_UsesClock tests that request age is checked against the injected clock
+func TestVerifySlackRequest_UsesClock(t *testing.T) {
+	handler := NewHandlerWithDependencies(&config.Config{SlackSigningSecret: "secret"}, zap.NewNop(), Dependencies{
+		Backend: &fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)},
+		Slack:   &fakeSlack{},
+		Clock:   fixedClock(time.Now().Add(time.Hour)),
+	})
+
+	req := createValidSlackRequest(http.MethodPost, "/slack/interactive", []byte("payload=%7B%7D"), "secret")
+	if handler.verifySlackRequest(req.Header, []byte("payload=%7B%7D")) {
+		t.Error("expected request signed an hour before the clock to be rejected")
+	}
+}
+
+// TestHandleInteractive_SubmitErrorMessages tests the modal error shown for Notion API failures
+func TestHandleInteractive_SubmitErrorMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "rate limited",
+			err:  &notion.NotionAPIError{Status: http.StatusTooManyRequests, Code: notion.ErrorCodeRateLimited},
+			want: "Notion is busy right now",
+		},
+		{
+			name: "no access",
+			err:  fmt.Errorf("wrapped: %w", &notion.NotionAPIError{Status: http.StatusNotFound, Code: notion.ErrorCodeObjectNotFound}),
+			want: "doesn't have access to the ideas database",
+		},
+		{
+			name: "validation",
+			err:  &notion.NotionAPIError{Status: http.StatusBadRequest, Code: notion.ErrorCodeValidation, Message: "Title is expected to be title."},
+			want: "Notion rejected the idea: Title is expected to be title.",
+		},
+		{
+			name: "unavailable",
+			err:  fmt.Errorf("failed to send request: %w", httpclient.ErrCircuitOpen),
+			want: "Notion is unavailable right now, so your idea wasn't saved",
+		},
+		{
+			name: "other",
+			err:  errors.New("connection reset"),
+			want: "Failed to submit: connection reset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fakeBackend{
+				snapshot: notion.NewCacheSnapshot(
+					map[string]string{"Acme": "customer-page-acme"},
+					map[string]string{"alice@example.com": "notion-user-alice"},
+				),
+				submitErr: tt.err,
+			}
+			slackAPI := &fakeSlack{
+				users:  map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+				posted: make(chan string, 1),
+			}
+			handler := newInteractiveTestHandler(backend, slackAPI)
+
+			title := "Exports are slow"
+			req := submissionRequest(t, map[string]map[string]StateValue{
+				BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+				BlockIDTheme:       {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "Customer Pain Point"}}},
+				BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+				BlockIDComments:    {ActionIDCommentsInput: {Type: "plain_text_input"}},
+				BlockIDCustomerOrg: {ActionIDCustomerOrgSelect: {Type: "multi_external_select", SelectedOptions: []SelectedOption{{Value: "Acme"}}}},
+			})
+			w := httptest.NewRecorder()
+			handler.HandleInteractive(w, req.WithContext(requestid.NewContext(req.Context(), "req-1")))
+
+			var response ViewSubmissionResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !strings.Contains(response.Errors[BlockIDTitle], tt.want) {
+				t.Errorf("title error = %q, want containing %q", response.Errors[BlockIDTitle], tt.want)
+			}
+			if !strings.HasSuffix(response.Errors[BlockIDTitle], "(Reference: req-1)") {
+				t.Errorf("title error = %q, want the request ID as a reference", response.Errors[BlockIDTitle])
+			}
+		})
+	}
+}
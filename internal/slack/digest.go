@@ -0,0 +1,145 @@
+package slack
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// maxDigestIdeasPerArea caps the ideas listed under each product area in a
+// digest; the area's count still includes all of them.
+const maxDigestIdeasPerArea = 10
+
+// digestDateFormat formats the start and end of the digest period.
+const digestDateFormat = "Mon Jan 2"
+
+// digestGroup is a theme or product area in a digest with its ideas.
+type digestGroup struct {
+	name  string
+	ideas []notion.IdeaSummary
+}
+
+// PostDigest posts a digest of the ideas submitted in the last
+// constants.DigestPeriod to channel: how many there were per theme, and the
+// ideas per product area. It runs as a scheduled job (see DIGEST_SCHEDULE).
+// Only the default ideas database is covered, since the channel belongs to the
+// SLACK_BOT_TOKEN workspace.
+func (h *Handler) PostDigest(ctx context.Context, channel string) error {
+	to := h.clock.Now().UTC()
+	from := to.Add(-constants.DigestPeriod)
+
+	ideas, err := h.digestIdeas(from, to)
+	if err != nil {
+		h.recordDigest("failed")
+		return fmt.Errorf("failed to query ideas for digest: %w", err)
+	}
+
+	text := h.formatDigest(ideas, from, to)
+	if _, _, err := h.defaultSlackClient().PostMessageContext(ctx, channel, slack.MsgOptionText(text, false)); err != nil {
+		h.recordSlackAPIError("chat.postMessage", err)
+		h.recordDigest("failed")
+		return fmt.Errorf("failed to post digest: %w", err)
+	}
+
+	h.recordDigest("posted")
+	h.logger.Info("posted submissions digest", zap.String("channel", channel), zap.Int("ideas", len(ideas)))
+	return nil
+}
+
+// digestIdeas returns every idea created in [from, to), oldest first,
+// continuing truncated queries until all are read.
+func (h *Handler) digestIdeas(from, to time.Time) ([]notion.IdeaSummary, error) {
+	filter := notion.IdeasFilter{CreatedAfter: from, CreatedBefore: to}
+	oldestFirst := notion.Sort{Ascending: true}
+
+	var ideas []notion.IdeaSummary
+	cursor := ""
+	for {
+		result, err := h.backend.QueryIdeas(filter, oldestFirst, cursor)
+		if err != nil {
+			return nil, err
+		}
+		ideas = append(ideas, result.Ideas...)
+		if !result.Truncated || result.NextCursor == "" {
+			return ideas, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// formatDigest builds the digest message. Groups are ordered by size, then name.
+func (h *Handler) formatDigest(ideas []notion.IdeaSummary, from, to time.Time) string {
+	period := messages.Params{"count": len(ideas), "from": from.Format(digestDateFormat), "to": to.Format(digestDateFormat)}
+	if len(ideas) == 0 {
+		return h.messages.Format(messages.KeyDigestEmpty, period)
+	}
+
+	unset := h.messages.Format(messages.KeyDigestUnset, nil)
+	byTheme := groupIdeas(ideas, unset, func(idea notion.IdeaSummary) []string { return idea.Themes })
+	byArea := groupIdeas(ideas, unset, func(idea notion.IdeaSummary) []string { return []string{idea.ProductArea} })
+
+	lines := []string{h.messages.Format(messages.KeyDigest, period), "", h.messages.Format(messages.KeyDigestByTheme, nil)}
+	for _, group := range byTheme {
+		lines = append(lines, h.messages.Format(messages.KeyDigestGroup, messages.Params{"name": group.name, "count": len(group.ideas)}))
+	}
+
+	lines = append(lines, "", h.messages.Format(messages.KeyDigestByProductArea, nil))
+	noStatus := h.messages.Format(messages.KeyReminderNoStatus, nil)
+	for _, group := range byArea {
+		lines = append(lines, h.messages.Format(messages.KeyDigestGroup, messages.Params{"name": group.name, "count": len(group.ideas)}))
+		for i, idea := range group.ideas {
+			if i == maxDigestIdeasPerArea {
+				lines = append(lines, h.messages.Format(messages.KeyDigestMore, messages.Params{"count": len(group.ideas) - i}))
+				break
+			}
+			status := idea.Status
+			if status == "" {
+				status = noStatus
+			}
+			lines = append(lines, h.messages.Format(messages.KeyDigestLine, messages.Params{
+				"title": idea.Title, "url": idea.URL, "status": status,
+			}))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// groupIdeas groups ideas by the names keys returns (an idea can be in several
+// groups); ideas without a name go to a group called unset.
+func groupIdeas(ideas []notion.IdeaSummary, unset string, keys func(notion.IdeaSummary) []string) []digestGroup {
+	index := make(map[string]int)
+	var groups []digestGroup
+	for _, idea := range ideas {
+		names := slices.DeleteFunc(slices.Clone(keys(idea)), func(name string) bool { return name == "" })
+		if len(names) == 0 {
+			names = []string{unset}
+		}
+		for _, name := range names {
+			i, ok := index[name]
+			if !ok {
+				i = len(groups)
+				index[name] = i
+				groups = append(groups, digestGroup{name: name})
+			}
+			groups[i].ideas = append(groups[i].ideas, idea)
+		}
+	}
+
+	slices.SortStableFunc(groups, func(a, b digestGroup) int {
+		if c := cmp.Compare(len(b.ideas), len(a.ideas)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.name, b.name)
+	})
+	return groups
+}
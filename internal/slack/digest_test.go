@@ -0,0 +1,73 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"go.uber.org/zap"
+)
+
+// TestPostDigest tests that last week's ideas are counted by theme and listed by product area
+func TestPostDigest(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	backend := &fakeBackend{ideas: &notion.IdeaQueryResult{Ideas: []notion.IdeaSummary{
+		{URL: "https://www.notion.so/sso", Title: "SSO", Status: "Planned", Themes: []string{"New Feature Idea", "Customer Pain Point"}, ProductArea: "Integrations"},
+		{URL: "https://www.notion.so/dark-mode", Title: "Dark mode", Themes: []string{"New Feature Idea"}, ProductArea: "AI/ML"},
+		{URL: "https://www.notion.so/exports", Title: "Exports", Themes: []string{"New Feature Idea"}, ProductArea: "Integrations"},
+		{URL: "https://www.notion.so/untagged", Title: "Untagged"},
+	}}}
+	slackAPI := &fakeSlack{posted: make(chan string, 1), sent: make(chan url.Values, 1)}
+	handler := NewHandlerWithDependencies(&config.Config{}, zap.NewNop(), Dependencies{Backend: backend, Slack: slackAPI, Clock: fixedClock(now)})
+
+	if err := handler.PostDigest(context.Background(), "C-digest"); err != nil {
+		t.Fatalf("PostDigest() error = %v", err)
+	}
+
+	if len(backend.queries) != 1 || !backend.queries[0].CreatedAfter.Equal(now.AddDate(0, 0, -7)) || !backend.queries[0].CreatedBefore.Equal(now) {
+		t.Errorf("queries = %+v, want the last 7 days", backend.queries)
+	}
+	if channel := <-slackAPI.posted; channel != "C-digest" {
+		t.Errorf("posted to %q, want C-digest", channel)
+	}
+	text := (<-slackAPI.sent).Get("text")
+	for _, want := range []string{
+		"*4* ideas were submitted from Mon Oct 12 to Mon Oct 19",
+		"• New Feature Idea: *3*\n• Customer Pain Point: *1*\n• Not set: *1*",
+		"• Integrations: *2*\n    • <https://www.notion.so/sso|SSO> (Planned)\n    • <https://www.notion.so/exports|Exports> (not triaged yet)",
+		"• AI/ML: *1*\n    • <https://www.notion.so/dark-mode|Dark mode>",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("digest = %q, want containing %q", text, want)
+		}
+	}
+}
+
+// TestPostDigest_EmptyAndFailures tests the digest of a quiet week and failed queries
+func TestPostDigest_EmptyAndFailures(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	backend := &fakeBackend{ideas: &notion.IdeaQueryResult{}}
+	slackAPI := &fakeSlack{posted: make(chan string, 1), sent: make(chan url.Values, 1)}
+	handler := NewHandlerWithDependencies(&config.Config{}, zap.NewNop(), Dependencies{Backend: backend, Slack: slackAPI, Clock: fixedClock(now)})
+
+	if err := handler.PostDigest(context.Background(), "C-digest"); err != nil {
+		t.Fatalf("PostDigest() error = %v", err)
+	}
+	<-slackAPI.posted
+	if text := (<-slackAPI.sent).Get("text"); !strings.Contains(text, "no ideas were submitted") {
+		t.Errorf("digest = %q, want the empty digest", text)
+	}
+
+	backend.ideasErr = errors.New("notion unavailable")
+	if err := handler.PostDigest(context.Background(), "C-digest"); err == nil {
+		t.Error("expected error when ideas can't be queried")
+	}
+	if len(slackAPI.posted) != 0 {
+		t.Error("a digest was posted although the query failed")
+	}
+}
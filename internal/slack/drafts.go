@@ -0,0 +1,211 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/drafts"
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// draftMessageTimeout bounds each DM about a shared draft.
+const draftMessageTimeout = 10 * time.Second
+
+// SetDraftStore enables sharing drafts between teammates.
+// The "Share draft" select is only shown in the modal once a store is set.
+func (h *Handler) SetDraftStore(store drafts.Store) {
+	h.drafts = store
+}
+
+// handleDraftAction handles the draft actions: picking a teammate in the
+// modal's "Share draft" select, clicking "Open draft" in a DM and discarding
+// an autosaved draft. It reports whether the payload was a draft action.
+func (h *Handler) handleDraftAction(ctx context.Context, w http.ResponseWriter, payload *InteractionPayload) bool {
+	if h.drafts == nil {
+		return false
+	}
+	for _, action := range payload.Actions {
+		switch action.ActionID {
+		case ActionIDShareDraft:
+			// DMs can take a while; Slack only waits 3 seconds for the acknowledgement
+			selected := action.SelectedUser
+			h.background("share_draft", func() { h.shareDraft(context.WithoutCancel(ctx), payload, selected) })
+			h.recordSlackInteraction(payload.Type, ActionIDShareDraft, "success")
+			w.WriteHeader(http.StatusOK)
+			return true
+		case ActionIDOpenDraft:
+			// Opened synchronously: the trigger ID expires after 3 seconds
+			h.openDraft(ctx, payload, action.Value)
+			w.WriteHeader(http.StatusOK)
+			return true
+		case ActionIDDiscardDraft:
+			h.background("discard_draft", func() { h.discardAutosavedDraft(context.WithoutCancel(ctx), payload) })
+			w.WriteHeader(http.StatusOK)
+			return true
+		}
+	}
+	return false
+}
+
+// shareDraft saves the modal's current values as a draft and DMs the
+// recipient and the author an "Open draft" button.
+func (h *Handler) shareDraft(ctx context.Context, payload *InteractionPayload, recipientID string) {
+	teamID, authorID := payload.Team.ID, payload.User.ID
+	logger := logging.FromContext(ctx, h.logger)
+	if recipientID == "" {
+		return // Selection cleared
+	}
+	if recipientID == authorID {
+		h.postDraftMessage(ctx, teamID, authorID, h.messages.Format(messages.KeyDraftShareSelf, nil), "")
+		return
+	}
+
+	id, err := drafts.NewID()
+	if err != nil {
+		logger.Error("failed to share draft", zap.Error(err))
+		h.postDraftMessage(ctx, teamID, authorID, h.messages.Format(messages.KeyDraftShareFailed, nil), "")
+		return
+	}
+	now := h.clock.Now().UTC()
+	draft := draftFromState(payload.View.State)
+	draft.ID = id
+	draft.SlackTeamID = teamID
+	draft.AuthorID = authorID
+	draft.RecipientID = recipientID
+	draft.CreatedAt = now
+	draft.ExpiresAt = now.Add(constants.DraftTTL)
+
+	if err := h.drafts.Save(draft); err != nil {
+		logger.Error("failed to save shared draft",
+			zap.String("author", authorID),
+			zap.String("recipient", recipientID),
+			zap.Error(err),
+		)
+		h.postDraftMessage(ctx, teamID, authorID, h.messages.Format(messages.KeyDraftShareFailed, nil), "")
+		return
+	}
+
+	title := draft.Title
+	if title == "" {
+		title = h.messages.Format(messages.KeyDraftUntitled, nil)
+	}
+	h.postDraftMessage(ctx, teamID, recipientID, h.messages.Format(messages.KeyDraftShared, messages.Params{
+		"author":  authorID,
+		"title":   title,
+		"expires": h.FormatTimeForUser(recipientID, draft.ExpiresAt),
+	}), draft.ID)
+	h.postDraftMessage(ctx, teamID, authorID, h.messages.Format(messages.KeyDraftShareSent, messages.Params{
+		"recipient": recipientID,
+		"title":     title,
+		"expires":   h.FormatTimeForUser(authorID, draft.ExpiresAt),
+	}), draft.ID)
+
+	logger.Info("draft shared",
+		zap.String("draft_id", draft.ID),
+		zap.String("author", authorID),
+		zap.String("recipient", recipientID),
+	)
+}
+
+// openDraft opens the submission modal pre-filled from a draft, if the user
+// may open it. Otherwise the user gets a DM explaining why.
+func (h *Handler) openDraft(ctx context.Context, payload *InteractionPayload, draftID string) {
+	teamID, userID := payload.Team.ID, payload.User.ID
+	logger := logging.FromContext(ctx, h.logger)
+
+	draft, found, err := h.drafts.Get(draftID, h.clock.Now())
+	if err != nil {
+		// Get only fails persisting the removal of an expired draft
+		logger.Warn("failed to drop expired draft", zap.String("draft_id", draftID), zap.Error(err))
+	}
+	if !found {
+		h.recordSlackInteraction(payload.Type, ActionIDOpenDraft, "expired")
+		h.postDraftMessage(ctx, teamID, userID, h.messages.Format(messages.KeyDraftExpired, nil), "")
+		return
+	}
+	if !draft.CanOpen(teamID, userID) {
+		logger.Warn("rejecting draft opened by another user",
+			zap.String("draft_id", draftID),
+			zap.String("user", userID),
+		)
+		h.recordSlackInteraction(payload.Type, ActionIDOpenDraft, "forbidden")
+		h.postDraftMessage(ctx, teamID, userID, h.messages.Format(messages.KeyDraftNotShared, nil), "")
+		return
+	}
+
+	modal := h.submissionModalWith(teamID, draft.ID, ModalValues{
+		Title:       draft.Title,
+		Theme:       draft.Theme,
+		ProductArea: draft.ProductArea,
+		Comments:    draft.Comments,
+		Customers:   draft.CustomerOrgs,
+	})
+
+	if _, err := h.slackFor(teamID).OpenView(payload.TriggerID, modal); err != nil {
+		h.recordSlackAPIError("views.open", err)
+		logger.Error("failed to open draft", zap.String("draft_id", draftID), zap.Error(err))
+		h.recordSlackInteraction(payload.Type, ActionIDOpenDraft, "error")
+		return
+	}
+	h.recordSlackInteraction(payload.Type, ActionIDOpenDraft, "success")
+}
+
+// deleteSubmittedDraft removes the draft a submitted modal was opened from, if any.
+func (h *Handler) deleteSubmittedDraft(payload *InteractionPayload) {
+	draftID := decodeModalMetadata(payload.View.PrivateMetadata).ID
+	if h.drafts == nil || draftID == "" || payload.View.CallbackID != ModalCallbackIDSubmitForm {
+		return
+	}
+	if err := h.drafts.Delete(draftID); err != nil {
+		h.logger.Warn("failed to delete submitted draft", zap.String("draft_id", draftID), zap.Error(err))
+	}
+}
+
+// postDraftMessage DMs a user, with an "Open draft" button when draftID is set.
+// Failures are logged.
+func (h *Handler) postDraftMessage(ctx context.Context, teamID, userID, text, draftID string) {
+	options := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if draftID != "" {
+		button := slack.NewButtonBlockElement(ActionIDOpenDraft, draftID, newPlainText(ButtonOpenDraft))
+		button.Style = slack.StylePrimary
+		options = append(options, slack.MsgOptionBlocks(
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+			slack.NewActionBlock(BlockIDDraftActions, button),
+		))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, draftMessageTimeout)
+	defer cancel()
+	if _, _, err := h.slackFor(teamID).PostMessageContext(ctx, userID, options...); err != nil {
+		h.recordSlackAPIError("chat.postMessage", err)
+		logging.FromContext(ctx, h.logger).Error("failed to send draft message", zap.String("user", userID), zap.Error(err))
+	}
+}
+
+// draftFromState reads the core fields filled in so far. Unlike submission,
+// nothing is required or validated: the recipient finishes the draft.
+func draftFromState(state ViewState) drafts.Draft {
+	var draft drafts.Draft
+	if title, err := state.GetValue(BlockIDTitle, ActionIDTitleInput); err == nil {
+		draft.Title = strings.TrimSpace(title)
+	}
+	if comments, err := state.GetValue(BlockIDComments, ActionIDCommentsInput); err == nil {
+		draft.Comments = strings.TrimSpace(comments)
+	}
+	draft.Theme, _ = state.GetSelectedOption(BlockIDTheme, ActionIDThemeSelect)
+	draft.ProductArea, _ = state.GetSelectedOption(BlockIDProductArea, ActionIDProductAreaSelect)
+	draft.CustomerOrgs, _ = state.GetSelectedOptions(BlockIDCustomerOrg, ActionIDCustomerOrgSelect)
+	return draft
+}
+
+// buildShareDraftBlock builds the "Share draft" user select of the submission modal.
+func buildShareDraftBlock() *slack.ActionBlock {
+	element := slack.NewOptionsSelectBlockElement(slack.OptTypeUser, newPlainText(PlaceholderShareDraft), ActionIDShareDraft)
+	return slack.NewActionBlock(BlockIDShareDraft, element)
+}
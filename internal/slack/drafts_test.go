@@ -0,0 +1,208 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/drafts"
+	"github.com/rudderlabs/hopperbot/pkg/featureflags"
+	"github.com/slack-go/slack"
+)
+
+func draftActionRequest(t *testing.T, userID string, view View, action Action) *http.Request {
+	t.Helper()
+	payload, err := json.Marshal(InteractionPayload{
+		Type:    InteractionTypeBlockActions,
+		User:    User{ID: userID},
+		Team:    Team{ID: "T456"},
+		View:    view,
+		Actions: []Action{action},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	return createValidSlackRequest(http.MethodPost, "/slack/interactive", []byte(body), "secret")
+}
+
+func newDraftTestHandler(t *testing.T, slackAPI *fakeSlack) (*Handler, *drafts.FileStore) {
+	t.Helper()
+	store, err := drafts.NewFileStore("")
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	handler := newInteractiveTestHandler(&fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}, slackAPI)
+	handler.SetDraftStore(store)
+	return handler, store
+}
+
+// TestHandleInteractive_ShareDraft tests that sharing saves the modal's values and DMs both users
+func TestHandleInteractive_ShareDraft(t *testing.T) {
+	slackAPI := &fakeSlack{
+		posted: make(chan string, 2),
+		sent:   make(chan url.Values, 2),
+	}
+	handler, store := newDraftTestHandler(t, slackAPI)
+
+	title := "Exports are slow"
+	view := View{CallbackID: ModalCallbackIDSubmitForm, State: ViewState{Values: map[string]map[string]StateValue{
+		BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+		BlockIDCustomerOrg: {ActionIDCustomerOrgSelect: {Type: "multi_external_select", SelectedOptions: []SelectedOption{{Value: "Acme"}}}},
+	}}}
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, draftActionRequest(t, "U123", view, Action{
+		ActionID:     ActionIDShareDraft,
+		BlockID:      BlockIDShareDraft,
+		SelectedUser: "U789",
+	}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var draftID string
+	recipients := map[string]bool{}
+	for range 2 {
+		select {
+		case values := <-slackAPI.sent:
+			recipients[values.Get("channel")] = true
+			blocks := values.Get("blocks")
+			if !strings.Contains(blocks, ActionIDOpenDraft) || !strings.Contains(values.Get("text"), title) {
+				t.Errorf("draft DM = %v, want the title and an Open draft button", values)
+			}
+			var parsed []struct {
+				Elements []struct {
+					Value string `json:"value"`
+				} `json:"elements"`
+			}
+			json.Unmarshal([]byte(blocks), &parsed)
+			for _, block := range parsed {
+				for _, element := range block.Elements {
+					draftID = element.Value
+				}
+			}
+			<-slackAPI.posted
+		case <-time.After(time.Second):
+			t.Fatal("draft DMs were not sent")
+		}
+	}
+	if !recipients["U123"] || !recipients["U789"] {
+		t.Errorf("draft DMs sent to %v, want U123 and U789", recipients)
+	}
+
+	draft, ok, _ := store.Get(draftID, time.Now())
+	if !ok {
+		t.Fatalf("draft %q not saved", draftID)
+	}
+	if draft.Title != title || draft.AuthorID != "U123" || draft.RecipientID != "U789" || len(draft.CustomerOrgs) != 1 {
+		t.Errorf("saved draft = %+v", draft)
+	}
+}
+
+// TestHandleInteractive_OpenDraft tests that only the two users can open a draft, pre-filled
+func TestHandleInteractive_OpenDraft(t *testing.T) {
+	slackAPI := &fakeSlack{
+		posted: make(chan string, 1),
+		opened: make(chan slack.ModalViewRequest, 1),
+	}
+	handler, store := newDraftTestHandler(t, slackAPI)
+	now := time.Now()
+	store.Save(drafts.Draft{
+		ID:          "draft-1",
+		SlackTeamID: "T456",
+		AuthorID:    "U123",
+		RecipientID: "U789",
+		Title:       "Exports are slow",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	})
+	open := Action{ActionID: ActionIDOpenDraft, BlockID: BlockIDDraftActions, Value: "draft-1"}
+
+	handler.HandleInteractive(httptest.NewRecorder(), draftActionRequest(t, "U789", View{}, open))
+	select {
+	case modal := <-slackAPI.opened:
+		if metadata := decodeModalMetadata(modal.PrivateMetadata); metadata.ID != "draft-1" || metadata.Values == nil {
+			t.Errorf("PrivateMetadata = %q, want draft-1 and its values", modal.PrivateMetadata)
+		}
+		data, _ := json.Marshal(modal)
+		if !strings.Contains(string(data), "Exports are slow") {
+			t.Error("modal is not pre-filled with the draft's title")
+		}
+	default:
+		t.Fatal("draft was not opened for the recipient")
+	}
+
+	handler.HandleInteractive(httptest.NewRecorder(), draftActionRequest(t, "U999", View{}, open))
+	select {
+	case <-slackAPI.opened:
+		t.Error("draft opened for a user it wasn't shared with")
+	case channel := <-slackAPI.posted:
+		if channel != "U999" {
+			t.Errorf("rejection sent to %s, want U999", channel)
+		}
+	}
+}
+
+// TestHandleInteractive_OpenExpiredDraft tests that expired drafts are not opened
+func TestHandleInteractive_OpenExpiredDraft(t *testing.T) {
+	slackAPI := &fakeSlack{
+		posted: make(chan string, 1),
+		sent:   make(chan url.Values, 1),
+		opened: make(chan slack.ModalViewRequest, 1),
+	}
+	handler, store := newDraftTestHandler(t, slackAPI)
+	now := time.Now()
+	store.Save(drafts.Draft{
+		ID:          "draft-1",
+		SlackTeamID: "T456",
+		AuthorID:    "U123",
+		RecipientID: "U789",
+		CreatedAt:   now.Add(-2 * time.Hour),
+		ExpiresAt:   now.Add(-time.Hour),
+	})
+
+	handler.HandleInteractive(httptest.NewRecorder(), draftActionRequest(t, "U789", View{}, Action{
+		ActionID: ActionIDOpenDraft,
+		BlockID:  BlockIDDraftActions,
+		Value:    "draft-1",
+	}))
+	select {
+	case <-slackAPI.opened:
+		t.Error("expired draft was opened")
+	case values := <-slackAPI.sent:
+		if !strings.Contains(values.Get("text"), "expired") {
+			t.Errorf("message = %q, want the draft reported expired", values.Get("text"))
+		}
+	}
+	if store.Len() != 0 {
+		t.Error("expired draft was not dropped")
+	}
+}
+
+// TestSubmissionModal_DraftsFlag tests that the drafts feature flag hides the "Share draft" select
+func TestSubmissionModal_DraftsFlag(t *testing.T) {
+	handler, _ := newDraftTestHandler(t, &fakeSlack{})
+	flags := featureflags.New()
+	handler.SetFeatureFlags(flags)
+
+	hasShareDraft := func() bool {
+		for _, block := range handler.submissionModal("T456").Blocks.BlockSet {
+			if action, ok := block.(*slack.ActionBlock); ok && action.BlockID == BlockIDShareDraft {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasShareDraft() {
+		t.Fatal("modal has no Share draft select with drafts enabled")
+	}
+	flags.Set(map[string]bool{featureflags.Drafts: false})
+	if hasShareDraft() {
+		t.Error("modal has a Share draft select with the drafts flag off")
+	}
+}
@@ -0,0 +1,239 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/analytics"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/store"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// editIdeasLimit is how many recent submissions /hopperbot edit offers to edit.
+const editIdeasLimit = 25
+
+// slackMaxOptionTextLength is Slack's limit on the text of a select option.
+const slackMaxOptionTextLength = 75
+
+// handleEditCommand handles /hopperbot edit, opening a modal to pick one of the
+// user's recent submissions. Submitting it replaces the modal with the
+// submission form pre-filled from the Notion page (see handleEditSelection).
+func (h *Handler) handleEditCommand(w http.ResponseWriter, teamID, userID, triggerID, command string) {
+	if triggerID == "" {
+		h.logger.Error("trigger_id is empty")
+		h.recordSlackCommand(command, "error")
+		respondToSlack(w, h.messages.Format(messages.KeyMissingTriggerID, nil))
+		return
+	}
+
+	notionUserID, message := h.editorNotionUserID(teamID, userID, h.cacheFor(teamID).Snapshot())
+	if message != "" {
+		h.recordSlackCommand(command, "error")
+		respondToSlack(w, message)
+		return
+	}
+
+	result, err := h.backendFor(teamID).SubmitterIdeas(notionUserID, editIdeasLimit)
+	if err != nil {
+		h.logger.Error("failed to query submitted ideas for editing",
+			zap.String("user_id", userID),
+			zap.String("notion_user_id", notionUserID),
+			zap.Error(err),
+		)
+		h.recordSlackCommand(command, "error")
+		respondToSlack(w, h.messages.Format(messages.KeyEditLookupFailed, nil))
+		return
+	}
+	if len(result.Ideas) == 0 {
+		h.recordSlackCommand(command, "success")
+		respondToSlack(w, h.messages.Format(messages.KeyEditNoIdeas, nil))
+		return
+	}
+
+	if _, err := h.slackFor(teamID).OpenView(triggerID, buildEditSelectModal(result.Ideas)); err != nil {
+		h.recordSlackAPIError("views.open", err)
+		h.logger.Error("failed to open edit modal", zap.String("user_id", userID), zap.Error(err))
+		h.recordSlackCommand(command, "error")
+		respondToSlack(w, h.messages.Format(messages.KeyOpenModalFailed, nil))
+		return
+	}
+
+	h.recordSlackCommand(command, "success")
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleEditSelection handles submission of the idea picker: it loads the
+// selected page and replaces the modal with the pre-filled edit form.
+// Only the page's submitters may edit it.
+func (h *Handler) handleEditSelection(w http.ResponseWriter, payload *InteractionPayload) {
+	reject := func(outcome, message string) {
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, outcome)
+		respondWithErrors(w, map[string]string{BlockIDEditIdea: message})
+	}
+
+	snapshot := h.cacheFor(payload.Team.ID).Snapshot()
+	notionUserID, message := h.editorNotionUserID(payload.Team.ID, payload.User.ID, snapshot)
+	if message != "" {
+		reject("user_not_found", message)
+		return
+	}
+
+	pageID, err := payload.View.State.GetSelectedOption(BlockIDEditIdea, ActionIDEditIdeaSelect)
+	if err != nil || pageID == "" {
+		reject("validation_error", h.messages.Format(messages.KeyFieldRequired, messages.Params{"field": "Idea"}))
+		return
+	}
+
+	page, err := h.backendFor(payload.Team.ID).GetSubmission(pageID)
+	if err != nil {
+		h.logger.Error("failed to load idea for editing", zap.String("page_id", pageID), zap.Error(err))
+		reject("notion_error", h.messages.Format(messages.KeyEditLoadFailed, messages.Params{"error": err}))
+		return
+	}
+	if page.Archived {
+		reject("archived", h.messages.Format(messages.KeyEditIdeaRemoved, nil))
+		return
+	}
+	if !slices.Contains(page.Submitters, notionUserID) {
+		h.logger.Warn("user tried to edit an idea they didn't submit",
+			zap.String("user_id", payload.User.ID),
+			zap.String("page_id", pageID),
+		)
+		reject("not_owner", h.messages.Format(messages.KeyEditNotOwner, nil))
+		return
+	}
+
+	h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "success")
+	respondWithView(w, ResponseActionUpdate, h.editModal(payload.Team.ID, page, snapshot))
+}
+
+// updateSubmission writes an edited submission to the page the edit form was
+// opened for (stored in the view's private metadata).
+func (h *Handler) updateSubmission(ctx context.Context, w http.ResponseWriter, payload *InteractionPayload, sub submission.Submission) {
+	pageID := decodeModalMetadata(payload.View.PrivateMetadata).ID
+	logger := logging.FromContext(ctx, h.logger)
+
+	start := time.Now()
+	page, err := h.backendFor(payload.Team.ID).UpdateSubmission(ctx, pageID, sub)
+	recorded := page
+	if recorded == nil {
+		recorded = &notion.CreatedPage{ID: pageID}
+	}
+	h.recordAttempt(store.ActionEdit, sub, recorded, err, time.Since(start))
+	if err != nil {
+		logger.Error("failed to update idea in Notion", zap.String("page_id", pageID), zap.Error(err))
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "notion_error")
+		h.recordModalSubmission("error")
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, &sub, "notion_error")
+		respondWithErrors(w, map[string]string{
+			BlockIDTitle: h.withReference(ctx, h.messages.Format(messages.KeyUpdateFailed, messages.Params{"error": err})),
+		})
+		return
+	}
+
+	logger.Info("successfully updated idea in Notion",
+		zap.String("user", payload.User.Username),
+		zap.String("page_id", page.ID),
+		zap.String("page_url", page.URL),
+	)
+	h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "success")
+	h.recordModalSubmission("updated")
+	h.trackSubmission(analytics.EventSubmissionUpdated, payload, &sub, "success")
+	h.respondSuccess(w)
+
+	// Copy the edit to secondary backends after closing the modal; failures are retried in the background
+	if h.fanout != nil {
+		h.background("fanout_edit", func() { h.fanoutWrite(context.WithoutCancel(ctx), sub, page) })
+	}
+}
+
+// editorNotionUserID maps the Slack user to their Notion user. If that fails,
+// it returns the message to show the user instead.
+func (h *Handler) editorNotionUserID(teamID, userID string, snapshot *notion.CacheSnapshot) (notionUserID, message string) {
+	notionUserID, profile, found, err := h.slackUserNotionID(teamID, userID, snapshot)
+	if err != nil {
+		h.logger.Error("failed to fetch Slack user info for editing", zap.String("user_id", userID), zap.Error(err))
+		return "", h.messages.Format(messages.KeyUserLookupFailed, nil)
+	}
+	if !found {
+		return "", h.messages.Format(messages.KeyEditUserNotFound, messages.Params{"email": profile.Email})
+	}
+	return notionUserID, ""
+}
+
+// buildEditSelectModal builds the idea picker opened by /hopperbot edit, listing
+// the given ideas newest first.
+func buildEditSelectModal(ideas []notion.IdeaSummary) slack.ModalViewRequest {
+	options := make([]*slack.OptionBlockObject, 0, len(ideas))
+	for _, idea := range ideas {
+		title := idea.Title
+		if title == "" {
+			title = idea.ID
+		}
+		if runes := []rune(title); len(runes) > slackMaxOptionTextLength {
+			title = string(runes[:slackMaxOptionTextLength-1]) + "…"
+		}
+		options = append(options, slack.NewOptionBlockObject(idea.ID, newPlainText(title), nil))
+	}
+
+	element := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, newPlainText(PlaceholderEditIdea), ActionIDEditIdeaSelect, options...)
+	block := slack.NewInputBlock(BlockIDEditIdea, newPlainText(LabelEditIdea), nil, element)
+
+	return slack.ModalViewRequest{
+		Type:       slack.VTModal,
+		CallbackID: ModalCallbackIDEditSelect,
+		Title:      newPlainText(ModalTitleEdit),
+		Submit:     newPlainText(ModalNextText),
+		Close:      newPlainText(ModalCancelText),
+		Blocks:     slack.Blocks{BlockSet: []slack.Block{block}},
+	}
+}
+
+// editModal builds the edit form for a page: the core submission fields,
+// pre-filled with the page's current values. Fields generated from the
+// database schema and Artifacts are left out, so editing never touches those
+// properties.
+func (h *Handler) editModal(teamID string, page *notion.SubmissionPage, snapshot *notion.CacheSnapshot) slack.ModalViewRequest {
+	fields := slices.DeleteFunc(slices.Clone(h.modalFields()), func(field ModalField) bool {
+		return !field.IsCore() || field.Property == constants.FieldArtifacts
+	})
+
+	modal := BuildSubmissionModalFromFields(fields, h.currentFormRules(), h.fieldLimits())
+	modal.CallbackID = ModalCallbackIDEditForm
+	modal.Title = newPlainText(ModalTitleEdit)
+	modal.Submit = newPlainText(ModalSaveText)
+	modal.Blocks.BlockSet[0] = slack.NewContextBlock("info_block", slack.NewTextBlockObject(slack.MarkdownType, EditInfoText, false, false))
+
+	var customers []string
+	for _, pageID := range page.Submission.CustomerOrgIDs {
+		if name, found := snapshot.CustomerName(pageID); found {
+			customers = append(customers, name)
+		}
+	}
+	prefillModal(&modal, page.ID, modalValuesFromSubmission(page.Submission, customers))
+	h.useStaticCustomerSelects(teamID, modal.Blocks.BlockSet)
+
+	return modal
+}
+
+// respondWithView replaces the submitted modal with view (ResponseActionUpdate)
+// or pushes view on top of it (ResponseActionPush).
+func respondWithView(w http.ResponseWriter, action ResponseAction, view slack.ModalViewRequest) {
+	response := struct {
+		ResponseAction ResponseAction         `json:"response_action"`
+		View           slack.ModalViewRequest `json:"view"`
+	}{action, view}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
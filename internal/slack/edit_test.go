@@ -0,0 +1,243 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+)
+
+// editTestSnapshot maps alice to a Notion user and has one customer
+var editTestSnapshot = notion.NewCacheSnapshot(
+	map[string]string{"Acme": "customer-page-acme"},
+	map[string]string{"alice@example.com": "notion-alice"},
+)
+
+func editTestSlack() *fakeSlack {
+	return &fakeSlack{
+		users: map[string]*slack.User{
+			"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}},
+			"U999": {ID: "U999", Profile: slack.UserProfile{Email: "mallory@example.com"}},
+		},
+		opened: make(chan slack.ModalViewRequest, 1),
+	}
+}
+
+func editInteractionRequest(t *testing.T, view View) *http.Request {
+	t.Helper()
+	payload, err := json.Marshal(InteractionPayload{
+		Type: InteractionTypeViewSubmission,
+		User: User{ID: "U123", Username: "alice"},
+		Team: Team{ID: "T456"},
+		View: view,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	return createValidSlackRequest(http.MethodPost, "/slack/interactive", []byte(body), "secret")
+}
+
+// TestHandleSlashCommand_Edit tests that /hopperbot edit lists the user's ideas
+func TestHandleSlashCommand_Edit(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		ideas       *notion.IdeaQueryResult
+		wantOptions []string
+		wantText    string
+	}{
+		{
+			name:   "ideas",
+			userID: "U123",
+			ideas: &notion.IdeaQueryResult{Total: 2, Ideas: []notion.IdeaSummary{
+				{ID: "page-1", Title: "Dark mode"},
+				{ID: "page-2", Title: strings.Repeat("a", 100)},
+			}},
+			wantOptions: []string{"Dark mode", strings.Repeat("a", 74) + "…"},
+		},
+		{
+			name:     "no ideas",
+			userID:   "U123",
+			ideas:    &notion.IdeaQueryResult{},
+			wantText: "nothing to edit",
+		},
+		{
+			name:     "unmapped user",
+			userID:   "U999",
+			wantText: "Your Slack email (mallory@example.com) is not associated with a Notion account",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slackAPI := editTestSlack()
+			handler := newInteractiveTestHandler(&fakeBackend{snapshot: editTestSnapshot, ideas: tt.ideas}, slackAPI)
+
+			body := url.Values{"command": {"/hopperbot"}, "text": {"edit"}, "user_id": {tt.userID}, "trigger_id": {"trigger-1"}}.Encode()
+			w := httptest.NewRecorder()
+			handler.HandleSlashCommand(w, createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+
+			if tt.wantText != "" {
+				if !strings.Contains(w.Body.String(), tt.wantText) {
+					t.Errorf("response = %q, want it to contain %q", w.Body.String(), tt.wantText)
+				}
+				return
+			}
+
+			var modal slack.ModalViewRequest
+			select {
+			case modal = <-slackAPI.opened:
+			default:
+				t.Fatal("edit modal was not opened")
+			}
+			if modal.CallbackID != ModalCallbackIDEditSelect {
+				t.Errorf("callback ID = %q, want %q", modal.CallbackID, ModalCallbackIDEditSelect)
+			}
+			element := modal.Blocks.BlockSet[0].(*slack.InputBlock).Element.(*slack.SelectBlockElement)
+			if len(element.Options) != len(tt.wantOptions) {
+				t.Fatalf("got %d options, want %d", len(element.Options), len(tt.wantOptions))
+			}
+			for i, want := range tt.wantOptions {
+				if element.Options[i].Text.Text != want {
+					t.Errorf("option %d = %q, want %q", i, element.Options[i].Text.Text, want)
+				}
+			}
+		})
+	}
+}
+
+// TestHandleInteractive_EditSelection tests that picking an idea replaces the modal with the pre-filled edit form
+func TestHandleInteractive_EditSelection(t *testing.T) {
+	page := &notion.SubmissionPage{
+		ID:         "page-1",
+		Submitters: []string{"notion-alice"},
+		Submission: submission.Submission{
+			Title:          "Dark mode",
+			Theme:          "New Feature Idea",
+			ProductArea:    "AI/ML",
+			Comments:       "Please",
+			CustomerOrgIDs: []string{"customer-page-acme"},
+		},
+	}
+	tests := []struct {
+		name      string
+		page      *notion.SubmissionPage
+		wantError string
+	}{
+		{name: "owner", page: page},
+		{name: "not owner", page: &notion.SubmissionPage{ID: "page-1", Submitters: []string{"notion-bob"}}, wantError: "only edit ideas you submitted"},
+		{name: "removed", page: &notion.SubmissionPage{ID: "page-1", Archived: true, Submitters: []string{"notion-alice"}}, wantError: "removed from Notion"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fakeBackend{snapshot: editTestSnapshot, pages: map[string]*notion.SubmissionPage{"page-1": tt.page}}
+			handler := newInteractiveTestHandler(backend, editTestSlack())
+
+			w := httptest.NewRecorder()
+			handler.HandleInteractive(w, editInteractionRequest(t, View{
+				CallbackID: ModalCallbackIDEditSelect,
+				State: ViewState{Values: map[string]map[string]StateValue{
+					BlockIDEditIdea: {ActionIDEditIdeaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "page-1"}}},
+				}},
+			}))
+
+			var response struct {
+				ResponseAction ResponseAction         `json:"response_action"`
+				Errors         map[string]string      `json:"errors"`
+				View           slack.ModalViewRequest `json:"view"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if tt.wantError != "" {
+				if !strings.Contains(response.Errors[BlockIDEditIdea], tt.wantError) {
+					t.Errorf("errors = %v, want %q on the idea block", response.Errors, tt.wantError)
+				}
+				return
+			}
+
+			if response.ResponseAction != ResponseActionUpdate {
+				t.Fatalf("response action = %q, want %q", response.ResponseAction, ResponseActionUpdate)
+			}
+			view := response.View
+			if view.CallbackID != ModalCallbackIDEditForm || decodeModalMetadata(view.PrivateMetadata).ID != "page-1" {
+				t.Errorf("callback ID = %q, private metadata = %q", view.CallbackID, view.PrivateMetadata)
+			}
+
+			initial := make(map[string]string)
+			for _, block := range view.Blocks.BlockSet {
+				input, ok := block.(*slack.InputBlock)
+				if !ok {
+					continue
+				}
+				switch element := input.Element.(type) {
+				case *slack.PlainTextInputBlockElement:
+					initial[input.BlockID] = element.InitialValue
+				case *slack.SelectBlockElement:
+					if element.InitialOption != nil {
+						initial[input.BlockID] = element.InitialOption.Value
+					}
+				case *slack.MultiSelectBlockElement:
+					for _, option := range element.InitialOptions {
+						initial[input.BlockID] += option.Value
+					}
+				}
+			}
+			want := map[string]string{
+				BlockIDTitle:       "Dark mode",
+				BlockIDTheme:       "New Feature Idea",
+				BlockIDProductArea: "AI/ML",
+				BlockIDComments:    "Please",
+				BlockIDCustomerOrg: "Acme",
+			}
+			for blockID, value := range want {
+				if initial[blockID] != value {
+					t.Errorf("%s initial value = %q, want %q", blockID, initial[blockID], value)
+				}
+			}
+		})
+	}
+}
+
+// TestHandleInteractive_EditSubmission tests that the edit form updates the page instead of creating one
+func TestHandleInteractive_EditSubmission(t *testing.T) {
+	backend := &fakeBackend{snapshot: editTestSnapshot}
+	handler := newInteractiveTestHandler(backend, editTestSlack())
+
+	title := "Dark mode everywhere"
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, editInteractionRequest(t, View{
+		CallbackID:      ModalCallbackIDEditForm,
+		PrivateMetadata: "page-1",
+		State: ViewState{Values: map[string]map[string]StateValue{
+			BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+			BlockIDTheme:       {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "New Feature Idea"}}},
+			BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+			BlockIDComments:    {ActionIDCommentsInput: {Type: "plain_text_input"}},
+			BlockIDCustomerOrg: {ActionIDCustomerOrgSelect: {Type: "multi_external_select"}},
+		}},
+	}))
+
+	if w.Code != http.StatusOK || w.Body.String() != "{}" {
+		t.Fatalf("response = %d %q, want 200 {}", w.Code, w.Body.String())
+	}
+	if len(backend.submissions) != 0 {
+		t.Errorf("expected no new pages, got %d", len(backend.submissions))
+	}
+	update, ok := backend.updates["page-1"]
+	if !ok {
+		t.Fatal("page-1 was not updated")
+	}
+	if update.Title != title || update.SubmitterNotionID != "notion-alice" {
+		t.Errorf("update = %+v", update)
+	}
+}
@@ -0,0 +1,111 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// maxRecentEvents is how many event IDs are remembered to drop Slack's retries
+// of events that were already dispatched.
+const maxRecentEvents = 1000
+
+// EventHandler handles one Events API event type. Handlers run in the
+// background once the request has been acknowledged, so they get their own
+// context rather than the request's.
+type EventHandler func(ctx context.Context, req EventRequest)
+
+// newEventHandlers registers the handled Events API event types. A new event
+// only needs an entry here (and a bot event subscription, see pkg/manifest):
+// verification, acknowledgement, retries and metrics come from HandleEvents.
+func (h *Handler) newEventHandlers() map[string]EventHandler {
+	return map[string]EventHandler{
+		EventTypeAppHomeOpened:   h.handleAppHomeOpened,
+		EventTypeReactionAdded:   h.handleReactionEvent,
+		EventTypeReactionRemoved: h.handleReactionEvent,
+	}
+}
+
+// HandleEvents handles Events API requests: the url_verification handshake,
+// and event_callback requests, which are dispatched by event type to the
+// handlers registered in newEventHandlers. Events of other types are ignored.
+//
+// Slack expects an acknowledgement within 3 seconds and retries otherwise, so
+// events are acknowledged right away and handled in the background; retries
+// of an event that was already dispatched are acknowledged and dropped.
+func (h *Handler) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := h.readSlackRequestBody(w, r)
+	if !ok {
+		return
+	}
+
+	var req EventRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		h.handleError(r.Context(), w, err, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Type == EventRequestURLVerification {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"challenge": req.Challenge})
+		return
+	}
+
+	handle, found := h.events[req.Event.Type]
+	switch {
+	case req.Type != EventRequestCallback || !found:
+		h.recordSlackInteraction(req.Type, req.Event.Type, "ignored")
+	case !h.recentEvents.add(req.EventID):
+		h.logger.Debug("dropping retried event",
+			zap.String("event_type", req.Event.Type),
+			zap.String("event_id", req.EventID),
+			zap.String("retry_num", r.Header.Get("X-Slack-Retry-Num")),
+		)
+		h.recordSlackInteraction(req.Type, req.Event.Type, "duplicate")
+	default:
+		h.recordSlackInteraction(req.Type, req.Event.Type, "received")
+		h.background("event:"+req.Event.Type, func() { handle(context.Background(), req) })
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// eventIDs remembers the IDs of recently dispatched events.
+type eventIDs struct {
+	mu    sync.Mutex
+	seen  map[string]bool
+	order []string // Oldest first; at most maxRecentEvents
+}
+
+// add records an event ID, returning false when it was already recorded.
+// Events without an ID are always new.
+func (e *eventIDs) add(id string) bool {
+	if id == "" {
+		return true
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.seen[id] {
+		return false
+	}
+	if e.seen == nil {
+		e.seen = make(map[string]bool)
+	}
+	if len(e.order) == maxRecentEvents {
+		delete(e.seen, e.order[0])
+		e.order = e.order[1:]
+	}
+	e.seen[id] = true
+	e.order = append(e.order, id)
+	return true
+}
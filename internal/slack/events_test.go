@@ -0,0 +1,59 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestHandleEvents_Dispatch tests that events reach their handler once, and unknown events are acknowledged
+func TestHandleEvents_Dispatch(t *testing.T) {
+	handler := newInteractiveTestHandler(&fakeBackend{}, &fakeSlack{})
+	dispatched := make(chan EventRequest, 2)
+	handler.events["message"] = func(_ context.Context, req EventRequest) { dispatched <- req }
+
+	send := func(body string) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.HandleEvents(w, createValidSlackRequest(http.MethodPost, "/slack/events", []byte(body), "secret"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+	}
+	send(`{"type":"event_callback","team_id":"T456","event_id":"Ev1","event":{"type":"message","user":"U123","channel":"C1"}}`)
+	send(`{"type":"event_callback","team_id":"T456","event_id":"Ev1","event":{"type":"message","user":"U123","channel":"C1"}}`) // Retry
+	send(`{"type":"event_callback","event_id":"Ev2","event":{"type":"channel_created"}}`)
+
+	select {
+	case req := <-dispatched:
+		if req.TeamID != "T456" || req.Event.Channel != "C1" {
+			t.Errorf("dispatched %+v, want the message event", req)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("event was not dispatched")
+	}
+	select {
+	case req := <-dispatched:
+		t.Errorf("dispatched again: %+v", req)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEventIDs(t *testing.T) {
+	var recent eventIDs
+	if !recent.add("Ev0") || recent.add("Ev0") {
+		t.Fatal("add() should accept an ID once")
+	}
+	if !recent.add("") || !recent.add("") {
+		t.Error("events without an ID should always be new")
+	}
+	for i := 1; i <= maxRecentEvents; i++ {
+		recent.add(fmt.Sprintf("Ev%d", i))
+	}
+	if !recent.add("Ev0") {
+		t.Error("oldest ID should be forgotten after maxRecentEvents more")
+	}
+}
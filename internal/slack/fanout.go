@@ -0,0 +1,61 @@
+package slack
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/fanout"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+)
+
+// SetFanout writes every created or edited idea to secondary backends too
+// (FANOUT_BACKENDS). Confirmations say where copies were saved.
+func (h *Handler) SetFanout(f *fanout.Fanout) {
+	h.fanout = f
+}
+
+// fanoutWrite writes an idea the backend accepted to the secondary backends
+// and returns the outcome per backend, or nil without fan-out. Failed writes
+// are retried in the background and never fail the submission.
+func (h *Handler) fanoutWrite(ctx context.Context, sub submission.Submission, page *notion.CreatedPage) []fanout.Result {
+	if h.fanout == nil {
+		return nil
+	}
+	return h.fanout.Write(ctx, fanout.Record{
+		PageID:      page.ID,
+		PageURL:     page.URL,
+		CreatedTime: page.CreatedTime,
+		UpdatedAt:   h.clock.Now().UTC(),
+		Submission:  sub,
+	})
+}
+
+// copyStatusBlocks appends a line to a confirmation card saying which
+// secondary backends have a copy of the idea and which are being retried.
+func (h *Handler) copyStatusBlocks(blocks []slack.Block, copies []fanout.Result) []slack.Block {
+	var saved, failed []string
+	for _, result := range copies {
+		if result.Err == nil {
+			saved = append(saved, result.Target)
+		} else {
+			failed = append(failed, result.Target)
+		}
+	}
+
+	var elements []slack.MixedElement
+	if len(saved) > 0 {
+		text := h.messages.Format(messages.KeyCopiesSaved, messages.Params{"targets": strings.Join(saved, ", ")})
+		elements = append(elements, slack.NewTextBlockObject(slack.MarkdownType, text, false, false))
+	}
+	if len(failed) > 0 {
+		text := h.messages.Format(messages.KeyCopiesRetrying, messages.Params{"targets": strings.Join(failed, ", ")})
+		elements = append(elements, slack.NewTextBlockObject(slack.MarkdownType, text, false, false))
+	}
+	if len(elements) == 0 {
+		return blocks
+	}
+	return append(blocks, slack.NewContextBlock("", elements...))
+}
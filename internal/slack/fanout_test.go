@@ -0,0 +1,82 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/fanout"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// fakeTarget is a fan-out target that records writes and fails while err is set.
+type fakeTarget struct {
+	name    string
+	err     error
+	written []fanout.Record
+}
+
+func (t *fakeTarget) Name() string { return t.name }
+
+func (t *fakeTarget) Write(_ context.Context, rec fanout.Record) error {
+	if t.err != nil {
+		return t.err
+	}
+	t.written = append(t.written, rec)
+	return nil
+}
+
+// TestFanoutConfirmation tests that ideas are written to secondary backends and
+// that the confirmation says which have a copy and which are being retried
+func TestFanoutConfirmation(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	slackAPI := &fakeSlack{posted: make(chan string, 1), sent: make(chan url.Values, 1)}
+	handler := NewHandlerWithDependencies(&config.Config{}, zap.NewNop(), Dependencies{
+		Backend: &fakeBackend{},
+		Slack:   slackAPI,
+		Clock:   fixedClock(now),
+	})
+
+	archive := &fakeTarget{name: "postgres"}
+	warehouse := &fakeTarget{name: "warehouse", err: errors.New("connection refused")}
+	store, _ := fanout.NewFileStore("")
+	handler.SetFanout(fanout.New([]fanout.Target{archive, warehouse}, store, nil, zap.NewNop()))
+
+	sub := submission.Submission{Title: "CSV exports", Source: submission.Source{SlackUserID: "U123"}}
+	page := &notion.CreatedPage{ID: "page-1", URL: "https://www.notion.so/page-1"}
+	copies := handler.fanoutWrite(t.Context(), sub, page)
+
+	if len(archive.written) != 1 {
+		t.Fatalf("archive writes = %d, want 1", len(archive.written))
+	}
+	if rec := archive.written[0]; rec.PageID != "page-1" || rec.PageURL != page.URL || !rec.UpdatedAt.Equal(now) || rec.Submission.Title != "CSV exports" {
+		t.Errorf("archived record = %+v", rec)
+	}
+
+	handler.postConfirmation(t.Context(), sub, page, copies)
+	<-slackAPI.posted
+	blocks := (<-slackAPI.sent).Get("blocks")
+	if !strings.Contains(blocks, "Also saved to postgres") {
+		t.Errorf("confirmation blocks = %s, want the saved copy listed", blocks)
+	}
+	if !strings.Contains(blocks, "Couldn't save a copy to warehouse yet") {
+		t.Errorf("confirmation blocks = %s, want the failed copy listed", blocks)
+	}
+
+	// Without fan-out, nothing is written or shown
+	handler.SetFanout(nil)
+	if copies := handler.fanoutWrite(t.Context(), sub, page); copies != nil {
+		t.Errorf("fanoutWrite() without fan-out = %v, want nil", copies)
+	}
+	handler.postConfirmation(t.Context(), sub, page, nil)
+	<-slackAPI.posted
+	if blocks := (<-slackAPI.sent).Get("blocks"); strings.Contains(blocks, "saved to") || strings.Contains(blocks, "save a copy") {
+		t.Errorf("confirmation blocks = %s, want no copy status", blocks)
+	}
+}
@@ -0,0 +1,310 @@
+package slack
+
+// This file implements the schema-driven part of the submission modal.
+//
+// The core fields (title, theme, product area, comments, customer org) have
+// dedicated blocks and validation. Every other property of the ideas database
+// whose type the modal supports gets a generated, optional input block, so
+// adding a column in Notion shows up in the form without a code change.
+// Generated values are submitted as submission.Submission.Extra.
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+)
+
+// slackMaxOptionValueLength is Slack's limit on option values; longer Notion options are left out.
+const slackMaxOptionValueLength = 150
+
+// maxModalFields keeps the modal within Slack's 100-block limit, leaving room
+// for the info and remind-me blocks.
+const maxModalFields = 98
+
+// ModalField is one input block of the submission modal.
+type ModalField struct {
+	Property string   // Notion property the value is written to
+	Type     string   // submission.Type* of generated fields; empty for core fields
+	Options  []string // Options of generated select and multi_select fields
+	BlockID  string
+	ActionID string
+
+	build func() *slack.InputBlock // Set for core fields, which have dedicated builders and validation
+}
+
+// IsCore reports whether f is one of the fixed core fields.
+func (f ModalField) IsCore() bool {
+	return f.build != nil
+}
+
+// CoreModalFields returns the fixed fields every ideas database has, in modal
+// order, with the built-in select options.
+func CoreModalFields() []ModalField {
+	return coreModalFields(notion.DefaultSelectOptions())
+}
+
+// coreModalFields returns the fixed fields with the given select options.
+func coreModalFields(options notion.SelectOptions) []ModalField {
+	buildTheme := func() *slack.InputBlock { return buildThemeBlock(options.Themes) }
+	buildProductArea := func() *slack.InputBlock { return buildProductAreaBlock(options.ProductAreas) }
+
+	return []ModalField{
+		{Property: constants.FieldIdeaTopic, BlockID: BlockIDTitle, ActionID: ActionIDTitleInput, build: buildTitleBlock},
+		{Property: constants.FieldThemeCategory, BlockID: BlockIDTheme, ActionID: ActionIDThemeSelect, build: buildTheme},
+		{Property: constants.FieldProductArea, BlockID: BlockIDProductArea, ActionID: ActionIDProductAreaSelect, build: buildProductArea},
+		{Property: constants.FieldComments, BlockID: BlockIDComments, ActionID: ActionIDCommentsInput, build: buildCommentsBlock},
+		{Property: constants.FieldCustomerOrg, BlockID: BlockIDCustomerOrg, ActionID: ActionIDCustomerOrgSelect, build: buildCustomerOrgBlock},
+	}
+}
+
+// schemaActionIDs maps generated field types to their action IDs.
+// Relations are only generated for the Customers database (see ModalFieldsFromSchema).
+var schemaActionIDs = map[string]string{
+	submission.TypeSelect:      ActionIDSchemaSelect,
+	submission.TypeMultiSelect: ActionIDSchemaMultiSelect,
+	submission.TypeRichText:    ActionIDSchemaText,
+	submission.TypePeople:      ActionIDSchemaPeople,
+	submission.TypeDate:        ActionIDSchemaDate,
+	submission.TypeRelation:    ActionIDSchemaCustomers,
+}
+
+// ModalFieldsFromSchema returns the core fields, with select options synced from
+// the schema, followed by a generated field for each other supported property.
+//
+// Skipped properties:
+//   - Properties written by the core fields or by the bot (Submitted By)
+//   - Artifacts, which gets the dedicated Artifacts field if it is a rich_text property
+//   - The triage status, which the product team maintains
+//   - The intake channel (Source), which the bot sets
+//   - Unsupported types (formulas, rollups, timestamps, ...)
+//   - Relations to databases other than Customers (their options can't be loaded)
+//   - Selects without options (Slack requires at least one)
+//   - Properties beyond maxModalFields
+func ModalFieldsFromSchema(schema []notion.FormProperty) []ModalField {
+	fields := coreModalFields(notion.SelectOptionsFromSchema(schema))
+	skip := map[string]bool{constants.FieldSubmittedBy: true, constants.FieldSubmittedBySlack: true, constants.FieldStatus: true, constants.FieldArtifacts: true, constants.FieldSource: true}
+	for _, field := range fields {
+		skip[field.Property] = true
+	}
+	for _, property := range schema {
+		if property.Name == constants.FieldArtifacts && property.Type == submission.TypeRichText {
+			fields = append(fields, ModalField{
+				Property: constants.FieldArtifacts,
+				BlockID:  BlockIDArtifacts,
+				ActionID: ActionIDArtifactsInput,
+				build:    buildArtifactsBlock,
+			})
+		}
+	}
+
+	for _, property := range schema {
+		if len(fields) == maxModalFields {
+			break
+		}
+		actionID, supported := schemaActionIDs[property.Type]
+		if skip[property.Name] || !supported {
+			continue
+		}
+		if property.Type == submission.TypeRelation && !property.RelatesToCustomers {
+			continue
+		}
+
+		field := ModalField{
+			Property: property.Name,
+			Type:     property.Type,
+			BlockID:  BlockIDSchemaPrefix + property.Name,
+			ActionID: actionID,
+		}
+		if property.Type == submission.TypeSelect || property.Type == submission.TypeMultiSelect {
+			field.Options = slackOptionValues(property.Options)
+			if len(field.Options) == 0 {
+				continue
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// slackOptionValues returns the options Slack can display in a static select:
+// non-empty, within the value length limit, and at most constants.SlackMaxOptions.
+func slackOptionValues(options []string) []string {
+	values := make([]string, 0, min(len(options), constants.SlackMaxOptions))
+	for _, option := range options {
+		if option != "" && len(option) <= slackMaxOptionValueLength && len(values) < constants.SlackMaxOptions {
+			values = append(values, option)
+		}
+	}
+	return values
+}
+
+// buildBlock returns the modal input block for f. Generated fields are always optional;
+// their length and selection limits are applied by applyFieldLimits.
+func (f ModalField) buildBlock() *slack.InputBlock {
+	if f.IsCore() {
+		return f.build()
+	}
+
+	var element slack.BlockElement
+	switch f.Type {
+	case submission.TypeSelect:
+		element = slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, newPlainText(PlaceholderSelect), f.ActionID, createOptions(f.Options)...)
+	case submission.TypeMultiSelect:
+		element = slack.NewOptionsMultiSelectBlockElement(slack.MultiOptTypeStatic, newPlainText(PlaceholderSelect), f.ActionID, createOptions(f.Options)...)
+	case submission.TypeRichText:
+		input := slack.NewPlainTextInputBlockElement(nil, f.ActionID)
+		input.Multiline = true
+		element = input
+	case submission.TypePeople:
+		element = slack.NewOptionsMultiSelectBlockElement(slack.MultiOptTypeUser, newPlainText(PlaceholderPeople), f.ActionID)
+	case submission.TypeDate:
+		element = slack.NewDatePickerBlockElement(f.ActionID)
+	case submission.TypeRelation:
+		element = slack.NewOptionsMultiSelectBlockElement(slack.MultiOptTypeExternal, newPlainText(PlaceholderCustomerOrg), f.ActionID)
+	}
+
+	block := slack.NewInputBlock(f.BlockID, newPlainText(f.Property), nil, element)
+	block.Optional = true
+	return block
+}
+
+// modalForm is the form definition loaded from the database schema.
+type modalForm struct {
+	fields  []ModalField
+	options notion.SelectOptions
+}
+
+// modalFields returns the fields the submission modal is currently built from.
+func (h *Handler) modalFields() []ModalField {
+	if form := h.form.Load(); form != nil {
+		return form.fields
+	}
+	return CoreModalFields()
+}
+
+// selectOptions returns the options theme and product area are validated against.
+func (h *Handler) selectOptions() notion.SelectOptions {
+	if form := h.form.Load(); form != nil {
+		return form.options
+	}
+	return notion.DefaultSelectOptions()
+}
+
+// LoadModalFields syncs the ideas database schema and regenerates the submission
+// modal fields and core select options from it.
+//
+// On failure the previous fields are kept (the core fields with built-in options
+// if none were loaded), so a Notion hiccup never breaks the form.
+func (h *Handler) LoadModalFields() error {
+	schema, err := h.backend.SyncSchema()
+	if err != nil {
+		return fmt.Errorf("failed to load database schema: %w", err)
+	}
+
+	h.form.Store(&modalForm{
+		fields:  ModalFieldsFromSchema(schema),
+		options: notion.SelectOptionsFromSchema(schema),
+	})
+	return nil
+}
+
+// extractSchemaFields reads the generated fields present in the view state.
+//
+// Fields missing from the view (the modal predates a new column) and empty
+// values are skipped. People are mapped from Slack users to Notion users by
+// email, and customers to their page IDs, against snapshot.
+func (h *Handler) extractSchemaFields(teamID string, state ViewState, snapshot *notion.CacheSnapshot) (map[string]submission.Value, error) {
+	var extra map[string]submission.Value
+	for _, field := range h.modalFields() {
+		if field.IsCore() {
+			continue
+		}
+		stateValue, found := state.Values[field.BlockID][field.ActionID]
+		if !found {
+			continue
+		}
+
+		value := submission.Value{Type: field.Type}
+		switch field.Type {
+		case submission.TypeSelect:
+			if stateValue.SelectedOption != nil && stateValue.SelectedOption.Value != "" {
+				value.Values = []string{stateValue.SelectedOption.Value}
+			}
+		case submission.TypeMultiSelect:
+			value.Values, _ = state.GetSelectedOptions(field.BlockID, field.ActionID)
+		case submission.TypeRichText:
+			if stateValue.Value != nil {
+				value.Text = strings.TrimSpace(*stateValue.Value)
+			}
+			if len(value.Text) > h.limits.TextMaxLength {
+				return nil, h.schemaFieldError(field, messages.KeyFieldTooLong, messages.Params{
+					"field": field.Property, "max": h.limits.TextMaxLength, "current": len(value.Text),
+				})
+			}
+		case submission.TypeDate:
+			value.Date = stateValue.SelectedDate
+		case submission.TypePeople:
+			for _, slackUserID := range stateValue.SelectedUsers {
+				notionUserID, displayName, found := h.notionUserIDForSlackUser(teamID, slackUserID, snapshot)
+				if !found {
+					return nil, h.schemaFieldError(field, messages.KeyPersonNotFound, messages.Params{"user": displayName})
+				}
+				value.Values = append(value.Values, notionUserID)
+			}
+		case submission.TypeRelation:
+			names, _ := state.GetSelectedOptions(field.BlockID, field.ActionID)
+			names = slices.DeleteFunc(names, func(name string) bool { return name == OptionValueMoreResults })
+			if len(names) > h.limits.MaxCustomerOrgs {
+				return nil, h.schemaFieldError(field, messages.KeyTooManySelections, messages.Params{
+					"max": h.limits.MaxCustomerOrgs, "selected": len(names),
+				})
+			}
+			for _, name := range names {
+				pageID, found := snapshot.CustomerPageID(name)
+				if !found {
+					return nil, h.schemaFieldError(field, messages.KeyInvalidCustomerOrg, messages.Params{"value": name})
+				}
+				value.Values = append(value.Values, pageID)
+			}
+		}
+
+		if value.Text == "" && value.Date == "" && len(value.Values) == 0 {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]submission.Value)
+		}
+		extra[field.Property] = value
+	}
+
+	return extra, nil
+}
+
+// notionUserIDForSlackUser maps a Slack user to a Notion user by email.
+// When there is no mapping, it returns a name to show in the error instead.
+func (h *Handler) notionUserIDForSlackUser(teamID, slackUserID string, snapshot *notion.CacheSnapshot) (notionUserID, displayName string, found bool) {
+	notionUserID, profile, found, err := h.slackUserNotionID(teamID, slackUserID, snapshot)
+	if err != nil {
+		return "", slackUserID, false
+	}
+	if found {
+		return notionUserID, "", true
+	}
+	return "", cmp.Or(profile.RealName, profile.Email, slackUserID), false
+}
+
+// schemaFieldError records a validation error on a generated field and wraps it for the modal.
+func (h *Handler) schemaFieldError(field ModalField, key messages.Key, params messages.Params) error {
+	h.recordValidationError("schema_field")
+	return fieldValidationError{
+		errors: map[string]string{field.BlockID: h.messages.Format(key, params)},
+	}
+}
@@ -0,0 +1,125 @@
+// Package slack provides handlers and types for Slack integration.
+//
+// This file implements declarative conditional-requirement rules for the
+// submission form. A rule makes an otherwise optional field required when
+// another field has one of a set of values, e.g. Customer Org is required when
+// the theme is "Customer Pain Point".
+//
+// Rules reference fields by block ID, so the same rule set drives both
+// validation (extractAndValidateFields) and the hint text rendered in the modal,
+// keeping what users are told in sync with what is enforced.
+package slack
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+)
+
+// FormRule makes Field required when WhenField has one of WhenValues.
+type FormRule struct {
+	// Field is the block ID of the field that becomes required (e.g., BlockIDCustomerOrg).
+	Field string
+
+	// WhenField is the block ID of the field whose value triggers the requirement (e.g., BlockIDTheme).
+	WhenField string
+
+	// WhenValues lists the values of WhenField that trigger the requirement.
+	WhenValues []string
+}
+
+// CustomerOrgRequiredRule returns the rule requiring at least one customer org
+// for the given themes. Returns nil if themes is empty (rule disabled).
+func CustomerOrgRequiredRule(themes []string) []FormRule {
+	if len(themes) == 0 {
+		return nil
+	}
+	return []FormRule{{
+		Field:      BlockIDCustomerOrg,
+		WhenField:  BlockIDTheme,
+		WhenValues: themes,
+	}}
+}
+
+// DefaultFormRules is the rule set used until Handler.SetFormRules is called.
+var DefaultFormRules = CustomerOrgRequiredRule(constants.CustomerOrgRequiredThemes)
+
+// fieldLabels maps block IDs to the labels shown in the modal, for rule messages and hints.
+var fieldLabels = map[string]string{
+	BlockIDTitle:       LabelTitle,
+	BlockIDTheme:       LabelThemeCategory,
+	BlockIDProductArea: LabelProductArea,
+	BlockIDComments:    LabelComments,
+	BlockIDCustomerOrg: LabelCustomerOrg,
+}
+
+// fieldMetricNames maps block IDs to the field names used in validation error metrics.
+var fieldMetricNames = map[string]string{
+	BlockIDTitle:       "title",
+	BlockIDTheme:       "theme",
+	BlockIDProductArea: "product_area",
+	BlockIDComments:    "comments",
+	BlockIDCustomerOrg: "customer_org",
+	BlockIDArtifacts:   "artifacts",
+	BlockIDAttachments: "attachments",
+}
+
+// ruleViolation describes a rule whose required field was left empty.
+type ruleViolation struct {
+	Field        string // Block ID of the empty required field
+	FieldLabel   string // Label of the empty required field
+	WhenLabel    string // Label of the trigger field
+	TriggerValue string // Value of the trigger field that activated the rule
+}
+
+// evaluateFormRules returns one violation per required field left empty.
+//
+// values maps block IDs to the submitted values for that field (multi-selects
+// may have several; empty or missing means the field was left blank).
+func evaluateFormRules(rules []FormRule, values map[string][]string) []ruleViolation {
+	var violations []ruleViolation
+	seen := make(map[string]bool)
+
+	for _, rule := range rules {
+		if seen[rule.Field] || len(values[rule.Field]) > 0 {
+			continue
+		}
+		for _, value := range values[rule.WhenField] {
+			if slices.Contains(rule.WhenValues, value) {
+				violations = append(violations, ruleViolation{
+					Field:        rule.Field,
+					FieldLabel:   fieldLabels[rule.Field],
+					WhenLabel:    fieldLabels[rule.WhenField],
+					TriggerValue: value,
+				})
+				seen[rule.Field] = true
+				break
+			}
+		}
+	}
+
+	return violations
+}
+
+// ruleHint returns hint text describing when field is required, or "" if no rule applies.
+//
+// Example:
+//
+//	ruleHint(DefaultFormRules, BlockIDCustomerOrg)
+//	// Returns: "Required for Customer Pain Point and Market/Competition Intelligence"
+func ruleHint(rules []FormRule, field string) string {
+	var triggers []string
+	for _, rule := range rules {
+		if rule.Field == field {
+			triggers = append(triggers, rule.WhenValues...)
+		}
+	}
+	if len(triggers) == 0 {
+		return ""
+	}
+	if len(triggers) == 1 {
+		return "Required for " + triggers[0]
+	}
+	return "Required for " + strings.Join(triggers[:len(triggers)-1], ", ") + " and " + triggers[len(triggers)-1]
+}
@@ -0,0 +1,141 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// TestEvaluateFormRules tests conditional requirement evaluation
+func TestEvaluateFormRules(t *testing.T) {
+	rules := CustomerOrgRequiredRule([]string{"Customer Pain Point", "Market/Competition Intelligence"})
+
+	tests := []struct {
+		name          string
+		values        map[string][]string
+		wantViolation bool
+	}{
+		{
+			name:          "triggering theme without customer org",
+			values:        map[string][]string{BlockIDTheme: {"Customer Pain Point"}},
+			wantViolation: true,
+		},
+		{
+			name: "triggering theme with customer org",
+			values: map[string][]string{
+				BlockIDTheme:       {"Market/Competition Intelligence"},
+				BlockIDCustomerOrg: {"Acme"},
+			},
+			wantViolation: false,
+		},
+		{
+			name:          "non-triggering theme",
+			values:        map[string][]string{BlockIDTheme: {"New Feature Idea"}},
+			wantViolation: false,
+		},
+		{
+			name:          "no theme",
+			values:        map[string][]string{},
+			wantViolation: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := evaluateFormRules(rules, tt.values)
+			if (len(violations) > 0) != tt.wantViolation {
+				t.Fatalf("violations = %+v, wantViolation %v", violations, tt.wantViolation)
+			}
+			if tt.wantViolation {
+				v := violations[0]
+				if v.Field != BlockIDCustomerOrg || v.FieldLabel != LabelCustomerOrg || v.TriggerValue != "Customer Pain Point" {
+					t.Errorf("unexpected violation: %+v", v)
+				}
+			}
+		})
+	}
+}
+
+// TestCustomerOrgRequiredRule_Disabled verifies an empty theme list disables the rule
+func TestCustomerOrgRequiredRule_Disabled(t *testing.T) {
+	if rules := CustomerOrgRequiredRule(nil); rules != nil {
+		t.Errorf("expected nil rules, got %+v", rules)
+	}
+}
+
+// TestRuleHint tests hint text generation
+func TestRuleHint(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []FormRule
+		field string
+		want  string
+	}{
+		{name: "no rules", rules: nil, field: BlockIDCustomerOrg, want: ""},
+		{name: "single trigger", rules: CustomerOrgRequiredRule([]string{"Customer Pain Point"}), field: BlockIDCustomerOrg, want: "Required for Customer Pain Point"},
+		{name: "multiple triggers", rules: CustomerOrgRequiredRule([]string{"A", "B", "C"}), field: BlockIDCustomerOrg, want: "Required for A, B and C"},
+		{name: "other field", rules: CustomerOrgRequiredRule([]string{"A"}), field: BlockIDComments, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruleHint(tt.rules, tt.field); got != tt.want {
+				t.Errorf("ruleHint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBuildSubmissionModalWithRules verifies rule hints are rendered on the affected block
+func TestBuildSubmissionModalWithRules(t *testing.T) {
+	modal := BuildSubmissionModalWithRules(CustomerOrgRequiredRule([]string{"Customer Pain Point"}))
+
+	for _, block := range modal.Blocks.BlockSet {
+		input, ok := block.(*slack.InputBlock)
+		if !ok || input.BlockID != BlockIDCustomerOrg {
+			continue
+		}
+		want := fmt.Sprintf(HintMaxCustomerOrgs, constants.MaxCustomerOrgSelections) + ". Required for Customer Pain Point"
+		if input.Hint == nil || input.Hint.Text != want {
+			t.Errorf("hint = %v, want %q", input.Hint, want)
+		}
+		if !input.Optional {
+			t.Error("conditionally required block should stay optional in Slack")
+		}
+		return
+	}
+	t.Fatal("customer org block not found")
+}
+
+// TestExtractAndValidateFields_ConditionalCustomerOrg tests the rule is enforced on submission
+func TestExtractAndValidateFields_ConditionalCustomerOrg(t *testing.T) {
+	handler := NewHandler(&config.Config{SlackSigningSecret: "secret"}, zap.NewNop())
+
+	title := "Exports are slow"
+	state := ViewState{Values: map[string]map[string]StateValue{
+		BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+		BlockIDTheme:       {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "Customer Pain Point"}}},
+		BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+	}}
+
+	_, err := handler.extractAndValidateFields(context.Background(), "T456", state, handler.notionClient.Snapshot())
+	validationErr, ok := err.(fieldValidationError)
+	if !ok {
+		t.Fatalf("expected fieldValidationError, got %v", err)
+	}
+	want := `Client Organization is required when Theme/Category is "Customer Pain Point"`
+	if validationErr.errors[BlockIDCustomerOrg] != want {
+		t.Errorf("error = %q, want %q", validationErr.errors[BlockIDCustomerOrg], want)
+	}
+
+	// Disabling the rules accepts the same submission
+	handler.SetFormRules(nil)
+	if _, err := handler.extractAndValidateFields(context.Background(), "T456", state, handler.notionClient.Snapshot()); err != nil {
+		t.Errorf("expected no error with rules disabled, got %v", err)
+	}
+}
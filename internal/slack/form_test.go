@@ -0,0 +1,184 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// testFormSchema is an ideas database schema with one property of each supported kind
+var testFormSchema = []notion.FormProperty{
+	{Name: constants.FieldComments, Type: "rich_text"},
+	{Name: constants.FieldCustomerOrg, Type: "relation", RelatesToCustomers: true},
+	{Name: "Affected Customers", Type: "relation", RelatesToCustomers: true},
+	{Name: "Created", Type: "created_time"},
+	{Name: "Due", Type: "date"},
+	{Name: "Epic", Type: "relation"},
+	{Name: "Labels", Type: "multi_select", Options: []string{"ux", "perf"}},
+	{Name: "Notes", Type: "rich_text"},
+	{Name: "Owners", Type: "people"},
+	{Name: "Priority", Type: "select", Options: []string{"High", "Low"}},
+	{Name: "Size", Type: "select"},
+	{Name: constants.FieldStatus, Type: "status"},
+	{Name: constants.FieldSubmittedBy, Type: "people"},
+}
+
+// TestModalFieldsFromSchema tests which properties get generated fields
+func TestModalFieldsFromSchema(t *testing.T) {
+	fields := ModalFieldsFromSchema(testFormSchema)
+
+	var got []string
+	for _, field := range fields {
+		got = append(got, field.Property)
+	}
+	want := []string{
+		constants.FieldIdeaTopic, constants.FieldThemeCategory, constants.FieldProductArea, constants.FieldComments, constants.FieldCustomerOrg,
+		"Affected Customers", "Due", "Labels", "Notes", "Owners", "Priority",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+
+	priority := fields[len(fields)-1]
+	if priority.IsCore() || priority.BlockID != BlockIDSchemaPrefix+"Priority" || priority.ActionID != ActionIDSchemaSelect {
+		t.Errorf("priority field = %+v", priority)
+	}
+}
+
+// TestBuildSubmissionModalFromFields tests the element types of generated blocks
+func TestBuildSubmissionModalFromFields(t *testing.T) {
+	modal := BuildSubmissionModalFromFields(ModalFieldsFromSchema(testFormSchema), nil, DefaultFieldLimits())
+
+	// Info block + 5 core fields + 6 generated fields
+	if len(modal.Blocks.BlockSet) != 12 {
+		t.Fatalf("expected 12 blocks, got %d", len(modal.Blocks.BlockSet))
+	}
+
+	wantTypes := map[string]string{
+		BlockIDSchemaPrefix + "Affected Customers": "multi_external_select",
+		BlockIDSchemaPrefix + "Due":                "datepicker",
+		BlockIDSchemaPrefix + "Labels":             "multi_static_select",
+		BlockIDSchemaPrefix + "Notes":              "plain_text_input",
+		BlockIDSchemaPrefix + "Owners":             "multi_users_select",
+		BlockIDSchemaPrefix + "Priority":           "static_select",
+	}
+	for _, block := range modal.Blocks.BlockSet[6:] {
+		input, ok := block.(*slack.InputBlock)
+		if !ok {
+			t.Fatalf("expected input block, got %T", block)
+		}
+		if !input.Optional {
+			t.Errorf("%s should be optional", input.BlockID)
+		}
+		if got := string(input.Element.ElementType()); got != wantTypes[input.BlockID] {
+			t.Errorf("%s element type = %s, want %s", input.BlockID, got, wantTypes[input.BlockID])
+		}
+	}
+}
+
+// TestExtractSchemaFields tests reading generated fields into submission values
+func TestExtractSchemaFields(t *testing.T) {
+	snapshot := notion.NewCacheSnapshot(
+		map[string]string{"Acme": "page-acme"},
+		map[string]string{"bob@example.com": "notion-bob"},
+	)
+	slackAPI := &fakeSlack{users: map[string]*slack.User{
+		"U1": {ID: "U1", Profile: slack.UserProfile{Email: "bob@example.com"}},
+		"U2": {ID: "U2", RealName: "Carol", Profile: slack.UserProfile{Email: "carol@example.com"}},
+	}}
+	handler := NewHandlerWithDependencies(&config.Config{}, zap.NewNop(), Dependencies{
+		Backend: &fakeBackend{snapshot: snapshot, schema: testFormSchema},
+		Slack:   slackAPI,
+	})
+	if err := handler.LoadModalFields(); err != nil {
+		t.Fatalf("LoadModalFields() error = %v", err)
+	}
+
+	notes := "  some notes "
+	state := ViewState{Values: map[string]map[string]StateValue{
+		BlockIDSchemaPrefix + "Affected Customers": {ActionIDSchemaCustomers: {SelectedOptions: []SelectedOption{{Value: "Acme"}, {Value: OptionValueMoreResults}}}},
+		BlockIDSchemaPrefix + "Due":                {ActionIDSchemaDate: {SelectedDate: "2025-12-01"}},
+		BlockIDSchemaPrefix + "Labels":             {ActionIDSchemaMultiSelect: {}},
+		BlockIDSchemaPrefix + "Notes":              {ActionIDSchemaText: {Value: &notes}},
+		BlockIDSchemaPrefix + "Owners":             {ActionIDSchemaPeople: {SelectedUsers: []string{"U1"}}},
+		BlockIDSchemaPrefix + "Priority":           {ActionIDSchemaSelect: {SelectedOption: &SelectedOption{Value: "High"}}},
+	}}
+
+	extra, err := handler.extractSchemaFields("T456", state, snapshot)
+	if err != nil {
+		t.Fatalf("extractSchemaFields() error = %v", err)
+	}
+	want := map[string]submission.Value{
+		"Affected Customers": {Type: submission.TypeRelation, Values: []string{"page-acme"}},
+		"Due":                {Type: submission.TypeDate, Date: "2025-12-01"},
+		"Notes":              {Type: submission.TypeRichText, Text: "some notes"},
+		"Owners":             {Type: submission.TypePeople, Values: []string{"notion-bob"}},
+		"Priority":           {Type: submission.TypeSelect, Values: []string{"High"}},
+	}
+	if !reflect.DeepEqual(extra, want) {
+		t.Errorf("extractSchemaFields() = %+v, want %+v", extra, want)
+	}
+
+	// People without a Notion account are rejected on their field
+	state.Values[BlockIDSchemaPrefix+"Owners"] = map[string]StateValue{ActionIDSchemaPeople: {SelectedUsers: []string{"U2"}}}
+	_, err = handler.extractSchemaFields("T456", state, snapshot)
+	var validationErr fieldValidationError
+	if !errors.As(err, &validationErr) || validationErr.errors[BlockIDSchemaPrefix+"Owners"] == "" {
+		t.Fatalf("expected validation error on Owners, got %v", err)
+	}
+	if msg := validationErr.errors[BlockIDSchemaPrefix+"Owners"]; msg != "Carol is not associated with a Notion account in this workspace." {
+		t.Errorf("error = %q", msg)
+	}
+
+	// Views without generated fields (modal opened before the schema loaded) submit no extras
+	extra, err = handler.extractSchemaFields("T456", ViewState{}, snapshot)
+	if err != nil || extra != nil {
+		t.Errorf("extractSchemaFields(empty) = %v, %v, want nil, nil", extra, err)
+	}
+}
+
+// TestLoadModalFields_SyncsSelectOptions tests that theme options from the schema drive the modal and validation
+func TestLoadModalFields_SyncsSelectOptions(t *testing.T) {
+	schema := []notion.FormProperty{{Name: constants.FieldThemeCategory, Type: "multi_select", Options: []string{"Tech Debt"}}}
+	handler := NewHandlerWithDependencies(&config.Config{}, zap.NewNop(), Dependencies{
+		Backend: &fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil), schema: schema},
+		Slack:   &fakeSlack{},
+	})
+	if err := handler.LoadModalFields(); err != nil {
+		t.Fatalf("LoadModalFields() error = %v", err)
+	}
+
+	modal := BuildSubmissionModalFromFields(handler.modalFields(), nil, handler.limits)
+	themeElement := modal.Blocks.BlockSet[2].(*slack.InputBlock).Element.(*slack.SelectBlockElement)
+	if len(themeElement.Options) != 1 || themeElement.Options[0].Value != "Tech Debt" {
+		t.Errorf("theme options = %+v, want [Tech Debt]", themeElement.Options)
+	}
+
+	title := "Idea"
+	state := ViewState{Values: map[string]map[string]StateValue{
+		BlockIDTitle:       {ActionIDTitleInput: {Value: &title}},
+		BlockIDTheme:       {ActionIDThemeSelect: {SelectedOption: &SelectedOption{Value: "Tech Debt"}}},
+		BlockIDProductArea: {ActionIDProductAreaSelect: {SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+	}}
+	sub, err := handler.extractAndValidateFields(context.Background(), "T456", state, notion.NewCacheSnapshot(nil, nil))
+	if err != nil {
+		t.Fatalf("extractAndValidateFields() error = %v", err)
+	}
+	if sub.Theme != "Tech Debt" {
+		t.Errorf("Theme = %q, want Tech Debt", sub.Theme)
+	}
+
+	// Built-in themes missing from the database are rejected
+	state.Values[BlockIDTheme] = map[string]StateValue{ActionIDThemeSelect: {SelectedOption: &SelectedOption{Value: "New Feature Idea"}}}
+	if _, err := handler.extractAndValidateFields(context.Background(), "T456", state, notion.NewCacheSnapshot(nil, nil)); err == nil {
+		t.Error("expected error for theme missing from the database")
+	}
+}
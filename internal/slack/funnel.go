@@ -0,0 +1,91 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/analytics"
+	"github.com/rudderlabs/hopperbot/pkg/funnel"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// SetFunnelTracker enables idea funnel tracking: every created idea is recorded
+// so its time to triage and decision can be measured. Passing nil (the default)
+// disables tracking.
+func (h *Handler) SetFunnelTracker(tracker *funnel.Tracker) {
+	h.funnel = tracker
+}
+
+// trackIdea records a newly created idea in the funnel tracker.
+// Failures are logged but never fail the submission, which has already succeeded.
+func (h *Handler) trackIdea(sub submission.Submission, page *notion.CreatedPage) {
+	if h.funnel == nil {
+		return
+	}
+
+	submittedAt := page.CreatedTime
+	if submittedAt.IsZero() {
+		submittedAt = h.clock.Now().UTC()
+	}
+	idea := funnel.Idea{
+		PageID:      page.ID,
+		SlackTeamID: sub.Source.SlackTeamID,
+		SlackUserID: sub.Source.SlackUserID,
+		SubmittedAt: submittedAt,
+	}
+	if err := h.funnel.Track(idea); err != nil {
+		h.logger.Error("failed to track idea in funnel", zap.String("page_id", page.ID), zap.Error(err))
+	}
+}
+
+// FunnelStatus is the funnel.StatusFunc for the tracker. It reads the idea's
+// status from the Notion database of the workspace it was submitted from; a
+// page that no longer exists is reported as archived.
+func (h *Handler) FunnelStatus(_ context.Context, idea funnel.Idea) (funnel.Status, error) {
+	status, err := h.backendFor(idea.SlackTeamID).GetPageStatus(idea.PageID)
+	var apiErr *notion.NotionAPIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return funnel.Status{Archived: true}, nil
+	}
+	if err != nil {
+		return funnel.Status{}, err
+	}
+	return funnel.Status{Value: status.Status, Archived: status.Archived, LastEditedTime: status.LastEditedTime}, nil
+}
+
+// TrackFunnelStage is the funnel.StageFunc for the tracker. It exports the
+// idea's triage, decision or removal as an analytics event.
+func (h *Handler) TrackFunnelStage(idea funnel.Idea, stage string) {
+	if h.analytics == nil {
+		return
+	}
+
+	properties := map[string]interface{}{
+		"page_id": idea.PageID,
+		"team_id": idea.SlackTeamID,
+	}
+	var event string
+	switch stage {
+	case funnel.StageInReview:
+		event = analytics.EventIdeaTriaged
+		properties["status"] = idea.FirstStatus
+		properties["hours_to_triage"] = idea.FirstStatusAt.Sub(idea.SubmittedAt).Hours()
+	case funnel.StageClosed:
+		event = analytics.EventIdeaClosed
+		properties["status"] = idea.ClosedStatus
+		properties["hours_to_decision"] = idea.ClosedAt.Sub(idea.SubmittedAt).Hours()
+	case funnel.StageRemoved:
+		event = analytics.EventIdeaRemoved
+	default:
+		return
+	}
+
+	h.analytics.Track(analytics.Event{
+		Event:      event,
+		UserID:     idea.SlackUserID,
+		Properties: properties,
+	})
+}
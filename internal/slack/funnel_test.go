@@ -0,0 +1,108 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/analytics"
+	"github.com/rudderlabs/hopperbot/pkg/funnel"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// TestHandleInteractive_TracksFunnel tests that created ideas are recorded in the funnel tracker
+func TestHandleInteractive_TracksFunnel(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(nil, map[string]string{"alice@example.com": "notion-alice"})}
+	slackAPI := &fakeSlack{
+		users:  map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+		posted: make(chan string, 1),
+	}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+	store, _ := funnel.NewFileStore("")
+	handler.SetFunnelTracker(funnel.NewTracker(store, handler.FunnelStatus, []string{"Shipped"}, nil, zap.NewNop(), time.Hour))
+
+	title := "Exports are slow"
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, submissionRequest(t, map[string]map[string]StateValue{
+		BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+		BlockIDTheme:       {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "New Feature Idea"}}},
+		BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+		BlockIDComments:    {ActionIDCommentsInput: {Type: "plain_text_input"}},
+		BlockIDCustomerOrg: {ActionIDCustomerOrgSelect: {Type: "multi_external_select"}},
+	}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	ideas, _ := store.List()
+	if len(ideas) != 1 || ideas[0].PageID != "page-1" || ideas[0].SlackTeamID != "T456" || ideas[0].SlackUserID != "U123" || ideas[0].SubmittedAt.IsZero() {
+		t.Errorf("tracked ideas = %+v, want page-1 from U123 in T456", ideas)
+	}
+}
+
+// TestTrackFunnelStage tests that funnel stages are exported as analytics events
+func TestTrackFunnelStage(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []analytics.Event
+	)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct{ Batch []analytics.Event }
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid batch: %v", err)
+		}
+		mu.Lock()
+		events = append(events, payload.Batch...)
+		mu.Unlock()
+	}))
+	defer endpoint.Close()
+
+	exporter := analytics.NewExporter(analytics.Config{Endpoint: endpoint.URL, BatchSize: 10}, nil, zap.NewNop())
+	exporter.Start()
+	handler := newInteractiveTestHandler(&fakeBackend{}, &fakeSlack{})
+	handler.SetAnalyticsExporter(exporter)
+
+	submitted := time.Date(2025, 11, 3, 9, 0, 0, 0, time.UTC)
+	idea := funnel.Idea{
+		PageID:        "page-1",
+		SlackTeamID:   "T456",
+		SlackUserID:   "U123",
+		SubmittedAt:   submitted,
+		FirstStatus:   "Planned",
+		FirstStatusAt: submitted.Add(6 * time.Hour),
+		ClosedStatus:  "Shipped",
+		ClosedAt:      submitted.Add(48 * time.Hour),
+	}
+	handler.TrackFunnelStage(idea, funnel.StageInReview)
+	handler.TrackFunnelStage(idea, funnel.StageClosed)
+	handler.TrackFunnelStage(idea, funnel.StageRemoved)
+	handler.TrackFunnelStage(idea, funnel.StageAwaitingTriage) // Not a transition; ignored
+	exporter.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 3 {
+		t.Fatalf("exported %d events, want 3: %+v", len(events), events)
+	}
+	for i, want := range []struct {
+		event, status, hoursKey string
+		hours                   float64
+	}{
+		{analytics.EventIdeaTriaged, "Planned", "hours_to_triage", 6},
+		{analytics.EventIdeaClosed, "Shipped", "hours_to_decision", 48},
+		{analytics.EventIdeaRemoved, "", "", 0},
+	} {
+		got := events[i]
+		if got.Event != want.event || got.UserID != "U123" || got.Properties["page_id"] != "page-1" || got.Properties["team_id"] != "T456" {
+			t.Errorf("event %d = %+v, want %s for page-1 from U123", i, got, want.event)
+		}
+		if want.status != "" && (got.Properties["status"] != want.status || got.Properties[want.hoursKey] != want.hours) {
+			t.Errorf("event %d properties = %v, want status %s and %s = %v", i, got.Properties, want.status, want.hoursKey, want.hours)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// Submitters the policies apply to, as recorded in metrics and analytics.
+const (
+	userTypeSlackGuest  = "slack_guest"  // Single- or multi-channel Slack guest (SLACK_GUEST_POLICY)
+	userTypeHiddenEmail = "hidden_email" // Slack profile without a visible email (HIDDEN_EMAIL_POLICY)
+)
+
+// submitterPolicy returns the policy (constants.SubmitterPolicy*) for a
+// submitter and the type of user it applies to, or "" when the submitter is
+// mapped by email as usual: members with a visible email, and guests while
+// SLACK_GUEST_POLICY allows them.
+func (h *Handler) submitterPolicy(profile slackUserProfile) (policy, userType string) {
+	cfg := h.config.Load()
+	if profile.Guest && cfg.SlackGuestPolicy != "" && cfg.SlackGuestPolicy != constants.SubmitterPolicyAllow {
+		return cfg.SlackGuestPolicy, userTypeSlackGuest
+	}
+	if profile.Email == "" {
+		if cfg.HiddenEmailPolicy == "" {
+			return constants.SubmitterPolicyReject, userTypeHiddenEmail
+		}
+		return cfg.HiddenEmailPolicy, userTypeHiddenEmail
+	}
+	return "", ""
+}
+
+// applySubmitterPolicy maps a submitter the policy applies to: attributed
+// submitters get NOTION_FALLBACK_USER_ID, anyone else a submitterError, with
+// triage set for SubmitterPolicyTriage. Its message is the rejection, for
+// callers that can't triage (edits).
+func (h *Handler) applySubmitterPolicy(userID string, profile slackUserProfile, policy, userType string) (string, error) {
+	cfg := h.config.Load()
+	key := messages.KeyHiddenEmailRejected
+	if userType == userTypeSlackGuest {
+		key = messages.KeySlackGuestRejected
+	}
+
+	// Attributing without a fallback user (only possible in an unvalidated config) rejects instead
+	if policy == constants.SubmitterPolicyAttribute && cfg.NotionFallbackUserID == "" {
+		policy = constants.SubmitterPolicyReject
+	}
+	h.logger.Info("applying submitter policy",
+		zap.String("user_id", userID),
+		zap.String("email", profile.Email),
+		zap.String("user_type", userType),
+		zap.String("policy", policy),
+	)
+	h.recordSubmitterPolicy(userType, policy)
+
+	switch policy {
+	case constants.SubmitterPolicyAttribute:
+		return cfg.NotionFallbackUserID, nil
+	case constants.SubmitterPolicyTriage:
+		return "", submitterError{message: h.messages.Format(key, nil), reason: userType, triage: true}
+	default:
+		return "", submitterError{message: h.messages.Format(key, nil), reason: userType}
+	}
+}
+
+// postToTriage posts an idea held back by SubmitterPolicyTriage to
+// GUEST_TRIAGE_CHANNEL, so someone on the team can review it and add it to
+// Notion. The channel belongs to the SLACK_BOT_TOKEN workspace, like the
+// confirmation channel. Failures are returned for the caller to log or retry.
+func (h *Handler) postToTriage(ctx context.Context, sub submission.Submission) error {
+	channel := h.config.Load().GuestTriageChannel
+	if channel == "" {
+		return errors.New("GUEST_TRIAGE_CHANNEL is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, confirmationTimeout)
+	defer cancel()
+
+	text := h.messages.Format(messages.KeyTriageIdea, messages.Params{
+		"user": "<@" + sub.Source.SlackUserID + ">", "title": sub.Title,
+	})
+	blocks := buildConfirmationBlocks(text, sub, &notion.CreatedPage{})
+	if sub.Comments != "" {
+		blocks = append(blocks, newMarkdownSection(sub.Comments))
+	}
+	_, _, err := h.defaultSlackClient().PostMessageContext(ctx, channel,
+		slack.MsgOptionText(text, false), // Notification and fallback text
+		slack.MsgOptionBlocks(blocks...),
+	)
+	if err != nil {
+		h.recordSlackAPIError("chat.postMessage", err)
+		return fmt.Errorf("failed to post to %s: %w", channel, err)
+	}
+
+	logging.FromContext(ctx, h.logger).Info("idea posted for triage",
+		zap.String("channel", channel),
+		zap.String("slack_user_id", sub.Source.SlackUserID),
+		zap.String("title", sub.Title),
+	)
+	return nil
+}
+
+// triageInBackground posts an idea to GUEST_TRIAGE_CHANNEL on the worker
+// pool, keeping ctx's request-scoped logger. The submitter has already been
+// told the idea went to triage, so a failed post is logged with the idea for
+// someone to recover it.
+func (h *Handler) triageInBackground(ctx context.Context, sub submission.Submission) {
+	ctx = context.WithoutCancel(ctx)
+	h.background("triage", func() {
+		if err := h.postToTriage(ctx, sub); err != nil {
+			logging.FromContext(ctx, h.logger).Error("failed to post idea for triage",
+				zap.String("slack_user_id", sub.Source.SlackUserID),
+				zap.String("title", sub.Title),
+				zap.Error(err),
+			)
+		}
+	})
+}
+
+// triagedModal replaces the submitted modal with text, for ideas sent to
+// GUEST_TRIAGE_CHANNEL. Closing it closes any modals beneath it too.
+func triagedModal(text string) slack.ModalViewRequest {
+	modal := queuedModal(text)
+	modal.Title = newPlainText(ModalTitleTriaged)
+	return modal
+}
@@ -0,0 +1,148 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/requestid"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestSubmitterNotionID_Policies tests the guest and hidden email policies
+func TestSubmitterNotionID_Policies(t *testing.T) {
+	slackAPI := &fakeSlack{users: map[string]*slack.User{
+		"U-member": {ID: "U-member", Profile: slack.UserProfile{Email: "alice@example.com"}},
+		"U-guest":  {ID: "U-guest", IsRestricted: true, Profile: slack.UserProfile{Email: "bob@example.com"}},
+		"U-hidden": {ID: "U-hidden"},
+	}}
+	handler := NewHandlerWithDependencies(&config.Config{}, zap.NewNop(), Dependencies{Backend: &fakeBackend{}, Slack: slackAPI})
+	snapshot := notion.NewCacheSnapshot(nil, map[string]string{
+		"alice@example.com": "notion-user-alice",
+		"bob@example.com":   "notion-user-bob",
+	})
+
+	tests := []struct {
+		name        string
+		guestPolicy string
+		hiddenEmail string
+		userID      string
+		want        string // Notion user, or "" for an error
+		wantTriage  bool
+		wantMessage string
+	}{
+		{name: "members are mapped by email", guestPolicy: constants.SubmitterPolicyReject, userID: "U-member", want: "notion-user-alice"},
+		{name: "guests are allowed by default", userID: "U-guest", want: "notion-user-bob"},
+		{name: "hidden emails are rejected by default", userID: "U-hidden", wantMessage: "doesn't show your email"},
+		{name: "rejected guest", guestPolicy: constants.SubmitterPolicyReject, userID: "U-guest", wantMessage: "Guest accounts can't submit ideas"},
+		{name: "attributed guest", guestPolicy: constants.SubmitterPolicyAttribute, userID: "U-guest", want: "notion-fallback"},
+		{name: "triaged guest", guestPolicy: constants.SubmitterPolicyTriage, userID: "U-guest", wantTriage: true, wantMessage: "Guest accounts"},
+		{name: "attributed hidden email", hiddenEmail: constants.SubmitterPolicyAttribute, userID: "U-hidden", want: "notion-fallback"},
+		{name: "triaged hidden email", hiddenEmail: constants.SubmitterPolicyTriage, userID: "U-hidden", wantTriage: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler.ApplyConfig(&config.Config{
+				NotionFallbackUserID: "notion-fallback",
+				SlackGuestPolicy:     tt.guestPolicy,
+				HiddenEmailPolicy:    tt.hiddenEmail,
+				GuestTriageChannel:   "C-triage",
+			})
+			notionUserID, _, err := handler.submitterNotionID("T1", tt.userID, snapshot)
+			if tt.want != "" {
+				if err != nil || notionUserID != tt.want {
+					t.Fatalf("submitterNotionID() = %q, %v, want %q", notionUserID, err, tt.want)
+				}
+				return
+			}
+			unmapped, ok := err.(submitterError)
+			if !ok {
+				t.Fatalf("submitterNotionID() = %q, %v, want a submitterError", notionUserID, err)
+			}
+			if unmapped.triage != tt.wantTriage || !strings.Contains(unmapped.message, tt.wantMessage) {
+				t.Errorf("submitterError = %+v, want triage %v and %q", unmapped, tt.wantTriage, tt.wantMessage)
+			}
+		})
+	}
+
+	// Overridden users are mapped regardless of the policies
+	handler.ApplyConfig(&config.Config{
+		SlackGuestPolicy:         constants.SubmitterPolicyReject,
+		SlackNotionUserOverrides: map[string]string{"U-guest": "notion-user-override", "U-hidden": "notion-user-hidden"},
+	})
+	for userID, want := range map[string]string{"U-guest": "notion-user-override", "U-hidden": "notion-user-hidden"} {
+		if notionUserID, _, err := handler.submitterNotionID("T1", userID, snapshot); err != nil || notionUserID != want {
+			t.Errorf("submitterNotionID(%s) = %q, %v, want %q", userID, notionUserID, err, want)
+		}
+	}
+}
+
+// TestHandleInteractive_TriagedSubmission tests that a triaged guest's idea is
+// posted to the triage channel instead of Notion
+func TestHandleInteractive_TriagedSubmission(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}
+	slackAPI := &fakeSlack{
+		users:  map[string]*slack.User{"U123": {ID: "U123", IsUltraRestricted: true, Profile: slack.UserProfile{Email: "guest@contractor.example.com"}}},
+		posted: make(chan string, 1),
+	}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+	handler.ApplyConfig(&config.Config{SlackGuestPolicy: constants.SubmitterPolicyTriage, GuestTriageChannel: "C-triage"})
+
+	title := "Exports are slow"
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, submissionRequest(t, map[string]map[string]StateValue{
+		BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+		BlockIDTheme:       {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "Customer Pain Point"}}},
+		BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+	}))
+
+	if !strings.Contains(w.Body.String(), ModalTitleTriaged) || !strings.Contains(w.Body.String(), "sent to the team for review") {
+		t.Fatalf("response = %s, want the triaged modal", w.Body.String())
+	}
+	select {
+	case channel := <-slackAPI.posted:
+		if channel != "C-triage" {
+			t.Errorf("posted to %s, want the triage channel", channel)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("idea was not posted for triage")
+	}
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if len(backend.submissions) != 0 {
+		t.Errorf("a triaged idea was submitted: %+v", backend.submissions)
+	}
+}
+
+// TestTriageInBackground_Failure tests that a triage post that fails after the
+// submitter was answered is logged with the request's ID
+func TestTriageInBackground_Failure(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	handler := NewHandlerWithDependencies(&config.Config{}, zap.New(core), Dependencies{Backend: &fakeBackend{}, Slack: &fakeSlack{}})
+
+	ctx, cancel := context.WithCancel(requestid.NewContext(context.Background(), "req-1"))
+	cancel() // The request is over by the time the task runs
+	handler.triageInBackground(ctx, submission.Submission{Title: "Exports are slow", Source: submission.Source{SlackUserID: "U-guest"}})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for logs.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	entries := logs.FilterMessage("failed to post idea for triage").All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d triage failures, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["request_id"] != "req-1" || fields["title"] != "Exports are slow" || !strings.Contains(fmt.Sprint(fields["error"]), "GUEST_TRIAGE_CHANNEL") {
+		t.Errorf("log fields = %v, want the request ID, title and error", fields)
+	}
+}
@@ -2,10 +2,8 @@ package slack
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,32 +11,125 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/analytics"
 	"github.com/rudderlabs/hopperbot/pkg/cache"
 	"github.com/rudderlabs/hopperbot/pkg/config"
 	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/drafts"
+	"github.com/rudderlabs/hopperbot/pkg/fanout"
+	"github.com/rudderlabs/hopperbot/pkg/featureflags"
+	"github.com/rudderlabs/hopperbot/pkg/funnel"
+	"github.com/rudderlabs/hopperbot/pkg/httpclient"
+	"github.com/rudderlabs/hopperbot/pkg/installations"
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
 	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/middleware"
+	"github.com/rudderlabs/hopperbot/pkg/queue"
+	"github.com/rudderlabs/hopperbot/pkg/reminders"
+	"github.com/rudderlabs/hopperbot/pkg/requestid"
+	"github.com/rudderlabs/hopperbot/pkg/responseurl"
+	"github.com/rudderlabs/hopperbot/pkg/statuswatch"
+	"github.com/rudderlabs/hopperbot/pkg/store"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/rudderlabs/hopperbot/pkg/votes"
+	"github.com/rudderlabs/hopperbot/pkg/workers"
 	"github.com/slack-go/slack"
 	"go.uber.org/zap"
 )
 
-// MaxOptionsResults is the maximum number of options to return in a block suggestion response
-const MaxOptionsResults = 100
-
 type Handler struct {
-	config       *Config
-	notionClient *notion.Client
-	slackClient  *slack.Client
-	logger       *zap.Logger
-	metrics      *metrics.Metrics
-	cacheManager *cache.Manager
+	config        atomic.Pointer[Config] // Swapped whole by ApplyConfig on configuration reloads
+	configMu      sync.Mutex             // Serializes config updates (ApplyConfig, RotateBotToken); readers don't lock
+	notionClient  *notion.Client         // Nil when a custom backend is injected
+	backend       SubmissionBackend
+	cache         CacheStore
+	slackClient   atomic.Pointer[SlackAPI] // SLACK_BOT_TOKEN client, swapped by RotateBotToken (see defaultSlackClient)
+	slackHTTP     *httpclient.Transport    // Retrying transport shared by the Slack clients the handler creates
+	responder     Responder                // Posts delayed messages to response URLs (see respond)
+	clock         Clock
+	logger        *zap.Logger
+	metrics       *metrics.Metrics
+	cacheManager  *cache.Manager
+	analytics     *analytics.Exporter
+	timezones     *TimezoneCache
+	slackUsers    *slackUserCache // Slack users' emails for Notion mapping (see slack_users.go)
+	options       *optionsCache   // Recent options responses (see options_cache.go)
+	messages      *messages.Catalog
+	formRules     atomic.Pointer[[]FormRule] // See SetFormRules
+	limits        FieldLimits
+	reminders     *reminders.Scheduler
+	drafts        drafts.Store
+	multiStep     bool // Split the submission modal into two steps (see multistep.go)
+	attachments   bool // Show the file input and copy files to created pages (see attachments.go)
+	commentBlocks bool // Write comments to created pages as paragraphs (see comments.go)
+	queue         *queue.Queue
+	fanout        *fanout.Fanout // Secondary backends ideas are also written to; nil disables fan-out (see fanout.go)
+	auditLog      store.Store    // Submission attempts; nil disables the audit log (see audit.go)
+	funnel        *funnel.Tracker
+	statusWatcher *statuswatch.Watcher
+	votes         votes.Store // Cards whose reactions count as votes; nil disables voting (see votes.go)
+	voteReactions []string
+	votesMu       sync.Mutex // Orders vote updates so pages end with the latest count
+	customerUsage *CustomerUsage
+	quota         *submissionQuota          // Recent new ideas per user (see quota.go)
+	workers       *workers.Pool             // Work outliving requests (see workers.go); nil spawns a goroutine per task
+	flags         *featureflags.Flags       // Runtime toggles; nil enables every configured feature
+	admins        *adminAccess              // Who may run admin subcommands (see admin.go)
+	commands      *CommandRouter            // /hopperbot subcommands (see commands.go)
+	events        map[string]EventHandler   // Events API handlers by event type (see events.go)
+	recentEvents  eventIDs                  // Dispatched event IDs, to drop Slack's retries
+	form          atomic.Pointer[modalForm] // Modal fields and select options from the database schema; nil until loaded
+
+	// OAuth installs (see oauth.go); nil installations uses slackClient for every workspace
+	installations installations.Store
+	teamClients   sync.Map // Bot token -> SlackAPI for installed workspaces
+	newTeamClient func(botToken string) SlackAPI
+	oauthExchange func(ctx context.Context, code string) (*slack.OAuthV2Response, error)
+
+	// Per-workspace Notion databases (see tenants.go); teams not listed use backend and cache
+	tenantBackends map[string]SubmissionBackend
+
+	// Confirmations held while the confirmation channel is busy (see confirmation_batch.go)
+	confirmationBatch confirmationBatch
+
+	// External select data sources by action_id (see options_providers.go)
+	optionsProviders map[string]OptionsProvider
 }
 
 type Config struct {
-	SigningSecret string
-	BotToken      string
+	SigningSecret       string
+	BotToken            string
+	ConfirmationChannel string // Channel for submission confirmations; empty DMs the submitter
+	// Confirmations allowed in the channel per window before the rest are batched; 0 disables batching
+	ConfirmationBatchThreshold int
+	ConfirmationBatchWindow    time.Duration
+	MaxOptionsResults          int    // Options returned to external selects; 0 uses constants.MaxOptionsResults
+	CustomerSelectMode         string // constants.CustomerSelectStatic embeds customer options in the modal
+	ClientID                   string // Slack app client ID for the OAuth install flow
+	ClientSecret               string // Slack app client secret; also signs OAuth state
+	OAuthRedirectURL           string // redirect_uri sent to Slack; empty uses the app's default
+	BasePath                   string // Route prefix behind a reverse proxy (e.g. /hopperbot)
+	CaptchaProvider            string // Widget rendered in the web form (see middleware.CaptchaWidget); empty renders none
+	CaptchaSiteKey             string // Public key of the web form's captcha widget
+
+	// Per-user limits on new ideas (see quota.go); 0 disables each
+	SubmissionQuotaPerDay int           // New ideas per user in any 24 hours
+	SubmissionCooldown    time.Duration // Minimum time between a user's new ideas
+
+	// Submitter mapping (see slack_users.go)
+	SlackNotionUserOverrides map[string]string // Slack user ID -> Notion user ID, ahead of email matching
+	NotionFallbackUserID     string            // Notion user for submitters with none; empty rejects them
+
+	// Slack guests and hidden emails (see guest_policy.go)
+	SlackGuestPolicy   string // constants.SubmitterPolicy*; empty allows guests
+	HiddenEmailPolicy  string // constants.SubmitterPolicy*; empty rejects hidden emails
+	GuestTriageChannel string // Receives the ideas of SubmitterPolicyTriage
 }
 
 type slackRequest struct {
@@ -46,16 +137,107 @@ type slackRequest struct {
 	Values url.Values
 }
 
+// NewHandler creates a handler wired to real Notion and Slack clients built from cfg.
 func NewHandler(cfg *config.Config, logger *zap.Logger) *Handler {
-	return &Handler{
-		config: &Config{
-			SigningSecret: cfg.SlackSigningSecret,
-			BotToken:      cfg.SlackBotToken,
-		},
-		notionClient: notion.NewClient(cfg.NotionAPIKey, cfg.NotionDatabaseID, cfg.NotionClientsDBID, logger),
-		slackClient:  slack.New(cfg.SlackBotToken),
-		logger:       logger,
+	return NewHandlerWithDependencies(cfg, logger, Dependencies{})
+}
+
+// NewHandlerWithDependencies creates a handler using the given collaborators,
+// falling back to the NewHandler wiring for any left nil. Tests use it to
+// exercise HandleInteractive and other flows against fakes.
+func NewHandlerWithDependencies(cfg *config.Config, logger *zap.Logger, deps Dependencies) *Handler {
+	var notionClient *notion.Client
+	if deps.Backend == nil {
+		notionClient = notion.NewClient(cfg.NotionAPIKey, cfg.NotionDatabaseID, cfg.NotionClientsDBID, logger)
+		deps.Backend = notionClient
+	}
+	if deps.Store == nil {
+		deps.Store = deps.Backend
+	}
+	// One transport for every workspace's client, so they share a circuit breaker to slack.com
+	slackHTTPClient, slackHTTP := httpclient.NewClient("slack", constants.DefaultHTTPTimeout, logger)
+	if deps.Slack == nil {
+		deps.Slack = slack.New(cfg.SlackBotToken, slack.OptionHTTPClient(slackHTTPClient))
+	}
+	if deps.Responder == nil {
+		deps.Responder = responseurl.New(slackHTTPClient)
+	}
+	if deps.Clock == nil {
+		deps.Clock = systemClock{}
+	}
+
+	h := &Handler{
+		notionClient:  notionClient,
+		backend:       deps.Backend,
+		cache:         deps.Store,
+		clock:         deps.Clock,
+		logger:        logger,
+		messages:      messages.Default(),
+		limits:        DefaultFieldLimits(),
+		customerUsage: NewCustomerUsage(),
+		quota:         newSubmissionQuota(),
+		slackUsers:    newSlackUserCache(constants.SlackUserProfileTTL),
+		options:       newOptionsCache(constants.OptionsCacheTTL, constants.OptionsCacheMaxEntries),
+		admins:        newAdminAccess(cfg.SlackAdminUserIDs, cfg.SlackAdminUsergroup),
+		slackHTTP:     slackHTTP,
+		responder:     deps.Responder,
+		newTeamClient: func(botToken string) SlackAPI { return slack.New(botToken, slack.OptionHTTPClient(slackHTTPClient)) },
 	}
+	h.slackClient.Store(&deps.Slack)
+	h.timezones = NewTimezoneCache(func(user string) (*slack.User, error) {
+		return h.defaultSlackClient().GetUserInfo(user)
+	}, constants.SlackUserTimezoneTTL, logger)
+	h.config.Store(&Config{
+		SigningSecret:              cfg.SlackSigningSecret,
+		BotToken:                   cfg.SlackBotToken,
+		ConfirmationChannel:        cfg.ConfirmationChannel,
+		ConfirmationBatchThreshold: cfg.ConfirmationBatchThreshold,
+		ConfirmationBatchWindow:    cfg.ConfirmationBatchWindow,
+		MaxOptionsResults:          cfg.MaxOptionsResults,
+		CustomerSelectMode:         cfg.CustomerSelectMode,
+		ClientID:                   cfg.SlackClientID,
+		ClientSecret:               cfg.SlackClientSecret,
+		OAuthRedirectURL:           cfg.SlackOAuthRedirectURL,
+		BasePath:                   cfg.BasePath,
+		CaptchaProvider:            cfg.CaptchaProvider,
+		CaptchaSiteKey:             cfg.CaptchaSiteKey,
+		SubmissionQuotaPerDay:      cfg.SubmissionQuotaPerDay,
+		SubmissionCooldown:         cfg.SubmissionCooldown,
+		SlackNotionUserOverrides:   cfg.SlackNotionUserOverrides,
+		NotionFallbackUserID:       cfg.NotionFallbackUserID,
+		SlackGuestPolicy:           cfg.SlackGuestPolicy,
+		HiddenEmailPolicy:          cfg.HiddenEmailPolicy,
+		GuestTriageChannel:         cfg.GuestTriageChannel,
+	})
+	h.SetFormRules(DefaultFormRules)
+	h.commands = h.newCommandRouter()
+	h.events = h.newEventHandlers()
+	h.optionsProviders = h.newOptionsProviders()
+	return h
+}
+
+// ApplyConfig applies the reloadable handler settings of a reloaded
+// configuration (see config.Config.Reloaded): the confirmation channel and
+// batching, the options cap, the submission limits, the submitter mapping
+// and policies, and the customer org rule. Requests already in flight finish with the settings they started with.
+func (h *Handler) ApplyConfig(cfg *config.Config) {
+	h.configMu.Lock()
+	defer h.configMu.Unlock()
+
+	next := *h.config.Load()
+	next.ConfirmationChannel = cfg.ConfirmationChannel
+	next.ConfirmationBatchThreshold = cfg.ConfirmationBatchThreshold
+	next.ConfirmationBatchWindow = cfg.ConfirmationBatchWindow
+	next.MaxOptionsResults = cfg.MaxOptionsResults
+	next.SubmissionQuotaPerDay = cfg.SubmissionQuotaPerDay
+	next.SubmissionCooldown = cfg.SubmissionCooldown
+	next.SlackNotionUserOverrides = cfg.SlackNotionUserOverrides
+	next.NotionFallbackUserID = cfg.NotionFallbackUserID
+	next.SlackGuestPolicy = cfg.SlackGuestPolicy
+	next.HiddenEmailPolicy = cfg.HiddenEmailPolicy
+	next.GuestTriageChannel = cfg.GuestTriageChannel
+	h.config.Store(&next)
+	h.SetFormRules(CustomerOrgRequiredRule(cfg.CustomerOrgRequiredThemes))
 }
 
 // SetCacheManager sets the cache manager instance for the handler
@@ -63,45 +245,145 @@ func (h *Handler) SetCacheManager(cm *cache.Manager) {
 	h.cacheManager = cm
 }
 
+// SetAnalyticsExporter sets the analytics exporter used to stream submission events.
+// Passing nil (the default) disables analytics export.
+func (h *Handler) SetAnalyticsExporter(exporter *analytics.Exporter) {
+	h.analytics = exporter
+}
+
+// SetMessageCatalog sets the catalog used for all user-facing Slack messages.
+// The handler uses the built-in default messages until this is called.
+func (h *Handler) SetMessageCatalog(catalog *messages.Catalog) {
+	h.messages = catalog
+}
+
+// SetFormRules sets the conditional-requirement rules applied to submissions and
+// reflected in the modal's field hints. Passing nil disables conditional requirements.
+func (h *Handler) SetFormRules(rules []FormRule) {
+	h.formRules.Store(&rules)
+}
+
+// currentFormRules returns the rules set with SetFormRules.
+func (h *Handler) currentFormRules() []FormRule {
+	return *h.formRules.Load()
+}
+
+// SetFieldLimits sets the limits submissions are validated against and the modal's
+// hints are generated from. Unset values, and values above what Notion accepts,
+// fall back to DefaultFieldLimits.
+func (h *Handler) SetFieldLimits(limits FieldLimits) {
+	h.limits = limits.effective()
+}
+
+// SetFeatureFlags sets the runtime toggles checked before optional features
+// (reminders, drafts, confirmations, the submission queue). Flags only switch
+// off features that are configured; nil (the default) leaves them all on.
+func (h *Handler) SetFeatureFlags(flags *featureflags.Flags) {
+	h.flags = flags
+}
+
+// FormatTimeForUser formats t in the Slack user's timezone for user-facing messages
+// (confirmations, digests, reminders). Falls back to UTC if the timezone is unknown.
+func (h *Handler) FormatTimeForUser(userID string, t time.Time) string {
+	return h.timezones.Format(userID, t)
+}
+
 // Initialize initializes the handler by fetching required data from Notion
 func (h *Handler) Initialize() error {
 	// Discover data source IDs for both main and customers databases
 	// Required for API v2025-09-03 which uses data source IDs instead of database IDs
-	if err := h.notionClient.InitializeDataSources(); err != nil {
+	if err := h.backend.InitializeDataSources(); err != nil {
 		return fmt.Errorf("failed to initialize data sources: %w", err)
 	}
 
 	// Fetch the list of valid customers from the Customers database
-	if err := h.notionClient.InitializeCustomers(); err != nil {
+	if err := h.backend.InitializeCustomers(); err != nil {
 		return fmt.Errorf("failed to initialize clients: %w", err)
 	}
 
 	// Fetch the list of Notion workspace users for Slack-to-Notion user mapping
-	if err := h.notionClient.InitializeUsers(); err != nil {
+	if err := h.backend.InitializeUsers(); err != nil {
 		return fmt.Errorf("failed to initialize users: %w", err)
 	}
 
+	// Same for the databases of tenant workspaces (TENANTS_FILE)
+	if err := h.eachTenant(func(backend SubmissionBackend) error {
+		if err := backend.InitializeDataSources(); err != nil {
+			return fmt.Errorf("failed to initialize data sources: %w", err)
+		}
+		if err := backend.InitializeCustomers(); err != nil {
+			return fmt.Errorf("failed to initialize clients: %w", err)
+		}
+		if err := backend.InitializeUsers(); err != nil {
+			return fmt.Errorf("failed to initialize users: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Generate modal fields for additional database properties (non-fatal, core fields always work)
+	if err := h.LoadModalFields(); err != nil {
+		h.logger.Warn("failed to load modal fields from database schema, using core fields", zap.Error(err))
+	}
+
 	return nil
 }
 
-// InitializeCustomers refreshes the customer cache by delegating to the notion client
+// InitializeCustomers refreshes the customer cache by delegating to the notion client,
+// then the tenant customer caches. The modal fields are regenerated from the
+// database schema on the same schedule.
 func (h *Handler) InitializeCustomers() error {
-	return h.notionClient.InitializeCustomers()
+	if err := h.backend.InitializeCustomers(); err != nil {
+		return err
+	}
+	tenantErr := h.eachTenant(SubmissionBackend.InitializeCustomers)
+	if err := h.LoadModalFields(); err != nil {
+		h.logger.Warn("failed to refresh modal fields from database schema, keeping previous fields", zap.Error(err))
+	}
+	return tenantErr
 }
 
-// InitializeUsers refreshes the user cache by delegating to the notion client
+// InitializeUsers refreshes the user cache by delegating to the notion client,
+// then the tenant user caches.
 func (h *Handler) InitializeUsers() error {
-	return h.notionClient.InitializeUsers()
+	if err := h.backend.InitializeUsers(); err != nil {
+		return err
+	}
+	return h.eachTenant(SubmissionBackend.InitializeUsers)
 }
 
 // GetCachedUserEmails returns the list of cached user emails for debugging
 func (h *Handler) GetCachedUserEmails() []string {
-	return h.notionClient.GetCachedUserEmails()
+	return h.backend.GetCachedUserEmails()
 }
 
 // GetUserCacheSize returns the number of users in the cache
 func (h *Handler) GetUserCacheSize() int {
-	return h.notionClient.GetUserCacheSize()
+	return h.backend.GetUserCacheSize()
+}
+
+// CacheSizes returns the number of entries in each of the handler's in-memory
+// caches, for GET /debug/runtime. Customer, user and guest counts add up the
+// default and tenant snapshots.
+func (h *Handler) CacheSizes() map[string]int {
+	sizes := map[string]int{
+		"slack_timezones": h.timezones.Len(),
+		"slack_users":     h.slackUsers.Len(),
+		"options":         h.options.Len(),
+		"customer_usage":  h.customerUsage.Len(),
+	}
+	stores := []CacheStore{h.cache}
+	for _, backend := range h.tenantBackends {
+		stores = append(stores, backend)
+	}
+	for _, store := range stores {
+		snapshot := store.Snapshot()
+		sizes["customers"] += snapshot.CustomerCount()
+		sizes["users"] += snapshot.UserCount()
+		sizes["excluded_guests"] += snapshot.GuestCount()
+	}
+	return sizes
 }
 
 // HandleSlashCommand handles incoming Slack slash commands
@@ -117,115 +399,218 @@ func (h *Handler) HandleSlashCommand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	triggerID := req.Values.Get("trigger_id")
-	userName := req.Values.Get("user_name")
-	command := req.Values.Get("command")
-	text := strings.TrimSpace(req.Values.Get("text"))
-
-	h.logger.Info("received slash command",
-		zap.String("command", command),
-		zap.String("text", text),
-		zap.String("user", userName),
-		zap.String("trigger_id", triggerID),
-		zap.Int("trigger_id_length", len(triggerID)),
-	)
-
-	// Check if this is a refresh-cache command
-	if text == "refresh-cache" {
-		h.handleRefreshCacheCommand(w, r)
-		return
-	}
+	cmd := parseSlashCommand(req.Values)
+	ctx := logging.With(r.Context(), h.logger, zap.String("team_id", cmd.TeamID), zap.String("user_id", cmd.UserID))
+	h.logSlashCommand(ctx, cmd, req.Values.Get("user_name"))
 
-	// Default behavior: open modal
-	h.handleOpenModalCommand(w, r, triggerID, command)
+	h.commands.Route(ctx, w, cmd)
 }
 
 // handleOpenModalCommand handles the default /hopperbot command to open the modal
-func (h *Handler) handleOpenModalCommand(w http.ResponseWriter, _ *http.Request, triggerID, command string) {
+func (h *Handler) handleOpenModalCommand(ctx context.Context, w http.ResponseWriter, teamID, userID, triggerID, command string) {
+	logger := logging.FromContext(ctx, h.logger)
+
 	// Validate trigger_id
 	if triggerID == "" {
-		h.logger.Error("trigger_id is empty")
+		logger.Error("trigger_id is empty")
 		h.recordSlackCommand(command, "error")
-		respondToSlack(w, "Internal error: missing trigger_id")
+		respondToSlack(w, h.messages.Format(messages.KeyMissingTriggerID, nil))
 		return
 	}
 
 	// Build modal (customer options loaded dynamically via external select)
-	modal := BuildSubmissionModal()
+	modal := h.newSubmissionModal(teamID, userID)
 
 	// Debug: log modal structure to diagnose issue
 	if modalJSON, err := json.MarshalIndent(modal, "", "  "); err == nil {
-		h.logger.Debug("modal structure being sent to Slack", zap.String("json", string(modalJSON)))
+		logger.Debug("modal structure being sent to Slack", zap.String("json", string(modalJSON)))
 	}
 
 	// Open the modal
-	viewResponse, err := h.slackClient.OpenView(triggerID, modal)
+	viewResponse, err := h.slackFor(teamID).OpenView(triggerID, modal)
 	if err != nil {
-		h.logger.Error("failed to open modal",
+		h.recordSlackAPIError("views.open", err)
+		logger.Error("failed to open modal",
 			zap.Error(err),
 			zap.String("error_type", fmt.Sprintf("%T", err)),
 		)
 
 		// Check if it's a SlackErrorResponse with more details
 		if slackErr, ok := err.(slack.SlackErrorResponse); ok {
-			h.logger.Error("slack API error details",
+			logger.Error("slack API error details",
 				zap.String("error", slackErr.Err),
 				zap.String("response_metadata", fmt.Sprintf("%+v", slackErr.ResponseMetadata)),
 			)
 		} else if slackErrPtr, ok := err.(*slack.SlackErrorResponse); ok {
-			h.logger.Error("slack API error details (pointer)",
+			logger.Error("slack API error details (pointer)",
 				zap.String("error", slackErrPtr.Err),
 				zap.String("response_metadata", fmt.Sprintf("%+v", slackErrPtr.ResponseMetadata)),
 			)
 		} else {
 			// Log the raw error string if type assertion fails
-			h.logger.Error("unable to extract slack error details",
+			logger.Error("unable to extract slack error details",
 				zap.String("error_string", err.Error()),
 			)
 		}
 
 		// Also log the modal structure on error for debugging
 		if modalJSON, marshalErr := json.MarshalIndent(modal, "", "  "); marshalErr == nil {
-			h.logger.Error("modal that failed to open", zap.String("modal_json", string(modalJSON)))
+			logger.Error("modal that failed to open", zap.String("modal_json", string(modalJSON)))
 		}
 
 		h.recordSlackCommand(command, "error")
-		respondToSlack(w, "Failed to open submission form. Please try again.")
+		respondToSlack(w, h.messages.Format(messages.KeyOpenModalFailed, nil))
 		return
 	}
 
-	h.logger.Info("modal opened successfully", zap.String("view_id", viewResponse.ID))
+	logger.Info("modal opened successfully", zap.String("view_id", viewResponse.ID))
 	h.recordSlackCommand(command, "success")
 
 	// Respond with 200 OK immediately (empty response)
 	w.WriteHeader(http.StatusOK)
 }
 
-// handleRefreshCacheCommand handles the /hopperbot refresh-cache command
-func (h *Handler) handleRefreshCacheCommand(w http.ResponseWriter, _ *http.Request) {
-	h.logger.Info("refresh-cache command received")
+// submissionModal builds the submission modal from the current form fields,
+// adding the "Remind me" field when reminders are enabled and the "Share
+// draft" select when drafts are enabled.
+func (h *Handler) submissionModal(teamID string) slack.ModalViewRequest {
+	return h.submissionModalWith(teamID, "", ModalValues{})
+}
+
+// submissionModalWith builds the submission modal with the core fields
+// pre-filled from values (e.g. from a shared draft or a quick submission).
+// id is the draft the modal is opened from, if any; it and the values are
+// carried in private_metadata.
+func (h *Handler) submissionModalWith(teamID, id string, values ModalValues) slack.ModalViewRequest {
+	modal := BuildSubmissionModalFromFields(h.modalFields(), h.currentFormRules(), h.fieldLimits())
+	prefillModal(&modal, id, values)
+	// Closing the modal sends view_closed, which autosaves what was entered
+	modal.NotifyOnClose = h.autosaveEnabled()
+	h.useStaticCustomerSelects(teamID, modal.Blocks.BlockSet)
+	if h.attachments {
+		modal.Blocks.BlockSet = append(modal.Blocks.BlockSet, buildAttachmentsBlock())
+	}
+	if h.reminders != nil && h.flags.Enabled(featureflags.Reminders) {
+		modal.Blocks.BlockSet = append(modal.Blocks.BlockSet, buildRemindMeBlock())
+	}
+	if h.drafts != nil && h.flags.Enabled(featureflags.Drafts) {
+		modal.Blocks.BlockSet = append(modal.Blocks.BlockSet, buildShareDraftBlock())
+	}
+	return modal
+}
+
+// handleRefreshCacheCommand handles the /hopperbot refresh-cache command.
+//
+// The command is acknowledged silently; once the refresh finishes, an
+// ephemeral summary of each cache's outcome is posted through the command's
+// response_url.
+func (h *Handler) handleRefreshCacheCommand(ctx context.Context, w http.ResponseWriter, responseURL string) {
+	logger := logging.FromContext(ctx, h.logger)
+	logger.Info("refresh-cache command received")
 
 	if h.cacheManager == nil {
-		h.logger.Error("cache manager not initialized, cannot process refresh-cache command")
+		logger.Error("cache manager not initialized, cannot process refresh-cache command")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	h.logger.Info("manual cache refresh triggered via slash command")
+	logger.Info("manual cache refresh triggered via slash command")
 
-	// Trigger async refresh (non-blocking)
-	h.cacheManager.ManualRefresh()
+	// Refresh in the background: it takes far longer than Slack waits for the acknowledgement
+	h.background("refresh_cache", func() { h.reportCacheRefresh(responseURL) })
 
-	// Silent response - just return 200 OK (no visible message to user)
 	w.WriteHeader(http.StatusOK)
 }
 
+// refreshProgressDelay is how long a manual cache refresh runs before the
+// admin is told it's still going; shortened by tests.
+var refreshProgressDelay = constants.ManualRefreshProgressDelay
+
+// reportCacheRefresh runs a manual cache refresh and posts its outcome as an
+// ephemeral message through the slash command's response_url, preceded by a
+// note that it's still running when it takes longer than refreshProgressDelay.
+func (h *Handler) reportCacheRefresh(responseURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.ManualRefreshReportTimeout)
+	defer cancel()
+
+	if responseURL == "" {
+		h.cacheManager.ManualRefreshAndWait(ctx)
+		return
+	}
+
+	notified := make(chan struct{})
+	progress := time.AfterFunc(refreshProgressDelay, func() {
+		defer close(notified)
+		if err := h.respondLater(responseURL, h.messages.Format(messages.KeyCacheRefreshInProgress, nil)); err != nil {
+			h.logger.Warn("failed to report cache refresh progress", zap.Error(err))
+		}
+	})
+	report, err := h.cacheManager.ManualRefreshAndWait(ctx)
+	if !progress.Stop() {
+		<-notified // Keep the outcome after the progress note
+	}
+	if err := h.respondLater(responseURL, h.formatRefreshReport(report, err)); err != nil {
+		h.logger.Error("failed to report cache refresh", zap.Error(err))
+	}
+}
+
+// respondLater posts an ephemeral message through a slash command's or
+// interaction's response_url, for results that arrive after the 3 second
+// acknowledgement.
+func (h *Handler) respondLater(responseURL, text string) error {
+	return h.respond(responseURL, responseurl.Message{Text: text, ResponseType: responseurl.Ephemeral})
+}
+
+// respond posts msg through a response_url (see pkg/responseurl).
+func (h *Handler) respond(responseURL string, msg responseurl.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultHTTPTimeout)
+	defer cancel()
+	err := h.responder.Post(ctx, responseURL, msg)
+	if err != nil {
+		h.recordSlackAPIError("response_url", err)
+	}
+	return err
+}
+
+// formatRefreshReport renders the refresh-cache summary, one line per cache.
+func (h *Handler) formatRefreshReport(report cache.RefreshReport, err error) string {
+	if err != nil {
+		return h.messages.Format(messages.KeyCacheRefreshNoReport, messages.Params{"error": err.Error()})
+	}
+
+	duration := report.Duration.Round(time.Millisecond).String()
+	var lines []string
+	if failed := report.Failed(); len(failed) > 0 {
+		lines = append(lines, h.messages.Format(messages.KeyCacheRefreshFailed, messages.Params{
+			"duration": duration,
+			"failed":   len(failed),
+			"total":    len(report.Caches),
+		}))
+	} else {
+		lines = append(lines, h.messages.Format(messages.KeyCacheRefreshDone, messages.Params{"duration": duration}))
+	}
+	for _, result := range report.Caches {
+		params := messages.Params{
+			"cache":    result.Name,
+			"duration": result.Duration.Round(time.Millisecond).String(),
+		}
+		if result.Err != nil {
+			params["error"] = result.Err.Error()
+			lines = append(lines, h.messages.Format(messages.KeyCacheRefreshLineFailed, params))
+		} else {
+			lines = append(lines, h.messages.Format(messages.KeyCacheRefreshLine, params))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // HandleInteractive handles incoming Slack interactive component submissions
 func (h *Handler) HandleInteractive(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	ctx := r.Context()
 
 	// Validate and parse Slack request
 	req, ok := h.validateSlackRequest(w, r)
@@ -235,17 +620,21 @@ func (h *Handler) HandleInteractive(w http.ResponseWriter, r *http.Request) {
 
 	payload, err := h.parseInteractionPayload(req.Values)
 	if err != nil {
-		h.handleError(w, err, "Bad request", http.StatusBadRequest)
+		h.handleError(ctx, w, err, "Bad request", http.StatusBadRequest)
 		return
 	}
 
 	// Validate the payload
 	if err := payload.Validate(); err != nil {
-		h.handleError(w, err, "Invalid interaction payload", http.StatusBadRequest)
+		h.handleError(ctx, w, err, "Invalid interaction payload", http.StatusBadRequest)
 		return
 	}
 
-	h.logger.Info("received interaction",
+	// Everything logged for this interaction, here and in the clients, carries the team and user
+	ctx = logging.With(ctx, h.logger, zap.String("team_id", payload.Team.ID), zap.String("user_id", payload.User.ID))
+	logger := logging.FromContext(ctx, h.logger)
+
+	logger.Info("received interaction",
 		zap.String("type", payload.Type),
 		zap.String("callback_id", payload.View.CallbackID),
 		zap.String("user", payload.User.Username),
@@ -254,8 +643,40 @@ func (h *Handler) HandleInteractive(w http.ResponseWriter, r *http.Request) {
 	// Record interaction received
 	h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "received")
 
+	if payload.Type == InteractionTypeMessageAction && payload.CallbackID == constants.ShortcutSubmitMessage {
+		h.handleMessageShortcut(w, payload)
+		return
+	}
+
+	if payload.Type == InteractionTypeBlockActions && payload.View.CallbackID == HomeCallbackID {
+		h.handleHomeAction(w, payload)
+		return
+	}
+
+	if payload.Type == InteractionTypeViewSubmission && payload.View.CallbackID == ModalCallbackIDEditSelect {
+		h.handleEditSelection(w, payload)
+		return
+	}
+
+	if payload.Type == InteractionTypeViewSubmission && payload.View.CallbackID == ModalCallbackIDSubmitStepOne {
+		h.handleStepOneSubmission(w, payload)
+		return
+	}
+
+	// The details step of a multi-step submission only has the remaining fields
+	restoreStepOneState(payload)
+
+	if payload.Type == InteractionTypeBlockActions && h.handleDraftAction(ctx, w, payload) {
+		return
+	}
+
+	if payload.Type == InteractionTypeViewClosed {
+		h.handleViewClosed(w, payload)
+		return
+	}
+
 	if !h.shouldProcessSubmission(payload) {
-		h.logger.Info("ignoring interaction",
+		logger.Info("ignoring interaction",
 			zap.String("type", payload.Type),
 			zap.String("callback_id", payload.View.CallbackID),
 		)
@@ -264,91 +685,228 @@ func (h *Handler) HandleInteractive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Fetch Slack user email and map to Notion user
-	slackUser, err := h.slackClient.GetUserInfo(payload.User.ID)
-	if err != nil {
-		h.logger.Error("failed to fetch Slack user info", zap.Error(err), zap.String("user_id", payload.User.ID))
-		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "user_lookup_error")
+	// Reject submissions up front while the ideas database is missing required properties
+	if err := h.backendFor(payload.Team.ID).SchemaError(); err != nil {
+		logger.Warn("rejecting submission while the ideas database schema is invalid", zap.Error(err))
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "schema_invalid")
 		h.recordModalSubmission("error")
-		respondWithErrors(w, map[string]string{
-			BlockIDTitle: "Failed to identify user. Please try again.",
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, nil, "schema_invalid")
+		respondWithViewErrors(w, payload, map[string]string{
+			BlockIDTitle: h.messages.Format(messages.KeySubmissionsPaused, nil),
 		})
 		return
 	}
 
-	// Map Slack user email to Notion user UUID
-	slackEmail := slackUser.Profile.Email
-	h.logger.Info("attempting to map Slack user to Notion user",
-		zap.String("slack_email", slackEmail),
-		zap.String("slack_user_id", payload.User.ID),
-		zap.String("slack_username", payload.User.Username),
-		zap.String("slack_real_name", slackUser.RealName),
-	)
+	// Pin one cache snapshot for the whole submission so a concurrent refresh
+	// can't change the users or customers between validation and creation
+	snapshot := h.cacheFor(payload.Team.ID).Snapshot()
+	setCacheVersionHeader(w, snapshot)
 
-	notionUserID, found := h.notionClient.GetNotionUserIDByEmail(slackEmail)
-	if !found {
-		h.logger.Warn("Slack user email not found in Notion workspace",
-			zap.String("email", slackEmail),
-			zap.String("normalized_email", strings.ToLower(strings.TrimSpace(slackEmail))),
-			zap.String("slack_user_id", payload.User.ID),
-			zap.String("slack_username", payload.User.Username),
-			zap.Int("notion_user_cache_size", h.notionClient.GetUserCacheSize()),
-		)
-		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "user_not_found")
-		h.recordModalSubmission("error")
-		respondWithErrors(w, map[string]string{
-			BlockIDTitle: fmt.Sprintf("Your Slack email (%s) is not associated with a Notion account in this workspace. Please contact your administrator.", slackEmail),
-		})
+	sub, err := h.extractAndValidateFields(ctx, payload.Team.ID, payload.View.State, snapshot)
+	if err != nil {
+		logger.Warn("field validation failed", zap.Error(err))
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "validation_error")
+		h.recordModalSubmission("validation_error")
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, nil, "validation_error")
+		respondWithViewErrors(w, payload, err.(fieldValidationError).errors)
 		return
 	}
 
-	h.logger.Info("successfully mapped Slack user to Notion user",
-		zap.String("slack_email", slackEmail),
-		zap.String("notion_user_id", notionUserID),
-	)
-
-	fields, err := h.extractAndValidateFields(payload.View.State)
+	reminderDelay, err := h.extractReminderDelay(payload.View.State)
 	if err != nil {
-		h.logger.Warn("field validation failed", zap.Error(err))
+		logger.Warn("reminder validation failed", zap.Error(err))
 		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "validation_error")
 		h.recordModalSubmission("validation_error")
-		respondWithErrors(w, err.(fieldValidationError).errors)
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, nil, "validation_error")
+		respondWithViewErrors(w, payload, err.(fieldValidationError).errors)
 		return
 	}
 
-	// Add the submitter's Notion user ID to the fields
-	fields[constants.AliasSubmittedBy] = notionUserID
+	// Attach where the submission came from
+	sub.Source = submission.Source{
+		Channel:        submission.ChannelSlackModal,
+		SlackUserID:    payload.User.ID,
+		SlackTeamID:    payload.Team.ID,
+		SlackPermalink: decodeModalMetadata(payload.View.PrivateMetadata).Permalink,
+		SubmittedAt:    h.clock.Now().UTC(),
+	}
+
+	// Queued submissions look up the submitter in the worker, so the modal closes within Slack's 3 seconds
+	queued := payload.View.CallbackID != ModalCallbackIDEditForm && h.queue != nil && h.flags.Enabled(featureflags.SubmissionQueue)
+	if !queued && !h.attachModalSubmitter(ctx, w, payload, snapshot, &sub) {
+		return
+	}
 
-	h.logger.Info("extracted form fields",
-		zap.String("title", fields[constants.AliasTitle]),
-		zap.String("theme", fields[constants.AliasTheme]),
-		zap.String("product_area", fields[constants.AliasProductArea]),
-		zap.String("comments", fields[constants.AliasComments]),
-		zap.String("customer_org", fields[constants.AliasCustomerOrg]),
-		zap.String("submitted_by", notionUserID),
-		zap.String("slack_email", slackUser.Profile.Email),
+	logger.Info("extracted form fields",
+		zap.String("title", sub.Title),
+		zap.String("theme", sub.Theme),
+		zap.String("product_area", sub.ProductArea),
+		zap.String("comments", sub.Comments),
+		zap.Strings("customer_org", sub.CustomerOrgs),
+		zap.String("submitted_by", sub.SubmitterNotionID),
+		zap.String("slack_email", sub.Source.SubmitterEmail),
 	)
 
-	if err := h.notionClient.SubmitForm(fields); err != nil {
-		h.logger.Error("failed to submit to Notion", zap.Error(err))
+	h.expandArtifactTitles(payload.Team.ID, sub.Artifacts)
+
+	// Edits update the page the form was opened for, synchronously
+	if payload.View.CallbackID == ModalCallbackIDEditForm {
+		h.updateSubmission(ctx, w, payload, sub)
+		return
+	}
+
+	// Reject the same idea submitted again right after it was created, e.g. from a second open form
+	if message := h.duplicateMessage(sub); message != "" {
+		logger.Info("rejected duplicate submission", zap.String("title", sub.Title))
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "duplicate")
+		h.recordModalSubmission("duplicate")
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, &sub, "duplicate")
+		respondWithViewErrors(w, payload, map[string]string{BlockIDTitle: message})
+		return
+	}
+
+	// Hold back users submitting too often (SUBMISSION_COOLDOWN, SUBMISSION_QUOTA_PER_DAY)
+	if message := h.limitMessage(ctx, sub); message != "" {
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "limited")
+		h.recordModalSubmission("limited")
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, &sub, "limited")
+		respondWithViewErrors(w, payload, map[string]string{BlockIDTitle: message})
+		return
+	}
+
+	// With the submission queue enabled, close the modal now and create the page in the background
+	if queued {
+		if h.enqueueSubmission(queue.Job{Submission: sub, ReminderDelay: reminderDelay}, 0) {
+			h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "queued")
+			h.recordModalSubmission("queued")
+			h.customerUsage.Record(sub.CustomerOrgs)
+			h.deleteSubmittedDraft(payload)
+			h.respondSubmitted(w, payload)
+			return
+		}
+		if !h.attachModalSubmitter(ctx, w, payload, snapshot, &sub) {
+			h.releaseSubmission(sub)
+			return
+		}
+	}
+
+	page, err := h.createPage(ctx, payload.Team.ID, sub)
+	if err != nil && h.queueIfUnavailable(queue.Job{Submission: sub, ReminderDelay: reminderDelay}, err) {
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "queued")
+		h.recordModalSubmission("queued")
+		h.customerUsage.Record(sub.CustomerOrgs)
+		h.deleteSubmittedDraft(payload)
+		respondWithView(w, ResponseActionUpdate, queuedModal(h.messages.Format(messages.KeySubmitQueuedUnavailable, messages.Params{"title": sub.Title})))
+		return
+	}
+	if err != nil {
+		logger.Error("failed to submit to Notion", zap.Error(err))
+		h.releaseSubmission(sub)
 		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "notion_error")
 		h.recordModalSubmission("error")
-		respondWithErrors(w, map[string]string{
-			BlockIDTitle: fmt.Sprintf("Failed to submit: %v", err),
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, &sub, "notion_error")
+		respondWithViewErrors(w, payload, map[string]string{
+			BlockIDTitle: h.submitErrorMessage(ctx, err),
 		})
 		return
 	}
 
-	h.logger.Info("successfully submitted form to Notion",
+	logger.Info("successfully submitted form to Notion",
 		zap.String("user", payload.User.Username),
+		zap.String("page_id", page.ID),
+		zap.String("page_url", page.URL),
+		zap.Bool("recovered", page.Recovered),
 	)
 
+	h.scheduleReminder(payload.Team.ID, payload.User.ID, sub.Title, page, reminderDelay)
+	h.trackIdea(sub, page)
+	h.watchStatus(sub, page)
+
+	// Post the confirmation and fill in the page body after responding so they don't delay closing the modal
+	h.background("confirmation", func() {
+		ctx := context.WithoutCancel(ctx)
+		copies := h.fanoutWrite(ctx, sub, page)
+		thread := h.postConfirmation(ctx, sub, page, copies)
+		h.appendComments(ctx, sub, page, thread)
+		h.attachFiles(ctx, sub, page)
+	})
+
 	// Record successful submission
 	h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "success")
 	h.recordModalSubmission("success")
+	h.customerUsage.Record(sub.CustomerOrgs)
+	h.deleteSubmittedDraft(payload)
+	h.trackSubmission(analytics.EventSubmissionCreated, payload, &sub, "success")
 
 	// Respond with success - modal will close automatically
-	h.respondSuccess(w)
+	h.respondSubmitted(w, payload)
+}
+
+// attachModalSubmitter maps the Slack user submitting the modal to their
+// Notion user and attaches them to the submission. If that fails, it responds
+// with the error on the modal, or sends the idea for triage (SLACK_GUEST_POLICY,
+// HIDDEN_EMAIL_POLICY) and says so, and returns false.
+func (h *Handler) attachModalSubmitter(ctx context.Context, w http.ResponseWriter, payload *InteractionPayload, snapshot *notion.CacheSnapshot, sub *submission.Submission) bool {
+	logger := logging.FromContext(ctx, h.logger)
+
+	notionUserID, slackUser, err := h.submitterNotionID(payload.Team.ID, payload.User.ID, snapshot)
+	unmapped, failed := err.(submitterError)
+	if failed && unmapped.lookup {
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, unmapped.reason)
+		h.recordModalSubmission("error")
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, nil, unmapped.reason)
+		respondWithViewErrors(w, payload, map[string]string{
+			BlockIDTitle: h.withReference(ctx, unmapped.message),
+		})
+		return false
+	}
+
+	// Map Slack user email to Notion user UUID
+	slackEmail := slackUser.Email
+	logger.Info("attempting to map Slack user to Notion user",
+		zap.String("slack_email", slackEmail),
+		zap.String("slack_username", payload.User.Username),
+		zap.String("slack_real_name", slackUser.RealName),
+	)
+
+	// Edits can't be triaged, so they are rejected instead
+	if failed && unmapped.triage && payload.View.CallbackID != ModalCallbackIDEditForm {
+		sub.Source.SubmitterEmail = slackEmail
+		sub.Source.SubmitterName = slackUser.RealName
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "triaged")
+		h.recordModalSubmission("triaged")
+		h.deleteSubmittedDraft(payload)
+		h.triageInBackground(ctx, *sub)
+		respondWithView(w, ResponseActionUpdate, triagedModal(h.messages.Format(messages.KeySubmissionTriaged, messages.Params{"title": sub.Title})))
+		return false
+	}
+	if failed {
+		logger.Warn("Slack user can't be mapped to a Notion user, rejecting submission",
+			zap.String("reason", unmapped.reason),
+			zap.String("email", slackEmail),
+			zap.String("normalized_email", strings.ToLower(strings.TrimSpace(slackEmail))),
+			zap.String("slack_username", payload.User.Username),
+			zap.Int("notion_user_cache_size", snapshot.UserCount()),
+			zap.Uint64("cache_version", snapshot.Version),
+		)
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, unmapped.reason)
+		h.recordModalSubmission("error")
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, nil, unmapped.reason)
+		respondWithViewErrors(w, payload, map[string]string{
+			BlockIDTitle: unmapped.message,
+		})
+		return false
+	}
+
+	logger.Info("successfully mapped Slack user to Notion user",
+		zap.String("slack_email", slackEmail),
+		zap.String("notion_user_id", notionUserID),
+	)
+
+	sub.SubmitterNotionID = notionUserID
+	sub.Source.SubmitterEmail = slackEmail
+	sub.Source.SubmitterName = slackUser.RealName
+	return true
 }
 
 // HandleOptionsRequest handles block suggestion requests for external select options
@@ -372,37 +930,95 @@ func (h *Handler) HandleOptionsRequest(w http.ResponseWriter, r *http.Request) {
 	// Parse the options request payload
 	optionsRequest, err := h.parseOptionsRequest(req.Values)
 	if err != nil {
-		h.handleError(w, err, "Bad request", http.StatusBadRequest)
+		h.handleError(ctx, w, err, "Bad request", http.StatusBadRequest)
 		return
 	}
 
 	// Validate the options request
 	if err := optionsRequest.Validate(); err != nil {
-		h.handleError(w, err, "Invalid options request", http.StatusBadRequest)
+		h.handleError(ctx, w, err, "Invalid options request", http.StatusBadRequest)
 		return
 	}
 
-	// Validate action_id is for customer org selection
-	if optionsRequest.ActionID != ActionIDCustomerOrgSelect {
-		h.logger.Warn("unexpected action_id in options request",
+	// Route to the provider registered for the select (see options_providers.go)
+	provider, found := h.optionsProviders[optionsRequest.ActionID]
+	if !found {
+		logging.FromContext(ctx, h.logger).Warn("unexpected action_id in options request",
 			zap.String("action_id", optionsRequest.ActionID),
-			zap.String("expected", ActionIDCustomerOrgSelect),
 		)
 		h.respondWithOptions(w, []Option{})
 		return
 	}
 
-	// Get all valid customers from cache and filter based on search query
-	allCustomers := h.notionClient.GetValidCustomers()
-	filteredOptions := FilterCustomerOptions(allCustomers, optionsRequest.Value, constants.MaxOptionsResults)
+	snapshot := h.cacheFor(optionsRequest.Team.ID).Snapshot()
+	setCacheVersionHeader(w, snapshot)
+	search := OptionsSearch{
+		ActionID:   optionsRequest.ActionID,
+		TeamID:     optionsRequest.Team.ID,
+		UserID:     optionsRequest.User.ID,
+		Query:      optionsRequest.Value,
+		MaxResults: optionsLimit(h.config.Load().MaxOptionsResults),
+		MoreText:   h.messages.Format(messages.KeyOptionsMoreResults, nil),
+		Snapshot:   snapshot,
+	}
+
+	// Serve the options from the cache if the same search was answered moments ago
+	var key optionsCacheKey
+	if provider.Version != nil {
+		key = newOptionsCacheKey(search.ActionID, provider.Version(search), search.Query, search.MaxResults, search.MoreText)
+	}
+	if key.version != "" {
+		if body, found := h.options.get(key, h.clock.Now()); found {
+			h.recordOptionsCacheLookup("hit")
+			w.Header().Set(HeaderOptionsCache, "hit")
+			logging.FromContext(ctx, h.logger).Debug("responding to options request from the options cache",
+				zap.String("action_id", optionsRequest.ActionID),
+				zap.String("query", optionsRequest.Value),
+			)
+			writeOptionsResponse(w, body)
+			return
+		}
+		h.recordOptionsCacheLookup("miss")
+	}
+
+	filteredOptions, err := provider.Search(ctx, search)
+	if err != nil {
+		// Slack shows no options for an error response too; empty ones at least aren't retried
+		logging.FromContext(ctx, h.logger).Error("failed to search options",
+			zap.String("action_id", optionsRequest.ActionID),
+			zap.String("query", optionsRequest.Value),
+			zap.Error(err),
+		)
+		h.respondWithOptions(w, []Option{})
+		return
+	}
+	filteredOptions = truncateOptions(filteredOptions, search.MaxResults, search.MoreText)
 
-	h.logger.Debug("responding to options request",
+	logging.FromContext(ctx, h.logger).Debug("responding to options request",
 		zap.String("action_id", optionsRequest.ActionID),
 		zap.String("query", optionsRequest.Value),
 		zap.Int("results_count", len(filteredOptions)),
 	)
 
-	h.respondWithOptions(w, filteredOptions)
+	body, err := json.Marshal(OptionsResponse{Options: filteredOptions})
+	if err != nil {
+		h.handleError(ctx, w, err, "Failed to encode options", http.StatusInternalServerError)
+		return
+	}
+	if key.version == "" {
+		writeOptionsResponse(w, body)
+		return
+	}
+	h.options.put(key, body, h.clock.Now())
+	w.Header().Set(HeaderOptionsCache, "miss")
+	writeOptionsResponse(w, body)
+}
+
+// writeOptionsResponse writes an encoded options response.
+func writeOptionsResponse(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
 }
 
 // parseOptionsRequest parses and unmarshals an options request from the request values
@@ -449,10 +1065,10 @@ func (h *Handler) parseInteractionPayload(values url.Values) (*InteractionPayloa
 }
 
 // shouldProcessSubmission checks if the interaction should be processed
-// Returns true only for view submissions with the correct callback ID
+// Returns true only for view submissions of the submission or edit form
 func (h *Handler) shouldProcessSubmission(payload *InteractionPayload) bool {
 	return payload.Type == InteractionTypeViewSubmission &&
-		payload.View.CallbackID == ModalCallbackIDSubmitForm
+		(payload.View.CallbackID == ModalCallbackIDSubmitForm || payload.View.CallbackID == ModalCallbackIDEditForm)
 }
 
 // fieldValidationError wraps validation errors with the error map for Slack
@@ -464,119 +1080,271 @@ func (e fieldValidationError) Error() string {
 	return fmt.Sprintf("validation failed: %v", e.errors)
 }
 
+// formField identifies an input in the submission modal.
+type formField struct {
+	blockID  string
+	actionID string
+}
+
+// requiredFormFields must be present in every submitted view. Missing optional
+// fields (comments, customer org) are treated as left empty.
+var requiredFormFields = []formField{
+	{BlockIDTitle, ActionIDTitleInput},
+	{BlockIDTheme, ActionIDThemeSelect},
+	{BlockIDProductArea, ActionIDProductAreaSelect},
+}
+
+// optionalFormFields may be absent from views opened from older versions of the modal.
+var optionalFormFields = []formField{
+	{BlockIDComments, ActionIDCommentsInput},
+	{BlockIDCustomerOrg, ActionIDCustomerOrgSelect},
+}
+
+// checkFormFields records fields missing from the submitted view and returns a
+// single "please reopen the form" error if any required field is missing.
+//
+// Without this, an outdated or custom-modified modal produces one confusing
+// "Failed to extract" error per missing block. The error is attached to the
+// first input still present in the view, since Slack can only display errors
+// on blocks that exist.
+func (h *Handler) checkFormFields(ctx context.Context, state ViewState) error {
+	var missingRequired []string
+	for _, field := range requiredFormFields {
+		if !state.HasField(field.blockID, field.actionID) {
+			missingRequired = append(missingRequired, field.blockID)
+			h.recordMissingFormField(fieldMetricNames[field.blockID], true)
+		}
+	}
+	for _, field := range optionalFormFields {
+		if !state.HasField(field.blockID, field.actionID) {
+			h.recordMissingFormField(fieldMetricNames[field.blockID], false)
+		}
+	}
+
+	if len(missingRequired) == 0 {
+		return nil
+	}
+
+	logging.FromContext(ctx, h.logger).Warn("submitted view is missing required form fields, asking user to reopen the form",
+		zap.Strings("missing_blocks", missingRequired),
+	)
+
+	errorBlock := BlockIDTitle
+	for _, field := range append(slices.Clone(requiredFormFields), optionalFormFields...) {
+		if state.HasField(field.blockID, field.actionID) {
+			errorBlock = field.blockID
+			break
+		}
+	}
+
+	return fieldValidationError{
+		errors: map[string]string{
+			errorBlock: h.messages.Format(messages.KeyFormOutdated, nil),
+		},
+	}
+}
+
 // extractAndValidateFields extracts all form fields from the view state
 // and validates required fields with comprehensive length and value checks.
-// Returns a combined map of all fields or validation errors.
-func (h *Handler) extractAndValidateFields(state ViewState) (map[string]string, error) {
-	fields := make(map[string]string)
+// Customer orgs are resolved to Notion page IDs against snapshot.
+// Returns the submission (without submitter or source) or validation errors.
+func (h *Handler) extractAndValidateFields(ctx context.Context, teamID string, state ViewState, snapshot *notion.CacheSnapshot) (submission.Submission, error) {
+	var sub submission.Submission
+	if err := h.checkFormFields(ctx, state); err != nil {
+		return submission.Submission{}, err
+	}
+
 	validationErrors := make(map[string]string)
+	options := h.selectOptions()
 
-	// Extract and validate title (required, max 2000 chars)
-	title, err := state.GetValue(BlockIDTitle, ActionIDTitleInput)
-	if err != nil {
-		validationErrors[BlockIDTitle] = fmt.Sprintf("Failed to extract title: %v", err)
-		h.recordValidationError("title")
+	// Extract and validate title (required, max 2000 chars) and theme (single select, required)
+	if title, message := h.validateTitle(state); message != "" {
+		validationErrors[BlockIDTitle] = message
 	} else {
-		title = strings.TrimSpace(title)
-		if title == "" {
-			validationErrors[BlockIDTitle] = "Title is required"
-			h.recordValidationError("title")
-		} else if len(title) > constants.MaxTitleLength {
-			validationErrors[BlockIDTitle] = fmt.Sprintf("Title exceeds maximum length of %d characters (current: %d)",
-				constants.MaxTitleLength, len(title))
-			h.recordValidationError("title")
-		} else {
-			fields[constants.AliasTitle] = title
-		}
+		sub.Title = title
 	}
-
-	// Extract and validate theme (single select, required)
-	theme, err := state.GetSelectedOption(BlockIDTheme, ActionIDThemeSelect)
-	if err != nil {
-		validationErrors[BlockIDTheme] = fmt.Sprintf("Failed to extract theme: %v", err)
-		h.recordValidationError("theme")
+	if theme, message := h.validateTheme(state, options.Themes); message != "" {
+		validationErrors[BlockIDTheme] = message
 	} else {
-		theme = strings.TrimSpace(theme)
-		if theme == "" {
-			validationErrors[BlockIDTheme] = "Theme is required"
-			h.recordValidationError("theme")
-		} else if !slices.Contains(constants.ValidThemeCategories, theme) {
-			validationErrors[BlockIDTheme] = fmt.Sprintf("Invalid theme selected: %s", theme)
-			h.recordValidationError("theme")
-		} else {
-			fields[constants.AliasTheme] = theme
-		}
+		sub.Theme = theme
 	}
 
 	// Extract and validate product area (single select, required)
 	productArea, err := state.GetSelectedOption(BlockIDProductArea, ActionIDProductAreaSelect)
 	if err != nil {
-		validationErrors[BlockIDProductArea] = fmt.Sprintf("Failed to extract product area: %v", err)
+		validationErrors[BlockIDProductArea] = h.messages.Format(messages.KeyFieldExtractFailed, messages.Params{"field": "product area", "error": err})
 		h.recordValidationError("product_area")
 	} else {
 		productArea = strings.TrimSpace(productArea)
 		if productArea == "" {
-			validationErrors[BlockIDProductArea] = "Product area is required"
+			validationErrors[BlockIDProductArea] = h.messages.Format(messages.KeyFieldRequired, messages.Params{"field": "Product area"})
 			h.recordValidationError("product_area")
-		} else if !slices.Contains(constants.ValidProductAreas, productArea) {
-			validationErrors[BlockIDProductArea] = fmt.Sprintf("Invalid product area selected: %s", productArea)
+		} else if !slices.Contains(options.ProductAreas, productArea) {
+			validationErrors[BlockIDProductArea] = h.messages.Format(messages.KeyInvalidSelection, messages.Params{"field": "product area", "value": productArea})
 			h.recordValidationError("product_area")
 		} else {
-			fields[constants.AliasProductArea] = productArea
+			sub.ProductArea = productArea
 		}
 	}
 
 	// Return validation errors if any required fields failed
 	if len(validationErrors) > 0 {
-		return nil, fieldValidationError{
+		return submission.Submission{}, fieldValidationError{
 			errors: validationErrors,
 		}
 	}
 
-	// Extract and validate comments (optional, max 2000 chars)
+	// Extract and validate comments (optional, max 2000 chars, or 3000 with comment blocks)
 	if comments, err := state.GetValue(BlockIDComments, ActionIDCommentsInput); err == nil {
 		comments = strings.TrimSpace(comments)
 		if comments != "" {
-			if len(comments) > constants.MaxCommentLength {
+			if maxLength := h.fieldLimits().commentsLength(); len(comments) > maxLength {
 				h.recordValidationError("comments")
-				return nil, fieldValidationError{
+				return submission.Submission{}, fieldValidationError{
 					errors: map[string]string{
-						BlockIDComments: fmt.Sprintf("Comments exceed maximum length of %d characters (current: %d)",
-							constants.MaxCommentLength, len(comments)),
+						BlockIDComments: h.messages.Format(messages.KeyFieldTooLong, messages.Params{
+							"field": "Comment", "max": maxLength, "current": len(comments),
+						}),
 					},
 				}
 			}
-			fields[constants.AliasComments] = comments
+			sub.Comments = comments
+		}
+	}
+
+	// Extract and validate artifacts (optional Slack canvas and huddle links, max 10)
+	if links, err := state.GetValue(BlockIDArtifacts, ActionIDArtifactsInput); err == nil {
+		artifacts, invalid := parseArtifactLinks(links)
+		var message string
+		switch {
+		case invalid != "":
+			message = h.messages.Format(messages.KeyInvalidArtifact, messages.Params{"value": invalid})
+		case len(artifacts) > constants.MaxArtifacts:
+			message = h.messages.Format(messages.KeyTooManyArtifacts, messages.Params{"max": constants.MaxArtifacts, "count": len(artifacts)})
+		}
+		if message != "" {
+			h.recordValidationError("artifacts")
+			return submission.Submission{}, fieldValidationError{errors: map[string]string{BlockIDArtifacts: message}}
 		}
+		sub.Artifacts = artifacts
 	}
 
+	// Extract and validate attachments (optional files, copied to the page once it is created)
+	attachments, message := h.validateAttachments(state)
+	if message != "" {
+		h.recordValidationError("attachments")
+		return submission.Submission{}, fieldValidationError{errors: map[string]string{BlockIDAttachments: message}}
+	}
+	sub.Attachments = attachments
+
 	// Extract and validate customer org (multi-select, optional, max 10)
-	if orgs, err := state.GetSelectedOptions(BlockIDCustomerOrg, ActionIDCustomerOrgSelect); err == nil && len(orgs) > 0 {
-		if len(orgs) > constants.MaxCustomerOrgSelections {
+	if orgs, err := selectedCustomerOrgs(state); err == nil && len(orgs) > 0 {
+		if len(orgs) > h.limits.MaxCustomerOrgs {
 			h.recordValidationError("customer_org")
-			return nil, fieldValidationError{
+			return submission.Submission{}, fieldValidationError{
 				errors: map[string]string{
-					BlockIDCustomerOrg: fmt.Sprintf("Too many customer orgs selected (max: %d, selected: %d)",
-						constants.MaxCustomerOrgSelections, len(orgs)),
+					BlockIDCustomerOrg: h.messages.Format(messages.KeyTooManySelections, messages.Params{
+						"max": h.limits.MaxCustomerOrgs, "selected": len(orgs),
+					}),
 				},
 			}
 		}
-		// Validate each customer org against valid values
-		validCustomers := h.notionClient.GetValidCustomers()
+		// Validate each customer org against valid values and resolve its page ID
+		ids := make([]string, 0, len(orgs))
 		for _, org := range orgs {
-			if !slices.Contains(validCustomers, org) {
+			pageID, found := snapshot.CustomerPageID(org)
+			if !found {
 				h.recordValidationError("customer_org")
-				return nil, fieldValidationError{
+				return submission.Submission{}, fieldValidationError{
 					errors: map[string]string{
-						BlockIDCustomerOrg: fmt.Sprintf("Invalid customer org selected: %s", org),
+						BlockIDCustomerOrg: h.messages.Format(messages.KeyInvalidCustomerOrg, messages.Params{"value": org}),
 					},
 				}
 			}
+			ids = append(ids, pageID)
+		}
+		sub.CustomerOrgs = orgs
+		sub.CustomerOrgIDs = ids
+	}
+
+	// Extract fields generated from the database schema (optional)
+	extra, err := h.extractSchemaFields(teamID, state, snapshot)
+	if err != nil {
+		return submission.Submission{}, err
+	}
+	sub.Extra = extra
+
+	// Apply conditional requirements (e.g., customer org required for pain points)
+	violations := evaluateFormRules(h.currentFormRules(), map[string][]string{
+		BlockIDTheme:       nonEmpty(sub.Theme),
+		BlockIDProductArea: nonEmpty(sub.ProductArea),
+		BlockIDComments:    nonEmpty(sub.Comments),
+		BlockIDCustomerOrg: sub.CustomerOrgs,
+	})
+	if len(violations) > 0 {
+		ruleErrors := make(map[string]string, len(violations))
+		for _, violation := range violations {
+			ruleErrors[violation.Field] = h.messages.Format(messages.KeyFieldRequiredWhen, messages.Params{
+				"field": violation.FieldLabel, "when": violation.WhenLabel, "value": violation.TriggerValue,
+			})
+			h.recordValidationError(fieldMetricNames[violation.Field])
 		}
-		fields[constants.AliasCustomerOrg] = strings.Join(orgs, ",")
+		return submission.Submission{}, fieldValidationError{errors: ruleErrors}
+	}
+
+	return sub, nil
+}
+
+// validateTitle returns the trimmed title, or the error to show on the title
+// block if it is missing or too long.
+func (h *Handler) validateTitle(state ViewState) (string, string) {
+	title, err := state.GetValue(BlockIDTitle, ActionIDTitleInput)
+	if err != nil {
+		h.recordValidationError("title")
+		return "", h.messages.Format(messages.KeyFieldExtractFailed, messages.Params{"field": "title", "error": err})
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		h.recordValidationError("title")
+		return "", h.messages.Format(messages.KeyFieldRequired, messages.Params{"field": "Title"})
+	}
+	if len(title) > h.limits.TitleMaxLength {
+		h.recordValidationError("title")
+		return "", h.messages.Format(messages.KeyFieldTooLong, messages.Params{
+			"field": "Title", "max": h.limits.TitleMaxLength, "current": len(title),
+		})
+	}
+	return title, ""
+}
+
+// validateTheme returns the selected theme, or the error to show on the theme
+// block if none of themes is selected.
+func (h *Handler) validateTheme(state ViewState, themes []string) (string, string) {
+	theme, err := state.GetSelectedOption(BlockIDTheme, ActionIDThemeSelect)
+	if err != nil {
+		h.recordValidationError("theme")
+		return "", h.messages.Format(messages.KeyFieldExtractFailed, messages.Params{"field": "theme", "error": err})
+	}
+	theme = strings.TrimSpace(theme)
+	if theme == "" {
+		h.recordValidationError("theme")
+		return "", h.messages.Format(messages.KeyFieldRequired, messages.Params{"field": "Theme"})
+	}
+	if !slices.Contains(themes, theme) {
+		h.recordValidationError("theme")
+		return "", h.messages.Format(messages.KeyInvalidSelection, messages.Params{"field": "theme", "value": theme})
 	}
+	return theme, ""
+}
 
-	return fields, nil
+// selectedCustomerOrgs returns the selected customer names, ignoring the
+// truncation indicator option (OptionValueMoreResults) if it was picked.
+func selectedCustomerOrgs(state ViewState) ([]string, error) {
+	orgs, err := state.GetSelectedOptions(BlockIDCustomerOrg, ActionIDCustomerOrgSelect)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(orgs, func(org string) bool { return org == OptionValueMoreResults }), nil
 }
 
 // respondSuccess sends a successful empty response to Slack that closes the modal
@@ -588,8 +1356,8 @@ func (h *Handler) respondSuccess(w http.ResponseWriter) {
 
 // handleError handles errors consistently across all handlers by logging the error
 // and sending an appropriate HTTP response with a user-friendly message
-func (h *Handler) handleError(w http.ResponseWriter, err error, userMessage string, statusCode int) {
-	h.logger.Error("handler error",
+func (h *Handler) handleError(ctx context.Context, w http.ResponseWriter, err error, userMessage string, statusCode int) {
+	logging.FromContext(ctx, h.logger).Error("handler error",
 		zap.Error(err),
 		zap.String("user_message", userMessage),
 		zap.Int("status_code", statusCode),
@@ -600,24 +1368,15 @@ func (h *Handler) handleError(w http.ResponseWriter, err error, userMessage stri
 // validateSlackRequest validates and parses a Slack request
 // Returns the parsed request and true if valid, or nil and false if invalid (error response already written)
 func (h *Handler) validateSlackRequest(w http.ResponseWriter, r *http.Request) (*slackRequest, bool) {
-	// Read body
-	defer r.Body.Close()
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		h.handleError(w, err, "Bad request", http.StatusBadRequest)
-		return nil, false
-	}
-
-	// Verify Slack request signature
-	if !h.verifySlackRequest(r.Header, body) {
-		h.handleError(w, fmt.Errorf("invalid Slack signature"), "Unauthorized", http.StatusUnauthorized)
+	body, ok := h.readSlackRequestBody(w, r)
+	if !ok {
 		return nil, false
 	}
 
 	// Parse form data
 	values, err := url.ParseQuery(string(body))
 	if err != nil {
-		h.handleError(w, err, "Bad request", http.StatusBadRequest)
+		h.handleError(r.Context(), w, err, "Bad request", http.StatusBadRequest)
 		return nil, false
 	}
 
@@ -627,31 +1386,29 @@ func (h *Handler) validateSlackRequest(w http.ResponseWriter, r *http.Request) (
 	}, true
 }
 
-// verifySlackRequest verifies that the request came from Slack
-func (h *Handler) verifySlackRequest(headers http.Header, body []byte) bool {
-	timestamp := headers.Get(HeaderSlackRequestTimestamp)
-	signature := headers.Get(HeaderSlackSignature)
-
-	if timestamp == "" || signature == "" {
-		return false
-	}
-
-	// Check timestamp is within 5 minutes
-	ts, err := strconv.ParseInt(timestamp, 10, 64)
+// readSlackRequestBody reads the request body and verifies its Slack signature.
+// Returns false if invalid (error response already written).
+func (h *Handler) readSlackRequestBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	// Read body
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		return false
+		h.handleError(r.Context(), w, err, "Bad request", http.StatusBadRequest)
+		return nil, false
 	}
-	if time.Now().Unix()-ts > constants.MaxSlackRequestAge {
-		return false
+
+	// Verify Slack request signature
+	if !h.verifySlackRequest(r.Header, body) {
+		h.handleError(r.Context(), w, fmt.Errorf("invalid Slack signature"), "Unauthorized", http.StatusUnauthorized)
+		return nil, false
 	}
 
-	// Compute signature
-	sigBaseString := fmt.Sprintf("%s:%s:%s", SignatureVersion, timestamp, string(body))
-	mac := hmac.New(sha256.New, []byte(h.config.SigningSecret))
-	mac.Write([]byte(sigBaseString))
-	expectedSignature := SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+	return body, true
+}
 
-	return hmac.Equal([]byte(expectedSignature), []byte(signature))
+// verifySlackRequest verifies that the request came from Slack
+func (h *Handler) verifySlackRequest(headers http.Header, body []byte) bool {
+	return middleware.VerifySlackSignature(h.config.Load().SigningSecret, h.clock.Now(), headers, body)
 }
 
 // respondToSlack sends a response back to Slack
@@ -665,6 +1422,19 @@ func respondToSlack(w http.ResponseWriter, message string) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// respondWithBlocks sends an ephemeral Block Kit response back to Slack, with
+// text as the notification fallback
+func respondWithBlocks(w http.ResponseWriter, text string, blocks []slack.Block) {
+	response := map[string]any{
+		"response_type": "ephemeral",
+		"text":          text,
+		"blocks":        blocks,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
 // respondWithErrors sends a view submission response with validation errors
 func respondWithErrors(w http.ResponseWriter, errors map[string]string) {
 	response := ViewSubmissionResponse{
@@ -676,3 +1446,53 @@ func respondWithErrors(w http.ResponseWriter, errors map[string]string) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// submitErrorMessage explains a failed submission on the modal, with specific
+// guidance for the Notion failures users can act on (or should report),
+// followed by the request reference.
+func (h *Handler) submitErrorMessage(ctx context.Context, err error) string {
+	return h.withReference(ctx, h.submitErrorText(err))
+}
+
+// submitErrorText is submitErrorMessage without the reference.
+func (h *Handler) submitErrorText(err error) string {
+	if notion.IsUnavailable(err) {
+		return h.messages.Format(messages.KeySubmitUnavailable, nil)
+	}
+	var apiErr *notion.NotionAPIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == notion.ErrorCodeRateLimited || apiErr.Status == http.StatusTooManyRequests:
+			return h.messages.Format(messages.KeySubmitRateLimited, nil)
+		case apiErr.Code == notion.ErrorCodeUnauthorized || apiErr.Code == notion.ErrorCodeRestricted || apiErr.Code == notion.ErrorCodeObjectNotFound:
+			return h.messages.Format(messages.KeySubmitNoAccess, nil)
+		case apiErr.Code == notion.ErrorCodeValidation && apiErr.Message != "":
+			return h.messages.Format(messages.KeySubmitRejected, messages.Params{"error": apiErr.Message})
+		}
+	}
+	return h.messages.Format(messages.KeySubmitFailed, messages.Params{"error": err})
+}
+
+// withReference appends the request ID in ctx (see pkg/requestid) to an error
+// shown in Slack, so a user reporting it gives support what to search the logs
+// for. text is returned unchanged outside a request.
+func (h *Handler) withReference(ctx context.Context, text string) string {
+	id := requestid.FromContext(ctx)
+	if id == "" {
+		return text
+	}
+	return text + " " + h.messages.Format(messages.KeyErrorReference, messages.Params{"request_id": id})
+}
+
+// setCacheVersionHeader reports which cache snapshot served the request (for debugging stale data).
+func setCacheVersionHeader(w http.ResponseWriter, snapshot *notion.CacheSnapshot) {
+	w.Header().Set(HeaderCacheVersion, strconv.FormatUint(snapshot.Version, 10))
+}
+
+// nonEmpty wraps a single field value for rule evaluation, treating "" as no value.
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
@@ -2,20 +2,28 @@ package slack
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/cache"
 	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/slack-go/slack"
 	"go.uber.org/zap"
 )
 
@@ -430,3 +438,224 @@ func TestRespondSuccess(t *testing.T) {
 		t.Errorf("expected empty response object, got %v", resp)
 	}
 }
+
+// TestExtractAndValidateFields_MissingBlocks tests handling of outdated or modified modals
+func TestExtractAndValidateFields_MissingBlocks(t *testing.T) {
+	handler := NewHandler(&config.Config{SlackSigningSecret: "secret"}, zap.NewNop())
+	outdated := messages.Default().Format(messages.KeyFormOutdated, nil)
+
+	title := "Exports are slow"
+	titleValue := StateValue{Type: "plain_text_input", Value: &title}
+	themeValue := StateValue{Type: "static_select", SelectedOption: &SelectedOption{Value: "New Feature Idea"}}
+	areaValue := StateValue{Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}
+	commentsValue := StateValue{Type: "plain_text_input"}
+
+	tests := []struct {
+		name       string
+		values     map[string]map[string]StateValue
+		wantErrors map[string]string
+	}{
+		{
+			name: "optional blocks missing are treated as empty",
+			values: map[string]map[string]StateValue{
+				BlockIDTitle:       {ActionIDTitleInput: titleValue},
+				BlockIDTheme:       {ActionIDThemeSelect: themeValue},
+				BlockIDProductArea: {ActionIDProductAreaSelect: areaValue},
+			},
+		},
+		{
+			name: "required block missing",
+			values: map[string]map[string]StateValue{
+				BlockIDTitle: {ActionIDTitleInput: titleValue},
+				BlockIDTheme: {ActionIDThemeSelect: themeValue},
+			},
+			wantErrors: map[string]string{BlockIDTitle: outdated},
+		},
+		{
+			name: "error shown on first present block",
+			values: map[string]map[string]StateValue{
+				BlockIDComments: {ActionIDCommentsInput: commentsValue},
+			},
+			wantErrors: map[string]string{BlockIDComments: outdated},
+		},
+		{
+			name:       "empty view state",
+			values:     nil,
+			wantErrors: map[string]string{BlockIDTitle: outdated},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := handler.extractAndValidateFields(context.Background(), "T456", ViewState{Values: tt.values}, handler.notionClient.Snapshot())
+			if tt.wantErrors == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if sub.Title != title || sub.Comments != "" || len(sub.CustomerOrgs) != 0 {
+					t.Errorf("submission = %+v", sub)
+				}
+				return
+			}
+
+			validationErr, ok := err.(fieldValidationError)
+			if !ok {
+				t.Fatalf("expected fieldValidationError, got %v", err)
+			}
+			if !reflect.DeepEqual(validationErr.errors, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", validationErr.errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+// TestSelectedCustomerOrgs_IgnoresMoreResultsOption tests that the truncation indicator is not treated as a customer
+func TestSelectedCustomerOrgs_IgnoresMoreResultsOption(t *testing.T) {
+	state := ViewState{Values: map[string]map[string]StateValue{
+		BlockIDCustomerOrg: {ActionIDCustomerOrgSelect: {
+			Type:            "multi_external_select",
+			SelectedOptions: []SelectedOption{{Value: "Acme"}, {Value: OptionValueMoreResults}},
+		}},
+	}}
+
+	orgs, err := selectedCustomerOrgs(state)
+	if err != nil {
+		t.Fatalf("selectedCustomerOrgs() error = %v", err)
+	}
+	if !reflect.DeepEqual(orgs, []string{"Acme"}) {
+		t.Errorf("selectedCustomerOrgs() = %v, want [Acme]", orgs)
+	}
+}
+
+// TestSlackErrorCategory tests mapping Slack Web API errors to bounded metric label values
+func TestSlackErrorCategory(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "missing scope", err: slack.SlackErrorResponse{Err: "missing_scope"}, want: metrics.ErrorCategoryAuth},
+		{name: "revoked token", err: fmt.Errorf("open view: %w", slack.SlackErrorResponse{Err: "token_revoked"}), want: metrics.ErrorCategoryAuth},
+		{name: "rate limited", err: &slack.RateLimitedError{RetryAfter: time.Second}, want: metrics.ErrorCategoryRateLimit},
+		{name: "expired trigger", err: slack.SlackErrorResponse{Err: "expired_trigger_id"}, want: metrics.ErrorCategoryValidation},
+		{name: "internal error", err: slack.SlackErrorResponse{Err: "internal_error"}, want: metrics.ErrorCategoryBackend5xx},
+		{name: "server error", err: slack.StatusCodeError{Code: http.StatusBadGateway, Status: "502 Bad Gateway"}, want: metrics.ErrorCategoryBackend5xx},
+		{name: "deadline", err: fmt.Errorf("post: %w", context.DeadlineExceeded), want: metrics.ErrorCategoryTimeout},
+		{name: "other", err: errors.New("connection reset"), want: metrics.ErrorCategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slackErrorCategory(tt.err); got != tt.want {
+				t.Errorf("slackErrorCategory() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestHandleSlashCommand_RefreshCache tests that refresh-cache reports each cache's outcome
+func TestHandleSlashCommand_RefreshCache(t *testing.T) {
+	slackAPI := &fakeSlack{
+		posted:    make(chan string, 1),
+		sent:      make(chan url.Values, 1),
+		responded: make(chan string, 1),
+	}
+	handler := newInteractiveTestHandler(&fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}, slackAPI)
+	cacheMgr := cache.NewManager(handler, nil, zap.NewNop(), time.Hour)
+	cacheMgr.Register("templates", func(context.Context) error { return nil })
+	handler.SetCacheManager(cacheMgr)
+	defer cacheMgr.Stop()
+
+	body := url.Values{
+		"command":      {"/hopperbot"},
+		"text":         {"refresh-cache"},
+		"team_id":      {"T456"},
+		"channel_id":   {"C123"},
+		"response_url": {"https://hooks.slack.com/commands/T456/1/abc"},
+	}.Encode()
+	w := httptest.NewRecorder()
+	handler.HandleSlashCommand(w, createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Fatalf("response = %d %q, want a silent 200", w.Code, w.Body.String())
+	}
+
+	select {
+	case endpoint := <-slackAPI.responded:
+		if endpoint != "https://hooks.slack.com/commands/T456/1/abc" {
+			t.Errorf("report sent to %q, want the response_url", endpoint)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("refresh report was not sent")
+	}
+	text := (<-slackAPI.sent).Get("text")
+	for _, want := range []string{"Caches refreshed", "customers: refreshed", "users: refreshed", "templates: refreshed"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report = %q, want it to contain %q", text, want)
+		}
+	}
+}
+
+// TestHandleSlashCommand_RefreshCacheProgress tests that a slow refresh-cache
+// posts a progress note before its outcome
+func TestHandleSlashCommand_RefreshCacheProgress(t *testing.T) {
+	defer func(delay time.Duration) { refreshProgressDelay = delay }(refreshProgressDelay)
+	refreshProgressDelay = 10 * time.Millisecond
+
+	slackAPI := &fakeSlack{
+		posted: make(chan string, 2),
+		sent:   make(chan url.Values, 2),
+	}
+	handler := newInteractiveTestHandler(&fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}, slackAPI)
+	cacheMgr := cache.NewManager(handler, nil, zap.NewNop(), time.Hour)
+	release := make(chan struct{})
+	cacheMgr.Register("templates", func(context.Context) error {
+		<-release
+		return nil
+	})
+	handler.SetCacheManager(cacheMgr)
+	defer cacheMgr.Stop()
+
+	body := url.Values{
+		"command":      {"/hopperbot"},
+		"text":         {"refresh-cache"},
+		"team_id":      {"T456"},
+		"response_url": {"https://hooks.slack.com/commands/T456/1/abc"},
+	}.Encode()
+	handler.HandleSlashCommand(httptest.NewRecorder(), createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+
+	for i, want := range []string{"Still refreshing caches", "Caches refreshed"} {
+		select {
+		case values := <-slackAPI.sent:
+			if text := values.Get("text"); !strings.Contains(text, want) {
+				t.Errorf("message %d = %q, want it to contain %q", i+1, text, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("message %d (%s) was not sent", i+1, want)
+		}
+		if i == 0 {
+			close(release)
+		}
+	}
+}
+
+// TestFormatRefreshReport tests the summary of failed refreshes
+func TestFormatRefreshReport(t *testing.T) {
+	handler := newInteractiveTestHandler(&fakeBackend{}, &fakeSlack{})
+
+	text := handler.formatRefreshReport(cache.RefreshReport{
+		Caches: []cache.CacheResult{
+			{Name: "customers", Duration: 5 * time.Minute, Err: errors.New("notion unavailable")},
+			{Name: "users", Duration: 1500 * time.Millisecond},
+		},
+		Duration: 5*time.Minute + 1500*time.Millisecond,
+	}, nil)
+	for _, want := range []string{"1 of 2 caches failed", "customers: failed after 5m0s (notion unavailable)", "users: refreshed in 1.5s"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report = %q, want it to contain %q", text, want)
+		}
+	}
+
+	if text := handler.formatRefreshReport(cache.RefreshReport{}, cache.ErrStopped); !strings.Contains(text, "unavailable") {
+		t.Errorf("report = %q, want the outcome reported unavailable", text)
+	}
+}
@@ -0,0 +1,154 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// homeIdeasLimit is how many recent submissions the App Home tab lists.
+const homeIdeasLimit = 20
+
+// homeTimeout bounds building and publishing the App Home view, which happens
+// after the event has already been acknowledged.
+const homeTimeout = 10 * time.Second
+
+// handleAppHomeOpened publishes the App Home tab of a user opening it. The
+// Messages tab is left alone.
+func (h *Handler) handleAppHomeOpened(ctx context.Context, req EventRequest) {
+	if req.Event.Tab != "home" {
+		return
+	}
+	h.logger.Info("app home opened",
+		zap.String("user", req.Event.User),
+		zap.String("event_id", req.EventID),
+	)
+	h.publishHome(ctx, req.TeamID, req.Event.User)
+}
+
+// handleHomeAction handles button clicks on the App Home tab: opening the
+// submission modal or refreshing the list of submissions.
+func (h *Handler) handleHomeAction(w http.ResponseWriter, payload *InteractionPayload) {
+	for _, action := range payload.Actions {
+		switch action.ActionID {
+		case ActionIDHomeSubmitIdea:
+			if _, err := h.slackFor(payload.Team.ID).OpenView(payload.TriggerID, h.newSubmissionModal(payload.Team.ID, payload.User.ID)); err != nil {
+				h.recordSlackAPIError("views.open", err)
+				h.logger.Error("failed to open modal from app home",
+					zap.String("user", payload.User.ID),
+					zap.Error(err),
+				)
+				h.recordSlackInteraction(payload.Type, HomeCallbackID, "error")
+				continue
+			}
+			h.recordSlackInteraction(payload.Type, HomeCallbackID, "success")
+		case ActionIDHomeRefresh:
+			h.background("publish_home", func() { h.publishHome(context.Background(), payload.Team.ID, payload.User.ID) })
+			h.recordSlackInteraction(payload.Type, HomeCallbackID, "success")
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// publishHome renders and publishes the App Home tab for a user.
+// Failures are logged; the user sees the previously published view.
+func (h *Handler) publishHome(ctx context.Context, teamID, userID string) {
+	ctx, cancel := context.WithTimeout(ctx, homeTimeout)
+	defer cancel()
+
+	req := slack.PublishViewContextRequest{UserID: userID, View: h.buildHomeView(teamID, userID)}
+	if _, err := h.slackFor(teamID).PublishViewContext(ctx, req); err != nil {
+		h.recordSlackAPIError("views.publish", err)
+		h.logger.Error("failed to publish app home",
+			zap.String("user", userID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	h.logger.Debug("app home published", zap.String("user", userID))
+}
+
+// buildHomeView renders the App Home tab: a header, the "Submit an idea" and
+// "Refresh" buttons, and the user's most recent submissions.
+func (h *Handler) buildHomeView(teamID, userID string) slack.HomeTabViewRequest {
+	submitButton := slack.NewButtonBlockElement(ActionIDHomeSubmitIdea, "", newPlainText(ButtonSubmitIdea))
+	submitButton.Style = slack.StylePrimary
+	refreshButton := slack.NewButtonBlockElement(ActionIDHomeRefresh, "", newPlainText(ButtonRefresh))
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(newPlainText(HomeHeaderText)),
+		slack.NewActionBlock(BlockIDHomeActions, submitButton, refreshButton),
+		slack.NewDividerBlock(),
+	}
+	for _, line := range h.homeIdeaLines(teamID, userID) {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, line, false, false), nil, nil))
+	}
+
+	return slack.HomeTabViewRequest{
+		Type:       slack.VTHomeTab,
+		CallbackID: HomeCallbackID,
+		Blocks:     slack.Blocks{BlockSet: blocks},
+	}
+}
+
+// homeIdeaLines lists the user's most recent submissions for the App Home tab.
+func (h *Handler) homeIdeaLines(teamID, userID string) []string {
+	return h.submittedIdeaLines(teamID, userID, messages.KeyHomeNoIdeas, messages.KeyHomeLookupFailed)
+}
+
+// submittedIdeaLines lists the user's most recent submissions, found through
+// the Submitted By property of the Notion user their Slack email maps to.
+// Lookup failures are rendered as the failed message instead of the list, and
+// an empty list as the none message.
+func (h *Handler) submittedIdeaLines(teamID, userID string, none, failed messages.Key) []string {
+	notionUserID, profile, found, err := h.slackUserNotionID(teamID, userID, h.cacheFor(teamID).Snapshot())
+	if err != nil {
+		h.logger.Error("failed to fetch Slack user info to list submitted ideas", zap.String("user_id", userID), zap.Error(err))
+		return []string{h.messages.Format(failed, nil)}
+	}
+	if !found {
+		return []string{h.messages.Format(messages.KeyHomeUserNotFound, messages.Params{"email": profile.Email})}
+	}
+
+	result, err := h.backendFor(teamID).SubmitterIdeas(notionUserID, homeIdeasLimit)
+	if err != nil {
+		h.logger.Error("failed to query submitted ideas",
+			zap.String("user_id", userID),
+			zap.String("notion_user_id", notionUserID),
+			zap.Error(err),
+		)
+		return []string{h.messages.Format(failed, nil)}
+	}
+	if result.Total == 0 {
+		return []string{h.messages.Format(none, nil)}
+	}
+
+	count := strconv.Itoa(result.Total)
+	if result.Truncated {
+		count += "+"
+	}
+	lines := []string{h.messages.Format(messages.KeyHomeIdeas, messages.Params{
+		"count": count,
+		"shown": len(result.Ideas),
+	})}
+	for _, idea := range result.Ideas {
+		status := idea.Status
+		if status == "" {
+			status = h.messages.Format(messages.KeyReminderNoStatus, nil)
+		}
+		lines = append(lines, h.messages.Format(messages.KeyHomeIdeaLine, messages.Params{
+			"title":     idea.Title,
+			"url":       idea.URL,
+			"status":    status,
+			"submitted": h.FormatTimeForUser(userID, idea.CreatedTime),
+		}))
+	}
+	return lines
+}
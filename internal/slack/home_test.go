@@ -0,0 +1,185 @@
+package slack
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// homeText concatenates the text of a Home view's section blocks
+func homeText(view slack.HomeTabViewRequest) string {
+	var lines []string
+	for _, block := range view.Blocks.BlockSet {
+		if section, ok := block.(*slack.SectionBlock); ok && section.Text != nil {
+			lines = append(lines, section.Text.Text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TestHandleEvents_URLVerification tests that the Events API challenge is echoed back
+func TestHandleEvents_URLVerification(t *testing.T) {
+	handler := newInteractiveTestHandler(&fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}, &fakeSlack{})
+
+	body := []byte(`{"type":"url_verification","challenge":"challenge-123"}`)
+	w := httptest.NewRecorder()
+	handler.HandleEvents(w, createValidSlackRequest(http.MethodPost, "/slack/events", body, "secret"))
+
+	var response map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response["challenge"] != "challenge-123" {
+		t.Errorf("challenge = %q, want challenge-123", response["challenge"])
+	}
+
+	// Unsigned requests are rejected
+	w = httptest.NewRecorder()
+	handler.HandleEvents(w, httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(string(body))))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+// TestHandleEvents_AppHomeOpened tests that opening the Home tab publishes the user's submissions
+func TestHandleEvents_AppHomeOpened(t *testing.T) {
+	created := time.Date(2025, 11, 12, 10, 0, 0, 0, time.UTC)
+	snapshot := notion.NewCacheSnapshot(nil, map[string]string{"alice@example.com": "notion-alice"})
+
+	tests := []struct {
+		name         string
+		email        string
+		ideas        *notion.IdeaQueryResult
+		ideasErr     error
+		wantContains []string
+	}{
+		{
+			name:  "ideas",
+			email: "alice@example.com",
+			ideas: &notion.IdeaQueryResult{Total: 2, Ideas: []notion.IdeaSummary{
+				{Title: "Dark mode", URL: "https://www.notion.so/page-1", Status: "Planned", CreatedTime: created},
+				{Title: "SSO", URL: "https://www.notion.so/page-2", CreatedTime: created},
+			}},
+			wantContains: []string{
+				"You have submitted *2* ideas. Most recent 2:",
+				"• <https://www.notion.so/page-1|Dark mode> – Planned",
+				"• <https://www.notion.so/page-2|SSO> – not triaged yet",
+			},
+		},
+		{
+			name:         "no ideas",
+			email:        "alice@example.com",
+			ideas:        &notion.IdeaQueryResult{},
+			wantContains: []string{"You haven't submitted any ideas yet"},
+		},
+		{
+			name:         "unmapped user",
+			email:        "bob@example.com",
+			wantContains: []string{"Your Slack email (bob@example.com) is not associated with a Notion account"},
+		},
+		{
+			name:         "query error",
+			email:        "alice@example.com",
+			ideasErr:     errors.New("notion unavailable"),
+			wantContains: []string{"Failed to load your ideas from Notion"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slackAPI := &fakeSlack{
+				users:     map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: tt.email}}},
+				published: make(chan slack.HomeTabViewRequest, 1),
+			}
+			handler := NewHandlerWithDependencies(&config.Config{SlackSigningSecret: "secret"}, zap.NewNop(), Dependencies{
+				Backend: &fakeBackend{snapshot: snapshot, ideas: tt.ideas, ideasErr: tt.ideasErr},
+				Slack:   slackAPI,
+			})
+
+			body := []byte(`{"type":"event_callback","event_id":"Ev1","event":{"type":"app_home_opened","user":"U123","tab":"home"}}`)
+			w := httptest.NewRecorder()
+			handler.HandleEvents(w, createValidSlackRequest(http.MethodPost, "/slack/events", body, "secret"))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+
+			var view slack.HomeTabViewRequest
+			select {
+			case view = <-slackAPI.published:
+			case <-time.After(2 * time.Second):
+				t.Fatal("home view was not published")
+			}
+
+			if view.Type != slack.VTHomeTab || view.CallbackID != HomeCallbackID {
+				t.Errorf("view type = %q, callback ID = %q", view.Type, view.CallbackID)
+			}
+			text := homeText(view)
+			for _, want := range tt.wantContains {
+				if !strings.Contains(text, want) {
+					t.Errorf("home text = %q, want it to contain %q", text, want)
+				}
+			}
+		})
+	}
+}
+
+// TestHandleEvents_IgnoresMessagesTab tests that only the Home tab is rendered
+func TestHandleEvents_IgnoresMessagesTab(t *testing.T) {
+	slackAPI := &fakeSlack{published: make(chan slack.HomeTabViewRequest, 1)}
+	handler := newInteractiveTestHandler(&fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}, slackAPI)
+
+	body := []byte(`{"type":"event_callback","event":{"type":"app_home_opened","user":"U123","tab":"messages"}}`)
+	w := httptest.NewRecorder()
+	handler.HandleEvents(w, createValidSlackRequest(http.MethodPost, "/slack/events", body, "secret"))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	select {
+	case <-slackAPI.published:
+		t.Error("messages tab should not publish a home view")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestHandleInteractive_HomeRefresh tests that the Refresh button republishes the Home tab
+func TestHandleInteractive_HomeRefresh(t *testing.T) {
+	slackAPI := &fakeSlack{
+		users:     map[string]*slack.User{"U123": {ID: "U123"}},
+		published: make(chan slack.HomeTabViewRequest, 1),
+	}
+	handler := newInteractiveTestHandler(&fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}, slackAPI)
+
+	payload, err := json.Marshal(InteractionPayload{
+		Type:    InteractionTypeBlockActions,
+		User:    User{ID: "U123"},
+		Team:    Team{ID: "T456"},
+		View:    View{Type: string(slack.VTHomeTab), CallbackID: HomeCallbackID},
+		Actions: []Action{{ActionID: ActionIDHomeRefresh, BlockID: BlockIDHomeActions}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, createValidSlackRequest(http.MethodPost, "/slack/interactive", []byte(body), "secret"))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	select {
+	case <-slackAPI.published:
+	case <-time.After(2 * time.Second):
+		t.Fatal("home view was not republished")
+	}
+}
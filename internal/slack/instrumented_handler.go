@@ -1,16 +1,23 @@
 package slack
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/analytics"
 	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
 )
 
 // SetMetrics sets the metrics instance for the handler and its dependencies
 func (h *Handler) SetMetrics(m *metrics.Metrics) {
 	h.metrics = m
+	h.slackHTTP.SetMetrics(m)
 	// Also set metrics on the Notion client
-	if h.notionClient != nil {
-		h.notionClient.SetMetrics(m)
+	if h.backend != nil {
+		h.backend.SetMetrics(m)
 	}
 }
 
@@ -21,6 +28,59 @@ func (h *Handler) recordSlackCommand(command, status string) {
 	}
 }
 
+// recordSubcommand records a routed slash subcommand
+func (h *Handler) recordSubcommand(subcommand string) {
+	if h.metrics != nil {
+		h.metrics.SlackSubcommandsTotal.WithLabelValues(subcommand).Inc()
+	}
+}
+
+// recordAttachment records a file from the modal attached to a created page
+func (h *Handler) recordAttachment(outcome string) {
+	if h.metrics != nil {
+		h.metrics.AttachmentsTotal.WithLabelValues(outcome).Inc()
+	}
+}
+
+// recordVote records a vote reaction added to or removed from a confirmation card
+func (h *Handler) recordVote(added bool) {
+	if h.metrics != nil {
+		action := "removed"
+		if added {
+			action = "added"
+		}
+		h.metrics.VotesTotal.WithLabelValues(action).Inc()
+	}
+}
+
+// recordDigest records a submissions digest by outcome (posted or failed)
+func (h *Handler) recordDigest(status string) {
+	if h.metrics != nil {
+		h.metrics.DigestsTotal.WithLabelValues(status).Inc()
+	}
+}
+
+// recordAdminCommandDenied records an admin subcommand run by a user who isn't an admin
+func (h *Handler) recordAdminCommandDenied(subcommand string) {
+	if h.metrics != nil {
+		h.metrics.SlackAdminCommandsDenied.WithLabelValues(subcommand).Inc()
+	}
+}
+
+// recordSubmissionLimited records a new idea rejected by SUBMISSION_COOLDOWN or SUBMISSION_QUOTA_PER_DAY
+func (h *Handler) recordSubmissionLimited(reason string) {
+	if h.metrics != nil {
+		h.metrics.SubmissionsLimited.WithLabelValues(reason).Inc()
+	}
+}
+
+// recordSubmissionLimitOverride records an admin going over the submission limits or resetting a user's quota
+func (h *Handler) recordSubmissionLimitOverride(override string) {
+	if h.metrics != nil {
+		h.metrics.SubmissionLimitOverrides.WithLabelValues(override).Inc()
+	}
+}
+
 // recordSlackInteraction records metrics for interactive component events
 func (h *Handler) recordSlackInteraction(interactionType, callbackID, status string) {
 	if h.metrics != nil {
@@ -42,15 +102,138 @@ func (h *Handler) recordValidationError(field string) {
 	}
 }
 
+// recordMissingFormField records metrics for form fields absent from a submitted view
+func (h *Handler) recordMissingFormField(field string, required bool) {
+	if h.metrics != nil {
+		h.metrics.SlackFormFieldsMissing.WithLabelValues(field, strconv.FormatBool(required)).Inc()
+	}
+}
+
+// recordSlackAPIError records a failed Slack Web API call by method (e.g. "views.open") and error category
+func (h *Handler) recordSlackAPIError(operation string, err error) {
+	if h.metrics != nil {
+		h.metrics.SlackAPIErrors.WithLabelValues(operation, slackErrorCategory(err)).Inc()
+	}
+}
+
+// slackErrorCategory maps a Slack Web API error to one of metrics.ErrorCategories.
+// Error responses (ok: false) are categorized by their error code; codes not
+// listed here mean Slack rejected the request, so they count as validation.
+func slackErrorCategory(err error) string {
+	if metrics.IsTimeoutError(err) {
+		return metrics.ErrorCategoryTimeout
+	}
+
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		return metrics.ErrorCategoryRateLimit
+	}
+	var statusErr slack.StatusCodeError
+	if errors.As(err, &statusErr) {
+		return metrics.StatusErrorCategory(statusErr.Code)
+	}
+	var slackErr slack.SlackErrorResponse
+	if !errors.As(err, &slackErr) {
+		return metrics.ErrorCategoryUnknown
+	}
+	switch slackErr.Err {
+	case "not_authed", "invalid_auth", "account_inactive", "token_revoked", "token_expired",
+		"missing_scope", "not_allowed_token_type", "no_permission", "ekm_access_denied", "access_denied":
+		return metrics.ErrorCategoryAuth
+	case "ratelimited", "rate_limited":
+		return metrics.ErrorCategoryRateLimit
+	case "internal_error", "fatal_error", "service_unavailable", "request_timeout":
+		return metrics.ErrorCategoryBackend5xx
+	}
+	return metrics.ErrorCategoryValidation
+}
+
+// recordSlackUserCacheLookup records a Slack user profile served from the cache (hit) or users.info (miss)
+func (h *Handler) recordSlackUserCacheLookup(result string) {
+	if h.metrics != nil {
+		h.metrics.SlackUserCacheLookups.WithLabelValues(result).Inc()
+	}
+}
+
+// recordSlackUserCacheSize records the number of cached Slack user profiles
+func (h *Handler) recordSlackUserCacheSize() {
+	if h.metrics != nil {
+		h.metrics.SlackUserCacheSize.Set(float64(h.slackUsers.Len()))
+	}
+}
+
+// recordOptionsCacheLookup records an options request served from the options cache (hit) or searched (miss)
+func (h *Handler) recordOptionsCacheLookup(result string) {
+	if h.metrics != nil {
+		h.metrics.OptionsCacheLookups.WithLabelValues(result).Inc()
+	}
+}
+
+// recordSubmitterFallback records a submission attributed to NOTION_FALLBACK_USER_ID
+func (h *Handler) recordSubmitterFallback() {
+	if h.metrics != nil {
+		h.metrics.SubmitterFallbacks.Inc()
+	}
+}
+
+// recordSubmitterPolicy records a submission handled by SLACK_GUEST_POLICY or HIDDEN_EMAIL_POLICY
+func (h *Handler) recordSubmitterPolicy(userType, policy string) {
+	if h.metrics != nil {
+		h.metrics.SubmitterPolicies.WithLabelValues(userType, policy).Inc()
+	}
+}
+
+// recordStaticCustomerOptionsTruncated records a static customer select that couldn't list every customer
+func (h *Handler) recordStaticCustomerOptionsTruncated() {
+	if h.metrics != nil {
+		h.metrics.StaticCustomerOptionsTruncated.Inc()
+	}
+}
+
+// trackSubmission streams a submission outcome to the analytics exporter.
+// sub may be nil when the submission failed before fields were extracted.
+func (h *Handler) trackSubmission(event string, payload *InteractionPayload, sub *submission.Submission, outcome string) {
+	if h.analytics == nil {
+		return
+	}
+
+	properties := map[string]interface{}{
+		"outcome":     outcome,
+		"team_id":     payload.Team.ID,
+		"callback_id": payload.View.CallbackID,
+	}
+	// Modals opened pre-filled (drafts, quick-submit fallbacks, edits) carry their values in private_metadata;
+	// the details step of a multi-step submission always carries the first step's
+	if metadata := decodeModalMetadata(payload.View.PrivateMetadata); metadata.Prefilled || (metadata.Values != nil && metadata.Step == 0) {
+		properties["prefilled"] = true
+	}
+	if sub != nil {
+		properties["title"] = sub.Title
+		properties["theme"] = sub.Theme
+		properties["product_area"] = sub.ProductArea
+		properties["channel"] = sub.Source.ChannelName()
+		if len(sub.CustomerOrgs) > 0 {
+			properties["customer_orgs"] = sub.CustomerOrgs
+		}
+	}
+
+	h.analytics.Track(analytics.Event{
+		Event:      event,
+		UserID:     payload.User.ID,
+		Properties: properties,
+	})
+}
+
 // GetClientCount returns the count of cached clients for health checks
 func (h *Handler) GetClientCount() int {
-	if h.notionClient != nil {
-		return len(h.notionClient.GetValidCustomers())
+	if h.cache != nil {
+		return h.cache.Snapshot().CustomerCount()
 	}
 	return 0
 }
 
-// NotionClient returns the Notion client for health checks
+// NotionClient returns the Notion client for health checks and admin endpoints.
+// Returns nil when the handler was built with a custom backend.
 func (h *Handler) NotionClient() *notion.Client {
 	return h.notionClient
 }
@@ -0,0 +1,95 @@
+package slack
+
+import (
+	"fmt"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/slack-go/slack"
+)
+
+// FieldLimits are the limits submissions are validated against.
+//
+// The modal's max lengths, max selections and length hints ("Max 2000
+// characters", "Select up to 10 customer organizations") are generated from
+// the same values (see applyFieldLimits), so the UI never drifts from
+// server-side validation when the limits change.
+type FieldLimits struct {
+	TitleMaxLength  int // Characters in the title
+	TextMaxLength   int // Characters in comments and generated text fields
+	MaxCustomerOrgs int // Customer organizations per relation field
+
+	// commentsMaxLength raises the comments limit above TextMaxLength when
+	// comments are written to the page body (see Handler.fieldLimits).
+	commentsMaxLength int
+}
+
+// commentsLength returns the characters allowed in comments.
+func (l FieldLimits) commentsLength() int {
+	if l.commentsMaxLength > 0 {
+		return l.commentsMaxLength
+	}
+	return l.TextMaxLength
+}
+
+// DefaultFieldLimits returns the limits Notion enforces (see constants.MaxTitleLength).
+func DefaultFieldLimits() FieldLimits {
+	return FieldLimits{
+		TitleMaxLength:  constants.MaxTitleLength,
+		TextMaxLength:   constants.MaxCommentLength,
+		MaxCustomerOrgs: constants.MaxCustomerOrgSelections,
+	}
+}
+
+// effective returns l with unset or out-of-range values replaced by the defaults.
+// Limits can only be tightened: Notion rejects values above the defaults.
+func (l FieldLimits) effective() FieldLimits {
+	defaults := DefaultFieldLimits()
+	if l.TitleMaxLength <= 0 || l.TitleMaxLength > defaults.TitleMaxLength {
+		l.TitleMaxLength = defaults.TitleMaxLength
+	}
+	if l.TextMaxLength <= 0 || l.TextMaxLength > defaults.TextMaxLength {
+		l.TextMaxLength = defaults.TextMaxLength
+	}
+	if l.MaxCustomerOrgs <= 0 || l.MaxCustomerOrgs > defaults.MaxCustomerOrgs {
+		l.MaxCustomerOrgs = defaults.MaxCustomerOrgs
+	}
+	return l
+}
+
+// applyFieldLimits sets the max length of every text input and the max
+// selections of every customer select, and describes them in the block hints.
+// Screen readers announce hints with the field, unlike Slack's character counter.
+func applyFieldLimits(blocks []slack.Block, limits FieldLimits) {
+	for _, block := range blocks {
+		input, ok := block.(*slack.InputBlock)
+		if !ok {
+			continue
+		}
+		switch element := input.Element.(type) {
+		case *slack.PlainTextInputBlockElement:
+			maxLength := limits.TextMaxLength
+			switch input.BlockID {
+			case BlockIDTitle:
+				maxLength = limits.TitleMaxLength
+			case BlockIDComments:
+				maxLength = limits.commentsLength()
+			}
+			element.MaxLength = maxLength
+			appendHint(input, fmt.Sprintf(HintMaxLength, maxLength))
+		case *slack.MultiSelectBlockElement:
+			if element.Type != slack.MultiOptTypeExternal {
+				continue // Only customer selects load external options
+			}
+			setMaxSelections(element, limits.MaxCustomerOrgs)
+			appendHint(input, fmt.Sprintf(HintMaxCustomerOrgs, limits.MaxCustomerOrgs))
+		}
+	}
+}
+
+// appendHint adds hint to the block's hint, separated by ". " from any existing one.
+func appendHint(input *slack.InputBlock, hint string) {
+	if input.Hint != nil && input.Hint.Text != "" {
+		hint = input.Hint.Text + ". " + hint
+	}
+	input.Hint = newPlainText(hint)
+}
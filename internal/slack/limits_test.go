@@ -0,0 +1,90 @@
+package slack
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+// TestBuildSubmissionModalFromFields_Limits tests that max lengths, max selections
+// and hints are generated from the given limits
+func TestBuildSubmissionModalFromFields_Limits(t *testing.T) {
+	limits := FieldLimits{TitleMaxLength: 150, TextMaxLength: 500, MaxCustomerOrgs: 3}
+	modal := BuildSubmissionModalFromFields(ModalFieldsFromSchema(testFormSchema), nil, limits)
+
+	tests := []struct {
+		blockID       string
+		wantMaxLength int
+		wantMaxItems  int
+		wantHint      string
+	}{
+		{blockID: BlockIDTitle, wantMaxLength: 150, wantHint: "Max 150 characters"},
+		{blockID: BlockIDComments, wantMaxLength: 500, wantHint: "Max 500 characters"},
+		{blockID: BlockIDSchemaPrefix + "Notes", wantMaxLength: 500, wantHint: "Max 500 characters"},
+		{blockID: BlockIDCustomerOrg, wantMaxItems: 3, wantHint: "Select up to 3 customer organizations"},
+		{blockID: BlockIDSchemaPrefix + "Affected Customers", wantMaxItems: 3, wantHint: "Select up to 3 customer organizations"},
+		{blockID: BlockIDSchemaPrefix + "Labels"},
+	}
+
+	blocks := make(map[string]*slack.InputBlock)
+	for _, block := range modal.Blocks.BlockSet {
+		if input, ok := block.(*slack.InputBlock); ok {
+			blocks[input.BlockID] = input
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.blockID, func(t *testing.T) {
+			input := blocks[tt.blockID]
+			if input == nil {
+				t.Fatalf("block %s not found", tt.blockID)
+			}
+
+			hint := ""
+			if input.Hint != nil {
+				hint = input.Hint.Text
+			}
+			if hint != tt.wantHint {
+				t.Errorf("hint = %q, want %q", hint, tt.wantHint)
+			}
+
+			switch element := input.Element.(type) {
+			case *slack.PlainTextInputBlockElement:
+				if element.MaxLength != tt.wantMaxLength {
+					t.Errorf("max length = %d, want %d", element.MaxLength, tt.wantMaxLength)
+				}
+			case *slack.MultiSelectBlockElement:
+				got := 0
+				if element.MaxSelectedItems != nil {
+					got = *element.MaxSelectedItems
+				}
+				if got != tt.wantMaxItems {
+					t.Errorf("max selected items = %d, want %d", got, tt.wantMaxItems)
+				}
+			}
+		})
+	}
+}
+
+// TestFieldLimitsEffective tests that limits fall back to the defaults when unset or above Notion's limits
+func TestFieldLimitsEffective(t *testing.T) {
+	defaults := DefaultFieldLimits()
+
+	tests := []struct {
+		name   string
+		limits FieldLimits
+		want   FieldLimits
+	}{
+		{name: "unset", limits: FieldLimits{}, want: defaults},
+		{name: "tightened", limits: FieldLimits{TitleMaxLength: 200, TextMaxLength: 1000, MaxCustomerOrgs: 5}, want: FieldLimits{TitleMaxLength: 200, TextMaxLength: 1000, MaxCustomerOrgs: 5}},
+		{name: "above notion limits", limits: FieldLimits{TitleMaxLength: 5000, TextMaxLength: -1, MaxCustomerOrgs: 50}, want: defaults},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.effective(); got != tt.want {
+				t.Errorf("effective() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,6 +13,9 @@
 //   - Comments: Multiline text input
 //   - Customer Org: Multi-select external dropdown (loads options dynamically)
 //
+// Additional properties of the Notion database get generated optional fields
+// (see form.go).
+//
 // Modal Structure:
 // The modal is built as a View with Blocks. Each block represents a form field.
 // Blocks use ActionIDs to identify field values when the modal is submitted.
@@ -27,6 +30,7 @@ import (
 	"math/rand/v2"
 
 	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/reminders"
 	"github.com/slack-go/slack"
 )
 
@@ -59,6 +63,9 @@ func GetRandomModalTitle() string {
 // 4. Comments (optional) - Multiline text input
 // 5. Customer Org (optional) - Multi-select external dropdown (loads options dynamically)
 //
+// Conditional requirements from DefaultFormRules and the DefaultFieldLimits are
+// reflected in field hints.
+//
 // Example:
 //
 //	modal := BuildSubmissionModal()
@@ -66,6 +73,33 @@ func GetRandomModalTitle() string {
 //	// modal.CallbackID == "submit_form_modal"
 //	// len(modal.Blocks.BlockSet) == 5
 func BuildSubmissionModal() slack.ModalViewRequest {
+	return BuildSubmissionModalWithRules(DefaultFormRules)
+}
+
+// BuildSubmissionModalWithRules constructs the submission modal with hints describing
+// the given conditional requirements (e.g., "Required for Customer Pain Point").
+//
+// Slack has no native conditional-required inputs, so conditionally required fields
+// stay optional in the modal and are enforced on submission; the hint tells users
+// up front so the validation error isn't a surprise.
+func BuildSubmissionModalWithRules(rules []FormRule) slack.ModalViewRequest {
+	return BuildSubmissionModalFromFields(CoreModalFields(), rules, DefaultFieldLimits())
+}
+
+// BuildSubmissionModalFromFields constructs the submission modal from a form
+// definition: one input block per field, in order, after the info block.
+// Use ModalFieldsFromSchema to include fields generated from the database schema.
+//
+// Text lengths and customer selections are capped at the given limits, which
+// are also spelled out in the field hints.
+func BuildSubmissionModalFromFields(fields []ModalField, rules []FormRule, limits FieldLimits) slack.ModalViewRequest {
+	blocks := []slack.Block{buildInfoBlock()}
+	for _, field := range fields {
+		blocks = append(blocks, field.buildBlock())
+	}
+	applyFieldLimits(blocks, limits.effective())
+	applyRuleHints(blocks, rules)
+
 	return slack.ModalViewRequest{
 		Type:       slack.VTModal,
 		CallbackID: ModalCallbackIDSubmitForm,
@@ -73,18 +107,24 @@ func BuildSubmissionModal() slack.ModalViewRequest {
 		Submit:     newPlainText(ModalSubmitText),
 		Close:      newPlainText(ModalCancelText),
 		Blocks: slack.Blocks{
-			BlockSet: []slack.Block{
-				buildInfoBlock(),
-				buildTitleBlock(),
-				buildThemeBlock(),
-				buildProductAreaBlock(),
-				buildCommentsBlock(),
-				buildCustomerOrgBlock(),
-			},
+			BlockSet: blocks,
 		},
 	}
 }
 
+// applyRuleHints appends conditional-requirement hints to the affected input blocks.
+func applyRuleHints(blocks []slack.Block, rules []FormRule) {
+	for _, block := range blocks {
+		input, ok := block.(*slack.InputBlock)
+		if !ok {
+			continue
+		}
+		if hint := ruleHint(rules, input.BlockID); hint != "" {
+			appendHint(input, hint)
+		}
+	}
+}
+
 // buildInfoBlock creates an informational context block at the top of the modal.
 // This provides helpful guidance to users about what happens when they submit.
 //
@@ -129,7 +169,7 @@ func buildTitleBlock() *slack.InputBlock {
 
 // buildThemeBlock creates the "Theme/Category" form field block.
 // This is a required single-select dropdown for selecting the idea theme.
-// Valid options come from constants.ValidThemeCategories.
+// Options are the theme options synced from the database (see notion.SelectOptions).
 //
 // Returns an InputBlock with a SelectBlockElement.
 // BlockID: "theme_block"
@@ -138,12 +178,12 @@ func buildTitleBlock() *slack.InputBlock {
 //
 // Example:
 //
-//	block := buildThemeBlock()
+//	block := buildThemeBlock(constants.ValidThemeCategories)
 //	// block.Label.Text == "Theme/Category"
 //	// block.Optional == false
 //	// len(element.Options) == 4
-func buildThemeBlock() *slack.InputBlock {
-	options := createOptions(constants.ValidThemeCategories)
+func buildThemeBlock(themes []string) *slack.InputBlock {
+	options := createOptions(slackOptionValues(themes))
 
 	element := slack.NewOptionsSelectBlockElement(
 		slack.OptTypeStatic,
@@ -162,7 +202,7 @@ func buildThemeBlock() *slack.InputBlock {
 
 // buildProductAreaBlock creates the "Product Area" form field block.
 // This is a required single-select dropdown for selecting the product area.
-// Valid options come from constants.ValidProductAreas.
+// Options are the product area options synced from the database (see notion.SelectOptions).
 //
 // Returns an InputBlock with a SelectBlockElement.
 // BlockID: "product_area_block"
@@ -171,11 +211,11 @@ func buildThemeBlock() *slack.InputBlock {
 //
 // Example:
 //
-//	block := buildProductAreaBlock()
+//	block := buildProductAreaBlock(constants.ValidProductAreas)
 //	// block.Label.Text == "Product Area"
 //	// block.Optional == false
-func buildProductAreaBlock() *slack.InputBlock {
-	options := createOptions(constants.ValidProductAreas)
+func buildProductAreaBlock(productAreas []string) *slack.InputBlock {
+	options := createOptions(slackOptionValues(productAreas))
 
 	element := slack.NewOptionsSelectBlockElement(
 		slack.OptTypeStatic,
@@ -227,7 +267,7 @@ func buildCommentsBlock() *slack.InputBlock {
 // BlockID: "client_org_block"
 // ActionID: "client_org_select"
 // Optional: true (optional field)
-// MaxSelectedItems: 10 (enforced from constants.MaxCustomerOrgSelections)
+// MaxSelectedItems: 10 (constants.MaxCustomerOrgSelections; the modal applies the handler's FieldLimits)
 //
 // Note: Requires Slack app to have "Options Load URL" configured pointing to /slack/options endpoint.
 // Without this configuration, the modal will fail to open with "invalid_arguments" error.
@@ -252,7 +292,7 @@ func buildCustomerOrgBlock() *slack.InputBlock {
 	block := slack.NewInputBlock(
 		BlockIDCustomerOrg,
 		newPlainText(LabelCustomerOrg),
-		newPlainText(HintCustomerOrg),
+		nil,
 		element,
 	)
 
@@ -262,6 +302,44 @@ func buildCustomerOrgBlock() *slack.InputBlock {
 	return block
 }
 
+// buildRemindMeBlock creates the optional "Remind me to follow up" block.
+// This is a single-select dropdown of reminder delays from reminders.Options.
+// Only added to the modal when a reminder scheduler is configured.
+//
+// Returns an InputBlock with a SelectBlockElement.
+// BlockID: "remind_me_block"
+// ActionID: "remind_me_select"
+// Optional: true (no reminder when left empty)
+//
+// Example:
+//
+//	block := buildRemindMeBlock()
+//	// block.Label.Text == "Remind me to follow up"
+//	// len(element.Options) == 3 // 1 week, 2 weeks, 1 month
+func buildRemindMeBlock() *slack.InputBlock {
+	options := make([]*slack.OptionBlockObject, 0, len(reminders.Options))
+	for _, option := range reminders.Options {
+		options = append(options, slack.NewOptionBlockObject(option.Value, newPlainText(option.Label), nil))
+	}
+
+	element := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic,
+		newPlainText(PlaceholderRemindMe),
+		ActionIDRemindMeSelect,
+		options...,
+	)
+
+	block := slack.NewInputBlock(
+		BlockIDRemindMe,
+		newPlainText(LabelRemindMe),
+		newPlainText(HintRemindMe),
+		element,
+	)
+	block.Optional = true
+
+	return block
+}
+
 // createTextInputBlock creates a generic text input block (InputBlock).
 // Used to build both single-line and multiline text input fields.
 //
@@ -132,7 +132,7 @@ func TestBuildTitleBlock(t *testing.T) {
 
 // TestBuildThemeBlock tests theme block creation (single select)
 func TestBuildThemeBlock(t *testing.T) {
-	block := buildThemeBlock()
+	block := buildThemeBlock(constants.ValidThemeCategories)
 
 	if block.BlockID != BlockIDTheme {
 		t.Errorf("block ID = %s, want %s", block.BlockID, BlockIDTheme)
@@ -162,7 +162,7 @@ func TestBuildThemeBlock(t *testing.T) {
 
 // TestBuildProductAreaBlock tests product area block creation
 func TestBuildProductAreaBlock(t *testing.T) {
-	block := buildProductAreaBlock()
+	block := buildProductAreaBlock(constants.ValidProductAreas)
 
 	if block.BlockID != BlockIDProductArea {
 		t.Errorf("block ID = %s, want %s", block.BlockID, BlockIDProductArea)
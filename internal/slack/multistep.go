@@ -0,0 +1,186 @@
+package slack
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/pkg/featureflags"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/slack-go/slack"
+)
+
+// stepOneBlocks are the fields of the first step of a multi-step submission.
+// The details step has every other field of the submission modal.
+var stepOneBlocks = []string{BlockIDTitle, BlockIDTheme}
+
+// SetMultiStepModal splits the submission modal into two steps: the title and
+// theme, then the remaining fields with hints for the chosen theme.
+func (h *Handler) SetMultiStepModal(enabled bool) {
+	h.multiStep = enabled
+}
+
+// multiStepEnabled reports whether /hopperbot opens the first step instead of
+// the whole submission modal.
+func (h *Handler) multiStepEnabled() bool {
+	return h.multiStep && h.flags.Enabled(featureflags.MultiStep)
+}
+
+// openingModal builds the modal /hopperbot and the App Home button open: the
+// first step of a multi-step submission, or the whole submission modal.
+func (h *Handler) openingModal(teamID, id string, values ModalValues) slack.ModalViewRequest {
+	if h.multiStepEnabled() {
+		return h.stepOneModal(id, values)
+	}
+	return h.submissionModalWith(teamID, id, values)
+}
+
+// stepOneModal builds the first step, with the title and theme. id and values
+// (e.g. from an autosaved draft) are carried to the details step in
+// private_metadata.
+func (h *Handler) stepOneModal(id string, values ModalValues) slack.ModalViewRequest {
+	fields := slices.DeleteFunc(slices.Clone(h.modalFields()), func(field ModalField) bool {
+		return !slices.Contains(stepOneBlocks, field.BlockID)
+	})
+
+	modal := BuildSubmissionModalFromFields(fields, h.currentFormRules(), h.fieldLimits())
+	modal.CallbackID = ModalCallbackIDSubmitStepOne
+	modal.Submit = newPlainText(ModalNextText)
+	prefillModal(&modal, id, values)
+	return modal
+}
+
+// handleStepOneSubmission validates the first step and pushes the details step
+// on top of it. The title and theme travel in the details step's
+// private_metadata, since Slack only sends the state of the submitted view.
+func (h *Handler) handleStepOneSubmission(w http.ResponseWriter, payload *InteractionPayload) {
+	errors := make(map[string]string)
+	title, message := h.validateTitle(payload.View.State)
+	if message != "" {
+		errors[BlockIDTitle] = message
+	}
+	theme, message := h.validateTheme(payload.View.State, h.selectOptions().Themes)
+	if message != "" {
+		errors[BlockIDTheme] = message
+	}
+	if len(errors) > 0 {
+		h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "validation_error")
+		respondWithErrors(w, errors)
+		return
+	}
+
+	metadata := decodeModalMetadata(payload.View.PrivateMetadata)
+	var values ModalValues
+	if metadata.Values != nil {
+		values = *metadata.Values
+	}
+	values.Title, values.Theme = title, theme
+
+	modal := h.stepTwoModal(payload.Team.ID, metadata.ID, values, metadata.Values != nil)
+	setModalPermalink(&modal, metadata.Permalink)
+	h.recordSlackInteraction(payload.Type, payload.View.CallbackID, "next")
+	respondWithView(w, ResponseActionPush, modal)
+}
+
+// stepTwoModal builds the details step: the submission modal without the title
+// and theme, summarizing them at the top. Fields the theme makes required are
+// required here, and listed under the summary.
+func (h *Handler) stepTwoModal(teamID, id string, values ModalValues, prefilled bool) slack.ModalViewRequest {
+	modal := h.submissionModalWith(teamID, id, values)
+	modal.Title = newPlainText(ModalTitleStepTwo)
+	modal.Close = newPlainText(ModalBackText)
+
+	blocks := slices.DeleteFunc(modal.Blocks.BlockSet, func(block slack.Block) bool {
+		input, ok := block.(*slack.InputBlock)
+		return ok && slices.Contains(stepOneBlocks, input.BlockID)
+	})
+	summary := []slack.MixedElement{slack.NewTextBlockObject(slack.MarkdownType, h.messages.Format(messages.KeyStepTwoSummary, messages.Params{
+		"title": mrkdwnEscaper.Replace(values.Title), "theme": values.Theme,
+	}), false, false)}
+	if required := h.requireForTheme(blocks, values.Theme); len(required) > 0 {
+		summary = append(summary, slack.NewTextBlockObject(slack.MarkdownType, h.messages.Format(messages.KeyStepTwoRequired, messages.Params{
+			"theme": values.Theme, "fields": strings.Join(required, ", "),
+		}), false, false))
+	}
+	blocks[0] = slack.NewContextBlock("info_block", summary...)
+	modal.Blocks.BlockSet = blocks
+
+	modal.PrivateMetadata = encodeModalMetadata(modalMetadata{ID: id, Values: &values, Step: 2, Prefilled: prefilled})
+	return modal
+}
+
+// requireForTheme makes the input blocks that the form rules require for theme
+// required, and returns their labels.
+func (h *Handler) requireForTheme(blocks []slack.Block, theme string) []string {
+	// With only the theme set, every rule it triggers is violated
+	var required []string
+	for _, violation := range evaluateFormRules(h.currentFormRules(), map[string][]string{BlockIDTheme: {theme}}) {
+		required = append(required, violation.Field)
+	}
+
+	var labels []string
+	for _, block := range blocks {
+		if input, ok := block.(*slack.InputBlock); ok && slices.Contains(required, input.BlockID) {
+			input.Optional = false
+			labels = append(labels, input.Label.Text)
+		}
+	}
+	return labels
+}
+
+// restoreStepOneState adds the title and theme entered in the first step to the
+// state of the details step, so it is validated, autosaved and shared like the
+// single-step modal.
+func restoreStepOneState(payload *InteractionPayload) {
+	metadata := decodeModalMetadata(payload.View.PrivateMetadata)
+	if payload.View.CallbackID != ModalCallbackIDSubmitForm || metadata.Step != 2 || metadata.Values == nil {
+		return
+	}
+
+	if payload.View.State.Values == nil {
+		payload.View.State.Values = make(map[string]map[string]StateValue)
+	}
+	title := metadata.Values.Title
+	payload.View.State.Values[BlockIDTitle] = map[string]StateValue{
+		ActionIDTitleInput: {Type: "plain_text_input", Value: &title},
+	}
+	payload.View.State.Values[BlockIDTheme] = map[string]StateValue{
+		ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: metadata.Values.Theme}},
+	}
+}
+
+// respondWithViewErrors shows errors on the submitted view. The details step
+// has no title or theme block, so their errors (and general errors, which are
+// shown on the title) move to its product area.
+func respondWithViewErrors(w http.ResponseWriter, payload *InteractionPayload, errors map[string]string) {
+	if decodeModalMetadata(payload.View.PrivateMetadata).Step == 2 {
+		moved := make(map[string]string, len(errors))
+		for _, blockID := range slices.Sorted(maps.Keys(errors)) {
+			message := errors[blockID]
+			if slices.Contains(stepOneBlocks, blockID) {
+				blockID = BlockIDProductArea
+			}
+			if moved[blockID] != "" {
+				message = moved[blockID] + " " + message
+			}
+			moved[blockID] = message
+		}
+		errors = moved
+	}
+	respondWithErrors(w, errors)
+}
+
+// respondSubmitted closes the modal after a successful submission. The details
+// step clears the whole view stack; closing only it would go back to the first
+// step.
+func (h *Handler) respondSubmitted(w http.ResponseWriter, payload *InteractionPayload) {
+	if decodeModalMetadata(payload.View.PrivateMetadata).Step != 2 {
+		h.respondSuccess(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ViewSubmissionResponse{ResponseAction: ResponseActionClear})
+}
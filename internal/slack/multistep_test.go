@@ -0,0 +1,137 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/slack-go/slack"
+)
+
+func viewSubmissionRequest(t *testing.T, view View) *http.Request {
+	t.Helper()
+	payload, err := json.Marshal(InteractionPayload{
+		Type: InteractionTypeViewSubmission,
+		User: User{ID: "U123", Username: "alice"},
+		Team: Team{ID: "T456"},
+		View: view,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	return createValidSlackRequest(http.MethodPost, "/slack/interactive", []byte(body), "secret")
+}
+
+// TestMultiStepModal tests the title and theme step pushing the details step, and submitting it
+func TestMultiStepModal(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(
+		map[string]string{"Acme": "customer-page-acme"},
+		map[string]string{"alice@example.com": "notion-user-alice"},
+	)}
+	slackAPI := &fakeSlack{
+		users:  map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+		posted: make(chan string, 1),
+	}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+	handler.SetMultiStepModal(true)
+
+	stepOne := handler.newSubmissionModal("T456", "U123")
+	if stepOne.CallbackID != ModalCallbackIDSubmitStepOne || len(stepOne.Blocks.BlockSet) != 3 || stepOne.Submit.Text != ModalNextText {
+		t.Fatalf("first step = %+v, want the info, title and theme blocks with a Next button", stepOne)
+	}
+
+	title := "Exports are slow"
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, viewSubmissionRequest(t, View{CallbackID: ModalCallbackIDSubmitStepOne, State: ViewState{Values: map[string]map[string]StateValue{
+		BlockIDTitle: {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+		BlockIDTheme: {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "Customer Pain Point"}}},
+	}}}))
+
+	var pushed struct {
+		ResponseAction ResponseAction `json:"response_action"`
+		View           struct {
+			CallbackID      string            `json:"callback_id"`
+			PrivateMetadata string            `json:"private_metadata"`
+			Blocks          []json.RawMessage `json:"blocks"`
+		} `json:"view"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &pushed); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", w.Body.String(), err)
+	}
+	if pushed.ResponseAction != ResponseActionPush || pushed.View.CallbackID != ModalCallbackIDSubmitForm {
+		t.Fatalf("response = %s, want the details step pushed", w.Body.String())
+	}
+	body := w.Body.String()
+	if strings.Contains(body, BlockIDTitle) || !strings.Contains(body, "*Exports are slow* · Customer Pain Point") {
+		t.Errorf("details step = %s, want the title summarized instead of asked again", body)
+	}
+	if !strings.Contains(body, "Customer Pain Point ideas also need: "+LabelCustomerOrg) {
+		t.Errorf("details step = %s, want a hint that the theme requires a customer", body)
+	}
+	for _, raw := range pushed.View.Blocks {
+		var block struct {
+			BlockID  string `json:"block_id"`
+			Optional bool   `json:"optional"`
+		}
+		json.Unmarshal(raw, &block)
+		if block.BlockID == BlockIDCustomerOrg && block.Optional {
+			t.Error("customer org is optional although the theme requires it")
+		}
+	}
+
+	area := "AI/ML"
+	w = httptest.NewRecorder()
+	handler.HandleInteractive(w, viewSubmissionRequest(t, View{
+		CallbackID:      ModalCallbackIDSubmitForm,
+		PrivateMetadata: pushed.View.PrivateMetadata,
+		State: ViewState{Values: map[string]map[string]StateValue{
+			BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: area}}},
+			BlockIDCustomerOrg: {ActionIDCustomerOrgSelect: {Type: "multi_external_select", SelectedOptions: []SelectedOption{{Value: "Acme"}}}},
+		}},
+	}))
+	if !strings.Contains(w.Body.String(), `"response_action":"clear"`) {
+		t.Errorf("response = %s, want both steps closed", w.Body.String())
+	}
+	if len(backend.submissions) != 1 {
+		t.Fatalf("expected 1 submission, got %d", len(backend.submissions))
+	}
+	if sub := backend.submissions[0]; sub.Title != title || sub.Theme != "Customer Pain Point" || sub.ProductArea != area {
+		t.Errorf("submission = %+v, want the first step's title and theme", sub)
+	}
+}
+
+// TestMultiStepModal_Errors tests that errors for the first step's fields are shown on the details step
+func TestMultiStepModal_Errors(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}
+	handler := newInteractiveTestHandler(backend, &fakeSlack{})
+	handler.SetMultiStepModal(true)
+
+	// The users.info lookup fails, which is reported on the title
+	metadata := encodeModalMetadata(modalMetadata{Values: &ModalValues{Title: "Exports", Theme: "Feature Improvement"}, Step: 2})
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, viewSubmissionRequest(t, View{CallbackID: ModalCallbackIDSubmitForm, PrivateMetadata: metadata}))
+
+	var response ViewSubmissionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if _, found := response.Errors[BlockIDTitle]; found || response.Errors[BlockIDProductArea] == "" {
+		t.Errorf("errors = %v, want the title's error on the product area", response.Errors)
+	}
+	if len(backend.submissions) != 0 {
+		t.Error("a failed submission was submitted")
+	}
+}
+
+// TestMultiStepModal_Disabled tests that /hopperbot opens the whole modal unless enabled
+func TestMultiStepModal_Disabled(t *testing.T) {
+	handler := newInteractiveTestHandler(&fakeBackend{}, &fakeSlack{})
+	if modal := handler.newSubmissionModal("T456", "U123"); modal.CallbackID != ModalCallbackIDSubmitForm {
+		t.Errorf("CallbackID = %q, want the single-step modal", modal.CallbackID)
+	}
+}
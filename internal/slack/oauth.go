@@ -0,0 +1,245 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/installations"
+	"github.com/rudderlabs/hopperbot/pkg/manifest"
+	"github.com/rudderlabs/hopperbot/pkg/middleware"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// Slack OAuth v2 install flow.
+//
+// /slack/oauth/install redirects to Slack's authorize page with a signed state,
+// also set as a cookie so the callback can only complete an install started in
+// the same browser. /slack/oauth/callback verifies the state, exchanges the
+// code for a bot token and saves it in the installation store. Requests from an
+// installed workspace then use that workspace's token (see slackFor); other
+// workspaces keep using SLACK_BOT_TOKEN.
+const (
+	slackAuthorizeURL = "https://slack.com/oauth/v2/authorize"
+
+	// oauthStateCookie holds the state issued by the install endpoint.
+	oauthStateCookie = "hopperbot_oauth_state"
+
+	// oauthStateTTL is how long an install can take between the redirect and the callback.
+	oauthStateTTL = 10 * time.Minute
+
+	// oauthExchangeTimeout bounds the oauth.v2.access call.
+	oauthExchangeTimeout = 10 * time.Second
+)
+
+// SetInstallationStore enables per-workspace bot tokens from OAuth installs.
+// Passing nil (the default) uses SLACK_BOT_TOKEN for every workspace.
+func (h *Handler) SetInstallationStore(store installations.Store) {
+	h.installations = store
+}
+
+// HandleOAuthInstall starts the OAuth install flow by redirecting to Slack.
+func (h *Handler) HandleOAuthInstall(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.installations == nil || h.config.Load().ClientID == "" {
+		http.Error(w, "OAuth install is not configured", http.StatusNotFound)
+		return
+	}
+
+	state, err := h.newOAuthState()
+	if err != nil {
+		h.handleError(r.Context(), w, err, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     h.config.Load().BasePath + constants.RouteSlackOAuthCallback, // As the browser sees it
+		MaxAge:   int(oauthStateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   middleware.IsSecure(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, h.authorizeURL(state), http.StatusFound)
+}
+
+// HandleOAuthCallback completes the OAuth install flow: it verifies the state,
+// exchanges the code for the workspace's bot token and saves the installation.
+func (h *Handler) HandleOAuthCallback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.installations == nil || h.config.Load().ClientID == "" {
+		http.Error(w, "OAuth install is not configured", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	state := query.Get("state")
+	cookie, err := r.Cookie(oauthStateCookie)
+	if err != nil || cookie.Value != state || !h.validOAuthState(state) {
+		h.logger.Warn("rejected OAuth callback with invalid state", zap.Bool("has_cookie", err == nil))
+		http.Error(w, "Invalid or expired install link, please start the installation again", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Path: h.config.Load().BasePath + constants.RouteSlackOAuthCallback, MaxAge: -1})
+
+	if oauthErr := query.Get("error"); oauthErr != "" {
+		h.logger.Info("OAuth install canceled", zap.String("error", oauthErr))
+		writeHTMLPage(w, http.StatusOK, "Installation canceled", "Hopperbot was not installed.")
+		return
+	}
+	code := query.Get("code")
+	if code == "" {
+		http.Error(w, "Missing code", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), oauthExchangeTimeout)
+	defer cancel()
+
+	exchange := h.oauthExchange
+	if exchange == nil {
+		exchange = h.exchangeOAuthCode
+	}
+	resp, err := exchange(ctx, code)
+	if err != nil {
+		h.recordSlackAPIError("oauth.v2.access", err)
+		h.handleError(ctx, w, fmt.Errorf("failed to exchange OAuth code: %w", err), "Installation failed, please try again", http.StatusBadGateway)
+		return
+	}
+
+	installation := installations.Installation{
+		TeamID:      resp.Team.ID,
+		TeamName:    resp.Team.Name,
+		BotToken:    resp.AccessToken,
+		BotUserID:   resp.BotUserID,
+		Scopes:      resp.Scope,
+		InstalledBy: resp.AuthedUser.ID,
+		InstalledAt: h.clock.Now().UTC(),
+	}
+	if err := h.installations.Save(installation); err != nil {
+		h.handleError(ctx, w, fmt.Errorf("failed to save installation: %w", err), "Installation failed, please try again", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("Slack workspace installed",
+		zap.String("team_id", installation.TeamID),
+		zap.String("team_name", installation.TeamName),
+		zap.String("installed_by", installation.InstalledBy),
+	)
+	writeHTMLPage(w, http.StatusOK, "Hopperbot installed",
+		fmt.Sprintf("Hopperbot is now installed in %s. Use %s in Slack to submit an idea.", installation.TeamName, constants.SlashCommand))
+}
+
+// slackFor returns the Slack client for a workspace: the bot token from its
+// OAuth install if there is one, otherwise the default SLACK_BOT_TOKEN client.
+func (h *Handler) slackFor(teamID string) SlackAPI {
+	installation, ok := h.installation(teamID)
+	if !ok {
+		return h.defaultSlackClient()
+	}
+	if client, ok := h.teamClients.Load(installation.BotToken); ok {
+		return client.(SlackAPI)
+	}
+	client, _ := h.teamClients.LoadOrStore(installation.BotToken, h.newTeamClient(installation.BotToken))
+	return client.(SlackAPI)
+}
+
+// installation returns the OAuth install for a workspace, if any.
+// Store errors are logged and treated as not installed.
+func (h *Handler) installation(teamID string) (installations.Installation, bool) {
+	if h.installations == nil || teamID == "" {
+		return installations.Installation{}, false
+	}
+	installation, ok, err := h.installations.Get(teamID)
+	if err != nil {
+		h.logger.Warn("failed to look up Slack installation, using the default bot token",
+			zap.String("team_id", teamID),
+			zap.Error(err),
+		)
+		return installations.Installation{}, false
+	}
+	return installation, ok
+}
+
+// exchangeOAuthCode calls oauth.v2.access, the default oauthExchange.
+func (h *Handler) exchangeOAuthCode(ctx context.Context, code string) (*slack.OAuthV2Response, error) {
+	client := &http.Client{Timeout: oauthExchangeTimeout}
+	cfg := h.config.Load()
+	return slack.GetOAuthV2ResponseContext(ctx, client, cfg.ClientID, cfg.ClientSecret, code, cfg.OAuthRedirectURL)
+}
+
+// authorizeURL builds the Slack authorize URL requesting the bot scopes from the app manifest.
+func (h *Handler) authorizeURL(state string) string {
+	cfg := h.config.Load()
+	params := url.Values{}
+	params.Set("client_id", cfg.ClientID)
+	params.Set("scope", strings.Join(manifest.BotScopes, ","))
+	params.Set("state", state)
+	if cfg.OAuthRedirectURL != "" {
+		params.Set("redirect_uri", cfg.OAuthRedirectURL)
+	}
+	return slackAuthorizeURL + "?" + params.Encode()
+}
+
+// newOAuthState returns "<unix time>.<nonce>.<signature>", signed with the client secret.
+func (h *Handler) newOAuthState() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate OAuth state: %w", err)
+	}
+	payload := strconv.FormatInt(h.clock.Now().Unix(), 10) + "." + hex.EncodeToString(nonce)
+	return payload + "." + h.signOAuthState(payload), nil
+}
+
+// validOAuthState reports whether state was issued by newOAuthState within oauthStateTTL.
+func (h *Handler) validOAuthState(state string) bool {
+	idx := strings.LastIndex(state, ".")
+	if idx < 0 {
+		return false
+	}
+	payload, signature := state[:idx], state[idx+1:]
+	if !hmac.Equal([]byte(signature), []byte(h.signOAuthState(payload))) {
+		return false
+	}
+
+	issuedStr, _, _ := strings.Cut(payload, ".")
+	issued, err := strconv.ParseInt(issuedStr, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := h.clock.Now().Sub(time.Unix(issued, 0))
+	return age >= 0 && age <= oauthStateTTL
+}
+
+func (h *Handler) signOAuthState(payload string) string {
+	mac := hmac.New(sha256.New, []byte(h.config.Load().ClientSecret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// writeHTMLPage renders the minimal HTML page shown in the browser after an
+// install or a web form submission.
+func writeHTMLPage(w http.ResponseWriter, status int, title, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<!DOCTYPE html><html><head><title>%s</title></head><body><h1>%s</h1><p>%s</p></body></html>",
+		html.EscapeString(title), html.EscapeString(title), html.EscapeString(message))
+}
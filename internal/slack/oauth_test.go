@@ -0,0 +1,221 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/installations"
+	"github.com/rudderlabs/hopperbot/pkg/middleware"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+func newOAuthTestHandler(t *testing.T, now time.Time) (*Handler, *installations.FileStore) {
+	t.Helper()
+	cfg := &config.Config{
+		SlackSigningSecret: "secret",
+		SlackClientID:      "123.456",
+		SlackClientSecret:  "client-secret",
+	}
+	handler := NewHandlerWithDependencies(cfg, zap.NewNop(), Dependencies{
+		Backend: &fakeBackend{},
+		Slack:   &fakeSlack{},
+		Clock:   fixedClock(now),
+	})
+	store, _ := installations.NewFileStore("")
+	handler.SetInstallationStore(store)
+	return handler, store
+}
+
+// TestOAuthState tests that state is only accepted when signed and recent
+func TestOAuthState(t *testing.T) {
+	now := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+	handler, _ := newOAuthTestHandler(t, now)
+
+	state, err := handler.newOAuthState()
+	if err != nil {
+		t.Fatalf("newOAuthState() error = %v", err)
+	}
+	if !handler.validOAuthState(state) {
+		t.Error("fresh state rejected")
+	}
+	if handler.validOAuthState("9" + state[1:]) {
+		t.Error("tampered state accepted")
+	}
+	if handler.validOAuthState("") {
+		t.Error("empty state accepted")
+	}
+
+	handler.clock = fixedClock(now.Add(oauthStateTTL + time.Second))
+	if handler.validOAuthState(state) {
+		t.Error("expired state accepted")
+	}
+}
+
+// TestHandleOAuthInstall tests the redirect to Slack's authorize page
+func TestHandleOAuthInstall(t *testing.T) {
+	handler, _ := newOAuthTestHandler(t, time.Now())
+
+	w := httptest.NewRecorder()
+	handler.HandleOAuthInstall(w, httptest.NewRequest(http.MethodGet, constants.RouteSlackOAuthInstall, nil))
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("status = %d, want 302", w.Code)
+	}
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid Location: %v", err)
+	}
+	query := location.Query()
+	if query.Get("client_id") != "123.456" || !strings.Contains(query.Get("scope"), "commands") {
+		t.Errorf("authorize query = %v", query)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oauthStateCookie || cookies[0].Value != query.Get("state") {
+		t.Errorf("cookies = %v, want the state cookie", cookies)
+	}
+
+	// Behind a reverse proxy the browser only sends the cookie back under the base path
+	handler.config.Load().BasePath = "/hopperbot"
+	w = httptest.NewRecorder()
+	handler.HandleOAuthInstall(w, httptest.NewRequest(http.MethodGet, constants.RouteSlackOAuthInstall, nil))
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].Path != "/hopperbot"+constants.RouteSlackOAuthCallback {
+		t.Errorf("cookies = %v, want the state cookie under /hopperbot", cookies)
+	}
+
+	disabled := newInteractiveTestHandler(&fakeBackend{}, &fakeSlack{})
+	w = httptest.NewRecorder()
+	disabled.HandleOAuthInstall(w, httptest.NewRequest(http.MethodGet, constants.RouteSlackOAuthInstall, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status without OAuth config = %d, want 404", w.Code)
+	}
+}
+
+// TestHandleOAuthInstall_SecureCookie tests that the state cookie is Secure only
+// when the request arrived over HTTPS or a trusted proxy says it did
+func TestHandleOAuthInstall_SecureCookie(t *testing.T) {
+	handler, _ := newOAuthTestHandler(t, time.Now())
+	forwardedHTTPS := func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, constants.RouteSlackOAuthInstall, nil)
+		req.Header.Set("X-Forwarded-Proto", "https")
+		return req
+	}
+
+	tests := []struct {
+		name        string
+		trustedHops int
+		want        bool
+	}{
+		{"X-Forwarded-Proto without TRUST_PROXY_HEADERS", 0, false},
+		{"X-Forwarded-Proto from a trusted proxy", 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			middleware.WithProxyHeaders(tt.trustedHops, handler.HandleOAuthInstall)(w, forwardedHTTPS())
+			if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].Secure != tt.want {
+				t.Errorf("cookies = %v, want one with Secure = %v", cookies, tt.want)
+			}
+		})
+	}
+}
+
+// TestHandleOAuthCallback tests state verification, the code exchange and saving the installation
+func TestHandleOAuthCallback(t *testing.T) {
+	now := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+	callback := func(handler *Handler, query, cookie string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, constants.RouteSlackOAuthCallback+"?"+query, nil)
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: oauthStateCookie, Value: cookie})
+		}
+		w := httptest.NewRecorder()
+		handler.HandleOAuthCallback(w, r)
+		return w
+	}
+	exchange := func(_ context.Context, code string) (*slack.OAuthV2Response, error) {
+		if code != "good-code" {
+			return nil, errors.New("invalid_code")
+		}
+		resp := &slack.OAuthV2Response{AccessToken: "xoxb-acme", BotUserID: "B1", Scope: "commands,chat:write"}
+		resp.Team.ID, resp.Team.Name = "T789", "Acme"
+		resp.AuthedUser.ID = "U1"
+		return resp, nil
+	}
+
+	t.Run("installs the workspace", func(t *testing.T) {
+		handler, store := newOAuthTestHandler(t, now)
+		handler.oauthExchange = exchange
+		state, _ := handler.newOAuthState()
+
+		w := callback(handler, "code=good-code&state="+url.QueryEscape(state), state)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %q", w.Code, w.Body.String())
+		}
+		installation, found, _ := store.Get("T789")
+		if !found {
+			t.Fatal("installation was not saved")
+		}
+		if installation.BotToken != "xoxb-acme" || installation.InstalledBy != "U1" || !installation.InstalledAt.Equal(now) {
+			t.Errorf("installation = %+v", installation)
+		}
+	})
+
+	t.Run("rejects invalid state", func(t *testing.T) {
+		handler, store := newOAuthTestHandler(t, now)
+		handler.oauthExchange = exchange
+		state, _ := handler.newOAuthState()
+		other, _ := handler.newOAuthState()
+
+		for name, cookie := range map[string]string{"missing cookie": "", "mismatched cookie": other} {
+			if w := callback(handler, "code=good-code&state="+url.QueryEscape(state), cookie); w.Code != http.StatusBadRequest {
+				t.Errorf("%s: status = %d, want 400", name, w.Code)
+			}
+		}
+		if store.Len() != 0 {
+			t.Error("installation saved despite invalid state")
+		}
+	})
+
+	t.Run("exchange failure", func(t *testing.T) {
+		handler, store := newOAuthTestHandler(t, now)
+		handler.oauthExchange = exchange
+		state, _ := handler.newOAuthState()
+
+		if w := callback(handler, "code=bad-code&state="+url.QueryEscape(state), state); w.Code != http.StatusBadGateway {
+			t.Errorf("status = %d, want 502", w.Code)
+		}
+		if store.Len() != 0 {
+			t.Error("installation saved despite failed exchange")
+		}
+	})
+}
+
+// TestSlackFor tests that installed workspaces use their own bot token
+func TestSlackFor(t *testing.T) {
+	handler, store := newOAuthTestHandler(t, time.Now())
+	clients := map[string]*fakeSlack{}
+	handler.newTeamClient = func(botToken string) SlackAPI {
+		clients[botToken] = &fakeSlack{}
+		return clients[botToken]
+	}
+	store.Save(installations.Installation{TeamID: "T789", BotToken: "xoxb-acme"})
+
+	if handler.slackFor("T456") != handler.defaultSlackClient() {
+		t.Error("workspace without an installation should use the default client")
+	}
+	client := handler.slackFor("T789")
+	if client != SlackAPI(clients["xoxb-acme"]) {
+		t.Error("installed workspace should use its own client")
+	}
+	if handler.slackFor("T789") != client || len(clients) != 1 {
+		t.Error("installed workspace client should be reused")
+	}
+}
@@ -13,18 +13,93 @@ package slack
 import (
 	"sort"
 	"strings"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/fuzzy"
 )
 
+// OptionValueMoreResults is the value of the truncation indicator option added by
+// CustomerOptions. It is not a customer and is ignored if a user selects it.
+const OptionValueMoreResults = "__more_results__"
+
+// CustomerOptions filters customers like FilterCustomerOptions for an external
+// select response. maxResults is clamped to Slack's limit (constants.SlackMaxOptions;
+// <= 0 uses constants.MaxOptionsResults).
+//
+// When more customers match than fit, the last option is replaced by a
+// truncation indicator labelled moreText (e.g., "… more results, keep typing"),
+// so users know to narrow their search.
+func CustomerOptions(customers []string, query string, maxResults int, moreText string) []Option {
+	maxResults = optionsLimit(maxResults)
+	return truncateOptions(FilterCustomerOptions(customers, query, maxResults+1), maxResults, moreText)
+}
+
+// SearchCustomerOptions is CustomerOptions over a cache snapshot's customer
+// search index (notion.CustomerIndex), which is what options requests use:
+// it stays fast with tens of thousands of customers, where scanning them all
+// on each keystroke could miss Slack's 3 second limit. Customers are also
+// found by their aliases, and show their description (e.g. region and tier,
+// see notion.Client.SetCustomerProperties) under their name.
+func SearchCustomerOptions(snapshot *notion.CacheSnapshot, query string, maxResults int, moreText string) []Option {
+	maxResults = optionsLimit(maxResults)
+	names := snapshot.SearchCustomers(query, maxResults+1)
+
+	options := make([]Option, 0, len(names))
+	for _, customer := range names {
+		option := Option{
+			Text:  newOptionText(customer),
+			Value: customer,
+		}
+		if description := snapshot.CustomerDetails(customer).Description; description != "" {
+			if runes := []rune(description); len(runes) > slackMaxOptionTextLength {
+				description = string(runes[:slackMaxOptionTextLength-1]) + "…"
+			}
+			text := newOptionText(description)
+			option.Description = &text
+		}
+		options = append(options, option)
+	}
+	return truncateOptions(options, maxResults, moreText)
+}
+
+// truncateOptions replaces the options past maxResults, and the last one that
+// fits, with the truncation indicator.
+func truncateOptions(options []Option, maxResults int, moreText string) []Option {
+	if len(options) <= maxResults {
+		return options
+	}
+
+	options = options[:maxResults-1]
+	return append(options, Option{
+		Text:  newOptionText(moreText),
+		Value: OptionValueMoreResults,
+	})
+}
+
+// optionsLimit returns the number of options to send, within Slack's limit.
+func optionsLimit(maxResults int) int {
+	if maxResults <= 0 {
+		return constants.MaxOptionsResults
+	}
+	return min(maxResults, constants.SlackMaxOptions)
+}
+
 // FilterCustomerOptions filters a list of customers based on a search query
 // and returns formatted Option objects for Slack.
 //
-// Search logic implements three-tier matching for optimal user experience:
+// Search logic implements four-tier matching for optimal user experience:
 // 1. Exact matches (case-insensitive): "apple" matches "Apple"
 // 2. Prefix matches: "app" matches "Apple Inc", "Application Systems"
 // 3. Contains matches: "inc" matches "Apple Inc", "Lincoln Corp"
+// 4. Fuzzy matches (pkg/fuzzy), for typos: "ruderstack" matches "RudderStack"
 //
-// Each tier is sorted alphabetically, and tiers are combined in order.
-// Results are limited to maxResults (defaults to 100 if <= 0).
+// The first three tiers are sorted alphabetically, fuzzy matches by fewest
+// edits and then alphabetically, and tiers are combined in order.
+// Results are limited to maxResults (defaults to constants.MaxOptionsResults if <= 0).
+// maxResults is not clamped to Slack's limit; use CustomerOptions for responses.
+// Options requests use SearchCustomerOptions instead, which ranks the same
+// way through the cache snapshot's precomputed index.
 //
 // When query is empty, returns the first N customers alphabetically.
 //
@@ -34,9 +109,9 @@ import (
 //	options := FilterCustomerOptions(customers, "app", 100)
 //	// Returns: ["Applied Systems", "Apple Inc"] (exact/prefix matches, alphabetically)
 func FilterCustomerOptions(customers []string, query string, maxResults int) []Option {
-	// Default to 100 results if not specified or invalid
+	// Default to constants.MaxOptionsResults if not specified or invalid
 	if maxResults <= 0 {
-		maxResults = 100
+		maxResults = constants.MaxOptionsResults
 	}
 
 	// Empty query: return first N alphabetically
@@ -67,8 +142,44 @@ func FilterCustomerOptions(customers []string, query string, maxResults int) []O
 		}
 	}
 
-	// Build final options list from tiers
-	return buildOptionsList(exactMatches, prefixMatches, containsMatches, maxResults)
+	// Build final options list from tiers, then fill what's left with typo matches
+	options := buildOptionsList(exactMatches, prefixMatches, containsMatches, maxResults)
+	if len(options) < maxResults {
+		options = append(options, fuzzyOptions(customers, normalizedQuery, maxResults-len(options))...)
+	}
+	return options
+}
+
+// fuzzyOptions returns up to maxResults customers matching the normalized
+// query with typos (see pkg/fuzzy), fewest edits first, then alphabetically.
+// Customers containing the query aren't included; the other tiers have them.
+func fuzzyOptions(customers []string, normalizedQuery string, maxResults int) []Option {
+	maxEdits := fuzzy.MaxEdits(normalizedQuery)
+	if maxEdits == 0 {
+		return nil
+	}
+
+	byEdits := make([][]string, maxEdits+1)
+	for _, customer := range customers {
+		if edits, ok := fuzzy.Match(normalizedQuery, strings.ToLower(customer)); ok && edits > 0 {
+			byEdits[edits] = append(byEdits[edits], customer)
+		}
+	}
+
+	var options []Option
+	for _, matches := range byEdits {
+		sort.Strings(matches)
+		for _, customer := range matches {
+			if len(options) == maxResults {
+				return options
+			}
+			options = append(options, Option{
+				Text:  newOptionText(customer),
+				Value: customer,
+			})
+		}
+	}
+	return options
 }
 
 // formatFirstNOptions returns the first N customers alphabetically as options.
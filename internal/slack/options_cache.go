@@ -0,0 +1,92 @@
+package slack
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// optionsCacheKey identifies an options response: the same query for the same
+// select against the same data, with the same limit and truncation text, gets
+// the same options. Keying by the provider's version of the data (for
+// customers, the snapshot's CustomersChecksum) rather than the workspace means
+// a refresh that changes the data is never served stale options, and
+// workspaces with the same data share entries.
+type optionsCacheKey struct {
+	actionID   string
+	version    string // OptionsProvider.Version of the data searched
+	query      string // Trimmed, lowercased search query, as the customer index normalizes it
+	maxResults int
+	moreText   string
+}
+
+// cachedOptions is an encoded options response with the time it was built.
+type cachedOptions struct {
+	body     []byte
+	storedAt time.Time
+}
+
+// optionsCache memoizes encoded options responses for a short time, so
+// repeated keystrokes from many users (a team all typing "acme" into the
+// customer select) don't search and encode the results again.
+// It holds at most maxEntries responses; when full, expired entries are
+// dropped, and if none had expired, all of them. It is safe for concurrent use.
+type optionsCache struct {
+	ttl        time.Duration
+	maxEntries int
+	mu         sync.Mutex
+	entries    map[optionsCacheKey]cachedOptions
+}
+
+func newOptionsCache(ttl time.Duration, maxEntries int) *optionsCache {
+	return &optionsCache{ttl: ttl, maxEntries: maxEntries, entries: make(map[optionsCacheKey]cachedOptions)}
+}
+
+// newOptionsCacheKey builds the key of an options request.
+func newOptionsCacheKey(actionID, version, query string, maxResults int, moreText string) optionsCacheKey {
+	return optionsCacheKey{
+		actionID:   actionID,
+		version:    version,
+		query:      strings.ToLower(strings.TrimSpace(query)),
+		maxResults: maxResults,
+		moreText:   moreText,
+	}
+}
+
+// get returns the cached response body for key unless it's missing or expired.
+// The body is shared and must not be modified.
+func (c *optionsCache) get(key optionsCacheKey, now time.Time) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.entries[key]
+	if !found || now.Sub(entry.storedAt) >= c.ttl {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// put caches the response body for key.
+func (c *optionsCache) put(key optionsCacheKey, body []byte, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.entries[key]; !found && len(c.entries) >= c.maxEntries {
+		for cachedKey, entry := range c.entries {
+			if now.Sub(entry.storedAt) >= c.ttl {
+				delete(c.entries, cachedKey)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			clear(c.entries)
+		}
+	}
+	c.entries[key] = cachedOptions{body: body, storedAt: now}
+}
+
+// Len returns the number of cached responses, expired entries included.
+func (c *optionsCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
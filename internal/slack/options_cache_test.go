@@ -0,0 +1,96 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"go.uber.org/zap"
+)
+
+// TestOptionsCache tests expiry and the entry limit
+func TestOptionsCache(t *testing.T) {
+	now := time.Now()
+	cache := newOptionsCache(time.Minute, 2)
+	acme := newOptionsCacheKey(ActionIDCustomerOrgSelect, "checksum", " ACME ", 100, "more")
+
+	cache.put(acme, []byte("acme"), now)
+	if body, found := cache.get(newOptionsCacheKey(ActionIDCustomerOrgSelect, "checksum", "acme", 100, "more"), now.Add(59*time.Second)); !found || string(body) != "acme" {
+		t.Errorf("get() = %q, %v, want the response cached for the normalized query", body, found)
+	}
+	for _, key := range []optionsCacheKey{
+		newOptionsCacheKey(ActionIDCustomerOrgSelect, "other-checksum", "acme", 100, "more"),
+		newOptionsCacheKey(ActionIDCustomerOrgSelect, "checksum", "acme", 5, "more"),
+		newOptionsCacheKey(ActionIDCustomerOrgSelect, "checksum", "acm", 100, "more"),
+		newOptionsCacheKey(ActionIDSchemaCustomers, "checksum", "acme", 100, "more"),
+	} {
+		if _, found := cache.get(key, now); found {
+			t.Errorf("get(%+v) found the response for another request", key)
+		}
+	}
+	if _, found := cache.get(acme, now.Add(time.Minute)); found {
+		t.Error("get() returned an expired response")
+	}
+
+	// A full cache drops expired entries first, and everything when none expired
+	cache.put(newOptionsCacheKey(ActionIDCustomerOrgSelect, "checksum", "beta", 100, "more"), []byte("beta"), now.Add(30*time.Second))
+	cache.put(newOptionsCacheKey(ActionIDCustomerOrgSelect, "checksum", "gamma", 100, "more"), []byte("gamma"), now.Add(time.Minute))
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d after dropping the expired entry, want 2", cache.Len())
+	}
+	cache.put(newOptionsCacheKey(ActionIDCustomerOrgSelect, "checksum", "delta", 100, "more"), []byte("delta"), now.Add(time.Minute))
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d after clearing a full cache, want 1", cache.Len())
+	}
+}
+
+// TestHandleOptionsRequest_Cache tests that repeated searches are served from
+// the options cache until the customers change
+func TestHandleOptionsRequest_Cache(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(map[string]string{"Acme": "page-acme", "Beta": "page-beta"}, nil)}
+	handler := NewHandlerWithDependencies(&config.Config{SlackSigningSecret: "secret"}, zap.NewNop(), Dependencies{
+		Backend: backend,
+		Slack:   &fakeSlack{},
+		Clock:   fixedClock(time.Now()),
+	})
+
+	search := func(query string) (cacheResult string, options []Option) {
+		t.Helper()
+		payload, _ := json.Marshal(OptionsRequest{
+			Type:     "block_suggestion",
+			ActionID: ActionIDCustomerOrgSelect,
+			BlockID:  BlockIDCustomerOrg,
+			Value:    query,
+			Team:     Team{ID: "T456"},
+		})
+		body := url.Values{"payload": {string(payload)}}.Encode()
+		w := httptest.NewRecorder()
+		handler.HandleOptionsRequest(w, createValidSlackRequest(http.MethodPost, "/slack/options", []byte(body), "secret"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+		}
+		var response OptionsResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("invalid options response: %v", err)
+		}
+		return w.Header().Get(HeaderOptionsCache), response.Options
+	}
+
+	if result, options := search("ac"); result != "miss" || len(options) != 1 || options[0].Value != "Acme" {
+		t.Fatalf("first search = %s, %+v, want a miss finding Acme", result, options)
+	}
+	if result, options := search(" AC"); result != "hit" || len(options) != 1 || options[0].Value != "Acme" {
+		t.Errorf("repeated search = %s, %+v, want a hit finding Acme", result, options)
+	}
+
+	// A refresh with other customers isn't served the old options
+	backend.snapshot = notion.NewCacheSnapshot(map[string]string{"Acme": "page-acme", "Acme Labs": "page-labs"}, nil)
+	if result, options := search("ac"); result != "miss" || len(options) != 2 {
+		t.Errorf("search after a refresh = %s, %+v, want a miss finding both customers", result, options)
+	}
+}
@@ -0,0 +1,81 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+)
+
+// OptionsSearch is an external select's options request, as the options
+// provider registered for its action_id sees it.
+type OptionsSearch struct {
+	ActionID   string
+	TeamID     string
+	UserID     string
+	Query      string                // What the user typed, untrimmed
+	MaxResults int                   // Options shown, within Slack's limit (see optionsLimit)
+	MoreText   string                // Label of the truncation indicator
+	Snapshot   *notion.CacheSnapshot // The team's cache snapshot, taken once per request
+}
+
+// OptionsProvider is the data source and filter behind an external select.
+// HandleOptionsRequest routes options requests to the provider registered for
+// their action_id; verification, truncation, the options cache and the
+// response come from the handler.
+type OptionsProvider struct {
+	// Search returns the options matching the search, best first. Options past
+	// MaxResults are replaced by the truncation indicator.
+	Search func(ctx context.Context, search OptionsSearch) ([]Option, error)
+	// Version identifies the data Search filters (e.g. a checksum), so
+	// responses are cached by version and query (see options_cache.go). Nil,
+	// or an empty version, searches on every request.
+	Version func(search OptionsSearch) string
+}
+
+// newOptionsProviders registers the built-in external selects: Customer Org,
+// and the customers relations generated from the database schema.
+func (h *Handler) newOptionsProviders() map[string]OptionsProvider {
+	customers := OptionsProvider{
+		Search: func(_ context.Context, search OptionsSearch) ([]Option, error) {
+			return SearchCustomerOptions(search.Snapshot, search.Query, search.MaxResults, search.MoreText), nil
+		},
+		Version: func(search OptionsSearch) string {
+			return search.Snapshot.CustomersChecksum
+		},
+	}
+	return map[string]OptionsProvider{
+		ActionIDCustomerOrgSelect: customers,
+		ActionIDSchemaCustomers:   customers,
+	}
+}
+
+// RegisterOptionsProvider routes the options requests of external selects
+// with actionID to provider. Like CommandRouter.Register, it panics on an
+// action_id that already has a provider, or a provider without Search, which
+// are programming errors. Register providers before serving requests.
+func (h *Handler) RegisterOptionsProvider(actionID string, provider OptionsProvider) {
+	if provider.Search == nil {
+		panic(fmt.Sprintf("slack: options provider for %q has no Search", actionID))
+	}
+	if _, exists := h.optionsProviders[actionID]; exists {
+		panic(fmt.Sprintf("slack: options provider for %q registered twice", actionID))
+	}
+	h.optionsProviders[actionID] = provider
+}
+
+// ListOptionsProvider returns a provider filtering the names list returns,
+// ranked like customers (see FilterCustomerOptions). It suits short lists,
+// such as options read from the database schema; they are filtered on every
+// request rather than cached.
+func ListOptionsProvider(list func(ctx context.Context, search OptionsSearch) ([]string, error)) OptionsProvider {
+	return OptionsProvider{
+		Search: func(ctx context.Context, search OptionsSearch) ([]Option, error) {
+			names, err := list(ctx, search)
+			if err != nil {
+				return nil, err
+			}
+			return CustomerOptions(names, search.Query, search.MaxResults, search.MoreText), nil
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"go.uber.org/zap"
+)
+
+// requestOptions sends an options request for actionID to handler and
+// returns the options cache header and the options
+func requestOptions(t *testing.T, handler *Handler, actionID, query string) (cacheResult string, options []Option) {
+	t.Helper()
+	payload, _ := json.Marshal(OptionsRequest{
+		Type:     "block_suggestion",
+		ActionID: actionID,
+		BlockID:  "block",
+		Value:    query,
+		Team:     Team{ID: "T456"},
+		User:     User{ID: "U123"},
+	})
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	w := httptest.NewRecorder()
+	handler.HandleOptionsRequest(w, createValidSlackRequest(http.MethodPost, "/slack/options", []byte(body), "secret"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var response OptionsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid options response: %v", err)
+	}
+	return w.Header().Get(HeaderOptionsCache), response.Options
+}
+
+// TestHandleOptionsRequest_Providers tests routing options requests to the
+// provider registered for their action_id
+func TestHandleOptionsRequest_Providers(t *testing.T) {
+	handler := NewHandlerWithDependencies(&config.Config{SlackSigningSecret: "secret", MaxOptionsResults: 3}, zap.NewNop(), Dependencies{
+		Backend: &fakeBackend{snapshot: notion.NewCacheSnapshot(map[string]string{"Acme": "page-acme"}, nil)},
+		Slack:   &fakeSlack{},
+		Clock:   fixedClock(time.Now()),
+	})
+
+	var searches []OptionsSearch
+	handler.RegisterOptionsProvider("component_select", ListOptionsProvider(func(_ context.Context, search OptionsSearch) ([]string, error) {
+		searches = append(searches, search)
+		return []string{"API Gateway", "Billing", "Data Pipeline", "Database", "Dashboards", "Destinations"}, nil
+	}))
+	handler.RegisterOptionsProvider("failing_select", OptionsProvider{
+		Search: func(context.Context, OptionsSearch) ([]Option, error) { return nil, errors.New("unavailable") },
+	})
+	versioned := 0
+	handler.RegisterOptionsProvider("versioned_select", OptionsProvider{
+		Search: func(_ context.Context, search OptionsSearch) ([]Option, error) {
+			versioned++
+			return []Option{{Text: newOptionText(search.Query), Value: search.Query}}, nil
+		},
+		Version: func(OptionsSearch) string { return "v1" },
+	})
+
+	result, options := requestOptions(t, handler, "component_select", "d")
+	if result != "" || len(options) != 3 || options[0].Value != "Dashboards" || options[2].Value != OptionValueMoreResults {
+		t.Errorf("component options = %s, %+v, want 2 matches and the truncation indicator, uncached", result, options)
+	}
+	if len(searches) != 1 || searches[0].TeamID != "T456" || searches[0].UserID != "U123" || searches[0].MaxResults != 3 || searches[0].Snapshot == nil {
+		t.Errorf("provider searched %+v", searches)
+	}
+	if _, options := requestOptions(t, handler, "component_select", "d"); len(searches) != 2 || len(options) != 3 {
+		t.Errorf("repeated search without a version searched %d times, %+v", len(searches), options)
+	}
+
+	if _, options := requestOptions(t, handler, "failing_select", "x"); len(options) != 0 {
+		t.Errorf("failing provider options = %+v, want none", options)
+	}
+	if _, options := requestOptions(t, handler, "unknown_select", "x"); len(options) != 0 {
+		t.Errorf("unknown action_id options = %+v, want none", options)
+	}
+
+	requestOptions(t, handler, "versioned_select", "billing")
+	if result, options := requestOptions(t, handler, "versioned_select", "Billing"); result != "hit" || versioned != 1 || len(options) != 1 {
+		t.Errorf("repeated versioned search = %s, %+v after %d searches, want a cache hit", result, options, versioned)
+	}
+
+	// The built-in providers still serve both customer selects
+	for _, actionID := range []string{ActionIDCustomerOrgSelect, ActionIDSchemaCustomers} {
+		if _, options := requestOptions(t, handler, actionID, "acm"); len(options) != 1 || options[0].Value != "Acme" {
+			t.Errorf("%s options = %+v, want Acme", actionID, options)
+		}
+	}
+}
+
+// TestRegisterOptionsProvider_Panics tests that duplicate and incomplete
+// providers are rejected
+func TestRegisterOptionsProvider_Panics(t *testing.T) {
+	handler := NewHandlerWithDependencies(&config.Config{SlackSigningSecret: "secret"}, zap.NewNop(), Dependencies{
+		Backend: &fakeBackend{},
+		Slack:   &fakeSlack{},
+	})
+	search := func(context.Context, OptionsSearch) ([]Option, error) { return nil, nil }
+
+	for name, register := range map[string]func(){
+		"duplicate": func() { handler.RegisterOptionsProvider(ActionIDCustomerOrgSelect, OptionsProvider{Search: search}) },
+		"no search": func() { handler.RegisterOptionsProvider("component_select", OptionsProvider{}) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: RegisterOptionsProvider() didn't panic", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
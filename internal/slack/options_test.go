@@ -1,9 +1,13 @@
 package slack
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
 )
 
 func TestFilterCustomerOptions_EmptyQuery(t *testing.T) {
@@ -82,13 +86,45 @@ func TestFilterCustomerOptions_ExactMatch(t *testing.T) {
 
 	options := FilterCustomerOptions(customers, "apple inc", 100)
 
-	if len(options) != 1 {
-		t.Fatalf("got %d options, want 1", len(options))
+	// "Pineapple Corp" is two edits from "apple inc", so it follows as a fuzzy match
+	if len(options) != 2 {
+		t.Fatalf("got %d options, want 2", len(options))
 	}
 
 	if options[0].Value != "Apple Inc" {
 		t.Errorf("got %q, want %q", options[0].Value, "Apple Inc")
 	}
+	if options[1].Value != "Pineapple Corp" {
+		t.Errorf("got %q, want %q", options[1].Value, "Pineapple Corp")
+	}
+}
+
+// TestFilterCustomerOptions_FuzzyMatch tests that typos still find customers,
+// ranked after exact, prefix and contains matches
+func TestFilterCustomerOptions_FuzzyMatch(t *testing.T) {
+	customers := []string{"Acme Corp", "Acne Studios", "RudderStack", "Big Acmee Holdings", "Microsoft"}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"acmee", []string{"Big Acmee Holdings", "Acme Corp"}},
+		{"ruderstack", []string{"RudderStack"}},
+		{"rudersatck", []string{"RudderStack"}},
+		{"acme", []string{"Acme Corp", "Big Acmee Holdings", "Acne Studios"}},
+		{"acm", []string{"Acme Corp", "Big Acmee Holdings"}}, // Too short for typos
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []string
+			for _, option := range FilterCustomerOptions(customers, tt.query, 100) {
+				got = append(got, option.Value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterCustomerOptions(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
 }
 
 func TestFilterCustomerOptions_PrefixMatch(t *testing.T) {
@@ -200,6 +236,94 @@ func TestFilterCustomerOptions_MaxResultsLimit(t *testing.T) {
 	}
 }
 
+func TestCustomerOptions(t *testing.T) {
+	customers := make([]string, 150)
+	for i := range customers {
+		customers[i] = fmt.Sprintf("Customer %03d", i)
+	}
+
+	tests := []struct {
+		name          string
+		customers     []string
+		maxResults    int
+		wantCount     int
+		wantIndicator bool
+	}{
+		{"fits", customers[:5], 10, 5, false},
+		{"exactly fits", customers[:10], 10, 10, false},
+		{"truncated", customers, 10, 10, true},
+		{"default limit", customers, 0, constants.MaxOptionsResults, true},
+		{"clamped to slack limit", customers, 500, constants.SlackMaxOptions, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := CustomerOptions(tt.customers, "customer", tt.maxResults, "more")
+
+			if len(options) != tt.wantCount {
+				t.Fatalf("got %d options, want %d", len(options), tt.wantCount)
+			}
+			last := options[len(options)-1]
+			if gotIndicator := last.Value == OptionValueMoreResults; gotIndicator != tt.wantIndicator {
+				t.Errorf("last option = %+v, want indicator %v", last, tt.wantIndicator)
+			}
+			if tt.wantIndicator && last.Text.Text != "more" {
+				t.Errorf("indicator text = %q, want more", last.Text.Text)
+			}
+		})
+	}
+}
+
+// TestSearchCustomerOptions tests that searching a snapshot's customer index
+// gives the same options as filtering its customer names
+func TestSearchCustomerOptions(t *testing.T) {
+	customers := map[string]string{}
+	for i := range 300 {
+		customers[fmt.Sprintf("Customer %03d", i)] = fmt.Sprintf("page-%d", i)
+	}
+	for _, name := range []string{"Apple", "apple", "Applied Systems", "Pineapple Inc", "APPLE BANK", "Snapple", "Big Apple Customer"} {
+		customers[name] = "page-" + name
+	}
+	snapshot := notion.NewCacheSnapshot(customers, nil)
+
+	for _, query := range []string{"", " ", "apple", "APP", "pp", "e", "customer", "customer 1", "ustomer 2", "12", "apple bank", "xyz", "appel", "pinaple", "custmer 12", "snaple"} {
+		for _, maxResults := range []int{0, 5, 100} {
+			want := CustomerOptions(snapshot.CustomerNames(), query, maxResults, "more")
+			got := SearchCustomerOptions(snapshot, query, maxResults, "more")
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("SearchCustomerOptions(%q, %d) = %v, want %v", query, maxResults, got, want)
+			}
+		}
+	}
+}
+
+// TestSearchCustomerOptions_Details tests that customers are found by alias
+// and show their description
+func TestSearchCustomerOptions_Details(t *testing.T) {
+	snapshot := notion.NewCacheSnapshotWithDetails(
+		map[string]string{"International Business Machines": "page-ibm", "Initech": "page-initech"},
+		map[string]notion.CustomerDetails{
+			"page-ibm":     {Aliases: []string{"IBM"}, Description: "NA · Enterprise"},
+			"page-initech": {Description: strings.Repeat("Long description ", 10)},
+		},
+		nil,
+	)
+
+	options := SearchCustomerOptions(snapshot, "ibm", 10, "more")
+	if len(options) != 1 || options[0].Value != "International Business Machines" ||
+		options[0].Description == nil || options[0].Description.Text != "NA · Enterprise" {
+		t.Fatalf("SearchCustomerOptions(ibm) = %+v, want IBM with its description", options)
+	}
+
+	options = SearchCustomerOptions(snapshot, "initech", 10, "more")
+	if len(options) != 1 || options[0].Description == nil {
+		t.Fatalf("SearchCustomerOptions(initech) = %+v, want a description", options)
+	}
+	if description := []rune(options[0].Description.Text); len(description) != slackMaxOptionTextLength || description[len(description)-1] != '…' {
+		t.Errorf("description = %q, want it truncated to Slack's limit", options[0].Description.Text)
+	}
+}
+
 func TestFilterCustomerOptions_CaseInsensitive(t *testing.T) {
 	customers := []string{"Apple Inc", "APPLE INC", "apple inc"}
 
@@ -0,0 +1,146 @@
+package slack
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+)
+
+// maxPrivateMetadataLength is Slack's limit on a view's private_metadata.
+const maxPrivateMetadataLength = 3000
+
+// ModalValues are initial values for the submission modal's core fields.
+// Select values that are no longer valid options are left empty.
+type ModalValues struct {
+	Title       string   `json:"title,omitempty"`
+	Theme       string   `json:"theme,omitempty"`
+	ProductArea string   `json:"product_area,omitempty"`
+	Comments    string   `json:"comments,omitempty"`
+	Customers   []string `json:"customers,omitempty"`
+}
+
+// modalValuesFromSubmission returns a submission's core fields as modal
+// values. customers are the names of sub's customer orgs.
+func modalValuesFromSubmission(sub submission.Submission, customers []string) ModalValues {
+	return ModalValues{
+		Title:       sub.Title,
+		Theme:       sub.Theme,
+		ProductArea: sub.ProductArea,
+		Comments:    sub.Comments,
+		Customers:   customers,
+	}
+}
+
+// IsZero reports whether no value is set.
+func (v ModalValues) IsZero() bool {
+	return v.Title == "" && v.Theme == "" && v.ProductArea == "" && v.Comments == "" && len(v.Customers) == 0
+}
+
+// modalMetadata is what the submission and edit modals carry in
+// private_metadata, so it comes back with the view on submission.
+type modalMetadata struct {
+	ID     string       `json:"id,omitempty"`     // Page being edited, or draft the modal was opened from
+	Values *ModalValues `json:"values,omitempty"` // Values the modal was pre-filled with
+
+	// Multi-step submissions (see multistep.go): Step is 2 on the details step,
+	// whose Values hold the title and theme entered in the first step
+	Step      int  `json:"step,omitempty"`
+	Prefilled bool `json:"prefilled,omitempty"` // The first step was pre-filled
+
+	// Permalink is the Slack message the modal was opened from with the
+	// message shortcut (see shortcut.go)
+	Permalink string `json:"permalink,omitempty"`
+}
+
+// encodeModalMetadata encodes metadata as JSON. The values are dropped if they
+// don't fit in private_metadata (they are still pre-filled in the blocks),
+// except for the title and theme the details step can't be submitted without.
+func encodeModalMetadata(metadata modalMetadata) string {
+	if metadata.ID == "" && metadata.Values == nil && metadata.Permalink == "" {
+		return ""
+	}
+	data, _ := json.Marshal(metadata)
+	if len(data) > maxPrivateMetadataLength {
+		var kept *ModalValues
+		if metadata.Step == 2 {
+			kept = &ModalValues{Title: metadata.Values.Title, Theme: metadata.Values.Theme}
+		}
+		metadata.Values = kept
+		data, _ = json.Marshal(metadata)
+	}
+	return string(data)
+}
+
+// decodeModalMetadata decodes a view's private_metadata. Views opened before
+// metadata was JSON carry the page or draft ID as plain text.
+func decodeModalMetadata(raw string) modalMetadata {
+	var metadata modalMetadata
+	if !strings.HasPrefix(raw, "{") || json.Unmarshal([]byte(raw), &metadata) != nil {
+		return modalMetadata{ID: raw}
+	}
+	return metadata
+}
+
+// BuildSubmissionModalWithValues constructs the default submission modal
+// pre-filled with values, which are also carried in its private_metadata.
+func BuildSubmissionModalWithValues(values ModalValues) slack.ModalViewRequest {
+	modal := BuildSubmissionModal()
+	prefillModal(&modal, "", values)
+	return modal
+}
+
+// prefillModal sets the initial values of modal's core field blocks and
+// records id and values in its private_metadata.
+func prefillModal(modal *slack.ModalViewRequest, id string, values ModalValues) {
+	metadata := modalMetadata{ID: id}
+	if !values.IsZero() {
+		metadata.Values = &values
+		prefillSubmissionBlocks(modal.Blocks.BlockSet, values)
+	}
+	modal.PrivateMetadata = encodeModalMetadata(metadata)
+}
+
+// setModalPermalink records the Slack message a modal was opened from in its
+// private_metadata, next to what prefillModal recorded.
+func setModalPermalink(modal *slack.ModalViewRequest, permalink string) {
+	if permalink == "" {
+		return
+	}
+	metadata := decodeModalMetadata(modal.PrivateMetadata)
+	metadata.Permalink = permalink
+	modal.PrivateMetadata = encodeModalMetadata(metadata)
+}
+
+// prefillSubmissionBlocks sets the initial values of the core field blocks.
+// Select values that are no longer valid options are left empty.
+func prefillSubmissionBlocks(blocks []slack.Block, values ModalValues) {
+	for _, block := range blocks {
+		input, ok := block.(*slack.InputBlock)
+		if !ok {
+			continue
+		}
+
+		switch element := input.Element.(type) {
+		case *slack.PlainTextInputBlockElement:
+			switch input.BlockID {
+			case BlockIDTitle:
+				element.InitialValue = values.Title
+			case BlockIDComments:
+				element.InitialValue = values.Comments
+			}
+		case *slack.SelectBlockElement:
+			value := map[string]string{BlockIDTheme: values.Theme, BlockIDProductArea: values.ProductArea}[input.BlockID]
+			for _, option := range element.Options {
+				if value != "" && option.Value == value {
+					element.InitialOption = option
+				}
+			}
+		case *slack.MultiSelectBlockElement:
+			if input.BlockID == BlockIDCustomerOrg && len(values.Customers) > 0 {
+				element.InitialOptions = createOptions(values.Customers)
+			}
+		}
+	}
+}
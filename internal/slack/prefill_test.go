@@ -0,0 +1,75 @@
+package slack
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+// TestModalMetadata tests encoding, legacy plain IDs and the private_metadata size limit
+func TestModalMetadata(t *testing.T) {
+	values := ModalValues{Title: "Exports are slow", Customers: []string{"Acme"}}
+	metadata := decodeModalMetadata(encodeModalMetadata(modalMetadata{ID: "draft-1", Values: &values}))
+	if metadata.ID != "draft-1" || metadata.Values == nil || metadata.Values.Title != values.Title || len(metadata.Values.Customers) != 1 {
+		t.Errorf("round trip = %+v", metadata)
+	}
+
+	if got := encodeModalMetadata(modalMetadata{}); got != "" {
+		t.Errorf("empty metadata encoded as %q", got)
+	}
+	if got := decodeModalMetadata("page-1"); got.ID != "page-1" || got.Values != nil {
+		t.Errorf("legacy metadata = %+v, want the plain ID", got)
+	}
+
+	long := ModalValues{Title: strings.Repeat("a", 2000), Comments: strings.Repeat("b", 2000)}
+	encoded := encodeModalMetadata(modalMetadata{ID: "page-1", Values: &long})
+	if len(encoded) > maxPrivateMetadataLength {
+		t.Fatalf("encoded metadata is %d characters, over Slack's limit", len(encoded))
+	}
+	if got := decodeModalMetadata(encoded); got.ID != "page-1" || got.Values != nil {
+		t.Errorf("oversized metadata = %+v, want only the ID kept", got)
+	}
+}
+
+// TestBuildSubmissionModalWithValues tests that the values are pre-filled and carried in private_metadata
+func TestBuildSubmissionModalWithValues(t *testing.T) {
+	modal := BuildSubmissionModalWithValues(ModalValues{
+		Title:       "Exports are slow",
+		Theme:       "Feature Improvement",
+		ProductArea: "Not an area",
+		Customers:   []string{"Acme"},
+	})
+
+	for _, block := range modal.Blocks.BlockSet {
+		input, ok := block.(*slack.InputBlock)
+		if !ok {
+			continue
+		}
+		switch element := input.Element.(type) {
+		case *slack.PlainTextInputBlockElement:
+			if input.BlockID == BlockIDTitle && element.InitialValue != "Exports are slow" {
+				t.Errorf("title initial value = %q", element.InitialValue)
+			}
+		case *slack.SelectBlockElement:
+			switch {
+			case input.BlockID == BlockIDTheme && (element.InitialOption == nil || element.InitialOption.Value != "Feature Improvement"):
+				t.Errorf("theme initial option = %+v", element.InitialOption)
+			case input.BlockID == BlockIDProductArea && element.InitialOption != nil:
+				t.Errorf("invalid product area pre-filled as %+v", element.InitialOption)
+			}
+		case *slack.MultiSelectBlockElement:
+			if input.BlockID == BlockIDCustomerOrg && len(element.InitialOptions) != 1 {
+				t.Errorf("customer initial options = %+v", element.InitialOptions)
+			}
+		}
+	}
+
+	metadata := decodeModalMetadata(modal.PrivateMetadata)
+	if metadata.Values == nil || metadata.Values.Theme != "Feature Improvement" {
+		t.Errorf("private_metadata = %q, want the values", modal.PrivateMetadata)
+	}
+	if BuildSubmissionModal().PrivateMetadata != "" {
+		t.Error("an empty modal carries private_metadata")
+	}
+}
@@ -0,0 +1,220 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/analytics"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/queue"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// SetSubmissionQueue enables asynchronous submissions. Validated submissions are
+// enqueued and Slack is answered immediately, before the submitter is even
+// looked up; the queue calls ProcessQueuedSubmission and FailQueuedSubmission
+// to create the page and tell the submitter the outcome.
+func (h *Handler) SetSubmissionQueue(q *queue.Queue) {
+	h.queue = q
+}
+
+// enqueueSubmission queues a validated submission to be created after delay
+// (0 for straight away). Returns false if it could not be queued, in which case
+// the caller falls back to submitting synchronously.
+func (h *Handler) enqueueSubmission(job queue.Job, delay time.Duration) bool {
+	if delay > 0 {
+		job.NextAttemptAt = h.clock.Now().UTC().Add(delay)
+	}
+	job, err := h.queue.Enqueue(job)
+	if err != nil {
+		h.logger.Error("failed to enqueue submission, submitting synchronously",
+			zap.String("slack_user_id", job.Submission.Source.SlackUserID),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	h.logger.Info("submission queued",
+		zap.String("job_id", job.ID),
+		zap.String("slack_user_id", job.Submission.Source.SlackUserID),
+	)
+	return true
+}
+
+// queueIfUnavailable queues a submission whose page couldn't be created because
+// Notion is unavailable (notion.IsUnavailable), so it's created once Notion
+// recovers instead of being lost. The first attempt waits for the circuit
+// breaker to let requests through again. Returns false for other errors, when
+// there is no queue, or when queueing fails.
+func (h *Handler) queueIfUnavailable(job queue.Job, err error) bool {
+	if h.queue == nil || !notion.IsUnavailable(err) {
+		return false
+	}
+	h.logger.Warn("Notion is unavailable, queueing submission",
+		zap.String("slack_user_id", job.Submission.Source.SlackUserID),
+		zap.Error(err),
+	)
+	return h.enqueueSubmission(job, constants.CircuitBreakerOpenDuration)
+}
+
+// queuedModal replaces the submitted modal with text, for submissions queued
+// because Notion is unavailable. Closing it closes any modals beneath it too.
+func queuedModal(text string) slack.ModalViewRequest {
+	return slack.ModalViewRequest{
+		Type:         slack.VTModal,
+		Title:        newPlainText(ModalTitleQueued),
+		Close:        newPlainText(ModalCloseText),
+		Blocks:       slack.Blocks{BlockSet: []slack.Block{newMarkdownSection(text)}},
+		ClearOnClose: true,
+	}
+}
+
+// ProcessQueuedSubmission is the queue.ProcessFunc. It maps the submitter to
+// their Notion user if that was left to the queue, creates the Notion page,
+// schedules any requested reminder, and posts the submission confirmation.
+//
+// Transient Slack and Notion errors are returned for retry; anything else
+// (unknown submitter, validation, permissions) is marked permanent so the user
+// hears about it straight away.
+func (h *Handler) ProcessQueuedSubmission(ctx context.Context, job queue.Job) error {
+	sub := job.Submission
+	if sub.SubmitterNotionID == "" && sub.Source.SlackUserID != "" {
+		err := h.attachQueuedSubmitter(&sub)
+		var unmapped submitterError
+		if errors.As(err, &unmapped) && unmapped.triage {
+			return h.triageQueuedSubmission(ctx, job, sub)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	page, err := h.createPage(ctx, sub.Source.SlackTeamID, sub)
+	if err != nil {
+		if !notion.IsRetryableError(err) {
+			return queue.Permanent(err)
+		}
+		return err
+	}
+
+	h.logger.Info("successfully submitted queued form to Notion",
+		zap.String("job_id", job.ID),
+		zap.String("slack_user_id", sub.Source.SlackUserID),
+		zap.String("page_id", page.ID),
+		zap.String("page_url", page.URL),
+		zap.Bool("recovered", page.Recovered),
+	)
+
+	h.scheduleReminder(sub.Source.SlackTeamID, sub.Source.SlackUserID, sub.Title, page, job.ReminderDelay)
+	h.trackIdea(sub, page)
+	h.watchStatus(sub, page)
+	h.trackSubmission(analytics.EventSubmissionCreated, queuedPayload(sub), &sub, "success")
+
+	// The page exists now, so failed confirmations, comments or attachments must not trigger a retry (and a duplicate page)
+	copies := h.fanoutWrite(ctx, sub, page)
+	thread := h.postConfirmation(ctx, sub, page, copies)
+	h.appendComments(ctx, sub, page, thread)
+	h.attachFiles(ctx, sub, page)
+	if job.ResponseURL != "" {
+		h.reportQueued(ctx, job, h.messages.Format(messages.KeySubmissionConfirmation, messages.Params{"title": sub.Title, "url": page.URL}))
+	}
+	return nil
+}
+
+// attachQueuedSubmitter maps a queued submission's Slack user to their Notion
+// user. A failed Slack lookup is returned for retry; a user who can't be
+// mapped fails the job, unless their idea is to be triaged.
+func (h *Handler) attachQueuedSubmitter(sub *submission.Submission) error {
+	snapshot := h.cacheFor(sub.Source.SlackTeamID).Snapshot()
+	notionUserID, profile, err := h.submitterNotionID(sub.Source.SlackTeamID, sub.Source.SlackUserID, snapshot)
+	sub.Source.SubmitterEmail = profile.Email
+	sub.Source.SubmitterName = profile.RealName
+	if err != nil {
+		if unmapped := err.(submitterError); unmapped.lookup || unmapped.triage {
+			return err
+		}
+		return queue.Permanent(err)
+	}
+	sub.SubmitterNotionID = notionUserID
+	return nil
+}
+
+// triageQueuedSubmission posts a queued idea held back by SubmitterPolicyTriage
+// to GUEST_TRIAGE_CHANNEL instead of creating it, and tells the submitter. A
+// failed post is returned for retry.
+func (h *Handler) triageQueuedSubmission(ctx context.Context, job queue.Job, sub submission.Submission) error {
+	if err := h.postToTriage(ctx, sub); err != nil {
+		return err
+	}
+	h.releaseSubmission(sub)
+	h.reportQueued(ctx, job, h.messages.Format(messages.KeySubmissionTriaged, messages.Params{"title": sub.Title}))
+	return nil
+}
+
+// FailQueuedSubmission is the queue.FailFunc. It tells the submitter their idea
+// was not saved so they can submit it again.
+func (h *Handler) FailQueuedSubmission(ctx context.Context, job queue.Job, err error) {
+	sub := job.Submission
+	h.releaseSubmission(sub)
+
+	var unmapped submitterError
+	if errors.As(err, &unmapped) && !unmapped.lookup {
+		h.trackSubmission(analytics.EventSubmissionFailed, queuedPayload(sub), &sub, "user_not_found")
+		h.reportQueued(ctx, job, h.messages.Format(messages.KeyQueuedSubmissionRejected, messages.Params{
+			"title": sub.Title, "reason": unmapped.message,
+		}))
+		return
+	}
+
+	h.trackSubmission(analytics.EventSubmissionFailed, queuedPayload(sub), &sub, "notion_error")
+	h.reportQueued(ctx, job, h.messages.Format(messages.KeyQueuedSubmissionFailed, messages.Params{
+		"title": sub.Title, "error": err,
+	}))
+}
+
+// reportQueued tells the submitter how their queued submission went: through
+// the response_url of the command that submitted it, or by DM when there is
+// none or it has expired (Slack accepts them for 30 minutes).
+func (h *Handler) reportQueued(ctx context.Context, job queue.Job, text string) {
+	sub := job.Submission
+	if job.ResponseURL != "" {
+		err := h.respondLater(job.ResponseURL, text)
+		if err == nil {
+			return
+		}
+		h.logger.Warn("failed to report queued submission through the response URL, sending a DM",
+			zap.String("job_id", job.ID),
+			zap.Error(err),
+		)
+	}
+	h.notifySubmitter(ctx, sub.Source.SlackTeamID, sub.Source.SlackUserID, text)
+}
+
+// notifySubmitter DMs a Slack user, logging (not returning) failures.
+func (h *Handler) notifySubmitter(ctx context.Context, slackTeamID, slackUserID, text string) {
+	if slackUserID == "" {
+		return
+	}
+	if _, _, err := h.slackFor(slackTeamID).PostMessageContext(ctx, slackUserID, slack.MsgOptionText(text, false)); err != nil {
+		h.recordSlackAPIError("chat.postMessage", err)
+		h.logger.Error("failed to DM submitter about queued submission",
+			zap.String("slack_user_id", slackUserID),
+			zap.Error(err),
+		)
+	}
+}
+
+// queuedPayload rebuilds the parts of the interaction payload used for analytics
+// from a queued submission's source.
+func queuedPayload(sub submission.Submission) *InteractionPayload {
+	return &InteractionPayload{
+		User: User{ID: sub.Source.SlackUserID},
+		Team: Team{ID: sub.Source.SlackTeamID},
+		View: View{CallbackID: ModalCallbackIDSubmitForm},
+	}
+}
@@ -0,0 +1,222 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/featureflags"
+	"github.com/rudderlabs/hopperbot/pkg/httpclient"
+	"github.com/rudderlabs/hopperbot/pkg/queue"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// TestProcessQueuedSubmission_InvalidIsPermanent tests that invalid submissions are not retried
+func TestProcessQueuedSubmission_InvalidIsPermanent(t *testing.T) {
+	handler := NewHandler(&config.Config{SlackBotToken: "test-token"}, zap.NewNop())
+
+	// Missing theme, product area, and submitter: rejected before any Notion request
+	err := handler.ProcessQueuedSubmission(context.Background(), queue.Job{
+		ID:         "job-1",
+		Submission: submission.Submission{Title: "Test Idea"},
+	})
+	if err == nil {
+		t.Fatal("expected error for incomplete submission")
+	}
+	if !queue.IsPermanent(err) {
+		t.Errorf("error = %v, want permanent", err)
+	}
+}
+
+// TestQueuedPayload tests rebuilding analytics context from a queued submission
+func TestQueuedPayload(t *testing.T) {
+	payload := queuedPayload(submission.Submission{
+		Source: submission.Source{Channel: submission.ChannelSlack, SlackUserID: "U123", SlackTeamID: "T456"},
+	})
+	if payload.User.ID != "U123" || payload.Team.ID != "T456" || payload.View.CallbackID != ModalCallbackIDSubmitForm {
+		t.Errorf("queuedPayload() = %+v", payload)
+	}
+}
+
+// TestHandleInteractive_QueuesWhileNotionUnavailable tests that a synchronous
+// submission failing fast on an open circuit is queued and the modal says so
+func TestHandleInteractive_QueuesWhileNotionUnavailable(t *testing.T) {
+	backend := &fakeBackend{
+		snapshot: notion.NewCacheSnapshot(
+			map[string]string{"Acme": "customer-page-acme"},
+			map[string]string{"alice@example.com": "notion-user-alice"},
+		),
+		submitErr: fmt.Errorf("failed to send request: %w", httpclient.ErrCircuitOpen),
+	}
+	slackAPI := &fakeSlack{
+		users: map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+	}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+
+	store, err := queue.NewFileStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.SetSubmissionQueue(queue.NewQueue(store, handler.ProcessQueuedSubmission, handler.FailQueuedSubmission, nil, zap.NewNop(), time.Minute))
+	flags := featureflags.New()
+	flags.Set(map[string]bool{featureflags.SubmissionQueue: false}) // Submit synchronously
+	handler.SetFeatureFlags(flags)
+
+	title := "Exports are slow"
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, submissionRequest(t, map[string]map[string]StateValue{
+		BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+		BlockIDTheme:       {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "Customer Pain Point"}}},
+		BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+		BlockIDComments:    {ActionIDCommentsInput: {Type: "plain_text_input"}},
+		BlockIDCustomerOrg: {ActionIDCustomerOrgSelect: {Type: "multi_external_select", SelectedOptions: []SelectedOption{{Value: "Acme"}}}},
+	}))
+
+	var response struct {
+		ResponseAction ResponseAction         `json:"response_action"`
+		View           slack.ModalViewRequest `json:"view"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.ResponseAction != ResponseActionUpdate || !response.View.ClearOnClose {
+		t.Fatalf("response = %+v, want the modal replaced by the queued notice", response)
+	}
+	section, ok := response.View.Blocks.BlockSet[0].(*slack.SectionBlock)
+	if !ok || !strings.Contains(section.Text.Text, "was queued") {
+		t.Errorf("notice = %+v, want the queued message", response.View.Blocks.BlockSet[0])
+	}
+
+	// Queued for after the circuit breaker lets requests through again
+	if due, _ := store.Due(time.Now()); len(due) != 0 {
+		t.Errorf("due jobs = %d, want the job delayed", len(due))
+	}
+	due, _ := store.Due(time.Now().Add(constants.CircuitBreakerOpenDuration + time.Second))
+	if len(due) != 1 || due[0].Submission.Title != title {
+		t.Fatalf("queued jobs = %+v, want the submission", due)
+	}
+}
+
+// TestHandleInteractive_QueuedBeforeLookup tests that a queued modal
+// submission closes the modal without looking up the submitter, and that a
+// submitter the worker can't map is told by DM
+func TestHandleInteractive_QueuedBeforeLookup(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(
+		map[string]string{"Acme": "customer-page-acme"},
+		map[string]string{"alice@example.com": "notion-user-alice"},
+	)}
+	slackAPI := &fakeSlack{
+		users:  map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "bob@example.com"}}},
+		posted: make(chan string, 5),
+		sent:   make(chan url.Values, 5),
+	}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+	store, err := queue.NewFileStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := queue.NewQueue(store, handler.ProcessQueuedSubmission, handler.FailQueuedSubmission, nil, zap.NewNop(), time.Minute)
+	handler.SetSubmissionQueue(q)
+
+	title := "Exports are slow"
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, submissionRequest(t, map[string]map[string]StateValue{
+		BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+		BlockIDTheme:       {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "Customer Pain Point"}}},
+		BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+		BlockIDComments:    {ActionIDCommentsInput: {Type: "plain_text_input"}},
+		BlockIDCustomerOrg: {ActionIDCustomerOrgSelect: {Type: "multi_external_select", SelectedOptions: []SelectedOption{{Value: "Acme"}}}},
+	}))
+	if strings.Contains(w.Body.String(), "errors") {
+		t.Fatalf("response = %s, want the modal closed", w.Body.String())
+	}
+	if store.Len() != 1 {
+		t.Fatalf("queued jobs = %d, want the submission queued", store.Len())
+	}
+
+	q.RunDue()
+	if store.Len() != 0 {
+		t.Errorf("queued jobs = %d, want the job given up on", store.Len())
+	}
+	select {
+	case values := <-slackAPI.sent:
+		if text := values.Get("text"); values.Get("channel") != "U123" || !strings.Contains(text, "wasn't submitted") || !strings.Contains(text, "bob@example.com") {
+			t.Errorf("DM = %v, want why the idea wasn't submitted", values)
+		}
+	default:
+		t.Fatal("submitter was not told the idea wasn't submitted")
+	}
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if len(backend.submissions) != 0 {
+		t.Errorf("backend submissions = %d, want none for an unmapped submitter", len(backend.submissions))
+	}
+}
+
+// TestQuickSubmit_Queued tests that a queued quick submission is acknowledged
+// straight away and its outcome is posted through the response_url
+func TestQuickSubmit_Queued(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(
+		map[string]string{"Acme Corp": "customer-page-acme"},
+		map[string]string{"alice@example.com": "notion-user-alice"},
+	)}
+	slackAPI := &fakeSlack{
+		users:     map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+		posted:    make(chan string, 5),
+		responded: make(chan string, 5),
+	}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+	store, err := queue.NewFileStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := queue.NewQueue(store, handler.ProcessQueuedSubmission, handler.FailQueuedSubmission, nil, zap.NewNop(), time.Minute)
+	handler.SetSubmissionQueue(q)
+
+	body := url.Values{
+		"command":      {"/hopperbot"},
+		"text":         {`"Exports are slow" theme:"customer pain point" area:ai/ml customer:"acme corp"`},
+		"team_id":      {"T456"},
+		"user_id":      {"U123"},
+		"channel_id":   {"C789"},
+		"response_url": {"https://hooks.slack.com/commands/T456/1/abc"},
+	}.Encode()
+	w := httptest.NewRecorder()
+	handler.HandleSlashCommand(w, createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+	if !strings.Contains(w.Body.String(), "Submitting") {
+		t.Fatalf("response = %s, want the submission acknowledged", w.Body.String())
+	}
+
+	due, _ := store.Due(time.Now())
+	if len(due) != 1 || due[0].ResponseURL == "" || due[0].Submission.SubmitterNotionID != "" {
+		t.Fatalf("queued jobs = %+v, want the submission with its response_url and the submitter left to the worker", due)
+	}
+
+	q.RunDue()
+	backend.mu.Lock()
+	submitted := backend.submissions
+	backend.mu.Unlock()
+	if len(submitted) != 1 || submitted[0].SubmitterNotionID != "notion-user-alice" || submitted[0].Source.SubmitterEmail != "alice@example.com" {
+		t.Fatalf("backend submissions = %+v, want the submission by alice", submitted)
+	}
+
+	select {
+	case endpoint := <-slackAPI.responded:
+		if endpoint != "https://hooks.slack.com/commands/T456/1/abc" {
+			t.Errorf("outcome posted to %s, want the response_url", endpoint)
+		}
+	default:
+		t.Error("outcome was not posted through the response_url")
+	}
+}
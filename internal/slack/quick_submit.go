@@ -0,0 +1,409 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/analytics"
+	"github.com/rudderlabs/hopperbot/pkg/featureflags"
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/queue"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// quickSubmitFields maps the key:value fields of a quick submission to the
+// modal blocks they fill.
+var quickSubmitFields = map[string]string{
+	"theme":    BlockIDTheme,
+	"category": BlockIDTheme,
+	"area":     BlockIDProductArea,
+	"customer": BlockIDCustomerOrg,
+}
+
+// quickSubmitFieldOrder is the order validation errors are listed in, matching the modal.
+var quickSubmitFieldOrder = []string{BlockIDTitle, BlockIDTheme, BlockIDProductArea, BlockIDCustomerOrg, BlockIDComments}
+
+// quoteNormalizer turns the curly quotes Slack clients may substitute into straight quotes.
+var quoteNormalizer = strings.NewReplacer("“", `"`, "”", `"`)
+
+// quickSubmission is what a power user typed after /hopperbot, e.g.
+//
+//	"Exports are slow" theme:"Customer Pain Point" area:AI/ML customer:"Acme Corp" -- comments here
+type quickSubmission struct {
+	Title       string
+	Theme       string
+	ProductArea string
+	Customers   []string
+	Comments    string
+}
+
+// parseQuickSubmit parses a quick submission. Words outside key:value fields
+// form the title; everything after a standalone "--" is the comments.
+// customer: may be repeated. Parsing stops at the first error, returning what
+// was parsed so far so it can still pre-fill the modal.
+func parseQuickSubmit(text string) (quickSubmission, error) {
+	var qs quickSubmission
+	var title []string
+	fail := func(err error) (quickSubmission, error) {
+		qs.Title = strings.Join(title, " ")
+		return qs, err
+	}
+
+	rest := quoteNormalizer.Replace(text)
+	for {
+		rest = strings.TrimLeft(rest, " \t\n")
+		if rest == "" {
+			break
+		}
+		if rest == "--" || strings.HasPrefix(rest, "-- ") || strings.HasPrefix(rest, "--\n") {
+			qs.Comments = strings.TrimSpace(rest[2:])
+			break
+		}
+
+		key, value, next, err := nextQuickSubmitToken(rest)
+		if err != nil {
+			return fail(err)
+		}
+		rest = next
+
+		switch quickSubmitFields[key] {
+		case "":
+			title = append(title, value)
+		case BlockIDTheme:
+			if qs.Theme != "" {
+				return fail(fmt.Errorf("%s: is given twice", key))
+			}
+			qs.Theme = value
+		case BlockIDProductArea:
+			if qs.ProductArea != "" {
+				return fail(fmt.Errorf("%s: is given twice", key))
+			}
+			qs.ProductArea = value
+		case BlockIDCustomerOrg:
+			qs.Customers = append(qs.Customers, value)
+		}
+	}
+	qs.Title = strings.Join(title, " ")
+	return qs, nil
+}
+
+// nextQuickSubmitToken reads one whitespace-separated token, keeping quoted
+// text together. A token of the form key:value with a key in
+// quickSubmitFields returns its key; other tokens (e.g. "Note:" or a URL in
+// the title) return key "".
+func nextQuickSubmitToken(text string) (key, value, rest string, err error) {
+	var token strings.Builder
+	quoted := false
+	for i, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			continue
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			rest = text[i:]
+		case !quoted && r == ':' && key == "" && quickSubmitFields[token.String()] != "":
+			key = token.String()
+			token.Reset()
+			continue
+		}
+		if rest != "" {
+			break
+		}
+		token.WriteRune(r)
+	}
+	if quoted {
+		return "", "", "", errors.New("a quote is not closed")
+	}
+	value = strings.TrimSpace(token.String())
+	if key != "" && value == "" {
+		return "", "", "", fmt.Errorf("%s: has no value", key)
+	}
+	return key, value, rest, nil
+}
+
+// viewState returns the quick submission as the view state the modal would
+// submit, so it is validated by the same code. Theme and product area match
+// their options case-insensitively, customers the cache.
+func (qs quickSubmission) viewState(options notion.SelectOptions, snapshot *notion.CacheSnapshot) ViewState {
+	title, comments := qs.Title, qs.Comments
+	customers := make([]SelectedOption, 0, len(qs.Customers))
+	for _, name := range qs.Customers {
+		if canonical, _, found := snapshot.FindCustomer(name); found {
+			name = canonical
+		}
+		customers = append(customers, SelectedOption{Value: name})
+	}
+	return ViewState{Values: map[string]map[string]StateValue{
+		BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+		BlockIDTheme:       {ActionIDThemeSelect: selectedStateValue(matchOption(options.Themes, qs.Theme))},
+		BlockIDProductArea: {ActionIDProductAreaSelect: selectedStateValue(matchOption(options.ProductAreas, qs.ProductArea))},
+		BlockIDComments:    {ActionIDCommentsInput: {Type: "plain_text_input", Value: &comments}},
+		BlockIDCustomerOrg: {ActionIDCustomerOrgSelect: {Type: "multi_external_select", SelectedOptions: customers}},
+	}}
+}
+
+// selectedStateValue returns a static select's state with value selected, or nothing if value is "".
+func selectedStateValue(value string) StateValue {
+	state := StateValue{Type: "static_select"}
+	if value != "" {
+		state.SelectedOption = &SelectedOption{Value: value}
+	}
+	return state
+}
+
+// matchOption returns the option equal to value ignoring case, or value itself.
+func matchOption(options []string, value string) string {
+	for _, option := range options {
+		if strings.EqualFold(option, value) {
+			return option
+		}
+	}
+	return value
+}
+
+// handleQuickSubmit handles /hopperbot "Title" theme:… area:… customer:… -- comments.
+//
+// The text is validated by the modal's own validation. Valid ideas are
+// acknowledged straight away and created in the background (or through the
+// submission queue), with the outcome posted ephemerally through the
+// response_url. Otherwise the errors are listed and the modal is opened
+// pre-filled with what was typed, so nothing has to be typed again.
+func (h *Handler) handleQuickSubmit(ctx context.Context, w http.ResponseWriter, cmd SlashCommand) {
+	payload := commandPayload(cmd)
+
+	// Reject submissions up front while the ideas database is missing required properties
+	if err := h.backendFor(cmd.TeamID).SchemaError(); err != nil {
+		logging.FromContext(ctx, h.logger).Warn("rejecting quick submission while the ideas database schema is invalid", zap.Error(err))
+		h.recordSlackCommand(cmd.Command, "error")
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, nil, "schema_invalid")
+		respondToSlack(w, h.messages.Format(messages.KeySubmissionsPaused, nil))
+		return
+	}
+
+	snapshot := h.cacheFor(cmd.TeamID).Snapshot()
+	qs, parseErr := parseQuickSubmit(cmd.ArgText)
+	state := qs.viewState(h.selectOptions(), snapshot)
+	if parseErr != nil {
+		h.recordSlackCommand(cmd.Command, "validation_error")
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, nil, "validation_error")
+		h.openQuickSubmitFallback(w, cmd, state, parseErr.Error())
+		return
+	}
+
+	sub, err := h.extractAndValidateFields(ctx, cmd.TeamID, state, snapshot)
+	if err != nil {
+		logging.FromContext(ctx, h.logger).Info("quick submission failed validation, opening the modal", zap.Error(err))
+		h.recordSlackCommand(cmd.Command, "validation_error")
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, nil, "validation_error")
+		h.openQuickSubmitFallback(w, cmd, state, validationErrorText(err.(fieldValidationError).errors))
+		return
+	}
+
+	sub.Source = submission.Source{
+		Channel:     submission.ChannelSlackCommand,
+		SlackUserID: cmd.UserID,
+		SlackTeamID: cmd.TeamID,
+		SubmittedAt: h.clock.Now().UTC(),
+	}
+
+	// Queued submissions look up the submitter in the worker, so the command is answered within Slack's 3 seconds
+	queued := h.queue != nil && h.flags.Enabled(featureflags.SubmissionQueue)
+	if !queued && !h.attachQuickSubmitter(ctx, w, cmd, snapshot, &sub) {
+		return
+	}
+	if message := h.duplicateMessage(sub); message != "" {
+		h.recordSlackCommand(cmd.Command, "duplicate")
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, &sub, "duplicate")
+		respondToSlack(w, message)
+		return
+	}
+	if message := h.limitMessage(ctx, sub); message != "" {
+		h.recordSlackCommand(cmd.Command, "limited")
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, &sub, "limited")
+		respondToSlack(w, message)
+		return
+	}
+	if queued && !h.enqueueSubmission(quickSubmitJob(cmd, sub), 0) {
+		if !h.attachQuickSubmitter(ctx, w, cmd, snapshot, &sub) {
+			h.releaseSubmission(sub)
+			return
+		}
+		queued = false
+	}
+	h.customerUsage.Record(sub.CustomerOrgs)
+	h.recordSlackCommand(cmd.Command, "success")
+	respondToSlack(w, h.messages.Format(messages.KeyQuickSubmitAccepted, messages.Params{"title": sub.Title}))
+
+	if !queued {
+		h.background("quick_submission", func() { h.createQuickSubmission(context.WithoutCancel(ctx), cmd, sub) })
+	}
+}
+
+// quickSubmitJob queues a quick submission, reporting the outcome through the
+// command's response_url.
+func quickSubmitJob(cmd SlashCommand, sub submission.Submission) queue.Job {
+	return queue.Job{Submission: sub, ResponseURL: cmd.ResponseURL}
+}
+
+// createQuickSubmission creates the page for a validated quick submission and
+// reports the outcome through the command's response_url. ctx carries the
+// command's request ID but must outlive the request.
+func (h *Handler) createQuickSubmission(ctx context.Context, cmd SlashCommand, sub submission.Submission) {
+	payload := commandPayload(cmd)
+	logger := logging.FromContext(ctx, h.logger)
+
+	page, err := h.createPage(ctx, cmd.TeamID, sub)
+	if err != nil && h.queueIfUnavailable(quickSubmitJob(cmd, sub), err) {
+		text := h.messages.Format(messages.KeySubmitQueuedUnavailable, messages.Params{"title": sub.Title})
+		if err := h.respondLater(cmd.ResponseURL, text); err != nil {
+			logger.Error("failed to report queued quick submission", zap.Error(err))
+		}
+		return
+	}
+	if err != nil {
+		logger.Error("failed to submit quick submission to Notion", zap.Error(err))
+		h.releaseSubmission(sub)
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, &sub, "notion_error")
+		if err := h.respondLater(cmd.ResponseURL, h.submitErrorMessage(ctx, err)); err != nil {
+			logger.Error("failed to report quick submission failure", zap.Error(err))
+		}
+		return
+	}
+
+	logger.Info("successfully submitted quick submission to Notion",
+		zap.String("page_id", page.ID),
+		zap.String("page_url", page.URL),
+	)
+	h.trackIdea(sub, page)
+	h.watchStatus(sub, page)
+	h.trackSubmission(analytics.EventSubmissionCreated, payload, &sub, "success")
+	copies := h.fanoutWrite(ctx, sub, page)
+	thread := h.postConfirmation(ctx, sub, page, copies)
+	h.appendComments(ctx, sub, page, thread)
+
+	text := h.messages.Format(messages.KeySubmissionConfirmation, messages.Params{"title": sub.Title, "url": page.URL})
+	if err := h.respondLater(cmd.ResponseURL, text); err != nil {
+		logger.Error("failed to report quick submission", zap.Error(err))
+	}
+}
+
+// openQuickSubmitFallback opens the modal pre-filled from state and replies
+// with why the quick submission wasn't accepted.
+func (h *Handler) openQuickSubmitFallback(w http.ResponseWriter, cmd SlashCommand, state ViewState, reason string) {
+	text := h.messages.Format(messages.KeyQuickSubmitInvalid, messages.Params{"errors": reason})
+
+	var values ModalValues
+	values.Title, _ = state.GetValue(BlockIDTitle, ActionIDTitleInput)
+	values.Comments, _ = state.GetValue(BlockIDComments, ActionIDCommentsInput)
+	values.Theme, _ = state.GetSelectedOption(BlockIDTheme, ActionIDThemeSelect)
+	values.ProductArea, _ = state.GetSelectedOption(BlockIDProductArea, ActionIDProductAreaSelect)
+	snapshot := h.cacheFor(cmd.TeamID).Snapshot()
+	orgs, _ := selectedCustomerOrgs(state)
+	for _, org := range orgs {
+		if _, found := snapshot.CustomerPageID(org); found {
+			values.Customers = append(values.Customers, org)
+		}
+	}
+
+	if cmd.TriggerID != "" {
+		if _, err := h.slackFor(cmd.TeamID).OpenView(cmd.TriggerID, h.submissionModalWith(cmd.TeamID, "", values)); err != nil {
+			h.recordSlackAPIError("views.open", err)
+			h.logger.Error("failed to open modal for quick submission", zap.Error(err))
+		} else {
+			text += "\n" + h.messages.Format(messages.KeyQuickSubmitFormOpened, nil)
+		}
+	}
+	respondToSlack(w, text)
+}
+
+// commandPayload identifies a slash command's user and team for analytics,
+// which are keyed on interaction payloads.
+func commandPayload(cmd SlashCommand) *InteractionPayload {
+	return &InteractionPayload{User: User{ID: cmd.UserID}, Team: Team{ID: cmd.TeamID}}
+}
+
+// validationErrorText joins validation errors in modal order.
+func validationErrorText(errs map[string]string) string {
+	var lines []string
+	for _, blockID := range quickSubmitFieldOrder {
+		if message, ok := errs[blockID]; ok {
+			lines = append(lines, message)
+			delete(errs, blockID)
+		}
+	}
+	for _, message := range errs {
+		lines = append(lines, message)
+	}
+	return strings.Join(lines, "; ")
+}
+
+// submitterError is why a Slack user couldn't be mapped to a Notion user. Its
+// text is the message to show the user.
+type submitterError struct {
+	message string
+	reason  string // Metric and analytics status, e.g. user_not_found or slack_guest
+	lookup  bool   // Slack's users.info failed, so trying again may succeed
+	triage  bool   // SubmitterPolicyTriage: the idea goes to GUEST_TRIAGE_CHANNEL instead
+}
+
+func (e submitterError) Error() string { return e.message }
+
+// submitterNotionID maps the Slack user to their Notion user, applying
+// SLACK_GUEST_POLICY and HIDDEN_EMAIL_POLICY to users who aren't overridden.
+// If that fails, it returns a submitterError.
+func (h *Handler) submitterNotionID(teamID, userID string, snapshot *notion.CacheSnapshot) (notionUserID string, profile slackUserProfile, err error) {
+	notionUserID, profile, found, err := h.slackUserNotionID(teamID, userID, snapshot)
+	if err != nil {
+		h.logger.Error("failed to fetch Slack user info", zap.String("user_id", userID), zap.Error(err))
+		return "", profile, submitterError{message: h.messages.Format(messages.KeyUserLookupFailed, nil), reason: "user_lookup_error", lookup: true}
+	}
+	if _, overridden := h.config.Load().SlackNotionUserOverrides[userID]; !overridden {
+		if policy, userType := h.submitterPolicy(profile); policy != "" {
+			notionUserID, err = h.applySubmitterPolicy(userID, profile, policy, userType)
+			return notionUserID, profile, err
+		}
+	}
+
+	email := profile.Email
+	switch {
+	case found:
+		return notionUserID, profile, nil
+	case snapshot.IsExternalGuest(email):
+		return "", profile, submitterError{message: h.messages.Format(messages.KeyUserExternalGuest, messages.Params{"email": email}), reason: "external_guest"}
+	}
+	if notionUserID = h.fallbackSubmitterID(userID, email); notionUserID == "" {
+		return "", profile, submitterError{message: h.messages.Format(messages.KeyUserNotFound, messages.Params{"email": email}), reason: "user_not_found"}
+	}
+	return notionUserID, profile, nil
+}
+
+// attachQuickSubmitter maps the command's user to their Notion user and
+// attaches them to the submission. If that fails, it replies with why and
+// returns false.
+func (h *Handler) attachQuickSubmitter(ctx context.Context, w http.ResponseWriter, cmd SlashCommand, snapshot *notion.CacheSnapshot, sub *submission.Submission) bool {
+	notionUserID, profile, err := h.submitterNotionID(cmd.TeamID, cmd.UserID, snapshot)
+	if unmapped, ok := err.(submitterError); ok && unmapped.triage {
+		sub.Source.SubmitterEmail = profile.Email
+		sub.Source.SubmitterName = profile.RealName
+		h.recordSlackCommand(cmd.Command, "triaged")
+		h.triageInBackground(ctx, *sub)
+		respondToSlack(w, h.messages.Format(messages.KeySubmissionTriaged, messages.Params{"title": sub.Title}))
+		return false
+	}
+	if err != nil {
+		h.recordSlackCommand(cmd.Command, "error")
+		h.trackSubmission(analytics.EventSubmissionFailed, commandPayload(cmd), nil, "user_lookup_error")
+		respondToSlack(w, err.Error())
+		return false
+	}
+	sub.SubmitterNotionID = notionUserID
+	sub.Source.SubmitterEmail = profile.Email
+	sub.Source.SubmitterName = profile.RealName
+	return true
+}
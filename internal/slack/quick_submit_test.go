@@ -0,0 +1,174 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+)
+
+func TestParseQuickSubmit(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    quickSubmission
+		wantErr string
+	}{
+		{
+			name: "all fields",
+			text: `"Exports are slow" theme:"Customer Pain Point" area:AI/ML customer:"Acme Corp" customer:Globex -- comments: here`,
+			want: quickSubmission{
+				Title:       "Exports are slow",
+				Theme:       "Customer Pain Point",
+				ProductArea: "AI/ML",
+				Customers:   []string{"Acme Corp", "Globex"},
+				Comments:    "comments: here",
+			},
+		},
+		{
+			name: "curly quotes and unquoted title words",
+			text: `“Faster exports” please category:“Feature Improvement”`,
+			want: quickSubmission{Title: "Faster exports please", Theme: "Feature Improvement"},
+		},
+		{
+			name: "colons in the title",
+			text: `Note: see https://example.com area:UX`,
+			want: quickSubmission{Title: "Note: see https://example.com", ProductArea: "UX"},
+		},
+		{
+			name:    "unclosed quote",
+			text:    `"Exports are slow theme:UX`,
+			wantErr: "quote is not closed",
+		},
+		{
+			name:    "repeated theme",
+			text:    `"Exports" theme:UX theme:AI/ML`,
+			want:    quickSubmission{Title: "Exports", Theme: "UX"},
+			wantErr: "theme: is given twice",
+		},
+		{
+			name:    "empty value",
+			text:    `"Exports" area:""`,
+			wantErr: "area: has no value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseQuickSubmit(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseQuickSubmit() error = %v, want %q", err, tt.wantErr)
+				}
+				if tt.want.Title != "" && got.Title != tt.want.Title {
+					t.Errorf("Title = %q, want %q parsed before the error", got.Title, tt.want.Title)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseQuickSubmit() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseQuickSubmit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func quickSubmitRequest(text string) *http.Request {
+	body := url.Values{
+		"command":      {"/hopperbot"},
+		"text":         {text},
+		"team_id":      {"T456"},
+		"user_id":      {"U123"},
+		"channel_id":   {"C1"},
+		"trigger_id":   {"trigger"},
+		"response_url": {"https://hooks.slack.com/commands/T456/1/abc"},
+	}.Encode()
+	return createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret")
+}
+
+// TestHandleSlashCommand_QuickSubmit tests that a valid quick submission creates the page without the modal
+func TestHandleSlashCommand_QuickSubmit(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(
+		map[string]string{"Acme Corp": "customer-page-acme"},
+		map[string]string{"alice@example.com": "notion-user-alice"},
+	)}
+	slackAPI := &fakeSlack{
+		users:     map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+		posted:    make(chan string, 2),
+		responded: make(chan string, 1),
+		opened:    make(chan slack.ModalViewRequest, 1),
+	}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+
+	w := httptest.NewRecorder()
+	handler.HandleSlashCommand(w, quickSubmitRequest(`"Exports are slow" theme:"customer pain point" area:ai/ml customer:"acme corp" -- From the QBR`))
+	if !strings.Contains(w.Body.String(), "Submitting *Exports are slow*") {
+		t.Fatalf("response = %s, want the submission acknowledged", w.Body.String())
+	}
+
+	select {
+	case <-slackAPI.responded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("outcome was not posted to the response_url")
+	}
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if len(backend.submissions) != 1 {
+		t.Fatalf("expected 1 submission, got %d", len(backend.submissions))
+	}
+	sub := backend.submissions[0]
+	if sub.Theme != "Customer Pain Point" || sub.ProductArea != "AI/ML" || sub.Comments != "From the QBR" {
+		t.Errorf("submission = %+v, want options matched case-insensitively", sub)
+	}
+	if len(sub.CustomerOrgIDs) != 1 || sub.CustomerOrgIDs[0] != "customer-page-acme" || sub.SubmitterNotionID != "notion-user-alice" {
+		t.Errorf("submission = %+v", sub)
+	}
+	if sub.Source.Channel != submission.ChannelSlackCommand {
+		t.Errorf("Source.Channel = %q, want %q", sub.Source.Channel, submission.ChannelSlackCommand)
+	}
+	if len(slackAPI.opened) != 0 {
+		t.Error("a valid quick submission opened the modal")
+	}
+}
+
+// TestHandleSlashCommand_QuickSubmitInvalid tests that validation errors open the modal pre-filled
+func TestHandleSlashCommand_QuickSubmitInvalid(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}
+	slackAPI := &fakeSlack{opened: make(chan slack.ModalViewRequest, 1)}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+
+	w := httptest.NewRecorder()
+	handler.HandleSlashCommand(w, quickSubmitRequest(`"Exports are slow" theme:"Feature Improvement"`))
+
+	var response struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if !strings.Contains(response.Text, "wasn't submitted") || !strings.Contains(response.Text, "Product area") || !strings.Contains(response.Text, "form is open") {
+		t.Errorf("response = %q, want the missing product area reported", response.Text)
+	}
+
+	select {
+	case modal := <-slackAPI.opened:
+		data, _ := json.Marshal(modal)
+		if !strings.Contains(string(data), "Exports are slow") || !strings.Contains(string(data), `"initial_option":{"text":{"type":"plain_text","text":"Feature Improvement"`) {
+			t.Errorf("modal is not pre-filled: %s", data)
+		}
+	default:
+		t.Fatal("modal was not opened")
+	}
+	if len(backend.submissions) != 0 {
+		t.Error("an invalid quick submission was submitted")
+	}
+}
@@ -0,0 +1,203 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// Reasons a new idea is rejected by the submission limits, as counted in
+// hopperbot_submissions_limited_total.
+const (
+	limitReasonCooldown = "cooldown"
+	limitReasonQuota    = "quota"
+)
+
+// quotaSweepInterval is how often users with no submission in the quota
+// window are dropped.
+const quotaSweepInterval = time.Hour
+
+// submissionQuota remembers when each user's new ideas were accepted over the
+// last constants.SubmissionQuotaWindow, to enforce SUBMISSION_QUOTA_PER_DAY
+// and SUBMISSION_COOLDOWN. It is kept in memory, so limits start over on
+// restart. It is safe for concurrent use.
+type submissionQuota struct {
+	mu        sync.Mutex
+	accepted  map[string][]time.Time // Team ID + user ID -> acceptance times, oldest first
+	lastSweep time.Time
+}
+
+func newSubmissionQuota() *submissionQuota {
+	return &submissionQuota{accepted: make(map[string][]time.Time)}
+}
+
+// quotaKey identifies a user across workspaces.
+func quotaKey(teamID, userID string) string {
+	return teamID + ":" + userID
+}
+
+// reserve counts a new idea accepted at the given time unless it breaks a
+// limit, in which case it returns the limit's reason and when the user may
+// submit again. Limits of 0 are disabled.
+func (q *submissionQuota) reserve(key string, at time.Time, perDay int, cooldown time.Duration) (string, time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.sweep(at)
+	times := q.recent(key, at)
+	if cooldown > 0 && len(times) > 0 {
+		if next := times[len(times)-1].Add(cooldown); at.Before(next) {
+			return limitReasonCooldown, next
+		}
+	}
+	if perDay > 0 && len(times) >= perDay {
+		return limitReasonQuota, times[len(times)-perDay].Add(constants.SubmissionQuotaWindow)
+	}
+	q.accepted[key] = append(times, at)
+	return "", time.Time{}
+}
+
+// add counts a new idea accepted at the given time regardless of the limits,
+// for admins who are exempt from them.
+func (q *submissionQuota) add(key string, at time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.accepted[key] = append(q.recent(key, at), at)
+}
+
+// release stops counting the new idea accepted at the given time, once
+// creating it failed, so retrying isn't held back by the cooldown.
+func (q *submissionQuota) release(key string, at time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	times := q.accepted[key]
+	for i := len(times) - 1; i >= 0; i-- {
+		if times[i].Equal(at) {
+			q.accepted[key] = append(times[:i:i], times[i+1:]...)
+			return
+		}
+	}
+}
+
+// reset forgets the user's recent new ideas and returns how many were counted.
+func (q *submissionQuota) reset(key string, now time.Time) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	count := len(q.recent(key, now))
+	delete(q.accepted, key)
+	return count
+}
+
+// recent returns the key's acceptance times within the quota window, dropping
+// older ones. Caller must hold q.mu.
+func (q *submissionQuota) recent(key string, now time.Time) []time.Time {
+	times := q.accepted[key]
+	cutoff := now.Add(-constants.SubmissionQuotaWindow)
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	if i == len(times) {
+		delete(q.accepted, key)
+		return nil
+	}
+	return times[i:]
+}
+
+// sweep drops users with no acceptance in the quota window at most once per
+// quotaSweepInterval, so memory only grows with recently active users.
+// Caller must hold q.mu.
+func (q *submissionQuota) sweep(now time.Time) {
+	if now.Sub(q.lastSweep) < quotaSweepInterval {
+		return
+	}
+	q.lastSweep = now
+	for key := range q.accepted {
+		q.recent(key, now)
+	}
+}
+
+// limitMessage checks a new idea against the submitter's SUBMISSION_COOLDOWN
+// and SUBMISSION_QUOTA_PER_DAY, counting it when it is let through, and
+// returns "" or the message rejecting it. Admins (see isAdmin) are exempt;
+// their ideas are still counted. An idea that is let through but then fails
+// to be created must be handed to releaseSubmission.
+func (h *Handler) limitMessage(ctx context.Context, sub submission.Submission) string {
+	cfg := h.config.Load()
+	if cfg.SubmissionQuotaPerDay <= 0 && cfg.SubmissionCooldown <= 0 {
+		return ""
+	}
+
+	teamID, userID := sub.Source.SlackTeamID, sub.Source.SlackUserID
+	key := quotaKey(teamID, userID)
+	reason, next := h.quota.reserve(key, sub.Source.SubmittedAt, cfg.SubmissionQuotaPerDay, cfg.SubmissionCooldown)
+	if reason == "" {
+		return ""
+	}
+	if h.isAdmin(ctx, teamID, userID) {
+		h.quota.add(key, sub.Source.SubmittedAt)
+		h.recordSubmissionLimitOverride("exempt")
+		return ""
+	}
+
+	h.logger.Info("submission rejected by the submission limits",
+		zap.String("reason", reason),
+		zap.String("user_id", userID),
+		zap.String("team_id", teamID),
+		zap.Time("next", next),
+	)
+	h.recordSubmissionLimited(reason)
+	if reason == limitReasonCooldown {
+		seconds := int(next.Sub(sub.Source.SubmittedAt).Round(time.Second) / time.Second)
+		return h.messages.Format(messages.KeySubmissionCooldown, messages.Params{"seconds": max(seconds, 1)})
+	}
+	return h.messages.Format(messages.KeySubmissionQuotaReached, messages.Params{
+		"limit": cfg.SubmissionQuotaPerDay,
+		"next":  h.FormatTimeForUser(userID, next),
+	})
+}
+
+// releaseSubmission stops counting a new idea limitMessage let through, once
+// creating it failed.
+func (h *Handler) releaseSubmission(sub submission.Submission) {
+	h.quota.release(quotaKey(sub.Source.SlackTeamID, sub.Source.SlackUserID), sub.Source.SubmittedAt)
+}
+
+// slackUserMention matches a user as Slack escapes it in slash command text,
+// e.g. <@U012AB3CD|jane>, or a bare user ID.
+var slackUserMention = regexp.MustCompile(`^(?:<@([UW][A-Z0-9]+)(?:\|[^>]*)?>|([UW][A-Z0-9]+))$`)
+
+// handleResetQuotaCommand handles /hopperbot reset-quota <@user>, letting the
+// user submit new ideas again right away.
+func (h *Handler) handleResetQuotaCommand(w http.ResponseWriter, cmd SlashCommand) {
+	match := slackUserMention.FindStringSubmatch(cmd.Args[0])
+	if match == nil {
+		h.recordSlackCommand(cmd.Command, "error")
+		respondToSlack(w, h.messages.Format(messages.KeyQuotaResetInvalidUser, messages.Params{"value": cmd.Args[0]}))
+		return
+	}
+	userID := match[1] + match[2]
+
+	count := h.quota.reset(quotaKey(cmd.TeamID, userID), h.clock.Now())
+	h.logger.Info("submission quota reset",
+		zap.String("user_id", userID),
+		zap.String("team_id", cmd.TeamID),
+		zap.String("reset_by", cmd.UserID),
+		zap.Int("cleared", count),
+	)
+	h.recordSubmissionLimitOverride("reset")
+	h.recordSlackCommand(cmd.Command, "success")
+	respondToSlack(w, h.messages.Format(messages.KeyQuotaReset, messages.Params{
+		"user":  userID,
+		"count": count,
+	}))
+}
@@ -0,0 +1,150 @@
+package slack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+func TestSubmissionQuota(t *testing.T) {
+	quota := newSubmissionQuota()
+	start := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	reserve := func(at time.Time) (string, time.Time) {
+		return quota.reserve("T1:U1", at, 2, 30*time.Second)
+	}
+
+	if reason, _ := reserve(start); reason != "" {
+		t.Fatalf("first submission rejected: %s", reason)
+	}
+	if reason, next := reserve(start.Add(10 * time.Second)); reason != limitReasonCooldown || !next.Equal(start.Add(30*time.Second)) {
+		t.Errorf("reserve() within the cooldown = %s, %v, want cooldown until 30s", reason, next)
+	}
+	if reason, _ := reserve(start.Add(time.Minute)); reason != "" {
+		t.Fatalf("second submission rejected: %s", reason)
+	}
+	if reason, next := reserve(start.Add(2 * time.Minute)); reason != limitReasonQuota || !next.Equal(start.Add(24*time.Hour)) {
+		t.Errorf("reserve() over the quota = %s, %v, want quota until the first submission expires", reason, next)
+	}
+
+	// A released submission no longer counts
+	quota.release("T1:U1", start.Add(time.Minute))
+	if reason, _ := reserve(start.Add(2 * time.Minute)); reason != "" {
+		t.Errorf("submission after a release rejected: %s", reason)
+	}
+
+	// Submissions leave the quota a day after they were made
+	if reason, _ := reserve(start.Add(24*time.Hour + time.Second)); reason != "" {
+		t.Errorf("submission a day later rejected: %s", reason)
+	}
+
+	if count := quota.reset("T1:U1", start.Add(24*time.Hour+time.Minute)); count != 2 {
+		t.Errorf("reset() = %d, want 2 recent submissions cleared", count)
+	}
+	if reason, _ := reserve(start.Add(24*time.Hour + time.Minute)); reason != "" {
+		t.Errorf("submission after a reset rejected: %s", reason)
+	}
+
+	// Idle users are swept
+	quota.reserve("T1:U2", start, 2, 0)
+	quota.reserve("T1:U1", start.Add(72*time.Hour), 2, 0)
+	if _, ok := quota.accepted["T1:U2"]; ok {
+		t.Error("idle user not swept")
+	}
+}
+
+// TestSubmissionLimits tests that quick submissions over the cooldown and the
+// daily quota are rejected, that admins are exempt, and that an admin can
+// reset a user's quota
+func TestSubmissionLimits(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(
+		map[string]string{"Acme Corp": "customer-page-acme"},
+		map[string]string{"alice@example.com": "notion-user-alice", "admin@example.com": "notion-user-admin"},
+	)}
+	slackAPI := &fakeSlack{
+		users: map[string]*slack.User{
+			"U123":    {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}},
+			"U-admin": {ID: "U-admin", Profile: slack.UserProfile{Email: "admin@example.com"}},
+		},
+		posted:    make(chan string, 20),
+		responded: make(chan string, 20),
+	}
+	clock := &mutableClock{now: time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)}
+	handler := NewHandlerWithDependencies(&config.Config{
+		SlackSigningSecret:    "secret",
+		SlackAdminUserIDs:     []string{"U-admin"},
+		SubmissionQuotaPerDay: 2,
+		SubmissionCooldown:    30 * time.Second,
+	}, zap.NewNop(), Dependencies{Backend: backend, Slack: slackAPI, Responder: slackAPI, Clock: clock})
+
+	run := func(userID, text string) string {
+		body := url.Values{
+			"command":      {"/hopperbot"},
+			"text":         {text},
+			"team_id":      {"T456"},
+			"user_id":      {userID},
+			"response_url": {"https://hooks.slack.com/commands/T456/1/abc"},
+		}.Encode()
+		w := httptest.NewRecorder()
+		handler.HandleSlashCommand(w, createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+		if strings.Contains(w.Body.String(), "Submitting") {
+			<-slackAPI.responded // Wait for the page so the clock isn't moved under it
+		}
+		return w.Body.String()
+	}
+	submit := func(userID, title string) string {
+		return run(userID, `"`+title+`" theme:"customer pain point" area:ai/ml customer:"acme corp"`)
+	}
+
+	if reply := submit("U123", "Exports are slow"); !strings.Contains(reply, "Submitting") {
+		t.Fatalf("first submission = %s, want it accepted", reply)
+	}
+	clock.now = clock.now.Add(10 * time.Second)
+	if reply := submit("U123", "Imports are slow"); !strings.Contains(reply, "Please wait 20 seconds") {
+		t.Errorf("submission within the cooldown = %s, want the wait", reply)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if reply := submit("U123", "Imports are slow"); !strings.Contains(reply, "Submitting") {
+		t.Fatalf("second submission = %s, want it accepted", reply)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	if reply := submit("U123", "Search is slow"); !strings.Contains(reply, "You've submitted 2 ideas in the last 24 hours") {
+		t.Errorf("submission over the quota = %s, want the quota message", reply)
+	}
+
+	// Admins are exempt
+	for _, title := range []string{"Admin idea 1", "Admin idea 2", "Admin idea 3"} {
+		if reply := submit("U-admin", title); !strings.Contains(reply, "Submitting") {
+			t.Errorf("admin submission %q = %s, want it accepted", title, reply)
+		}
+	}
+
+	// Only admins can reset a quota, and only of a Slack user
+	if reply := run("U123", "reset-quota <@U123|alice>"); !strings.Contains(reply, "limited to Hopperbot admins") {
+		t.Errorf("reset by a user = %s, want it denied", reply)
+	}
+	if reply := run("U-admin", "reset-quota alice"); !strings.Contains(reply, "isn't a Slack user") {
+		t.Errorf("reset of a name = %s, want it rejected", reply)
+	}
+	if reply := run("U-admin", "reset-quota <@U123|alice>"); !strings.Contains(reply, "Cleared 2 recent submissions from") {
+		t.Errorf("reset = %s, want 2 submissions cleared", reply)
+	}
+	if reply := submit("U123", "Search is slow"); !strings.Contains(reply, "Submitting") {
+		t.Errorf("submission after the reset = %s, want it accepted", reply)
+	}
+
+	backend.mu.Lock()
+	submitted := len(backend.submissions)
+	backend.mu.Unlock()
+	if submitted != 6 {
+		t.Errorf("backend submissions = %d, want the limited ones not submitted", submitted)
+	}
+}
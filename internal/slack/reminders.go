@@ -0,0 +1,132 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/reminders"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// SetReminderScheduler enables per-idea follow-up reminders.
+// The "Remind me" field is only shown in the modal once a scheduler is set.
+func (h *Handler) SetReminderScheduler(scheduler *reminders.Scheduler) {
+	h.reminders = scheduler
+}
+
+// extractReminderDelay returns the reminder delay chosen in the modal, or 0 if none.
+func (h *Handler) extractReminderDelay(state ViewState) (time.Duration, error) {
+	value, err := state.GetSelectedOption(BlockIDRemindMe, ActionIDRemindMeSelect)
+	if err != nil || value == "" {
+		// Block absent (reminders disabled or older modal) or left empty
+		return 0, nil
+	}
+
+	delay, ok := reminders.ParseOption(value)
+	if !ok {
+		h.recordValidationError("remind_me")
+		return 0, fieldValidationError{
+			errors: map[string]string{
+				BlockIDRemindMe: h.messages.Format(messages.KeyInvalidSelection, messages.Params{"field": "reminder", "value": value}),
+			},
+		}
+	}
+
+	return delay, nil
+}
+
+// scheduleReminder stores a follow-up reminder for a newly created idea.
+// Failures are logged but never fail the submission, which has already succeeded.
+func (h *Handler) scheduleReminder(slackTeamID, slackUserID, title string, page *notion.CreatedPage, delay time.Duration) {
+	if h.reminders == nil || delay <= 0 {
+		return
+	}
+
+	createdAt := page.CreatedTime
+	if createdAt.IsZero() {
+		createdAt = h.clock.Now().UTC()
+	}
+
+	reminder := reminders.Reminder{
+		ID:          page.ID,
+		PageID:      page.ID,
+		PageURL:     page.URL,
+		Title:       title,
+		SlackTeamID: slackTeamID,
+		SlackUserID: slackUserID,
+		CreatedAt:   createdAt,
+		DueAt:       createdAt.Add(delay),
+	}
+	if err := h.reminders.Schedule(reminder); err != nil {
+		h.logger.Error("failed to schedule follow-up reminder",
+			zap.String("page_id", page.ID),
+			zap.String("slack_user_id", slackUserID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	h.logger.Info("follow-up reminder scheduled",
+		zap.String("page_id", page.ID),
+		zap.String("slack_user_id", slackUserID),
+		zap.Time("due_at", reminder.DueAt),
+	)
+}
+
+// SendReminder is the reminders.Notifier for the scheduler. It looks up the
+// idea's current status in Notion and DMs the submitter.
+//
+// Notion and Slack errors are returned so the scheduler retries; a page that no
+// longer exists is reported to the user as removed rather than retried.
+func (h *Handler) SendReminder(ctx context.Context, reminder reminders.Reminder) error {
+	status, err := h.backendFor(reminder.SlackTeamID).GetPageStatus(reminder.PageID)
+	var apiErr *notion.NotionAPIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		status, err = &notion.PageStatus{Archived: true}, nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch idea status: %w", err)
+	}
+
+	text := h.reminderText(reminder, status)
+	if _, _, err := h.slackFor(reminder.SlackTeamID).PostMessageContext(ctx, reminder.SlackUserID, slack.MsgOptionText(text, false)); err != nil {
+		h.recordSlackAPIError("chat.postMessage", err)
+		return fmt.Errorf("failed to send reminder DM: %w", err)
+	}
+
+	return nil
+}
+
+// reminderText renders the reminder DM for the idea's current status.
+func (h *Handler) reminderText(reminder reminders.Reminder, status *notion.PageStatus) string {
+	title := status.Title
+	if title == "" {
+		title = reminder.Title
+	}
+	submitted := h.FormatTimeForUser(reminder.SlackUserID, reminder.CreatedAt)
+
+	if status.Archived {
+		return h.messages.Format(messages.KeyReminderRemoved, messages.Params{
+			"title": title, "submitted": submitted,
+		})
+	}
+
+	url := status.URL
+	if url == "" {
+		url = reminder.PageURL
+	}
+	state := status.Status
+	if state == "" {
+		state = h.messages.Format(messages.KeyReminderNoStatus, nil)
+	}
+
+	return h.messages.Format(messages.KeyReminder, messages.Params{
+		"title": title, "submitted": submitted, "status": state, "url": url,
+	})
+}
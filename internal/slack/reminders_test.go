@@ -0,0 +1,128 @@
+package slack
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/reminders"
+	"go.uber.org/zap"
+)
+
+func newReminderTestHandler() *Handler {
+	handler := NewHandler(&config.Config{SlackBotToken: "test-token"}, zap.NewNop())
+	handler.timezones = NewTimezoneCache(nil, time.Hour, zap.NewNop())
+	return handler
+}
+
+// TestExtractReminderDelay tests reading the optional "Remind me" selection
+func TestExtractReminderDelay(t *testing.T) {
+	handler := newReminderTestHandler()
+
+	selected := func(value string) ViewState {
+		return ViewState{Values: map[string]map[string]StateValue{
+			BlockIDRemindMe: {ActionIDRemindMeSelect: {SelectedOption: &SelectedOption{Value: value}}},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		state   ViewState
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "block absent", state: ViewState{Values: map[string]map[string]StateValue{}}, want: 0},
+		{name: "no selection", state: ViewState{Values: map[string]map[string]StateValue{
+			BlockIDRemindMe: {ActionIDRemindMeSelect: {}},
+		}}, want: 0},
+		{name: "two weeks", state: selected("2w"), want: 14 * 24 * time.Hour},
+		{name: "unknown option", state: selected("1y"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := handler.extractReminderDelay(tt.state)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, ok := err.(fieldValidationError).errors[BlockIDRemindMe]; !ok {
+					t.Errorf("expected error on %s, got %v", BlockIDRemindMe, err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("delay = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestReminderText tests the reminder DM for each idea state
+func TestReminderText(t *testing.T) {
+	handler := newReminderTestHandler()
+	reminder := reminders.Reminder{
+		PageID:      "page-1",
+		PageURL:     "https://www.notion.so/page-1",
+		Title:       "Original title",
+		SlackUserID: "U123",
+		CreatedAt:   time.Date(2025, 11, 5, 17, 30, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name     string
+		status   *notion.PageStatus
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "triaged",
+			status:   &notion.PageStatus{Title: "Renamed title", Status: "Planned", URL: "https://www.notion.so/renamed"},
+			contains: []string{"Renamed title", "*Planned*", "https://www.notion.so/renamed", "Nov 5, 2025"},
+		},
+		{
+			name:     "no status yet",
+			status:   &notion.PageStatus{},
+			contains: []string{"Original title", "not triaged yet", "https://www.notion.so/page-1"},
+		},
+		{
+			name:     "removed",
+			status:   &notion.PageStatus{Archived: true},
+			contains: []string{"Original title", "removed from Notion"},
+			excludes: []string{"https://"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := handler.reminderText(reminder, tt.status)
+			for _, want := range tt.contains {
+				if !strings.Contains(text, want) {
+					t.Errorf("text %q should contain %q", text, want)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(text, unwanted) {
+					t.Errorf("text %q should not contain %q", text, unwanted)
+				}
+			}
+		})
+	}
+}
+
+// TestBuildRemindMeBlock tests the optional reminder select
+func TestBuildRemindMeBlock(t *testing.T) {
+	block := buildRemindMeBlock()
+
+	if block.BlockID != BlockIDRemindMe {
+		t.Errorf("block ID = %s, want %s", block.BlockID, BlockIDRemindMe)
+	}
+	if !block.Optional {
+		t.Error("reminder block should be optional")
+	}
+	if len(reminders.Options) != 3 {
+		t.Errorf("expected 3 reminder options (1w/2w/1m), got %d", len(reminders.Options))
+	}
+}
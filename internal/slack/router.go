@@ -0,0 +1,207 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// AnyArgs is Subcommand.MaxArgs for subcommands taking any number of arguments.
+const AnyArgs = -1
+
+// maxSuggestionDistance is how many edits away a typo can be from a
+// subcommand name for it to be suggested.
+const maxSuggestionDistance = 2
+
+// SlashCommand is a parsed /hopperbot invocation.
+type SlashCommand struct {
+	Command     string   // The slash command, e.g. /hopperbot
+	Name        string   // Subcommand as typed, lowercased; empty for plain /hopperbot
+	Args        []string // Whitespace-separated arguments after the subcommand
+	ArgText     string   // Arguments as typed, for free text such as customer names
+	TeamID      string
+	UserID      string
+	ChannelID   string
+	TriggerID   string
+	ResponseURL string
+}
+
+// parseSlashCommand parses the form values Slack posts for a slash command.
+// Text starting with a quote (a quick-submit title) goes to the default
+// subcommand as its arguments.
+func parseSlashCommand(values url.Values) SlashCommand {
+	name, argText, _ := strings.Cut(strings.TrimSpace(values.Get("text")), " ")
+	if strings.HasPrefix(name, `"`) || strings.HasPrefix(name, "\u201c") {
+		name, argText = "", strings.TrimSpace(values.Get("text"))
+	}
+	argText = strings.TrimSpace(argText)
+	return SlashCommand{
+		Command:     values.Get("command"),
+		Name:        strings.ToLower(name),
+		Args:        strings.Fields(argText),
+		ArgText:     argText,
+		TeamID:      values.Get("team_id"),
+		UserID:      values.Get("user_id"),
+		ChannelID:   values.Get("channel_id"),
+		TriggerID:   values.Get("trigger_id"),
+		ResponseURL: values.Get("response_url"),
+	}
+}
+
+// CommandHandler handles a routed subcommand. Like any slash command response,
+// it must reply within Slack's 3 second acknowledgement window.
+type CommandHandler func(ctx context.Context, w http.ResponseWriter, cmd SlashCommand)
+
+// CommandMiddleware wraps a subcommand's handler, e.g. to check permissions
+// or record metrics. It is given the subcommand it wraps.
+type CommandMiddleware func(sub Subcommand, next CommandHandler) CommandHandler
+
+// Subcommand is a /hopperbot subcommand registered with a CommandRouter.
+type Subcommand struct {
+	Name        string              // First word of the command text
+	Aliases     []string            // Other names routed to this subcommand
+	Usage       string              // Arguments shown in help and usage replies, e.g. "<customer name>"
+	Description string              // One line shown in help
+	MinArgs     int                 // Fewer arguments get the usage reply instead of Handler
+	MaxArgs     int                 // More arguments get the usage reply; AnyArgs for no limit
+	Admin       bool                // Limited to admins (enforced by middleware, see authorizeAdmin)
+	Middleware  []CommandMiddleware // Wraps Handler, inside the router-wide middleware
+	Handler     CommandHandler
+}
+
+// acceptsArgs reports whether n arguments are within the subcommand's limits.
+func (s Subcommand) acceptsArgs(n int) bool {
+	return n >= s.MinArgs && (s.MaxArgs == AnyArgs || n <= s.MaxArgs)
+}
+
+// CommandRouter dispatches slash commands to registered subcommands.
+//
+// Plain /hopperbot runs the default subcommand. Unknown subcommands are
+// answered by NotFound with the closest subcommand name, if any, and argument
+// counts outside a subcommand's limits by BadUsage.
+type CommandRouter struct {
+	// NotFound replies to unknown subcommands; suggestion is "" without a close match.
+	NotFound func(ctx context.Context, w http.ResponseWriter, cmd SlashCommand, suggestion string)
+	// BadUsage replies when a subcommand gets too few or too many arguments.
+	BadUsage func(ctx context.Context, w http.ResponseWriter, cmd SlashCommand, sub Subcommand)
+
+	defaultName string
+	subcommands []Subcommand
+	names       map[string]int // Names and aliases -> index in subcommands
+	middleware  []CommandMiddleware
+}
+
+// NewCommandRouter creates a router that runs defaultName for plain /hopperbot.
+func NewCommandRouter(defaultName string) *CommandRouter {
+	return &CommandRouter{
+		defaultName: defaultName,
+		names:       make(map[string]int),
+	}
+}
+
+// Register adds a subcommand. Like http.ServeMux, it panics on a duplicate
+// name or alias, which is a programming error.
+func (r *CommandRouter) Register(sub Subcommand) {
+	if sub.Handler == nil {
+		panic(fmt.Sprintf("slack: subcommand %q has no handler", sub.Name))
+	}
+	for _, name := range append([]string{sub.Name}, sub.Aliases...) {
+		if _, exists := r.names[name]; exists {
+			panic(fmt.Sprintf("slack: subcommand %q registered twice", name))
+		}
+		r.names[name] = len(r.subcommands)
+	}
+	r.subcommands = append(r.subcommands, sub)
+}
+
+// Use adds middleware run around every subcommand, outermost first.
+func (r *CommandRouter) Use(middleware ...CommandMiddleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Subcommands returns the registered subcommands in registration order.
+func (r *CommandRouter) Subcommands() []Subcommand {
+	return r.subcommands
+}
+
+// Lookup returns the subcommand registered under name or an alias.
+// The empty name is the default subcommand.
+func (r *CommandRouter) Lookup(name string) (Subcommand, bool) {
+	if name == "" {
+		name = r.defaultName
+	}
+	i, ok := r.names[name]
+	if !ok {
+		return Subcommand{}, false
+	}
+	return r.subcommands[i], true
+}
+
+// Route runs the subcommand cmd names, wrapped in its middleware.
+func (r *CommandRouter) Route(ctx context.Context, w http.ResponseWriter, cmd SlashCommand) {
+	sub, ok := r.Lookup(cmd.Name)
+	if !ok {
+		r.NotFound(ctx, w, cmd, r.Suggest(cmd.Name))
+		return
+	}
+
+	handler := sub.Handler
+	if !sub.acceptsArgs(len(cmd.Args)) {
+		handler = func(ctx context.Context, w http.ResponseWriter, cmd SlashCommand) {
+			r.BadUsage(ctx, w, cmd, sub)
+		}
+	}
+	// Middleware still runs for usage errors, so admin-only usage isn't revealed
+	for i := len(sub.Middleware) - 1; i >= 0; i-- {
+		handler = sub.Middleware[i](sub, handler)
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](sub, handler)
+	}
+	handler(ctx, w, cmd)
+}
+
+// Suggest returns the registered name or alias closest to a mistyped
+// subcommand, or "" if none is close. Prefixes (e.g. "ref") also match.
+func (r *CommandRouter) Suggest(name string) string {
+	if name == "" {
+		return ""
+	}
+	best, bestDistance := "", maxSuggestionDistance+1
+	for _, sub := range r.subcommands {
+		for _, candidate := range append([]string{sub.Name}, sub.Aliases...) {
+			distance := editDistance(name, candidate)
+			if strings.HasPrefix(candidate, name) {
+				distance = 0
+			}
+			if distance < bestDistance {
+				best, bestDistance = candidate, distance
+			}
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(br)]
+}
@@ -0,0 +1,119 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestRouter(calls *[]string) *CommandRouter {
+	record := func(name string) CommandHandler {
+		return func(_ context.Context, _ http.ResponseWriter, cmd SlashCommand) {
+			*calls = append(*calls, name+":"+strings.Join(cmd.Args, ","))
+		}
+	}
+	router := NewCommandRouter("submit")
+	router.NotFound = func(_ context.Context, _ http.ResponseWriter, cmd SlashCommand, suggestion string) {
+		*calls = append(*calls, "not-found:"+suggestion)
+	}
+	router.BadUsage = func(_ context.Context, _ http.ResponseWriter, _ SlashCommand, sub Subcommand) {
+		*calls = append(*calls, "usage:"+sub.Name)
+	}
+	router.Register(Subcommand{Name: "submit", Handler: record("submit")})
+	router.Register(Subcommand{Name: "list", Aliases: []string{"mine"}, Handler: record("list")})
+	router.Register(Subcommand{Name: "customer", MinArgs: 1, MaxArgs: AnyArgs, Handler: record("customer")})
+	router.Register(Subcommand{Name: "refresh-cache", MaxArgs: 1, Handler: record("refresh-cache")})
+	return router
+}
+
+// TestCommandRouter_Route tests dispatch by name, alias and default, and argument limits
+func TestCommandRouter_Route(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", "submit:"},
+		{"  ", "submit:"},
+		{"LIST", "list:"},
+		{"mine", "list:"},
+		{"customer Acme  Corp", "customer:Acme,Corp"},
+		{"customer", "usage:customer"},
+		{"refresh-cache a b", "usage:refresh-cache"},
+		{"refresh", "not-found:refresh-cache"},
+		{"xyz", "not-found:"},
+	}
+	for _, tt := range tests {
+		var calls []string
+		router := newTestRouter(&calls)
+		router.Route(context.Background(), httptest.NewRecorder(), parseSlashCommand(url.Values{"text": {tt.text}}))
+		if len(calls) != 1 || calls[0] != tt.want {
+			t.Errorf("Route(%q) calls = %v, want [%s]", tt.text, calls, tt.want)
+		}
+	}
+}
+
+// TestCommandRouter_Middleware tests that router-wide middleware wraps per-subcommand middleware, including usage errors
+func TestCommandRouter_Middleware(t *testing.T) {
+	var calls []string
+	router := newTestRouter(&calls)
+	trace := func(label string) CommandMiddleware {
+		return func(sub Subcommand, next CommandHandler) CommandHandler {
+			return func(ctx context.Context, w http.ResponseWriter, cmd SlashCommand) {
+				calls = append(calls, label+":"+sub.Name)
+				next(ctx, w, cmd)
+			}
+		}
+	}
+	router.Use(trace("outer"), trace("inner"))
+	router.Register(Subcommand{
+		Name:       "stats",
+		Middleware: []CommandMiddleware{trace("own")},
+		Handler:    func(context.Context, http.ResponseWriter, SlashCommand) { calls = append(calls, "stats") },
+	})
+
+	router.Route(context.Background(), httptest.NewRecorder(), parseSlashCommand(url.Values{"text": {"stats"}}))
+	if want := []string{"outer:stats", "inner:stats", "own:stats", "stats"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+
+	calls = nil
+	router.Route(context.Background(), httptest.NewRecorder(), parseSlashCommand(url.Values{"text": {"customer"}}))
+	if want := []string{"outer:customer", "inner:customer", "usage:customer"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+// TestCommandRouter_RegisterDuplicate tests that registering a name twice panics
+func TestCommandRouter_RegisterDuplicate(t *testing.T) {
+	var calls []string
+	router := newTestRouter(&calls)
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() did not panic on a duplicate alias")
+		}
+	}()
+	router.Register(Subcommand{Name: "mine", Handler: func(context.Context, http.ResponseWriter, SlashCommand) {}})
+}
+
+// TestEditDistance tests the Levenshtein distance used for suggestions
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"help", "help", 0},
+		{"hlep", "help", 2},
+		{"lst", "list", 1},
+		{"", "stats", 5},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// shortcutPermalinkTimeout bounds the chat.getPermalink call made before
+// opening the modal, since the shortcut's trigger_id expires after 3 seconds.
+const shortcutPermalinkTimeout = 1500 * time.Millisecond
+
+// handleMessageShortcut handles the "Submit as idea" message shortcut: it
+// opens the submission modal with the message as the comments, and records
+// the message's permalink in the modal's private_metadata so the created page
+// links back to the discussion (see notion.Client.SetSourceURLProperty).
+func (h *Handler) handleMessageShortcut(w http.ResponseWriter, payload *InteractionPayload) {
+	// Slack only needs the acknowledgement; failures are reported through response_url
+	w.WriteHeader(http.StatusOK)
+
+	teamID := payload.Team.ID
+	var values ModalValues
+	var permalink string
+	if payload.Message != nil {
+		values.Comments = shortenText(payload.Message.Text, h.fieldLimits().commentsLength())
+		permalink = h.messagePermalink(teamID, payload.Channel.ID, payload.Message.TS)
+	}
+
+	modal := h.openingModal(teamID, "", values)
+	setModalPermalink(&modal, permalink)
+	if _, err := h.slackFor(teamID).OpenView(payload.TriggerID, modal); err != nil {
+		h.recordSlackAPIError("views.open", err)
+		h.recordSlackInteraction(payload.Type, constants.ShortcutSubmitMessage, "error")
+		h.logger.Error("failed to open modal from message shortcut",
+			zap.String("user", payload.User.ID),
+			zap.Error(err),
+		)
+		if payload.ResponseURL != "" {
+			if err := h.respondLater(payload.ResponseURL, h.messages.Format(messages.KeyOpenModalFailed, nil)); err != nil {
+				h.logger.Error("failed to report modal failure", zap.Error(err))
+			}
+		}
+		return
+	}
+	h.recordSlackInteraction(payload.Type, constants.ShortcutSubmitMessage, "success")
+}
+
+// messagePermalink returns the permalink of a message (including its thread
+// for replies), or "" if it can't be resolved.
+func (h *Handler) messagePermalink(teamID, channelID, ts string) string {
+	if channelID == "" || ts == "" {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shortcutPermalinkTimeout)
+	defer cancel()
+
+	permalink, err := h.slackFor(teamID).GetPermalinkContext(ctx, &slack.PermalinkParameters{Channel: channelID, Ts: ts})
+	if err != nil {
+		h.recordSlackAPIError("chat.getPermalink", err)
+		h.logger.Warn("failed to get message permalink, submitting without it",
+			zap.String("channel", channelID),
+			zap.Error(err),
+		)
+		return ""
+	}
+	return permalink
+}
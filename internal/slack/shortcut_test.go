@@ -0,0 +1,101 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/slack-go/slack"
+)
+
+// TestMessageShortcut tests that the shortcut opens the modal with the message, and that the submission links back to it
+func TestMessageShortcut(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(nil, map[string]string{"alice@example.com": "notion-user-alice"})}
+	slackAPI := &fakeSlack{
+		users:  map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+		posted: make(chan string, 1),
+		opened: make(chan slack.ModalViewRequest, 1),
+	}
+	handler := newInteractiveTestHandler(backend, slackAPI)
+
+	payload, err := json.Marshal(InteractionPayload{
+		Type:       InteractionTypeMessageAction,
+		CallbackID: constants.ShortcutSubmitMessage,
+		TriggerID:  "trigger-1",
+		User:       User{ID: "U123", Username: "alice"},
+		Team:       Team{ID: "T456"},
+		Channel:    Channel{ID: "C789", Name: "customer-feedback"},
+		Message:    &Message{Type: "message", User: "U999", Text: "Acme keeps asking for CSV exports", TS: "1700000000.000200"},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, createValidSlackRequest(http.MethodPost, "/slack/interactive", []byte(url.Values{"payload": {string(payload)}}.Encode()), "secret"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	modal := <-slackAPI.opened
+	metadata := decodeModalMetadata(modal.PrivateMetadata)
+	if metadata.Permalink != "https://acme.slack.com/archives/C789/p1700000000000200" {
+		t.Errorf("permalink = %q, want the message's", metadata.Permalink)
+	}
+	if metadata.Values == nil || metadata.Values.Comments != "Acme keeps asking for CSV exports" {
+		t.Errorf("values = %+v, want the message as comments", metadata.Values)
+	}
+
+	title := "CSV exports"
+	w = httptest.NewRecorder()
+	handler.HandleInteractive(w, viewSubmissionRequest(t, View{
+		CallbackID:      ModalCallbackIDSubmitForm,
+		PrivateMetadata: modal.PrivateMetadata,
+		State: ViewState{Values: map[string]map[string]StateValue{
+			BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+			BlockIDTheme:       {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "Feature Improvement"}}},
+			BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+		}},
+	}))
+	if len(backend.submissions) != 1 {
+		t.Fatalf("expected 1 submission, got %d (response %s)", len(backend.submissions), w.Body.String())
+	}
+	if got := backend.submissions[0].Source.SlackPermalink; got != metadata.Permalink {
+		t.Errorf("SlackPermalink = %q, want %q", got, metadata.Permalink)
+	}
+}
+
+// TestSetModalPermalink_MultiStep tests that the permalink is carried to the details step
+func TestSetModalPermalink_MultiStep(t *testing.T) {
+	handler := newInteractiveTestHandler(&fakeBackend{}, &fakeSlack{})
+	handler.SetMultiStepModal(true)
+
+	stepOne := handler.openingModal("T456", "", ModalValues{})
+	setModalPermalink(&stepOne, "https://acme.slack.com/archives/C789/p1")
+
+	title := "CSV exports"
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, viewSubmissionRequest(t, View{
+		CallbackID:      ModalCallbackIDSubmitStepOne,
+		PrivateMetadata: stepOne.PrivateMetadata,
+		State: ViewState{Values: map[string]map[string]StateValue{
+			BlockIDTitle: {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+			BlockIDTheme: {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "Feature Improvement"}}},
+		}},
+	}))
+
+	var pushed struct {
+		View struct {
+			PrivateMetadata string `json:"private_metadata"`
+		} `json:"view"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &pushed); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", w.Body.String(), err)
+	}
+	if got := decodeModalMetadata(pushed.View.PrivateMetadata).Permalink; got != "https://acme.slack.com/archives/C789/p1" {
+		t.Errorf("details step permalink = %q, want the first step's", got)
+	}
+}
@@ -0,0 +1,188 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// slackUserProfile is what the handler needs from a Slack user's profile to
+// map them to a Notion user.
+type slackUserProfile struct {
+	Email    string // Empty when the profile hides it
+	RealName string
+	Guest    bool // Single- or multi-channel guest
+}
+
+// cachedSlackUser is a cached profile with the time it was fetched.
+type cachedSlackUser struct {
+	profile   slackUserProfile
+	fetchedAt time.Time
+}
+
+// slackUserCache caches Slack users' profiles per workspace, so submissions,
+// edits and App Home views don't each call users.info. Entries expire after
+// ttl; refresh reloads every workspace with cached users through users.list.
+// It is safe for concurrent use.
+type slackUserCache struct {
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[string]map[string]cachedSlackUser // Team ID -> user ID -> profile
+}
+
+func newSlackUserCache(ttl time.Duration) *slackUserCache {
+	return &slackUserCache{ttl: ttl, entries: make(map[string]map[string]cachedSlackUser)}
+}
+
+// get returns the user's cached profile unless it's missing or expired.
+func (c *slackUserCache) get(teamID, userID string, now time.Time) (slackUserProfile, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, found := c.entries[teamID][userID]
+	if !found || now.Sub(entry.fetchedAt) >= c.ttl {
+		return slackUserProfile{}, false
+	}
+	return entry.profile, true
+}
+
+// remember caches a profile fetched with users.info.
+func (c *slackUserCache) remember(teamID string, user *slack.User, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries[teamID] == nil {
+		c.entries[teamID] = make(map[string]cachedSlackUser)
+	}
+	c.entries[teamID][user.ID] = cachedSlackUser{profile: profileOf(user), fetchedAt: now}
+}
+
+// replace swaps a workspace's profiles for those listed by users.list.
+// Deleted users and bots are left out, so they are looked up (and rejected)
+// again.
+func (c *slackUserCache) replace(teamID string, users []slack.User, now time.Time) {
+	entries := make(map[string]cachedSlackUser, len(users))
+	for i := range users {
+		if users[i].Deleted || users[i].IsBot || users[i].Profile.Email == "" {
+			continue
+		}
+		entries[users[i].ID] = cachedSlackUser{profile: profileOf(&users[i]), fetchedAt: now}
+	}
+
+	c.mu.Lock()
+	c.entries[teamID] = entries
+	c.mu.Unlock()
+}
+
+// teams returns the workspaces with cached users.
+func (c *slackUserCache) teams() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	teams := make([]string, 0, len(c.entries))
+	for teamID := range c.entries {
+		teams = append(teams, teamID)
+	}
+	return teams
+}
+
+// Len returns the number of cached users across workspaces, expired entries included.
+func (c *slackUserCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	count := 0
+	for _, users := range c.entries {
+		count += len(users)
+	}
+	return count
+}
+
+func profileOf(user *slack.User) slackUserProfile {
+	return slackUserProfile{Email: user.Profile.Email, RealName: user.RealName, Guest: user.IsRestricted || user.IsUltraRestricted}
+}
+
+// slackUser returns a Slack user's profile from the Slack user cache, calling
+// users.info (and caching the result) on a miss. Failed lookups aren't cached.
+func (h *Handler) slackUser(teamID, userID string) (slackUserProfile, error) {
+	now := h.clock.Now()
+	if profile, found := h.slackUsers.get(teamID, userID, now); found {
+		h.recordSlackUserCacheLookup("hit")
+		return profile, nil
+	}
+	h.recordSlackUserCacheLookup("miss")
+
+	user, err := h.slackFor(teamID).GetUserInfo(userID)
+	if err != nil {
+		h.recordSlackAPIError("users.info", err)
+		return slackUserProfile{}, err
+	}
+	h.timezones.Remember(user)
+	h.slackUsers.remember(teamID, user, now)
+	h.recordSlackUserCacheSize()
+	return profileOf(user), nil
+}
+
+// slackUserNotionID maps a Slack user to their Notion user: the one
+// SLACK_NOTION_USER_OVERRIDES names, or else the one with their (cached)
+// email. found is false when the email has no Notion user in snapshot.
+// Overridden users are mapped even when their profile can't be fetched.
+func (h *Handler) slackUserNotionID(teamID, userID string, snapshot *notion.CacheSnapshot) (notionUserID string, profile slackUserProfile, found bool, err error) {
+	profile, err = h.slackUser(teamID, userID)
+	if override, ok := h.config.Load().SlackNotionUserOverrides[userID]; ok {
+		return override, profile, true, nil
+	}
+	if err != nil {
+		return "", profile, false, err
+	}
+	notionUserID, found = snapshot.NotionUserIDByEmail(profile.Email)
+	return notionUserID, profile, found, nil
+}
+
+// fallbackSubmitterID returns NOTION_FALLBACK_USER_ID for a submitter whose
+// email has no Notion user, or "" when no fallback is configured. The real
+// submitter is still recorded in constants.FieldSubmittedBySlack.
+func (h *Handler) fallbackSubmitterID(userID, email string) string {
+	fallback := h.config.Load().NotionFallbackUserID
+	if fallback == "" {
+		return ""
+	}
+	h.logger.Info("Slack user has no Notion user, attributing the submission to the fallback user",
+		zap.String("user_id", userID),
+		zap.String("email", email),
+		zap.String("notion_user_id", fallback),
+	)
+	h.recordSubmitterFallback()
+	return fallback
+}
+
+// RefreshSlackUsers reloads the profiles of every workspace with cached users
+// through users.list, so users who already submitted keep being served from
+// the cache. It is registered with the cache manager as
+// cache.CacheTypeSlackUsers. A workspace that fails keeps its cached
+// profiles, which expire after constants.SlackUserProfileTTL.
+func (h *Handler) RefreshSlackUsers(ctx context.Context) error {
+	var errs []error
+	for _, teamID := range h.slackUsers.teams() {
+		users, err := h.slackFor(teamID).GetUsersContext(ctx)
+		if err != nil {
+			h.recordSlackAPIError("users.list", err)
+			errs = append(errs, fmt.Errorf("team %s: %w", teamID, err))
+			continue
+		}
+		h.slackUsers.replace(teamID, users, h.clock.Now())
+		h.logger.Debug("refreshed Slack user cache", zap.String("team_id", teamID), zap.Int("users", len(users)))
+	}
+	h.recordSlackUserCacheSize()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to list Slack users: %w", errors.Join(errs...))
+	}
+	return nil
+}
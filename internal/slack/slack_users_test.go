@@ -0,0 +1,154 @@
+package slack
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// countingSlack counts users.info calls
+type countingSlack struct {
+	*fakeSlack
+	lookups int
+}
+
+func (s *countingSlack) GetUserInfo(user string) (*slack.User, error) {
+	s.lookups++
+	return s.fakeSlack.GetUserInfo(user)
+}
+
+// TestSlackUserNotionID_Cached tests that a Slack user's email is looked up
+// once and served from the cache until it expires
+func TestSlackUserNotionID_Cached(t *testing.T) {
+	slackAPI := &countingSlack{fakeSlack: &fakeSlack{users: map[string]*slack.User{
+		"U123": {ID: "U123", RealName: "Alice", Profile: slack.UserProfile{Email: "alice@example.com"}},
+	}}}
+	clock := &mutableClock{now: time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)}
+	handler := NewHandlerWithDependencies(&config.Config{}, zap.NewNop(), Dependencies{Backend: &fakeBackend{}, Slack: slackAPI, Clock: clock})
+	snapshot := notion.NewCacheSnapshot(nil, map[string]string{"alice@example.com": "notion-user-alice"})
+
+	for range 3 {
+		notionUserID, profile, found, err := handler.slackUserNotionID("T456", "U123", snapshot)
+		if err != nil || !found || notionUserID != "notion-user-alice" || profile.RealName != "Alice" {
+			t.Fatalf("slackUserNotionID() = %q, %+v, %v, %v, want alice", notionUserID, profile, found, err)
+		}
+	}
+	if slackAPI.lookups != 1 {
+		t.Errorf("users.info calls = %d, want 1", slackAPI.lookups)
+	}
+
+	// Another workspace's user with the same ID isn't served from the cache
+	handler.slackUser("T789", "U123")
+	if slackAPI.lookups != 2 {
+		t.Errorf("users.info calls after another workspace's lookup = %d, want 2", slackAPI.lookups)
+	}
+
+	clock.now = clock.now.Add(constants.SlackUserProfileTTL)
+	handler.slackUser("T456", "U123")
+	if slackAPI.lookups != 3 {
+		t.Errorf("users.info calls after expiry = %d, want 3", slackAPI.lookups)
+	}
+
+	// Failed lookups aren't cached
+	for range 2 {
+		if _, err := handler.slackUser("T456", "U-missing"); err == nil {
+			t.Fatal("slackUser() of an unknown user succeeded")
+		}
+	}
+	if slackAPI.lookups != 5 {
+		t.Errorf("users.info calls after failed lookups = %d, want 5", slackAPI.lookups)
+	}
+}
+
+// TestRefreshSlackUsers tests that a refresh reloads the workspaces with
+// cached users from users.list
+func TestRefreshSlackUsers(t *testing.T) {
+	slackAPI := &countingSlack{fakeSlack: &fakeSlack{users: map[string]*slack.User{
+		"U123":  {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}},
+		"U456":  {ID: "U456", Profile: slack.UserProfile{Email: "bob@example.com"}},
+		"U-bot": {ID: "U-bot", IsBot: true, Profile: slack.UserProfile{Email: "bot@example.com"}},
+	}}}
+	clock := &mutableClock{now: time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)}
+	handler := NewHandlerWithDependencies(&config.Config{}, zap.NewNop(), Dependencies{Backend: &fakeBackend{}, Slack: slackAPI, Clock: clock})
+
+	// No workspace has cached users yet, so there is nothing to list
+	if err := handler.RefreshSlackUsers(context.Background()); err != nil {
+		t.Fatalf("RefreshSlackUsers() error = %v", err)
+	}
+	if got := handler.slackUsers.Len(); got != 0 {
+		t.Fatalf("cached users = %d, want 0", got)
+	}
+
+	handler.slackUser("T456", "U123")
+	slackAPI.users["U123"].Profile.Email = "alice@new.example.com"
+	clock.now = clock.now.Add(time.Hour)
+	if err := handler.RefreshSlackUsers(context.Background()); err != nil {
+		t.Fatalf("RefreshSlackUsers() error = %v", err)
+	}
+	if got := handler.slackUsers.Len(); got != 2 {
+		t.Errorf("cached users = %d, want the workspace's 2 people", got)
+	}
+
+	for userID, want := range map[string]string{"U123": "alice@new.example.com", "U456": "bob@example.com"} {
+		profile, err := handler.slackUser("T456", userID)
+		if err != nil || profile.Email != want {
+			t.Errorf("slackUser(%s) = %+v, %v, want %s", userID, profile, err, want)
+		}
+	}
+	if slackAPI.lookups != 1 {
+		t.Errorf("users.info calls = %d, want only the first lookup", slackAPI.lookups)
+	}
+}
+
+// TestSubmitterNotionID_Mapping tests the user overrides and the fallback
+// Notion user for submitters whose email has no Notion user
+func TestSubmitterNotionID_Mapping(t *testing.T) {
+	slackAPI := &fakeSlack{users: map[string]*slack.User{
+		"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}},
+		"U456": {ID: "U456", RealName: "Jane Doe", Profile: slack.UserProfile{Email: "jane@contractor.example.com"}},
+	}}
+	handler := NewHandlerWithDependencies(&config.Config{}, zap.NewNop(), Dependencies{Backend: &fakeBackend{}, Slack: slackAPI})
+	snapshot := notion.NewCacheSnapshot(nil, map[string]string{"alice@example.com": "notion-user-alice"})
+
+	// Without a fallback, unmatched submitters are rejected
+	if _, _, err := handler.submitterNotionID("T1", "U456", snapshot); err == nil {
+		t.Fatal("submitterNotionID() of an unmatched user succeeded without a fallback")
+	}
+
+	cfg := &config.Config{
+		NotionFallbackUserID:     "notion-fallback",
+		SlackNotionUserOverrides: map[string]string{"U123": "notion-user-override", "U-gone": "notion-user-gone"},
+	}
+	handler.ApplyConfig(cfg)
+
+	tests := []struct {
+		userID string
+		want   string
+	}{
+		{"U123", "notion-user-override"}, // Overrides win over the email match
+		{"U456", "notion-fallback"},      // Unmatched emails get the fallback
+		{"U-gone", "notion-user-gone"},   // Overridden users don't need a Slack profile
+	}
+	for _, tt := range tests {
+		notionUserID, _, err := handler.submitterNotionID("T1", tt.userID, snapshot)
+		if err != nil || notionUserID != tt.want {
+			t.Errorf("submitterNotionID(%s) = %q, %v, want %q", tt.userID, notionUserID, err, tt.want)
+		}
+	}
+
+	_, profile, _ := handler.submitterNotionID("T1", "U456", snapshot)
+	if profile.RealName != "Jane Doe" || profile.Email != "jane@contractor.example.com" {
+		t.Errorf("profile = %+v, want the real submitter", profile)
+	}
+
+	// Users whose profile can't be fetched still fail
+	if _, _, err := handler.submitterNotionID("T1", "U-missing", snapshot); err == nil {
+		t.Error("submitterNotionID() of an unknown user succeeded")
+	}
+}
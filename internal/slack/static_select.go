@@ -0,0 +1,108 @@
+package slack
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/slack-go/slack"
+)
+
+// CustomerUsage counts how often each customer has been selected on submissions.
+// Static customer selects use it to decide which customers to offer when not
+// all of them fit. Counts are kept in memory and start over on restart.
+type CustomerUsage struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewCustomerUsage creates an empty usage counter.
+func NewCustomerUsage() *CustomerUsage {
+	return &CustomerUsage{counts: make(map[string]int)}
+}
+
+// Record counts one selection of each of the given customers.
+func (u *CustomerUsage) Record(customers []string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, customer := range customers {
+		u.counts[customer]++
+	}
+}
+
+// Len returns the number of customers counted.
+func (u *CustomerUsage) Len() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return len(u.counts)
+}
+
+// MostUsed returns up to n of the given customers, most selected first.
+// Ties, including customers never selected, keep their order in customers.
+func (u *CustomerUsage) MostUsed(customers []string, n int) []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	sorted := slices.Clone(customers)
+	slices.SortStableFunc(sorted, func(a, b string) int {
+		return u.counts[b] - u.counts[a]
+	})
+	return sorted[:min(n, len(sorted))]
+}
+
+// useStaticCustomerSelects replaces the external customer selects in blocks with
+// static selects when CUSTOMER_SELECT_MODE is static, for Slack apps without an
+// Options Load URL. Selected customers are kept.
+//
+// A static select holds at most constants.SlackMaxOptions options. With more
+// customers than that, it offers the most used ones and a final "more
+// customers" option telling users to mention the others in the title or
+// comments; each truncated select is counted in the
+// hopperbot_static_customer_options_truncated_total metric.
+func (h *Handler) useStaticCustomerSelects(teamID string, blocks []slack.Block) {
+	if h.config.Load().CustomerSelectMode != constants.CustomerSelectStatic {
+		return
+	}
+
+	customers := h.cacheFor(teamID).Snapshot().CustomerNames()
+	for _, block := range blocks {
+		input, ok := block.(*slack.InputBlock)
+		if !ok {
+			continue
+		}
+		element, ok := input.Element.(*slack.MultiSelectBlockElement)
+		if !ok || element.Type != slack.MultiOptTypeExternal {
+			continue
+		}
+
+		element.Type = slack.MultiOptTypeStatic
+		element.MinQueryLength = nil
+		element.Options = h.staticCustomerOptions(customers, element.InitialOptions)
+	}
+}
+
+// staticCustomerOptions returns the options of a static customer select,
+// always including the initially selected customers.
+func (h *Handler) staticCustomerOptions(customers []string, initial []*slack.OptionBlockObject) []*slack.OptionBlockObject {
+	if len(customers) <= constants.SlackMaxOptions {
+		return createOptions(customers)
+	}
+
+	options := slices.Clone(initial)
+	selected := make(map[string]bool, len(initial))
+	for _, option := range initial {
+		selected[option.Value] = true
+	}
+	others := slices.DeleteFunc(slices.Clone(customers), func(name string) bool { return selected[name] })
+
+	// Reserve the last option for the truncation indicator
+	shown := h.customerUsage.MostUsed(others, constants.SlackMaxOptions-1-len(options))
+	options = append(options, createOptions(shown)...)
+
+	more := h.messages.Format(messages.KeyStaticCustomersTruncated, messages.Params{"count": len(others) - len(shown)})
+	options = append(options, slack.NewOptionBlockObject(OptionValueMoreResults, newPlainText(more), nil))
+
+	h.recordStaticCustomerOptionsTruncated()
+	return options
+}
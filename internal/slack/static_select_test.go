@@ -0,0 +1,134 @@
+package slack
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// staticCustomerSelect returns the customer select of a modal
+func staticCustomerSelect(t *testing.T, modal slack.ModalViewRequest) *slack.MultiSelectBlockElement {
+	t.Helper()
+	for _, block := range modal.Blocks.BlockSet {
+		if input, ok := block.(*slack.InputBlock); ok && input.BlockID == BlockIDCustomerOrg {
+			return input.Element.(*slack.MultiSelectBlockElement)
+		}
+	}
+	t.Fatal("modal has no customer select")
+	return nil
+}
+
+// TestCustomerUsage_MostUsed tests that customers are ordered by selections, then by their original order
+func TestCustomerUsage_MostUsed(t *testing.T) {
+	usage := NewCustomerUsage()
+	usage.Record([]string{"Globex", "Initech"})
+	usage.Record([]string{"Globex"})
+
+	got := usage.MostUsed([]string{"Acme", "Globex", "Initech", "Umbrella"}, 3)
+	want := []string{"Globex", "Initech", "Acme"}
+	if !slices.Equal(got, want) {
+		t.Errorf("MostUsed() = %v, want %v", got, want)
+	}
+	if got := usage.MostUsed([]string{"Acme"}, 3); !slices.Equal(got, []string{"Acme"}) {
+		t.Errorf("MostUsed() = %v, want [Acme]", got)
+	}
+}
+
+// TestSubmissionModal_StaticCustomerSelect tests the static customer select fallback
+func TestSubmissionModal_StaticCustomerSelect(t *testing.T) {
+	manyCustomers := make(map[string]string)
+	for i := range 150 {
+		manyCustomers[fmt.Sprintf("Customer %03d", i)] = fmt.Sprintf("page-%d", i)
+	}
+
+	tests := []struct {
+		name        string
+		mode        string
+		customers   map[string]string
+		wantType    string
+		wantOptions int
+		wantFirst   string
+		wantLast    string
+	}{
+		{
+			name:      "external by default",
+			mode:      constants.CustomerSelectExternal,
+			customers: map[string]string{"Acme": "page-acme"},
+			wantType:  slack.MultiOptTypeExternal,
+		},
+		{
+			name:        "all customers fit",
+			mode:        constants.CustomerSelectStatic,
+			customers:   map[string]string{"Acme": "page-acme", "Globex": "page-globex"},
+			wantType:    slack.MultiOptTypeStatic,
+			wantOptions: 2,
+			wantFirst:   "Acme",
+			wantLast:    "Globex",
+		},
+		{
+			name:        "most used customers",
+			mode:        constants.CustomerSelectStatic,
+			customers:   manyCustomers,
+			wantType:    slack.MultiOptTypeStatic,
+			wantOptions: constants.SlackMaxOptions,
+			wantFirst:   "Customer 120",
+			wantLast:    "… 51 more: name them in the title or comments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{SlackSigningSecret: "secret", CustomerSelectMode: tt.mode}
+			handler := NewHandlerWithDependencies(cfg, zap.NewNop(), Dependencies{
+				Backend: &fakeBackend{snapshot: notion.NewCacheSnapshot(tt.customers, nil)},
+				Slack:   &fakeSlack{},
+				Clock:   fixedClock(time.Now()),
+			})
+			handler.customerUsage.Record([]string{"Customer 120"})
+
+			element := staticCustomerSelect(t, handler.submissionModal("T456"))
+			if element.Type != tt.wantType {
+				t.Fatalf("select type = %q, want %q", element.Type, tt.wantType)
+			}
+			if len(element.Options) != tt.wantOptions {
+				t.Fatalf("got %d options, want %d", len(element.Options), tt.wantOptions)
+			}
+			if tt.wantOptions == 0 {
+				return
+			}
+			if first := element.Options[0].Text.Text; first != tt.wantFirst {
+				t.Errorf("first option = %q, want %q", first, tt.wantFirst)
+			}
+			if last := element.Options[len(element.Options)-1].Text.Text; last != tt.wantLast {
+				t.Errorf("last option = %q, want %q", last, tt.wantLast)
+			}
+		})
+	}
+}
+
+// TestStaticCustomerOptions_KeepsInitialOptions tests that pre-filled customers are offered even if rarely used
+func TestStaticCustomerOptions_KeepsInitialOptions(t *testing.T) {
+	customers := make([]string, 0, 150)
+	for i := range 150 {
+		customers = append(customers, fmt.Sprintf("Customer %03d", i))
+	}
+	handler := newInteractiveTestHandler(&fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}, &fakeSlack{})
+
+	options := handler.staticCustomerOptions(customers, createOptions([]string{"Customer 149"}))
+	if len(options) != constants.SlackMaxOptions {
+		t.Fatalf("got %d options, want %d", len(options), constants.SlackMaxOptions)
+	}
+	if options[0].Value != "Customer 149" {
+		t.Errorf("first option = %q, want the initial option", options[0].Value)
+	}
+	if options[len(options)-1].Value != OptionValueMoreResults {
+		t.Errorf("last option = %q, want %q", options[len(options)-1].Value, OptionValueMoreResults)
+	}
+}
@@ -0,0 +1,75 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/statuswatch"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// SetStatusWatcher enables status change notifications: every created idea
+// submitted from Slack is watched, and its submitter is DMed when the product
+// team moves it to a new status. Passing nil (the default) disables them.
+func (h *Handler) SetStatusWatcher(watcher *statuswatch.Watcher) {
+	h.statusWatcher = watcher
+}
+
+// watchStatus starts watching a newly created idea's status. Ideas without a
+// Slack submitter (e.g. from the API) aren't watched. Failures are logged but
+// never fail the submission, which has already succeeded.
+func (h *Handler) watchStatus(sub submission.Submission, page *notion.CreatedPage) {
+	if h.statusWatcher == nil || sub.Source.SlackUserID == "" {
+		return
+	}
+
+	idea := statuswatch.Idea{
+		PageID:      page.ID,
+		PageURL:     page.URL,
+		Title:       sub.Title,
+		SlackTeamID: sub.Source.SlackTeamID,
+		SlackUserID: sub.Source.SlackUserID,
+		SubmittedAt: page.CreatedTime,
+	}
+	if err := h.statusWatcher.Watch(idea); err != nil {
+		h.logger.Error("failed to watch idea status", zap.String("page_id", page.ID), zap.Error(err))
+	}
+}
+
+// WatchedStatus is the statuswatch.StatusFunc for the watcher. It reads the
+// idea's status from the Notion database of the workspace it was submitted
+// from; a page that no longer exists is reported as archived.
+func (h *Handler) WatchedStatus(_ context.Context, idea statuswatch.Idea) (statuswatch.Status, error) {
+	status, err := h.backendFor(idea.SlackTeamID).GetPageStatus(idea.PageID)
+	var apiErr *notion.NotionAPIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return statuswatch.Status{Archived: true}, nil
+	}
+	if err != nil {
+		return statuswatch.Status{}, err
+	}
+	return statuswatch.Status{Value: status.Status, Archived: status.Archived}, nil
+}
+
+// NotifyStatusChange is the statuswatch.NotifyFunc for the watcher. It DMs
+// the submitter the idea's new status; Slack errors are returned so the
+// watcher retries.
+func (h *Handler) NotifyStatusChange(ctx context.Context, idea statuswatch.Idea, previous string) error {
+	if previous == "" {
+		previous = h.messages.Format(messages.KeyReminderNoStatus, nil)
+	}
+	text := h.messages.Format(messages.KeyStatusChanged, messages.Params{
+		"title": idea.Title, "url": idea.PageURL, "status": idea.Status, "previous": previous,
+	})
+	if _, _, err := h.slackFor(idea.SlackTeamID).PostMessageContext(ctx, idea.SlackUserID, slack.MsgOptionText(text, false)); err != nil {
+		h.recordSlackAPIError("chat.postMessage", err)
+		return fmt.Errorf("failed to send status change DM: %w", err)
+	}
+	return nil
+}
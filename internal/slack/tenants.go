@@ -0,0 +1,54 @@
+package slack
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// SetTenantBackends routes requests from the given Slack teams to their own
+// backends, e.g. a *notion.Client per tenant from TENANTS_FILE (see pkg/tenants).
+// Teams not in the map use the default backend.
+//
+// Tenant backends are initialized and refreshed along with the default one,
+// and share its modal form: the form is generated from the default database
+// schema, so tenant databases are expected to have the same properties.
+func (h *Handler) SetTenantBackends(backends map[string]SubmissionBackend) {
+	h.tenantBackends = backends
+	if h.metrics != nil {
+		for _, backend := range backends {
+			backend.SetMetrics(h.metrics)
+		}
+	}
+}
+
+// backendFor returns the backend for a Slack team: its tenant backend if it
+// has one, otherwise the default backend.
+func (h *Handler) backendFor(teamID string) SubmissionBackend {
+	if backend, ok := h.tenantBackends[teamID]; ok {
+		return backend
+	}
+	return h.backend
+}
+
+// cacheFor returns the customer and user cache for a Slack team.
+func (h *Handler) cacheFor(teamID string) CacheStore {
+	if backend, ok := h.tenantBackends[teamID]; ok {
+		return backend
+	}
+	return h.cache
+}
+
+// eachTenant calls fn for every tenant backend in team ID order, joining the
+// errors (each prefixed with the team ID) so one failing tenant doesn't stop
+// the others.
+func (h *Handler) eachTenant(fn func(SubmissionBackend) error) error {
+	var errs []error
+	for _, teamID := range slices.Sorted(maps.Keys(h.tenantBackends)) {
+		if err := fn(h.tenantBackends[teamID]); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", teamID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+package slack
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/slack-go/slack"
+)
+
+// TestHandleInteractive_TenantBackend tests that submissions from a tenant workspace go to its own database
+func TestHandleInteractive_TenantBackend(t *testing.T) {
+	defaultBackend := &fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}
+	tenantBackend := &fakeBackend{snapshot: notion.NewCacheSnapshot(
+		map[string]string{"Acme": "tenant-customer-acme"},
+		map[string]string{"alice@example.com": "tenant-alice"},
+	)}
+	slackAPI := &fakeSlack{
+		users:  map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+		posted: make(chan string, 1),
+	}
+	handler := newInteractiveTestHandler(defaultBackend, slackAPI)
+	handler.SetTenantBackends(map[string]SubmissionBackend{"T456": tenantBackend})
+
+	title := "Exports are slow"
+	w := httptest.NewRecorder()
+	handler.HandleInteractive(w, submissionRequest(t, map[string]map[string]StateValue{
+		BlockIDTitle:       {ActionIDTitleInput: {Type: "plain_text_input", Value: &title}},
+		BlockIDTheme:       {ActionIDThemeSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "Customer Pain Point"}}},
+		BlockIDProductArea: {ActionIDProductAreaSelect: {Type: "static_select", SelectedOption: &SelectedOption{Value: "AI/ML"}}},
+		BlockIDComments:    {ActionIDCommentsInput: {Type: "plain_text_input"}},
+		BlockIDCustomerOrg: {ActionIDCustomerOrgSelect: {Type: "multi_external_select", SelectedOptions: []SelectedOption{{Value: "Acme"}}}},
+	}))
+
+	if w.Code != http.StatusOK || w.Body.String() != "{}" {
+		t.Fatalf("response = %d %q, want 200 {}", w.Code, w.Body.String())
+	}
+	if len(defaultBackend.submissions) != 0 {
+		t.Errorf("default backend got %d submissions, want 0", len(defaultBackend.submissions))
+	}
+	if len(tenantBackend.submissions) != 1 {
+		t.Fatalf("tenant backend got %d submissions, want 1", len(tenantBackend.submissions))
+	}
+	sub := tenantBackend.submissions[0]
+	if sub.SubmitterNotionID != "tenant-alice" || len(sub.CustomerOrgIDs) != 1 || sub.CustomerOrgIDs[0] != "tenant-customer-acme" {
+		t.Errorf("submission = %+v, want the tenant's user and customer IDs", sub)
+	}
+}
+
+// TestBackendFor tests tenant routing and the default fallback
+func TestBackendFor(t *testing.T) {
+	defaultBackend := &fakeBackend{}
+	tenantBackend := &fakeBackend{}
+	handler := newInteractiveTestHandler(defaultBackend, &fakeSlack{})
+	handler.SetTenantBackends(map[string]SubmissionBackend{"T789": tenantBackend})
+
+	if handler.backendFor("T789") != SubmissionBackend(tenantBackend) || handler.cacheFor("T789") != CacheStore(tenantBackend) {
+		t.Error("tenant team should use its own backend")
+	}
+	if handler.backendFor("T456") != SubmissionBackend(defaultBackend) || handler.backendFor("") != SubmissionBackend(defaultBackend) {
+		t.Error("other teams should use the default backend")
+	}
+}
+
+// TestEachTenant tests that tenant errors are collected without stopping other tenants
+func TestEachTenant(t *testing.T) {
+	handler := newInteractiveTestHandler(&fakeBackend{}, &fakeSlack{})
+	failing, ok := &fakeBackend{}, &fakeBackend{}
+	handler.SetTenantBackends(map[string]SubmissionBackend{"T2": failing, "T1": ok})
+
+	var visited []SubmissionBackend
+	err := handler.eachTenant(func(backend SubmissionBackend) error {
+		visited = append(visited, backend)
+		if backend == SubmissionBackend(failing) {
+			return errors.New("unauthorized")
+		}
+		return nil
+	})
+	if len(visited) != 2 {
+		t.Errorf("visited %d tenants, want 2", len(visited))
+	}
+	if err == nil || !strings.Contains(err.Error(), "tenant T2: unauthorized") {
+		t.Errorf("error = %v, want the failing tenant's error", err)
+	}
+}
+
+// TestCacheSizes tests that snapshot sizes add up across tenants
+func TestCacheSizes(t *testing.T) {
+	defaultBackend := &fakeBackend{snapshot: notion.NewCacheSnapshot(
+		map[string]string{"Acme": "customer-acme", "Globex": "customer-globex"},
+		map[string]string{"alice@example.com": "alice"},
+	)}
+	tenantBackend := &fakeBackend{snapshot: notion.NewCacheSnapshot(
+		map[string]string{"Initech": "customer-initech"},
+		map[string]string{"bob@example.com": "bob"},
+	)}
+	handler := newInteractiveTestHandler(defaultBackend, &fakeSlack{})
+	handler.SetTenantBackends(map[string]SubmissionBackend{"T456": tenantBackend})
+	handler.customerUsage.Record([]string{"Acme"})
+	handler.timezones.Remember(&slack.User{ID: "U123", TZ: "Europe/Berlin"})
+
+	sizes := handler.CacheSizes()
+	want := map[string]int{"customers": 3, "users": 2, "excluded_guests": 0, "customer_usage": 1, "slack_timezones": 1}
+	for name, size := range want {
+		if sizes[name] != size {
+			t.Errorf("%s = %d, want %d", name, sizes[name], size)
+		}
+	}
+}
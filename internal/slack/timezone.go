@@ -0,0 +1,143 @@
+// Package slack provides handlers and types for Slack integration.
+//
+// This file implements per-user timezone resolution for user-facing timestamps.
+// Slack exposes each user's timezone via users.info (tz, tz_label, tz_offset).
+// Messages sent by the bot (confirmations, digests, reminders) format times in
+// the recipient's timezone instead of UTC so "submitted at 3:04 PM" means the
+// same thing to the reader as it does on their wall clock.
+//
+// Timezones change rarely, so resolved locations are cached per user with a TTL.
+// The cache is also populated opportunistically from users.info responses the
+// handler already fetches during submission, avoiding extra API calls.
+package slack
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// UserTimeLayout is the layout used for user-facing timestamps.
+// Example: "Mon, Jan 2, 2006 at 3:04 PM PST"
+const UserTimeLayout = "Mon, Jan 2, 2006 at 3:04 PM MST"
+
+// userInfoLookup fetches a Slack user's profile (satisfied by slack.Client.GetUserInfo).
+type userInfoLookup func(userID string) (*slack.User, error)
+
+// cachedTimezone is a resolved timezone with the time it was fetched.
+type cachedTimezone struct {
+	location  *time.Location
+	fetchedAt time.Time
+}
+
+// TimezoneCache resolves and caches Slack users' timezones.
+//
+// Lookups that fail (user not found, API error, unknown tz name) fall back to UTC
+// so formatting never fails; failures are not cached so the next call retries.
+type TimezoneCache struct {
+	lookup  userInfoLookup
+	ttl     time.Duration
+	entries map[string]cachedTimezone
+	mu      sync.RWMutex
+	logger  *zap.Logger
+}
+
+// NewTimezoneCache creates a timezone cache backed by the given users.info lookup.
+// A non-positive ttl uses constants.SlackUserTimezoneTTL.
+func NewTimezoneCache(lookup userInfoLookup, ttl time.Duration, logger *zap.Logger) *TimezoneCache {
+	if ttl <= 0 {
+		ttl = constants.SlackUserTimezoneTTL
+	}
+	return &TimezoneCache{
+		lookup:  lookup,
+		ttl:     ttl,
+		entries: make(map[string]cachedTimezone),
+		logger:  logger,
+	}
+}
+
+// Remember stores the timezone from a users.info response that was already fetched.
+func (c *TimezoneCache) Remember(user *slack.User) {
+	if user == nil || user.ID == "" {
+		return
+	}
+	location := locationForUser(user)
+
+	c.mu.Lock()
+	c.entries[user.ID] = cachedTimezone{location: location, fetchedAt: time.Now()}
+	c.mu.Unlock()
+}
+
+// Len returns the number of cached users, expired entries included (they are
+// refreshed on use, never evicted).
+func (c *TimezoneCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// Location returns the user's timezone, fetching it from Slack when not cached or expired.
+// Returns time.UTC if the timezone cannot be determined.
+func (c *TimezoneCache) Location(userID string) *time.Location {
+	c.mu.RLock()
+	entry, found := c.entries[userID]
+	c.mu.RUnlock()
+
+	if found && time.Since(entry.fetchedAt) < c.ttl {
+		return entry.location
+	}
+
+	if c.lookup == nil {
+		return time.UTC
+	}
+
+	user, err := c.lookup(userID)
+	if err != nil {
+		c.logger.Warn("failed to resolve Slack user timezone, falling back to UTC",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+		return time.UTC
+	}
+
+	c.Remember(user)
+	return locationForUser(user)
+}
+
+// Format renders t in the user's timezone using UserTimeLayout.
+//
+// Example:
+//
+//	cache.Format("U123", time.Date(2025, 11, 5, 17, 30, 0, 0, time.UTC))
+//	// Returns: "Wed, Nov 5, 2025 at 9:30 AM PST" for a user in America/Los_Angeles
+func (c *TimezoneCache) Format(userID string, t time.Time) string {
+	return t.In(c.Location(userID)).Format(UserTimeLayout)
+}
+
+// locationForUser resolves a *time.Location from a Slack user profile.
+//
+// Prefers the IANA name (tz) so DST transitions are handled correctly, then falls
+// back to a fixed zone from tz_offset/tz_label, then UTC.
+func locationForUser(user *slack.User) *time.Location {
+	if user.TZ != "" {
+		if location, err := time.LoadLocation(user.TZ); err == nil {
+			return location
+		}
+	}
+	if user.TZOffset != 0 {
+		name := user.TZLabel
+		if name == "" {
+			sign, offset := "+", user.TZOffset
+			if offset < 0 {
+				sign, offset = "-", -offset
+			}
+			name = fmt.Sprintf("UTC%s%02d:%02d", sign, offset/3600, (offset%3600)/60)
+		}
+		return time.FixedZone(name, user.TZOffset)
+	}
+	return time.UTC
+}
@@ -0,0 +1,128 @@
+package slack
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// TestLocationForUser tests timezone resolution from Slack user profiles
+func TestLocationForUser(t *testing.T) {
+	tests := []struct {
+		name         string
+		user         *slack.User
+		expectedName string
+		expectedOff  int
+	}{
+		{
+			name:         "IANA timezone name",
+			user:         &slack.User{TZ: "Asia/Kolkata"},
+			expectedName: "Asia/Kolkata",
+			expectedOff:  5*3600 + 30*60,
+		},
+		{
+			name:         "invalid tz name falls back to offset and label",
+			user:         &slack.User{TZ: "Not/AZone", TZLabel: "Custom Time", TZOffset: -7 * 3600},
+			expectedName: "Custom Time",
+			expectedOff:  -7 * 3600,
+		},
+		{
+			name:         "offset without label",
+			user:         &slack.User{TZOffset: -(3*3600 + 30*60)},
+			expectedName: "UTC-03:30",
+			expectedOff:  -(3*3600 + 30*60),
+		},
+		{
+			name:         "no timezone information",
+			user:         &slack.User{},
+			expectedName: "UTC",
+			expectedOff:  0,
+		},
+	}
+
+	reference := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := locationForUser(tt.user)
+			if location.String() != tt.expectedName {
+				t.Errorf("location name = %q, want %q", location.String(), tt.expectedName)
+			}
+			_, offset := reference.In(location).Zone()
+			if offset != tt.expectedOff {
+				t.Errorf("offset = %d, want %d", offset, tt.expectedOff)
+			}
+		})
+	}
+}
+
+// TestTimezoneCache_CachesLookups verifies users.info is called once per TTL window
+func TestTimezoneCache_CachesLookups(t *testing.T) {
+	calls := 0
+	lookup := func(userID string) (*slack.User, error) {
+		calls++
+		return &slack.User{ID: userID, TZ: "America/New_York"}, nil
+	}
+
+	cache := NewTimezoneCache(lookup, time.Hour, zap.NewNop())
+
+	for i := 0; i < 3; i++ {
+		if got := cache.Location("U123").String(); got != "America/New_York" {
+			t.Errorf("Location() = %q, want America/New_York", got)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("expected 1 users.info call, got %d", calls)
+	}
+}
+
+// TestTimezoneCache_Remember verifies pre-fetched profiles populate the cache
+func TestTimezoneCache_Remember(t *testing.T) {
+	lookup := func(userID string) (*slack.User, error) {
+		t.Fatal("lookup should not be called for remembered users")
+		return nil, nil
+	}
+
+	cache := NewTimezoneCache(lookup, time.Hour, zap.NewNop())
+	cache.Remember(&slack.User{ID: "U123", TZ: "Europe/Berlin"})
+
+	if got := cache.Location("U123").String(); got != "Europe/Berlin" {
+		t.Errorf("Location() = %q, want Europe/Berlin", got)
+	}
+}
+
+// TestTimezoneCache_LookupFailure verifies UTC fallback and that failures aren't cached
+func TestTimezoneCache_LookupFailure(t *testing.T) {
+	calls := 0
+	lookup := func(userID string) (*slack.User, error) {
+		calls++
+		return nil, errors.New("user_not_found")
+	}
+
+	cache := NewTimezoneCache(lookup, time.Hour, zap.NewNop())
+
+	if got := cache.Location("U404"); got != time.UTC {
+		t.Errorf("Location() = %v, want UTC", got)
+	}
+	cache.Location("U404")
+
+	if calls != 2 {
+		t.Errorf("expected failed lookups to be retried, got %d calls", calls)
+	}
+}
+
+// TestTimezoneCache_Format verifies formatting in the user's timezone
+func TestTimezoneCache_Format(t *testing.T) {
+	cache := NewTimezoneCache(nil, time.Hour, zap.NewNop())
+	cache.Remember(&slack.User{ID: "U123", TZ: "America/Los_Angeles"})
+
+	ts := time.Date(2025, 11, 5, 17, 30, 0, 0, time.UTC)
+	expected := "Wed, Nov 5, 2025 at 9:30 AM PST"
+
+	if got := cache.Format("U123", ts); got != expected {
+		t.Errorf("Format() = %q, want %q", got, expected)
+	}
+}
@@ -12,6 +12,7 @@ package slack
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 )
 
@@ -32,6 +33,12 @@ type InteractionPayload struct {
 	ResponseURL string    `json:"response_url,omitempty"`
 	Actions     []Action  `json:"actions,omitempty"`
 	Container   Container `json:"container,omitempty"`
+
+	// Message shortcuts (message_action) carry their callback ID, and the
+	// message and channel they were used on
+	CallbackID string   `json:"callback_id,omitempty"`
+	Channel    Channel  `json:"channel,omitempty"`
+	Message    *Message `json:"message,omitempty"`
 }
 
 // User represents the Slack user who triggered the interaction
@@ -85,6 +92,11 @@ type ViewElement struct {
 	Emoji bool   `json:"emoji,omitempty"`
 }
 
+// ErrFieldNotInView is returned (wrapped) by the ViewState getters when a block or
+// action is absent from the submitted view, e.g. because the modal was opened
+// from an older version of the form. Use errors.Is to tell it apart from other failures.
+var ErrFieldNotInView = errors.New("field not in view state")
+
 // ViewState represents the state of a view, containing all input values.
 //
 // The structure is nested: state.values[block_id][action_id] -> StateValue
@@ -102,6 +114,7 @@ type ViewState struct {
 // - Single-select: SelectedOption contains the chosen option
 // - Multi-select: SelectedOptions contains array of chosen options
 // - Date/time/user/channel: Respective fields contain the selection
+// - File inputs: Files contains the uploaded files
 //
 // The Type field indicates which field(s) will be populated.
 type StateValue struct {
@@ -110,10 +123,20 @@ type StateValue struct {
 	SelectedDate         string           `json:"selected_date,omitempty"`
 	SelectedTime         string           `json:"selected_time,omitempty"`
 	SelectedUser         string           `json:"selected_user,omitempty"`
+	SelectedUsers        []string         `json:"selected_users,omitempty"`
 	SelectedChannel      string           `json:"selected_channel,omitempty"`
 	SelectedConversation string           `json:"selected_conversation,omitempty"`
 	SelectedOption       *SelectedOption  `json:"selected_option,omitempty"`
 	SelectedOptions      []SelectedOption `json:"selected_options,omitempty"`
+	Files                []StateFile      `json:"files,omitempty"`
+}
+
+// StateFile is a file uploaded in a file_input element.
+type StateFile struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Mimetype string `json:"mimetype"`
+	Size     int64  `json:"size"`
 }
 
 // SelectedOption represents a selected option from a select menu.
@@ -149,6 +172,7 @@ type Action struct {
 	ActionTS        string           `json:"action_ts"`
 	SelectedOption  *SelectedOption  `json:"selected_option,omitempty"`
 	SelectedOptions []SelectedOption `json:"selected_options,omitempty"`
+	SelectedUser    string           `json:"selected_user,omitempty"` // users_select
 }
 
 // Container represents the container of an interactive component
@@ -160,6 +184,51 @@ type Container struct {
 	ViewID      string `json:"view_id,omitempty"`
 }
 
+// Channel represents the channel a message shortcut was used in
+type Channel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Message represents the message a message shortcut was used on
+type Message struct {
+	Type     string `json:"type"`
+	User     string `json:"user"`
+	Text     string `json:"text"`
+	TS       string `json:"ts"`
+	ThreadTS string `json:"thread_ts,omitempty"` // Set on threaded replies and thread parents
+}
+
+// EventRequest is the body of an Events API request.
+//
+// Slack first sends a url_verification request whose Challenge must be echoed
+// back; subscribed events then arrive as event_callback requests.
+type EventRequest struct {
+	Type      string `json:"type"`                // EventRequestURLVerification or EventRequestCallback
+	Challenge string `json:"challenge,omitempty"` // Set for url_verification
+	TeamID    string `json:"team_id,omitempty"`
+	EventID   string `json:"event_id,omitempty"`
+	Event     Event  `json:"event"`
+}
+
+// Event is the inner event of an event_callback request (fields used by
+// app_home_opened, reaction_added and reaction_removed).
+type Event struct {
+	Type     string     `json:"type"`
+	User     string     `json:"user"`
+	Channel  string     `json:"channel,omitempty"`
+	Tab      string     `json:"tab,omitempty"`      // "home" or "messages" for app_home_opened
+	Reaction string     `json:"reaction,omitempty"` // Emoji name without colons, e.g. "+1" or "+1::skin-tone-2"
+	Item     *EventItem `json:"item,omitempty"`     // The reacted-to item
+}
+
+// EventItem is the item a reaction event refers to.
+type EventItem struct {
+	Type    string `json:"type"` // "message" for reactions on messages
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
 // OptionsRequest represents a block suggestion request from Slack for external select options.
 //
 // When a user types in a search field for an external select menu (e.g., Client Organization),
@@ -182,10 +251,12 @@ type OptionsRequest struct {
 //
 // Used in OptionsResponse to return matching options to Slack.
 // The Value field is what gets submitted when the option is selected,
-// while Text is what the user sees.
+// while Text is what the user sees, with Description (optional) in a
+// smaller line below it.
 type Option struct {
-	Text  OptionText `json:"text"`
-	Value string     `json:"value"`
+	Text        OptionText  `json:"text"`
+	Value       string      `json:"value"`
+	Description *OptionText `json:"description,omitempty"`
 }
 
 // OptionsResponse represents the response structure for block suggestion requests.
@@ -301,6 +372,12 @@ func (sv *StateValue) IsMultiSelect() bool {
 		sv.Type == "multi_channels_select"
 }
 
+// HasField reports whether the view state contains the given block and action.
+func (vs *ViewState) HasField(blockID, actionID string) bool {
+	_, exists := vs.Values[blockID][actionID]
+	return exists
+}
+
 // GetValue extracts a plain text value from the view state.
 //
 // Used for text input fields (plain_text_input or rich_text_input).
@@ -315,17 +392,17 @@ func (sv *StateValue) IsMultiSelect() bool {
 //	}
 func (vs *ViewState) GetValue(blockID, actionID string) (string, error) {
 	if vs.Values == nil {
-		return "", fmt.Errorf("view state values is nil")
+		return "", fmt.Errorf("view state values is nil: %w", ErrFieldNotInView)
 	}
 
 	block, exists := vs.Values[blockID]
 	if !exists {
-		return "", fmt.Errorf("block %q not found in view state", blockID)
+		return "", fmt.Errorf("block %q not found in view state: %w", blockID, ErrFieldNotInView)
 	}
 
 	stateValue, exists := block[actionID]
 	if !exists {
-		return "", fmt.Errorf("action %q not found in block %q", actionID, blockID)
+		return "", fmt.Errorf("action %q not found in block %q: %w", actionID, blockID, ErrFieldNotInView)
 	}
 
 	if stateValue.Value != nil {
@@ -355,17 +432,17 @@ func (vs *ViewState) GetValue(blockID, actionID string) (string, error) {
 //	}
 func (vs *ViewState) GetSelectedOption(blockID, actionID string) (string, error) {
 	if vs.Values == nil {
-		return "", fmt.Errorf("view state values is nil")
+		return "", fmt.Errorf("view state values is nil: %w", ErrFieldNotInView)
 	}
 
 	block, exists := vs.Values[blockID]
 	if !exists {
-		return "", fmt.Errorf("block %q not found in view state", blockID)
+		return "", fmt.Errorf("block %q not found in view state: %w", blockID, ErrFieldNotInView)
 	}
 
 	stateValue, exists := block[actionID]
 	if !exists {
-		return "", fmt.Errorf("action %q not found in block %q", actionID, blockID)
+		return "", fmt.Errorf("action %q not found in block %q: %w", actionID, blockID, ErrFieldNotInView)
 	}
 
 	// CRITICAL: Check if SelectedOption is nil before accessing its fields
@@ -404,17 +481,17 @@ func (vs *ViewState) GetSelectedOption(blockID, actionID string) (string, error)
 //	}
 func (vs *ViewState) GetSelectedOptions(blockID, actionID string) ([]string, error) {
 	if vs.Values == nil {
-		return nil, fmt.Errorf("view state values is nil")
+		return nil, fmt.Errorf("view state values is nil: %w", ErrFieldNotInView)
 	}
 
 	block, exists := vs.Values[blockID]
 	if !exists {
-		return nil, fmt.Errorf("block %q not found in view state", blockID)
+		return nil, fmt.Errorf("block %q not found in view state: %w", blockID, ErrFieldNotInView)
 	}
 
 	stateValue, exists := block[actionID]
 	if !exists {
-		return nil, fmt.Errorf("action %q not found in block %q", actionID, blockID)
+		return nil, fmt.Errorf("action %q not found in block %q: %w", actionID, blockID, ErrFieldNotInView)
 	}
 
 	// Extract values from all selected options
@@ -1,6 +1,9 @@
 package slack
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 // TestGetSelectedOption_NilPointerSafety tests that GetSelectedOption handles nil pointers safely
 func TestGetSelectedOption_NilPointerSafety(t *testing.T) {
@@ -398,3 +401,32 @@ func TestGetSelectedOptions(t *testing.T) {
 		})
 	}
 }
+
+// TestViewState_MissingFields tests that absent blocks are reported with ErrFieldNotInView
+func TestViewState_MissingFields(t *testing.T) {
+	value := "x"
+	state := ViewState{Values: map[string]map[string]StateValue{
+		BlockIDTitle: {ActionIDTitleInput: {Type: "plain_text_input", Value: &value}},
+	}}
+
+	if !state.HasField(BlockIDTitle, ActionIDTitleInput) {
+		t.Error("HasField() = false for present field")
+	}
+	if state.HasField(BlockIDTitle, ActionIDThemeSelect) || state.HasField(BlockIDTheme, ActionIDThemeSelect) {
+		t.Error("HasField() = true for missing field")
+	}
+
+	if _, err := state.GetValue(BlockIDComments, ActionIDCommentsInput); !errors.Is(err, ErrFieldNotInView) {
+		t.Errorf("GetValue() error = %v, want ErrFieldNotInView", err)
+	}
+	if _, err := state.GetSelectedOption(BlockIDTitle, ActionIDThemeSelect); !errors.Is(err, ErrFieldNotInView) {
+		t.Errorf("GetSelectedOption() error = %v, want ErrFieldNotInView", err)
+	}
+	empty := ViewState{}
+	if _, err := empty.GetSelectedOptions(BlockIDCustomerOrg, ActionIDCustomerOrgSelect); !errors.Is(err, ErrFieldNotInView) {
+		t.Errorf("GetSelectedOptions() error = %v, want ErrFieldNotInView", err)
+	}
+	if empty.HasField(BlockIDTitle, ActionIDTitleInput) {
+		t.Error("HasField() = true on empty state")
+	}
+}
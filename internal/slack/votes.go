@@ -0,0 +1,101 @@
+package slack
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/rudderlabs/hopperbot/pkg/votes"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// SetVoting turns reactions on confirmations in the confirmation channel into
+// votes: each card is recorded in store, and the number of users who reacted
+// with one of reactions (emoji names without colons; skin tones count as the
+// base emoji) is written to the idea's Votes property. Needs the
+// reactions:read scope, the reaction_added and reaction_removed events, and a
+// Votes number property in the ideas database. Passing a nil store (the
+// default) disables voting.
+func (h *Handler) SetVoting(store votes.Store, reactions []string) {
+	if len(reactions) == 0 {
+		reactions = constants.DefaultVoteReactions
+	}
+	h.votes = store
+	h.voteReactions = reactions
+}
+
+// voteHintBlocks appends a line inviting reactions to a confirmation card when
+// voting is enabled.
+func (h *Handler) voteHintBlocks(blocks []slack.Block) []slack.Block {
+	if h.votes == nil {
+		return blocks
+	}
+	emoji := make([]string, len(h.voteReactions))
+	for i, reaction := range h.voteReactions {
+		emoji[i] = ":" + reaction + ":"
+	}
+	hint := h.messages.Format(messages.KeyVoteHint, messages.Params{"reactions": strings.Join(emoji, " ")})
+	return append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, hint, false, false)))
+}
+
+// recordVoteCard records a confirmation posted to the confirmation channel so
+// reactions on it are counted. Failures are logged but never fail the
+// submission, which has already succeeded.
+func (h *Handler) recordVoteCard(sub submission.Submission, page *notion.CreatedPage, channel, ts string) {
+	if h.votes == nil {
+		return
+	}
+
+	card := votes.Card{
+		Channel:     channel,
+		TS:          ts,
+		PageID:      page.ID,
+		SlackTeamID: sub.Source.SlackTeamID,
+		PostedAt:    h.clock.Now().UTC(),
+	}
+	if err := h.votes.Add(card); err != nil {
+		h.logger.Error("failed to record vote card", zap.String("page_id", page.ID), zap.Error(err))
+	}
+}
+
+// handleReactionEvent updates the votes of the idea whose card a
+// reaction_added or reaction_removed event refers to. Reactions on other
+// messages, and with other emoji, are ignored.
+func (h *Handler) handleReactionEvent(_ context.Context, req EventRequest) {
+	event := req.Event
+	if h.votes == nil || event.Item == nil || event.Item.Type != "message" {
+		return
+	}
+	// Skin tones are variants of the same vote, e.g. "+1::skin-tone-2"
+	reaction, _, _ := strings.Cut(event.Reaction, "::")
+	if !slices.Contains(h.voteReactions, reaction) {
+		return
+	}
+
+	h.votesMu.Lock()
+	defer h.votesMu.Unlock()
+
+	added := event.Type == EventTypeReactionAdded
+	card, found, err := h.votes.React(event.Item.Channel, event.Item.TS, event.User, reaction, added)
+	if err != nil {
+		// The in-memory count is updated; the page still gets it
+		h.logger.Error("failed to save vote", zap.String("page_id", card.PageID), zap.Error(err))
+	}
+	if !found {
+		return
+	}
+	h.recordVote(added)
+
+	if err := h.backendFor(card.SlackTeamID).SetVotes(card.PageID, card.Votes()); err != nil {
+		h.logger.Error("failed to update votes on Notion page",
+			zap.String("page_id", card.PageID),
+			zap.Int("votes", card.Votes()),
+			zap.Error(err),
+		)
+	}
+}
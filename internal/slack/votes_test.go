@@ -0,0 +1,76 @@
+package slack
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/rudderlabs/hopperbot/pkg/votes"
+	"go.uber.org/zap"
+)
+
+// TestVoting tests that vote reactions on a confirmation card set the idea's Votes, and other reactions don't
+func TestVoting(t *testing.T) {
+	backend := &fakeBackend{}
+	slackAPI := &fakeSlack{posted: make(chan string, 1), sent: make(chan url.Values, 1)}
+	handler := NewHandlerWithDependencies(&config.Config{SlackSigningSecret: "secret", ConfirmationChannel: "C-ideas"}, zap.NewNop(), Dependencies{
+		Backend: backend,
+		Slack:   slackAPI,
+		Clock:   fixedClock(time.Now()),
+	})
+	store, _ := votes.NewFileStore("")
+	handler.SetVoting(store, nil)
+
+	sub := submission.Submission{Title: "CSV exports", Source: submission.Source{SlackUserID: "U123"}}
+	handler.postConfirmation(t.Context(), sub, &notion.CreatedPage{ID: "page-1", URL: "https://www.notion.so/page-1"}, nil)
+	<-slackAPI.posted
+	if blocks := (<-slackAPI.sent).Get("blocks"); !strings.Contains(blocks, "React with :+1: to vote") {
+		t.Errorf("confirmation blocks = %s, want the vote hint", blocks)
+	}
+
+	react := func(eventType, user, reaction, ts string) {
+		body := fmt.Sprintf(`{"type":"event_callback","event":{"type":%q,"user":%q,"reaction":%q,"item":{"type":"message","channel":"C-ideas","ts":%q}}}`,
+			eventType, user, reaction, ts)
+		w := httptest.NewRecorder()
+		handler.HandleEvents(w, createValidSlackRequest(http.MethodPost, "/slack/events", []byte(body), "secret"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", w.Code)
+		}
+	}
+	waitForVotes := func(want int) {
+		t.Helper()
+		var got int
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			backend.mu.Lock()
+			got = backend.votes["page-1"]
+			backend.mu.Unlock()
+			if got == want {
+				return
+			}
+		}
+		t.Fatalf("votes = %d, want %d", got, want)
+	}
+
+	react(EventTypeReactionAdded, "U1", "+1", "1700000000.000100")
+	waitForVotes(1)
+	react(EventTypeReactionAdded, "U2", "+1::skin-tone-3", "1700000000.000100")
+	waitForVotes(2)
+	react(EventTypeReactionRemoved, "U1", "+1", "1700000000.000100")
+	waitForVotes(1)
+
+	react(EventTypeReactionAdded, "U3", "tada", "1700000000.000100")
+	react(EventTypeReactionAdded, "U3", "+1", "1600000000.000100")
+	time.Sleep(50 * time.Millisecond)
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if len(backend.votes) != 1 || backend.votes["page-1"] != 1 {
+		t.Errorf("votes = %v, want other emoji and messages ignored", backend.votes)
+	}
+}
@@ -0,0 +1,198 @@
+package slack
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/pkg/analytics"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/middleware"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// webFormCallbackID identifies web form submissions in analytics, where Slack
+// submissions carry their modal's callback ID.
+const webFormCallbackID = "web_form"
+
+// webForm is what the web intake form posts, and what it's re-rendered with
+// after a rejected submission.
+type webForm struct {
+	Title       string
+	Theme       string
+	ProductArea string
+	Customers   string // Comma-separated customer names
+	Comments    string
+	Email       string
+}
+
+// webFormFromRequest reads the fields of a posted web form.
+func webFormFromRequest(r *http.Request) webForm {
+	field := func(name string) string { return strings.TrimSpace(r.PostFormValue(name)) }
+	return webForm{
+		Title:       field("title"),
+		Theme:       field("theme"),
+		ProductArea: field("product_area"),
+		Customers:   field("customers"),
+		Comments:    field("comments"),
+		Email:       field("email"),
+	}
+}
+
+// quickSubmission returns the form as a quick submission, so it is validated
+// by the same code as the modal.
+func (f webForm) quickSubmission() quickSubmission {
+	var customers []string
+	for _, name := range strings.Split(f.Customers, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			customers = append(customers, name)
+		}
+	}
+	return quickSubmission{
+		Title:       f.Title,
+		Theme:       f.Theme,
+		ProductArea: f.ProductArea,
+		Customers:   customers,
+		Comments:    f.Comments,
+	}
+}
+
+// HandleWebForm serves the web intake form (constants.RouteWebSubmit): GET
+// renders it and POST files the idea. Web submitters aren't authenticated, so
+// their ideas are attributed to NOTION_FALLBACK_USER_ID with the email they
+// typed recorded as the contact, and the Slack user quotas and duplicate
+// checks don't apply. Instead server.HandleForm limits posts per client IP and
+// keeps bots out with middleware.WithCaptcha, which config requires.
+func (h *Handler) HandleWebForm(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.writeWebForm(w, http.StatusOK, webForm{}, "")
+	case http.MethodPost:
+		h.submitWebForm(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// submitWebForm validates a posted web form and creates its page.
+func (h *Handler) submitWebForm(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.FromContext(ctx, h.logger)
+	form := webFormFromRequest(r)
+	payload := &InteractionPayload{View: View{CallbackID: webFormCallbackID}}
+
+	fallbackUserID := h.config.Load().NotionFallbackUserID
+	if fallbackUserID == "" {
+		logger.Error("rejecting web submission without NOTION_FALLBACK_USER_ID to attribute it to")
+		h.writeWebForm(w, http.StatusServiceUnavailable, form, "Web submissions aren't available right now.")
+		return
+	}
+	if address, err := mail.ParseAddress(form.Email); err != nil || address.Address != form.Email {
+		h.writeWebForm(w, http.StatusBadRequest, form, "Enter a valid email address so the product team can follow up.")
+		return
+	}
+	if err := h.backendFor("").SchemaError(); err != nil {
+		logger.Warn("rejecting web submission while the ideas database schema is invalid", zap.Error(err))
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, nil, "schema_invalid")
+		h.writeWebForm(w, http.StatusServiceUnavailable, form, h.messages.Format(messages.KeySubmissionsPaused, nil))
+		return
+	}
+
+	snapshot := h.cacheFor("").Snapshot()
+	sub, err := h.extractAndValidateFields(ctx, "", form.quickSubmission().viewState(h.selectOptions(), snapshot), snapshot)
+	if err != nil {
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, nil, "validation_error")
+		h.writeWebForm(w, http.StatusBadRequest, form, validationErrorText(err.(fieldValidationError).errors))
+		return
+	}
+	sub.SubmitterNotionID = fallbackUserID
+	sub.Source = submission.Source{
+		Channel:        submission.ChannelWeb,
+		SubmitterEmail: form.Email,
+		SubmittedAt:    h.clock.Now().UTC(),
+	}
+
+	page, err := h.createPage(ctx, "", sub)
+	if err != nil {
+		logger.Error("failed to submit web submission to Notion", zap.Error(err))
+		h.trackSubmission(analytics.EventSubmissionFailed, payload, &sub, "notion_error")
+		h.writeWebForm(w, http.StatusBadGateway, form, h.submitErrorMessage(ctx, err))
+		return
+	}
+
+	logger.Info("successfully submitted web submission to Notion",
+		zap.String("page_id", page.ID),
+		zap.String("page_url", page.URL),
+	)
+	h.customerUsage.Record(sub.CustomerOrgs)
+	h.trackIdea(sub, page)
+	h.trackSubmission(analytics.EventSubmissionCreated, payload, &sub, "success")
+	writeHTMLPage(w, http.StatusOK, "Idea submitted", "Thanks! \""+sub.Title+"\" was sent to the product team.")
+
+	h.background("web_submission", func() {
+		ctx := context.WithoutCancel(ctx)
+		copies := h.fanoutWrite(ctx, sub, page)
+		thread := h.postConfirmation(ctx, sub, page, copies)
+		h.appendComments(ctx, sub, page, thread)
+	})
+}
+
+// webFormTemplate renders the web intake form. html/template escapes every value.
+var webFormTemplate = template.Must(template.New("web_form").Parse(`<!DOCTYPE html>
+<html><head><title>Submit an idea</title>{{with .CaptchaScript}}<script src="{{.}}" async defer></script>{{end}}</head>
+<body>
+<h1>Submit an idea</h1>
+{{with .Error}}<p role="alert">{{.}}</p>{{end}}
+<form method="post" action="{{.Action}}">
+<p><label>Title <input name="title" value="{{.Form.Title}}" maxlength="{{.MaxTitle}}" required></label></p>
+<p><label>Theme <select name="theme" required><option value=""></option>{{range .Themes}}<option{{if eq . $.Form.Theme}} selected{{end}}>{{.}}</option>{{end}}</select></label></p>
+<p><label>Product area <select name="product_area" required><option value=""></option>{{range .ProductAreas}}<option{{if eq . $.Form.ProductArea}} selected{{end}}>{{.}}</option>{{end}}</select></label></p>
+<p><label>Customers (comma-separated) <input name="customers" value="{{.Form.Customers}}"></label></p>
+<p><label>Comments <textarea name="comments">{{.Form.Comments}}</textarea></label></p>
+<p><label>Your email <input type="email" name="email" value="{{.Form.Email}}" required></label></p>
+{{with .CaptchaClass}}<div class="{{.}}" data-sitekey="{{$.CaptchaSiteKey}}"></div>{{end}}
+<p><button type="submit">Submit</button></p>
+</form>
+</body></html>
+`))
+
+// writeWebForm renders the web intake form filled with form, showing message
+// (if any) above it.
+func (h *Handler) writeWebForm(w http.ResponseWriter, status int, form webForm, message string) {
+	cfg := h.config.Load()
+	options := h.selectOptions()
+	data := struct {
+		Action         string
+		Form           webForm
+		Error          string
+		MaxTitle       int
+		Themes         []string
+		ProductAreas   []string
+		CaptchaScript  string
+		CaptchaClass   string
+		CaptchaSiteKey string
+	}{
+		Action:         cfg.BasePath + constants.RouteWebSubmit,
+		Form:           form,
+		Error:          message,
+		MaxTitle:       constants.MaxTitleLength,
+		Themes:         options.Themes,
+		ProductAreas:   options.ProductAreas,
+		CaptchaSiteKey: cfg.CaptchaSiteKey,
+	}
+	if script, class, ok := middleware.CaptchaWidget(cfg.CaptchaProvider); ok {
+		data.CaptchaScript, data.CaptchaClass = script, class
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := webFormTemplate.Execute(w, data); err != nil {
+		h.logger.Error("failed to render the web form", zap.Error(err))
+	}
+}
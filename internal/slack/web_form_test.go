@@ -0,0 +1,149 @@
+package slack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+const testFallbackUserID = "0123456789abcdef0123456789abcdef"
+
+func newWebFormTestHandler(backend *fakeBackend, cfg *config.Config) *Handler {
+	return NewHandlerWithDependencies(cfg, zap.NewNop(), Dependencies{
+		Backend:   backend,
+		Slack:     &fakeSlack{},
+		Responder: &fakeSlack{},
+		Clock:     fixedClock(time.Now()),
+	})
+}
+
+func webFormRequest(values url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/web/submit", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// TestHandleWebForm_Render tests that GET renders the form with the options and captcha widget
+func TestHandleWebForm_Render(t *testing.T) {
+	handler := newWebFormTestHandler(&fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}, &config.Config{
+		BasePath:             "/hopperbot",
+		NotionFallbackUserID: testFallbackUserID,
+		CaptchaProvider:      "turnstile",
+		CaptchaSiteKey:       "site-key",
+	})
+
+	w := httptest.NewRecorder()
+	handler.HandleWebForm(w, httptest.NewRequest(http.MethodGet, "/web/submit", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		`action="/hopperbot/web/submit"`,
+		"<option>Customer Pain Point</option>",
+		"<option>AI/ML</option>",
+		`https://challenges.cloudflare.com/turnstile/v0/api.js`,
+		`<div class="cf-turnstile" data-sitekey="site-key">`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("form doesn't contain %s:\n%s", want, body)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleWebForm(w, httptest.NewRequest(http.MethodPut, "/web/submit", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT status = %d, want 405", w.Code)
+	}
+}
+
+// TestHandleWebForm_Submit tests that a valid web submission is filed for the fallback user
+func TestHandleWebForm_Submit(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(map[string]string{"Acme Corp": "customer-page-acme"}, nil)}
+	handler := newWebFormTestHandler(backend, &config.Config{NotionFallbackUserID: testFallbackUserID})
+
+	w := httptest.NewRecorder()
+	handler.HandleWebForm(w, webFormRequest(url.Values{
+		"title":        {"Exports are slow"},
+		"theme":        {"customer pain point"},
+		"product_area": {"AI/ML"},
+		"customers":    {"acme corp, "},
+		"comments":     {"From the website"},
+		"email":        {"visitor@example.com"},
+	}))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Exports are slow") {
+		t.Fatalf("status = %d, body = %s; want the submission confirmed", w.Code, w.Body.String())
+	}
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if len(backend.submissions) != 1 {
+		t.Fatalf("expected 1 submission, got %d", len(backend.submissions))
+	}
+	sub := backend.submissions[0]
+	if sub.Theme != "Customer Pain Point" || len(sub.CustomerOrgIDs) != 1 || sub.CustomerOrgIDs[0] != "customer-page-acme" {
+		t.Errorf("submission = %+v, want options and customers matched", sub)
+	}
+	if sub.SubmitterNotionID != testFallbackUserID {
+		t.Errorf("SubmitterNotionID = %q, want the fallback user", sub.SubmitterNotionID)
+	}
+	if sub.Source.Channel != submission.ChannelWeb || sub.Source.SubmitterEmail != "visitor@example.com" {
+		t.Errorf("Source = %+v, want the web channel and the typed email", sub.Source)
+	}
+}
+
+// TestHandleWebForm_Rejected tests that invalid web submissions re-render the form without creating a page
+func TestHandleWebForm_Rejected(t *testing.T) {
+	valid := url.Values{
+		"title":        {"Exports are slow"},
+		"theme":        {"Customer Pain Point"},
+		"product_area": {"AI/ML"},
+		"email":        {"visitor@example.com"},
+	}
+	with := func(key, value string) url.Values {
+		values := url.Values{}
+		for k, v := range valid {
+			values[k] = v
+		}
+		values.Set(key, value)
+		return values
+	}
+
+	tests := []struct {
+		name       string
+		cfg        *config.Config
+		values     url.Values
+		wantStatus int
+		wantText   string
+	}{
+		{"no fallback user", &config.Config{}, valid, http.StatusServiceUnavailable, "aren&#39;t available"},
+		{"invalid email", &config.Config{NotionFallbackUserID: testFallbackUserID}, with("email", "visitor"), http.StatusBadRequest, "valid email"},
+		{"missing title", &config.Config{NotionFallbackUserID: testFallbackUserID}, with("title", ""), http.StatusBadRequest, "Title"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(nil, nil)}
+			handler := newWebFormTestHandler(backend, tt.cfg)
+
+			w := httptest.NewRecorder()
+			handler.HandleWebForm(w, webFormRequest(tt.values))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if body := w.Body.String(); !strings.Contains(body, tt.wantText) || !strings.Contains(body, `value="visitor`) {
+				t.Errorf("body = %s, want %q and the form re-rendered with its values", body, tt.wantText)
+			}
+			if len(backend.submissions) != 0 {
+				t.Errorf("rejected submission created %d pages", len(backend.submissions))
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package slack
+
+import (
+	"context"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/workers"
+	"go.uber.org/zap"
+)
+
+// SetWorkerPool runs the work that outlives a request (confirmations, DMs,
+// quick submissions, Events API callbacks, App Home refreshes) on pool.
+// Without one, e.g. in tests, each task gets a goroutine of its own.
+func (h *Handler) SetWorkerPool(pool *workers.Pool) {
+	h.workers = pool
+}
+
+// background runs task on the worker pool. While the pool is full it waits up
+// to constants.BackgroundSubmitTimeout for room, so a request still answers
+// within Slack's 3 seconds, then drops the task and logs it.
+func (h *Handler) background(name string, task workers.Task) {
+	if h.workers == nil {
+		go task()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.BackgroundSubmitTimeout)
+	defer cancel()
+	if err := h.workers.Submit(ctx, name, task); err != nil {
+		h.logger.Error("failed to run background task", zap.String("task", name), zap.Error(err))
+	}
+}
@@ -0,0 +1,60 @@
+package slack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/internal/notion"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/workers"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// TestBackground_WorkerPool tests that a quick submission is created on the
+// handler's worker pool, and that tasks are dropped once the pool is stopped
+func TestBackground_WorkerPool(t *testing.T) {
+	backend := &fakeBackend{snapshot: notion.NewCacheSnapshot(
+		map[string]string{"Acme Corp": "customer-page-acme"},
+		map[string]string{"alice@example.com": "notion-user-alice"},
+	)}
+	slackAPI := &fakeSlack{
+		users:     map[string]*slack.User{"U123": {ID: "U123", Profile: slack.UserProfile{Email: "alice@example.com"}}},
+		posted:    make(chan string, 10),
+		responded: make(chan string, 10),
+	}
+	handler := NewHandlerWithDependencies(&config.Config{SlackSigningSecret: "secret"}, zap.NewNop(),
+		Dependencies{Backend: backend, Slack: slackAPI, Responder: slackAPI})
+	pool := workers.New("test", 1, 1, nil, zap.NewNop())
+	handler.SetWorkerPool(pool)
+
+	body := url.Values{
+		"command":      {"/hopperbot"},
+		"text":         {`"Exports are slow" theme:"customer pain point" area:ai/ml customer:"acme corp"`},
+		"team_id":      {"T456"},
+		"user_id":      {"U123"},
+		"response_url": {"https://hooks.slack.com/commands/T456/1/abc"},
+	}.Encode()
+	w := httptest.NewRecorder()
+	handler.HandleSlashCommand(w, createValidSlackRequest(http.MethodPost, "/slack/command", []byte(body), "secret"))
+	if !strings.Contains(w.Body.String(), "Submitting") {
+		t.Fatalf("quick submission = %s, want it accepted", w.Body.String())
+	}
+	pool.Stop() // Runs the queued submission
+
+	backend.mu.Lock()
+	submitted := len(backend.submissions)
+	backend.mu.Unlock()
+	if submitted != 1 {
+		t.Fatalf("backend submissions = %d, want the submission created on the pool", submitted)
+	}
+
+	ran := false
+	handler.background("late", func() { ran = true })
+	if ran {
+		t.Error("task submitted to a stopped pool ran")
+	}
+}
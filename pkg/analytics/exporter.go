@@ -0,0 +1,305 @@
+// Package analytics streams submission events to an external analytics warehouse.
+//
+// Events are emitted by the Slack handler whenever a submission succeeds or fails
+// and are shipped in batches to a generic HTTP endpoint. The payload uses the
+// RudderStack/Segment batch format ({"batch": [...]}) so the endpoint can be a
+// RudderStack HTTP source (which forwards to Snowflake, BigQuery, etc.) or any
+// webhook that accepts JSON.
+//
+// Features:
+// - Non-blocking enqueue with a bounded buffer (events are dropped, not blocked, when full)
+// - Size- and interval-based flushing in a background goroutine
+// - Exponential backoff retry per batch with a bounded number of attempts
+// - Graceful shutdown that flushes any buffered events
+// - Metrics for exported, dropped, and failed events
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// Event names sent to the warehouse.
+const (
+	// EventSubmissionCreated is emitted when an idea was successfully written to Notion.
+	EventSubmissionCreated = "Idea Submitted"
+
+	// EventSubmissionUpdated is emitted when an existing idea was edited with /hopperbot edit.
+	EventSubmissionUpdated = "Idea Updated"
+
+	// EventSubmissionFailed is emitted when a submission was rejected or could not be written.
+	EventSubmissionFailed = "Idea Submission Failed"
+
+	// EventIdeaTriaged is emitted when the funnel tracker sees a submitted idea get its first status.
+	EventIdeaTriaged = "Idea Triaged"
+
+	// EventIdeaClosed is emitted when the funnel tracker sees an idea reach a closed status.
+	EventIdeaClosed = "Idea Closed"
+
+	// EventIdeaRemoved is emitted when the funnel tracker sees an idea's page deleted before a decision.
+	EventIdeaRemoved = "Idea Removed"
+)
+
+const (
+	// Retry configuration for a single batch
+	initialBackoff  = 1 * time.Second
+	backoffMultiple = 2
+	maxAttempts     = 5
+
+	// defaultBufferSize bounds the number of events held in memory awaiting export
+	defaultBufferSize = 1000
+)
+
+// Event is a single analytics event in RudderStack/Segment "track" format.
+//
+// UserID is the Slack user ID of the submitter. Properties carry the submission
+// fields (title, theme, product area, customer orgs) and outcome details so the
+// warehouse can join idea flow with customer data; funnel events (triaged,
+// closed, removed) carry the page ID, status and time taken instead.
+type Event struct {
+	Type       string                 `json:"type"`  // Always "track"
+	Event      string                 `json:"event"` // Event name (e.g., EventSubmissionCreated)
+	UserID     string                 `json:"userId"`
+	MessageID  string                 `json:"messageId,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// batchPayload is the request body sent to the analytics endpoint.
+type batchPayload struct {
+	Batch  []Event   `json:"batch"`
+	SentAt time.Time `json:"sentAt"`
+}
+
+// Config holds the exporter settings.
+type Config struct {
+	// Endpoint is the URL batches are POSTed to (e.g., https://<dataplane>/v1/batch).
+	Endpoint string
+
+	// WriteKey is sent as the basic auth username when set (RudderStack source write key).
+	WriteKey string
+
+	// BatchSize is the number of events that triggers an immediate flush.
+	BatchSize int
+
+	// FlushInterval is the maximum time an event waits in the buffer before being sent.
+	FlushInterval time.Duration
+}
+
+// Exporter batches events and ships them to the configured endpoint.
+//
+// Thread safety:
+// - Track may be called concurrently from request handlers
+// - A single background goroutine owns the batch and performs all HTTP calls
+// - Stop flushes remaining events and waits for the goroutine to exit
+type Exporter struct {
+	config     Config
+	httpClient *http.Client
+	events     chan Event
+	metrics    *metrics.Metrics
+	logger     *zap.Logger
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewExporter creates a new analytics exporter in a stopped state.
+//
+// Call Start() to begin the background flush loop. Events tracked before
+// Start() are buffered (up to the buffer size) and sent once started.
+func NewExporter(config Config, metrics *metrics.Metrics, logger *zap.Logger) *Exporter {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Exporter{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		events:     make(chan Event, defaultBufferSize),
+		metrics:    metrics,
+		logger:     logger,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Track enqueues an event for export without blocking.
+//
+// If the buffer is full (endpoint down for an extended period), the event is
+// dropped and counted in metrics rather than slowing down the submission path.
+// A nil Exporter is a no-op so callers don't need to check whether analytics is enabled.
+func (e *Exporter) Track(event Event) {
+	if e == nil {
+		return
+	}
+
+	if event.Type == "" {
+		event.Type = "track"
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	select {
+	case e.events <- event:
+	default:
+		e.recordEvents("dropped", 1)
+		e.logger.Warn("analytics buffer full, dropping event",
+			zap.String("event", event.Event),
+		)
+	}
+}
+
+// Start begins the background batching goroutine.
+func (e *Exporter) Start() {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(e.config.FlushInterval)
+		defer ticker.Stop()
+
+		batch := make([]Event, 0, e.config.BatchSize)
+
+		for {
+			select {
+			case event := <-e.events:
+				batch = append(batch, event)
+				if len(batch) >= e.config.BatchSize {
+					e.sendWithRetry(batch)
+					batch = make([]Event, 0, e.config.BatchSize)
+				}
+			case <-ticker.C:
+				if len(batch) > 0 {
+					e.sendWithRetry(batch)
+					batch = make([]Event, 0, e.config.BatchSize)
+				}
+			case <-e.ctx.Done():
+				// Drain whatever is still buffered and make a final best-effort send
+			drain:
+				for {
+					select {
+					case event := <-e.events:
+						batch = append(batch, event)
+					default:
+						break drain
+					}
+				}
+				if len(batch) > 0 {
+					if err := e.send(batch); err != nil {
+						e.recordEvents("failed", len(batch))
+						e.logger.Error("failed to flush analytics events on shutdown",
+							zap.Int("events", len(batch)),
+							zap.Error(err),
+						)
+					} else {
+						e.recordEvents("exported", len(batch))
+					}
+				}
+				return
+			}
+		}
+	}()
+}
+
+// Stop flushes buffered events and stops the background goroutine.
+func (e *Exporter) Stop() {
+	e.cancel()
+	e.wg.Wait()
+	e.logger.Info("analytics exporter stopped")
+}
+
+// sendWithRetry sends a batch, retrying with exponential backoff on failure.
+//
+// Gives up after maxAttempts and records the events as failed. Stops retrying
+// immediately when the exporter is shutting down.
+func (e *Exporter) sendWithRetry(batch []Event) {
+	backoff := initialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := e.send(batch)
+		if err == nil {
+			e.recordEvents("exported", len(batch))
+			return
+		}
+
+		if attempt >= maxAttempts {
+			e.recordEvents("failed", len(batch))
+			e.logger.Error("analytics batch export failed after retries",
+				zap.Int("events", len(batch)),
+				zap.Int("attempts", attempt),
+				zap.Error(err),
+			)
+			return
+		}
+
+		e.logger.Warn("analytics batch export failed, retrying with backoff",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		select {
+		case <-time.After(backoff):
+		case <-e.ctx.Done():
+			e.recordEvents("failed", len(batch))
+			return
+		}
+		backoff *= backoffMultiple
+	}
+}
+
+// send POSTs a single batch to the configured endpoint.
+func (e *Exporter) send(batch []Event) error {
+	body, err := json.Marshal(batchPayload{
+		Batch:  batch,
+		SentAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.config.WriteKey != "" {
+		req.SetBasicAuth(e.config.WriteKey, "")
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("analytics endpoint error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// recordEvents records the outcome of exported events.
+func (e *Exporter) recordEvents(status string, count int) {
+	if e.metrics == nil {
+		return
+	}
+	e.metrics.AnalyticsEventsTotal.WithLabelValues(status).Add(float64(count))
+}
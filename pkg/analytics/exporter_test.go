@@ -0,0 +1,182 @@
+package analytics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// recordingServer captures batches received by a fake analytics endpoint
+type recordingServer struct {
+	mu       sync.Mutex
+	batches  []batchPayload
+	failures int // Number of requests to fail before succeeding
+	calls    int
+	username string
+}
+
+func (rs *recordingServer) handler(w http.ResponseWriter, r *http.Request) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.calls++
+	rs.username, _, _ = r.BasicAuth()
+
+	if rs.calls <= rs.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var payload batchPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	rs.batches = append(rs.batches, payload)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (rs *recordingServer) eventCount() int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	count := 0
+	for _, b := range rs.batches {
+		count += len(b.Batch)
+	}
+	return count
+}
+
+// TestTrack_NilExporter verifies a nil exporter is a safe no-op
+func TestTrack_NilExporter(t *testing.T) {
+	var exporter *Exporter
+	exporter.Track(Event{Event: EventSubmissionCreated, UserID: "U123"})
+}
+
+// TestExporter_FlushOnBatchSize verifies a full batch is sent immediately
+func TestExporter_FlushOnBatchSize(t *testing.T) {
+	rs := &recordingServer{}
+	server := httptest.NewServer(http.HandlerFunc(rs.handler))
+	defer server.Close()
+
+	exporter := NewExporter(Config{
+		Endpoint:      server.URL,
+		WriteKey:      "write-key",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	}, nil, zap.NewNop())
+	exporter.Start()
+	defer exporter.Stop()
+
+	exporter.Track(Event{Event: EventSubmissionCreated, UserID: "U1"})
+	exporter.Track(Event{Event: EventSubmissionFailed, UserID: "U2"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for rs.eventCount() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := rs.eventCount(); got != 2 {
+		t.Fatalf("expected 2 exported events, got %d", got)
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.username != "write-key" {
+		t.Errorf("expected write key as basic auth username, got %q", rs.username)
+	}
+	event := rs.batches[0].Batch[0]
+	if event.Type != "track" {
+		t.Errorf("expected type 'track', got %q", event.Type)
+	}
+	if event.Timestamp.IsZero() {
+		t.Error("expected timestamp to be populated")
+	}
+}
+
+// TestExporter_FlushOnStop verifies buffered events are flushed during shutdown
+func TestExporter_FlushOnStop(t *testing.T) {
+	rs := &recordingServer{}
+	server := httptest.NewServer(http.HandlerFunc(rs.handler))
+	defer server.Close()
+
+	exporter := NewExporter(Config{
+		Endpoint:      server.URL,
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+	}, nil, zap.NewNop())
+	exporter.Start()
+
+	exporter.Track(Event{Event: EventSubmissionCreated, UserID: "U1"})
+	exporter.Stop()
+
+	if got := rs.eventCount(); got != 1 {
+		t.Errorf("expected 1 event flushed on stop, got %d", got)
+	}
+}
+
+// TestExporter_RetryOnFailure verifies transient endpoint failures are retried
+func TestExporter_RetryOnFailure(t *testing.T) {
+	rs := &recordingServer{failures: 1}
+	server := httptest.NewServer(http.HandlerFunc(rs.handler))
+	defer server.Close()
+
+	exporter := NewExporter(Config{
+		Endpoint:      server.URL,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+	}, nil, zap.NewNop())
+	exporter.Start()
+	defer exporter.Stop()
+
+	exporter.Track(Event{Event: EventSubmissionCreated, UserID: "U1"})
+
+	// First attempt fails, retry happens after initialBackoff (1s)
+	deadline := time.Now().Add(3 * time.Second)
+	for rs.eventCount() < 1 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if got := rs.eventCount(); got != 1 {
+		t.Fatalf("expected event to be exported after retry, got %d", got)
+	}
+}
+
+// TestExporter_DropWhenBufferFull verifies Track never blocks when the buffer is full
+func TestExporter_DropWhenBufferFull(t *testing.T) {
+	exporter := NewExporter(Config{Endpoint: "http://127.0.0.1:0"}, nil, zap.NewNop())
+
+	// Not started, so nothing drains the buffer
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < defaultBufferSize+10; i++ {
+			exporter.Track(Event{Event: EventSubmissionCreated, UserID: "U1"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Track blocked when buffer was full")
+	}
+
+	if len(exporter.events) != defaultBufferSize {
+		t.Errorf("expected buffer to hold %d events, got %d", defaultBufferSize, len(exporter.events))
+	}
+}
+
+// TestNewExporter_Defaults verifies default batch size and flush interval
+func TestNewExporter_Defaults(t *testing.T) {
+	exporter := NewExporter(Config{Endpoint: "http://example.com"}, nil, zap.NewNop())
+
+	if exporter.config.BatchSize != 100 {
+		t.Errorf("expected default batch size 100, got %d", exporter.config.BatchSize)
+	}
+	if exporter.config.FlushInterval != 30*time.Second {
+		t.Errorf("expected default flush interval 30s, got %v", exporter.config.FlushInterval)
+	}
+}
@@ -1,13 +1,17 @@
 // Package cache provides cache management with automatic refresh capabilities.
 //
-// The Manager handles periodic and manual refresh of two critical caches:
+// The Manager handles periodic and manual refresh of named caches. Two critical
+// caches are registered from the CacheRefresher given to NewManager:
 // 1. Customer cache - Valid customer organization names from Notion Customers database
 // 2. User cache - Notion workspace users for Slack-to-Notion user mapping
 //
+// Other caches plug into the same retry and metrics machinery with Register.
+//
 // Features:
-// - Automatic periodic refresh in background goroutine
+// - Automatic periodic refresh as a pkg/scheduler job (see Schedule)
 // - Manual refresh on-demand (non-blocking)
 // - Exponential backoff retry with configurable window
+// - Optional background initialization for lazy startup (SetInitializer)
 // - Graceful shutdown with context cancellation
 // - Comprehensive metrics and structured logging
 // - Thread-safe with proper coordination via sync.WaitGroup
@@ -15,11 +19,15 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/rudderlabs/hopperbot/pkg/logging"
 	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/scheduler"
 	"go.uber.org/zap"
 )
 
@@ -28,13 +36,55 @@ const (
 	CacheTypeCustomers = "customers"
 	// CacheTypeUsers identifies the user cache type in metrics and logs
 	CacheTypeUsers = "users"
+	// CacheTypeSlackUsers identifies the Slack user email cache (registered by main)
+	CacheTypeSlackUsers = "slack_users"
 
 	// Retry configuration
 	initialBackoff  = 3 * time.Second // Start with 3 second delay
 	backoffMultiple = 2               // Double the backoff each retry
 	maxRetryWindow  = 5 * time.Minute // Stop retrying after 5 minutes
+
+	// maxInitializeBackoff caps the delay between initialization attempts, which
+	// never give up (see SetInitializer)
+	maxInitializeBackoff = time.Minute
 )
 
+// ErrStopped is returned by ManualRefreshAndWait once the manager has been stopped.
+var ErrStopped = errors.New("cache manager stopped")
+
+// RefreshFunc refreshes one cache. The context is cancelled when the manager stops.
+type RefreshFunc func(ctx context.Context) error
+
+// refresher is a cache registered with the manager.
+type refresher struct {
+	name    string // cache_type label in metrics and logs
+	refresh RefreshFunc
+}
+
+// CacheResult is the outcome of refreshing one cache.
+type CacheResult struct {
+	Name     string        // Cache name (cache_type label)
+	Duration time.Duration // Including retries and backoff
+	Err      error         // nil on success; the last error once retries are exhausted
+}
+
+// RefreshReport is the outcome of refreshing every registered cache.
+type RefreshReport struct {
+	Caches   []CacheResult // In refresh order
+	Duration time.Duration
+}
+
+// Failed returns the caches that failed to refresh.
+func (r RefreshReport) Failed() []CacheResult {
+	var failed []CacheResult
+	for _, result := range r.Caches {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
 // CacheRefresher defines the interface for cache initialization operations.
 //
 // Implementations should handle fetching data from external sources
@@ -47,37 +97,53 @@ type CacheRefresher interface {
 	InitializeUsers() error
 }
 
+// Clock abstracts the passage of time for the manager, so tests can run it
+// through virtual time. The default is the system clock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the default Clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
 // Manager orchestrates automatic and manual cache refresh operations.
 //
-// The manager runs a background goroutine that periodically refreshes both
-// caches (customers and users) by calling the CacheRefresher's Initialize methods.
-// On failure, it implements exponential backoff retry up to a configurable window.
+// A scheduled job (see Schedule) periodically refreshes every cache
+// (customers, users and any registered ones). On failure, it implements
+// exponential backoff retry up to a configurable window.
 //
 // Thread safety:
-// - Background goroutine is the only one calling refresh methods
 // - CacheRefresher implementations handle their own locking internally
-// - Context cancellation stops the background goroutine gracefully
+// - Context cancellation stops refreshes and initialization gracefully
 // - WaitGroup ensures proper shutdown coordination
 type Manager struct {
-	refresher       CacheRefresher   // Interface for cache operations
+	refresher       CacheRefresher   // Interface for the built-in caches (may be nil)
+	refreshers      []refresher      // Registered caches, refreshed in registration order
 	metrics         *metrics.Metrics // For recording cache refresh metrics
 	logger          *zap.Logger      // Structured logging
-	refreshInterval time.Duration    // How often to refresh (from config)
-	ticker          *time.Ticker     // For periodic refresh
+	refreshInterval atomic.Int64     // How often to refresh, as a time.Duration (see SetRefreshInterval)
+	clock           Clock            // Source of time for backoff and metrics
 	ctx             context.Context  // For cancellation
 	cancel          context.CancelFunc
 	wg              sync.WaitGroup // To wait for goroutine completion
+	initialize      func() error   // Run until it succeeds before refreshes begin (lazy startup)
+	initializing    atomic.Bool    // Set while initialize hasn't succeeded yet
 }
 
 // NewManager creates a new cache manager.
 //
 // Parameters:
-// - refresher: Implementation with InitializeCustomers() and InitializeUsers() methods
+// - refresher: Registered as CacheTypeCustomers and CacheTypeUsers (nil registers nothing)
 // - metrics: Metrics instance for recording refresh operations
 // - logger: Zap logger for structured logging
 // - refreshInterval: How often to refresh caches (e.g., 1 hour)
 //
-// The manager is created in a stopped state. Call Start() to begin automatic refresh.
+// The manager is created in a stopped state. Call Schedule to refresh the caches
+// periodically, and Start() to run the initializer, if any.
 func NewManager(
 	refresher CacheRefresher,
 	metrics *metrics.Metrics,
@@ -86,57 +152,123 @@ func NewManager(
 ) *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Manager{
-		refresher:       refresher,
-		metrics:         metrics,
-		logger:          logger,
-		refreshInterval: refreshInterval,
-		ctx:             ctx,
-		cancel:          cancel,
+	m := &Manager{
+		refresher: refresher,
+		metrics:   metrics,
+		logger:    logger,
+		clock:     systemClock{},
+		ctx:       ctx,
+		cancel:    cancel,
 	}
+	m.refreshInterval.Store(int64(refreshInterval))
+	if refresher != nil {
+		m.Register(CacheTypeCustomers, func(context.Context) error { return refresher.InitializeCustomers() })
+		m.Register(CacheTypeUsers, func(context.Context) error { return refresher.InitializeUsers() })
+	}
+	return m
 }
 
-// Start begins the background cache refresh goroutine.
-//
-// The goroutine runs until Stop() is called or the context is cancelled.
-// It refreshes both caches on each tick, implementing retry logic on failures.
+// Register adds a named cache to refresh on every cycle, after those already
+// registered. The name labels its metrics (cache_type) and logs, so it should
+// be short and stable, e.g. "templates". It must be called before Start.
+func (m *Manager) Register(name string, refresh RefreshFunc) {
+	m.refreshers = append(m.refreshers, refresher{name: name, refresh: refresh})
+}
+
+// SetClock replaces the system clock, e.g. with a virtual clock in tests.
+// It must be called before Start.
+func (m *Manager) SetClock(clock Clock) {
+	m.clock = clock
+}
+
+// SetInitializer makes Start run initialize in the background, retrying it
+// with exponential backoff (capped at one minute) until it succeeds; scheduled
+// refreshes are skipped until then. It supports lazy startup: the server comes up while
+// Notion is unavailable and initialization completes once it recovers.
+// It must be called before Start.
+func (m *Manager) SetInitializer(initialize func() error) {
+	m.initialize = initialize
+	m.initializing.Store(initialize != nil)
+}
+
+// Initialized reports whether the initializer set with SetInitializer has
+// succeeded. It is always true without an initializer.
+func (m *Manager) Initialized() bool {
+	return !m.initializing.Load()
+}
+
+// RefreshInterval returns how often the caches are refreshed.
+func (m *Manager) RefreshInterval() time.Duration {
+	return time.Duration(m.refreshInterval.Load())
+}
+
+// SetRefreshInterval changes the refresh interval, e.g. on a configuration
+// reload. It applies from the wait after the one in progress.
+func (m *Manager) SetRefreshInterval(interval time.Duration) {
+	m.refreshInterval.Store(int64(interval))
+}
+
+// Schedule registers the periodic refresh of every cache with jobs, as the
+// "cache-refresh" job run every refresh interval (see ScheduledRefresh).
+func (m *Manager) Schedule(jobs *scheduler.Scheduler) {
+	jobs.Add("cache-refresh", scheduler.EveryFunc(m.RefreshInterval), m.ScheduledRefresh)
+}
+
+// Start runs the initializer set with SetInitializer in the background, if any.
 //
-// This method returns immediately - the refresh happens in the background.
-// Call Stop() to gracefully shut down the background goroutine.
+// This method returns immediately. Call Stop() to cancel initialization and
+// refreshes in progress.
 func (m *Manager) Start() {
-	m.ticker = time.NewTicker(m.refreshInterval)
+	m.logger.Info("cache manager started",
+		zap.Duration("refresh_interval", m.RefreshInterval()),
+	)
+	if m.initialize == nil {
+		return
+	}
 
 	m.wg.Add(1)
 	go func() {
 		defer m.wg.Done()
-		defer m.ticker.Stop()
+		m.initializeWithRetry()
+	}()
+}
 
-		m.logger.Info("cache manager started",
-			zap.Duration("refresh_interval", m.refreshInterval),
-		)
+// ScheduledRefresh is the periodic refresh job: it refreshes every cache with
+// retries and returns the errors of those that failed. It is cancelled when
+// ctx is done or the manager stops, and does nothing until the initializer set
+// with SetInitializer has succeeded.
+func (m *Manager) ScheduledRefresh(ctx context.Context) error {
+	if m.ctx.Err() != nil {
+		return ErrStopped
+	}
+	if !m.Initialized() {
+		logging.FromContext(ctx, m.logger).Debug("periodic cache refresh skipped - initialization pending")
+		return nil
+	}
 
-		for {
-			select {
-			case <-m.ticker.C:
-				m.logger.Debug("periodic cache refresh triggered")
-				m.refreshAll()
-			case <-m.ctx.Done():
-				m.logger.Info("cache manager stopping due to context cancellation")
-				return
-			}
-		}
-	}()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(m.ctx, cancel)
+	defer stop()
+
+	logging.FromContext(ctx, m.logger).Debug("periodic cache refresh triggered")
+	report := m.refreshAll(ctx)
+
+	var errs []error
+	for _, result := range report.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %w", result.Name, result.Err))
+	}
+	return errors.Join(errs...)
 }
 
 // Stop gracefully shuts down the cache manager.
 //
-// Cancels the context to stop the background goroutine, stops the ticker,
-// and waits for the goroutine to complete before returning.
-//
-// This ensures no refresh operations are in progress when Stop() returns.
+// Cancels the context to stop initialization and refreshes, and waits for
+// manual refreshes and initialization to complete before returning. Scheduled
+// refreshes are cancelled too; the scheduler waits for them.
 func (m *Manager) Stop() {
 	m.logger.Info("cache manager shutdown initiated")
-	m.cancel() // Signal the goroutine to stop
+	m.cancel() // Signal initialization and refreshes to stop
 	m.wg.Wait()
 	m.logger.Info("cache manager shutdown complete")
 }
@@ -155,37 +287,100 @@ func (m *Manager) ManualRefresh() {
 		return
 	default:
 		m.logger.Info("manual cache refresh triggered")
-		// Run in separate goroutine so we don't block the caller
-		go m.refreshAll()
+		// Run in separate goroutine so we don't block the caller; Stop waits for it
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.refreshAll(m.ctx)
+		}()
 	}
 }
 
-// refreshAll refreshes both caches sequentially with retry logic.
-//
-// Order of operations:
-// 1. Refresh customers cache (with retries)
-// 2. Refresh users cache (with retries)
-//
-// Each cache refresh is independent - failure of one doesn't prevent the other.
-// On failure, the old cache is retained (handled by CacheRefresher.Initialize methods).
-func (m *Manager) refreshAll() {
-	m.logger.Info("refreshing all caches")
-
-	// Refresh customers cache first
-	if err := m.refreshCacheWithRetry(CacheTypeCustomers, m.refresher.InitializeCustomers); err != nil {
-		m.logger.Error("customers cache refresh failed after retries",
+// initializeWithRetry runs the initializer until it succeeds, backing off
+// exponentially between attempts, until the manager is stopped.
+func (m *Manager) initializeWithRetry() {
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := m.initialize()
+		if err == nil {
+			m.initializing.Store(false)
+			m.logger.Info("background initialization succeeded", zap.Int("attempt", attempt))
+			return
+		}
+
+		m.logger.Warn("background initialization failed, retrying with backoff",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
 			zap.Error(err),
 		)
+		select {
+		case <-m.clock.After(backoff):
+		case <-m.ctx.Done():
+			m.logger.Info("background initialization cancelled", zap.Int("attempt", attempt))
+			return
+		}
+		backoff = min(backoff*backoffMultiple, maxInitializeBackoff)
 	}
+}
 
-	// Refresh users cache
-	if err := m.refreshCacheWithRetry(CacheTypeUsers, m.refresher.InitializeUsers); err != nil {
-		m.logger.Error("users cache refresh failed after retries",
-			zap.Error(err),
-		)
+// ManualRefreshAndWait refreshes every cache like ManualRefresh, but waits for
+// the refresh to finish and reports the outcome of each cache.
+//
+// Returns ErrStopped if the manager has been stopped, or ctx's error if ctx is
+// done first; the refresh itself then still runs to completion in the background.
+func (m *Manager) ManualRefreshAndWait(ctx context.Context) (RefreshReport, error) {
+	select {
+	case <-m.ctx.Done():
+		return RefreshReport{}, ErrStopped
+	default:
 	}
 
-	m.logger.Info("cache refresh cycle complete")
+	logger := logging.FromContext(ctx, m.logger)
+	logger.Info("manual cache refresh triggered, waiting for completion")
+	done := make(chan RefreshReport, 1) // Buffered so the refresh never blocks on an abandoned wait
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		// Runs on the manager's context, but logs with the caller's request fields
+		done <- m.refreshAll(logging.NewContext(m.ctx, logger))
+	}()
+
+	select {
+	case report := <-done:
+		return report, nil
+	case <-ctx.Done():
+		return RefreshReport{}, ctx.Err()
+	}
+}
+
+// refreshAll refreshes the registered caches sequentially with retry logic,
+// in registration order (customers, then users, then any added with Register),
+// and reports the outcome of each.
+//
+// Each cache refresh is independent - failure of one doesn't prevent the others.
+// On failure, the old cache is retained (handled by the refresh functions).
+// Cancelling ctx stops retrying.
+func (m *Manager) refreshAll(ctx context.Context) RefreshReport {
+	logger := logging.FromContext(ctx, m.logger)
+	logger.Info("refreshing all caches")
+	start := m.clock.Now()
+
+	report := RefreshReport{Caches: make([]CacheResult, 0, len(m.refreshers))}
+	for _, r := range m.refreshers {
+		cacheStart := m.clock.Now()
+		err := m.refreshCacheWithRetry(ctx, r.name, func() error { return r.refresh(ctx) })
+		if err != nil {
+			logger.Error("cache refresh failed after retries",
+				zap.String("cache_type", r.name),
+				zap.Error(err),
+			)
+		}
+		report.Caches = append(report.Caches, CacheResult{Name: r.name, Duration: m.clock.Now().Sub(cacheStart), Err: err})
+	}
+	report.Duration = m.clock.Now().Sub(start)
+
+	logger.Info("cache refresh cycle complete", zap.Int("failed", len(report.Failed())))
+	return report
 }
 
 // refreshCacheWithRetry refreshes a single cache with exponential backoff retry.
@@ -207,22 +402,23 @@ func (m *Manager) refreshAll() {
 // - Logs error
 // - Returns error
 //
-// Thread safety: Only called from background goroutine or ManualRefresh goroutine.
-func (m *Manager) refreshCacheWithRetry(cacheType string, refreshFunc func() error) error {
-	startTime := time.Now()
+// Thread safety: Only called from the scheduled refresh or ManualRefresh goroutine.
+func (m *Manager) refreshCacheWithRetry(ctx context.Context, cacheType string, refreshFunc func() error) error {
+	logger := logging.FromContext(ctx, m.logger)
+	startTime := m.clock.Now()
 	attempt := 1
 	backoffDuration := initialBackoff
 
 	for {
 		// Attempt refresh
-		attemptStart := time.Now()
+		attemptStart := m.clock.Now()
 		err := refreshFunc()
-		duration := time.Since(attemptStart)
+		duration := m.clock.Now().Sub(attemptStart)
 
 		if err == nil {
 			// Success! Record metrics and return
 			m.recordSuccess(cacheType, duration)
-			m.logger.Info("cache refresh succeeded",
+			logger.Info("cache refresh succeeded",
 				zap.String("cache_type", cacheType),
 				zap.Int("attempt", attempt),
 				zap.Duration("duration", duration),
@@ -231,12 +427,12 @@ func (m *Manager) refreshCacheWithRetry(cacheType string, refreshFunc func() err
 		}
 
 		// Check if we've exceeded the retry window
-		if time.Since(startTime) >= maxRetryWindow {
+		if m.clock.Now().Sub(startTime) >= maxRetryWindow {
 			// Record final failure after all retries exhausted
 			m.recordFailure(cacheType)
-			m.logger.Error("cache refresh failed after max retry window",
+			logger.Error("cache refresh failed after max retry window",
 				zap.String("cache_type", cacheType),
-				zap.Duration("total_time", time.Since(startTime)),
+				zap.Duration("total_time", m.clock.Now().Sub(startTime)),
 				zap.Int("attempts", attempt),
 				zap.Error(err),
 			)
@@ -247,7 +443,7 @@ func (m *Manager) refreshCacheWithRetry(cacheType string, refreshFunc func() err
 		m.recordRetry(cacheType)
 
 		// Log warning about retry
-		m.logger.Warn("cache refresh failed, retrying with backoff",
+		logger.Warn("cache refresh failed, retrying with backoff",
 			zap.String("cache_type", cacheType),
 			zap.Int("attempt", attempt),
 			zap.Duration("backoff", backoffDuration),
@@ -256,15 +452,15 @@ func (m *Manager) refreshCacheWithRetry(cacheType string, refreshFunc func() err
 
 		// Exponential backoff with context cancellation check
 		select {
-		case <-time.After(backoffDuration):
+		case <-m.clock.After(backoffDuration):
 			// Continue with retry
-		case <-m.ctx.Done():
+		case <-ctx.Done():
 			// Context cancelled, stop retrying
-			m.logger.Info("cache refresh cancelled during backoff",
+			logger.Info("cache refresh cancelled during backoff",
 				zap.String("cache_type", cacheType),
 				zap.Int("attempt", attempt),
 			)
-			return m.ctx.Err()
+			return ctx.Err()
 		}
 
 		// Exponential backoff
@@ -286,7 +482,7 @@ func (m *Manager) recordSuccess(cacheType string, duration time.Duration) {
 
 	m.metrics.CacheRefreshTotal.WithLabelValues(cacheType, "success").Inc()
 	m.metrics.CacheRefreshDuration.WithLabelValues(cacheType).Observe(duration.Seconds())
-	m.metrics.CacheLastRefreshTimestamp.WithLabelValues(cacheType).Set(float64(time.Now().Unix()))
+	m.metrics.CacheLastRefreshTimestamp.WithLabelValues(cacheType).Set(float64(m.clock.Now().Unix()))
 }
 
 // recordFailure records failure metrics when cache refresh retries are exhausted.
@@ -1,11 +1,13 @@
 package cache
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/rudderlabs/hopperbot/pkg/scheduler"
 	"go.uber.org/zap"
 )
 
@@ -80,8 +82,8 @@ func TestNewManager(t *testing.T) {
 		t.Error("logger not set correctly")
 	}
 
-	if mgr.refreshInterval != interval {
-		t.Errorf("refreshInterval = %v, want %v", mgr.refreshInterval, interval)
+	if mgr.RefreshInterval() != interval {
+		t.Errorf("RefreshInterval() = %v, want %v", mgr.RefreshInterval(), interval)
 	}
 
 	if mgr.ctx == nil {
@@ -113,18 +115,96 @@ func TestStartStop(t *testing.T) {
 	// Verify stop completed (if this hangs, wg.Wait() has an issue)
 }
 
-// TestPeriodicRefresh verifies automatic periodic refresh
+// TestInitializer verifies lazy startup: initialization is retried in the
+// background until it succeeds, and scheduled refreshes are skipped until then
+func TestInitializer(t *testing.T) {
+	mockRef := &mockRefresher{}
+	clock := newVirtualClock(time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC))
+	start := clock.Now()
+
+	var attempts int
+	release := make(chan struct{})
+	mgr := NewManager(mockRef, nil, zap.NewNop(), time.Hour)
+	mgr.SetClock(clock)
+	mgr.SetInitializer(func() error {
+		attempts++
+		if attempts < 8 {
+			return errors.New("notion unavailable")
+		}
+		<-release
+		return nil
+	})
+	if mgr.Initialized() {
+		t.Fatal("Initialized() = true before the initializer ran")
+	}
+
+	mgr.Start()
+	if err := mgr.ScheduledRefresh(context.Background()); err != nil {
+		t.Errorf("ScheduledRefresh() during initialization error = %v", err)
+	}
+	if customers, users := mockRef.getCallCounts(); customers != 0 || users != 0 {
+		t.Errorf("refresh calls = %d, %d during initialization, want 0, 0", customers, users)
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for !mgr.Initialized() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !mgr.Initialized() {
+		t.Fatal("Initialized() = false after the initializer succeeded")
+	}
+	if err := mgr.ScheduledRefresh(context.Background()); err != nil {
+		t.Errorf("ScheduledRefresh() error = %v", err)
+	}
+	mgr.Stop()
+
+	// 3s, 6s, 12s, 24s, 48s, then capped at 1m
+	if waited := clock.Now().Sub(start); waited != 93*time.Second+2*maxInitializeBackoff {
+		t.Errorf("waited %v between attempts, want backoff capped at %v", waited, maxInitializeBackoff)
+	}
+	if customers, users := mockRef.getCallCounts(); customers != 1 || users != 1 {
+		t.Errorf("refresh calls = %d, %d after initialization, want 1, 1", customers, users)
+	}
+}
+
+// TestInitializerStop verifies Stop cancels initialization that never succeeds
+func TestInitializerStop(t *testing.T) {
+	clock := newVirtualClock(time.Now())
+	clock.freeze()
+	failed := make(chan struct{}, 1)
+
+	mgr := NewManager(&mockRefresher{}, nil, zap.NewNop(), time.Hour)
+	mgr.SetClock(clock)
+	mgr.SetInitializer(func() error {
+		failed <- struct{}{}
+		return errors.New("notion unavailable")
+	})
+	mgr.Start()
+	<-failed
+	mgr.Stop()
+
+	if mgr.Initialized() {
+		t.Error("Initialized() = true although the initializer never succeeded")
+	}
+}
+
+// TestPeriodicRefresh verifies automatic periodic refresh through the scheduler
 func TestPeriodicRefresh(t *testing.T) {
 	mockRef := &mockRefresher{}
 	logger := zap.NewNop()
 	interval := 50 * time.Millisecond // Very short interval for testing
 
 	mgr := NewManager(mockRef, nil, logger, interval)
+	jobs := scheduler.New(nil, logger)
+	mgr.Schedule(jobs)
 	mgr.Start()
+	jobs.Start()
 
 	// Wait for at least 2 refresh cycles
 	time.Sleep(150 * time.Millisecond)
 
+	jobs.Stop()
 	mgr.Stop()
 
 	customers, users := mockRef.getCallCounts()
@@ -137,6 +217,89 @@ func TestPeriodicRefresh(t *testing.T) {
 	if users < 2 {
 		t.Errorf("InitializeUsers called %d times, want at least 2", users)
 	}
+
+	// Once stopped, scheduled refreshes report it instead of refreshing
+	if err := mgr.ScheduledRefresh(context.Background()); !errors.Is(err, ErrStopped) {
+		t.Errorf("ScheduledRefresh() after Stop error = %v, want ErrStopped", err)
+	}
+}
+
+// TestRegister verifies registered caches are refreshed after the built-in
+// ones, with retries, and get the manager's context
+func TestRegister(t *testing.T) {
+	mockRef := &mockRefresher{}
+	mgr := NewManager(mockRef, nil, zap.NewNop(), time.Hour)
+	mgr.SetClock(newVirtualClock(time.Now()))
+
+	var order []string
+	var attempts int
+	mgr.Register("templates", func(ctx context.Context) error {
+		if ctx == nil || ctx.Err() != nil {
+			t.Error("refresh called without a live context")
+		}
+		attempts++
+		if attempts == 1 {
+			return errors.New("temporary failure")
+		}
+		order = append(order, "templates")
+		return nil
+	})
+	mgr.Register("tenants", func(context.Context) error {
+		order = append(order, "tenants")
+		return nil
+	})
+
+	mgr.refreshAll(mgr.ctx)
+
+	if customers, users := mockRef.getCallCounts(); customers != 1 || users != 1 {
+		t.Errorf("built-in refresh calls = %d, %d, want 1, 1", customers, users)
+	}
+	if attempts != 2 || len(order) != 2 || order[0] != "templates" || order[1] != "tenants" {
+		t.Errorf("registered refreshes = %v after %d attempts, want templates (retried) then tenants", order, attempts)
+	}
+
+	// Without a CacheRefresher only registered caches are refreshed
+	empty := NewManager(nil, nil, zap.NewNop(), time.Hour)
+	empty.refreshAll(empty.ctx)
+}
+
+// TestManualRefreshAndWait verifies the report of a waited-for manual refresh
+func TestManualRefreshAndWait(t *testing.T) {
+	mockRef := &mockRefresher{
+		customersErr:       errors.New("notion unavailable"),
+		customersFailUntil: 1000, // Never recovers
+	}
+	clock := newVirtualClock(time.Now())
+	mgr := NewManager(mockRef, nil, zap.NewNop(), time.Hour)
+	mgr.SetClock(clock)
+
+	report, err := mgr.ManualRefreshAndWait(context.Background())
+	if err != nil {
+		t.Fatalf("ManualRefreshAndWait() error = %v", err)
+	}
+	if len(report.Caches) != 2 || report.Caches[0].Name != CacheTypeCustomers || report.Caches[1].Name != CacheTypeUsers {
+		t.Fatalf("report caches = %+v, want customers then users", report.Caches)
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Name != CacheTypeCustomers || !errors.Is(failed[0].Err, mockRef.customersErr) {
+		t.Errorf("Failed() = %+v, want only customers", failed)
+	}
+	if failed[0].Duration < maxRetryWindow || report.Duration < failed[0].Duration {
+		t.Errorf("durations = %v (customers), %v (total), want the retry window included", failed[0].Duration, report.Duration)
+	}
+
+	// A done context stops the wait, not the refresh (held in backoff until Stop)
+	clock.freeze()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := mgr.ManualRefreshAndWait(ctx); err == nil {
+		t.Error("ManualRefreshAndWait() with a cancelled context error = nil")
+	}
+
+	mgr.Stop()
+	if _, err := mgr.ManualRefreshAndWait(context.Background()); !errors.Is(err, ErrStopped) {
+		t.Errorf("ManualRefreshAndWait() after Stop error = %v, want ErrStopped", err)
+	}
 }
 
 // TestManualRefresh verifies manual refresh trigger
@@ -173,7 +336,7 @@ func TestRefreshAllSuccessPath(t *testing.T) {
 	mgr := NewManager(mockRef, nil, logger, interval)
 
 	// Call refreshAll directly
-	mgr.refreshAll()
+	mgr.refreshAll(mgr.ctx)
 
 	customers, users := mockRef.getCallCounts()
 
@@ -204,7 +367,7 @@ func TestRefreshAllCustomersFailure(t *testing.T) {
 	mgr := NewManager(mockRef, nil, logger, interval)
 
 	// Call refreshAll - it should try customers and users independently
-	mgr.refreshAll()
+	mgr.refreshAll(mgr.ctx)
 
 	customers, users := mockRef.getCallCounts()
 
@@ -227,7 +390,7 @@ func TestRefreshCacheWithRetrySuccess(t *testing.T) {
 
 	mgr := NewManager(mockRef, nil, logger, interval)
 
-	err := mgr.refreshCacheWithRetry(CacheTypeCustomers, mockRef.InitializeCustomers)
+	err := mgr.refreshCacheWithRetry(mgr.ctx, CacheTypeCustomers, mockRef.InitializeCustomers)
 
 	if err != nil {
 		t.Errorf("refreshCacheWithRetry returned error: %v", err)
@@ -251,7 +414,7 @@ func TestRefreshCacheWithRetryTransientFailure(t *testing.T) {
 
 	mgr := NewManager(mockRef, nil, logger, interval)
 
-	err := mgr.refreshCacheWithRetry(CacheTypeCustomers, mockRef.InitializeCustomers)
+	err := mgr.refreshCacheWithRetry(mgr.ctx, CacheTypeCustomers, mockRef.InitializeCustomers)
 
 	if err != nil {
 		t.Errorf("refreshCacheWithRetry returned error after recovery: %v", err)
@@ -284,7 +447,7 @@ func TestRefreshCacheWithRetryPermanentFailure(t *testing.T) {
 	// Note: This will take up to 5 minutes in production
 	// In practice, you'd mock time or reduce maxRetryWindow for testing
 	startTime := time.Now()
-	err := mgr.refreshCacheWithRetry(CacheTypeCustomers, mockRef.InitializeCustomers)
+	err := mgr.refreshCacheWithRetry(mgr.ctx, CacheTypeCustomers, mockRef.InitializeCustomers)
 
 	if err == nil {
 		t.Error("refreshCacheWithRetry should return error after max retries")
@@ -314,7 +477,7 @@ func TestRefreshCacheWithRetryContextCancellation(t *testing.T) {
 	}()
 
 	startTime := time.Now()
-	err := mgr.refreshCacheWithRetry(CacheTypeCustomers, mockRef.InitializeCustomers)
+	err := mgr.refreshCacheWithRetry(mgr.ctx, CacheTypeCustomers, mockRef.InitializeCustomers)
 
 	if err == nil {
 		t.Error("refreshCacheWithRetry should return error when context cancelled")
@@ -489,7 +652,7 @@ func BenchmarkRefreshAllSuccess(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		mockRef.resetCallCounts()
-		mgr.refreshAll()
+		mgr.refreshAll(mgr.ctx)
 	}
 }
 
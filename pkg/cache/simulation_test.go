@@ -0,0 +1,356 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rudderlabs/hopperbot/pkg/health"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// virtualClock is a Clock whose time only moves when the simulation says so.
+//
+// Waiting is instantaneous: After advances virtual time by the wait and
+// returns a channel that has already fired, so a refresh with backoff runs to
+// completion without real sleeps. Once frozen, time stops and After never fires, which lets a shutdown win
+// the backoff select deterministically.
+type virtualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	frozen bool
+}
+
+func newVirtualClock(start time.Time) *virtualClock {
+	return &virtualClock{now: start}
+}
+
+func (c *virtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *virtualClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if !c.frozen {
+		c.now = c.now.Add(d)
+		ch <- c.now
+	}
+	return ch
+}
+
+// advance moves virtual time forward, e.g. to simulate Notion latency.
+func (c *virtualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// set moves virtual time forward to t.
+func (c *virtualClock) set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+func (c *virtualClock) freeze() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.frozen = true
+}
+
+// outage is a window of virtual time in which a fake Notion endpoint fails.
+type outage struct {
+	from, until time.Time
+}
+
+// fakeNotion is a CacheRefresher backed by a scripted Notion workspace. Each
+// call takes latency of virtual time and fails while an outage covers it; a
+// successful call publishes a new cache version, like notion.Client does.
+type fakeNotion struct {
+	clock   *virtualClock
+	latency time.Duration
+
+	mu               sync.Mutex
+	customers        int      // Customers returned by the customers database
+	customerOutages  []outage // Windows in which customer queries fail
+	userOutages      []outage // Windows in which user queries fail
+	cachedCustomers  int      // Customers in the published cache
+	version          uint64   // Published cache version
+	customerCalls    int
+	userCalls        int
+	onUserFailure    func(failures int) // Called after every failed user query
+	userFailureCount int
+}
+
+var errNotionUnavailable = errors.New("notion API returned status 503: service unavailable")
+
+func (n *fakeNotion) InitializeCustomers() error {
+	n.clock.advance(n.latency)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.customerCalls++
+	if n.down(n.customerOutages) {
+		return errNotionUnavailable
+	}
+	n.cachedCustomers = n.customers
+	n.version++
+	return nil
+}
+
+func (n *fakeNotion) InitializeUsers() error {
+	n.clock.advance(n.latency)
+	n.mu.Lock()
+	n.userCalls++
+	if !n.down(n.userOutages) {
+		n.version++
+		n.mu.Unlock()
+		return nil
+	}
+	n.userFailureCount++
+	failures, onFailure := n.userFailureCount, n.onUserFailure
+	n.mu.Unlock()
+
+	if onFailure != nil {
+		onFailure(failures)
+	}
+	return errNotionUnavailable
+}
+
+// down reports whether an outage covers the current virtual time. Callers hold n.mu.
+func (n *fakeNotion) down(outages []outage) bool {
+	now := n.clock.Now()
+	for _, o := range outages {
+		if !now.Before(o.from) && now.Before(o.until) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *fakeNotion) update(f func(n *fakeNotion)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f(n)
+}
+
+func (n *fakeNotion) state() (version uint64, cachedCustomers, customerCalls, userCalls int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.version, n.cachedCustomers, n.customerCalls, n.userCalls
+}
+
+// cacheCounters is a snapshot of the cache metrics for one cache type.
+type cacheCounters struct {
+	successes, failures, retries float64
+	lastRefresh                  float64
+}
+
+func readCacheCounters(m *metrics.Metrics, cacheType string) cacheCounters {
+	return cacheCounters{
+		successes:   testutil.ToFloat64(m.CacheRefreshTotal.WithLabelValues(cacheType, "success")),
+		failures:    testutil.ToFloat64(m.CacheRefreshTotal.WithLabelValues(cacheType, "failure")),
+		retries:     testutil.ToFloat64(m.CacheRefreshRetriesTotal.WithLabelValues(cacheType)),
+		lastRefresh: testutil.ToFloat64(m.CacheLastRefreshTimestamp.WithLabelValues(cacheType)),
+	}
+}
+
+// TestSimulation_CacheLifecycle runs the cache manager through hours of virtual
+// time against a scripted Notion workspace: successful refreshes, a transient
+// outage, a permanent outage, a shrunken customer list, a manual refresh and a
+// shutdown during backoff. After each phase it checks the refresh metrics,
+// the published cache version and the readiness status.
+//
+// Virtual time makes the run deterministic: every backoff completes
+// instantly, and the test waits for each refresh cycle to finish (by its
+// "cycle complete" log) before moving on.
+func TestSimulation_CacheLifecycle(t *testing.T) {
+	start := time.Date(2025, 11, 12, 0, 0, 0, 0, time.UTC)
+	clock := newVirtualClock(start)
+	notion := &fakeNotion{clock: clock, latency: 2 * time.Second, customers: 12}
+
+	core, logs := observer.New(zap.InfoLevel)
+	m := metrics.Init()
+	mgr := NewManager(notion, m, zap.New(core), time.Hour)
+	mgr.SetClock(clock)
+
+	healthMgr := health.NewManager(zap.NewNop())
+	healthMgr.RegisterReadinessCheck("client_cache", health.ClientCacheChecker(func() int {
+		_, cached, _, _ := notion.state()
+		return cached
+	}, 10))
+	readiness := func() health.Status {
+		w := httptest.NewRecorder()
+		healthMgr.ReadinessHandler()(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		var response health.Response
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode readiness response: %v", err)
+		}
+		return response.Status
+	}
+
+	cycles := 0
+	waitForCycle := func() {
+		t.Helper()
+		cycles++
+		deadline := time.Now().Add(5 * time.Second)
+		for logs.FilterMessage("cache refresh cycle complete").Len() < cycles {
+			if time.Now().After(deadline) {
+				t.Fatalf("refresh cycle %d did not complete", cycles)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	// tick runs the scheduled refresh at the given hour, as the scheduler would
+	tick := func(hours int) {
+		t.Helper()
+		clock.set(start.Add(time.Duration(hours) * time.Hour))
+		_ = mgr.ScheduledRefresh(context.Background())
+		waitForCycle()
+	}
+
+	type want struct {
+		customers, users cacheCounters // Deltas since the previous phase, except lastRefresh
+		version          uint64
+		status           health.Status
+	}
+	previous := map[string]cacheCounters{
+		CacheTypeCustomers: readCacheCounters(m, CacheTypeCustomers),
+		CacheTypeUsers:     readCacheCounters(m, CacheTypeUsers),
+	}
+	check := func(phase string, w want) {
+		t.Helper()
+		for cacheType, expected := range map[string]cacheCounters{CacheTypeCustomers: w.customers, CacheTypeUsers: w.users} {
+			current := readCacheCounters(m, cacheType)
+			got := cacheCounters{
+				successes:   current.successes - previous[cacheType].successes,
+				failures:    current.failures - previous[cacheType].failures,
+				retries:     current.retries - previous[cacheType].retries,
+				lastRefresh: current.lastRefresh,
+			}
+			if got != expected {
+				t.Errorf("%s: %s metrics = %+v, want %+v", phase, cacheType, got, expected)
+			}
+			previous[cacheType] = current
+		}
+		if version, _, _, _ := notion.state(); version != w.version {
+			t.Errorf("%s: cache version = %d, want %d", phase, version, w.version)
+		}
+		if status := readiness(); status != w.status {
+			t.Errorf("%s: readiness = %q, want %q", phase, status, w.status)
+		}
+	}
+	unix := func(d time.Duration) float64 { return float64(start.Add(d).Unix()) }
+
+	if status := readiness(); status != health.StatusUnhealthy {
+		t.Errorf("before the first refresh: readiness = %q, want %q", status, health.StatusUnhealthy)
+	}
+	mgr.Start()
+
+	// Hour 1: both caches refresh on the first attempt
+	tick(1)
+	check("first refresh", want{
+		customers: cacheCounters{successes: 1, lastRefresh: unix(time.Hour + 2*time.Second)},
+		users:     cacheCounters{successes: 1, lastRefresh: unix(time.Hour + 4*time.Second)},
+		version:   2,
+		status:    health.StatusHealthy,
+	})
+
+	// Hour 2: customer queries fail for 20s. Attempts at +2s, +7s and +15s fail;
+	// the fourth, after 3s+6s+12s of backoff and 2s per call, succeeds at +29s
+	notion.update(func(n *fakeNotion) {
+		n.customerOutages = []outage{{start.Add(2 * time.Hour), start.Add(2*time.Hour + 20*time.Second)}}
+	})
+	tick(2)
+	check("transient failure", want{
+		customers: cacheCounters{successes: 1, retries: 3, lastRefresh: unix(2*time.Hour + 29*time.Second)},
+		users:     cacheCounters{successes: 1, lastRefresh: unix(2*time.Hour + 31*time.Second)},
+		version:   4,
+		status:    health.StatusHealthy,
+	})
+
+	// Hour 3: Notion is down for 30 minutes, longer than the retry window. Each
+	// cache gives up after 8 attempts (7 retries) and keeps serving the old data
+	notion.update(func(n *fakeNotion) {
+		down := []outage{{start.Add(3 * time.Hour), start.Add(3*time.Hour + 30*time.Minute)}}
+		n.customerOutages, n.userOutages = down, down
+	})
+	tick(3)
+	check("permanent failure", want{
+		customers: cacheCounters{failures: 1, retries: 7, lastRefresh: unix(2*time.Hour + 29*time.Second)},
+		users:     cacheCounters{failures: 1, retries: 7, lastRefresh: unix(2*time.Hour + 31*time.Second)},
+		version:   4,
+		status:    health.StatusHealthy,
+	})
+
+	// Hour 4: Notion is back, but the customers database lost most rows
+	notion.update(func(n *fakeNotion) { n.customers = 5 })
+	tick(4)
+	check("recovery with fewer customers", want{
+		customers: cacheCounters{successes: 1, lastRefresh: unix(4*time.Hour + 2*time.Second)},
+		users:     cacheCounters{successes: 1, lastRefresh: unix(4*time.Hour + 4*time.Second)},
+		version:   6,
+		status:    health.StatusDegraded,
+	})
+
+	// Hour 4:30: the rows are restored and an admin refreshes manually
+	notion.update(func(n *fakeNotion) { n.customers = 12 })
+	clock.set(start.Add(4*time.Hour + 30*time.Minute))
+	mgr.ManualRefresh()
+	waitForCycle()
+	check("manual refresh", want{
+		customers: cacheCounters{successes: 1, lastRefresh: unix(4*time.Hour + 30*time.Minute + 2*time.Second)},
+		users:     cacheCounters{successes: 1, lastRefresh: unix(4*time.Hour + 30*time.Minute + 4*time.Second)},
+		version:   8,
+		status:    health.StatusHealthy,
+	})
+
+	// Hour 5: user queries fail, and the process shuts down during the second
+	// backoff. The refresh is cancelled without recording a permanent failure
+	stopped := make(chan struct{})
+	notion.update(func(n *fakeNotion) {
+		n.userOutages = []outage{{start.Add(5 * time.Hour), start.Add(6 * time.Hour)}}
+		n.userFailureCount = 0
+		n.onUserFailure = func(failures int) {
+			if failures == 2 {
+				clock.freeze()
+				go func() {
+					mgr.Stop()
+					close(stopped)
+				}()
+			}
+		}
+	})
+	tick(5)
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("manager did not stop")
+	}
+	check("shutdown during backoff", want{
+		customers: cacheCounters{successes: 1, lastRefresh: unix(5*time.Hour + 2*time.Second)},
+		users:     cacheCounters{retries: 2, lastRefresh: unix(4*time.Hour + 30*time.Minute + 4*time.Second)},
+		version:   9,
+		status:    health.StatusHealthy,
+	})
+
+	// After shutdown, manual refreshes are skipped
+	_, _, customerCalls, userCalls := notion.state()
+	mgr.ManualRefresh()
+	if _, _, c, u := notion.state(); c != customerCalls || u != userCalls {
+		t.Errorf("refresh after shutdown called Notion: customers %d→%d, users %d→%d", customerCalls, c, userCalls, u)
+	}
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// fileSettings maps each setting of the YAML config file (CONFIG_FILE) to the
+// environment variable it stands for. Values take the same units as the
+// variables (minutes or seconds as documented there), lists are YAML lists,
+// and a variable set in the environment overrides the file.
+var fileSettings = map[string]string{
+	"backend": "BACKEND",
+
+	"server.port":                  "PORT",
+	"server.base_path":             "BASE_PATH",
+	"server.trust_proxy_headers":   "TRUST_PROXY_HEADERS",
+	"server.trusted_proxy_hops":    "TRUSTED_PROXY_HOPS",
+	"server.idle_timeout":          "SERVER_IDLE_TIMEOUT",
+	"server.max_header_bytes":      "SERVER_MAX_HEADER_BYTES",
+	"server.keep_alives":           "SERVER_KEEP_ALIVES",
+	"server.compression_min_bytes": "COMPRESSION_MIN_BYTES",
+	"server.lazy_startup":          "LAZY_STARTUP",
+	"server.rate_limit.per_minute": "RATE_LIMIT_PER_MINUTE",
+	"server.rate_limit.burst":      "RATE_LIMIT_BURST",
+	"server.background_workers":    "BACKGROUND_WORKERS",
+
+	"web_form.enabled":            "WEB_FORM_ENABLED",
+	"web_form.captcha.provider":   "CAPTCHA_PROVIDER",
+	"web_form.captcha.site_key":   "CAPTCHA_SITE_KEY",
+	"web_form.captcha.secret":     "CAPTCHA_SECRET",
+	"web_form.captcha.min_score":  "CAPTCHA_MIN_SCORE",
+	"web_form.captcha.verify_url": "CAPTCHA_VERIFY_URL",
+
+	"admin.token":          "ADMIN_TOKEN",
+	"admin.port":           "ADMIN_PORT",
+	"admin.tls_cert_file":  "ADMIN_TLS_CERT_FILE",
+	"admin.tls_key_file":   "ADMIN_TLS_KEY_FILE",
+	"admin.client_ca_file": "ADMIN_CLIENT_CA_FILE",
+
+	"slack.signing_secret":               "SLACK_SIGNING_SECRET",
+	"slack.bot_token":                    "SLACK_BOT_TOKEN",
+	"slack.bot_token_file":               "SLACK_BOT_TOKEN_FILE",
+	"slack.client_id":                    "SLACK_CLIENT_ID",
+	"slack.client_secret":                "SLACK_CLIENT_SECRET",
+	"slack.oauth_redirect_url":           "SLACK_OAUTH_REDIRECT_URL",
+	"slack.installations_file":           "SLACK_INSTALLATIONS_FILE",
+	"slack.admin_user_ids":               "SLACK_ADMIN_USER_IDS",
+	"slack.admin_usergroup":              "SLACK_ADMIN_USERGROUP",
+	"slack.messages_file":                "MESSAGES_FILE",
+	"slack.max_options_results":          "MAX_OPTIONS_RESULTS",
+	"slack.customer_select_mode":         "CUSTOMER_SELECT_MODE",
+	"slack.confirmation_channel":         "CONFIRMATION_CHANNEL",
+	"slack.confirmation_batch_threshold": "CONFIRMATION_BATCH_THRESHOLD",
+	"slack.confirmation_batch_window":    "CONFIRMATION_BATCH_WINDOW",
+	"slack.digest_channel":               "DIGEST_CHANNEL",
+	"slack.digest_schedule":              "DIGEST_SCHEDULE",
+	"slack.guest_policy":                 "SLACK_GUEST_POLICY",
+	"slack.hidden_email_policy":          "HIDDEN_EMAIL_POLICY",
+	"slack.guest_triage_channel":         "GUEST_TRIAGE_CHANNEL",
+
+	"notion.api_key":                    "NOTION_API_KEY",
+	"notion.api_key_file":               "NOTION_API_KEY_FILE",
+	"notion.database_id":                "NOTION_DATABASE_ID",
+	"notion.clients_db_id":              "NOTION_CLIENTS_DB_ID",
+	"notion.api_version":                "NOTION_API_VERSION",
+	"notion.source_url_property":        "NOTION_SOURCE_URL_PROPERTY",
+	"notion.customer_aliases_property":  "NOTION_CUSTOMER_ALIASES_PROPERTY",
+	"notion.customer_detail_properties": "NOTION_CUSTOMER_DETAIL_PROPERTIES",
+	"notion.customer_name_property":     "NOTION_CUSTOMER_NAME_PROPERTY",
+	"notion.customer_status_property":   "NOTION_CUSTOMER_STATUS_PROPERTY",
+	"notion.customer_statuses":          "NOTION_CUSTOMER_STATUSES",
+	"notion.customers_filter":           "NOTION_CUSTOMERS_FILTER",
+	"notion.fallback_user_id":           "NOTION_FALLBACK_USER_ID",
+	"notion.user_overrides":             "SLACK_NOTION_USER_OVERRIDES",
+	"notion.permission_check_interval":  "PERMISSION_CHECK_INTERVAL",
+	"notion.allowed_email_domains":      "ALLOWED_EMAIL_DOMAINS",
+	"notion.tenants_file":               "TENANTS_FILE",
+
+	"linear.api_key":                  "LINEAR_API_KEY",
+	"linear.team":                     "LINEAR_TEAM",
+	"linear.product_area_teams":       "LINEAR_PRODUCT_AREA_TEAMS",
+	"linear.theme_label_group":        "LINEAR_THEME_LABEL_GROUP",
+	"linear.product_area_label_group": "LINEAR_PRODUCT_AREA_LABEL_GROUP",
+
+	"cache.refresh_interval": "CACHE_REFRESH_INTERVAL",
+	"cache.backend":          "CACHE_BACKEND",
+	"cache.redis_url":        "REDIS_URL",
+	"cache.redis_key_prefix": "REDIS_KEY_PREFIX",
+	"cache.snapshot_file":    "CACHE_SNAPSHOT_FILE",
+
+	"fanout.backends":       "FANOUT_BACKENDS",
+	"fanout.retry_file":     "FANOUT_RETRY_FILE",
+	"fanout.postgres_url":   "POSTGRES_URL",
+	"fanout.postgres_table": "POSTGRES_TABLE",
+
+	"audit_log.backend":        "AUDIT_LOG_BACKEND",
+	"audit_log.file":           "AUDIT_LOG_FILE",
+	"audit_log.table":          "AUDIT_LOG_TABLE",
+	"audit_log.retention_days": "AUDIT_LOG_RETENTION_DAYS",
+
+	"features.multi_step_modal":             "MULTI_STEP_MODAL",
+	"features.attachments":                  "ATTACHMENTS_ENABLED",
+	"features.comment_blocks":               "COMMENT_BLOCKS",
+	"features.customer_org_required_themes": "CUSTOMER_ORG_REQUIRED_THEMES",
+	"features.disabled_flags":               "DISABLED_FEATURE_FLAGS",
+	"features.reminders_file":               "REMINDERS_FILE",
+	"features.drafts_file":                  "DRAFTS_FILE",
+	"features.submission_queue.enabled":     "SUBMISSION_QUEUE_ENABLED",
+	"features.submission_queue.file":        "SUBMISSION_QUEUE_FILE",
+	"features.submission_quota.per_day":     "SUBMISSION_QUOTA_PER_DAY",
+	"features.submission_quota.cooldown":    "SUBMISSION_COOLDOWN",
+	"features.funnel.enabled":               "FUNNEL_TRACKING_ENABLED",
+	"features.funnel.file":                  "FUNNEL_FILE",
+	"features.funnel.closed_statuses":       "FUNNEL_CLOSED_STATUSES",
+	"features.status_notifications.enabled": "STATUS_NOTIFICATIONS_ENABLED",
+	"features.status_notifications.file":    "STATUS_NOTIFICATIONS_FILE",
+	"features.voting.enabled":               "VOTING_ENABLED",
+	"features.voting.file":                  "VOTES_FILE",
+	"features.voting.reactions":             "VOTE_REACTIONS",
+
+	"analytics.endpoint":       "ANALYTICS_ENDPOINT",
+	"analytics.write_key":      "ANALYTICS_WRITE_KEY",
+	"analytics.batch_size":     "ANALYTICS_BATCH_SIZE",
+	"analytics.flush_interval": "ANALYTICS_FLUSH_INTERVAL",
+}
+
+// settings looks up configuration variables in the environment, falling back
+// to the config file.
+type settings struct {
+	file map[string]string // Environment variable name -> value from the file
+}
+
+// loadSettings reads the config file at path; an empty path reads the
+// environment only.
+func loadSettings(path string) (*settings, error) {
+	s := &settings{file: map[string]string{}}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading CONFIG_FILE: %w", err)
+	}
+	var root map[string]interface{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parsing CONFIG_FILE %s: %w", path, err)
+	}
+	if err := s.flatten("", root); err != nil {
+		return nil, fmt.Errorf("CONFIG_FILE %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// flatten stores the leaves of a YAML mapping under their environment
+// variable names. Unknown settings are rejected, so a typo doesn't silently
+// leave a default in place.
+func (s *settings) flatten(prefix string, values map[string]interface{}) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		switch value := values[key].(type) {
+		case nil:
+			// "key:" with no value leaves the setting unset
+		case map[interface{}]interface{}:
+			nested := make(map[string]interface{}, len(value))
+			for k, v := range value {
+				nested[fmt.Sprint(k)] = v
+			}
+			if err := s.flatten(path, nested); err != nil {
+				return err
+			}
+		case []interface{}:
+			name, ok := fileSettings[path]
+			if !ok {
+				return fmt.Errorf("unknown setting %q", path)
+			}
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				if _, ok := item.(map[interface{}]interface{}); ok {
+					return fmt.Errorf("%s must be a list of values", path)
+				}
+				items = append(items, fmt.Sprint(item))
+			}
+			s.file[name] = strings.Join(items, ",")
+		default:
+			name, ok := fileSettings[path]
+			if !ok {
+				return fmt.Errorf("unknown setting %q", path)
+			}
+			s.file[name] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
+
+// ReadSecretFile reads a credential from a secret file, without the trailing
+// newline editors and `kubectl create secret --from-file` tend to leave.
+func ReadSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return value, nil
+}
+
+// get returns the variable from the environment, or from the config file when
+// it is unset or empty there.
+func (s *settings) get(name string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return s.file[name]
+}
+
+// lookup is get for variables where an empty value differs from an unset one:
+// a variable set in the environment wins even when empty.
+func (s *settings) lookup(name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	value, ok := s.file[name]
+	return value, ok
+}
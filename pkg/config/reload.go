@@ -0,0 +1,90 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+)
+
+// reloadable lists the Config fields a running bot applies on a configuration
+// reload (see pkg/configwatch). Everything else, including every secret, is
+// read once at startup and needs a restart to change.
+var reloadable = []string{
+	"CacheRefreshInterval",
+	"AllowedEmailDomains",
+	"CustomerOrgRequiredThemes",
+	"DisabledFeatureFlags",
+	"MaxOptionsResults",
+	"NotionSourceURLProperty",
+	"NotionCustomerAliasesProperty",
+	"NotionCustomerDetailProperties",
+	"NotionCustomerNameProperty",
+	"NotionCustomerStatusProperty",
+	"NotionCustomerStatuses",
+	"NotionCustomersFilter",
+	"SlackNotionUserOverrides",
+	"NotionFallbackUserID",
+	"SlackGuestPolicy",
+	"HiddenEmailPolicy",
+	"GuestTriageChannel",
+	"ConfirmationChannel",
+	"ConfirmationBatchThreshold",
+	"ConfirmationBatchWindow",
+	"SubmissionQuotaPerDay",
+	"SubmissionCooldown",
+}
+
+// rotated lists the credentials pkg/credentials rotates at runtime when their
+// secret file changes. Reloads leave them to it, so they aren't reported as
+// changes and keep the value currently in use.
+var rotated = []string{"SlackBotToken", "NotionAPIKey"}
+
+// Change is a Config field whose value differs between two configs.
+type Change struct {
+	Field      string // Config field name, e.g. CacheRefreshInterval
+	Reloadable bool   // Applied by a reload; false means the change waits for a restart
+}
+
+// Diff returns the fields whose values differ in next, in declaration order.
+// Only field names are reported, so the result is safe to log even when a
+// secret changed.
+func (c *Config) Diff(next *Config) []Change {
+	current, updated := reflect.ValueOf(c).Elem(), reflect.ValueOf(next).Elem()
+	var changes []Change
+	for i := 0; i < current.NumField(); i++ {
+		if reflect.DeepEqual(current.Field(i).Interface(), updated.Field(i).Interface()) {
+			continue
+		}
+		name := current.Type().Field(i).Name
+		if slices.Contains(rotated, name) {
+			continue
+		}
+		changes = append(changes, Change{Field: name, Reloadable: slices.Contains(reloadable, name)})
+	}
+	return changes
+}
+
+// Reloaded returns a copy of the config with the reloadable fields taken from
+// next and everything else kept, i.e. the configuration the bot runs with
+// after reloading next. The result is validated, since a reloadable value may
+// only be valid alongside settings that can't change without a restart (e.g.
+// VOTING_ENABLED requires CONFIRMATION_CHANNEL).
+func (c *Config) Reloaded(next *Config) (*Config, error) {
+	var reloaded Config
+	result := reflect.ValueOf(&reloaded).Elem()
+	source, updated := reflect.ValueOf(c).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < result.NumField(); i++ {
+		field := source.Field(i)
+		if slices.Contains(reloadable, result.Type().Field(i).Name) {
+			field = updated.Field(i)
+		}
+		if field.Kind() == reflect.Slice && !field.IsNil() { // Don't share lists between configs
+			field = reflect.AppendSlice(reflect.MakeSlice(field.Type(), 0, field.Len()), field)
+		}
+		result.Field(i).Set(field)
+	}
+
+	if err := reloaded.Validate(); err != nil {
+		return nil, err
+	}
+	return &reloaded, nil
+}
@@ -0,0 +1,202 @@
+// Package configwatch reloads the tunable settings of a running bot without a
+// restart.
+//
+// A reload is triggered by SIGHUP, or by a change to CONFIG_FILE noticed by
+// CheckFile, run periodically by pkg/scheduler. It reads the configuration
+// again with config.Load, so the environment still overrides the file and
+// everything is validated, then applies the reloadable settings (see
+// config.Config.Reloaded) through an ApplyFunc.
+//
+// Features:
+// - An invalid configuration is rejected as a whole; the bot keeps the previous one
+// - Changed settings are logged by name only, so secrets never reach the logs
+// - Changes to settings that aren't reloadable are logged as needing a restart
+// - Reloads are counted by trigger and outcome (hopperbot_config_reloads_total)
+package configwatch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// Reload triggers, used as the trigger label of the reloads metric.
+const (
+	TriggerSignal = "signal" // SIGHUP
+	TriggerFile   = "file"   // CONFIG_FILE changed
+)
+
+// ApplyFunc applies a reloaded configuration to the running bot. previous is
+// the configuration it replaces, so changes can be applied incrementally.
+type ApplyFunc func(previous, cfg *config.Config)
+
+// Watcher reloads the configuration on SIGHUP and on CONFIG_FILE changes.
+type Watcher struct {
+	load    func() (*config.Config, error) // config.Load; replaced in tests
+	apply   ApplyFunc
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+
+	current atomic.Pointer[config.Config]
+
+	mu       sync.Mutex // Serializes reloads and guards the fields below
+	fileStat fileStat   // CONFIG_FILE as of the last reload
+
+	signals chan os.Signal
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// fileStat identifies a version of the config file.
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// New returns a watcher for a bot started with cfg.
+func New(cfg *config.Config, apply ApplyFunc, m *metrics.Metrics, logger *zap.Logger) *Watcher {
+	w := &Watcher{
+		load:    config.Load,
+		apply:   apply,
+		metrics: m,
+		logger:  logger,
+		signals: make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+	}
+	w.current.Store(cfg)
+	w.fileStat, _ = statFile(cfg.ConfigFile)
+	return w
+}
+
+// Config returns the configuration the bot is running with.
+func (w *Watcher) Config() *config.Config {
+	return w.current.Load()
+}
+
+// Start reloads the configuration on every SIGHUP until Stop.
+func (w *Watcher) Start() {
+	signal.Notify(w.signals, syscall.SIGHUP)
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for {
+			select {
+			case <-w.signals:
+				w.Reload(TriggerSignal)
+			case <-w.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops listening for SIGHUP and waits for a reload in progress.
+func (w *Watcher) Stop() {
+	signal.Stop(w.signals)
+	close(w.done)
+	w.wg.Wait()
+}
+
+// CheckFile reloads the configuration when CONFIG_FILE changed since the last
+// reload. A file that fails to reload isn't retried until it changes again.
+func (w *Watcher) CheckFile(ctx context.Context) error {
+	path := w.Config().ConfigFile
+	if path == "" {
+		return nil
+	}
+
+	stat, err := statFile(path)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	changed := stat != w.fileStat
+	w.mu.Unlock()
+	if changed {
+		w.Reload(TriggerFile)
+	}
+	return nil
+}
+
+// Reload reads the configuration again and applies its reloadable settings.
+// On error the running configuration is left unchanged.
+func (w *Watcher) Reload(trigger string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	current := w.current.Load()
+	if stat, err := statFile(current.ConfigFile); err == nil {
+		w.fileStat = stat
+	}
+
+	reloaded, applied, err := w.reload(current)
+	if err != nil {
+		w.metrics.ConfigReloadsTotal.WithLabelValues(trigger, "failure").Inc()
+		w.logger.Error("configuration reload failed, keeping the running configuration",
+			zap.String("trigger", trigger),
+			zap.Error(err),
+		)
+		return err
+	}
+	w.metrics.ConfigReloadsTotal.WithLabelValues(trigger, "success").Inc()
+
+	if len(applied) == 0 {
+		w.logger.Info("configuration reloaded, no reloadable settings changed", zap.String("trigger", trigger))
+		return nil
+	}
+	w.current.Store(reloaded)
+	w.apply(current, reloaded)
+	w.logger.Info("configuration reloaded",
+		zap.String("trigger", trigger),
+		zap.Strings("changed", applied),
+	)
+	return nil
+}
+
+// reload loads the configuration and merges it into current, returning the
+// reloadable settings that changed.
+func (w *Watcher) reload(current *config.Config) (*config.Config, []string, error) {
+	next, err := w.load()
+	if err != nil {
+		return nil, nil, err
+	}
+	reloaded, err := current.Reloaded(next)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reloaded settings conflict with the running configuration: %w", err)
+	}
+
+	var applied, restart []string
+	for _, change := range current.Diff(next) {
+		if change.Reloadable {
+			applied = append(applied, change.Field)
+		} else {
+			restart = append(restart, change.Field)
+		}
+	}
+	if len(restart) > 0 {
+		w.logger.Warn("configuration changes need a restart to apply", zap.Strings("settings", restart))
+	}
+	return reloaded, applied, nil
+}
+
+// statFile returns the modification time and size of a config file.
+func statFile(path string) (fileStat, error) {
+	if path == "" {
+		return fileStat{}, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}, fmt.Errorf("checking CONFIG_FILE: %w", err)
+	}
+	return fileStat{modTime: info.ModTime(), size: info.Size()}, nil
+}
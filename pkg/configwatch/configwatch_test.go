@@ -0,0 +1,174 @@
+package configwatch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		SlackSigningSecret:   "secret",
+		SlackBotToken:        "xoxb-token",
+		NotionAPIKey:         "secret_notion_key",
+		NotionDatabaseID:     "1a2b3c4d5e6f4a7b8c9d0e1f2a3b4c5d",
+		NotionClientsDBID:    "9f8e7d6c5b4a439281706f5e4d3c2b1a",
+		CacheRefreshInterval: time.Hour,
+	}
+}
+
+// newTestWatcher returns a watcher whose reloads load next() and record the
+// configurations applied.
+func newTestWatcher(t *testing.T, cfg *config.Config, next func() (*config.Config, error)) (*Watcher, *[]*config.Config) {
+	t.Helper()
+	var applied []*config.Config
+	w := New(cfg, func(previous, cfg *config.Config) {
+		applied = append(applied, cfg)
+	}, metrics.Init(), zap.NewNop())
+	w.load = next
+	return w, &applied
+}
+
+func reloads(trigger, status string) float64 {
+	return testutil.ToFloat64(metrics.Get().ConfigReloadsTotal.WithLabelValues(trigger, status))
+}
+
+func TestReload_AppliesReloadableSettings(t *testing.T) {
+	next := testConfig()
+	next.CacheRefreshInterval = 5 * time.Minute
+	next.ConfirmationChannel = "C0123"
+	w, applied := newTestWatcher(t, testConfig(), func() (*config.Config, error) { return next, nil })
+
+	before := reloads(TriggerSignal, "success")
+	if err := w.Reload(TriggerSignal); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(*applied) != 1 {
+		t.Fatalf("applied %d configurations, want 1", len(*applied))
+	}
+	if got := w.Config(); got.CacheRefreshInterval != 5*time.Minute || got.ConfirmationChannel != "C0123" {
+		t.Errorf("Config() = %+v, want the reloaded settings", got)
+	}
+	if got := reloads(TriggerSignal, "success") - before; got != 1 {
+		t.Errorf("successful reloads = %v, want 1", got)
+	}
+}
+
+func TestReload_KeepsSettingsNeedingRestart(t *testing.T) {
+	next := testConfig()
+	next.SlackSigningSecret = "rotated"
+	w, applied := newTestWatcher(t, testConfig(), func() (*config.Config, error) { return next, nil })
+
+	if err := w.Reload(TriggerSignal); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(*applied) != 0 {
+		t.Errorf("applied %d configurations, want none", len(*applied))
+	}
+	if w.Config().SlackSigningSecret != "secret" {
+		t.Errorf("SlackSigningSecret = %q, want the startup value", w.Config().SlackSigningSecret)
+	}
+}
+
+func TestReload_Failure(t *testing.T) {
+	running := testConfig()
+	running.VotingEnabled = true
+	running.ConfirmationChannel = "C0123"
+	running.VoteReactions = []string{"+1"}
+
+	tests := []struct {
+		name string
+		next func() (*config.Config, error)
+	}{
+		{"invalid configuration", func() (*config.Config, error) {
+			return nil, errors.New("CACHE_REFRESH_INTERVAL must be greater than 0")
+		}},
+		{"conflicts with running configuration", func() (*config.Config, error) {
+			return testConfig(), nil // No confirmation channel, which voting needs
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, applied := newTestWatcher(t, running, tt.next)
+
+			before := reloads(TriggerFile, "failure")
+			if err := w.Reload(TriggerFile); err == nil {
+				t.Fatal("Reload() error = nil, want an error")
+			}
+			if len(*applied) != 0 || w.Config() != running {
+				t.Error("a failed reload changed the running configuration")
+			}
+			if got := reloads(TriggerFile, "failure") - before; got != 1 {
+				t.Errorf("failed reloads = %v, want 1", got)
+			}
+		})
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hopperbot.yaml")
+	if err := os.WriteFile(path, []byte("cache:\n  refresh_interval: 60\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig()
+	cfg.ConfigFile = path
+
+	loads := 0
+	w, _ := newTestWatcher(t, cfg, func() (*config.Config, error) {
+		loads++
+		return cfg, nil
+	})
+
+	if err := w.CheckFile(context.Background()); err != nil {
+		t.Fatalf("CheckFile() error = %v", err)
+	}
+	if loads != 0 {
+		t.Fatalf("unchanged file reloaded %d times, want 0", loads)
+	}
+
+	if err := os.WriteFile(path, []byte("cache:\n  refresh_interval: 5\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := w.CheckFile(context.Background()); err != nil {
+			t.Fatalf("CheckFile() error = %v", err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("changed file reloaded %d times, want 1", loads)
+	}
+
+	os.Remove(path)
+	if err := w.CheckFile(context.Background()); err == nil {
+		t.Error("CheckFile() on a missing file error = nil, want an error")
+	}
+}
+
+func TestStart_ReloadsOnSIGHUP(t *testing.T) {
+	loaded := make(chan struct{}, 1)
+	w, _ := newTestWatcher(t, testConfig(), func() (*config.Config, error) {
+		loaded <- struct{}{}
+		return testConfig(), nil
+	})
+	w.Start()
+	defer w.Stop()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-loaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGHUP didn't trigger a reload")
+	}
+}
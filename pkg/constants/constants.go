@@ -22,6 +22,27 @@ const (
 	FieldComments      = "Comments"
 	FieldCustomerOrg   = "Customer Organization"
 	FieldSubmittedBy   = "Submitted by"
+
+	// FieldStatus is the triage status maintained by the product team (status or select).
+	// The bot never writes it; it is only read for follow-up reminders.
+	FieldStatus = "Status"
+
+	// FieldArtifacts holds links to Slack canvases and huddles (optional rich_text).
+	// The modal only offers it when the ideas database has this property.
+	FieldArtifacts = "Artifacts"
+
+	// FieldSource records the intake channel of an idea (optional select, e.g. "slack_modal").
+	// The bot sets it on new pages when the ideas database has this property; users never edit it.
+	FieldSource = "Source"
+
+	// FieldVotes counts the Slack users who reacted to an idea's card with a vote emoji
+	// (optional number). The bot only writes it when voting is enabled and the database has it.
+	FieldVotes = "Votes"
+
+	// FieldSubmittedBySlack names the Slack user who submitted an idea (optional rich_text),
+	// so ideas attributed to NOTION_FALLBACK_USER_ID still show who sent them.
+	// The bot sets it on new pages when the ideas database has this property.
+	FieldSubmittedBySlack = "Submitted by (Slack)"
 )
 
 // Field aliases for title field.
@@ -78,6 +99,28 @@ var ValidThemeCategories = []string{
 	"Customer Pain Point",
 }
 
+// CustomerOrgRequiredThemes lists the themes that require at least one customer org.
+//
+// Pain points and competitive intel are only actionable when tied to the customers
+// who raised them. Overridable via CUSTOMER_ORG_REQUIRED_THEMES.
+var CustomerOrgRequiredThemes = []string{
+	"Customer Pain Point",
+	"Market/Competition Intelligence",
+}
+
+// FunnelClosedStatuses are the Status values that mark a decision on an idea,
+// ending its time-to-decision measurement. Overridable via FUNNEL_CLOSED_STATUSES.
+var FunnelClosedStatuses = []string{
+	"Shipped",
+	"Done",
+	"Won't Do",
+	"Rejected",
+}
+
+// DefaultVoteReactions are the emoji (names without colons) counted as votes on
+// idea confirmations. Overridable via VOTE_REACTIONS.
+var DefaultVoteReactions = []string{"+1"}
+
 // ValidProductAreas defines the allowed values for the Product Area field.
 //
 // Represents the different product areas within the organization.
@@ -105,10 +148,119 @@ const (
 	// preventing abuse. Most ideas relate to fewer than 10 customers.
 	MaxCustomerOrgSelections = 10
 
-	// MaxOptionsResults limits the number of options returned in external select menus.
-	// Rationale: Slack recommends limiting to 100 options for good UX and performance.
+	// MaxArtifacts limits the Slack canvas and huddle links per submission.
+	MaxArtifacts = 10
+
+	// MaxAttachments limits the files attached to a submission.
+	MaxAttachments = 5
+
+	// MaxAttachmentBytes limits the size of each attached file. Notion accepts
+	// up to 20 MB per single-part upload; files are held in memory while copied.
+	MaxAttachmentBytes = 10 << 20
+
+	// MaxOptionsResults is the default number of options returned in external select
+	// menus (configurable via MAX_OPTIONS_RESULTS, never above SlackMaxOptions).
 	// Users can narrow results by typing more specific search queries.
 	MaxOptionsResults = 100
+
+	// SlackMaxOptions is Slack's hard limit on options in an external select response.
+	// Responses with more options are rejected and the menu shows no results.
+	SlackMaxOptions = 100
+)
+
+// AttachmentFileTypes are the file extensions that can be attached to a
+// submission: screenshots and common documents.
+var AttachmentFileTypes = []string{
+	"png", "jpg", "jpeg", "gif", "webp",
+	"pdf", "txt", "csv", "md",
+	"docx", "xlsx", "pptx",
+}
+
+// Customer select modes (CUSTOMER_SELECT_MODE).
+const (
+	// CustomerSelectExternal loads customer options from /slack/options as the user types (default).
+	CustomerSelectExternal = "external"
+
+	// CustomerSelectStatic embeds customer options in the modal, for Slack apps
+	// without an Options Load URL. At most SlackMaxOptions customers can be offered.
+	CustomerSelectStatic = "static"
+)
+
+// Submitter policies (SLACK_GUEST_POLICY, HIDDEN_EMAIL_POLICY), for Slack
+// guests and users whose Slack profile hides their email.
+const (
+	// SubmitterPolicyAllow maps them to Notion users by email like members
+	// (SLACK_GUEST_POLICY default; hidden emails can't be mapped).
+	SubmitterPolicyAllow = "allow"
+
+	// SubmitterPolicyReject rejects their ideas with a message of its own
+	// (HIDDEN_EMAIL_POLICY default).
+	SubmitterPolicyReject = "reject"
+
+	// SubmitterPolicyTriage posts their ideas to GUEST_TRIAGE_CHANNEL for a
+	// member to review instead of creating them.
+	SubmitterPolicyTriage = "triage"
+
+	// SubmitterPolicyAttribute creates their ideas attributed to NOTION_FALLBACK_USER_ID.
+	SubmitterPolicyAttribute = "attribute"
+)
+
+// Submission backends (BACKEND), the tracker ideas are filed in.
+const (
+	// BackendNotion files ideas as pages of the Notion ideas database (default).
+	BackendNotion = "notion"
+
+	// BackendLinear files ideas as issues of a Linear team (see internal/linear).
+	BackendLinear = "linear"
+)
+
+// Cache backends (CACHE_BACKEND).
+const (
+	// CacheBackendMemory keeps the customer and user caches in each replica only (default).
+	CacheBackendMemory = "memory"
+
+	// CacheBackendRedis shares the caches between replicas through Redis, so
+	// one replica fetches them from Notion per refresh interval.
+	CacheBackendRedis = "redis"
+
+	// DefaultRedisKeyPrefix namespaces the bot's Redis keys.
+	DefaultRedisKeyPrefix = "hopperbot:"
+)
+
+// Fan-out backends (FANOUT_BACKENDS), secondary stores every submission is
+// also written to after the primary backend accepts it.
+const (
+	// FanoutPostgres archives submissions in a Postgres table (see pkg/pgstore).
+	FanoutPostgres = "postgres"
+
+	// DefaultPostgresTable is the archive table (POSTGRES_TABLE), created if missing.
+	DefaultPostgresTable = "hopperbot_ideas"
+)
+
+// Audit log backends (AUDIT_LOG_BACKEND), where submission attempts are recorded.
+const (
+	// AuditLogFile appends attempts to a JSON Lines file (AUDIT_LOG_FILE).
+	AuditLogFile = "file"
+
+	// AuditLogPostgres records attempts in a Postgres table (see pkg/pgstore).
+	AuditLogPostgres = "postgres"
+
+	// DefaultAuditLogTable is the audit log table (AUDIT_LOG_TABLE), created if missing.
+	DefaultAuditLogTable = "hopperbot_submission_attempts"
+
+	// DefaultAuditLogRetention is how long attempts are kept (AUDIT_LOG_RETENTION_DAYS).
+	DefaultAuditLogRetention = 90 * 24 * time.Hour
+
+	// DuplicateSubmissionWindow is how long after creating an idea the same
+	// user submitting the same fields again is rejected as a duplicate.
+	DuplicateSubmissionWindow = 10 * time.Minute
+
+	// AuditStatsPeriod is the period /hopperbot stats summarizes attempts over.
+	AuditStatsPeriod = 7 * 24 * time.Hour
+
+	// SubmissionQuotaWindow is the rolling period SUBMISSION_QUOTA_PER_DAY
+	// counts a user's submissions over.
+	SubmissionQuotaWindow = 24 * time.Hour
 )
 
 // Input length limits are based on Notion API constraints.
@@ -120,6 +272,11 @@ const (
 	// MaxCommentLength is the maximum character limit for rich text fields.
 	// Notion enforces a 2000 character limit on rich text properties.
 	MaxCommentLength = 2000
+
+	// MaxCommentBodyLength is the maximum character limit for comments written
+	// to the page body instead of the Comments property. Slack's text inputs
+	// accept at most 3000 characters.
+	MaxCommentBodyLength = 3000
 )
 
 // Time-based security limits.
@@ -148,9 +305,132 @@ const (
 	// when keep-alives are enabled.
 	ServerIdleTimeout = 120 * time.Second
 
+	// HandlerTimeout bounds the Slack endpoint handlers (middleware.WithTimeout).
+	HandlerTimeout = 30 * time.Second
+
 	// GracefulShutdownTimeout is the maximum time to wait for graceful shutdown.
 	// Allows in-flight requests to complete before forcing shutdown.
 	GracefulShutdownTimeout = 30 * time.Second
+
+	// DefaultConfirmationBatchThreshold is how many confirmations may reach the
+	// confirmation channel within DefaultConfirmationBatchWindow before the rest
+	// of a burst (e.g. a bulk import) is summarized instead.
+	DefaultConfirmationBatchThreshold = 10
+
+	// DefaultConfirmationBatchWindow is how often batched confirmations are posted.
+	DefaultConfirmationBatchWindow = 5 * time.Minute
+
+	// DefaultDigestSchedule is when the submissions digest is posted: Mondays at 09:00 UTC.
+	DefaultDigestSchedule = "0 9 * * MON"
+
+	// DigestPeriod is how far back the submissions digest looks.
+	DigestPeriod = 7 * 24 * time.Hour
+
+	// ManualRefreshReportTimeout bounds how long /hopperbot refresh-cache waits to
+	// report the outcome. Each cache retries for up to 5 minutes, and the
+	// command's response_url is valid for 30 minutes.
+	ManualRefreshReportTimeout = 20 * time.Minute
+
+	// ManualRefreshProgressDelay is how long /hopperbot refresh-cache runs
+	// before the admin is told it's still going.
+	ManualRefreshProgressDelay = 10 * time.Second
+
+	// AdminCacheRefreshTimeout bounds how long POST /admin/cache/refresh waits
+	// for the outcome before answering 202, within ServerWriteTimeout.
+	AdminCacheRefreshTimeout = 25 * time.Second
+
+	// DebugProfileDuration is how long GET /debug/pprof/profile and
+	// /debug/pprof/trace record without ?seconds=N. Longer recordings must
+	// still finish within ServerWriteTimeout.
+	DebugProfileDuration = 10 * time.Second
+
+	// DraftTTL is how long a shared submission draft can be opened.
+	DraftTTL = 7 * 24 * time.Hour
+
+	// AutosaveDraftTTL is how long the values of a closed submission modal are
+	// kept to restore the next time the user opens it.
+	AutosaveDraftTTL = 24 * time.Hour
+
+	// ComponentStopTimeout bounds how long shutdown waits for each background
+	// component (schedulers, queue, exporter) before moving on without it.
+	ComponentStopTimeout = 10 * time.Second
+
+	// SlackUserTimezoneTTL is how long a Slack user's resolved timezone is cached.
+	// Timezones change rarely (travel, relocation), so a day keeps users.info calls low.
+	SlackUserTimezoneTTL = 24 * time.Hour
+
+	// SlackUserProfileTTL is how long a Slack user's email and name are cached
+	// for Slack-to-Notion mapping. Cache refreshes reload them for every
+	// workspace with cached users, so this only bounds staleness while
+	// users.list fails.
+	SlackUserProfileTTL = 6 * time.Hour
+
+	// OptionsCacheTTL is how long an options response is reused for the same
+	// search. Responses are keyed by the customers they were built from, so
+	// this only bounds memory, not staleness.
+	OptionsCacheTTL = time.Minute
+
+	// OptionsCacheMaxEntries caps the options responses cached at once (each at
+	// most a few kilobytes).
+	OptionsCacheMaxEntries = 1000
+
+	// SlackAdminUsergroupTTL is how long SLACK_ADMIN_USERGROUP's members are
+	// cached, so adding or removing an admin takes effect within minutes.
+	SlackAdminUsergroupTTL = 5 * time.Minute
+
+	// SlackAdminCheckTimeout bounds the user group lookup before an admin
+	// subcommand, which has to fit in Slack's 3 second acknowledgement.
+	SlackAdminCheckTimeout = 2 * time.Second
+
+	// DefaultReminderCheckInterval is how often the reminder scheduler looks for due reminders.
+	// Reminders are days or weeks out, so minute-level precision is plenty.
+	DefaultReminderCheckInterval = 1 * time.Minute
+
+	// DefaultSubmissionQueueCheckInterval is how often the submission queue looks for jobs due a retry.
+	// New jobs are processed immediately; this only bounds how late a retry can run.
+	DefaultSubmissionQueueCheckInterval = 10 * time.Second
+
+	// DefaultBackgroundWorkers is how many background tasks (confirmations, DMs, queued submissions, status polls) run at a time.
+	DefaultBackgroundWorkers = 8
+
+	// BackgroundQueueSize is how many background tasks may wait for a worker.
+	BackgroundQueueSize = 256
+
+	// BackgroundSubmitTimeout bounds how long a request waits for room in a full
+	// background queue before dropping the task, well within Slack's 3 seconds.
+	BackgroundSubmitTimeout = 1 * time.Second
+
+	// DefaultPermissionCheckInterval is how often Notion integration permissions are re-probed.
+	// Share settings change rarely, so hourly catches regressions without adding API load.
+	DefaultPermissionCheckInterval = 1 * time.Hour
+
+	// DefaultFunnelCheckInterval is how often the funnel tracker polls open ideas' status.
+	// Triage takes hours to days, and each poll reads every open page, so hourly is enough.
+	DefaultFunnelCheckInterval = 1 * time.Hour
+
+	// DefaultStatusWatchInterval is how often submitted ideas' status is polled for changes to DM.
+	// Each poll reads every watched page; a quarter hour keeps notifications timely enough.
+	DefaultStatusWatchInterval = 15 * time.Minute
+
+	// ConfigFileCheckInterval is how often CONFIG_FILE is checked for changes to reload.
+	// Checking is a single stat, so edits apply within seconds at no real cost.
+	ConfigFileCheckInterval = 10 * time.Second
+
+	// SecretFileCheckInterval is how often SLACK_BOT_TOKEN_FILE and NOTION_API_KEY_FILE are re-read
+	// to rotate their credential. Mounted secrets update within a minute or so of a change anyway.
+	SecretFileCheckInterval = 30 * time.Second
+
+	// FanoutRetryInterval is how often failed writes to secondary backends are retried.
+	// Retries back off from a minute, so checking every minute keeps them on time.
+	FanoutRetryInterval = 1 * time.Minute
+
+	// FanoutWriteTimeout bounds one write to a secondary backend, so a slow
+	// archive delays the submission confirmation by at most this much.
+	FanoutWriteTimeout = 5 * time.Second
+
+	// AuditLogPruneInterval is how often attempts past the audit log retention are deleted.
+	// Retention is counted in days, so pruning hourly keeps the log within an hour of it.
+	AuditLogPruneInterval = 1 * time.Hour
 )
 
 // Notion API configuration constants.
@@ -163,12 +443,128 @@ const (
 	// Using a fixed version ensures consistent behavior.
 	NotionAPIVersion = "2025-09-03"
 
+	// NotionAPIVersionLegacy is the version before multi-source databases,
+	// still accepted by NOTION_API_VERSION (see notion.Client.SetAPIVersion).
+	NotionAPIVersionLegacy = "2022-06-28"
+
 	// NotionAPIBaseURL is the base URL for all Notion API requests.
 	NotionAPIBaseURL = "https://api.notion.com/v1"
+
+	// NotionCreateMaxAttempts is the maximum number of page creation attempts per submission.
+	// Kept low because the user is waiting on the modal for the result.
+	NotionCreateMaxAttempts = 3
+
+	// NotionCreateInitialBackoff is the delay before the first page creation retry (doubles each retry).
+	NotionCreateInitialBackoff = 500 * time.Millisecond
+
+	// NotionHealthLatencyThreshold is the rolling p95 latency of the notion_api
+	// readiness check above which Notion is reported degraded. Below the
+	// health manager's 3s check timeout, so slowness shows before failures do.
+	NotionHealthLatencyThreshold = 2 * time.Second
+)
+
+// Linear API configuration constants.
+const (
+	// LinearAPIURL is the Linear GraphQL endpoint.
+	LinearAPIURL = "https://api.linear.app/graphql"
+
+	// LinearPageSize is the number of items to fetch per page (Linear's maximum is 250).
+	LinearPageSize = 100
+)
+
+// Outbound HTTP retry and circuit breaker settings for the Notion and Slack
+// clients (see pkg/httpclient).
+const (
+	// HTTPClientMaxAttempts is the number of attempts per outbound request, including the first.
+	HTTPClientMaxAttempts = 3
+
+	// HTTPClientInitialBackoff caps the delay before the first retry (doubles each retry, jittered).
+	HTTPClientInitialBackoff = 200 * time.Millisecond
+
+	// HTTPClientMaxBackoff caps the delay between retries. Longer Retry-After
+	// values aren't waited for, since users are usually waiting on the result.
+	HTTPClientMaxBackoff = 2 * time.Second
+
+	// HTTPClientAttemptTimeout bounds each attempt, so a hung connection leaves
+	// time for a retry within DefaultHTTPTimeout.
+	HTTPClientAttemptTimeout = 15 * time.Second
+
+	// CircuitBreakerFailureThreshold is the number of consecutive failed requests
+	// to a host that opens its circuit.
+	CircuitBreakerFailureThreshold = 5
+
+	// CircuitBreakerOpenDuration is how long an open circuit fails requests fast
+	// before letting a probe through.
+	CircuitBreakerOpenDuration = 30 * time.Second
 )
 
+// HTTP route paths.
+// Shared by route registration in main.go and external URL generation
+// (Slack app manifest) so the two never drift apart.
+const (
+	RouteSlackCommand       = "/slack/command"
+	RouteSlackInteractive   = "/slack/interactive"
+	RouteSlackOptions       = "/slack/options"
+	RouteSlackEvents        = "/slack/events"
+	RouteSlackOAuthInstall  = "/slack/oauth/install"
+	RouteSlackOAuthCallback = "/slack/oauth/callback"
+	RouteWebSubmit          = "/web/submit" // Web intake form (WEB_FORM_ENABLED)
+	RouteMetrics            = "/metrics"
+	RouteHealth             = "/health"
+	RouteReady              = "/ready"
+	RouteVersion            = "/version"
+
+	// Admin endpoints (require ADMIN_TOKEN bearer auth and/or admin mTLS; disabled when neither is set)
+	RouteAdminPermissions  = "/admin/permissions"
+	RouteAdminDatabases    = "/admin/databases"
+	RouteAdminCache        = "/admin/cache"
+	RouteAdminCacheRefresh = "/admin/cache/refresh"
+	RouteAdminConfig       = "/admin/config"
+	RouteAdminFlags        = "/admin/flags"
+	RouteAdminFunnel       = "/admin/funnel"
+	RouteAdminCredentials  = "/admin/credentials"
+
+	// Debug endpoints (same auth as the admin endpoints)
+	RouteDebugPprof   = "/debug/pprof/" // Prefix: /debug/pprof/<profile>
+	RouteDebugRuntime = "/debug/runtime"
+)
+
+// SlashCommand is the slash command registered in the Slack app.
+const SlashCommand = "/hopperbot"
+
+// ShortcutSubmitMessage is the callback ID of the "Submit as idea" message
+// shortcut registered in the Slack app.
+const ShortcutSubmitMessage = "submit_message_idea"
+
 // Default configuration values.
 const (
 	// DefaultPort is the default HTTP server port.
 	DefaultPort = "8080"
+
+	// ServerMaxHeaderBytes is the default maximum request header size (matches http.DefaultMaxHeaderBytes).
+	// Slack requests carry small headers, so operators may lower this under heavy load.
+	ServerMaxHeaderBytes = 1 << 20
+
+	// DefaultCompressionMinBytes is the smallest response gzipped on the options endpoint.
+	// Below ~1KB the gzip header and CPU cost outweigh the bandwidth saved.
+	DefaultCompressionMinBytes = 1024
+
+	// DefaultTrustedProxyHops is how many reverse proxies are assumed to sit in front of
+	// the server with TRUST_PROXY_HEADERS: one ingress or load balancer.
+	DefaultTrustedProxyHops = 1
+
+	// DefaultRateLimitPerMinute is the sustained request rate allowed per Slack user and endpoint.
+	// Generous enough for customer search typeahead, which loads options as the user types.
+	DefaultRateLimitPerMinute = 120
+
+	// DefaultRateLimitBurst is how many requests a Slack user may make at once per endpoint.
+	DefaultRateLimitBurst = 30
+
+	// WebFormRateLimitPerMinute is the sustained rate of posts allowed per client IP to a
+	// public browser form (see server.HandleForm); a person files an idea every few minutes at most.
+	WebFormRateLimitPerMinute = 2
+
+	// WebFormRateLimitBurst is how many posts a client IP may make to a public form at once,
+	// leaving room to fix a rejected submission.
+	WebFormRateLimitBurst = 5
 )
@@ -255,6 +255,9 @@ func TestSlackConstants(t *testing.T) {
 	if MaxCustomerOrgSelections > 100 {
 		t.Errorf("MaxCustomerOrgSelections (%d) exceeds typical Slack limits", MaxCustomerOrgSelections)
 	}
+	if MaxOptionsResults > SlackMaxOptions {
+		t.Errorf("MaxOptionsResults (%d) exceeds Slack's limit of %d options", MaxOptionsResults, SlackMaxOptions)
+	}
 }
 
 // TestLengthLimitComparison tests that field length limits are consistent
@@ -0,0 +1,155 @@
+// Package credentials rotates the Slack bot token and Notion API key of a
+// running bot without a restart.
+//
+// A new value comes from POST /admin/credentials or, for a credential read
+// from a secret file (SLACK_BOT_TOKEN_FILE, NOTION_API_KEY_FILE), from the
+// file changing: CheckFiles, run periodically by pkg/scheduler, re-reads the
+// files. Each credential's RotateFunc checks the value against its API before
+// switching to it, so a revoked or mistyped value never takes effect.
+//
+// Features:
+// - Rotations are serialized and a value already in use is a no-op
+// - Secret files only rotate when their content changes, so a rejected value isn't retried
+// - Credential values never reach logs or errors
+// - Rotations are counted by credential, source and outcome (hopperbot_credential_rotations_total)
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// Rotatable credentials, named as in POST /admin/credentials and the metric's
+// credential label.
+const (
+	SlackBotToken = "slack_bot_token"
+	NotionAPIKey  = "notion_api_key"
+)
+
+// Sources of a new credential value, used as the metric's source label.
+const (
+	SourceAdmin = "admin" // POST /admin/credentials
+	SourceFile  = "file"  // The credential's secret file changed
+)
+
+// RotateFunc checks a new credential value and, if it works, switches to it.
+type RotateFunc func(ctx context.Context, value string) error
+
+// Rotator rotates registered credentials.
+type Rotator struct {
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+
+	mu          sync.Mutex // Serializes rotations and guards credentials
+	credentials map[string]*credential
+}
+
+// credential is a registered credential and its current value.
+type credential struct {
+	rotate    RotateFunc
+	file      string // Secret file the value is read from; empty when it comes from the environment
+	fileValue string // Content of file when last read
+	current   string // Value in use
+}
+
+// New returns a rotator with no credentials registered.
+func New(m *metrics.Metrics, logger *zap.Logger) *Rotator {
+	return &Rotator{
+		metrics:     m,
+		logger:      logger,
+		credentials: make(map[string]*credential),
+	}
+}
+
+// Register adds a credential with the value in use. file is the secret file
+// it was read from, watched by CheckFiles; empty leaves it to the admin API.
+func (r *Rotator) Register(name, value, file string, rotate RotateFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.credentials[name] = &credential{rotate: rotate, file: file, fileValue: value, current: value}
+}
+
+// Names returns the registered credentials in sorted order.
+func (r *Rotator) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.credentials))
+	for name := range r.credentials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rotate switches a credential to value. On error the current value stays in
+// use. It reports whether the value changed (false when already in use).
+func (r *Rotator) Rotate(ctx context.Context, name, value, source string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cred, ok := r.credentials[name]
+	if !ok {
+		return false, fmt.Errorf("unknown credential %q", name)
+	}
+	return r.rotate(ctx, name, cred, value, source)
+}
+
+// rotate switches cred to value; r.mu must be held.
+func (r *Rotator) rotate(ctx context.Context, name string, cred *credential, value, source string) (bool, error) {
+	if value == "" {
+		return false, fmt.Errorf("%s must not be empty", name)
+	}
+	if value == cred.current {
+		return false, nil
+	}
+
+	if err := cred.rotate(ctx, value); err != nil {
+		r.metrics.CredentialRotationsTotal.WithLabelValues(name, source, "failure").Inc()
+		r.logger.Error("credential rotation failed, keeping the current value",
+			zap.String("credential", name),
+			zap.String("source", source),
+			zap.Error(err),
+		)
+		return false, fmt.Errorf("rotating %s: %w", name, err)
+	}
+
+	cred.current = value
+	r.metrics.CredentialRotationsTotal.WithLabelValues(name, source, "success").Inc()
+	r.logger.Info("credential rotated", zap.String("credential", name), zap.String("source", source))
+	return true, nil
+}
+
+// CheckFiles re-reads the secret files of credentials registered with one and
+// rotates those whose content changed since the last check.
+func (r *Rotator) CheckFiles(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for name, cred := range r.credentials {
+		if cred.file == "" {
+			continue
+		}
+		value, err := config.ReadSecretFile(cred.file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reading %s: %w", name, err))
+			continue
+		}
+		if value == cred.fileValue {
+			continue
+		}
+		cred.fileValue = value
+		if _, err := r.rotate(ctx, name, cred, value, SourceFile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,118 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// fakeAPI accepts the values in valid and records those it switched to
+type fakeAPI struct {
+	valid    map[string]bool
+	switched []string
+}
+
+func (f *fakeAPI) rotate(_ context.Context, value string) error {
+	if !f.valid[value] {
+		return errors.New("invalid_auth")
+	}
+	f.switched = append(f.switched, value)
+	return nil
+}
+
+func rotations(name, source, status string) float64 {
+	return testutil.ToFloat64(metrics.Get().CredentialRotationsTotal.WithLabelValues(name, source, status))
+}
+
+func TestRotate(t *testing.T) {
+	api := &fakeAPI{valid: map[string]bool{"xoxb-new": true}}
+	r := New(metrics.Init(), zap.NewNop())
+	r.Register(SlackBotToken, "xoxb-old", "", api.rotate)
+
+	successes := rotations(SlackBotToken, SourceAdmin, "success")
+	failures := rotations(SlackBotToken, SourceAdmin, "failure")
+
+	if _, err := r.Rotate(context.Background(), SlackBotToken, "xoxb-revoked", SourceAdmin); err == nil {
+		t.Error("Rotate() with a rejected value succeeded")
+	} else if strings.Contains(err.Error(), "xoxb-revoked") {
+		t.Errorf("Rotate() error %q contains the credential", err)
+	}
+	if changed, err := r.Rotate(context.Background(), SlackBotToken, "xoxb-new", SourceAdmin); err != nil || !changed {
+		t.Errorf("Rotate() = %v, %v, want true, nil", changed, err)
+	}
+	if changed, err := r.Rotate(context.Background(), SlackBotToken, "xoxb-new", SourceAdmin); err != nil || changed {
+		t.Errorf("Rotate() to the value in use = %v, %v, want false, nil", changed, err)
+	}
+	if _, err := r.Rotate(context.Background(), NotionAPIKey, "secret_new", SourceAdmin); err == nil {
+		t.Error("Rotate() of an unregistered credential succeeded")
+	}
+
+	if !slices.Equal(api.switched, []string{"xoxb-new"}) {
+		t.Errorf("switched to %v, want [xoxb-new]", api.switched)
+	}
+	if got := rotations(SlackBotToken, SourceAdmin, "success") - successes; got != 1 {
+		t.Errorf("successful rotations = %v, want 1", got)
+	}
+	if got := rotations(SlackBotToken, SourceAdmin, "failure") - failures; got != 1 {
+		t.Errorf("failed rotations = %v, want 1", got)
+	}
+}
+
+func TestCheckFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notion-api-key")
+	write := func(value string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(value+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("secret_1")
+
+	api := &fakeAPI{valid: map[string]bool{"secret_2": true, "secret_3": true}}
+	r := New(metrics.Init(), zap.NewNop())
+	r.Register(NotionAPIKey, "secret_1", path, api.rotate)
+	r.Register(SlackBotToken, "xoxb-env", "", (&fakeAPI{}).rotate) // No file to check
+
+	if err := r.CheckFiles(context.Background()); err != nil || len(api.switched) != 0 {
+		t.Fatalf("CheckFiles() on unchanged files = %v, switched %v", err, api.switched)
+	}
+
+	write("secret_2")
+	if err := r.CheckFiles(context.Background()); err != nil {
+		t.Fatalf("CheckFiles() error = %v", err)
+	}
+
+	// A rejected value is reported once, then left until the file changes again
+	write("secret_bad")
+	if err := r.CheckFiles(context.Background()); err == nil {
+		t.Error("CheckFiles() with a rejected value error = nil")
+	}
+	if err := r.CheckFiles(context.Background()); err != nil {
+		t.Errorf("CheckFiles() retried a rejected value: %v", err)
+	}
+
+	// A value set through the admin API isn't reverted by the unchanged file
+	if _, err := r.Rotate(context.Background(), NotionAPIKey, "secret_3", SourceAdmin); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if err := r.CheckFiles(context.Background()); err != nil {
+		t.Errorf("CheckFiles() error = %v", err)
+	}
+
+	if want := []string{"secret_2", "secret_3"}; !slices.Equal(api.switched, want) {
+		t.Errorf("switched to %v, want %v", api.switched, want)
+	}
+
+	os.Remove(path)
+	if err := r.CheckFiles(context.Background()); err == nil {
+		t.Error("CheckFiles() with a missing file error = nil")
+	}
+}
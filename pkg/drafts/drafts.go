@@ -0,0 +1,122 @@
+// Package drafts stores submission drafts shared between teammates.
+//
+// A user filling in the submission modal can share what they have so far with
+// a teammate. The values are saved as a Draft with a random ID; both users get
+// a DM with an "Open draft" button that opens the modal pre-filled from it.
+// Only those two users may open a draft, and drafts expire after a while (or
+// once submitted).
+//
+// Features:
+// - JSON file-backed store so drafts survive restarts (memory-only when no path is set)
+// - Expired drafts are dropped on access, so no background cleanup is needed
+package drafts
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/jsonfile"
+)
+
+// Draft is a partially filled submission shared by its author with a recipient.
+type Draft struct {
+	ID           string    `json:"id"`
+	SlackTeamID  string    `json:"slack_team_id"`
+	AuthorID     string    `json:"author_id"`    // Slack user who shared the draft
+	RecipientID  string    `json:"recipient_id"` // Slack user it was shared with
+	Title        string    `json:"title,omitempty"`
+	Theme        string    `json:"theme,omitempty"`
+	ProductArea  string    `json:"product_area,omitempty"`
+	Comments     string    `json:"comments,omitempty"`
+	CustomerOrgs []string  `json:"customer_orgs,omitempty"` // Customer names
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// CanOpen reports whether a Slack user may open the draft: only its author
+// and recipient, in the workspace it was shared in.
+func (d Draft) CanOpen(teamID, userID string) bool {
+	return teamID == d.SlackTeamID && (userID == d.AuthorID || userID == d.RecipientID)
+}
+
+// Expired reports whether the draft has expired at now.
+func (d Draft) Expired(now time.Time) bool {
+	return !now.Before(d.ExpiresAt)
+}
+
+// NewID returns a random, unguessable draft ID.
+func NewID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate draft ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Store persists shared drafts.
+type Store interface {
+	// Save inserts or replaces a draft (keyed by ID).
+	Save(draft Draft) error
+
+	// Get returns the draft with the given ID, or false if it doesn't exist or has expired at now.
+	Get(id string, now time.Time) (Draft, bool, error)
+
+	// Delete removes a draft. Deleting an unknown ID is not an error.
+	Delete(id string) error
+}
+
+// FileStore is a Store holding drafts by ID in a jsonfile.Map. With an empty
+// path the store is memory-only and drafts are lost on restart.
+type FileStore struct {
+	*jsonfile.Map[string, Draft]
+}
+
+// NewFileStore creates a store backed by path, loading any drafts already saved there.
+// A missing file is treated as empty; an empty path creates a memory-only store.
+func NewFileStore(path string) (*FileStore, error) {
+	drafts, err := jsonfile.NewMap(path, "drafts file",
+		func(draft Draft) string { return draft.ID },
+		func(a, b Draft) bool { return a.CreatedAt.Before(b.CreatedAt) })
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{drafts}, nil
+}
+
+// Save inserts or replaces a draft, dropping drafts that have expired by its creation time.
+func (s *FileStore) Save(draft Draft) error {
+	if draft.ID == "" {
+		return fmt.Errorf("draft ID is required")
+	}
+
+	return s.Update(func(drafts map[string]Draft) bool {
+		for id, existing := range drafts {
+			if existing.Expired(draft.CreatedAt) {
+				delete(drafts, id)
+			}
+		}
+		drafts[draft.ID] = draft
+		return true
+	})
+}
+
+// Get returns an unexpired draft. An expired draft is removed.
+func (s *FileStore) Get(id string, now time.Time) (Draft, bool, error) {
+	draft, ok := s.Map.Get(id)
+	if !ok {
+		return Draft{}, false, nil
+	}
+	if draft.Expired(now) {
+		return Draft{}, false, s.Update(func(drafts map[string]Draft) bool {
+			// Only drop the draft read above, not one saved again since
+			if current, ok := drafts[id]; !ok || !current.Expired(now) {
+				return false
+			}
+			delete(drafts, id)
+			return true
+		})
+	}
+	return draft, true, nil
+}
@@ -0,0 +1,108 @@
+package drafts
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestFileStore_Persistence tests that drafts survive reopening the store
+func TestFileStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "drafts.json")
+	now := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	draft := Draft{
+		ID:           "draft-1",
+		SlackTeamID:  "T1",
+		AuthorID:     "U-author",
+		RecipientID:  "U-teammate",
+		Title:        "Bulk export",
+		CustomerOrgs: []string{"Acme"},
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(time.Hour),
+	}
+	if err := store.Save(draft); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Save(Draft{ID: "draft-2", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Delete("draft-2"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() reopen error = %v", err)
+	}
+	if reopened.Len() != 1 {
+		t.Errorf("Len() = %d after reopening, want 1", reopened.Len())
+	}
+	got, ok, err := reopened.Get("draft-1", now)
+	if err != nil || !ok {
+		t.Fatalf("Get() = %v, %v, want the saved draft", ok, err)
+	}
+	if got.Title != draft.Title || got.RecipientID != draft.RecipientID || !slices.Equal(got.CustomerOrgs, draft.CustomerOrgs) {
+		t.Errorf("Get() = %+v, want %+v", got, draft)
+	}
+}
+
+// TestFileStore_Expiry tests that expired drafts can't be opened and are dropped
+func TestFileStore_Expiry(t *testing.T) {
+	now := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+	store, _ := NewFileStore("")
+
+	store.Save(Draft{ID: "old", CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+	if _, ok, _ := store.Get("old", now.Add(time.Hour)); ok {
+		t.Error("Get() returned a draft at its expiry time")
+	}
+	if store.Len() != 0 {
+		t.Errorf("Len() = %d, want the expired draft dropped", store.Len())
+	}
+
+	// Saving drops drafts expired by then
+	store.Save(Draft{ID: "stale", CreatedAt: now, ExpiresAt: now.Add(time.Minute)})
+	store.Save(Draft{ID: "new", CreatedAt: now.Add(time.Hour), ExpiresAt: now.Add(2 * time.Hour)})
+	if store.Len() != 1 {
+		t.Errorf("Len() = %d, want only the new draft", store.Len())
+	}
+
+	if err := store.Save(Draft{}); err == nil {
+		t.Error("Save() without an ID error = nil, want error")
+	}
+}
+
+// TestDraft_CanOpen tests that only the two users in the draft's workspace may open it
+func TestDraft_CanOpen(t *testing.T) {
+	draft := Draft{SlackTeamID: "T1", AuthorID: "U1", RecipientID: "U2"}
+	tests := []struct {
+		teamID, userID string
+		want           bool
+	}{
+		{"T1", "U1", true},
+		{"T1", "U2", true},
+		{"T1", "U3", false},
+		{"T2", "U2", false},
+	}
+	for _, tt := range tests {
+		if got := draft.CanOpen(tt.teamID, tt.userID); got != tt.want {
+			t.Errorf("CanOpen(%s, %s) = %v, want %v", tt.teamID, tt.userID, got, tt.want)
+		}
+	}
+}
+
+func TestNewID(t *testing.T) {
+	a, err := NewID()
+	if err != nil {
+		t.Fatalf("NewID() error = %v", err)
+	}
+	b, _ := NewID()
+	if len(a) != 32 || a == b {
+		t.Errorf("NewID() = %q, %q, want distinct 32-character IDs", a, b)
+	}
+}
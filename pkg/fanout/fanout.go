@@ -0,0 +1,268 @@
+// Package fanout writes submissions to secondary backends (FANOUT_BACKENDS),
+// such as a Postgres archive, after the primary backend has created the idea.
+//
+// The primary backend (Notion or Linear) stays the source of truth: a
+// submission succeeds or fails on it alone. Each accepted submission is then
+// written to every target concurrently. A target that fails doesn't hold up
+// the others; its write is stored and retried with exponential backoff by a
+// scheduled job, so a secondary outage delays copies instead of losing them.
+// The results of the first attempt are returned so the submitter can be told
+// where their idea was saved.
+//
+// Features:
+// - Concurrent writes with a per-target timeout
+// - JSON file-backed retry store so pending writes survive restarts (memory-only when no path is set)
+// - Exponential backoff with a bounded number of attempts; a newer version of an idea replaces a pending one
+// - Metrics for writes by target and outcome and the pending retries per target
+package fanout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+const (
+	// Retry configuration for failed writes
+	baseRetryDelay = 1 * time.Minute // Doubled after every failed attempt
+	maxRetryDelay  = 1 * time.Hour
+	maxAttempts    = 20 // About 16 hours of retries
+)
+
+// Record is one version of an idea as accepted by the primary backend.
+type Record struct {
+	PageID      string                `json:"page_id"`      // ID of the idea in the primary backend
+	PageURL     string                `json:"page_url"`     // Link to the idea in the primary backend
+	CreatedTime time.Time             `json:"created_time"` // When the primary backend created the idea
+	UpdatedAt   time.Time             `json:"updated_at"`   // When this version was written (creation or edit)
+	Submission  submission.Submission `json:"submission"`
+}
+
+// Target is a secondary backend.
+type Target interface {
+	// Name identifies the target in logs, metrics and messages (e.g. "postgres").
+	Name() string
+
+	// Write stores a record. It must be an idempotent upsert keyed by PageID,
+	// since a write is retried after ambiguous failures, and must not replace a
+	// version with an older UpdatedAt, since a retry can run after a newer write.
+	Write(ctx context.Context, rec Record) error
+}
+
+// Result is the outcome of the first write of a record to one target.
+type Result struct {
+	Target   string
+	Err      error // nil when the write succeeded
+	Retrying bool  // The failed write was stored for retry
+}
+
+// Fanout writes records to its targets and retries failed writes.
+type Fanout struct {
+	targets []Target
+	store   Store
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+	timeout time.Duration
+	now     func() time.Time // Overridable for tests
+}
+
+// New creates a fan-out to targets, with failed writes kept in store.
+// metrics may be nil to disable metrics recording.
+func New(targets []Target, store Store, m *metrics.Metrics, logger *zap.Logger) *Fanout {
+	f := &Fanout{
+		targets: targets,
+		store:   store,
+		metrics: m,
+		logger:  logger,
+		timeout: constants.FanoutWriteTimeout,
+		now:     time.Now,
+	}
+	f.recordPending()
+	return f
+}
+
+// Targets returns the names of the targets, in configuration order.
+func (f *Fanout) Targets() []string {
+	names := make([]string, len(f.targets))
+	for i, target := range f.targets {
+		names[i] = target.Name()
+	}
+	return names
+}
+
+// Write writes rec to every target concurrently and returns one result per
+// target, in configuration order. A failed write is stored for retry,
+// replacing any older version of the same idea still pending for the target;
+// a successful one drops it. Records should have UpdatedAt set, as it orders
+// versions of an idea.
+func (f *Fanout) Write(ctx context.Context, rec Record) []Result {
+	results := make([]Result, len(f.targets))
+	var wg sync.WaitGroup
+	for i, target := range f.targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = f.write(ctx, target, rec)
+		}()
+	}
+	wg.Wait()
+	f.recordPending()
+	return results
+}
+
+// write makes the first attempt to write rec to target.
+func (f *Fanout) write(ctx context.Context, target Target, rec Record) Result {
+	result := Result{Target: target.Name()}
+	result.Err = f.attempt(ctx, target, rec)
+	if result.Err == nil {
+		f.record(target.Name(), "success")
+		if err := f.store.Delete(target.Name(), rec.PageID, rec.UpdatedAt); err != nil {
+			f.logger.Error("failed to drop superseded fan-out retry", zap.String("target", target.Name()), zap.String("page_id", rec.PageID), zap.Error(err))
+		}
+		return result
+	}
+
+	f.record(target.Name(), "failed")
+	pending := Pending{
+		Target:        target.Name(),
+		Record:        rec,
+		Attempts:      1,
+		FirstFailedAt: f.now().UTC(),
+		NextAttemptAt: f.now().Add(retryDelay(1)).UTC(),
+		LastError:     result.Err.Error(),
+	}
+	if err := f.store.Add(pending); err != nil {
+		f.logger.Error("failed to store fan-out write for retry, the copy is lost",
+			zap.String("target", target.Name()),
+			zap.String("page_id", rec.PageID),
+			zap.NamedError("write_error", result.Err),
+			zap.Error(err),
+		)
+		return result
+	}
+	f.logger.Warn("fan-out write failed, will retry",
+		zap.String("target", target.Name()),
+		zap.String("page_id", rec.PageID),
+		zap.Time("retry_at", pending.NextAttemptAt),
+		zap.Error(result.Err),
+	)
+	result.Retrying = true
+	return result
+}
+
+// RetryDue retries the stored writes that are due, oldest first. It is meant
+// to run as a scheduled job; it only returns an error when the store fails.
+// Writes to targets no longer configured are dropped.
+func (f *Fanout) RetryDue(ctx context.Context) error {
+	due, err := f.store.Due(f.now())
+	if err != nil {
+		return fmt.Errorf("failed to load due fan-out writes: %w", err)
+	}
+	defer f.recordPending()
+
+	targets := make(map[string]Target, len(f.targets))
+	for _, target := range f.targets {
+		targets[target.Name()] = target
+	}
+
+	var errs []error
+	for _, pending := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		target, ok := targets[pending.Target]
+		if !ok {
+			f.logger.Warn("dropping fan-out write to a target that is no longer configured",
+				zap.String("target", pending.Target),
+				zap.String("page_id", pending.Record.PageID),
+			)
+			errs = append(errs, f.store.Delete(pending.Target, pending.Record.PageID, pending.Record.UpdatedAt))
+			continue
+		}
+		errs = append(errs, f.retry(ctx, target, pending))
+	}
+	return errors.Join(errs...)
+}
+
+// retry makes another attempt at a stored write and updates the store with the outcome.
+func (f *Fanout) retry(ctx context.Context, target Target, pending Pending) error {
+	err := f.attempt(ctx, target, pending.Record)
+	if err == nil {
+		f.logger.Info("fan-out write succeeded after retrying",
+			zap.String("target", pending.Target),
+			zap.String("page_id", pending.Record.PageID),
+			zap.Int("failed_attempts", pending.Attempts),
+		)
+		f.record(pending.Target, "success")
+		return f.store.Delete(pending.Target, pending.Record.PageID, pending.Record.UpdatedAt)
+	}
+
+	pending.Attempts++
+	pending.LastError = err.Error()
+	if pending.Attempts >= maxAttempts {
+		f.logger.Error("giving up on fan-out write",
+			zap.String("target", pending.Target),
+			zap.String("page_id", pending.Record.PageID),
+			zap.String("page_url", pending.Record.PageURL),
+			zap.Int("attempts", pending.Attempts),
+			zap.Time("first_failed_at", pending.FirstFailedAt),
+			zap.Error(err),
+		)
+		f.record(pending.Target, "abandoned")
+		return f.store.Delete(pending.Target, pending.Record.PageID, pending.Record.UpdatedAt)
+	}
+
+	pending.NextAttemptAt = f.now().Add(retryDelay(pending.Attempts)).UTC()
+	f.logger.Warn("fan-out write failed again, will retry",
+		zap.String("target", pending.Target),
+		zap.String("page_id", pending.Record.PageID),
+		zap.Int("attempts", pending.Attempts),
+		zap.Time("retry_at", pending.NextAttemptAt),
+		zap.Error(err),
+	)
+	f.record(pending.Target, "retried")
+	return f.store.Add(pending)
+}
+
+// attempt writes rec to target within the per-target timeout.
+func (f *Fanout) attempt(ctx context.Context, target Target, rec Record) error {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+	return target.Write(ctx, rec)
+}
+
+// retryDelay returns the backoff after the given number of failed attempts.
+func retryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+func (f *Fanout) record(target, outcome string) {
+	if f.metrics == nil {
+		return
+	}
+	f.metrics.FanoutWritesTotal.WithLabelValues(target, outcome).Inc()
+}
+
+func (f *Fanout) recordPending() {
+	if f.metrics == nil {
+		return
+	}
+	counts := f.store.Counts()
+	for _, target := range f.targets {
+		f.metrics.FanoutPending.WithLabelValues(target.Name()).Set(float64(counts[target.Name()]))
+	}
+}
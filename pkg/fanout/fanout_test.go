@@ -0,0 +1,223 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"go.uber.org/zap"
+)
+
+// fakeTarget records written records and fails while err is set.
+type fakeTarget struct {
+	name string
+
+	mu      sync.Mutex
+	err     error
+	written []Record
+}
+
+func (t *fakeTarget) Name() string { return t.name }
+
+func (t *fakeTarget) Write(_ context.Context, rec Record) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return t.err
+	}
+	t.written = append(t.written, rec)
+	return nil
+}
+
+func (t *fakeTarget) setErr(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.err = err
+}
+
+func (t *fakeTarget) titles() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var titles []string
+	for _, rec := range t.written {
+		titles = append(titles, rec.Submission.Title)
+	}
+	return titles
+}
+
+func record(pageID, title string, version time.Time) Record {
+	return Record{
+		PageID:     pageID,
+		PageURL:    "https://www.notion.so/" + pageID,
+		UpdatedAt:  version,
+		Submission: submission.Submission{Title: title},
+	}
+}
+
+// TestFileStore_Persistence tests that pending writes survive reopening the store
+func TestFileStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fanout.json")
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	for _, p := range []Pending{
+		{Target: "postgres", Record: record("later", "Later", now), FirstFailedAt: now.Add(-2 * time.Hour), NextAttemptAt: now.Add(time.Hour)},
+		{Target: "postgres", Record: record("second", "Second", now), FirstFailedAt: now.Add(-time.Minute), NextAttemptAt: now},
+		{Target: "postgres", Record: record("first", "First", now), FirstFailedAt: now.Add(-time.Hour), NextAttemptAt: now},
+	} {
+		if err := store.Add(p); err != nil {
+			t.Fatalf("Add(%s) error = %v", p.Record.PageID, err)
+		}
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopening store: %v", err)
+	}
+	if counts := reopened.Counts(); counts["postgres"] != 3 {
+		t.Fatalf("Counts() = %v, want 3 for postgres", counts)
+	}
+	due, _ := reopened.Due(now)
+	if len(due) != 2 || due[0].Record.PageID != "first" || due[1].Record.PageID != "second" {
+		t.Fatalf("Due() = %v, want [first second]", due)
+	}
+	if due[0].Record.Submission.Title != "First" {
+		t.Errorf("record not round-tripped: %+v", due[0].Record)
+	}
+
+	if err := store.Add(Pending{Target: "postgres"}); err == nil {
+		t.Error("Add() without a page ID error = nil, want error")
+	}
+}
+
+// TestFileStore_Versions tests that an older version of an idea never replaces
+// or removes a newer pending one
+func TestFileStore_Versions(t *testing.T) {
+	older := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Minute)
+	store, _ := NewFileStore("")
+
+	store.Add(Pending{Target: "postgres", Record: record("page-1", "Edited", newer)})
+	store.Add(Pending{Target: "postgres", Record: record("page-1", "Original", older)})
+	store.Delete("postgres", "page-1", older)
+
+	due, _ := store.Due(newer)
+	if len(due) != 1 || due[0].Record.Submission.Title != "Edited" {
+		t.Fatalf("Due() = %v, want the edited version", due)
+	}
+
+	store.Delete("postgres", "page-1", newer)
+	if counts := store.Counts(); counts["postgres"] != 0 {
+		t.Errorf("Counts() after deleting = %v, want none", counts)
+	}
+}
+
+// TestFanout_Write tests that a failing target doesn't affect the others and
+// that its write is stored for retry
+func TestFanout_Write(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	archive := &fakeTarget{name: "postgres"}
+	warehouse := &fakeTarget{name: "warehouse", err: errors.New("connection refused")}
+	store, _ := NewFileStore("")
+	f := New([]Target{archive, warehouse}, store, nil, zap.NewNop())
+	f.now = func() time.Time { return now }
+
+	results := f.Write(context.Background(), record("page-1", "Dark mode", now))
+	if len(results) != 2 {
+		t.Fatalf("Write() returned %d results, want 2", len(results))
+	}
+	if r := results[0]; r.Target != "postgres" || r.Err != nil || r.Retrying {
+		t.Errorf("results[0] = %+v, want a success for postgres", r)
+	}
+	if r := results[1]; r.Target != "warehouse" || r.Err == nil || !r.Retrying {
+		t.Errorf("results[1] = %+v, want a retrying failure for warehouse", r)
+	}
+
+	due, _ := store.Due(now.Add(baseRetryDelay))
+	if len(due) != 1 || due[0].Target != "warehouse" || due[0].Attempts != 1 || !strings.Contains(due[0].LastError, "refused") {
+		t.Fatalf("pending = %+v, want one warehouse write", due)
+	}
+	if got := f.Targets(); len(got) != 2 || got[0] != "postgres" || got[1] != "warehouse" {
+		t.Errorf("Targets() = %v", got)
+	}
+
+	// A newer version written successfully drops the pending older one
+	warehouse.setErr(nil)
+	f.Write(context.Background(), record("page-1", "Dark mode (edited)", now.Add(time.Minute)))
+	if counts := store.Counts(); counts["warehouse"] != 0 {
+		t.Errorf("Counts() = %v, want the superseded retry dropped", counts)
+	}
+}
+
+// TestFanout_RetryDue tests success, backoff, giving up, and dropping writes
+// to targets no longer configured
+func TestFanout_RetryDue(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	archive := &fakeTarget{name: "postgres"}
+	store, _ := NewFileStore("")
+	f := New([]Target{archive}, store, nil, zap.NewNop())
+	f.now = func() time.Time { return now }
+
+	store.Add(Pending{Target: "postgres", Record: record("ok", "Recovered", now), Attempts: 1, NextAttemptAt: now})
+	store.Add(Pending{Target: "postgres", Record: record("future", "Not yet", now), Attempts: 1, NextAttemptAt: now.Add(time.Hour)})
+	store.Add(Pending{Target: "removed", Record: record("orphan", "Orphan", now), Attempts: 1, NextAttemptAt: now})
+
+	if err := f.RetryDue(context.Background()); err != nil {
+		t.Fatalf("RetryDue() error = %v", err)
+	}
+	if got := archive.titles(); len(got) != 1 || got[0] != "Recovered" {
+		t.Errorf("written = %v, want [Recovered]", got)
+	}
+	if counts := store.Counts(); counts["postgres"] != 1 || counts["removed"] != 0 {
+		t.Errorf("Counts() = %v, want only the future write", counts)
+	}
+
+	// Failures back off, then are given up on
+	archive.setErr(errors.New("timeout"))
+	store.Add(Pending{Target: "postgres", Record: record("flaky", "Flaky", now), Attempts: 2, NextAttemptAt: now})
+	store.Add(Pending{Target: "postgres", Record: record("exhausted", "Exhausted", now), Attempts: maxAttempts - 1, NextAttemptAt: now})
+	f.RetryDue(context.Background())
+
+	due, _ := store.Due(now.Add(24 * time.Hour))
+	var flaky *Pending
+	for i := range due {
+		switch due[i].Record.PageID {
+		case "flaky":
+			flaky = &due[i]
+		case "exhausted":
+			t.Error("exhausted write still pending, want it given up on")
+		}
+	}
+	if flaky == nil {
+		t.Fatal("flaky write not pending")
+	}
+	if flaky.Attempts != 3 || !flaky.NextAttemptAt.Equal(now.Add(4*baseRetryDelay)) {
+		t.Errorf("flaky = attempts %d, next %v, want 3 attempts retried in %v", flaky.Attempts, flaky.NextAttemptAt, 4*baseRetryDelay)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{6, 32 * time.Minute},
+		{7, time.Hour},
+		{maxAttempts, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package fanout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+)
+
+// Execer runs a statement. *pgstore.Client implements it.
+type Execer interface {
+	Exec(ctx context.Context, query string, args ...any) (int64, error)
+}
+
+// PostgresArchive is a Target that keeps one row per idea in a Postgres
+// table, created on the first write if missing. The main fields get their own
+// columns for querying; the full submission is kept as JSON.
+type PostgresArchive struct {
+	db    Execer
+	table string // Validated identifier (see config)
+
+	mu      sync.Mutex
+	created bool // The table was created (or found) by this process
+}
+
+// NewPostgresArchive creates an archive writing to table through db.
+func NewPostgresArchive(db Execer, table string) *PostgresArchive {
+	return &PostgresArchive{db: db, table: table}
+}
+
+// Name returns "postgres".
+func (a *PostgresArchive) Name() string {
+	return constants.FanoutPostgres
+}
+
+// Write upserts the idea's row. An older version than the stored one is
+// ignored, and edits keep the original submitter, channel and times.
+func (a *PostgresArchive) Write(ctx context.Context, rec Record) error {
+	if err := a.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(rec.Submission)
+	if err != nil {
+		return fmt.Errorf("failed to encode submission: %w", err)
+	}
+	sub := rec.Submission
+	_, err = a.db.Exec(ctx, a.upsertStatement(),
+		rec.PageID,
+		rec.PageURL,
+		sub.Title,
+		sub.Theme,
+		sub.ProductArea,
+		strings.Join(sub.CustomerOrgs, ", "),
+		sub.Source.SubmitterEmail,
+		sub.Source.ChannelName(),
+		sub.Source.SubmittedAt,
+		rec.CreatedTime,
+		rec.UpdatedAt,
+		payload,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to archive idea %s: %w", rec.PageID, err)
+	}
+	return nil
+}
+
+// ensureTable creates the table on the first write.
+func (a *PostgresArchive) ensureTable(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.created {
+		return nil
+	}
+	if _, err := a.db.Exec(ctx, a.createStatement()); err != nil {
+		return fmt.Errorf("failed to create archive table %s: %w", a.table, err)
+	}
+	a.created = true
+	return nil
+}
+
+func (a *PostgresArchive) createStatement() string {
+	return `CREATE TABLE IF NOT EXISTS "` + a.table + `" (
+  page_id text PRIMARY KEY,
+  page_url text NOT NULL,
+  title text NOT NULL,
+  theme text NOT NULL,
+  product_area text NOT NULL,
+  customers text NOT NULL,
+  submitter_email text NOT NULL,
+  channel text NOT NULL,
+  submitted_at timestamptz,
+  created_at timestamptz,
+  updated_at timestamptz NOT NULL,
+  submission jsonb NOT NULL
+)`
+}
+
+func (a *PostgresArchive) upsertStatement() string {
+	return `INSERT INTO "` + a.table + `" (page_id, page_url, title, theme, product_area, customers, submitter_email, channel, submitted_at, created_at, updated_at, submission)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (page_id) DO UPDATE SET
+  page_url = EXCLUDED.page_url,
+  title = EXCLUDED.title,
+  theme = EXCLUDED.theme,
+  product_area = EXCLUDED.product_area,
+  customers = EXCLUDED.customers,
+  updated_at = EXCLUDED.updated_at,
+  submission = EXCLUDED.submission
+WHERE "` + a.table + `".updated_at <= EXCLUDED.updated_at`
+}
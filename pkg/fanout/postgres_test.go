@@ -0,0 +1,102 @@
+package fanout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+)
+
+// fakeDB records statements and fails while err is set.
+type fakeDB struct {
+	err        error
+	statements []string
+	args       [][]any
+}
+
+func (db *fakeDB) Exec(_ context.Context, query string, args ...any) (int64, error) {
+	if db.err != nil {
+		return 0, db.err
+	}
+	db.statements = append(db.statements, query)
+	db.args = append(db.args, args)
+	return 1, nil
+}
+
+func TestPostgresArchive_Write(t *testing.T) {
+	db := &fakeDB{}
+	archive := NewPostgresArchive(db, "ideas_archive")
+	if archive.Name() != "postgres" {
+		t.Errorf("Name() = %q, want postgres", archive.Name())
+	}
+
+	submitted := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	rec := Record{
+		PageID:      "page-1",
+		PageURL:     "https://www.notion.so/page-1",
+		CreatedTime: submitted,
+		UpdatedAt:   submitted.Add(time.Second),
+		Submission: submission.Submission{
+			Title:        "Dark mode",
+			Theme:        "New Feature",
+			ProductArea:  "Dashboard",
+			CustomerOrgs: []string{"Acme", "Globex"},
+			Source: submission.Source{
+				Channel:        submission.ChannelSlack,
+				SubmitterEmail: "jane@example.com",
+				SubmittedAt:    submitted,
+			},
+		},
+	}
+	for range 2 {
+		if err := archive.Write(context.Background(), rec); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	// The table is created once, then each write is an upsert guarded by version
+	if len(db.statements) != 3 {
+		t.Fatalf("statements = %d, want CREATE TABLE and two upserts", len(db.statements))
+	}
+	if !strings.HasPrefix(db.statements[0], `CREATE TABLE IF NOT EXISTS "ideas_archive"`) {
+		t.Errorf("first statement = %q", db.statements[0])
+	}
+	upsert := db.statements[1]
+	if strings.Contains(upsert, "submitted_at = ") {
+		t.Errorf("upsert overwrites the original submission time: %q", upsert)
+	}
+	if !strings.Contains(upsert, "ON CONFLICT (page_id) DO UPDATE") || !strings.Contains(upsert, `WHERE "ideas_archive".updated_at <= EXCLUDED.updated_at`) {
+		t.Errorf("upsert = %q", upsert)
+	}
+
+	args := db.args[1]
+	if args[0] != "page-1" || args[2] != "Dark mode" || args[5] != "Acme, Globex" || args[6] != "jane@example.com" || args[7] != submission.ChannelSlackModal {
+		t.Errorf("upsert args = %v", args)
+	}
+	var stored submission.Submission
+	if err := json.Unmarshal(args[11].([]byte), &stored); err != nil || stored.Title != "Dark mode" {
+		t.Errorf("submission column = %s, %v", args[11], err)
+	}
+}
+
+func TestPostgresArchive_Errors(t *testing.T) {
+	db := &fakeDB{err: errors.New("connection refused")}
+	archive := NewPostgresArchive(db, "ideas_archive")
+
+	if err := archive.Write(context.Background(), Record{PageID: "page-1"}); err == nil || !strings.Contains(err.Error(), "create archive table") {
+		t.Errorf("Write() error = %v, want a table creation error", err)
+	}
+
+	// Creation is retried by the next write
+	db.err = nil
+	if err := archive.Write(context.Background(), Record{PageID: "page-1"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if len(db.statements) != 2 || !strings.HasPrefix(db.statements[0], "CREATE TABLE") {
+		t.Errorf("statements = %q, want CREATE TABLE then the upsert", db.statements)
+	}
+}
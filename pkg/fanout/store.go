@@ -0,0 +1,120 @@
+package fanout
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/jsonfile"
+)
+
+// Pending is a failed write waiting for a retry.
+type Pending struct {
+	Target        string    `json:"target"`
+	Record        Record    `json:"record"`
+	Attempts      int       `json:"attempts"` // Failed attempts so far
+	FirstFailedAt time.Time `json:"first_failed_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error,omitempty"` // Error from the last failed attempt
+}
+
+// key identifies the pending write of an idea to a target: a newer version of
+// the idea replaces an older one still waiting.
+func (p Pending) key() string {
+	return key(p.Target, p.Record.PageID)
+}
+
+func key(target, pageID string) string {
+	return target + "/" + pageID
+}
+
+// Store persists pending writes.
+type Store interface {
+	// Add inserts a pending write (keyed by target and page ID), replacing one
+	// for an older version of the idea. A version older than the one already
+	// pending is ignored, so a slow retry can't undo a newer write.
+	Add(p Pending) error
+
+	// Due returns pending writes with NextAttemptAt at or before now, oldest first.
+	Due(now time.Time) ([]Pending, error)
+
+	// Delete removes the pending write of an idea to target if it is for
+	// version (Record.UpdatedAt) or older. Deleting an unknown write is not an error.
+	Delete(target, pageID string, version time.Time) error
+
+	// Counts returns the number of pending writes per target.
+	Counts() map[string]int
+}
+
+// FileStore is a Store holding pending writes by target and page ID in a
+// jsonfile.Map. It is normally empty or holds the writes made during a
+// target's outage. With an empty path the store is memory-only and pending
+// writes are lost on restart.
+type FileStore struct {
+	*jsonfile.Map[string, Pending]
+}
+
+// NewFileStore creates a store backed by path, loading any writes already saved there.
+// A missing file is treated as empty; an empty path creates a memory-only store.
+func NewFileStore(path string) (*FileStore, error) {
+	pending, err := jsonfile.NewMap(path, "fan-out retry file", Pending.key, pendingBefore)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{pending}, nil
+}
+
+// Add inserts or replaces a pending write unless a newer version is pending.
+func (s *FileStore) Add(p Pending) error {
+	if p.Target == "" || p.Record.PageID == "" {
+		return fmt.Errorf("target and page ID are required")
+	}
+
+	return s.Update(func(pending map[string]Pending) bool {
+		if current, ok := pending[p.key()]; ok && current.Record.UpdatedAt.After(p.Record.UpdatedAt) {
+			return false
+		}
+		pending[p.key()] = p
+		return true
+	})
+}
+
+// Due returns pending writes that are due at now, oldest first.
+func (s *FileStore) Due(now time.Time) ([]Pending, error) {
+	var due []Pending
+	for _, p := range s.Values() {
+		if !p.NextAttemptAt.After(now) {
+			due = append(due, p)
+		}
+	}
+	return due, nil
+}
+
+// Delete removes a pending write unless it is for a newer version.
+func (s *FileStore) Delete(target, pageID string, version time.Time) error {
+	return s.Update(func(pending map[string]Pending) bool {
+		k := key(target, pageID)
+		current, ok := pending[k]
+		if !ok || current.Record.UpdatedAt.After(version) {
+			return false
+		}
+		delete(pending, k)
+		return true
+	})
+}
+
+// Counts returns the number of pending writes per target.
+func (s *FileStore) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, p := range s.Values() {
+		counts[p.Target]++
+	}
+	return counts
+}
+
+// pendingBefore orders pending writes by when they first failed, then by key.
+func pendingBefore(a, b Pending) bool {
+	if !a.FirstFailedAt.Equal(b.FirstFailedAt) {
+		return a.FirstFailedAt.Before(b.FirstFailedAt)
+	}
+	return a.key() < b.key()
+}
@@ -0,0 +1,99 @@
+// Package featureflags holds runtime toggles for optional bot behaviour.
+//
+// Flags gate features that are already configured, so on-call can switch one
+// off without a redeploy (e.g. stop posting confirmations while Slack is
+// rate limiting, or bypass the submission queue while it is misbehaving).
+// They can't enable a feature that wasn't configured at startup.
+//
+// Flags are toggled through POST /admin/flags and are not persisted: every
+// flag is back to its default after a restart.
+package featureflags
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Known flags. All are enabled by default.
+const (
+	// Autosave keeps the values of a closed submission modal and restores them
+	// the next time the user opens it (needs a draft store).
+	Autosave = "autosave"
+	// Confirmations posts submission confirmations (CONFIRMATION_CHANNEL or DM).
+	Confirmations = "confirmations"
+	// Reminders shows the "Remind me to follow up" field in the modal.
+	Reminders = "reminders"
+	// Drafts shows the "Share draft with a teammate" select in the modal.
+	Drafts = "drafts"
+	// MultiStep splits the submission modal into a title and theme step and a
+	// details step (MULTI_STEP_MODAL); switched off, the single modal is used.
+	MultiStep = "multi_step"
+	// SubmissionQueue creates pages through the async submission queue when
+	// enabled (SUBMISSION_QUEUE_ENABLED); switched off, submissions are synchronous.
+	SubmissionQueue = "submission_queue"
+)
+
+// Names lists the known flags in sorted order.
+var Names = []string{Autosave, Confirmations, Drafts, MultiStep, Reminders, SubmissionQueue}
+
+// Flags is a concurrency-safe set of the known flags.
+type Flags struct {
+	mu     sync.RWMutex
+	values map[string]bool
+}
+
+// New returns flags with every known flag enabled.
+func New() *Flags {
+	values := make(map[string]bool, len(Names))
+	for _, name := range Names {
+		values[name] = true
+	}
+	return &Flags{values: values}
+}
+
+// Enabled reports whether a flag is enabled. A nil *Flags enables everything,
+// so callers don't need to check whether flags were configured.
+func (f *Flags) Enabled(name string) bool {
+	if f == nil {
+		return true
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.values[name]
+}
+
+// Set applies changes to known flags. Unknown names are rejected and nothing is
+// changed, so a typo can't half-apply an update.
+func (f *Flags) Set(changes map[string]bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var unknown []string
+	for name := range changes {
+		if _, ok := f.values[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown feature flags %v (known: %v)", unknown, Names)
+	}
+
+	for name, enabled := range changes {
+		f.values[name] = enabled
+	}
+	return nil
+}
+
+// All returns a copy of every flag's current value.
+func (f *Flags) All() map[string]bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	all := make(map[string]bool, len(f.values))
+	for name, enabled := range f.values {
+		all[name] = enabled
+	}
+	return all
+}
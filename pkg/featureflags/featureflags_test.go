@@ -0,0 +1,47 @@
+package featureflags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	flags := New()
+	for _, name := range Names {
+		if !flags.Enabled(name) {
+			t.Errorf("Enabled(%s) = false, want enabled by default", name)
+		}
+	}
+	if flags.Enabled("unknown") {
+		t.Error("Enabled(unknown) = true")
+	}
+
+	if err := flags.Set(map[string]bool{Confirmations: false}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if flags.Enabled(Confirmations) || !flags.Enabled(Reminders) {
+		t.Errorf("All() = %v, want only confirmations disabled", flags.All())
+	}
+
+	// Unknown names reject the whole update
+	err := flags.Set(map[string]bool{Reminders: false, "remiders": false})
+	if err == nil || !strings.Contains(err.Error(), "remiders") {
+		t.Errorf("Set() error = %v, want the unknown flag named", err)
+	}
+	if !flags.Enabled(Reminders) {
+		t.Error("Set() applied part of a rejected update")
+	}
+
+	all := flags.All()
+	all[Drafts] = false
+	if !flags.Enabled(Drafts) {
+		t.Error("All() returned the live map")
+	}
+}
+
+func TestFlags_Nil(t *testing.T) {
+	var flags *Flags
+	if !flags.Enabled(Drafts) {
+		t.Error("nil Flags disabled a feature")
+	}
+}
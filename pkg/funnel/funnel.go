@@ -0,0 +1,429 @@
+// Package funnel measures how responsive the idea funnel is: how long submitted
+// ideas wait for triage (a first status) and for a decision (a closed status).
+//
+// Each submitted idea is recorded in a Store with its submission time. A
+// background Tracker polls the status of ideas still open, through a
+// StatusFunc supplied by the Slack handler, and records when each idea first
+// got a status and when it reached one of the closed statuses. Durations are
+// observed in Prometheus histograms and aggregated by Summary for the
+// /admin/funnel endpoint.
+//
+// Features:
+// - JSON file-backed store so tracked ideas survive restarts (memory-only when no path is set)
+// - Periodic status polling in a background goroutine
+// - Ideas still open after MaxTrackingAge are no longer polled
+// - Graceful shutdown with context cancellation
+package funnel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/jsonfile"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// MaxTrackingAge is how long an idea's status is polled. Ideas still open
+// afterwards keep their record but count as open forever.
+const MaxTrackingAge = 180 * 24 * time.Hour
+
+// Funnel stages, used as the stage label of hopperbot_funnel_ideas.
+const (
+	StageAwaitingTriage = "awaiting_triage" // No status yet
+	StageInReview       = "in_review"       // Has a status, not closed yet
+	StageClosed         = "closed"          // Reached a closed status
+	StageRemoved        = "removed"         // Page deleted before a decision
+)
+
+// Idea is the funnel record of one submitted idea.
+type Idea struct {
+	PageID        string    `json:"page_id"`
+	SlackTeamID   string    `json:"slack_team_id,omitempty"` // Selects the tenant database to poll
+	SlackUserID   string    `json:"slack_user_id,omitempty"` // Submitter, for analytics; empty outside Slack
+	SubmittedAt   time.Time `json:"submitted_at"`
+	FirstStatus   string    `json:"first_status,omitempty"`
+	FirstStatusAt time.Time `json:"first_status_at,omitzero"` // Zero until triaged
+	ClosedStatus  string    `json:"closed_status,omitempty"`
+	ClosedAt      time.Time `json:"closed_at,omitzero"` // Zero until closed
+	Removed       bool      `json:"removed,omitempty"`  // Page deleted before a decision
+}
+
+// Stage returns the idea's funnel stage.
+func (i Idea) Stage() string {
+	switch {
+	case !i.ClosedAt.IsZero():
+		return StageClosed
+	case i.Removed:
+		return StageRemoved
+	case !i.FirstStatusAt.IsZero():
+		return StageInReview
+	default:
+		return StageAwaitingTriage
+	}
+}
+
+// Open reports whether the idea still awaits a decision.
+func (i Idea) Open() bool {
+	return i.ClosedAt.IsZero() && !i.Removed
+}
+
+// Store persists tracked ideas.
+type Store interface {
+	// Save inserts or replaces an idea (keyed by page ID).
+	Save(idea Idea) error
+
+	// List returns all ideas, oldest submission first.
+	List() ([]Idea, error)
+}
+
+// FileStore is a Store holding the funnel's ideas by page ID in a
+// jsonfile.Map. With an empty path the store is memory-only and the funnel
+// history is lost on restart.
+type FileStore struct {
+	*jsonfile.Map[string, Idea]
+}
+
+// NewFileStore creates a store backed by path, loading any ideas already saved there.
+// A missing file is treated as empty; an empty path creates a memory-only store.
+func NewFileStore(path string) (*FileStore, error) {
+	ideas, err := jsonfile.NewMap(path, "funnel file",
+		func(idea Idea) string { return idea.PageID },
+		func(a, b Idea) bool { return a.SubmittedAt.Before(b.SubmittedAt) })
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{ideas}, nil
+}
+
+// Save inserts or replaces an idea.
+func (s *FileStore) Save(idea Idea) error {
+	if idea.PageID == "" {
+		return fmt.Errorf("page ID is required")
+	}
+	return s.Put(idea)
+}
+
+// List returns all ideas, oldest submission first.
+func (s *FileStore) List() ([]Idea, error) {
+	return s.Values(), nil
+}
+
+// Status is an idea's current state in Notion.
+type Status struct {
+	Value          string    // Status property value; empty when not triaged
+	Archived       bool      // Page was deleted
+	LastEditedTime time.Time // When the page was last edited; zero if unknown
+}
+
+// StatusFunc looks up an idea's current status (e.g., from Notion).
+type StatusFunc func(ctx context.Context, idea Idea) (Status, error)
+
+// StageFunc is called after an idea reached a new funnel stage
+// (StageInReview, StageClosed or StageRemoved) and was saved, e.g. to export
+// an analytics event. A poll that sees an idea triaged and closed at once
+// calls it for both stages.
+type StageFunc func(idea Idea, stage string)
+
+// Tracker records submitted ideas and polls the status of open ones.
+type Tracker struct {
+	store    Store
+	status   StatusFunc
+	onStage  StageFunc       // Optional, see SetStageFunc
+	closed   map[string]bool // Lowercased closed statuses
+	metrics  *metrics.Metrics
+	logger   *zap.Logger
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	now      func() time.Time // Overridable for tests
+}
+
+// NewTracker creates a tracker in a stopped state. Call Start() to begin polling.
+// closedStatuses are the status values that mean a decision was made (matched
+// case-insensitively). metrics may be nil to disable metrics recording.
+func NewTracker(store Store, status StatusFunc, closedStatuses []string, m *metrics.Metrics, logger *zap.Logger, interval time.Duration) *Tracker {
+	closed := make(map[string]bool, len(closedStatuses))
+	for _, value := range closedStatuses {
+		closed[strings.ToLower(strings.TrimSpace(value))] = true
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Tracker{
+		store:    store,
+		status:   status,
+		closed:   closed,
+		metrics:  m,
+		logger:   logger,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+		now:      time.Now,
+	}
+}
+
+// SetStageFunc sets the function called when an idea reaches a new stage.
+// Call it before Start; nil (the default) disables it.
+func (t *Tracker) SetStageFunc(fn StageFunc) {
+	t.onStage = fn
+}
+
+// Start begins the background polling loop.
+func (t *Tracker) Start() {
+	ticker := time.NewTicker(t.interval)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer ticker.Stop()
+
+		t.logger.Info("funnel tracker started", zap.Duration("check_interval", t.interval))
+		t.recordStages()
+
+		for {
+			select {
+			case <-ticker.C:
+				t.RunCheck()
+			case <-t.ctx.Done():
+				t.logger.Info("funnel tracker stopping due to context cancellation")
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the polling loop and waits for any in-progress check to finish.
+func (t *Tracker) Stop() {
+	t.cancel()
+	t.wg.Wait()
+}
+
+// Track records a newly submitted idea.
+func (t *Tracker) Track(idea Idea) error {
+	if idea.SubmittedAt.IsZero() {
+		idea.SubmittedAt = t.now().UTC()
+	}
+	if err := t.store.Save(idea); err != nil {
+		return err
+	}
+	t.recordStages()
+	return nil
+}
+
+// RunCheck polls the status of every open idea submitted within MaxTrackingAge.
+func (t *Tracker) RunCheck() {
+	ideas, err := t.store.List()
+	if err != nil {
+		t.logger.Error("failed to load tracked ideas", zap.Error(err))
+		return
+	}
+
+	now := t.now()
+	for _, idea := range ideas {
+		if t.ctx.Err() != nil {
+			return
+		}
+		if !idea.Open() || now.Sub(idea.SubmittedAt) > MaxTrackingAge {
+			continue
+		}
+
+		status, err := t.status(t.ctx, idea)
+		if err != nil {
+			t.logger.Warn("failed to check idea status, will retry", zap.String("page_id", idea.PageID), zap.Error(err))
+			continue
+		}
+		updated, changed := t.observe(idea, status, now)
+		if !changed {
+			continue
+		}
+		if err := t.store.Save(updated); err != nil {
+			t.logger.Error("failed to save idea funnel progress", zap.String("page_id", idea.PageID), zap.Error(err))
+			continue
+		}
+		if t.onStage != nil {
+			for _, stage := range reachedStages(idea, updated) {
+				t.onStage(updated, stage)
+			}
+		}
+	}
+	t.recordStages()
+}
+
+// reachedStages returns the stages updated reached since before, in funnel order.
+func reachedStages(before, updated Idea) []string {
+	var stages []string
+	if before.FirstStatusAt.IsZero() && !updated.FirstStatusAt.IsZero() {
+		stages = append(stages, StageInReview)
+	}
+	if before.ClosedAt.IsZero() && !updated.ClosedAt.IsZero() {
+		stages = append(stages, StageClosed)
+	}
+	if !before.Removed && updated.Removed {
+		stages = append(stages, StageRemoved)
+	}
+	return stages
+}
+
+// observe applies a status lookup to an idea, returning the updated idea and
+// whether anything changed. Times are estimated from the page's last edit,
+// which is no later than the poll that noticed the change.
+func (t *Tracker) observe(idea Idea, status Status, now time.Time) (Idea, bool) {
+	if status.Archived {
+		idea.Removed = true
+		t.logger.Info("tracked idea was removed", zap.String("page_id", idea.PageID))
+		return idea, true
+	}
+
+	value := strings.TrimSpace(status.Value)
+	if value == "" {
+		return idea, false
+	}
+
+	changedAt := now.UTC()
+	if !status.LastEditedTime.IsZero() && status.LastEditedTime.Before(changedAt) && !status.LastEditedTime.Before(idea.SubmittedAt) {
+		changedAt = status.LastEditedTime.UTC()
+	}
+
+	changed := false
+	if idea.FirstStatusAt.IsZero() {
+		idea.FirstStatus, idea.FirstStatusAt = value, changedAt
+		changed = true
+		t.observeDuration("triage", idea, changedAt)
+	}
+	if t.closed[strings.ToLower(value)] {
+		idea.ClosedStatus, idea.ClosedAt = value, changedAt
+		changed = true
+		t.observeDuration("decision", idea, changedAt)
+	}
+	return idea, changed
+}
+
+// observeDuration logs and records a stage duration.
+func (t *Tracker) observeDuration(stage string, idea Idea, at time.Time) {
+	elapsed := at.Sub(idea.SubmittedAt)
+	t.logger.Info("idea reached funnel stage",
+		zap.String("page_id", idea.PageID),
+		zap.String("stage", stage),
+		zap.Duration("elapsed", elapsed),
+	)
+	if t.metrics == nil {
+		return
+	}
+	switch stage {
+	case "triage":
+		t.metrics.IdeaTimeToTriage.Observe(elapsed.Seconds())
+	case "decision":
+		t.metrics.IdeaTimeToDecision.Observe(elapsed.Seconds())
+	}
+}
+
+// recordStages updates the per-stage idea gauges.
+func (t *Tracker) recordStages() {
+	if t.metrics == nil {
+		return
+	}
+	ideas, err := t.store.List()
+	if err != nil {
+		return
+	}
+	counts := map[string]int{StageAwaitingTriage: 0, StageInReview: 0, StageClosed: 0, StageRemoved: 0}
+	for _, idea := range ideas {
+		counts[idea.Stage()]++
+	}
+	for stage, count := range counts {
+		t.metrics.FunnelIdeas.WithLabelValues(stage).Set(float64(count))
+	}
+}
+
+// DurationStats aggregates stage durations, in hours.
+type DurationStats struct {
+	Count       int     `json:"count"`
+	MeanHours   float64 `json:"mean_hours"`
+	MedianHours float64 `json:"median_hours"`
+	P90Hours    float64 `json:"p90_hours"`
+}
+
+// Summary aggregates the funnel for ideas submitted since a point in time.
+type Summary struct {
+	Since          time.Time     `json:"since,omitzero"` // Zero covers every tracked idea
+	Submitted      int           `json:"submitted"`
+	AwaitingTriage int           `json:"awaiting_triage"`
+	InReview       int           `json:"in_review"`
+	Closed         int           `json:"closed"`
+	Removed        int           `json:"removed"`
+	TimeToTriage   DurationStats `json:"time_to_triage"`
+	TimeToDecision DurationStats `json:"time_to_decision"`
+}
+
+// Summary aggregates the ideas submitted at or after since (zero for all).
+func (t *Tracker) Summary(since time.Time) (Summary, error) {
+	ideas, err := t.store.List()
+	if err != nil {
+		return Summary{}, err
+	}
+	return Summarize(ideas, since), nil
+}
+
+// Summarize aggregates the ideas submitted at or after since (zero for all).
+func Summarize(ideas []Idea, since time.Time) Summary {
+	summary := Summary{Since: since}
+	var toTriage, toDecision []time.Duration
+	for _, idea := range ideas {
+		if idea.SubmittedAt.Before(since) {
+			continue
+		}
+		summary.Submitted++
+		switch idea.Stage() {
+		case StageAwaitingTriage:
+			summary.AwaitingTriage++
+		case StageInReview:
+			summary.InReview++
+		case StageClosed:
+			summary.Closed++
+		case StageRemoved:
+			summary.Removed++
+		}
+		if !idea.FirstStatusAt.IsZero() {
+			toTriage = append(toTriage, idea.FirstStatusAt.Sub(idea.SubmittedAt))
+		}
+		if !idea.ClosedAt.IsZero() {
+			toDecision = append(toDecision, idea.ClosedAt.Sub(idea.SubmittedAt))
+		}
+	}
+	summary.TimeToTriage = durationStats(toTriage)
+	summary.TimeToDecision = durationStats(toDecision)
+	return summary
+}
+
+// durationStats computes the mean, median and 90th percentile (nearest rank) of durations.
+func durationStats(durations []time.Duration) DurationStats {
+	if len(durations) == 0 {
+		return DurationStats{}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p*float64(len(durations)))) - 1
+		return hours(durations[max(rank, 0)])
+	}
+	return DurationStats{
+		Count:       len(durations),
+		MeanHours:   hours(total / time.Duration(len(durations))),
+		MedianHours: percentile(0.5),
+		P90Hours:    percentile(0.9),
+	}
+}
+
+// hours converts a duration to hours, rounded to two decimals.
+func hours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
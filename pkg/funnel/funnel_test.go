@@ -0,0 +1,162 @@
+package funnel
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var submitted = time.Date(2025, 11, 3, 9, 0, 0, 0, time.UTC)
+
+// TestFileStore_Persistence tests that tracked ideas survive reopening the store
+func TestFileStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "funnel.json")
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	for _, idea := range []Idea{
+		{PageID: "later", SubmittedAt: submitted.Add(time.Hour)},
+		{PageID: "earlier", SubmittedAt: submitted, FirstStatus: "Planned", FirstStatusAt: submitted.Add(2 * time.Hour)},
+	} {
+		if err := store.Save(idea); err != nil {
+			t.Fatalf("Save(%s) error = %v", idea.PageID, err)
+		}
+	}
+	if err := store.Save(Idea{}); err == nil {
+		t.Error("expected error for idea without page ID")
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopening store: %v", err)
+	}
+	ideas, _ := reopened.List()
+	if len(ideas) != 2 || ideas[0].PageID != "earlier" || ideas[1].PageID != "later" {
+		t.Fatalf("List() = %+v, want [earlier later]", ideas)
+	}
+	if ideas[0].Stage() != StageInReview || ideas[1].Stage() != StageAwaitingTriage {
+		t.Errorf("stages = %s, %s", ideas[0].Stage(), ideas[1].Stage())
+	}
+}
+
+// TestTracker_RunCheck tests recording triage, decisions, and removals from status polls
+func TestTracker_RunCheck(t *testing.T) {
+	now := submitted.Add(72 * time.Hour)
+	statuses := map[string]Status{
+		"untriaged": {},
+		"triaged":   {Value: "Planned", LastEditedTime: submitted.Add(5 * time.Hour)},
+		"shipped":   {Value: "shipped"}, // Closed statuses match case-insensitively; no edit time uses the poll time
+		"deleted":   {Archived: true},
+		"flaky":     {},
+	}
+	status := func(_ context.Context, idea Idea) (Status, error) {
+		if idea.PageID == "flaky" {
+			return Status{}, errors.New("notion unavailable")
+		}
+		return statuses[idea.PageID], nil
+	}
+
+	store, _ := NewFileStore("")
+	for _, pageID := range []string{"untriaged", "triaged", "shipped", "deleted", "flaky"} {
+		store.Save(Idea{PageID: pageID, SubmittedAt: submitted})
+	}
+	store.Save(Idea{PageID: "stale", SubmittedAt: now.Add(-MaxTrackingAge - time.Hour)})
+
+	tracker := NewTracker(store, status, []string{"Shipped", "Won't Do"}, nil, zap.NewNop(), time.Hour)
+	tracker.now = func() time.Time { return now }
+	tracker.RunCheck()
+
+	ideas, _ := store.List()
+	byID := make(map[string]Idea, len(ideas))
+	for _, idea := range ideas {
+		byID[idea.PageID] = idea
+	}
+
+	if got := byID["triaged"]; got.FirstStatus != "Planned" || !got.FirstStatusAt.Equal(submitted.Add(5*time.Hour)) || !got.Open() {
+		t.Errorf("triaged = %+v, want first status Planned at the last edit time", got)
+	}
+	if got := byID["shipped"]; got.Stage() != StageClosed || got.ClosedStatus != "shipped" || !got.ClosedAt.Equal(now) || !got.FirstStatusAt.Equal(now) {
+		t.Errorf("shipped = %+v, want closed at the poll time", got)
+	}
+	if got := byID["deleted"]; got.Stage() != StageRemoved {
+		t.Errorf("deleted stage = %s, want removed", got.Stage())
+	}
+	for _, pageID := range []string{"untriaged", "flaky", "stale"} {
+		if got := byID[pageID]; got.Stage() != StageAwaitingTriage {
+			t.Errorf("%s stage = %s, want awaiting_triage", pageID, got.Stage())
+		}
+	}
+}
+
+// TestTracker_StageFunc tests that each stage an idea reaches is reported once, after it's saved
+func TestTracker_StageFunc(t *testing.T) {
+	statuses := map[string]Status{
+		"triaged": {Value: "Planned"},
+		"shipped": {Value: "Shipped"},
+		"deleted": {Archived: true},
+	}
+	store, _ := NewFileStore("")
+	for pageID := range statuses {
+		store.Save(Idea{PageID: pageID, SlackUserID: "U1", SubmittedAt: submitted})
+	}
+	tracker := NewTracker(store, func(_ context.Context, idea Idea) (Status, error) {
+		return statuses[idea.PageID], nil
+	}, []string{"Shipped"}, nil, zap.NewNop(), time.Hour)
+	tracker.now = func() time.Time { return submitted.Add(time.Hour) }
+
+	var reached []string
+	tracker.SetStageFunc(func(idea Idea, stage string) {
+		if idea.SlackUserID != "U1" {
+			t.Errorf("stage func got %+v, want the saved idea", idea)
+		}
+		reached = append(reached, idea.PageID+":"+stage)
+	})
+	tracker.RunCheck()
+	slices.Sort(reached)
+	want := []string{"deleted:removed", "shipped:closed", "shipped:in_review", "triaged:in_review"}
+	if !slices.Equal(reached, want) {
+		t.Errorf("stages = %v, want %v", reached, want)
+	}
+
+	// Nothing new on the next poll
+	reached = nil
+	tracker.RunCheck()
+	if len(reached) != 0 {
+		t.Errorf("second poll reported %v again", reached)
+	}
+}
+
+// TestSummarize tests stage counts and duration statistics
+func TestSummarize(t *testing.T) {
+	ideas := []Idea{
+		{PageID: "old", SubmittedAt: submitted.Add(-30 * 24 * time.Hour)},
+		{PageID: "a", SubmittedAt: submitted, FirstStatusAt: submitted.Add(2 * time.Hour), ClosedAt: submitted.Add(48 * time.Hour)},
+		{PageID: "b", SubmittedAt: submitted, FirstStatusAt: submitted.Add(4 * time.Hour)},
+		{PageID: "c", SubmittedAt: submitted, FirstStatusAt: submitted.Add(12 * time.Hour)},
+		{PageID: "d", SubmittedAt: submitted},
+		{PageID: "e", SubmittedAt: submitted, Removed: true},
+	}
+
+	summary := Summarize(ideas, submitted.Add(-time.Hour))
+	if summary.Submitted != 5 || summary.AwaitingTriage != 1 || summary.InReview != 2 || summary.Closed != 1 || summary.Removed != 1 {
+		t.Errorf("counts = %+v", summary)
+	}
+	want := DurationStats{Count: 3, MeanHours: 6, MedianHours: 4, P90Hours: 12}
+	if summary.TimeToTriage != want {
+		t.Errorf("TimeToTriage = %+v, want %+v", summary.TimeToTriage, want)
+	}
+	if summary.TimeToDecision != (DurationStats{Count: 1, MeanHours: 48, MedianHours: 48, P90Hours: 48}) {
+		t.Errorf("TimeToDecision = %+v", summary.TimeToDecision)
+	}
+
+	if all := Summarize(ideas, time.Time{}); all.Submitted != 6 {
+		t.Errorf("Submitted without since = %d, want 6", all.Submitted)
+	}
+}
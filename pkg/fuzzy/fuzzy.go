@@ -0,0 +1,101 @@
+// Package fuzzy matches search queries with typos, so a customer search for
+// "acmee" or "ruderstack" still finds "Acme Corp" and "RudderStack".
+//
+// A query matches a text when some part of the text is within a few edits of
+// it: inserted, deleted or substituted characters, or two adjacent characters
+// swapped. Queries shorter than MinQueryLength are never matched fuzzily,
+// since they would be one edit away from almost anything. Callers rank fuzzy
+// matches after exact, prefix and substring ones, fewest edits first.
+package fuzzy
+
+import "unicode/utf8"
+
+// MinQueryLength is the length, in characters, of the shortest query matched fuzzily.
+const MinQueryLength = 4
+
+// longQueryLength is the length from which a query tolerates two edits instead of one.
+const longQueryLength = 8
+
+// MaxEdits returns the number of edits tolerated for query: none below
+// MinQueryLength, 1 below 8 characters, and 2 from there.
+func MaxEdits(query string) int {
+	switch length := len([]rune(query)); {
+	case length < MinQueryLength:
+		return 0
+	case length < longQueryLength:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Match reports whether query matches part of text with at most
+// MaxEdits(query) edits, and how many it took (0 when text contains query;
+// only meaningful when ok). Both should be normalized the same way, e.g.
+// lowercased.
+func Match(query, text string) (edits int, ok bool) {
+	maxEdits := MaxEdits(query)
+	if maxEdits == 0 || !enoughCharacters(query, text, maxEdits) {
+		return maxEdits + 1, false
+	}
+	edits = distance([]rune(query), []rune(text))
+	return edits, edits <= maxEdits
+}
+
+// enoughCharacters is a quick check that text has enough of the query's
+// characters for a match: every edit accounts for at most one missing
+// character, so at least len(query)-maxEdits of them must be in text. It
+// rules out most texts before distance is computed. Non-ASCII queries
+// aren't checked.
+func enoughCharacters(query, text string, maxEdits int) bool {
+	var counts [128]uint8
+	for i := 0; i < len(query); i++ {
+		if query[i] >= utf8.RuneSelf {
+			return true
+		}
+		counts[query[i]]++
+	}
+
+	needed := len(query) - maxEdits
+	for i := 0; i < len(text) && needed > 0; i++ {
+		if c := text[i]; c < utf8.RuneSelf && counts[c] > 0 {
+			counts[c]--
+			needed--
+		}
+	}
+	return needed <= 0
+}
+
+// distance returns the fewest edits turning query into any substring of text
+// (Sellers' algorithm, with adjacent transpositions counted as one edit).
+//
+// Column j holds, for each query prefix, the fewest edits matching it to a
+// substring of text ending at j; matching may start anywhere in text, so
+// every column starts at 0.
+func distance(query, text []rune) int {
+	m := len(query)
+	previous2 := make([]int, m+1) // Column j-2
+	previous := make([]int, m+1)  // Column j-1
+	current := make([]int, m+1)
+	for i := range previous {
+		previous[i] = i
+	}
+
+	best := previous[m]
+	for j := 1; j <= len(text); j++ {
+		current[0] = 0
+		for i := 1; i <= m; i++ {
+			cost := 1
+			if query[i-1] == text[j-1] {
+				cost = 0
+			}
+			current[i] = min(previous[i]+1, current[i-1]+1, previous[i-1]+cost)
+			if i > 1 && j > 1 && query[i-1] == text[j-2] && query[i-2] == text[j-1] {
+				current[i] = min(current[i], previous2[i-2]+1)
+			}
+		}
+		best = min(best, current[m])
+		previous2, previous, current = previous, current, previous2
+	}
+	return best
+}
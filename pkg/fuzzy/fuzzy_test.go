@@ -0,0 +1,38 @@
+package fuzzy
+
+import "testing"
+
+// TestMatch tests typo-tolerant matching against part of a text
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query, text string
+		wantEdits   int
+		wantOK      bool
+	}{
+		{"acme", "acme corp", 0, true},
+		{"acmee", "acme corp", 1, true},            // Inserted character
+		{"amce", "acme corp", 1, true},             // Swapped characters
+		{"acne", "big acme", 1, true},              // Substituted character
+		{"ruderstack", "rudderstack inc", 1, true}, // Deleted character
+		{"rudersatck", "rudderstack", 2, true},
+		{"acmeee", "acme", 2, false}, // Short queries tolerate one edit
+		{"xyzw", "acme corp", 4, false},
+		{"acm", "abm", 0, false},         // Too short to match fuzzily
+		{"zürich", "zurich re", 1, true}, // Edits count characters, not bytes
+	}
+	for _, tt := range tests {
+		edits, ok := Match(tt.query, tt.text)
+		if ok != tt.wantOK || (ok && edits != tt.wantEdits) {
+			t.Errorf("Match(%q, %q) = %d, %v, want %d, %v", tt.query, tt.text, edits, ok, tt.wantEdits, tt.wantOK)
+		}
+	}
+}
+
+// TestMaxEdits tests the typos tolerated for each query length
+func TestMaxEdits(t *testing.T) {
+	for query, want := range map[string]int{"": 0, "acm": 0, "acme": 1, "acme co": 1, "acme cor": 2, "rudderstack": 2} {
+		if got := MaxEdits(query); got != want {
+			t.Errorf("MaxEdits(%q) = %d, want %d", query, got, want)
+		}
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -49,11 +52,22 @@ func (f CheckerFunc) Check(ctx context.Context) Check {
 	return f(ctx)
 }
 
+const (
+	// DefaultMaxConcurrency bounds how many checks run at once per request
+	DefaultMaxConcurrency = 4
+
+	// DefaultCheckTimeout bounds how long a single check may take
+	DefaultCheckTimeout = 3 * time.Second
+)
+
 // Manager manages health checks and provides handlers
 type Manager struct {
 	startTime       time.Time
 	livenessChecks  map[string]Checker
 	readinessChecks map[string]Checker
+	degradable      map[string]bool // Readiness checks whose degraded status doesn't fail readiness
+	maxConcurrency  int             // Maximum checks running at once per request
+	checkTimeout    time.Duration   // Per-check deadline
 	mu              sync.RWMutex
 	logger          *zap.Logger
 }
@@ -64,10 +78,26 @@ func NewManager(logger *zap.Logger) *Manager {
 		startTime:       time.Now(),
 		livenessChecks:  make(map[string]Checker),
 		readinessChecks: make(map[string]Checker),
+		degradable:      make(map[string]bool),
+		maxConcurrency:  DefaultMaxConcurrency,
+		checkTimeout:    DefaultCheckTimeout,
 		logger:          logger,
 	}
 }
 
+// SetLimits sets how many checks may run concurrently and how long each may take.
+// Non-positive values keep the current setting.
+func (m *Manager) SetLimits(maxConcurrency int, checkTimeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if maxConcurrency > 0 {
+		m.maxConcurrency = maxConcurrency
+	}
+	if checkTimeout > 0 {
+		m.checkTimeout = checkTimeout
+	}
+}
+
 // RegisterLivenessCheck registers a liveness check
 // Liveness checks indicate if the application is running and should be restarted if failing
 func (m *Manager) RegisterLivenessCheck(name string, checker Checker) {
@@ -82,43 +112,94 @@ func (m *Manager) RegisterReadinessCheck(name string, checker Checker) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.readinessChecks[name] = checker
+	delete(m.degradable, name)
 }
 
-// runChecks executes all checks in parallel with timeout
+// RegisterDegradableReadinessCheck registers a readiness check that may report
+// degraded without failing readiness: /ready shows the degraded status but still
+// returns 200, so a dependency that's slow but working (e.g. Notion latency above
+// its threshold) doesn't take the instance out of rotation. Unhealthy still fails it.
+func (m *Manager) RegisterDegradableReadinessCheck(name string, checker Checker) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.readinessChecks[name] = checker
+	m.degradable[name] = true
+}
+
+// runChecks executes checks concurrently and returns results sorted by check name.
+//
+// At most maxConcurrency checks run at once so /ready latency stays roughly flat as
+// checks are added without stampeding dependencies. Each check gets its own
+// checkTimeout deadline; a check that doesn't return in time (or panics) is
+// reported unhealthy without blocking the others or the response.
 func (m *Manager) runChecks(ctx context.Context, checks map[string]Checker) []Check {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	checkers := make([]Checker, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		checkers[i] = checks[name]
+	}
+	maxConcurrency, checkTimeout := m.maxConcurrency, m.checkTimeout
+	m.mu.RUnlock()
 
-	results := make([]Check, 0, len(checks))
-	resultsChan := make(chan Check, len(checks))
+	results := make([]Check, len(names))
+	semaphore := make(chan struct{}, maxConcurrency)
 
 	var wg sync.WaitGroup
-	for name, checker := range checks {
+	for i := range names {
 		wg.Add(1)
-		go func(n string, c Checker) {
+		go func(i int) {
 			defer wg.Done()
-			start := time.Now()
-			check := c.Check(ctx)
-			check.Duration = time.Since(start).String()
-			if check.Name == "" {
-				check.Name = n
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				results[i] = Check{Name: names[i], Status: StatusUnhealthy, Message: "Check not started: " + ctx.Err().Error()}
+				return
 			}
-			resultsChan <- check
-		}(name, checker)
+
+			results[i] = m.runCheck(ctx, names[i], checkers[i], checkTimeout)
+		}(i)
 	}
+	wg.Wait()
+
+	return results
+}
+
+// runCheck runs a single check with its own deadline.
+func (m *Manager) runCheck(ctx context.Context, name string, checker Checker, timeout time.Duration) Check {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	// Wait for all checks to complete
+	start := time.Now()
+	done := make(chan Check, 1) // Buffered so a late check never blocks
 	go func() {
-		wg.Wait()
-		close(resultsChan)
+		defer func() {
+			if r := recover(); r != nil {
+				m.logger.Error("health check panicked", zap.String("check", name), zap.Any("panic", r))
+				done <- Check{Status: StatusUnhealthy, Message: fmt.Sprintf("Check panicked: %v", r)}
+			}
+		}()
+		done <- checker.Check(ctx)
 	}()
 
-	// Collect results
-	for check := range resultsChan {
-		results = append(results, check)
+	var check Check
+	select {
+	case check = <-done:
+	case <-ctx.Done():
+		check = Check{Status: StatusUnhealthy, Message: fmt.Sprintf("Check timed out after %s", timeout)}
 	}
 
-	return results
+	check.Duration = time.Since(start).String()
+	if check.Name == "" {
+		check.Name = name
+	}
+	return check
 }
 
 // determineOverallStatus determines the overall status based on individual checks
@@ -195,9 +276,10 @@ func (m *Manager) ReadinessHandler() http.HandlerFunc {
 			Checks:    checks,
 		}
 
-		// Readiness should fail for both unhealthy and degraded states
+		// Readiness should fail for both unhealthy and degraded states, except
+		// for checks registered as degradable
 		statusCode := http.StatusOK
-		if status == StatusUnhealthy || status == StatusDegraded {
+		if m.readinessFailed(checks) {
 			statusCode = http.StatusServiceUnavailable
 		}
 
@@ -205,6 +287,25 @@ func (m *Manager) ReadinessHandler() http.HandlerFunc {
 	}
 }
 
+// readinessFailed reports whether any check is unhealthy, or degraded without
+// having been registered with RegisterDegradableReadinessCheck.
+func (m *Manager) readinessFailed(checks []Check) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, check := range checks {
+		switch check.Status {
+		case StatusUnhealthy:
+			return true
+		case StatusDegraded:
+			if !m.degradable[check.Name] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // writeResponse writes the JSON response
 func (m *Manager) writeResponse(w http.ResponseWriter, statusCode int, response Response) {
 	w.Header().Set("Content-Type", "application/json")
@@ -215,21 +316,130 @@ func (m *Manager) writeResponse(w http.ResponseWriter, statusCode int, response
 	}
 }
 
-// NotionHealthChecker creates a health checker for Notion API connectivity
-func NotionHealthChecker(checkFunc func(ctx context.Context) error) Checker {
+// notionLatencyWindow is how many successful calls the Notion check's p95
+// latency is computed over.
+const notionLatencyWindow = 20
+
+// notionChecker is the Checker returned by NotionHealthChecker. It remembers
+// the latency of recent successful calls, when the last one succeeded, and how
+// many calls have failed since. It is safe for concurrent use.
+type notionChecker struct {
+	checkFunc        func(ctx context.Context) error
+	latencyThreshold time.Duration
+	now              func() time.Time // Replaced in tests
+
+	mu                  sync.Mutex
+	latencies           []time.Duration // Ring buffer of the last notionLatencyWindow successful calls
+	next                int             // Index in latencies the next latency is written to
+	lastSuccess         time.Time
+	consecutiveFailures int
+}
+
+// NotionHealthChecker creates a health checker for Notion API connectivity.
+//
+// A failed call is unhealthy. A successful one is degraded rather than healthy
+// while the rolling p95 latency of the last successful calls exceeds
+// latencyThreshold (non-positive disables it), so a slow Notion is reported
+// without flapping on a single slow call; register it with
+// RegisterDegradableReadinessCheck to keep serving meanwhile. The check's
+// metadata has the last successful call's time, the p95 latency and the number
+// of consecutive failures.
+func NotionHealthChecker(checkFunc func(ctx context.Context) error, latencyThreshold time.Duration) Checker {
+	return &notionChecker{
+		checkFunc:        checkFunc,
+		latencyThreshold: latencyThreshold,
+		now:              time.Now,
+		latencies:        make([]time.Duration, 0, notionLatencyWindow),
+	}
+}
+
+// Check calls Notion and reports its health with the recent call history.
+func (c *notionChecker) Check(ctx context.Context) Check {
+	start := c.now()
+	err := c.checkFunc(ctx)
+	end := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		c.consecutiveFailures++
+		return Check{
+			Name:     "notion_api",
+			Status:   StatusUnhealthy,
+			Message:  fmt.Sprintf("Failed to connect to Notion API: %v", err),
+			Metadata: c.metadata(),
+		}
+	}
+
+	c.consecutiveFailures = 0
+	c.lastSuccess = end
+	if len(c.latencies) < notionLatencyWindow {
+		c.latencies = append(c.latencies, end.Sub(start))
+	} else {
+		c.latencies[c.next] = end.Sub(start)
+	}
+	c.next = (c.next + 1) % notionLatencyWindow
+
+	if p95 := c.p95(); c.latencyThreshold > 0 && p95 > c.latencyThreshold {
+		return Check{
+			Name:     "notion_api",
+			Status:   StatusDegraded,
+			Message:  fmt.Sprintf("Notion API is slow (p95 latency %s, threshold %s)", p95, c.latencyThreshold),
+			Metadata: c.metadata(),
+		}
+	}
+	return Check{
+		Name:     "notion_api",
+		Status:   StatusHealthy,
+		Message:  "Notion API is reachable",
+		Metadata: c.metadata(),
+	}
+}
+
+// p95 returns the nearest-rank 95th percentile of the recorded latencies, or 0
+// if there are none. c.mu must be held.
+func (c *notionChecker) p95() time.Duration {
+	if len(c.latencies) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(c.latencies)
+	slices.Sort(sorted)
+	rank := (len(sorted)*95 + 99) / 100 // ceil(0.95 * n)
+	return sorted[rank-1]
+}
+
+// metadata describes the recent call history for the check's metadata.
+// c.mu must be held.
+func (c *notionChecker) metadata() map[string]interface{} {
+	metadata := map[string]interface{}{
+		"consecutive_failures": c.consecutiveFailures,
+		"p95_latency":          c.p95().String(),
+	}
+	if c.latencyThreshold > 0 {
+		metadata["latency_threshold"] = c.latencyThreshold.String()
+	}
+	if !c.lastSuccess.IsZero() {
+		metadata["last_success"] = c.lastSuccess.UTC().Format(time.RFC3339)
+	}
+	return metadata
+}
+
+// LinearHealthChecker creates a health checker for Linear API connectivity (BACKEND=linear)
+func LinearHealthChecker(checkFunc func(ctx context.Context) error) Checker {
 	return CheckerFunc(func(ctx context.Context) Check {
 		err := checkFunc(ctx)
 		if err != nil {
 			return Check{
-				Name:    "notion_api",
+				Name:    "linear_api",
 				Status:  StatusUnhealthy,
-				Message: fmt.Sprintf("Failed to connect to Notion API: %v", err),
+				Message: fmt.Sprintf("Failed to connect to Linear API: %v", err),
 			}
 		}
 		return Check{
-			Name:    "notion_api",
+			Name:    "linear_api",
 			Status:  StatusHealthy,
-			Message: "Notion API is reachable",
+			Message: "Linear API is reachable",
 		}
 	})
 }
@@ -246,6 +456,25 @@ func AlwaysHealthyChecker() Checker {
 	})
 }
 
+// StartupChecker creates a health checker for initialization that completes in
+// the background (lazy startup): unhealthy until initialized reports true.
+func StartupChecker(initialized func() bool) Checker {
+	return CheckerFunc(func(ctx context.Context) Check {
+		if !initialized() {
+			return Check{
+				Name:    "startup",
+				Status:  StatusUnhealthy,
+				Message: "Initialization from Notion has not completed yet, retrying in the background",
+			}
+		}
+		return Check{
+			Name:    "startup",
+			Status:  StatusHealthy,
+			Message: "Initialization complete",
+		}
+	})
+}
+
 // ClientCacheChecker creates a health checker for the client cache
 func ClientCacheChecker(getClientCount func() int, minExpected int) Checker {
 	return CheckerFunc(func(ctx context.Context) Check {
@@ -284,3 +513,52 @@ func ClientCacheChecker(getClientCount func() int, minExpected int) Checker {
 		}
 	})
 }
+
+// NotionPermissionsChecker creates a health checker for Notion integration permissions.
+//
+// getStatuses returns the latest capability -> status map ("granted", "missing",
+// "unknown"), or nil if permissions haven't been checked yet. Any "missing"
+// capability makes the check unhealthy, since submissions or validation will fail
+// until the database is shared or the capability is enabled. Inconclusive probes
+// don't fail the check; Notion connectivity is covered by NotionHealthChecker.
+func NotionPermissionsChecker(getStatuses func() map[string]string) Checker {
+	return CheckerFunc(func(ctx context.Context) Check {
+		statuses := getStatuses()
+		if statuses == nil {
+			return Check{
+				Name:    "notion_permissions",
+				Status:  StatusDegraded,
+				Message: "Notion permissions have not been checked yet",
+			}
+		}
+
+		var missing []string
+		for capability, status := range statuses {
+			if status == "missing" {
+				missing = append(missing, capability)
+			}
+		}
+		sort.Strings(missing)
+
+		metadata := map[string]interface{}{
+			"capabilities": statuses,
+		}
+
+		if len(missing) > 0 {
+			metadata["missing"] = missing
+			return Check{
+				Name:     "notion_permissions",
+				Status:   StatusUnhealthy,
+				Message:  fmt.Sprintf("Notion integration is missing permissions: %s", strings.Join(missing, ", ")),
+				Metadata: metadata,
+			}
+		}
+
+		return Check{
+			Name:     "notion_permissions",
+			Status:   StatusHealthy,
+			Message:  "Notion integration has required permissions",
+			Metadata: metadata,
+		}
+	})
+}
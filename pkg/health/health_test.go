@@ -3,8 +3,10 @@ package health
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -173,7 +175,7 @@ func TestNotionHealthChecker(t *testing.T) {
 	t.Run("healthy", func(t *testing.T) {
 		checker := NotionHealthChecker(func(ctx context.Context) error {
 			return nil
-		})
+		}, time.Second)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -188,7 +190,7 @@ func TestNotionHealthChecker(t *testing.T) {
 	t.Run("unhealthy", func(t *testing.T) {
 		checker := NotionHealthChecker(func(ctx context.Context) error {
 			return context.DeadlineExceeded
-		})
+		}, time.Second)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -201,6 +203,83 @@ func TestNotionHealthChecker(t *testing.T) {
 	})
 }
 
+// TestNotionHealthChecker_History tests the latency, last success and failure
+// count the Notion check tracks, and degrading on a high p95 latency
+func TestNotionHealthChecker_History(t *testing.T) {
+	var (
+		now     = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+		latency time.Duration
+		err     error
+	)
+	checker := NotionHealthChecker(func(ctx context.Context) error {
+		now = now.Add(latency)
+		return err
+	}, time.Second).(*notionChecker)
+	checker.now = func() time.Time { return now }
+
+	// One slow call among 20 doesn't move the p95 above the threshold
+	latency = 5 * time.Second
+	checker.Check(context.Background())
+	latency = 100 * time.Millisecond
+	for range 18 {
+		checker.Check(context.Background())
+	}
+	check := checker.Check(context.Background())
+	if check.Status != StatusHealthy || check.Metadata["p95_latency"] != "100ms" {
+		t.Errorf("check = %+v, want healthy with a 100ms p95", check)
+	}
+	lastSuccess := now.Format(time.RFC3339)
+
+	// Failures keep the last success and count up
+	err = context.DeadlineExceeded
+	checker.Check(context.Background())
+	check = checker.Check(context.Background())
+	if check.Status != StatusUnhealthy || check.Metadata["consecutive_failures"] != 2 || check.Metadata["last_success"] != lastSuccess {
+		t.Errorf("check = %+v, want unhealthy after 2 failures since %s", check, lastSuccess)
+	}
+
+	// Two slow calls in the window of 20 (the first replacing the oldest call)
+	// raise the p95 above the threshold
+	err = nil
+	latency = 3 * time.Second
+	if check = checker.Check(context.Background()); check.Status != StatusHealthy {
+		t.Errorf("check = %+v, want healthy with one slow call in the window", check)
+	}
+	check = checker.Check(context.Background())
+	if check.Status != StatusDegraded || check.Metadata["p95_latency"] != "3s" || check.Metadata["consecutive_failures"] != 0 {
+		t.Errorf("check = %+v, want degraded with a 3s p95 and no failures", check)
+	}
+}
+
+// TestNotionHealthChecker_NoHistory tests the metadata before any call succeeded
+func TestNotionHealthChecker_NoHistory(t *testing.T) {
+	checker := NotionHealthChecker(func(ctx context.Context) error { return context.DeadlineExceeded }, 0)
+
+	check := checker.Check(context.Background())
+	if _, found := check.Metadata["last_success"]; found {
+		t.Errorf("metadata = %v, want no last success", check.Metadata)
+	}
+	if _, found := check.Metadata["latency_threshold"]; found {
+		t.Errorf("metadata = %v, want no latency threshold when disabled", check.Metadata)
+	}
+	if check.Metadata["consecutive_failures"] != 1 || check.Metadata["p95_latency"] != "0s" {
+		t.Errorf("metadata = %v, want 1 failure and no latency", check.Metadata)
+	}
+}
+
+// TestLinearHealthChecker tests LinearHealthChecker
+func TestLinearHealthChecker(t *testing.T) {
+	checker := LinearHealthChecker(func(ctx context.Context) error { return nil })
+	if check := checker.Check(context.Background()); check.Status != StatusHealthy || check.Name != "linear_api" {
+		t.Errorf("check = %+v, want a healthy linear_api check", check)
+	}
+
+	checker = LinearHealthChecker(func(ctx context.Context) error { return context.DeadlineExceeded })
+	if check := checker.Check(context.Background()); check.Status != StatusUnhealthy {
+		t.Errorf("check status = %v, want %v", check.Status, StatusUnhealthy)
+	}
+}
+
 // TestClientCacheChecker tests ClientCacheChecker
 func TestClientCacheChecker(t *testing.T) {
 	t.Run("healthy", func(t *testing.T) {
@@ -243,6 +322,68 @@ func TestClientCacheChecker(t *testing.T) {
 	})
 }
 
+// TestStartupChecker tests StartupChecker
+func TestStartupChecker(t *testing.T) {
+	initialized := false
+	checker := StartupChecker(func() bool { return initialized })
+
+	if check := checker.Check(context.Background()); check.Status != StatusUnhealthy {
+		t.Errorf("check status = %v before initialization, want %v", check.Status, StatusUnhealthy)
+	}
+	initialized = true
+	if check := checker.Check(context.Background()); check.Status != StatusHealthy {
+		t.Errorf("check status = %v after initialization, want %v", check.Status, StatusHealthy)
+	}
+}
+
+// TestNotionPermissionsChecker tests NotionPermissionsChecker
+func TestNotionPermissionsChecker(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses map[string]string
+		want     Status
+	}{
+		{
+			name:     "not_checked",
+			statuses: nil,
+			want:     StatusDegraded,
+		},
+		{
+			name:     "all_granted",
+			statuses: map[string]string{"read_users": "granted", "insert_ideas": "granted"},
+			want:     StatusHealthy,
+		},
+		{
+			name:     "unknown_does_not_fail",
+			statuses: map[string]string{"read_users": "granted", "insert_ideas": "unknown"},
+			want:     StatusHealthy,
+		},
+		{
+			name:     "missing",
+			statuses: map[string]string{"read_users": "missing", "insert_ideas": "granted"},
+			want:     StatusUnhealthy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NotionPermissionsChecker(func() map[string]string { return tt.statuses })
+
+			check := checker.Check(context.Background())
+
+			if check.Status != tt.want {
+				t.Errorf("check status = %v, want %v", check.Status, tt.want)
+			}
+			if tt.want == StatusUnhealthy {
+				missing, ok := check.Metadata["missing"].([]string)
+				if !ok || len(missing) != 1 || missing[0] != "read_users" {
+					t.Errorf("check metadata missing = %v, want [read_users]", check.Metadata["missing"])
+				}
+			}
+		})
+	}
+}
+
 // TestDetermineOverallStatus tests status determination logic
 func TestDetermineOverallStatus(t *testing.T) {
 	tests := []struct {
@@ -381,6 +522,45 @@ func TestReadinessHandler(t *testing.T) {
 	}
 }
 
+// TestReadinessHandler_Degradable tests that a degradable check's degraded
+// status doesn't fail readiness, while its unhealthy status and other checks'
+// degraded status still do
+func TestReadinessHandler_Degradable(t *testing.T) {
+	status := StatusDegraded
+	manager := NewManager(zap.NewNop())
+	manager.RegisterDegradableReadinessCheck("notion_api", CheckerFunc(func(ctx context.Context) Check {
+		return Check{Name: "notion_api", Status: status}
+	}))
+
+	ready := func() (int, Status) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		manager.ReadinessHandler()(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		var response Response
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		return w.Code, response.Status
+	}
+
+	if code, overall := ready(); code != http.StatusOK || overall != StatusDegraded {
+		t.Errorf("degradable check degraded: %d %s, want 200 degraded", code, overall)
+	}
+
+	status = StatusUnhealthy
+	if code, _ := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("degradable check unhealthy: %d, want 503", code)
+	}
+
+	status = StatusDegraded
+	manager.RegisterReadinessCheck("client_cache", CheckerFunc(func(ctx context.Context) Check {
+		return Check{Name: "client_cache", Status: StatusDegraded}
+	}))
+	if code, _ := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("other check degraded: %d, want 503", code)
+	}
+}
+
 // TestReadinessHandler_Degraded tests readiness endpoint with degraded status
 func TestReadinessHandler_Degraded(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
@@ -514,3 +694,109 @@ func TestMultipleChecks(t *testing.T) {
 		t.Errorf("expected 5 checks, got %d", len(response.Checks))
 	}
 }
+
+// TestRunChecks_DeterministicOrder tests that results are sorted by check name
+func TestRunChecks_DeterministicOrder(t *testing.T) {
+	manager := NewManager(zap.NewNop())
+	names := []string{"notion", "cache", "users", "permissions", "analytics"}
+	for _, name := range names {
+		manager.RegisterReadinessCheck(name, CheckerFunc(func(ctx context.Context) Check {
+			return Check{Status: StatusHealthy}
+		}))
+	}
+
+	want := []string{"analytics", "cache", "notion", "permissions", "users"}
+	for run := 0; run < 10; run++ {
+		results := manager.runChecks(context.Background(), manager.readinessChecks)
+		if len(results) != len(want) {
+			t.Fatalf("expected %d checks, got %d", len(want), len(results))
+		}
+		for i, check := range results {
+			if check.Name != want[i] {
+				t.Fatalf("run %d: results[%d] = %s, want %s", run, i, check.Name, want[i])
+			}
+		}
+	}
+}
+
+// TestRunChecks_Timeout tests that a hung check is reported unhealthy without blocking
+func TestRunChecks_Timeout(t *testing.T) {
+	manager := NewManager(zap.NewNop())
+	manager.SetLimits(0, 50*time.Millisecond)
+
+	release := make(chan struct{})
+	defer close(release)
+	manager.RegisterReadinessCheck("hung", CheckerFunc(func(ctx context.Context) Check {
+		<-release // Ignores ctx on purpose
+		return Check{Status: StatusHealthy}
+	}))
+	manager.RegisterReadinessCheck("ok", CheckerFunc(func(ctx context.Context) Check {
+		return Check{Status: StatusHealthy}
+	}))
+
+	start := time.Now()
+	results := manager.runChecks(context.Background(), manager.readinessChecks)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("runChecks took %s, expected it to stop at the check timeout", elapsed)
+	}
+
+	if results[0].Name != "hung" || results[0].Status != StatusUnhealthy {
+		t.Errorf("hung check = %+v, want unhealthy", results[0])
+	}
+	if results[1].Name != "ok" || results[1].Status != StatusHealthy {
+		t.Errorf("ok check = %+v, want healthy", results[1])
+	}
+}
+
+// TestRunChecks_ConcurrencyLimit tests that no more than maxConcurrency checks run at once
+func TestRunChecks_ConcurrencyLimit(t *testing.T) {
+	manager := NewManager(zap.NewNop())
+	manager.SetLimits(2, time.Second)
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	for i := 0; i < 6; i++ {
+		manager.RegisterReadinessCheck(fmt.Sprintf("check_%d", i), CheckerFunc(func(ctx context.Context) Check {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return Check{Status: StatusHealthy}
+		}))
+	}
+
+	results := manager.runChecks(context.Background(), manager.readinessChecks)
+	if len(results) != 6 {
+		t.Fatalf("expected 6 checks, got %d", len(results))
+	}
+	if peak > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", peak)
+	}
+	if peak < 2 {
+		t.Errorf("peak concurrency = %d, expected checks to run in parallel", peak)
+	}
+}
+
+// TestRunChecks_Panic tests that a panicking check is reported unhealthy
+func TestRunChecks_Panic(t *testing.T) {
+	manager := NewManager(zap.NewNop())
+	manager.RegisterLivenessCheck("boom", CheckerFunc(func(ctx context.Context) Check {
+		panic("kaboom")
+	}))
+
+	results := manager.runChecks(context.Background(), manager.livenessChecks)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 check, got %d", len(results))
+	}
+	if results[0].Status != StatusUnhealthy || results[0].Name != "boom" {
+		t.Errorf("panicking check = %+v, want unhealthy boom", results[0])
+	}
+}
@@ -0,0 +1,412 @@
+// Package httpclient provides the http.RoundTripper shared by the bot's
+// outbound API clients (Notion and Slack), so transient network failures are
+// retried instead of surfacing to users.
+//
+// Features:
+// - Retries with exponential backoff and full jitter, honouring Retry-After
+// - A timeout per attempt, so one hung connection doesn't use up the whole request deadline
+// - A circuit breaker per host that fails fast while the API is down
+// - Retry and circuit breaker metrics (client label = the name given to New)
+//
+// What is retried depends on whether the request could have been processed:
+// - Always: connection failures (the request was never sent), 429 and 503
+// - Idempotent requests only: other network errors, attempt timeouts, 500, 502 and 504
+//
+// GET, HEAD, OPTIONS, PUT and DELETE are idempotent; other methods can opt in
+// with Idempotent (e.g. Notion's POST query endpoints, which only read).
+// Requests whose body can't be replayed (no GetBody) are sent once.
+//
+// Circuit breaker:
+//   - Closed: requests pass; FailureThreshold consecutive failed requests (after
+//     retries) open it. Failures are network errors and 5xx responses.
+//   - Open: requests fail immediately with ErrCircuitOpen for OpenDuration
+//   - Half-open: one probe request is let through; success closes the circuit,
+//     failure opens it again
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// ErrCircuitOpen is returned for requests rejected by an open circuit breaker.
+// http.Client wraps it in a *url.Error; use errors.Is to detect it.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Config holds the retry and circuit breaker settings.
+type Config struct {
+	// MaxAttempts is the number of attempts per request, including the first.
+	MaxAttempts int
+
+	// InitialBackoff is the backoff cap before the first retry; it doubles each
+	// retry up to MaxBackoff. The actual delay is random within the cap.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the backoff. A Retry-After longer than this isn't waited
+	// for: the response is returned as is.
+	MaxBackoff time.Duration
+
+	// AttemptTimeout bounds each attempt, including reading the response body.
+	// Zero disables it (the request context still applies).
+	AttemptTimeout time.Duration
+
+	// FailureThreshold is the number of consecutive failed requests that opens
+	// a host's circuit. Zero disables the circuit breaker.
+	FailureThreshold int
+
+	// OpenDuration is how long an open circuit rejects requests before letting a probe through.
+	OpenDuration time.Duration
+}
+
+// DefaultConfig returns the settings used for the Notion and Slack clients.
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts:      constants.HTTPClientMaxAttempts,
+		InitialBackoff:   constants.HTTPClientInitialBackoff,
+		MaxBackoff:       constants.HTTPClientMaxBackoff,
+		AttemptTimeout:   constants.HTTPClientAttemptTimeout,
+		FailureThreshold: constants.CircuitBreakerFailureThreshold,
+		OpenDuration:     constants.CircuitBreakerOpenDuration,
+	}
+}
+
+// idempotentKey marks a request context as safe to retry (see Idempotent).
+type idempotentKey struct{}
+
+// Idempotent marks requests made with the returned context as safe to retry
+// after ambiguous failures, whatever their method.
+func Idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// Transport is an http.RoundTripper that retries transient failures and
+// breaks the circuit to hosts that keep failing. It is safe for concurrent use.
+type Transport struct {
+	name     string
+	config   Config
+	base     http.RoundTripper
+	metrics  *metrics.Metrics
+	logger   *zap.Logger
+	now      func() time.Time // Overridable for tests
+	mu       sync.Mutex
+	breakers map[string]*breaker // Keyed by host
+}
+
+// New creates a transport for the named client (used in logs and metric
+// labels) that sends requests with base, or http.DefaultTransport if nil.
+func New(name string, config Config, base http.RoundTripper, logger *zap.Logger) *Transport {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 1
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		name:     name,
+		config:   config,
+		base:     base,
+		logger:   logger,
+		now:      time.Now,
+		breakers: make(map[string]*breaker),
+	}
+}
+
+// NewClient returns an http.Client using a new transport with the default
+// config; timeout bounds each request including its retries.
+func NewClient(name string, timeout time.Duration, logger *zap.Logger) (*http.Client, *Transport) {
+	transport := New(name, DefaultConfig(), nil, logger)
+	return &http.Client{Transport: transport, Timeout: timeout}, transport
+}
+
+// SetMetrics sets the metrics instance for the transport. nil disables metrics recording.
+func (t *Transport) SetMetrics(m *metrics.Metrics) {
+	t.metrics = m
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b := t.breaker(req.URL.Host)
+	if !b.allow(t.now()) {
+		t.recordRejected()
+		return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, req.URL.Host)
+	}
+
+	resp, err := t.roundTripWithRetry(req)
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+	if err != nil && req.Context().Err() != nil {
+		// The caller gave up; that says nothing about the host
+		failed = false
+	}
+	t.recordOutcome(b, req.URL.Host, failed)
+	return resp, err
+}
+
+// roundTripWithRetry sends the request, retrying as described in the package doc.
+func (t *Transport) roundTripWithRetry(req *http.Request) (*http.Response, error) {
+	idempotent := isIdempotent(req)
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.send(attemptReq)
+		if req.Context().Err() != nil || attempt >= t.config.MaxAttempts || !replayable {
+			return resp, err
+		}
+
+		reason, wait, retry := t.shouldRetry(resp, err, idempotent)
+		if !retry {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if wait < 0 {
+			wait = t.backoff(attempt)
+		}
+
+		t.recordRetry(reason)
+		t.logger.Debug("retrying outbound request",
+			zap.String("client", t.name),
+			zap.String("method", req.Method),
+			zap.String("host", req.URL.Host),
+			zap.String("path", req.URL.Path),
+			zap.Int("attempt", attempt),
+			zap.String("reason", reason),
+			zap.Duration("backoff", wait),
+		)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// send makes one attempt, bounded by AttemptTimeout. The attempt's context is
+// released when the response body is closed.
+func (t *Transport) send(req *http.Request) (*http.Response, error) {
+	if t.config.AttemptTimeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.config.AttemptTimeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose cancels an attempt's context when the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// shouldRetry decides whether an attempt's outcome is retried. It returns the
+// reason (for logs and metrics) and the delay Retry-After asks for, or -1 to
+// use the backoff.
+func (t *Transport) shouldRetry(resp *http.Response, err error, idempotent bool) (reason string, wait time.Duration, retry bool) {
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return "connect", -1, true
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "timeout", -1, idempotent
+		}
+		return "network", -1, idempotent
+	}
+
+	reason = strconv.Itoa(resp.StatusCode)
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		wait, ok := retryAfter(resp)
+		if !ok {
+			return reason, -1, true
+		}
+		return reason, wait, wait <= t.config.MaxBackoff
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return reason, -1, idempotent
+	}
+	return "", 0, false
+}
+
+// backoff returns a random delay up to InitialBackoff doubled for each retry
+// already made, capped at MaxBackoff ("full jitter").
+func (t *Transport) backoff(attempt int) time.Duration {
+	limit := t.config.InitialBackoff
+	for i := 1; i < attempt && limit < t.config.MaxBackoff; i++ {
+		limit *= 2
+	}
+	limit = min(limit, t.config.MaxBackoff)
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit + 1)
+}
+
+// retryAfter parses a Retry-After header given in seconds (the form Notion and Slack use).
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// isIdempotent reports whether a request is safe to resend after an ambiguous failure.
+func isIdempotent(req *http.Request) bool {
+	if marked, _ := req.Context().Value(idempotentKey{}).(bool); marked {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// breaker returns the host's circuit breaker, creating it on first use.
+func (t *Transport) breaker(host string) *breaker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, ok := t.breakers[host]
+	if !ok {
+		b = &breaker{threshold: t.config.FailureThreshold, openDuration: t.config.OpenDuration}
+		t.breakers[host] = b
+	}
+	return b
+}
+
+// recordOutcome feeds a request's outcome to its host's breaker, logging and
+// recording state changes.
+func (t *Transport) recordOutcome(b *breaker, host string, failed bool) {
+	opened, closed := b.record(failed, t.now())
+	switch {
+	case opened:
+		t.logger.Warn("circuit breaker opened, failing fast",
+			zap.String("client", t.name),
+			zap.String("host", host),
+			zap.Duration("open_for", t.config.OpenDuration),
+		)
+		t.recordCircuitOpen(host, true)
+	case closed:
+		t.logger.Info("circuit breaker closed", zap.String("client", t.name), zap.String("host", host))
+		t.recordCircuitOpen(host, false)
+	}
+}
+
+// recordRetry records a retried attempt by reason.
+func (t *Transport) recordRetry(reason string) {
+	if t.metrics != nil {
+		t.metrics.OutboundRetriesTotal.WithLabelValues(t.name, reason).Inc()
+	}
+}
+
+// recordRejected records a request rejected by an open circuit.
+func (t *Transport) recordRejected() {
+	if t.metrics != nil {
+		t.metrics.OutboundCircuitRejectionsTotal.WithLabelValues(t.name).Inc()
+	}
+}
+
+// recordCircuitOpen updates a host's circuit state gauge.
+func (t *Transport) recordCircuitOpen(host string, open bool) {
+	if t.metrics == nil {
+		return
+	}
+	value := 0.0
+	if open {
+		value = 1
+	}
+	t.metrics.OutboundCircuitOpen.WithLabelValues(t.name, host).Set(value)
+}
+
+// breaker is the circuit breaker state for one host.
+type breaker struct {
+	threshold    int
+	openDuration time.Duration
+
+	mu        sync.Mutex
+	failures  int       // Consecutive failed requests
+	openUntil time.Time // Zero while closed
+	probing   bool      // A half-open probe is in flight
+}
+
+// allow reports whether a request may be sent. Once an open circuit's
+// OpenDuration has passed, a single probe is allowed at a time.
+func (b *breaker) allow(now time.Time) bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record counts a request's outcome. It reports whether this outcome opened
+// the circuit, or closed one that was open.
+func (b *breaker) record(failed bool, now time.Time) (opened, closed bool) {
+	if b.threshold <= 0 {
+		return false, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasOpen := !b.openUntil.IsZero()
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		return false, wasOpen
+	}
+
+	b.failures++
+	if wasOpen || b.failures >= b.threshold {
+		b.openUntil = now.Add(b.openDuration)
+		return !wasOpen, false
+	}
+	return false, false
+}
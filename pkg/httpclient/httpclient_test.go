@@ -0,0 +1,310 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// testConfig retries quickly and never opens the circuit.
+func testConfig() Config {
+	return Config{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		AttemptTimeout: time.Second,
+	}
+}
+
+// statusServer responds with the given statuses in turn, then 200, and counts requests.
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		n := int(calls.Add(1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		_, _ = w.Write(body) // Echo, to check the body is replayed
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestRoundTrip_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		ctx       context.Context
+		statuses  []int
+		wantCalls int32
+		wantCode  int
+	}{
+		{"success", http.MethodGet, context.Background(), nil, 1, http.StatusOK},
+		{"get retries 502", http.MethodGet, context.Background(), []int{502, 502}, 3, http.StatusOK},
+		{"gives up after max attempts", http.MethodGet, context.Background(), []int{500, 500, 500, 500}, 3, http.StatusInternalServerError},
+		{"post retries 503", http.MethodPost, context.Background(), []int{503}, 2, http.StatusOK},
+		{"post retries 429", http.MethodPost, context.Background(), []int{429}, 2, http.StatusOK},
+		{"post doesn't retry 502", http.MethodPost, context.Background(), []int{502}, 1, http.StatusBadGateway},
+		{"idempotent post retries 502", http.MethodPost, Idempotent(context.Background()), []int{502}, 2, http.StatusOK},
+		{"client errors aren't retried", http.MethodGet, context.Background(), []int{400}, 1, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := statusServer(t, tt.statuses...)
+			client := &http.Client{Transport: New("test", testConfig(), nil, zap.NewNop())}
+
+			req, err := http.NewRequestWithContext(tt.ctx, tt.method, server.URL, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("requests = %d, want %d", got, tt.wantCalls)
+			}
+			if resp.StatusCode == http.StatusOK && string(body) != "payload" {
+				t.Errorf("body = %q, want the request body replayed", body)
+			}
+		})
+	}
+}
+
+func TestRoundTrip_RetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", r.URL.Query().Get("after"))
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: New("test", Config{MaxAttempts: 2, MaxBackoff: time.Second}, nil, zap.NewNop())}
+
+	// Within MaxBackoff: waited for and retried
+	start := time.Now()
+	resp, err := client.Get(server.URL + "?after=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || time.Since(start) < time.Second {
+		t.Errorf("status = %d after %v, want 200 after waiting Retry-After", resp.StatusCode, time.Since(start))
+	}
+
+	// Beyond MaxBackoff: returned as is
+	calls.Store(0)
+	resp, err = client.Get(server.URL + "?after=60")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+		t.Errorf("status = %d after %d requests, want 429 after 1", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestRoundTrip_AttemptTimeout(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-release // Hang until the test ends
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := testConfig()
+	config.AttemptTimeout = 50 * time.Millisecond
+	client := &http.Client{Transport: New("test", config, nil, zap.NewNop())}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v, want the hung attempt retried", err)
+	}
+	resp.Body.Close()
+	if calls.Load() != 2 {
+		t.Errorf("requests = %d, want 2", calls.Load())
+	}
+
+	// A POST that timed out might have been processed, so it isn't retried
+	calls.Store(0)
+	if _, err := client.Post(server.URL, "text/plain", strings.NewReader("x")); err == nil {
+		t.Error("Post() error = nil, want the attempt timeout")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("requests = %d, want 1", calls.Load())
+	}
+}
+
+func TestRoundTrip_ConnectionFailureRetriedForAnyMethod(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close() // Nothing listens, so connecting fails
+
+	var dials atomic.Int32
+	base := &http.Transport{DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+		dials.Add(1)
+		return (&net.Dialer{}).DialContext(ctx, network, address)
+	}}
+	client := &http.Client{Transport: New("test", testConfig(), base, zap.NewNop())}
+
+	if _, err := client.Post("http://"+addr, "text/plain", strings.NewReader("x")); err == nil {
+		t.Fatal("Post() error = nil, want connection refused")
+	}
+	if dials.Load() != 3 {
+		t.Errorf("dials = %d, want 3", dials.Load())
+	}
+}
+
+func TestRoundTrip_UnreplayableBodySentOnce(t *testing.T) {
+	server, calls := statusServer(t, 503)
+	client := &http.Client{Transport: New("test", testConfig(), nil, zap.NewNop())}
+
+	// A body without GetBody can't be resent
+	req, err := http.NewRequest(http.MethodPost, server.URL, io.NopCloser(strings.NewReader("x")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("status = %d after %d requests, want 503 after 1", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestRoundTrip_ContextCancelledDuringBackoff(t *testing.T) {
+	server, _ := statusServer(t, 503, 503, 503)
+	config := testConfig()
+	config.InitialBackoff = time.Minute
+	config.MaxBackoff = time.Minute
+	client := &http.Client{Transport: New("test", config, nil, zap.NewNop())}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	start := time.Now()
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Do() took %v, want it to stop waiting when the context ends", elapsed)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	config := Config{MaxAttempts: 1, FailureThreshold: 3, OpenDuration: time.Minute}
+	transport := New("test", config, nil, zap.NewNop())
+	now := time.Now()
+	transport.now = func() time.Time { return now }
+	client := &http.Client{Transport: transport}
+
+	get := func() error {
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	// Three failed requests open the circuit
+	for i := 0; i < 3; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("request %d: error = %v, want the 500 response", i, err)
+		}
+	}
+	if err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want ErrCircuitOpen", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("requests = %d, want the rejected one not sent", calls.Load())
+	}
+
+	// After OpenDuration a probe is let through; it fails, so the circuit reopens
+	now = now.Add(time.Minute)
+	if err := get(); err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+	if err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want ErrCircuitOpen after a failed probe", err)
+	}
+
+	// A successful probe closes it
+	healthy.Store(true)
+	now = now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("request %d after recovery: error = %v", i, err)
+		}
+	}
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	b := &breaker{threshold: 2, openDuration: time.Minute}
+	now := time.Now()
+
+	b.record(true, now)
+	b.record(false, now)
+	if opened, _ := b.record(true, now); opened {
+		t.Error("circuit opened, want consecutive failures reset by the success")
+	}
+	if opened, _ := b.record(true, now); !opened {
+		t.Error("circuit not opened after 2 consecutive failures")
+	}
+	if !b.allow(now.Add(time.Minute)) {
+		t.Fatal("probe not allowed after OpenDuration")
+	}
+	if b.allow(now.Add(time.Minute)) {
+		t.Error("second request allowed while the probe is in flight")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	transport := New("test", Config{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}, nil, zap.NewNop())
+
+	for attempt, limit := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: 300 * time.Millisecond} {
+		for i := 0; i < 50; i++ {
+			if d := transport.backoff(attempt); d < 0 || d > limit {
+				t.Fatalf("backoff(%d) = %v, want within [0, %v]", attempt, d, limit)
+			}
+		}
+	}
+}
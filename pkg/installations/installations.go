@@ -0,0 +1,79 @@
+// Package installations stores the bot tokens of Slack workspaces that
+// installed Hopperbot through the OAuth install flow.
+//
+// With a single workspace, the bot uses SLACK_BOT_TOKEN. When the app is
+// distributed, each workspace that completes /slack/oauth/install gets its own
+// bot token, saved here keyed by team ID; the Slack handler picks the token
+// matching the team of each request.
+//
+// Features:
+// - Pluggable Store interface
+// - JSON file-backed store so installations survive restarts (memory-only when no path is set)
+package installations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/jsonfile"
+)
+
+// Installation is a workspace's OAuth v2 install of the app.
+type Installation struct {
+	TeamID      string    `json:"team_id"`
+	TeamName    string    `json:"team_name"`
+	BotToken    string    `json:"bot_token"`    // xoxb- token for Web API calls in this workspace
+	BotUserID   string    `json:"bot_user_id"`  // The app's bot user in this workspace
+	Scopes      string    `json:"scopes"`       // Comma-separated scopes granted to the bot token
+	InstalledBy string    `json:"installed_by"` // Slack user ID of the installer
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// Store persists installations. Implementations must be safe for concurrent use.
+type Store interface {
+	// Save inserts or replaces the installation for its team.
+	Save(installation Installation) error
+
+	// Get returns the installation for a team, and whether there is one.
+	Get(teamID string) (Installation, bool, error)
+
+	// Delete removes a team's installation (e.g., after the app is uninstalled).
+	Delete(teamID string) error
+}
+
+// FileStore is a Store holding installations by team ID in a jsonfile.Map.
+// The file holds bot tokens; jsonfile writes it with 0600 permissions. With an
+// empty path the store is memory-only and workspaces must reinstall after a
+// restart.
+type FileStore struct {
+	*jsonfile.Map[string, Installation]
+}
+
+// NewFileStore creates a store backed by path, loading any installations already saved there.
+// A missing file is treated as empty; an empty path creates a memory-only store.
+func NewFileStore(path string) (*FileStore, error) {
+	installations, err := jsonfile.NewMap(path, "installations file",
+		func(installation Installation) string { return installation.TeamID },
+		func(a, b Installation) bool { return a.TeamID < b.TeamID })
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{installations}, nil
+}
+
+// Save inserts or replaces the installation for its team.
+func (s *FileStore) Save(installation Installation) error {
+	if installation.TeamID == "" {
+		return fmt.Errorf("team ID is required")
+	}
+	if installation.BotToken == "" {
+		return fmt.Errorf("bot token is required")
+	}
+	return s.Put(installation)
+}
+
+// Get returns the installation for a team.
+func (s *FileStore) Get(teamID string) (Installation, bool, error) {
+	installation, found := s.Map.Get(teamID)
+	return installation, found, nil
+}
@@ -0,0 +1,68 @@
+package installations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFileStore_Persistence tests that installations survive reopening the store
+func TestFileStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "installations.json")
+	installedAt := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	for _, installation := range []Installation{
+		{TeamID: "T1", TeamName: "Acme", BotToken: "xoxb-old", InstalledAt: installedAt},
+		{TeamID: "T1", TeamName: "Acme", BotToken: "xoxb-new", InstalledAt: installedAt},
+		{TeamID: "T2", TeamName: "Globex", BotToken: "xoxb-globex", InstalledAt: installedAt},
+	} {
+		if err := store.Save(installation); err != nil {
+			t.Fatalf("Save(%s) error = %v", installation.TeamID, err)
+		}
+	}
+	if err := store.Delete("T2"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat installations file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("file permissions = %o, want 600", perm)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopening store: %v", err)
+	}
+	if reopened.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", reopened.Len())
+	}
+	installation, found, err := reopened.Get("T1")
+	if err != nil || !found {
+		t.Fatalf("Get(T1) = %v, %v", found, err)
+	}
+	if installation.BotToken != "xoxb-new" || !installation.InstalledAt.Equal(installedAt) {
+		t.Errorf("Get(T1) = %+v, want the reinstalled token", installation)
+	}
+	if _, found, _ := reopened.Get("T2"); found {
+		t.Error("deleted installation was reloaded")
+	}
+}
+
+// TestFileStore_RequiredFields tests that installations without a team or token are rejected
+func TestFileStore_RequiredFields(t *testing.T) {
+	store, _ := NewFileStore("")
+	if err := store.Save(Installation{BotToken: "xoxb-1"}); err == nil {
+		t.Error("expected error for installation without team ID")
+	}
+	if err := store.Save(Installation{TeamID: "T1"}); err == nil {
+		t.Error("expected error for installation without bot token")
+	}
+}
@@ -0,0 +1,89 @@
+package jsonfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.json")
+
+	var items []string
+	if err := Load(path, &items); err != nil || items != nil {
+		t.Fatalf("Load(missing) = %v, %v, want nothing loaded", items, err)
+	}
+
+	if err := Save(path, []string{"a", "b"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := Save(path, []string{"c"}); err != nil {
+		t.Fatalf("second Save() error = %v", err)
+	}
+	if err := Load(path, &items); err != nil || !slices.Equal(items, []string{"c"}) {
+		t.Errorf("Load() = %v, %v, want [c]", items, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.json")
+	os.WriteFile(path, []byte("not json"), 0o600)
+
+	var items []string
+	if err := Load(path, &items); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("Load() error = %v, want a parse error naming the file", err)
+	}
+}
+
+func TestWrite_FailureKeepsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := Write(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "{\"a\":1}\n")
+		return err
+	}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	failed := errors.New("encoder failed")
+	err := Write(path, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Errorf("Write() error = %v, want the write error", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "{\"a\":1}\n" {
+		t.Errorf("file = %q, want the previous contents", data)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestSave_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "items.json")
+	if err := Save(path, []string{"a"}); err == nil {
+		t.Error("Save() into a missing directory error = nil, want error")
+	}
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Errorf("temp file %s left behind", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package jsonfile
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Map is a set of values keyed by K, kept in memory and mirrored to a JSON
+// file as an array in the order given by less. It is safe for concurrent use.
+//
+// The whole file is rewritten on every change, which suits the stores this
+// package serves: at most a few thousand values. With an empty path the map is
+// memory-only and its values are lost on restart.
+//
+// Stores embed a Map for Delete and Len and build their own methods on
+// Values, Get, Put and Update.
+type Map[K comparable, V any] struct {
+	path   string
+	name   string // Describes the file in errors, e.g. "drafts file"
+	key    func(V) K
+	less   func(a, b V) bool
+	mu     sync.RWMutex
+	values map[K]V
+}
+
+// NewMap creates a map backed by path, loading any values already saved
+// there, each stored under key(value). A missing file is treated as empty; an
+// empty path creates a memory-only map. name describes the file in errors.
+func NewMap[K comparable, V any](path, name string, key func(V) K, less func(a, b V) bool) (*Map[K, V], error) {
+	m := &Map[K, V]{
+		path:   path,
+		name:   name,
+		key:    key,
+		less:   less,
+		values: make(map[K]V),
+	}
+	if path == "" {
+		return m, nil
+	}
+
+	var saved []V
+	if err := Load(path, &saved); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", name, err)
+	}
+	for _, value := range saved {
+		m.values[key(value)] = value
+	}
+	return m, nil
+}
+
+// Values returns all values in order.
+func (m *Map[K, V]) Values() []V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sorted()
+}
+
+// Get returns the value stored under k.
+func (m *Map[K, V]) Get(k K) (V, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.values[k]
+	return value, ok
+}
+
+// Put inserts or replaces a value.
+func (m *Map[K, V]) Put(value V) error {
+	return m.Update(func(values map[K]V) bool {
+		values[m.key(value)] = value
+		return true
+	})
+}
+
+// Delete removes the value stored under k. Deleting an unknown key is not an error.
+func (m *Map[K, V]) Delete(k K) error {
+	return m.Update(func(values map[K]V) bool {
+		if _, ok := values[k]; !ok {
+			return false
+		}
+		delete(values, k)
+		return true
+	})
+}
+
+// Len returns the number of values.
+func (m *Map[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.values)
+}
+
+// Update calls change with the values, keyed by key(value), for changes that
+// depend on what is already stored. change reports whether it changed
+// anything; if so, the file is saved. No other call sees the values until
+// change returns.
+func (m *Map[K, V]) Update(change func(values map[K]V) bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !change(m.values) || m.path == "" {
+		return nil
+	}
+	if err := Save(m.path, m.sorted()); err != nil {
+		return fmt.Errorf("failed to save %s: %w", m.name, err)
+	}
+	return nil
+}
+
+// sorted returns the values in order. Caller must hold m.mu.
+func (m *Map[K, V]) sorted() []V {
+	all := make([]V, 0, len(m.values))
+	for _, value := range m.values {
+		all = append(all, value)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return m.less(all[i], all[j])
+	})
+	return all
+}
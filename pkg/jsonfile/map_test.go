@@ -0,0 +1,93 @@
+package jsonfile
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+type item struct {
+	ID   string `json:"id"`
+	Rank int    `json:"rank"`
+}
+
+func newItemMap(t *testing.T, path string) *Map[string, item] {
+	t.Helper()
+	m, err := NewMap(path, "items file", func(i item) string { return i.ID }, func(a, b item) bool { return a.Rank < b.Rank })
+	if err != nil {
+		t.Fatalf("NewMap() error = %v", err)
+	}
+	return m
+}
+
+func TestMap_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.json")
+	m := newItemMap(t, path)
+
+	for _, i := range []item{{"b", 2}, {"a", 1}, {"c", 3}} {
+		if err := m.Put(i); err != nil {
+			t.Fatalf("Put(%s) error = %v", i.ID, err)
+		}
+	}
+	m.Put(item{"a", 4}) // Replaces a
+	if err := m.Delete("c"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := m.Delete("unknown"); err != nil {
+		t.Errorf("Delete(unknown) error = %v", err)
+	}
+
+	reopened := newItemMap(t, path)
+	want := []item{{"b", 2}, {"a", 4}}
+	if got := reopened.Values(); !slices.Equal(got, want) {
+		t.Errorf("Values() = %v, want %v in order", got, want)
+	}
+	if got, ok := reopened.Get("a"); !ok || got.Rank != 4 {
+		t.Errorf("Get(a) = %v, %v, want the replaced value", got, ok)
+	}
+	if reopened.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", reopened.Len())
+	}
+}
+
+func TestMap_Update(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.json")
+	m := newItemMap(t, path)
+	m.Put(item{"a", 1})
+
+	// No change, no write
+	os.Remove(path)
+	if err := m.Update(func(map[string]item) bool { return false }); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Update without changes wrote the file")
+	}
+
+	if err := m.Update(func(values map[string]item) bool {
+		delete(values, "a")
+		values["b"] = item{"b", 2}
+		return true
+	}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got := newItemMap(t, path).Values(); !slices.Equal(got, []item{{"b", 2}}) {
+		t.Errorf("saved values = %v, want [b]", got)
+	}
+}
+
+func TestMap_MemoryOnly(t *testing.T) {
+	m := newItemMap(t, "")
+	if err := m.Put(item{"a", 1}); err != nil || m.Len() != 1 {
+		t.Errorf("Put() = %v, Len() = %d, want a stored in memory", err, m.Len())
+	}
+}
+
+func TestMap_LoadError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.json")
+	os.WriteFile(path, []byte("not json"), 0o600)
+	if _, err := NewMap(path, "items file", func(i item) string { return i.ID }, func(a, b item) bool { return false }); err == nil {
+		t.Error("NewMap() error = nil, want a parse error")
+	}
+}
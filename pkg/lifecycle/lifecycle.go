@@ -0,0 +1,240 @@
+// Package lifecycle starts and stops the bot's background components in
+// dependency order.
+//
+// Each component declares the components it depends on. Start runs them so
+// that dependencies start first; Stop runs them in reverse, so nothing is
+// stopped while a component that uses it is still running. Every stop is
+// bounded by a timeout: a component that hangs is logged and skipped rather
+// than blocking shutdown of the rest.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Component is a part of the bot with a start and stop step, e.g. a scheduler
+// or the HTTP server.
+type Component struct {
+	// Name identifies the component in DependsOn and logs.
+	Name string
+
+	// DependsOn lists the components that must be running while this one is.
+	DependsOn []string
+
+	// Start starts the component. It must not block; long-running work
+	// belongs in a goroutine. Nil means nothing to start.
+	Start func() error
+
+	// Stop stops the component, giving up when ctx is done. Nil means nothing to stop.
+	Stop func(ctx context.Context) error
+
+	// StopTimeout bounds Stop. Zero uses the manager's default.
+	StopTimeout time.Duration
+}
+
+// Manager starts and stops a set of components in dependency order.
+type Manager struct {
+	logger             *zap.Logger
+	defaultStopTimeout time.Duration
+
+	mu         sync.Mutex
+	components []Component
+	started    []Component // In start order
+}
+
+// NewManager creates a manager whose component stops are bounded by
+// defaultStopTimeout unless a component sets its own.
+func NewManager(logger *zap.Logger, defaultStopTimeout time.Duration) *Manager {
+	return &Manager{
+		logger:             logger,
+		defaultStopTimeout: defaultStopTimeout,
+	}
+}
+
+// Add registers a component. Components must be added before Start.
+func (m *Manager) Add(c Component) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, c)
+}
+
+// Start starts every component, dependencies first. Components with no
+// ordering constraint between them start in the order they were added.
+//
+// A missing dependency, a duplicate name or a dependency cycle is reported
+// before anything starts. If a component fails to start, the ones already
+// started are stopped in reverse and the error is returned.
+func (m *Manager) Start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	order, err := sortComponents(m.components)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range order {
+		if c.Start != nil {
+			if err := c.Start(); err != nil {
+				m.logger.Error("component failed to start", zap.String("component", c.Name), zap.Error(err))
+				m.stopStarted()
+				return fmt.Errorf("failed to start %s: %w", c.Name, err)
+			}
+		}
+		m.started = append(m.started, c)
+		m.logger.Info("component started", zap.String("component", c.Name))
+	}
+	return nil
+}
+
+// Stop stops the started components in reverse start order, each bounded by
+// its stop timeout. Errors and timeouts are logged and joined into the
+// returned error; shutdown always continues with the remaining components.
+// Stopping again is a no-op.
+func (m *Manager) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stopStarted()
+}
+
+// stopStarted stops m.started in reverse. Callers must hold m.mu.
+func (m *Manager) stopStarted() error {
+	var errs []error
+	for i := len(m.started) - 1; i >= 0; i-- {
+		if err := m.stopComponent(m.started[i]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.started[i].Name, err))
+		}
+	}
+	m.started = nil
+	return errors.Join(errs...)
+}
+
+// stopComponent runs one component's Stop with its timeout. A Stop that
+// doesn't return in time is abandoned (its goroutine keeps running) so a
+// stuck component can't deadlock shutdown.
+func (m *Manager) stopComponent(c Component) error {
+	if c.Stop == nil {
+		return nil
+	}
+	timeout := c.StopTimeout
+	if timeout <= 0 {
+		timeout = m.defaultStopTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			m.logger.Error("component failed to stop cleanly",
+				zap.String("component", c.Name),
+				zap.Duration("duration", time.Since(start)),
+				zap.Error(err),
+			)
+			return err
+		}
+		m.logger.Info("component stopped",
+			zap.String("component", c.Name),
+			zap.Duration("duration", time.Since(start)),
+		)
+		return nil
+	case <-ctx.Done():
+		m.logger.Error("component did not stop in time, continuing shutdown",
+			zap.String("component", c.Name),
+			zap.Duration("timeout", timeout),
+		)
+		return fmt.Errorf("stop timed out after %s", timeout)
+	}
+}
+
+// sortComponents orders components so each comes after its dependencies,
+// otherwise keeping the order they were added in.
+func sortComponents(components []Component) ([]Component, error) {
+	index := make(map[string]int, len(components))
+	for i, c := range components {
+		if c.Name == "" {
+			return nil, fmt.Errorf("component %d has no name", i)
+		}
+		if _, ok := index[c.Name]; ok {
+			return nil, fmt.Errorf("component %s is added more than once", c.Name)
+		}
+		index[c.Name] = i
+	}
+	for _, c := range components {
+		for _, dep := range c.DependsOn {
+			if _, ok := index[dep]; !ok {
+				return nil, fmt.Errorf("component %s depends on unknown component %s", c.Name, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(components))
+	order := make([]Component, 0, len(components))
+	var path []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s -> %s", strings.Join(path, " -> "), components[i].Name)
+		}
+		state[i] = visiting
+		path = append(path, components[i].Name)
+		for _, dep := range components[i].DependsOn {
+			if err := visit(index[dep]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		order = append(order, components[i])
+		return nil
+	}
+
+	for i := range components {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// StartFunc adapts a Start method that can't fail, like the schedulers'
+// Start, to a Component's Start.
+func StartFunc(fn func()) func() error {
+	return func() error {
+		fn()
+		return nil
+	}
+}
+
+// StopFunc adapts a Stop method that takes no context and can't fail to a
+// Component's Stop. The timeout still applies: if fn hangs, the manager
+// moves on without it.
+func StopFunc(fn func()) func(context.Context) error {
+	return func(context.Context) error {
+		fn()
+		return nil
+	}
+}
@@ -0,0 +1,191 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// recorder records start and stop calls across components.
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) component(name string, deps ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: deps,
+		Start: func() error {
+			r.events = append(r.events, "start "+name)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			r.events = append(r.events, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestManager_StartStopOrder(t *testing.T) {
+	rec := &recorder{}
+	m := NewManager(zap.NewNop(), time.Second)
+	m.Add(rec.component("server", "queue", "cache"))
+	m.Add(rec.component("queue", "analytics"))
+	m.Add(rec.component("cache"))
+	m.Add(rec.component("analytics"))
+
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := m.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	want := []string{
+		"start analytics", "start queue", "start cache", "start server",
+		"stop server", "stop cache", "stop queue", "stop analytics",
+	}
+	if !slices.Equal(rec.events, want) {
+		t.Errorf("events = %v, want %v", rec.events, want)
+	}
+
+	// Stopping again is a no-op
+	if err := m.Stop(); err != nil {
+		t.Errorf("second Stop() error = %v", err)
+	}
+	if len(rec.events) != len(want) {
+		t.Errorf("second Stop() ran components again: %v", rec.events[len(want):])
+	}
+}
+
+func TestManager_StartValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []Component
+		wantErr    string
+	}{
+		{
+			name:       "unknown dependency",
+			components: []Component{{Name: "queue", DependsOn: []string{"analytics"}}},
+			wantErr:    "depends on unknown component analytics",
+		},
+		{
+			name:       "duplicate name",
+			components: []Component{{Name: "cache"}, {Name: "cache"}},
+			wantErr:    "added more than once",
+		},
+		{
+			name:       "missing name",
+			components: []Component{{}},
+			wantErr:    "has no name",
+		},
+		{
+			name: "cycle",
+			components: []Component{
+				{Name: "a", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"c"}},
+				{Name: "c", DependsOn: []string{"a"}},
+			},
+			wantErr: "dependency cycle: a -> b -> c -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := false
+			m := NewManager(zap.NewNop(), time.Second)
+			for _, c := range tt.components {
+				c.Start = func() error {
+					started = true
+					return nil
+				}
+				m.Add(c)
+			}
+
+			err := m.Start()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Start() error = %v, want containing %q", err, tt.wantErr)
+			}
+			if started {
+				t.Error("Start() started components despite invalid configuration")
+			}
+		})
+	}
+}
+
+func TestManager_StartFailureStopsStarted(t *testing.T) {
+	rec := &recorder{}
+	m := NewManager(zap.NewNop(), time.Second)
+	m.Add(rec.component("cache"))
+	m.Add(rec.component("reminders"))
+	m.Add(Component{
+		Name:      "server",
+		DependsOn: []string{"cache", "reminders"},
+		Start:     func() error { return errors.New("port in use") },
+		Stop: func(context.Context) error {
+			t.Error("Stop() called on a component that failed to start")
+			return nil
+		},
+	})
+
+	err := m.Start()
+	if err == nil || !strings.Contains(err.Error(), "failed to start server: port in use") {
+		t.Fatalf("Start() error = %v", err)
+	}
+	want := []string{"start cache", "start reminders", "stop reminders", "stop cache"}
+	if !slices.Equal(rec.events, want) {
+		t.Errorf("events = %v, want %v", rec.events, want)
+	}
+}
+
+func TestManager_StopTimeout(t *testing.T) {
+	rec := &recorder{}
+	release := make(chan struct{})
+	defer close(release)
+
+	m := NewManager(zap.NewNop(), time.Second)
+	m.Add(rec.component("cache"))
+	m.Add(Component{
+		Name:        "queue",
+		DependsOn:   []string{"cache"},
+		Stop:        StopFunc(func() { <-release }), // Never returns on its own
+		StopTimeout: 20 * time.Millisecond,
+	})
+	m.Add(Component{
+		Name:      "server",
+		DependsOn: []string{"queue"},
+		Stop: func(context.Context) error {
+			return errors.New("connections still open")
+		},
+	})
+
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	err := m.Stop()
+	if err == nil {
+		t.Fatal("Stop() error = nil, want timeout and stop errors")
+	}
+	for _, want := range []string{"server: connections still open", "queue: stop timed out"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Stop() error = %v, want containing %q", err, want)
+		}
+	}
+	// A stuck component doesn't keep its dependencies running
+	if !slices.Contains(rec.events, "stop cache") {
+		t.Errorf("events = %v, want cache stopped after the queue timed out", rec.events)
+	}
+}
+
+func TestStartFunc(t *testing.T) {
+	called := false
+	if err := StartFunc(func() { called = true })(); err != nil || !called {
+		t.Errorf("StartFunc() called = %v, error = %v", called, err)
+	}
+}
@@ -0,0 +1,46 @@
+// Package logging carries a request-scoped *zap.Logger in a context, so code
+// working on behalf of a request logs with its fields (request_id, team_id,
+// user_id, ...) without every caller passing them along.
+//
+// middleware.WithRequestID stores a logger with the request ID; the Slack
+// handler adds the team and user once it has parsed them (With), and the
+// Notion client and cache manager log through FromContext. Scheduled jobs get a
+// logger with their job name the same way. Filtering the logs on one request_id
+// then shows everything done for that request, including retries deep in the
+// clients.
+//
+// Components keep their own logger as the fallback for work that isn't tied to
+// a request (startup, background loops).
+package logging
+
+import (
+	"context"
+
+	"github.com/rudderlabs/hopperbot/pkg/requestid"
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying logger.
+func NewContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger in ctx. Without one it returns fallback,
+// with a request_id field when ctx carries a request ID.
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		return fallback.With(zap.String("request_id", id))
+	}
+	return fallback
+}
+
+// With returns a copy of ctx whose logger has fields added, starting from
+// FromContext(ctx, fallback).
+func With(ctx context.Context, fallback *zap.Logger, fields ...zap.Field) context.Context {
+	return NewContext(ctx, FromContext(ctx, fallback).With(fields...))
+}
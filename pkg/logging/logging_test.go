@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/pkg/requestid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	fallback := zap.New(core)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want map[string]interface{}
+	}{
+		{
+			name: "no logger",
+			ctx:  context.Background(),
+			want: map[string]interface{}{},
+		},
+		{
+			name: "request ID only",
+			ctx:  requestid.NewContext(context.Background(), "req-1"),
+			want: map[string]interface{}{"request_id": "req-1"},
+		},
+		{
+			name: "stored logger",
+			ctx:  NewContext(context.Background(), fallback.With(zap.String("request_id", "req-2"))),
+			want: map[string]interface{}{"request_id": "req-2"},
+		},
+		{
+			name: "fields added with With",
+			ctx:  With(requestid.NewContext(context.Background(), "req-3"), fallback, zap.String("team_id", "T1"), zap.String("user_id", "U1")),
+			want: map[string]interface{}{"request_id": "req-3", "team_id": "T1", "user_id": "U1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			FromContext(tt.ctx, fallback).Info("message")
+
+			entries := logs.TakeAll()
+			if len(entries) != 1 {
+				t.Fatalf("logged %d entries, want 1", len(entries))
+			}
+			got := entries[0].ContextMap()
+			if len(got) != len(tt.want) {
+				t.Errorf("fields = %v, want %v", got, tt.want)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("%s = %v, want %v", key, got[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestWith_Accumulates(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	fallback := zap.New(core)
+
+	ctx := With(context.Background(), fallback, zap.String("team_id", "T1"))
+	ctx = With(ctx, zap.NewNop(), zap.String("user_id", "U1")) // The stored logger wins over the fallback
+	FromContext(ctx, zap.NewNop()).Info("message")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	if fields := entries[0].ContextMap(); fields["team_id"] != "T1" || fields["user_id"] != "U1" {
+		t.Errorf("fields = %v, want team_id and user_id", fields)
+	}
+}
@@ -0,0 +1,276 @@
+// Package manifest generates a Slack app manifest for Hopperbot.
+//
+// The manifest describes everything the Slack app needs to talk to this
+// deployment: the slash command, interactivity and options load URLs, event
+// subscriptions, and OAuth scopes. Generating it from the same route constants
+// the server registers keeps the Slack app configuration in sync with the
+// deployed endpoints, instead of hand-copying URLs into the Slack UI.
+//
+// Usage:
+//
+//	hopperbot manifest --base-url https://hopperbot.example.com > manifest.json
+//
+// The output can be pasted into "Create New App → From an app manifest" or
+// "App Manifest" in an existing app's settings.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+)
+
+// Bot events handled by Hopperbot.
+const (
+	// EventAppHomeOpened is sent when a user opens the app's Home tab.
+	EventAppHomeOpened = "app_home_opened"
+
+	// EventReactionAdded and EventReactionRemoved are sent for reactions in
+	// channels the bot is in; reactions on confirmations count as votes.
+	EventReactionAdded   = "reaction_added"
+	EventReactionRemoved = "reaction_removed"
+)
+
+// BotScopes lists the OAuth bot scopes Hopperbot requires.
+//
+// - commands: Register the /hopperbot slash command
+// - users:read: Look up the submitting user's profile (users.info)
+// - users:read.email: Read the user's email for Slack-to-Notion user mapping
+// - chat:write: DM submitters their follow-up reminders
+// - files:read: Look up canvas titles for the Artifacts field (files.info) and download attachments
+// - usergroups:read: Check SLACK_ADMIN_USERGROUP membership (usergroups.users.list)
+// - reactions:read: Receive reactions on confirmations as votes (VOTING_ENABLED)
+var BotScopes = []string{
+	"commands",
+	"chat:write",
+	"users:read",
+	"users:read.email",
+	"files:read",
+	"usergroups:read",
+	"reactions:read",
+}
+
+// Options configures manifest generation.
+type Options struct {
+	// BaseURL is the public HTTPS origin of the deployment (e.g., https://hopperbot.example.com).
+	BaseURL string
+
+	// BasePath is the route prefix when the deployment is served under a path
+	// behind a reverse proxy (BASE_PATH, e.g. /hopperbot). Empty for the root.
+	BasePath string
+
+	// AppName is the display name of the Slack app (default: "Hopperbot").
+	AppName string
+
+	// Description is the short app description shown in Slack.
+	Description string
+
+	// Command is the slash command to register (default: constants.SlashCommand).
+	Command string
+
+	// EventsPath is the Events API route; event subscriptions are omitted when empty.
+	EventsPath string
+
+	// BotEvents lists the bot events to subscribe to when EventsPath is set.
+	BotEvents []string
+}
+
+// Manifest is the Slack app manifest (JSON form).
+type Manifest struct {
+	DisplayInformation DisplayInformation `json:"display_information"`
+	Features           Features           `json:"features"`
+	OAuthConfig        OAuthConfig        `json:"oauth_config"`
+	Settings           Settings           `json:"settings"`
+}
+
+// DisplayInformation holds the app's name and description.
+type DisplayInformation struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Features holds App Home, bot user, shortcut and slash command configuration.
+type Features struct {
+	AppHome       *AppHome       `json:"app_home,omitempty"`
+	BotUser       BotUser        `json:"bot_user"`
+	Shortcuts     []Shortcut     `json:"shortcuts,omitempty"`
+	SlashCommands []SlashCommand `json:"slash_commands"`
+}
+
+// AppHome configures the app's Home and Messages tabs.
+type AppHome struct {
+	HomeTabEnabled             bool `json:"home_tab_enabled"`
+	MessagesTabEnabled         bool `json:"messages_tab_enabled"`
+	MessagesTabReadOnlyEnabled bool `json:"messages_tab_read_only_enabled"`
+}
+
+// BotUser configures the app's bot user.
+type BotUser struct {
+	DisplayName  string `json:"display_name"`
+	AlwaysOnline bool   `json:"always_online"`
+}
+
+// SlashCommand configures a slash command and where Slack sends it.
+type SlashCommand struct {
+	Command      string `json:"command"`
+	URL          string `json:"url"`
+	Description  string `json:"description"`
+	UsageHint    string `json:"usage_hint,omitempty"`
+	ShouldEscape bool   `json:"should_escape"`
+}
+
+// Shortcut configures a global or message shortcut. Slack sends its
+// interactions to the interactivity request URL.
+type Shortcut struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // "message" or "global"
+	CallbackID  string `json:"callback_id"`
+	Description string `json:"description"`
+}
+
+// OAuthConfig holds the requested OAuth scopes and the redirect URLs allowed
+// for the OAuth install flow.
+type OAuthConfig struct {
+	RedirectURLs []string `json:"redirect_urls,omitempty"`
+	Scopes       Scopes   `json:"scopes"`
+}
+
+// Scopes lists bot token scopes.
+type Scopes struct {
+	Bot []string `json:"bot"`
+}
+
+// Settings holds interactivity and event subscription settings.
+type Settings struct {
+	EventSubscriptions   *EventSubscriptions `json:"event_subscriptions,omitempty"`
+	Interactivity        Interactivity       `json:"interactivity"`
+	OrgDeployEnabled     bool                `json:"org_deploy_enabled"`
+	SocketModeEnabled    bool                `json:"socket_mode_enabled"`
+	TokenRotationEnabled bool                `json:"token_rotation_enabled"`
+}
+
+// EventSubscriptions configures the Events API request URL and subscribed events.
+type EventSubscriptions struct {
+	RequestURL string   `json:"request_url"`
+	BotEvents  []string `json:"bot_events"`
+}
+
+// Interactivity configures where Slack sends interactive payloads and options requests.
+//
+// MessageMenuOptionsURL is the "Options Load URL" required by external select menus
+// (the Customer Organization field). Without it, the modal fails with invalid_arguments.
+type Interactivity struct {
+	IsEnabled             bool   `json:"is_enabled"`
+	RequestURL            string `json:"request_url"`
+	MessageMenuOptionsURL string `json:"message_menu_options_url"`
+}
+
+// Build creates the manifest for the given options.
+//
+// Returns an error if the base URL is missing, not absolute, or not HTTPS
+// (Slack rejects non-HTTPS request URLs), or if the base path is not absolute.
+func Build(opts Options) (*Manifest, error) {
+	baseURL, err := normalizeBaseURL(opts.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	basePath := strings.TrimRight(opts.BasePath, "/")
+	if basePath != "" && !strings.HasPrefix(basePath, "/") {
+		return nil, fmt.Errorf("base path must start with /, got %q", opts.BasePath)
+	}
+	baseURL += basePath
+
+	if opts.AppName == "" {
+		opts.AppName = "Hopperbot"
+	}
+	if opts.Description == "" {
+		opts.Description = "Submit feature ideas and customer insights to Notion"
+	}
+	if opts.Command == "" {
+		opts.Command = constants.SlashCommand
+	}
+
+	manifest := &Manifest{
+		DisplayInformation: DisplayInformation{
+			Name:        opts.AppName,
+			Description: opts.Description,
+		},
+		Features: Features{
+			BotUser: BotUser{
+				DisplayName:  opts.AppName,
+				AlwaysOnline: true,
+			},
+			Shortcuts: []Shortcut{{
+				Name:        "Submit as idea",
+				Type:        "message",
+				CallbackID:  constants.ShortcutSubmitMessage,
+				Description: "Submit this message as an idea, linking back to it",
+			}},
+			SlashCommands: []SlashCommand{{
+				Command:      opts.Command,
+				URL:          baseURL + constants.RouteSlackCommand,
+				Description:  "Submit an idea to the Notion ideas database",
+				UsageHint:    "[refresh-cache]",
+				ShouldEscape: false,
+			}},
+		},
+		OAuthConfig: OAuthConfig{
+			RedirectURLs: []string{baseURL + constants.RouteSlackOAuthCallback},
+			Scopes:       Scopes{Bot: append([]string(nil), BotScopes...)},
+		},
+		Settings: Settings{
+			Interactivity: Interactivity{
+				IsEnabled:             true,
+				RequestURL:            baseURL + constants.RouteSlackInteractive,
+				MessageMenuOptionsURL: baseURL + constants.RouteSlackOptions,
+			},
+		},
+	}
+
+	if opts.EventsPath != "" {
+		manifest.Settings.EventSubscriptions = &EventSubscriptions{
+			RequestURL: baseURL + opts.EventsPath,
+			BotEvents:  append([]string(nil), opts.BotEvents...),
+		}
+
+		// The Home tab is only rendered when the app receives app_home_opened
+		if slices.Contains(opts.BotEvents, EventAppHomeOpened) {
+			manifest.Features.AppHome = &AppHome{
+				HomeTabEnabled:             true,
+				MessagesTabEnabled:         true, // Confirmations and reminders are DMed
+				MessagesTabReadOnlyEnabled: true,
+			}
+		}
+	}
+
+	return manifest, nil
+}
+
+// JSON renders the manifest as indented JSON ready to paste into Slack.
+func (m *Manifest) JSON() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// normalizeBaseURL validates the base URL and strips any trailing slash.
+func normalizeBaseURL(raw string) (string, error) {
+	if raw == "" {
+		return "", fmt.Errorf("base URL is required (set --base-url or PUBLIC_BASE_URL)")
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL: %w", err)
+	}
+	if parsed.Scheme != "https" {
+		return "", fmt.Errorf("base URL must use https (Slack requires HTTPS request URLs), got %q", raw)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("base URL must include a host, got %q", raw)
+	}
+
+	return strings.TrimRight(raw, "/"), nil
+}
@@ -0,0 +1,166 @@
+package manifest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+)
+
+// TestBuild tests manifest generation with default options
+func TestBuild(t *testing.T) {
+	manifest, err := Build(Options{BaseURL: "https://hopperbot.example.com/"})
+	if err != nil {
+		t.Fatalf("Build() returned unexpected error: %v", err)
+	}
+
+	if manifest.DisplayInformation.Name != "Hopperbot" {
+		t.Errorf("Name = %q, want Hopperbot", manifest.DisplayInformation.Name)
+	}
+
+	if len(manifest.Features.SlashCommands) != 1 {
+		t.Fatalf("expected 1 slash command, got %d", len(manifest.Features.SlashCommands))
+	}
+	command := manifest.Features.SlashCommands[0]
+	if command.Command != constants.SlashCommand {
+		t.Errorf("Command = %q, want %q", command.Command, constants.SlashCommand)
+	}
+	if command.URL != "https://hopperbot.example.com"+constants.RouteSlackCommand {
+		t.Errorf("command URL = %q", command.URL)
+	}
+
+	shortcuts := manifest.Features.Shortcuts
+	if len(shortcuts) != 1 || shortcuts[0].Type != "message" || shortcuts[0].CallbackID != constants.ShortcutSubmitMessage {
+		t.Errorf("shortcuts = %+v, want the message shortcut", shortcuts)
+	}
+
+	interactivity := manifest.Settings.Interactivity
+	if !interactivity.IsEnabled {
+		t.Error("interactivity should be enabled")
+	}
+	if interactivity.RequestURL != "https://hopperbot.example.com"+constants.RouteSlackInteractive {
+		t.Errorf("interactivity URL = %q", interactivity.RequestURL)
+	}
+	if interactivity.MessageMenuOptionsURL != "https://hopperbot.example.com"+constants.RouteSlackOptions {
+		t.Errorf("options URL = %q", interactivity.MessageMenuOptionsURL)
+	}
+
+	redirects := manifest.OAuthConfig.RedirectURLs
+	if len(redirects) != 1 || redirects[0] != "https://hopperbot.example.com"+constants.RouteSlackOAuthCallback {
+		t.Errorf("redirect URLs = %v", redirects)
+	}
+
+	if manifest.Settings.EventSubscriptions != nil {
+		t.Error("event subscriptions should be omitted when EventsPath is empty")
+	}
+	if manifest.Features.AppHome != nil {
+		t.Error("app home should be omitted without the app_home_opened event")
+	}
+}
+
+// TestBuild_BasePath tests that every URL includes the base path behind a reverse proxy
+func TestBuild_BasePath(t *testing.T) {
+	manifest, err := Build(Options{
+		BaseURL:    "https://tools.example.com",
+		BasePath:   "/hopperbot/",
+		EventsPath: constants.RouteSlackEvents,
+	})
+	if err != nil {
+		t.Fatalf("Build() returned unexpected error: %v", err)
+	}
+
+	const prefix = "https://tools.example.com/hopperbot"
+	urls := map[string]string{
+		"command":       manifest.Features.SlashCommands[0].URL,
+		"interactivity": manifest.Settings.Interactivity.RequestURL,
+		"options":       manifest.Settings.Interactivity.MessageMenuOptionsURL,
+		"redirect":      manifest.OAuthConfig.RedirectURLs[0],
+		"events":        manifest.Settings.EventSubscriptions.RequestURL,
+	}
+	for name, url := range urls {
+		if !strings.HasPrefix(url, prefix+"/slack/") {
+			t.Errorf("%s URL = %q, want under %s", name, url, prefix)
+		}
+	}
+}
+
+// TestBuild_EventSubscriptions tests that event subscriptions are included when configured
+func TestBuild_EventSubscriptions(t *testing.T) {
+	manifest, err := Build(Options{
+		BaseURL:    "https://hopperbot.example.com",
+		EventsPath: "/slack/events",
+		BotEvents:  []string{"app_home_opened"},
+	})
+	if err != nil {
+		t.Fatalf("Build() returned unexpected error: %v", err)
+	}
+
+	events := manifest.Settings.EventSubscriptions
+	if events == nil {
+		t.Fatal("expected event subscriptions")
+	}
+	if events.RequestURL != "https://hopperbot.example.com/slack/events" {
+		t.Errorf("events URL = %q", events.RequestURL)
+	}
+	if len(events.BotEvents) != 1 || events.BotEvents[0] != "app_home_opened" {
+		t.Errorf("BotEvents = %v", events.BotEvents)
+	}
+	if manifest.Features.AppHome == nil || !manifest.Features.AppHome.HomeTabEnabled {
+		t.Errorf("AppHome = %+v, want the Home tab enabled", manifest.Features.AppHome)
+	}
+}
+
+// TestBuild_InvalidBaseURL tests base URL validation
+func TestBuild_InvalidBaseURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseURL     string
+		basePath    string
+		errContains string
+	}{
+		{name: "empty", baseURL: "", errContains: "required"},
+		{name: "http scheme", baseURL: "http://hopperbot.example.com", errContains: "https"},
+		{name: "missing host", baseURL: "https://", errContains: "host"},
+		{name: "relative base path", baseURL: "https://tools.example.com", basePath: "hopperbot", errContains: "base path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Build(Options{BaseURL: tt.baseURL, BasePath: tt.basePath})
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("error %q should contain %q", err.Error(), tt.errContains)
+			}
+		})
+	}
+}
+
+// TestManifest_JSON verifies the rendered JSON uses Slack's manifest field names
+func TestManifest_JSON(t *testing.T) {
+	manifest, err := Build(Options{BaseURL: "https://hopperbot.example.com"})
+	if err != nil {
+		t.Fatalf("Build() returned unexpected error: %v", err)
+	}
+
+	data, err := manifest.JSON()
+	if err != nil {
+		t.Fatalf("JSON() returned unexpected error: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+
+	for _, key := range []string{"display_information", "features", "oauth_config", "settings"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("manifest JSON missing %q", key)
+		}
+	}
+	if strings.Contains(string(data), "event_subscriptions") {
+		t.Error("event_subscriptions should be omitted")
+	}
+}
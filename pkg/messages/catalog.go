@@ -0,0 +1,555 @@
+// Package messages provides the catalog of user-facing Slack messages.
+//
+// Every string the bot shows to a Slack user (modal field errors, ephemeral
+// command replies) is looked up here by key instead of being hard-coded in
+// handlers. Deployments can override any message through a JSON file to adapt
+// tone, add internal support links, or translate, without code changes.
+//
+// Messages may contain named placeholders in braces, e.g. "{email}" or "{max}",
+// which are substituted from Params at format time. Unknown placeholders are
+// left as-is so a typo in an override is visible rather than silently dropped.
+//
+// Example override file (MESSAGES_FILE):
+//
+//	{
+//	  "user_not_found": "We couldn't find {email} in Notion. Ask in #help-hopperbot.",
+//	  "submit_failed": "Notion didn't accept the idea ({error}). Try again in a minute."
+//	}
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Key identifies a message in the catalog.
+type Key string
+
+// Message keys for slash command responses.
+const (
+	KeyMissingTriggerID Key = "missing_trigger_id"
+	KeyOpenModalFailed  Key = "open_modal_failed"
+	KeyRateLimited      Key = "rate_limited"
+)
+
+// Message keys for customer select options.
+const (
+	KeyOptionsMoreResults       Key = "options_more_results"
+	KeyStaticCustomersTruncated Key = "static_customers_truncated"
+)
+
+// Message keys for the /hopperbot refresh-cache command.
+const (
+	KeyCacheRefreshDone       Key = "cache_refresh_done"
+	KeyCacheRefreshFailed     Key = "cache_refresh_failed"
+	KeyCacheRefreshLine       Key = "cache_refresh_line"
+	KeyCacheRefreshLineFailed Key = "cache_refresh_line_failed"
+	KeyCacheRefreshNoReport   Key = "cache_refresh_no_report"
+	KeyCacheRefreshInProgress Key = "cache_refresh_in_progress"
+)
+
+// Message keys for subcommand routing.
+const (
+	KeyUnknownSubcommand           Key = "unknown_subcommand"
+	KeyUnknownSubcommandSuggestion Key = "unknown_subcommand_suggestion"
+	KeySubcommandUsage             Key = "subcommand_usage"
+)
+
+// Message keys for admin subcommands (e.g. /hopperbot refresh-cache).
+const (
+	KeyAdminCommandDenied    Key = "admin_command_denied"
+	KeyQuotaReset            Key = "quota_reset"
+	KeyQuotaResetInvalidUser Key = "quota_reset_invalid_user"
+)
+
+// Message keys for /hopperbot list and /hopperbot stats.
+const (
+	KeyListNoIdeas      Key = "list_no_ideas"
+	KeyListLookupFailed Key = "list_lookup_failed"
+	KeyListIdeaLine     Key = "list_idea_line"
+	KeyStats            Key = "stats"
+	KeyAuditStats       Key = "audit_stats"
+)
+
+// Message keys for /hopperbot help.
+const (
+	KeyHelpIntro            Key = "help_intro"
+	KeyHelpCommandLine      Key = "help_command_line"
+	KeyHelpAdminCommandLine Key = "help_admin_command_line"
+	KeyHelpRequiredFields   Key = "help_required_fields"
+	KeyHelpConditionalField Key = "help_conditional_field"
+	KeyHelpBoard            Key = "help_board"
+)
+
+// Message keys for the /hopperbot customer command.
+const (
+	KeyCustomerUsage        Key = "customer_usage"
+	KeyCustomerNotFound     Key = "customer_not_found"
+	KeyCustomerLookupFailed Key = "customer_lookup_failed"
+	KeyCustomerIdeas        Key = "customer_ideas"
+	KeyCustomerIdeaLine     Key = "customer_idea_line"
+	KeyCustomerNoIdeas      Key = "customer_no_ideas"
+)
+
+// Message keys for submission errors shown on the modal.
+const (
+	KeyUserLookupFailed        Key = "user_lookup_failed"
+	KeyUserNotFound            Key = "user_not_found"
+	KeyUserExternalGuest       Key = "user_external_guest"
+	KeySlackGuestRejected      Key = "slack_guest_rejected"
+	KeyHiddenEmailRejected     Key = "hidden_email_rejected"
+	KeySubmitFailed            Key = "submit_failed"
+	KeySubmitRateLimited       Key = "submit_rate_limited"
+	KeySubmitNoAccess          Key = "submit_no_access"
+	KeySubmitRejected          Key = "submit_rejected"
+	KeySubmitUnavailable       Key = "submit_unavailable"
+	KeySubmitQueuedUnavailable Key = "submit_queued_unavailable"
+	KeySubmissionsPaused       Key = "submissions_paused"
+	KeyDuplicateSubmission     Key = "duplicate_submission"
+	KeySubmissionCooldown      Key = "submission_cooldown"
+	KeySubmissionQuotaReached  Key = "submission_quota_reached"
+	KeyErrorReference          Key = "error_reference"
+)
+
+// Message keys for quick submissions (/hopperbot "Title" theme:… area:…).
+const (
+	KeyQuickSubmitAccepted   Key = "quick_submit_accepted"
+	KeyQuickSubmitInvalid    Key = "quick_submit_invalid"
+	KeyQuickSubmitFormOpened Key = "quick_submit_form_opened"
+)
+
+// Message keys for the second step of the multi-step submission modal.
+const (
+	KeyStepTwoSummary  Key = "step_two_summary"
+	KeyStepTwoRequired Key = "step_two_required"
+)
+
+// Message keys for field validation errors shown on the modal.
+const (
+	KeyFieldExtractFailed Key = "field_extract_failed"
+	KeyFieldRequired      Key = "field_required"
+	KeyFieldRequiredWhen  Key = "field_required_when"
+	KeyFieldTooLong       Key = "field_too_long"
+	KeyInvalidSelection   Key = "invalid_selection"
+	KeyTooManySelections  Key = "too_many_selections"
+	KeyInvalidCustomerOrg Key = "invalid_customer_org"
+	KeyFormOutdated       Key = "form_outdated"
+	KeyPersonNotFound     Key = "person_not_found"
+	KeyInvalidArtifact    Key = "invalid_artifact"
+	KeyTooManyArtifacts   Key = "too_many_artifacts"
+	KeyTooManyAttachments Key = "too_many_attachments"
+	KeyAttachmentTooLarge Key = "attachment_too_large"
+	KeyAttachmentType     Key = "attachment_type"
+)
+
+// Message keys for follow-up reminder DMs.
+const (
+	KeyReminder         Key = "reminder"
+	KeyReminderRemoved  Key = "reminder_removed"
+	KeyReminderNoStatus Key = "reminder_no_status"
+)
+
+// Message keys for status change DMs.
+const (
+	KeyStatusChanged Key = "status_changed"
+)
+
+// Message keys for the weekly digest of submissions.
+const (
+	KeyDigest              Key = "digest"
+	KeyDigestEmpty         Key = "digest_empty"
+	KeyDigestByTheme       Key = "digest_by_theme"
+	KeyDigestByProductArea Key = "digest_by_product_area"
+	KeyDigestGroup         Key = "digest_group"
+	KeyDigestLine          Key = "digest_line"
+	KeyDigestMore          Key = "digest_more"
+	KeyDigestUnset         Key = "digest_unset"
+)
+
+// Message keys for the App Home tab.
+const (
+	KeyHomeIdeas        Key = "home_ideas"
+	KeyHomeIdeaLine     Key = "home_idea_line"
+	KeyHomeNoIdeas      Key = "home_no_ideas"
+	KeyHomeUserNotFound Key = "home_user_not_found"
+	KeyHomeLookupFailed Key = "home_lookup_failed"
+)
+
+// Message keys for the /hopperbot edit flow.
+const (
+	KeyEditNoIdeas      Key = "edit_no_ideas"
+	KeyEditUserNotFound Key = "edit_user_not_found"
+	KeyEditLookupFailed Key = "edit_lookup_failed"
+	KeyEditLoadFailed   Key = "edit_load_failed"
+	KeyEditNotOwner     Key = "edit_not_owner"
+	KeyEditIdeaRemoved  Key = "edit_idea_removed"
+	KeyUpdateFailed     Key = "update_failed"
+)
+
+// Message keys for messages posted after a submission.
+const (
+	KeySubmissionConfirmation   Key = "submission_confirmation"
+	KeyConfirmationBatch        Key = "confirmation_batch"
+	KeyConfirmationBatchLine    Key = "confirmation_batch_line"
+	KeyConfirmationBatchMore    Key = "confirmation_batch_more"
+	KeyQueuedSubmissionFailed   Key = "queued_submission_failed"
+	KeyQueuedSubmissionRejected Key = "queued_submission_rejected"
+	KeySubmissionTriaged        Key = "submission_triaged"
+	KeyTriageIdea               Key = "triage_idea"
+	KeyVoteHint                 Key = "vote_hint"
+	KeyCopiesSaved              Key = "copies_saved"
+	KeyCopiesRetrying           Key = "copies_retrying"
+)
+
+// Message keys for drafts shared between teammates.
+const (
+	KeyDraftShared      Key = "draft_shared"
+	KeyDraftShareSent   Key = "draft_share_sent"
+	KeyDraftShareSelf   Key = "draft_share_self"
+	KeyDraftShareFailed Key = "draft_share_failed"
+	KeyDraftExpired     Key = "draft_expired"
+	KeyDraftNotShared   Key = "draft_not_shared"
+	KeyDraftUntitled    Key = "draft_untitled"
+	KeyAutosaveRestored Key = "autosave_restored"
+)
+
+// Params supplies placeholder values for a message.
+type Params map[string]interface{}
+
+// defaultMessages contains the built-in English messages.
+//
+// Placeholders used by each message are documented inline; overrides should
+// use the same placeholder names.
+var defaultMessages = map[Key]string{
+	KeyMissingTriggerID: "Internal error: missing trigger_id",
+	KeyOpenModalFailed:  "Failed to open submission form. Please try again.",
+	// A user is sending requests faster than RATE_LIMIT_PER_MINUTE allows; {seconds} until they can retry
+	KeyRateLimited: "You're doing that too often. Please wait {seconds} seconds and try again.",
+
+	// Last option when a customer search has more matches than fit (max 75 characters)
+	KeyOptionsMoreResults: "… more results, keep typing",
+	// Last option of a static customer select that can't list every customer (max 75 characters); {count}
+	KeyStaticCustomersTruncated: "… {count} more: name them in the title or comments",
+
+	// {command}, {subcommand}
+	KeyUnknownSubcommand: "Unknown command `{command} {subcommand}`. Try `{command} help`.",
+	// {command}, {subcommand}, {suggestion}
+	KeyUnknownSubcommandSuggestion: "Unknown command `{command} {subcommand}`. Did you mean `{command} {suggestion}`?",
+	// {usage} (e.g. "/hopperbot customer <customer name>")
+	KeySubcommandUsage: "Usage: {usage}",
+
+	// {subcommand}
+	KeyAdminCommandDenied: "Sorry, `/hopperbot {subcommand}` is limited to Hopperbot admins.",
+	// {user} (a Slack user ID), {count}: submissions cleared from the last 24 hours
+	KeyQuotaReset: "Cleared {count} recent submissions from <@{user}>'s quota, so they can submit new ideas again.",
+	// {value} as typed
+	KeyQuotaResetInvalidUser: "`{value}` isn't a Slack user. Mention them, e.g. `/hopperbot reset-quota @jane`.",
+
+	KeyHelpIntro: "Hopperbot files product ideas in the Notion ideas board. Commands:",
+	// {usage} (e.g. "/hopperbot customer <customer name>"), {description}
+	KeyHelpCommandLine: "• `{usage}`: {description}",
+	// {usage}, {description}; shown when admin subcommands are restricted
+	KeyHelpAdminCommandLine: "• `{usage}`: {description} _(admins only)_",
+	// {fields}: comma-separated labels of the form's required fields
+	KeyHelpRequiredFields: "*Required fields:* {fields}",
+	// {field}, {hint} (e.g. "Required for Customer Pain Point")
+	KeyHelpConditionalField: "*{field}:* {hint}",
+	// {url}
+	KeyHelpBoard: "<{url}|Open the ideas board in Notion>",
+
+	KeyListNoIdeas:      "You haven't submitted any ideas yet. Use /hopperbot to share one.",
+	KeyListLookupFailed: "Failed to load your ideas from Notion. Please try again.",
+	// {title}, {url}, {submitted}; ideas listed from the audit log, which has no status
+	KeyListIdeaLine: "• <{url}|{title}> (submitted {submitted})",
+	// {version}, {built}, {customers}, {users}, {guests}
+	KeyStats: "Cache version {version}, built {built}: {customers} customers, {users} users ({guests} guests excluded).",
+	// {days}, {created}, {edited}, {submitters}, {failed}, {latency} (e.g. "850ms")
+	KeyAuditStats: "Last {days} days: {created} ideas created and {edited} edited by {submitters} people, {failed} failed attempts, {latency} average backend latency.",
+
+	// {duration}
+	KeyCacheRefreshDone: ":white_check_mark: Caches refreshed in {duration}:",
+	// {duration}, {failed}, {total}
+	KeyCacheRefreshFailed: ":warning: Cache refresh finished in {duration}, but {failed} of {total} caches failed (the previous data is kept):",
+	// {cache}, {duration}
+	KeyCacheRefreshLine: "• {cache}: refreshed in {duration}",
+	// {cache}, {duration}, {error}
+	KeyCacheRefreshLineFailed: "• {cache}: failed after {duration} ({error})",
+	// {error}; the refresh may still complete in the background
+	KeyCacheRefreshNoReport: "The cache refresh outcome is unavailable ({error}). Check the logs for details.",
+	// Posted once a refresh has run for constants.ManualRefreshProgressDelay
+	KeyCacheRefreshInProgress: ":hourglass_flowing_sand: Still refreshing caches; the outcome will be posted here when it's done.",
+
+	KeyCustomerUsage: "Usage: /hopperbot customer <customer name>",
+	// {name}
+	KeyCustomerNotFound: "No customer named \"{name}\" was found in Notion.",
+	// {customer}
+	KeyCustomerLookupFailed: "Failed to look up ideas for {customer}. Please try again.",
+	// {customer}, {count}, {shown}
+	KeyCustomerIdeas: "*{count}* ideas linked to *{customer}*. Most recent {shown}:",
+	// {title}, {url}, {status}, {submitted}
+	KeyCustomerIdeaLine: "• <{url}|{title}> – {status} (submitted {submitted})",
+	// {customer}
+	KeyCustomerNoIdeas: "No ideas are linked to *{customer}* yet.",
+
+	KeyUserLookupFailed: "Failed to identify user. Please try again.",
+	// {email}
+	KeyUserNotFound: "Your Slack email ({email}) is not associated with a Notion account in this workspace. Please contact your administrator.",
+	// {email}
+	KeyUserExternalGuest: "Your Notion account ({email}) is an external guest, so ideas can't be attributed to it. Please contact your administrator.",
+	// Slack guest under SLACK_GUEST_POLICY=reject (or triage, where the idea can't be triaged, e.g. edits)
+	KeySlackGuestRejected: "Guest accounts can't submit ideas. Please ask someone on the team to submit it for you.",
+	// Slack profile without a visible email under HIDDEN_EMAIL_POLICY=reject (or triage, as above)
+	KeyHiddenEmailRejected: "Your Slack profile doesn't show your email address, so your idea can't be attributed to a Notion account. Please contact your administrator.",
+	// {error}
+	KeySubmitFailed: "Failed to submit: {error}",
+	// Notion is rate limiting the integration
+	KeySubmitRateLimited: "Notion is busy right now, so your idea wasn't saved. Please try again in a minute.",
+	// The integration lost access to the ideas database
+	KeySubmitNoAccess: "Hopperbot doesn't have access to the ideas database in Notion. Please contact your administrator.",
+	// {error} is Notion's explanation of the invalid value
+	KeySubmitRejected: "Notion rejected the idea: {error}",
+	// Notion has been failing, so requests fail fast (circuit breaker open) and there is no queue to retry from
+	KeySubmitUnavailable: "Notion is unavailable right now, so your idea wasn't saved. Please try again in a few minutes.",
+	// Same, with the submission queued instead; {title}
+	KeySubmitQueuedUnavailable: "Notion is unavailable right now, so your submission *{title}* was queued. It will be added automatically once Notion is back, and you'll get a message if it can't be.",
+	// Shown while the ideas database is missing required properties
+	KeySubmissionsPaused: "The ideas database is being reconfigured, so new ideas can't be saved right now. Please try again in a few minutes.",
+	// The same user submitted the same fields moments ago; {submitted}, {url}
+	KeyDuplicateSubmission: "You already submitted this idea at {submitted} ({url}). Use /hopperbot edit to change it.",
+	// SUBMISSION_COOLDOWN hasn't passed since the user's last new idea; {seconds} left
+	KeySubmissionCooldown: "You just submitted an idea. Please wait {seconds} seconds before submitting another.",
+	// The user reached SUBMISSION_QUOTA_PER_DAY; {limit}, {next}: when the oldest counted submission expires
+	KeySubmissionQuotaReached: "You've submitted {limit} ideas in the last 24 hours, the most allowed. You can submit again at {next}, or ask a Hopperbot admin to reset your quota.",
+	// Appended to errors so support can find the request in the logs; {request_id}
+	KeyErrorReference: "(Reference: {request_id})",
+
+	// {title}
+	KeyQuickSubmitAccepted: "Submitting *{title}*…",
+	// {errors}: parse or validation errors, separated by semicolons
+	KeyQuickSubmitInvalid: "Your idea wasn't submitted: {errors}",
+	// Follows KeyQuickSubmitInvalid when the pre-filled form could be opened
+	KeyQuickSubmitFormOpened: "The form is open with what you typed, so you can fix it there.",
+
+	// {title}, {theme}: entered in the first step
+	KeyStepTwoSummary: "*{title}* · {theme}",
+	// {theme}, {fields}: labels of the fields the theme makes required
+	KeyStepTwoRequired: "{theme} ideas also need: {fields}",
+
+	// {field}, {error}
+	KeyFieldExtractFailed: "Failed to extract {field}: {error}",
+	// {field}
+	KeyFieldRequired: "{field} is required",
+	// {field}, {when}, {value}
+	KeyFieldRequiredWhen: "{field} is required when {when} is \"{value}\"",
+	// {field}, {max}, {current}
+	KeyFieldTooLong: "{field} exceeds maximum length of {max} characters (current: {current})",
+	// {field}, {value}
+	KeyInvalidSelection: "Invalid {field} selected: {value}",
+	// {max}, {selected}
+	KeyTooManySelections: "Too many customer orgs selected (max: {max}, selected: {selected})",
+	// {value}
+	KeyInvalidCustomerOrg: "Invalid customer org selected: {value}",
+	KeyFormOutdated:       "This form is out of date. Please close it and run /hopperbot again to open the latest version.",
+	// {user}
+	KeyPersonNotFound: "{user} is not associated with a Notion account in this workspace.",
+	// {value}
+	KeyInvalidArtifact: "{value} is not a Slack canvas or huddle link",
+	// {max}, {count}
+	KeyTooManyArtifacts: "Too many links (max: {max}, added: {count})",
+	// {max}, {count}
+	KeyTooManyAttachments: "Too many files (max: {max}, added: {count})",
+	// {name}, {max}: size limit in MB
+	KeyAttachmentTooLarge: "{name} is larger than {max} MB",
+	// {name}, {types}: accepted file extensions
+	KeyAttachmentType: "{name} can't be attached (accepted: {types})",
+
+	// {title}, {submitted}, {status}, {url}
+	KeyReminder: ":alarm_clock: Following up on your idea *{title}* (submitted {submitted}): its status is *{status}*. <{url}|Open in Notion>",
+	// {title}, {submitted}
+	KeyReminderRemoved: ":alarm_clock: Following up on your idea *{title}* (submitted {submitted}): it has been removed from Notion.",
+	// Substituted for {status} in KeyReminder, KeyCustomerIdeaLine and KeyHomeIdeaLine when the idea has no status yet
+	KeyReminderNoStatus: "not triaged yet",
+
+	// {title}, {url}, {status}, {previous} (KeyReminderNoStatus when the idea had no status)
+	KeyStatusChanged: ":arrows_counterclockwise: Your idea *<{url}|{title}>* moved to *{status}*.",
+
+	// Digest posted to DIGEST_CHANNEL; {count}, {from}, {to}
+	KeyDigest: ":bar_chart: *Idea digest*: *{count}* ideas were submitted from {from} to {to}.",
+	// {from}, {to}
+	KeyDigestEmpty:         ":bar_chart: *Idea digest*: no ideas were submitted from {from} to {to}.",
+	KeyDigestByTheme:       "*By theme*",
+	KeyDigestByProductArea: "*By product area*",
+	// Theme or product area heading; {name}, {count}
+	KeyDigestGroup: "• {name}: *{count}*",
+	// Idea under its product area; {title}, {url}, {status} (KeyReminderNoStatus when the idea has no status)
+	KeyDigestLine: "    • <{url}|{title}> ({status})",
+	// {count}
+	KeyDigestMore: "    …and {count} more",
+	// Group name for ideas without a theme or product area
+	KeyDigestUnset: "Not set",
+
+	// {count}, {shown}
+	KeyHomeIdeas: "You have submitted *{count}* ideas. Most recent {shown}:",
+	// {title}, {url}, {status}, {submitted}
+	KeyHomeIdeaLine: "• <{url}|{title}> – {status} (submitted {submitted})",
+	KeyHomeNoIdeas:  "You haven't submitted any ideas yet. Use the button above or /hopperbot to share one.",
+	// {email}
+	KeyHomeUserNotFound: "Your Slack email ({email}) is not associated with a Notion account, so your submissions can't be listed.",
+	KeyHomeLookupFailed: "Failed to load your ideas from Notion. Use Refresh to try again.",
+
+	KeyEditNoIdeas: "You haven't submitted any ideas yet, so there's nothing to edit. Use /hopperbot to share one.",
+	// {email}
+	KeyEditUserNotFound: "Your Slack email ({email}) is not associated with a Notion account, so your submissions can't be edited.",
+	KeyEditLookupFailed: "Failed to load your ideas from Notion. Please try again.",
+	// {error}
+	KeyEditLoadFailed:  "Failed to load this idea from Notion: {error}",
+	KeyEditNotOwner:    "You can only edit ideas you submitted.",
+	KeyEditIdeaRemoved: "This idea has been removed from Notion.",
+	// {error}
+	KeyUpdateFailed: "Failed to update: {error}",
+
+	// {title}, {url}
+	KeySubmissionConfirmation: ":white_check_mark: Idea *<{url}|{title}>* has been added to Notion.",
+	// Summary of confirmations batched during a burst; {count}, {minutes}. Details are threaded
+	KeyConfirmationBatch: ":white_check_mark: Ideas added to Notion in the last {minutes} minutes: *{count}* (details in thread)",
+	// {title}, {url}
+	KeyConfirmationBatchLine: "• <{url}|{title}>",
+	// {count}
+	KeyConfirmationBatchMore: "…and {count} more",
+	// {title}, {error}
+	KeyQueuedSubmissionFailed: ":x: Sorry, your idea *{title}* couldn't be added to Notion ({error}). Please submit it again with /hopperbot.",
+	// Queued submission whose submitter couldn't be mapped to a Notion user; {title}, {reason}
+	KeyQueuedSubmissionRejected: ":x: Your idea *{title}* wasn't submitted: {reason}",
+	// Told to a submitter whose idea was posted to GUEST_TRIAGE_CHANNEL instead of Notion; {title}
+	KeySubmissionTriaged: ":inbox_tray: Thanks! Your idea *{title}* was sent to the team for review.",
+	// Headline of an idea posted to GUEST_TRIAGE_CHANNEL; {user} (a mention), {title}
+	KeyTriageIdea: ":inbox_tray: {user} submitted *{title}* for triage. Add it to Notion if it should be tracked.",
+	// Context line under confirmations that count reactions as votes; {reactions} (e.g. ":+1:")
+	KeyVoteHint: "React with {reactions} to vote for this idea",
+	// Context line under confirmations when FANOUT_BACKENDS is set; {targets} (e.g. "postgres")
+	KeyCopiesSaved: "Also saved to {targets}",
+	// Context line under confirmations when a secondary backend failed; {targets}
+	KeyCopiesRetrying: ":warning: Couldn't save a copy to {targets} yet; retrying automatically",
+
+	// DM to the teammate a draft was shared with; {author} (Slack user ID), {title}, {expires}
+	KeyDraftShared: ":memo: <@{author}> shared an idea draft with you: *{title}*. Open it to review and submit it before {expires}.",
+	// DM to the author; {recipient} (Slack user ID), {title}, {expires}
+	KeyDraftShareSent:   ":memo: Your draft *{title}* was shared with <@{recipient}>. Either of you can open it before {expires}.",
+	KeyDraftShareSelf:   "Pick a teammate to share your draft with.",
+	KeyDraftShareFailed: "Sorry, your draft couldn't be shared. Please try again.",
+	KeyDraftExpired:     "This draft has expired or has already been submitted.",
+	KeyDraftNotShared:   "This draft wasn't shared with you.",
+	// Substituted for {title} in KeyDraftShared and KeyDraftShareSent when the draft has no title yet
+	KeyDraftUntitled: "Untitled idea",
+	// {saved}: when the modal was closed, in the user's timezone
+	KeyAutosaveRestored: ":floppy_disk: Restored what you entered before closing the form on {saved}.",
+}
+
+// Catalog resolves message keys to user-facing text.
+//
+// A Catalog is immutable after construction and safe for concurrent use.
+// A nil *Catalog behaves like the default catalog.
+type Catalog struct {
+	messages map[Key]string
+}
+
+// Default returns a catalog containing only the built-in messages.
+func Default() *Catalog {
+	catalog, _ := NewCatalog(nil)
+	return catalog
+}
+
+// NewCatalog creates a catalog with the given overrides applied on top of the defaults.
+//
+// Returns an error listing any override keys that don't correspond to a known message,
+// or any override that is empty, so misconfigurations are caught at startup.
+func NewCatalog(overrides map[string]string) (*Catalog, error) {
+	messages := make(map[Key]string, len(defaultMessages))
+	for key, text := range defaultMessages {
+		messages[key] = text
+	}
+
+	var unknown []string
+	for key, text := range overrides {
+		if _, known := defaultMessages[Key(key)]; !known {
+			unknown = append(unknown, key)
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil, fmt.Errorf("message %q override cannot be empty", key)
+		}
+		messages[Key(key)] = text
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown message keys in overrides: %s", strings.Join(unknown, ", "))
+	}
+
+	return &Catalog{messages: messages}, nil
+}
+
+// LoadCatalog builds a catalog from a JSON override file.
+// An empty path returns the default catalog.
+func LoadCatalog(path string) (*Catalog, error) {
+	if path == "" {
+		return Default(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read messages file: %w", err)
+	}
+
+	var overrides map[string]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse messages file: %w", err)
+	}
+
+	return NewCatalog(overrides)
+}
+
+// Format returns the message for key with placeholders substituted from params.
+//
+// Example:
+//
+//	catalog.Format(messages.KeyFieldTooLong, messages.Params{"field": "Title", "max": 2000, "current": 2100})
+//	// Returns: "Title exceeds maximum length of 2000 characters (current: 2100)"
+func (c *Catalog) Format(key Key, params Params) string {
+	text, found := c.lookup(key)
+	if !found {
+		return string(key)
+	}
+	if len(params) == 0 {
+		return text
+	}
+
+	replacements := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(text)
+}
+
+// Keys returns all known message keys in sorted order.
+func Keys() []Key {
+	keys := make([]Key, 0, len(defaultMessages))
+	for key := range defaultMessages {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// lookup returns the text for key, falling back to defaults for a nil catalog.
+func (c *Catalog) lookup(key Key) (string, bool) {
+	if c == nil {
+		text, found := defaultMessages[key]
+		return text, found
+	}
+	text, found := c.messages[key]
+	return text, found
+}
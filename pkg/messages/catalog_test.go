@@ -0,0 +1,179 @@
+package messages
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestFormat tests placeholder substitution in default messages
+func TestFormat(t *testing.T) {
+	catalog := Default()
+
+	tests := []struct {
+		name     string
+		key      Key
+		params   Params
+		expected string
+	}{
+		{
+			name:     "message without placeholders",
+			key:      KeyUserLookupFailed,
+			params:   nil,
+			expected: "Failed to identify user. Please try again.",
+		},
+		{
+			name:     "single placeholder",
+			key:      KeyUserNotFound,
+			params:   Params{"email": "jane@example.com"},
+			expected: "Your Slack email (jane@example.com) is not associated with a Notion account in this workspace. Please contact your administrator.",
+		},
+		{
+			name:     "numeric placeholders",
+			key:      KeyFieldTooLong,
+			params:   Params{"field": "Title", "max": 2000, "current": 2100},
+			expected: "Title exceeds maximum length of 2000 characters (current: 2100)",
+		},
+		{
+			name:     "missing placeholder value is left as-is",
+			key:      KeyFieldRequired,
+			params:   Params{"other": "value"},
+			expected: "{field} is required",
+		},
+		{
+			name:     "unknown key returns the key",
+			key:      Key("does_not_exist"),
+			params:   nil,
+			expected: "does_not_exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := catalog.Format(tt.key, tt.params); got != tt.expected {
+				t.Errorf("Format() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFormat_NilCatalog verifies a nil catalog falls back to defaults
+func TestFormat_NilCatalog(t *testing.T) {
+	var catalog *Catalog
+	if got := catalog.Format(KeyOpenModalFailed, nil); got != defaultMessages[KeyOpenModalFailed] {
+		t.Errorf("Format() = %q, want default message", got)
+	}
+}
+
+// TestNewCatalog_Overrides tests that overrides replace defaults
+func TestNewCatalog_Overrides(t *testing.T) {
+	catalog, err := NewCatalog(map[string]string{
+		"user_not_found": "Can't find {email} — ask in #help-hopperbot",
+	})
+	if err != nil {
+		t.Fatalf("NewCatalog() returned unexpected error: %v", err)
+	}
+
+	got := catalog.Format(KeyUserNotFound, Params{"email": "a@b.com"})
+	if got != "Can't find a@b.com — ask in #help-hopperbot" {
+		t.Errorf("Format() = %q", got)
+	}
+
+	// Non-overridden messages keep their defaults
+	if got := catalog.Format(KeyUserLookupFailed, nil); got != defaultMessages[KeyUserLookupFailed] {
+		t.Errorf("expected default for non-overridden key, got %q", got)
+	}
+}
+
+// TestNewCatalog_InvalidOverrides tests rejection of unknown keys and empty messages
+func TestNewCatalog_InvalidOverrides(t *testing.T) {
+	tests := []struct {
+		name        string
+		overrides   map[string]string
+		errContains string
+	}{
+		{
+			name:        "unknown keys",
+			overrides:   map[string]string{"nope": "x", "also_nope": "y"},
+			errContains: "also_nope, nope",
+		},
+		{
+			name:        "empty message",
+			overrides:   map[string]string{"submit_failed": "   "},
+			errContains: "cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCatalog(tt.overrides)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("error %q should contain %q", err.Error(), tt.errContains)
+			}
+		})
+	}
+}
+
+// TestLoadCatalog tests loading overrides from a JSON file
+func TestLoadCatalog(t *testing.T) {
+	dir := t.TempDir()
+
+	validPath := filepath.Join(dir, "messages.json")
+	if err := os.WriteFile(validPath, []byte(`{"submit_failed": "Oops: {error}"}`), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	invalidPath := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalidPath, []byte(`not json`), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	t.Run("empty path uses defaults", func(t *testing.T) {
+		catalog, err := LoadCatalog("")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := catalog.Format(KeySubmitFailed, Params{"error": "boom"}); got != "Failed to submit: boom" {
+			t.Errorf("Format() = %q", got)
+		}
+	})
+
+	t.Run("valid file", func(t *testing.T) {
+		catalog, err := LoadCatalog(validPath)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := catalog.Format(KeySubmitFailed, Params{"error": "boom"}); got != "Oops: boom" {
+			t.Errorf("Format() = %q", got)
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		if _, err := LoadCatalog(invalidPath); err == nil {
+			t.Error("expected error for invalid JSON")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := LoadCatalog(filepath.Join(dir, "missing.json")); err == nil {
+			t.Error("expected error for missing file")
+		}
+	})
+}
+
+// TestKeys verifies every key has a non-empty default message
+func TestKeys(t *testing.T) {
+	keys := Keys()
+	if len(keys) != len(defaultMessages) {
+		t.Fatalf("Keys() returned %d keys, want %d", len(keys), len(defaultMessages))
+	}
+	for _, key := range keys {
+		if strings.TrimSpace(defaultMessages[key]) == "" {
+			t.Errorf("key %q has an empty default message", key)
+		}
+	}
+}
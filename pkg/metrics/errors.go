@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Error categories are the only values used for error_type labels, so upstream
+// error strings can never add label values.
+const (
+	ErrorCategoryAuth       = "auth"        // Invalid or revoked credentials, missing scopes or access
+	ErrorCategoryRateLimit  = "rate_limit"  // Throttled by the upstream API
+	ErrorCategoryValidation = "validation"  // Request rejected as invalid
+	ErrorCategoryTimeout    = "timeout"     // Deadline exceeded, canceled, or network timeout
+	ErrorCategoryBackend5xx = "backend_5xx" // Upstream server error
+	ErrorCategoryUnknown    = "unknown"     // Anything else
+)
+
+// ErrorCategories lists every error category, in the order above.
+var ErrorCategories = []string{
+	ErrorCategoryAuth,
+	ErrorCategoryRateLimit,
+	ErrorCategoryValidation,
+	ErrorCategoryTimeout,
+	ErrorCategoryBackend5xx,
+	ErrorCategoryUnknown,
+}
+
+// StatusErrorCategory maps an HTTP error status code to an error category.
+func StatusErrorCategory(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrorCategoryAuth
+	case status == http.StatusTooManyRequests:
+		return ErrorCategoryRateLimit
+	case status == http.StatusRequestTimeout:
+		return ErrorCategoryTimeout
+	case status >= http.StatusInternalServerError:
+		return ErrorCategoryBackend5xx
+	case status >= http.StatusBadRequest:
+		return ErrorCategoryValidation
+	default:
+		return ErrorCategoryUnknown
+	}
+}
+
+// IsTimeoutError reports whether err is a context deadline or cancellation, or
+// a network timeout. These are categorized as ErrorCategoryTimeout regardless
+// of the API that returned them.
+func IsTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
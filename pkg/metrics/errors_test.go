@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+)
+
+// TestStatusErrorCategory tests mapping HTTP status codes to error categories
+func TestStatusErrorCategory(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{http.StatusBadRequest, ErrorCategoryValidation},
+		{http.StatusUnauthorized, ErrorCategoryAuth},
+		{http.StatusForbidden, ErrorCategoryAuth},
+		{http.StatusNotFound, ErrorCategoryValidation},
+		{http.StatusRequestTimeout, ErrorCategoryTimeout},
+		{http.StatusTooManyRequests, ErrorCategoryRateLimit},
+		{http.StatusBadGateway, ErrorCategoryBackend5xx},
+		{http.StatusGatewayTimeout, ErrorCategoryBackend5xx},
+		{http.StatusOK, ErrorCategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := StatusErrorCategory(tt.status); got != tt.want {
+			t.Errorf("StatusErrorCategory(%d) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+// TestIsTimeoutError tests detecting context and network timeouts
+func TestIsTimeoutError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "deadline", err: fmt.Errorf("request: %w", context.DeadlineExceeded), want: true},
+		{name: "canceled", err: context.Canceled, want: true},
+		{name: "network timeout", err: &net.OpError{Op: "dial", Err: timeoutError{}}, want: true},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: false},
+		{name: "other", err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTimeoutError(tt.err); got != tt.want {
+				t.Errorf("IsTimeoutError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
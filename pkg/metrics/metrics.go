@@ -14,21 +14,75 @@ type Metrics struct {
 	HTTPRequestDuration  *prometheus.HistogramVec
 	HTTPRequestsInFlight prometheus.Gauge
 	HTTPResponseSize     *prometheus.HistogramVec
+	HTTPConnectionStates *prometheus.CounterVec
+
+	// Per-user rate limiting of Slack endpoints (see middleware.WithRateLimit)
+	RateLimitRequestsTotal *prometheus.CounterVec
+	RateLimitBuckets       prometheus.Gauge
 
 	// Slack-specific metrics
 	SlackCommandsTotal     *prometheus.CounterVec
 	SlackInteractionsTotal *prometheus.CounterVec
 	SlackModalSubmissions  *prometheus.CounterVec
+	SlackFormFieldsMissing *prometheus.CounterVec
+	SlackAPIErrors         *prometheus.CounterVec
+
+	// SlackSubcommandsTotal counts /hopperbot subcommands by name ("unknown" for unrecognised ones)
+	SlackSubcommandsTotal *prometheus.CounterVec
+	// SlackAdminCommandsDenied counts admin subcommands rejected for users who aren't admins
+	SlackAdminCommandsDenied *prometheus.CounterVec
+
+	// StaticCustomerOptionsTruncated counts modals whose static customer select couldn't list every customer
+	StaticCustomerOptionsTruncated prometheus.Counter
+
+	// AttachmentsTotal counts files attached to created pages by outcome (uploaded, linked, failed)
+	AttachmentsTotal *prometheus.CounterVec
+
+	// VotesTotal counts vote reactions on confirmation cards by action (added, removed)
+	VotesTotal *prometheus.CounterVec
+
+	// SubmissionsLimited counts new ideas rejected by the per-user limits by reason (cooldown, quota)
+	SubmissionsLimited *prometheus.CounterVec
+	// SubmissionLimitOverrides counts admin overrides of the per-user limits (exempt, reset)
+	SubmissionLimitOverrides *prometheus.CounterVec
+
+	// SlackUserCacheLookups counts Slack user profile lookups by result (hit, miss)
+	SlackUserCacheLookups *prometheus.CounterVec
+	// SlackUserCacheSize is the number of Slack users whose email is cached
+	SlackUserCacheSize prometheus.Gauge
+	// OptionsCacheLookups counts customer options requests by result (hit, miss)
+	OptionsCacheLookups *prometheus.CounterVec
+	// SubmitterFallbacks counts submissions attributed to NOTION_FALLBACK_USER_ID
+	SubmitterFallbacks prometheus.Counter
+	// SubmitterPolicies counts ideas from Slack guests and hidden emails handled by their policy
+	SubmitterPolicies *prometheus.CounterVec
 
 	// Notion API metrics
 	NotionAPIRequestsTotal   *prometheus.CounterVec
 	NotionAPIRequestDuration *prometheus.HistogramVec
 	NotionAPIErrors          *prometheus.CounterVec
+	NotionPermissionGranted  *prometheus.GaugeVec
+	NotionSchemaValid        prometheus.Gauge
+
+	// Notion list pagination metrics (see notion.paginate)
+	NotionPaginatedPages   *prometheus.CounterVec
+	NotionPageLimitReached *prometheus.CounterVec
+
+	// Notion outbound connection metrics (httptrace)
+	NotionConnectionsTotal        *prometheus.CounterVec
+	NotionConnectionPhaseDuration *prometheus.HistogramVec
+
+	// Outbound client retry and circuit breaker metrics (see pkg/httpclient)
+	OutboundRetriesTotal           *prometheus.CounterVec
+	OutboundCircuitOpen            *prometheus.GaugeVec
+	OutboundCircuitRejectionsTotal *prometheus.CounterVec
 
 	// Application metrics
 	ValidationErrorsTotal *prometheus.CounterVec
 	ClientCacheSize       prometheus.Gauge
 	UserCacheSize         prometheus.Gauge
+	UserCacheGuests       prometheus.Gauge
+	CacheChecksum         *prometheus.GaugeVec
 	PanicRecoveriesTotal  prometheus.Counter
 
 	// Cache refresh metrics
@@ -36,6 +90,49 @@ type Metrics struct {
 	CacheRefreshDuration      *prometheus.HistogramVec
 	CacheLastRefreshTimestamp *prometheus.GaugeVec
 	CacheRefreshRetriesTotal  *prometheus.CounterVec
+
+	// Analytics export metrics
+	AnalyticsEventsTotal *prometheus.CounterVec
+
+	// Follow-up reminder metrics
+	RemindersTotal   *prometheus.CounterVec
+	RemindersPending prometheus.Gauge
+
+	// Async submission queue metrics
+	SubmissionQueueTotal *prometheus.CounterVec
+	SubmissionQueueDepth prometheus.Gauge
+
+	// Fan-out writes of submissions to secondary backends (see pkg/fanout)
+	FanoutWritesTotal *prometheus.CounterVec
+	FanoutPending     *prometheus.GaugeVec
+
+	// Idea funnel metrics (time from submission to triage and decision)
+	IdeaTimeToTriage   prometheus.Histogram
+	IdeaTimeToDecision prometheus.Histogram
+	FunnelIdeas        *prometheus.GaugeVec
+
+	// Status change DMs to submitters
+	StatusNotificationsTotal *prometheus.CounterVec
+
+	// Submissions digest
+	DigestsTotal *prometheus.CounterVec
+
+	// Scheduled job metrics (see pkg/scheduler)
+	ScheduledJobRunsTotal            *prometheus.CounterVec
+	ScheduledJobDuration             *prometheus.HistogramVec
+	ScheduledJobLastSuccessTimestamp *prometheus.GaugeVec
+
+	// Background worker pool metrics (see pkg/workers)
+	WorkerQueueDepth   *prometheus.GaugeVec
+	WorkerTasksTotal   *prometheus.CounterVec
+	WorkerTaskWait     *prometheus.HistogramVec
+	WorkerTaskDuration *prometheus.HistogramVec
+
+	// ConfigReloadsTotal counts configuration reloads by trigger (signal, file) and status (success, failure)
+	ConfigReloadsTotal *prometheus.CounterVec
+
+	// CredentialRotationsTotal counts credential rotations by credential, source (admin, file) and status (success, failure)
+	CredentialRotationsTotal *prometheus.CounterVec
 }
 
 // NewMetrics creates and registers all Prometheus metrics
@@ -78,6 +175,32 @@ func NewMetrics() *Metrics {
 			[]string{"endpoint", "method"},
 		),
 
+		// Server connection state transitions (keep-alive effectiveness)
+		HTTPConnectionStates: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_http_server_connection_states_total",
+				Help: "Total number of server connection state transitions (new, active, idle, hijacked, closed)",
+			},
+			[]string{"state"},
+		),
+
+		// Rate limit decisions by endpoint
+		RateLimitRequestsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_rate_limit_requests_total",
+				Help: "Total number of rate limit decisions for Slack requests by endpoint and decision (allowed, limited)",
+			},
+			[]string{"endpoint", "decision"},
+		),
+
+		// Rate limiter buckets held (recently active users per endpoint)
+		RateLimitBuckets: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_rate_limit_buckets",
+				Help: "Number of per-user rate limit buckets held, as of the last idle bucket sweep",
+			},
+		),
+
 		// Slack slash command invocations
 		SlackCommandsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -105,6 +228,124 @@ func NewMetrics() *Metrics {
 			[]string{"status"},
 		),
 
+		// Form fields absent from submitted views (outdated or modified modals)
+		SlackFormFieldsMissing: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_slack_form_fields_missing_total",
+				Help: "Total number of modal submissions missing an expected form field, by field and whether it is required",
+			},
+			[]string{"field", "required"},
+		),
+
+		// Slash subcommands routed by the command router
+		SlackSubcommandsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_slack_subcommands_total",
+				Help: "Total number of /hopperbot subcommand invocations by subcommand (unknown for unrecognised subcommands)",
+			},
+			[]string{"subcommand"},
+		),
+
+		// Admin subcommands run by users who aren't admins
+		SlackAdminCommandsDenied: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_slack_admin_commands_denied_total",
+				Help: "Total number of admin slash subcommands denied to users outside SLACK_ADMIN_USER_IDS and SLACK_ADMIN_USERGROUP",
+			},
+			[]string{"subcommand"},
+		),
+
+		// Slack Web API errors by error category (see ErrorCategories)
+		SlackAPIErrors: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_slack_api_errors_total",
+				Help: "Total number of Slack Web API errors by operation and error category (auth, rate_limit, validation, timeout, backend_5xx, unknown)",
+			},
+			[]string{"operation", "error_type"},
+		),
+
+		// Files from the modal copied to created pages
+		AttachmentsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_attachments_total",
+				Help: "Total number of files attached to created Notion pages by outcome (uploaded, linked to Slack, failed)",
+			},
+			[]string{"outcome"},
+		),
+
+		VotesTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_votes_total",
+				Help: "Total number of vote reactions on confirmation cards by action (added, removed)",
+			},
+			[]string{"action"},
+		),
+
+		// New ideas over SUBMISSION_COOLDOWN or SUBMISSION_QUOTA_PER_DAY
+		SubmissionsLimited: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_submissions_limited_total",
+				Help: "Total number of new ideas rejected by the per-user submission limits by reason (cooldown, quota)",
+			},
+			[]string{"reason"},
+		),
+		SubmissionLimitOverrides: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_submission_limit_overrides_total",
+				Help: "Total number of admin overrides of the per-user submission limits (exempt: an admin went over a limit, reset: /hopperbot reset-quota)",
+			},
+			[]string{"override"},
+		),
+
+		// Slack user profiles served from the Slack user cache instead of users.info
+		SlackUserCacheLookups: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_slack_user_cache_lookups_total",
+				Help: "Total number of Slack user profile lookups by result (hit: served from the cache, miss: fetched with users.info)",
+			},
+			[]string{"result"},
+		),
+		SlackUserCacheSize: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_slack_user_cache_size",
+				Help: "Number of Slack users whose email is cached for Slack-to-Notion mapping",
+			},
+		),
+
+		// Customer options responses reused for repeated searches
+		OptionsCacheLookups: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_options_cache_lookups_total",
+				Help: "Total number of customer options requests by result (hit: served from the options cache, miss: searched)",
+			},
+			[]string{"result"},
+		),
+
+		// Submitters with no Notion user, attributed to the fallback user
+		SubmitterFallbacks: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "hopperbot_submitter_fallbacks_total",
+				Help: "Total number of submissions attributed to NOTION_FALLBACK_USER_ID because the submitter's Slack email has no Notion user",
+			},
+		),
+
+		// Slack guests and hidden emails (SLACK_GUEST_POLICY, HIDDEN_EMAIL_POLICY)
+		SubmitterPolicies: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_submitter_policy_total",
+				Help: "Total number of submissions from Slack guests and users with hidden emails, by user type and the policy applied",
+			},
+			[]string{"user_type", "policy"}, // user_type: slack_guest, hidden_email; policy: reject, triage, attribute
+		),
+
+		// Static customer selects that had to leave customers out (CUSTOMER_SELECT_MODE=static)
+		StaticCustomerOptionsTruncated: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "hopperbot_static_customer_options_truncated_total",
+				Help: "Total number of modals whose static customer select listed only the most used customers",
+			},
+		),
+
 		// Notion API request counter
 		NotionAPIRequestsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -124,15 +365,96 @@ func NewMetrics() *Metrics {
 			[]string{"operation"},
 		),
 
-		// Notion API errors
+		// Notion API errors by error category (see ErrorCategories)
 		NotionAPIErrors: promauto.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "hopperbot_notion_api_errors_total",
-				Help: "Total number of Notion API errors",
+				Help: "Total number of Notion API errors by operation and error category (auth, rate_limit, validation, timeout, backend_5xx, unknown)",
 			},
 			[]string{"operation", "error_type"},
 		),
 
+		// Pages of paginated Notion lists read, by operation
+		NotionPaginatedPages: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_notion_paginated_pages_total",
+				Help: "Total number of pages of paginated Notion lists read, by operation (fetch_customers, fetch_users, find_user, list_databases, query_ideas)",
+			},
+			[]string{"operation"},
+		),
+
+		// Paginated Notion lists that stopped at the page limit, by operation
+		NotionPageLimitReached: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_notion_page_limit_reached_total",
+				Help: "Total number of paginated Notion lists that stopped at their page limit with pages remaining, by operation",
+			},
+			[]string{"operation"},
+		),
+
+		// Notion outbound connections by whether a keep-alive connection was reused
+		NotionConnectionsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_notion_connections_total",
+				Help: "Total number of connections obtained for Notion API requests by reuse (true/false)",
+			},
+			[]string{"reused"},
+		),
+
+		// Notion outbound connection setup duration by phase
+		NotionConnectionPhaseDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "hopperbot_notion_connection_phase_duration_seconds",
+				Help:    "Duration of new Notion API connection setup phases (dns, connect, tls) in seconds",
+				Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+			},
+			[]string{"phase"},
+		),
+
+		// Outbound request retries by client and reason
+		OutboundRetriesTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_outbound_retries_total",
+				Help: "Total number of retried outbound API requests by client and reason (connect, timeout, network, or HTTP status)",
+			},
+			[]string{"client", "reason"},
+		),
+
+		// Outbound circuit breaker state by client and host
+		OutboundCircuitOpen: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_outbound_circuit_open",
+				Help: "Whether the circuit breaker for an outbound API host is open (1) or closed (0)",
+			},
+			[]string{"client", "host"},
+		),
+
+		// Outbound requests rejected by an open circuit by client
+		OutboundCircuitRejectionsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_outbound_circuit_rejections_total",
+				Help: "Total number of outbound API requests failed fast by an open circuit breaker",
+			},
+			[]string{"client"},
+		),
+
+		// Notion integration permission gauge by capability
+		NotionPermissionGranted: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_notion_permission_granted",
+				Help: "Whether the Notion integration has a required capability (1 = granted, 0 = missing)",
+			},
+			[]string{"capability"},
+		),
+
+		// Whether the ideas database has every required property (submissions are blocked when 0)
+		NotionSchemaValid: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_notion_schema_valid",
+				Help: "Whether the ideas database schema has every required property (1 = valid, 0 = submissions blocked)",
+			},
+		),
+
 		// Form validation errors
 		ValidationErrorsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -158,6 +480,23 @@ func NewMetrics() *Metrics {
 			},
 		),
 
+		// Notion users excluded from mapping by the email domain allowlist
+		UserCacheGuests: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_user_cache_excluded_guests",
+				Help: "Number of Notion users excluded from Slack-to-Notion mapping as external guests (ALLOWED_EMAIL_DOMAINS)",
+			},
+		),
+
+		// Content checksum of each cache, to compare replicas (always 1, identified by labels)
+		CacheChecksum: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_cache_checksum_info",
+				Help: "Checksum of the cached customers or users; replicas with the same checksum have the same cache content",
+			},
+			[]string{"cache", "checksum"},
+		),
+
 		// Panic recoveries
 		PanicRecoveriesTotal: promauto.NewCounter(
 			prometheus.CounterOpts{
@@ -202,9 +541,213 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"cache_type"},
 		),
+
+		// Analytics events by export outcome
+		AnalyticsEventsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_analytics_events_total",
+				Help: "Total number of analytics events by export status (exported, dropped, failed)",
+			},
+			[]string{"status"},
+		),
+
+		// Follow-up reminders by outcome
+		RemindersTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_reminders_total",
+				Help: "Total number of follow-up reminders by status (scheduled, sent, retried, failed)",
+			},
+			[]string{"status"},
+		),
+
+		// Follow-up reminders awaiting delivery
+		RemindersPending: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_reminders_pending",
+				Help: "Current number of follow-up reminders awaiting delivery",
+			},
+		),
+
+		// Queued submissions by outcome
+		SubmissionQueueTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_submission_queue_jobs_total",
+				Help: "Total number of queued submissions by status (enqueued, succeeded, retried, failed)",
+			},
+			[]string{"status"},
+		),
+
+		// Queued submissions awaiting Notion page creation
+		SubmissionQueueDepth: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_submission_queue_depth",
+				Help: "Current number of queued submissions awaiting Notion page creation",
+			},
+		),
+
+		// Writes to secondary backends by target and outcome
+		FanoutWritesTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_fanout_writes_total",
+				Help: "Total number of submission writes to secondary backends by target and outcome (success, failed, retried, abandoned)",
+			},
+			[]string{"target", "outcome"},
+		),
+
+		// Writes waiting for a retry by target
+		FanoutPending: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_fanout_pending",
+				Help: "Current number of submission writes to secondary backends waiting for a retry",
+			},
+			[]string{"target"},
+		),
+
+		// Time from submission until an idea first gets a status
+		IdeaTimeToTriage: promauto.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "hopperbot_idea_time_to_triage_seconds",
+				Help:    "Time from submission until an idea first gets a status",
+				Buckets: funnelBuckets,
+			},
+		),
+
+		// Time from submission until an idea reaches a closed status
+		IdeaTimeToDecision: promauto.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "hopperbot_idea_time_to_decision_seconds",
+				Help:    "Time from submission until an idea reaches a closed status",
+				Buckets: funnelBuckets,
+			},
+		),
+
+		// Tracked ideas by funnel stage
+		FunnelIdeas: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_funnel_ideas",
+				Help: "Current number of tracked ideas by funnel stage (awaiting_triage, in_review, closed, removed)",
+			},
+			[]string{"stage"},
+		),
+
+		// Status change DMs to submitters by outcome
+		StatusNotificationsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_status_notifications_total",
+				Help: "Total number of idea status change DMs to submitters by status (sent, failed)",
+			},
+			[]string{"status"},
+		),
+
+		// Submissions digests by outcome
+		DigestsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_digests_total",
+				Help: "Total number of submissions digests by status (posted, failed)",
+			},
+			[]string{"status"},
+		),
+
+		// Scheduled job runs by job and outcome
+		ScheduledJobRunsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_scheduled_job_runs_total",
+				Help: "Total number of scheduled job runs by job and status (success, failure)",
+			},
+			[]string{"job", "status"},
+		),
+
+		// Scheduled job run duration by job
+		ScheduledJobDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "hopperbot_scheduled_job_duration_seconds",
+				Help:    "Duration of scheduled job runs in seconds",
+				Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+			},
+			[]string{"job"},
+		),
+
+		// Scheduled job last success timestamp by job
+		ScheduledJobLastSuccessTimestamp: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_scheduled_job_last_success_timestamp",
+				Help: "Unix timestamp of the last successful run of a scheduled job",
+			},
+			[]string{"job"},
+		),
+
+		// Background tasks waiting for a worker by pool
+		WorkerQueueDepth: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "hopperbot_worker_queue_depth",
+				Help: "Current number of background tasks waiting for a worker by pool",
+			},
+			[]string{"pool"},
+		),
+
+		// Background tasks by pool and outcome
+		WorkerTasksTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_worker_tasks_total",
+				Help: "Total number of background tasks by pool and status (completed, panicked, rejected)",
+			},
+			[]string{"pool", "status"},
+		),
+
+		// Time background tasks waited for a worker by pool
+		WorkerTaskWait: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "hopperbot_worker_task_wait_seconds",
+				Help:    "Time background tasks waited for a worker in seconds",
+				Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30},
+			},
+			[]string{"pool"},
+		),
+
+		// Time background tasks took to run by pool
+		WorkerTaskDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "hopperbot_worker_task_duration_seconds",
+				Help:    "Duration of background tasks in seconds",
+				Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+			},
+			[]string{"pool"},
+		),
+
+		// Configuration reloads by trigger and outcome (see pkg/configwatch)
+		ConfigReloadsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_config_reloads_total",
+				Help: "Total number of configuration reloads by trigger (signal, file) and status (success, failure)",
+			},
+			[]string{"trigger", "status"},
+		),
+
+		// Credential rotations by credential, source and outcome (see pkg/credentials)
+		CredentialRotationsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hopperbot_credential_rotations_total",
+				Help: "Total number of credential rotations by credential (slack_bot_token, notion_api_key), source (admin, file) and status (success, failure)",
+			},
+			[]string{"credential", "source", "status"},
+		),
 	}
 }
 
+// funnelBuckets spans an hour to a quarter, for idea triage and decision times.
+var funnelBuckets = []float64{
+	3600,     // 1h
+	4 * 3600, // 4h
+	12 * 3600,
+	24 * 3600, // 1d
+	2 * 24 * 3600,
+	3 * 24 * 3600,
+	7 * 24 * 3600, // 1w
+	14 * 24 * 3600,
+	30 * 24 * 3600, // 1 month
+	90 * 24 * 3600,
+}
+
 // GetMetrics returns the singleton metrics instance
 var (
 	defaultMetrics *Metrics
@@ -165,9 +165,9 @@ func TestNotionAPIRequestDuration_Operations(t *testing.T) {
 func TestNotionAPIErrors_Operations(t *testing.T) {
 	metrics := getTestMetrics()
 
-	metrics.NotionAPIErrors.WithLabelValues("submit_form", "api_error").Inc()
-	metrics.NotionAPIErrors.WithLabelValues("fetch_clients", "timeout").Inc()
-	metrics.NotionAPIErrors.WithLabelValues("health_check", "connection_error").Inc()
+	metrics.NotionAPIErrors.WithLabelValues("submit_form", ErrorCategoryValidation).Inc()
+	metrics.NotionAPIErrors.WithLabelValues("fetch_clients", ErrorCategoryTimeout).Inc()
+	metrics.NotionAPIErrors.WithLabelValues("health_check", ErrorCategoryUnknown).Inc()
 }
 
 // TestValidationErrorsTotal tests validation errors counter
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// Bot protection providers supported by WithCaptcha.
+const (
+	CaptchaTurnstile = "turnstile" // Cloudflare Turnstile
+	CaptchaRecaptcha = "recaptcha" // Google reCAPTCHA (v2 or v3)
+)
+
+// captchaProvider is how a provider's widget is embedded, where it puts its
+// token and where the token is verified.
+type captchaProvider struct {
+	scriptURL   string
+	widgetClass string
+	tokenField  string
+	verifyURL   string
+}
+
+var captchaProviders = map[string]captchaProvider{
+	CaptchaTurnstile: {
+		scriptURL:   "https://challenges.cloudflare.com/turnstile/v0/api.js",
+		widgetClass: "cf-turnstile",
+		tokenField:  "cf-turnstile-response",
+		verifyURL:   "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+	},
+	CaptchaRecaptcha: {
+		scriptURL:   "https://www.google.com/recaptcha/api.js",
+		widgetClass: "g-recaptcha",
+		tokenField:  "g-recaptcha-response",
+		verifyURL:   "https://www.google.com/recaptcha/api/siteverify",
+	},
+}
+
+// CaptchaWidget returns the script a form loads for provider's widget and the
+// class of the element it renders into (with a data-sitekey attribute).
+func CaptchaWidget(provider string) (scriptURL, widgetClass string, ok bool) {
+	p, ok := captchaProviders[provider]
+	return p.scriptURL, p.widgetClass, ok
+}
+
+// captchaVerifyTimeout bounds the siteverify call.
+const captchaVerifyTimeout = 5 * time.Second
+
+// CaptchaConfig configures WithCaptcha.
+type CaptchaConfig struct {
+	Provider  string       // CaptchaTurnstile or CaptchaRecaptcha; empty disables verification
+	Secret    string       // Provider secret key
+	MinScore  float64      // Minimum reCAPTCHA v3 score (0-1); 0 accepts any successful check
+	VerifyURL string       // siteverify endpoint; empty uses the provider's
+	Client    *http.Client // HTTP client for siteverify; nil uses one with a 5s timeout
+}
+
+// Validate checks the provider is supported and has a secret.
+func (c CaptchaConfig) Validate() error {
+	if c.Provider == "" {
+		return nil
+	}
+	if _, ok := captchaProviders[c.Provider]; !ok {
+		return fmt.Errorf("unsupported captcha provider %q (want %q or %q)", c.Provider, CaptchaTurnstile, CaptchaRecaptcha)
+	}
+	if c.Secret == "" {
+		return fmt.Errorf("captcha secret is required for provider %q", c.Provider)
+	}
+	if c.MinScore < 0 || c.MinScore > 1 {
+		return fmt.Errorf("captcha minimum score must be between 0 and 1")
+	}
+	if c.VerifyURL != "" {
+		if u, err := url.Parse(c.VerifyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("captcha verify URL must be an http(s) URL, got %q", c.VerifyURL)
+		}
+	}
+	return nil
+}
+
+// WithCaptcha wraps browser-facing form handlers with bot protection. Slack
+// endpoints don't need it (requests are signed by Slack), but public forms do.
+//
+// GET and HEAD requests (rendering the form) pass through. On submissions the
+// token the provider's widget adds to the form (cf-turnstile-response or
+// g-recaptcha-response) is verified with the provider's siteverify API (or
+// cfg.VerifyURL) before the handler runs; missing or rejected tokens get 403 Forbidden, and a
+// provider outage gets 503 rather than letting unverified submissions through.
+// With an empty provider the handler is returned unwrapped.
+func WithCaptcha(cfg CaptchaConfig, logger *zap.Logger, handler http.HandlerFunc) http.HandlerFunc {
+	provider, ok := captchaProviders[cfg.Provider]
+	if !ok {
+		return handler
+	}
+	verifyURL := provider.verifyURL
+	if cfg.VerifyURL != "" {
+		verifyURL = cfg.VerifyURL
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: captchaVerifyTimeout}
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			handler(w, r)
+			return
+		}
+
+		token := r.FormValue(provider.tokenField)
+		if token == "" {
+			http.Error(w, "Captcha required", http.StatusForbidden)
+			return
+		}
+
+		result, err := verifyCaptcha(client, verifyURL, cfg.Secret, token, clientIP(r))
+		if err != nil {
+			logging.FromContext(r.Context(), logger).Error("captcha verification unavailable", zap.String("provider", cfg.Provider), zap.Error(err))
+			http.Error(w, "Captcha verification unavailable, please try again", http.StatusServiceUnavailable)
+			return
+		}
+		if !result.Success || (cfg.MinScore > 0 && result.Score != nil && *result.Score < cfg.MinScore) {
+			logging.FromContext(r.Context(), logger).Warn("captcha verification failed",
+				zap.String("provider", cfg.Provider),
+				zap.Strings("error_codes", result.ErrorCodes),
+				zap.Any("score", result.Score),
+			)
+			http.Error(w, "Captcha verification failed", http.StatusForbidden)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// captchaResult is the siteverify response shared by Turnstile and reCAPTCHA.
+type captchaResult struct {
+	Success    bool     `json:"success"`
+	Score      *float64 `json:"score"` // reCAPTCHA v3 only
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// verifyCaptcha calls a provider's siteverify API.
+func verifyCaptcha(client *http.Client, verifyURL, secret, token, remoteIP string) (*captchaResult, error) {
+	form := url.Values{"secret": {secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	resp, err := client.PostForm(verifyURL, form)
+	if err != nil {
+		return nil, fmt.Errorf("siteverify request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("siteverify returned status %d", resp.StatusCode)
+	}
+	var result captchaResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode siteverify response: %w", err)
+	}
+	return &result, nil
+}
+
+// clientIP returns the request's remote IP, without the port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return strings.TrimSpace(r.RemoteAddr)
+	}
+	return host
+}
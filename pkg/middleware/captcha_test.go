@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// siteverify starts a fake siteverify endpoint answering with status and body,
+// recording the form of each request.
+func siteverify(t *testing.T, status int, body string) (*httptest.Server, *[]url.Values) {
+	t.Helper()
+	var requests []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("siteverify request isn't a form: %v", err)
+		}
+		requests = append(requests, r.PostForm)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func captchaRequest(method string, form url.Values) *http.Request {
+	req := httptest.NewRequest(method, "/web/submit", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.RemoteAddr = "203.0.113.7:51234"
+	return req
+}
+
+func TestWithCaptcha(t *testing.T) {
+	token := url.Values{"cf-turnstile-response": {"token"}}
+
+	tests := []struct {
+		name         string
+		provider     string
+		minScore     float64
+		status       int
+		response     string
+		form         url.Values
+		wantStatus   int
+		wantVerified bool
+	}{
+		{name: "pass", provider: CaptchaTurnstile, status: http.StatusOK, response: `{"success":true}`, form: token, wantStatus: http.StatusOK, wantVerified: true},
+		{name: "missing token", provider: CaptchaTurnstile, status: http.StatusOK, response: `{"success":true}`, wantStatus: http.StatusForbidden},
+		{name: "rejected token", provider: CaptchaTurnstile, status: http.StatusOK, response: `{"success":false,"error-codes":["invalid-input-response"]}`, form: token, wantStatus: http.StatusForbidden, wantVerified: true},
+		{name: "score below minimum", provider: CaptchaRecaptcha, minScore: 0.5, status: http.StatusOK, response: `{"success":true,"score":0.3}`, form: url.Values{"g-recaptcha-response": {"token"}}, wantStatus: http.StatusForbidden, wantVerified: true},
+		{name: "score at minimum", provider: CaptchaRecaptcha, minScore: 0.5, status: http.StatusOK, response: `{"success":true,"score":0.5}`, form: url.Values{"g-recaptcha-response": {"token"}}, wantStatus: http.StatusOK, wantVerified: true},
+		{name: "provider error", provider: CaptchaTurnstile, status: http.StatusInternalServerError, form: token, wantStatus: http.StatusServiceUnavailable, wantVerified: true},
+		{name: "provider garbage", provider: CaptchaTurnstile, status: http.StatusOK, response: `<html>`, form: token, wantStatus: http.StatusServiceUnavailable, wantVerified: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := siteverify(t, tt.status, tt.response)
+			served := false
+			handler := WithCaptcha(CaptchaConfig{Provider: tt.provider, Secret: "secret", MinScore: tt.minScore, VerifyURL: srv.URL},
+				zap.NewNop(), func(w http.ResponseWriter, r *http.Request) { served = true })
+
+			rec := httptest.NewRecorder()
+			handler(rec, captchaRequest(http.MethodPost, tt.form))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if served != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("handler served = %v with status %d", served, rec.Code)
+			}
+			if got := len(*requests) == 1; got != tt.wantVerified {
+				t.Fatalf("siteverify called %d times, want verified = %v", len(*requests), tt.wantVerified)
+			}
+			if tt.wantVerified {
+				form := (*requests)[0]
+				if form.Get("secret") != "secret" || form.Get("response") != "token" || form.Get("remoteip") != "203.0.113.7" {
+					t.Errorf("siteverify form = %v, want the secret, token and client IP", form)
+				}
+			}
+		})
+	}
+}
+
+func TestWithCaptcha_ProviderUnreachable(t *testing.T) {
+	srv, _ := siteverify(t, http.StatusOK, `{"success":true}`)
+	srv.Close()
+
+	handler := WithCaptcha(CaptchaConfig{Provider: CaptchaTurnstile, Secret: "secret", VerifyURL: srv.URL},
+		zap.NewNop(), func(w http.ResponseWriter, r *http.Request) { t.Error("handler served without verification") })
+	rec := httptest.NewRecorder()
+	handler(rec, captchaRequest(http.MethodPost, url.Values{"cf-turnstile-response": {"token"}}))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}
+
+func TestWithCaptcha_Passthrough(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		method   string
+	}{
+		{"disabled", "", http.MethodPost},
+		{"rendering the form", CaptchaTurnstile, http.MethodGet},
+		{"HEAD", CaptchaTurnstile, http.MethodHead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := siteverify(t, http.StatusOK, `{"success":false}`)
+			served := false
+			handler := WithCaptcha(CaptchaConfig{Provider: tt.provider, Secret: "secret", VerifyURL: srv.URL},
+				zap.NewNop(), func(w http.ResponseWriter, r *http.Request) { served = true })
+
+			handler(httptest.NewRecorder(), captchaRequest(tt.method, nil))
+			if !served || len(*requests) != 0 {
+				t.Errorf("served = %v, siteverify calls = %d; want served unverified", served, len(*requests))
+			}
+		})
+	}
+}
+
+func TestCaptchaConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CaptchaConfig
+		wantErr string
+	}{
+		{"disabled", CaptchaConfig{}, ""},
+		{"turnstile", CaptchaConfig{Provider: CaptchaTurnstile, Secret: "s"}, ""},
+		{"recaptcha with score and URL", CaptchaConfig{Provider: CaptchaRecaptcha, Secret: "s", MinScore: 0.5, VerifyURL: "https://proxy.internal/siteverify"}, ""},
+		{"unknown provider", CaptchaConfig{Provider: "hcaptcha", Secret: "s"}, "unsupported captcha provider"},
+		{"no secret", CaptchaConfig{Provider: CaptchaTurnstile}, "secret is required"},
+		{"score out of range", CaptchaConfig{Provider: CaptchaRecaptcha, Secret: "s", MinScore: 1.5}, "between 0 and 1"},
+		{"relative verify URL", CaptchaConfig{Provider: CaptchaTurnstile, Secret: "s", VerifyURL: "/siteverify"}, "http(s) URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,13 +1,19 @@
 package middleware
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/subtle"
 	"net/http"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/rudderlabs/hopperbot/pkg/logging"
 	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/requestid"
 	"go.uber.org/zap"
 )
 
@@ -84,7 +90,7 @@ func WithTimeout(timeout time.Duration, logger *zap.Logger, m *metrics.Metrics,
 				if rec := recover(); rec != nil {
 					// Log the panic - it won't be caught by WithRecovery since we're in a goroutine
 					m.PanicRecoveriesTotal.Inc()
-					logger.Error("panic recovered in timeout middleware goroutine",
+					logging.FromContext(r.Context(), logger).Error("panic recovered in timeout middleware goroutine",
 						zap.Any("panic", rec),
 						zap.String("stack", string(debug.Stack())),
 						zap.String("method", r.Method),
@@ -117,7 +123,7 @@ func WithRecovery(logger *zap.Logger, m *metrics.Metrics, handler http.HandlerFu
 		defer func() {
 			if err := recover(); err != nil {
 				m.PanicRecoveriesTotal.Inc()
-				logger.Error("panic recovered",
+				logging.FromContext(r.Context(), logger).Error("panic recovered",
 					zap.Any("error", err),
 					zap.String("stack", string(debug.Stack())),
 					zap.String("method", r.Method),
@@ -151,17 +157,180 @@ func WithLogging(logger *zap.Logger, handler http.HandlerFunc) http.HandlerFunc
 			status = http.StatusOK
 		}
 
-		logger.Info("http request",
+		logging.FromContext(r.Context(), logger).Info("http request",
 			zap.String("method", r.Method),
 			zap.String("path", r.URL.Path),
 			zap.Int("status", status),
 			zap.Duration("duration", time.Since(start)),
 			zap.Int("size", rw.size),
 			zap.String("user_agent", r.UserAgent()),
+			zap.String("remote_ip", clientIP(r)),
 		)
 	}
 }
 
+// WithRequestID gives each request an ID (see pkg/requestid), stored in the
+// request context with a request logger (logger with a request_id field, see
+// pkg/logging) and echoed in the X-Request-ID response header. Place it
+// outermost so every later middleware and handler logs the same ID. When
+// trustHeader is true (behind a reverse proxy, see WithProxyHeaders), a valid
+// X-Request-ID the proxy already set is kept instead of generating a new one.
+func WithRequestID(trustHeader bool, logger *zap.Logger, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !trustHeader || !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
+		ctx := requestid.NewContext(r.Context(), id)
+		ctx = logging.NewContext(ctx, logger.With(zap.String("request_id", id)))
+		handler(w, r.WithContext(ctx))
+	}
+}
+
+// WithBasePath serves handler under a path prefix (e.g. "/hopperbot"), for
+// deployments behind a reverse proxy that routes by path without stripping it.
+// The prefix is removed before handler sees the request, so routes are
+// registered unprefixed; requests outside the prefix get 404. An empty
+// basePath returns handler unchanged.
+func WithBasePath(basePath string, handler http.HandlerFunc) http.HandlerFunc {
+	if basePath == "" {
+		return handler
+	}
+	stripped := http.StripPrefix(basePath, handler)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != basePath && !strings.HasPrefix(r.URL.Path, basePath+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		stripped.ServeHTTP(w, r)
+	}
+}
+
+// WithProxyHeaders takes the client IP, host and scheme from the
+// X-Forwarded-For, X-Forwarded-Host and X-Forwarded-Proto headers set by the
+// trustedHops reverse proxies in front of the server, so logs and handlers see
+// the original request (see IsSecure for the scheme). Each proxy appends the
+// address it received the request from to X-Forwarded-For, so the client is the
+// trustedHops-th entry from the right; anything further left was sent by the
+// client and may be spoofed. The other headers are read the same way. When
+// trustedHops is 0, handler is returned unchanged.
+func WithProxyHeaders(trustedHops int, handler http.HandlerFunc) http.HandlerFunc {
+	if trustedHops <= 0 {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if client := forwardedValue(r.Header, "X-Forwarded-For", trustedHops); client != "" {
+			r.RemoteAddr = client
+		}
+		if host := forwardedValue(r.Header, "X-Forwarded-Host", trustedHops); host != "" {
+			r.Host = host
+		}
+		if proto := strings.ToLower(forwardedValue(r.Header, "X-Forwarded-Proto", trustedHops)); proto == "http" || proto == "https" {
+			r = r.WithContext(context.WithValue(r.Context(), forwardedProtoKey{}, proto))
+		}
+		handler(w, r)
+	}
+}
+
+// forwardedValue returns the trustedHops-th entry from the right of a
+// comma-separated X-Forwarded-* header (repeated headers are joined), or the
+// leftmost when there are fewer entries, i.e. the request came through fewer
+// proxies and the leftmost was set by the first of them.
+func forwardedValue(header http.Header, name string, trustedHops int) string {
+	var entries []string
+	for _, value := range header.Values(name) {
+		for _, entry := range strings.Split(value, ",") {
+			entries = append(entries, strings.TrimSpace(entry))
+		}
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+	return entries[max(len(entries)-trustedHops, 0)]
+}
+
+// forwardedProtoKey is the context key of the scheme WithProxyHeaders took from X-Forwarded-Proto.
+type forwardedProtoKey struct{}
+
+// IsSecure reports whether the client reached the server over HTTPS: the
+// request arrived over TLS, or a trusted proxy (see WithProxyHeaders) says
+// it received it over HTTPS. X-Forwarded-Proto is ignored unless
+// TRUST_PROXY_HEADERS is set.
+func IsSecure(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	proto, _ := r.Context().Value(forwardedProtoKey{}).(string)
+	return proto == "https"
+}
+
+// compressionWriter buffers a response so it can be gzipped once its size is known
+type compressionWriter struct {
+	http.ResponseWriter
+	buf        bytes.Buffer
+	statusCode int
+}
+
+func (cw *compressionWriter) WriteHeader(code int) {
+	cw.statusCode = code
+}
+
+func (cw *compressionWriter) Write(b []byte) (int, error) {
+	return cw.buf.Write(b)
+}
+
+// WithCompression gzips responses of at least minBytes when the client accepts gzip.
+//
+// The response is buffered so small bodies (most Slack acks) are sent uncompressed,
+// where gzip overhead would outweigh the savings. Intended for endpoints with
+// potentially large JSON bodies such as /slack/options.
+func WithCompression(minBytes int, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			handler(w, r)
+			return
+		}
+
+		cw := &compressionWriter{ResponseWriter: w}
+		handler(cw, r)
+
+		statusCode := cw.statusCode
+		if statusCode == 0 {
+			statusCode = http.StatusOK
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		if cw.buf.Len() < minBytes || w.Header().Get("Content-Encoding") != "" {
+			w.WriteHeader(statusCode)
+			w.Write(cw.buf.Bytes())
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+		w.WriteHeader(statusCode)
+
+		gz := gzip.NewWriter(w)
+		gz.Write(cw.buf.Bytes())
+		gz.Close()
+	}
+}
+
+// WithBearerAuth wraps admin HTTP handlers with static bearer token authentication.
+// Requests must send "Authorization: Bearer <token>"; comparison is constant-time.
+func WithBearerAuth(token string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
 // Chain combines multiple middleware functions into one
 func Chain(handler http.HandlerFunc, middlewares ...func(http.HandlerFunc) http.HandlerFunc) http.HandlerFunc {
 	// Apply middleware in reverse order so they execute in the order specified
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithProxyHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		trustedHops int
+		header      http.Header
+		wantAddr    string
+		wantHost    string
+		wantSecure  bool
+	}{
+		{
+			name:        "untrusted",
+			trustedHops: 0,
+			header:      http.Header{"X-Forwarded-For": {"198.51.100.1"}, "X-Forwarded-Host": {"bot.example.com"}, "X-Forwarded-Proto": {"https"}},
+			wantAddr:    "192.0.2.1:1234",
+			wantHost:    "example.com",
+		},
+		{
+			name:        "one proxy",
+			trustedHops: 1,
+			header:      http.Header{"X-Forwarded-For": {"198.51.100.1"}, "X-Forwarded-Host": {"bot.example.com"}, "X-Forwarded-Proto": {"https"}},
+			wantAddr:    "198.51.100.1",
+			wantHost:    "bot.example.com",
+			wantSecure:  true,
+		},
+		{
+			name:        "spoofed entries left of the proxy's",
+			trustedHops: 1,
+			header:      http.Header{"X-Forwarded-For": {"10.0.0.1, 198.51.100.1"}, "X-Forwarded-Proto": {"https, http"}},
+			wantAddr:    "198.51.100.1",
+			wantHost:    "example.com",
+		},
+		{
+			name:        "two proxies",
+			trustedHops: 2,
+			header:      http.Header{"X-Forwarded-For": {"10.0.0.1, 198.51.100.1", "172.16.0.5"}, "X-Forwarded-Proto": {"HTTPS", "http"}},
+			wantAddr:    "198.51.100.1",
+			wantHost:    "example.com",
+			wantSecure:  true,
+		},
+		{
+			name:        "fewer entries than hops",
+			trustedHops: 3,
+			header:      http.Header{"X-Forwarded-For": {"198.51.100.1, 172.16.0.5"}},
+			wantAddr:    "198.51.100.1",
+			wantHost:    "example.com",
+		},
+		{
+			name:        "unknown scheme",
+			trustedHops: 1,
+			header:      http.Header{"X-Forwarded-Proto": {"wss"}},
+			wantAddr:    "192.0.2.1:1234",
+			wantHost:    "example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			req.Header = tt.header
+			var got *http.Request
+			WithProxyHeaders(tt.trustedHops, func(w http.ResponseWriter, r *http.Request) { got = r })(httptest.NewRecorder(), req)
+
+			if got.RemoteAddr != tt.wantAddr || got.Host != tt.wantHost {
+				t.Errorf("RemoteAddr = %q, Host = %q; want %q, %q", got.RemoteAddr, got.Host, tt.wantAddr, tt.wantHost)
+			}
+			if IsSecure(got) != tt.wantSecure {
+				t.Errorf("IsSecure() = %v, want %v", IsSecure(got), tt.wantSecure)
+			}
+		})
+	}
+}
+
+func TestIsSecure_TLS(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.TLS = &tls.ConnectionState{}
+	if !IsSecure(req) {
+		t.Error("IsSecure() = false for a TLS request")
+	}
+}
+
+func TestWithBasePath(t *testing.T) {
+	tests := []struct {
+		name       string
+		basePath   string
+		path       string
+		wantStatus int
+		wantPath   string
+	}{
+		{"no base path", "", "/slack/command", http.StatusOK, "/slack/command"},
+		{"prefixed route", "/hopperbot", "/hopperbot/slack/command", http.StatusOK, "/slack/command"},
+		{"base path itself", "/hopperbot", "/hopperbot", http.StatusOK, ""},
+		{"outside the prefix", "/hopperbot", "/slack/command", http.StatusNotFound, ""},
+		{"prefix of a longer segment", "/hopperbot", "/hopperbotx/slack/command", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			handler := WithBasePath(tt.basePath, func(w http.ResponseWriter, r *http.Request) { gotPath = r.URL.Path })
+
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("handler saw path %q, want %q", gotPath, tt.wantPath)
+			}
+		})
+	}
+}
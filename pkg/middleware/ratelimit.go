@@ -0,0 +1,231 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// rateLimitSweepInterval is how often idle buckets are dropped.
+const rateLimitSweepInterval = time.Minute
+
+// bucket is one key's token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time // When tokens was last refilled
+}
+
+// RateLimiter is a token bucket per key: each key may make burst requests at
+// once, refilled at perMinute tokens a minute. It is safe for concurrent use.
+//
+// Buckets that have refilled completely are dropped periodically, so memory
+// only grows with the number of recently active keys.
+type RateLimiter struct {
+	rate      float64 // Tokens per second
+	burst     float64
+	metrics   *metrics.Metrics
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time // Overridable for tests
+}
+
+// NewRateLimiter creates a rate limiter allowing perMinute requests a minute
+// per key, with bursts of up to burst. metrics may be nil to disable metrics recording.
+func NewRateLimiter(perMinute, burst int, m *metrics.Metrics) *RateLimiter {
+	return &RateLimiter{
+		rate:      float64(perMinute) / 60,
+		burst:     float64(max(burst, 1)),
+		metrics:   m,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Allow takes a token from key's bucket. When none is left it returns false
+// and how long until one is.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if l.rate <= 0 {
+		return false, time.Minute
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// sweep drops buckets that have refilled completely, at most once per
+// rateLimitSweepInterval. Callers hold mu.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	if l.metrics != nil {
+		l.metrics.RateLimitBuckets.Set(float64(len(l.buckets)))
+	}
+}
+
+// WithRateLimit wraps Slack endpoints with per-user rate limiting, so a
+// misbehaving client or runaway retries can't flood Notion and Slack through
+// one user. Requests are limited per Slack user ID and endpoint with limiter;
+// those over the limit get 429 Too Many Requests with a Retry-After header and
+// an ephemeral Slack message built by message. Requests without a user ID
+// (e.g. Events API callbacks) are never limited. A nil limiter returns handler
+// unchanged.
+//
+// The user ID is read from the form body: user_id for slash commands, or
+// user.id in the payload JSON for interactions and options loads. It is only
+// trusted once the body's Slack signature checks out against signingSecret:
+// unsigned or forged requests are passed on without touching any bucket, so
+// they can't use up another user's requests, and handler rejects them. The
+// body is restored for handler.
+func WithRateLimit(limiter *RateLimiter, endpoint, signingSecret string, message func(retryAfter time.Duration) string, logger *zap.Logger, handler http.HandlerFunc) http.HandlerFunc {
+	if limiter == nil {
+		return handler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := slackUserID(r, signingSecret, limiter.now())
+		if userID == "" {
+			handler(w, r)
+			return
+		}
+
+		allowed, retryAfter := limiter.Allow(endpoint + ":" + userID)
+		limiter.record(endpoint, allowed)
+		if allowed {
+			handler(w, r)
+			return
+		}
+
+		logging.FromContext(r.Context(), logger).Warn("rate limited Slack request",
+			zap.String("endpoint", endpoint),
+			zap.String("user_id", userID),
+			zap.Duration("retry_after", retryAfter),
+		)
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]string{
+			"response_type": "ephemeral",
+			"text":          message(time.Duration(seconds) * time.Second),
+		})
+	}
+}
+
+// WithIPRateLimit wraps a public browser form with rate limiting per client
+// IP (after WithProxyHeaders), so one client can't flood Notion through a form
+// that has no Slack user to limit. Only POSTs are limited; rendering the form
+// is free. Posts over the limit get 429 Too Many Requests with a Retry-After
+// header. A nil limiter returns handler unchanged.
+func WithIPRateLimit(limiter *RateLimiter, endpoint string, logger *zap.Logger, handler http.HandlerFunc) http.HandlerFunc {
+	if limiter == nil {
+		return handler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handler(w, r)
+			return
+		}
+
+		ip := clientIP(r)
+		allowed, retryAfter := limiter.Allow(endpoint + ":" + ip)
+		limiter.record(endpoint, allowed)
+		if allowed {
+			handler(w, r)
+			return
+		}
+
+		logging.FromContext(r.Context(), logger).Warn("rate limited form post",
+			zap.String("endpoint", endpoint),
+			zap.String("remote_ip", ip),
+			zap.Duration("retry_after", retryAfter),
+		)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		http.Error(w, "Too many submissions, try again later", http.StatusTooManyRequests)
+	}
+}
+
+// record counts a rate limit decision.
+func (l *RateLimiter) record(endpoint string, allowed bool) {
+	if l.metrics == nil {
+		return
+	}
+	decision := "allowed"
+	if !allowed {
+		decision = "limited"
+	}
+	l.metrics.RateLimitRequestsTotal.WithLabelValues(endpoint, decision).Inc()
+}
+
+// slackUserID returns the Slack user ID in a request's form body, restoring
+// the body for the next handler. Returns "" when there is none or the request
+// isn't signed by Slack.
+func slackUserID(r *http.Request, signingSecret string, now time.Time) string {
+	if r.Body == nil || r.Method != http.MethodPost {
+		return ""
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil || !VerifySlackSignature(signingSecret, now, r.Header, body) {
+		return ""
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return ""
+	}
+	if userID := values.Get("user_id"); userID != "" {
+		return userID
+	}
+
+	var payload struct {
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	}
+	if json.Unmarshal([]byte(values.Get("payload")), &payload) != nil {
+		return ""
+	}
+	return payload.User.ID
+}
+
+// readCloser reads from a replayed body but closes the original.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
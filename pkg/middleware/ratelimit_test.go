@@ -0,0 +1,249 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const testSigningSecret = "signing-secret"
+
+// fakeClock is a settable time source for RateLimiter.now.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestLimiter(perMinute, burst int) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	l := NewRateLimiter(perMinute, burst, nil)
+	l.now = clock.now
+	l.lastSweep = clock.t
+	return l, clock
+}
+
+// signedRequest builds a Slack POST with body signed by secret.
+func signedRequest(t *testing.T, body, secret string, now time.Time) *http.Request {
+	t.Helper()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	l, clock := newTestLimiter(60, 2)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("U1"); !ok {
+			t.Fatalf("request %d within burst was limited", i+1)
+		}
+	}
+	ok, retryAfter := l.Allow("U1")
+	if ok {
+		t.Fatal("request over burst was allowed")
+	}
+	if retryAfter != time.Second {
+		t.Errorf("retryAfter = %v, want 1s at 60 a minute", retryAfter)
+	}
+
+	// Other keys have their own bucket
+	if ok, _ := l.Allow("U2"); !ok {
+		t.Error("U2 limited by U1's requests")
+	}
+
+	// One token refills a second
+	clock.t = clock.t.Add(time.Second)
+	if ok, _ := l.Allow("U1"); !ok {
+		t.Error("request after refill was limited")
+	}
+	if ok, _ := l.Allow("U1"); ok {
+		t.Error("second request after a one-token refill was allowed")
+	}
+}
+
+func TestRateLimiter_AllowZeroRate(t *testing.T) {
+	l, _ := newTestLimiter(0, 1)
+	l.Allow("U1")
+	if ok, retryAfter := l.Allow("U1"); ok || retryAfter != time.Minute {
+		t.Errorf("Allow() = %v, %v, want false, 1m", ok, retryAfter)
+	}
+}
+
+func TestRateLimiter_Sweep(t *testing.T) {
+	l, clock := newTestLimiter(1, 2)
+	l.Allow("idle")
+	l.Allow("busy")
+
+	// Before rateLimitSweepInterval nothing is dropped
+	clock.t = clock.t.Add(rateLimitSweepInterval / 2)
+	l.Allow("busy")
+	if len(l.buckets) != 2 {
+		t.Fatalf("buckets = %d before the sweep interval, want 2", len(l.buckets))
+	}
+
+	// After it, buckets that refilled completely are dropped; busy is still
+	// short of its burst and kept
+	clock.t = clock.t.Add(rateLimitSweepInterval / 2)
+	l.Allow("busy")
+	if _, ok := l.buckets["idle"]; ok {
+		t.Error("idle bucket not swept")
+	}
+	if b, ok := l.buckets["busy"]; !ok || b.tokens >= 1 {
+		t.Errorf("busy bucket = %+v, want it kept with its tokens spent", b)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	l, clock := newTestLimiter(1, 1)
+	var served int
+	handler := WithRateLimit(l, "/slack/commands", testSigningSecret, func(retryAfter time.Duration) string {
+		return "wait " + retryAfter.String()
+	}, zap.NewNop(), func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "user_id=U1" {
+			t.Errorf("handler body = %q, want it restored", body)
+		}
+		served++
+	})
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	if rec := serve(signedRequest(t, "user_id=U1", testSigningSecret, clock.t)); rec.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", rec.Code)
+	}
+
+	rec := serve(signedRequest(t, "user_id=U1", testSigningSecret, clock.t))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want 60", got)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var msg map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &msg); err != nil {
+		t.Fatalf("body %q isn't JSON: %v", rec.Body.String(), err)
+	}
+	if msg["response_type"] != "ephemeral" || msg["text"] != "wait 1m0s" {
+		t.Errorf("body = %v, want an ephemeral message from message()", msg)
+	}
+	if served != 1 {
+		t.Errorf("handler served %d requests, want 1", served)
+	}
+}
+
+func TestWithRateLimit_UnverifiedRequestsNotCounted(t *testing.T) {
+	l, clock := newTestLimiter(1, 1)
+	var served int
+	handler := WithRateLimit(l, "/slack/commands", testSigningSecret, func(time.Duration) string { return "" },
+		zap.NewNop(), func(w http.ResponseWriter, r *http.Request) { served++ })
+
+	tests := []struct {
+		name string
+		req  *http.Request
+	}{
+		{"forged signature", signedRequest(t, "user_id=U1", "attacker-secret", clock.t)},
+		{"expired signature", signedRequest(t, "user_id=U1", testSigningSecret, clock.t.Add(-10*time.Minute))},
+		{"unsigned", httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader("user_id=U1"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler(rec, tt.req)
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want the request passed to the handler", rec.Code)
+			}
+		})
+	}
+	if len(l.buckets) != 0 {
+		t.Errorf("buckets = %d, want unverified requests to leave none", len(l.buckets))
+	}
+
+	// U1's own signed request still goes through
+	rec := httptest.NewRecorder()
+	handler(rec, signedRequest(t, "user_id=U1", testSigningSecret, clock.t))
+	if rec.Code != http.StatusOK || served != len(tests)+1 {
+		t.Errorf("signed request status = %d, served = %d; want 200, %d", rec.Code, served, len(tests)+1)
+	}
+}
+
+func TestWithIPRateLimit(t *testing.T) {
+	l, _ := newTestLimiter(1, 1)
+	var served int
+	handler := WithIPRateLimit(l, "/web/submit", zap.NewNop(), func(w http.ResponseWriter, r *http.Request) { served++ })
+
+	post := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/web/submit", strings.NewReader("title=x"))
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	if rec := post("203.0.113.7:5000"); rec.Code != http.StatusOK {
+		t.Fatalf("first post status = %d, want 200", rec.Code)
+	}
+	// Another port on the same IP shares the bucket
+	rec := post("203.0.113.7:5001")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second post status = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want 60", got)
+	}
+	if rec := post("198.51.100.2:5000"); rec.Code != http.StatusOK {
+		t.Errorf("other IP status = %d, want 200", rec.Code)
+	}
+
+	// Rendering the form isn't limited
+	get := httptest.NewRequest(http.MethodGet, "/web/submit", nil)
+	get.RemoteAddr = "203.0.113.7:5000"
+	handler(httptest.NewRecorder(), get)
+	if served != 3 {
+		t.Errorf("handler served %d requests, want 3", served)
+	}
+}
+
+func TestSlackUserID(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	payload := url.Values{"payload": {`{"type":"block_suggestion","user":{"id":"U2"}}`}}.Encode()
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"slash command", "user_id=U1&command=%2Fhopperbot", "U1"},
+		{"interaction payload", payload, "U2"},
+		{"no user", "command=%2Fhopperbot", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slackUserID(signedRequest(t, tt.body, testSigningSecret, now), testSigningSecret, now); got != tt.want {
+				t.Errorf("slackUserID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+)
+
+// Slack request signing (https://api.slack.com/authentication/verifying-requests-from-slack)
+const (
+	slackTimestampHeader = "X-Slack-Request-Timestamp"
+	slackSignatureHeader = "X-Slack-Signature"
+	slackSignatureBase   = "v0"
+	slackSignaturePrefix = "v0="
+)
+
+// VerifySlackSignature reports whether body and header carry a valid Slack
+// signature for signingSecret, made no more than constants.MaxSlackRequestAge
+// seconds before now.
+func VerifySlackSignature(signingSecret string, now time.Time, header http.Header, body []byte) bool {
+	timestamp := header.Get(slackTimestampHeader)
+	signature := header.Get(slackSignatureHeader)
+	if timestamp == "" || signature == "" {
+		return false
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || now.Unix()-ts > constants.MaxSlackRequestAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte(slackSignatureBase + ":" + timestamp + ":"))
+	mac.Write(body)
+	expected := slackSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,161 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantHost string
+		wantPort uint16
+		wantUser string
+		wantPass string
+		wantDB   string
+		wantTLS  bool // A TLS connection is tried first
+		wantErr  bool
+	}{
+		{url: "postgres://bot@localhost", wantHost: "localhost", wantPort: 5432, wantUser: "bot", wantTLS: true},
+		{url: "postgresql://bot:s3cret@db:6543/archive?sslmode=verify-full", wantHost: "db", wantPort: 6543, wantUser: "bot", wantPass: "s3cret", wantDB: "archive", wantTLS: true},
+		{url: "postgres://bot@db/archive?sslmode=disable", wantHost: "db", wantPort: 5432, wantUser: "bot", wantDB: "archive"},
+		{url: "mysql://bot@db", wantErr: true},
+		{url: "postgres://db/archive", wantErr: true},
+		{url: "postgres://bot@/archive", wantErr: true},
+		{url: "postgres://bot@db?sslmode=sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			c, err := New(tt.url)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("New() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer c.Close()
+
+			cfg := c.pool.Config()
+			conn := cfg.ConnConfig
+			if conn.Host != tt.wantHost || conn.Port != tt.wantPort || conn.User != tt.wantUser || conn.Password != tt.wantPass || conn.Database != tt.wantDB {
+				t.Errorf("New() = host %q port %d user %q pass %q db %q", conn.Host, conn.Port, conn.User, conn.Password, conn.Database)
+			}
+			if gotTLS := conn.TLSConfig != nil; gotTLS != tt.wantTLS {
+				t.Errorf("TLS first = %v, want %v", gotTLS, tt.wantTLS)
+			}
+			if conn.RuntimeParams["application_name"] != "hopperbot" || conn.RuntimeParams["timezone"] != "UTC" {
+				t.Errorf("runtime parameters = %v", conn.RuntimeParams)
+			}
+			if cfg.MaxConns != maxConns {
+				t.Errorf("MaxConns = %d, want %d", cfg.MaxConns, maxConns)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2026-05-01 12:00:00+00", time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"2026-05-01 12:00:00.25+00", time.Date(2026, 5, 1, 12, 0, 0, 250_000_000, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got, err := ParseTime(tt.value); err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+		}
+	}
+	if _, err := ParseTime("yesterday"); err == nil {
+		t.Error("ParseTime(yesterday) error = nil, want error")
+	}
+}
+
+func TestClient_Unreachable(t *testing.T) {
+	c, err := New("postgres://bot@127.0.0.1:" + strconv.Itoa(closedPort(t)) + "/ideas?sslmode=disable")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Ping(ctx); err == nil {
+		t.Error("Ping() to a closed port error = nil, want error")
+	}
+	if _, err := c.Exec(ctx, "SELECT 1"); err == nil {
+		t.Error("Exec() to a closed port error = nil, want error")
+	}
+}
+
+// TestClient_Postgres runs statements against the server in
+// HOPPERBOT_TEST_POSTGRES_URL, and is skipped without one.
+func TestClient_Postgres(t *testing.T) {
+	rawURL := os.Getenv("HOPPERBOT_TEST_POSTGRES_URL")
+	if rawURL == "" {
+		t.Skip("HOPPERBOT_TEST_POSTGRES_URL not set")
+	}
+	c, err := New(rawURL)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	ctx := context.Background()
+
+	// Not a temporary table: statements may run on different pool connections
+	table := "pgstore_test_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	if _, err := c.Exec(ctx, `CREATE TABLE `+table+` (id text, created_at timestamptz, payload jsonb, note text)`); err != nil {
+		t.Fatalf("Exec(CREATE) error = %v", err)
+	}
+	t.Cleanup(func() { c.Exec(context.Background(), `DROP TABLE `+table) })
+	created := time.Date(2026, 5, 1, 12, 0, 0, 250_000_000, time.UTC)
+	n, err := c.Exec(ctx, `INSERT INTO `+table+` VALUES ($1, $2, $3, $4), ($5, $2, $3, NULL)`, "page-1", created, []byte(`{"a":1}`), "note", "page-2")
+	if err != nil || n != 2 {
+		t.Fatalf("Exec(INSERT) = %d, %v, want 2 rows", n, err)
+	}
+
+	rows, err := c.Query(ctx, `SELECT id, created_at, payload, note, count(*) OVER () FROM `+table+` ORDER BY id`)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(rows) != 2 || !slices.Equal(rows[0], []string{"page-1", "2026-05-01 12:00:00.25+00", `{"a": 1}`, "note", "2"}) || rows[1][3] != "" {
+		t.Fatalf("Query() = %q", rows)
+	}
+	if got, err := ParseTime(rows[0][1]); err != nil || !got.Equal(created) {
+		t.Errorf("ParseTime() = %v, %v, want %v", got, err, created)
+	}
+
+	_, err = c.Exec(ctx, "SELECT * FROM pgstore_missing")
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "42P01" {
+		t.Errorf("Exec() error = %v, want a 42P01 server error", err)
+	}
+	// A server error doesn't break the client
+	if err := c.Ping(ctx); err != nil {
+		t.Errorf("Ping() after a server error error = %v", err)
+	}
+}
+
+// closedPort returns a local port with nothing listening on it.
+func closedPort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	return port
+}
@@ -0,0 +1,409 @@
+// Package queue creates Notion pages for submissions asynchronously.
+//
+// When the queue is enabled, the Slack handler validates a submission, enqueues
+// it, and closes the modal straight away instead of waiting on Notion. Jobs
+// run on the shared pkg/workers pool, which creates the page via a ProcessFunc
+// supplied by the handler; transient failures are retried with exponential
+// backoff, so a slow or unavailable Notion no longer fails submissions. Once a
+// job succeeds or is given up on, the handler tells the submitter the outcome.
+//
+// Features:
+// - JSON file-backed store so pending submissions survive restarts (memory-only when no path is set)
+// - Immediate processing of new jobs plus a periodic sweep for retries
+// - Jobs run on a shared worker pool, so one slow job doesn't hold up the others
+// - Exponential backoff with a bounded number of attempts; permanent errors fail fast
+// - Graceful shutdown with context cancellation
+// - Metrics for enqueued, succeeded, retried, and failed jobs and the queue depth
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/jsonfile"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/rudderlabs/hopperbot/pkg/workers"
+	"go.uber.org/zap"
+)
+
+const (
+	// Retry configuration for failed jobs
+	baseRetryDelay = 30 * time.Second // Doubled after every failed attempt
+	maxRetryDelay  = 30 * time.Minute
+	maxAttempts    = 10
+)
+
+// Job is one submission waiting to be written to Notion.
+type Job struct {
+	ID            string                `json:"id"`
+	Submission    submission.Submission `json:"submission"`
+	ReminderDelay time.Duration         `json:"reminder_delay,omitempty"` // Follow-up reminder to schedule on success (0 for none)
+	ResponseURL   string                `json:"response_url,omitempty"`   // Slack response_url to report the outcome to (DM when empty or expired)
+	EnqueuedAt    time.Time             `json:"enqueued_at"`
+	NextAttemptAt time.Time             `json:"next_attempt_at"`
+	Attempts      int                   `json:"attempts"`             // Failed attempts so far
+	LastError     string                `json:"last_error,omitempty"` // Error from the last failed attempt
+}
+
+// Store persists pending jobs.
+type Store interface {
+	// Add inserts or replaces a job (keyed by ID).
+	Add(job Job) error
+
+	// Due returns jobs with NextAttemptAt at or before now, oldest first.
+	Due(now time.Time) ([]Job, error)
+
+	// Delete removes a job. Deleting an unknown ID is not an error.
+	Delete(id string) error
+
+	// Len returns the number of pending jobs.
+	Len() int
+}
+
+// FileStore is a Store holding jobs by ID in a jsonfile.Map, saved oldest
+// first. The queue is normally empty or holds a handful of jobs during a Notion
+// outage. With an empty path the store is memory-only and pending submissions
+// are lost on restart.
+type FileStore struct {
+	*jsonfile.Map[string, Job]
+}
+
+// NewFileStore creates a store backed by path, loading any jobs already saved there.
+// A missing file is treated as empty; an empty path creates a memory-only store.
+func NewFileStore(path string) (*FileStore, error) {
+	jobs, err := jsonfile.NewMap(path, "submission queue file",
+		func(job Job) string { return job.ID },
+		func(a, b Job) bool { return a.EnqueuedAt.Before(b.EnqueuedAt) })
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{jobs}, nil
+}
+
+// Add inserts or replaces a job.
+func (s *FileStore) Add(job Job) error {
+	if job.ID == "" {
+		return fmt.Errorf("job ID is required")
+	}
+	return s.Put(job)
+}
+
+// Due returns jobs that are due at now, oldest first.
+func (s *FileStore) Due(now time.Time) ([]Job, error) {
+	var due []Job
+	for _, job := range s.Values() {
+		if !job.NextAttemptAt.After(now) {
+			due = append(due, job)
+		}
+	}
+	return due, nil
+}
+
+// ProcessFunc attempts to complete a job (e.g., create the Notion page and DM the user).
+// Returning an error schedules a retry unless it is wrapped with Permanent.
+type ProcessFunc func(ctx context.Context, job Job) error
+
+// FailFunc is called once when a job is given up on, with the last error.
+type FailFunc func(ctx context.Context, job Job, err error)
+
+// permanentError marks an error that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the queue fails the job immediately instead of retrying.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent.
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Queue processes jobs in the background.
+//
+// New jobs are processed as soon as they are enqueued; failed jobs are
+// rescheduled with exponential backoff and picked up by a periodic sweep.
+// Jobs are handed to the SetPool pool oldest first (or processed one at a time
+// without one), and a job is never processed by two workers at once.
+type Queue struct {
+	store    Store
+	process  ProcessFunc
+	fail     FailFunc
+	metrics  *metrics.Metrics
+	logger   *zap.Logger
+	interval time.Duration
+	wake     chan struct{}
+	pool     *workers.Pool
+	mu       sync.Mutex
+	running  map[string]bool // IDs of jobs being processed
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup   // Dispatch loop
+	jobs     sync.WaitGroup   // Jobs being processed
+	now      func() time.Time // Overridable for tests
+}
+
+// NewQueue creates a queue in a stopped state. Call Start() to begin processing jobs.
+// metrics may be nil to disable metrics recording.
+func NewQueue(store Store, process ProcessFunc, fail FailFunc, m *metrics.Metrics, logger *zap.Logger, interval time.Duration) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		store:    store,
+		process:  process,
+		fail:     fail,
+		metrics:  m,
+		logger:   logger,
+		interval: interval,
+		wake:     make(chan struct{}, 1),
+		running:  make(map[string]bool),
+		ctx:      ctx,
+		cancel:   cancel,
+		now:      time.Now,
+	}
+}
+
+// SetPool processes jobs on pool instead of one at a time. It must be called
+// before Start, and the pool stopped after the queue.
+func (q *Queue) SetPool(pool *workers.Pool) {
+	q.pool = pool
+}
+
+// Start begins dispatching jobs. Jobs persisted before a restart are processed right away.
+func (q *Queue) Start() {
+	ticker := time.NewTicker(q.interval)
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		defer ticker.Stop()
+
+		q.logger.Info("submission queue started",
+			zap.Duration("retry_check_interval", q.interval),
+			zap.Bool("pooled", q.pool != nil),
+			zap.Int("pending", q.store.Len()),
+		)
+		q.dispatch()
+
+		for {
+			select {
+			case <-q.wake:
+				q.dispatch()
+			case <-ticker.C:
+				q.dispatch()
+			case <-q.ctx.Done():
+				q.logger.Info("submission queue stopping due to context cancellation")
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops dispatching and waits for any in-progress jobs to finish.
+// Unprocessed jobs stay in the store.
+func (q *Queue) Stop() {
+	q.cancel()
+	q.wg.Wait()
+	q.jobs.Wait()
+}
+
+// Enqueue stores a new job and wakes the worker. ID, EnqueuedAt, and
+// NextAttemptAt are filled in when unset. Returns the stored job.
+func (q *Queue) Enqueue(job Job) (Job, error) {
+	if job.ID == "" {
+		id, err := newJobID()
+		if err != nil {
+			return Job{}, err
+		}
+		job.ID = id
+	}
+	if job.EnqueuedAt.IsZero() {
+		job.EnqueuedAt = q.now().UTC()
+	}
+	if job.NextAttemptAt.IsZero() {
+		job.NextAttemptAt = job.EnqueuedAt
+	}
+
+	if err := q.store.Add(job); err != nil {
+		return Job{}, err
+	}
+	q.record("enqueued")
+	q.recordDepth()
+
+	// Non-blocking: a pending wake-up already covers this job
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// RunDue processes all jobs that are currently due and waits for them to finish.
+func (q *Queue) RunDue() {
+	q.dispatch()
+	q.jobs.Wait()
+	q.recordDepth()
+}
+
+// dispatch hands due jobs to the pool, oldest first, without waiting for
+// them to finish; without a pool it processes them in turn. Submitting waits
+// while the pool's queue is full. Each job is handed out at most once per
+// call, so a job whose outcome couldn't be saved isn't retried in a tight loop.
+func (q *Queue) dispatch() {
+	dispatched := make(map[string]bool)
+	for q.ctx.Err() == nil {
+		job, ok := q.claimNext(dispatched)
+		if !ok {
+			return
+		}
+		dispatched[job.ID] = true
+
+		q.jobs.Add(1)
+		if q.pool == nil {
+			q.finish(job)
+			continue
+		}
+		if err := q.pool.Submit(q.ctx, "submission", func() { q.finish(job) }); err != nil {
+			q.logger.Warn("failed to schedule queued submission, will retry", zap.String("job_id", job.ID), zap.Error(err))
+			q.release(job.ID)
+			q.jobs.Done()
+			return
+		}
+	}
+}
+
+// finish processes a claimed job and releases it. Jobs still waiting for a
+// worker when the queue stops are left in the store untouched, without
+// spending an attempt.
+func (q *Queue) finish(job Job) {
+	defer q.jobs.Done()
+	if q.ctx.Err() == nil {
+		q.run(job)
+	}
+	q.release(job.ID)
+	q.recordDepth()
+}
+
+// claimNext marks the oldest due job that isn't being processed or skipped as
+// running and returns it. Due jobs are loaded again on every claim, under the
+// same lock release takes, so a job finished by another worker since the last
+// claim is never processed again.
+func (q *Queue) claimNext(skip map[string]bool) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	due, err := q.store.Due(q.now())
+	if err != nil {
+		q.logger.Error("failed to load due submissions", zap.Error(err))
+		return Job{}, false
+	}
+	for _, job := range due {
+		if !q.running[job.ID] && !skip[job.ID] {
+			q.running[job.ID] = true
+			return job, true
+		}
+	}
+	return Job{}, false
+}
+
+// release marks a job as no longer running, once its outcome is in the store.
+func (q *Queue) release(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.running, id)
+}
+
+// run processes one job and updates the store with the outcome.
+func (q *Queue) run(job Job) {
+	err := q.process(q.ctx, job)
+	if err == nil {
+		q.logger.Info("queued submission processed",
+			zap.String("job_id", job.ID),
+			zap.Int("failed_attempts", job.Attempts),
+		)
+		q.record("succeeded")
+		if err := q.store.Delete(job.ID); err != nil {
+			q.logger.Error("failed to delete processed submission", zap.String("job_id", job.ID), zap.Error(err))
+		}
+		return
+	}
+
+	job.Attempts++
+	job.LastError = err.Error()
+	if IsPermanent(err) || job.Attempts >= maxAttempts {
+		q.logger.Error("giving up on queued submission",
+			zap.String("job_id", job.ID),
+			zap.Int("attempts", job.Attempts),
+			zap.Bool("permanent", IsPermanent(err)),
+			zap.Error(err),
+		)
+		q.record("failed")
+		q.fail(q.ctx, job, err)
+		if err := q.store.Delete(job.ID); err != nil {
+			q.logger.Error("failed to delete failed submission", zap.String("job_id", job.ID), zap.Error(err))
+		}
+		return
+	}
+
+	job.NextAttemptAt = q.now().Add(retryDelay(job.Attempts))
+	q.logger.Warn("queued submission failed, will retry",
+		zap.String("job_id", job.ID),
+		zap.Int("attempts", job.Attempts),
+		zap.Time("retry_at", job.NextAttemptAt),
+		zap.Error(err),
+	)
+	q.record("retried")
+	if err := q.store.Add(job); err != nil {
+		q.logger.Error("failed to reschedule submission", zap.String("job_id", job.ID), zap.Error(err))
+	}
+}
+
+// retryDelay returns the backoff after the given number of failed attempts.
+func retryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+// newJobID returns a random job ID.
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (q *Queue) record(status string) {
+	if q.metrics == nil {
+		return
+	}
+	q.metrics.SubmissionQueueTotal.WithLabelValues(status).Inc()
+}
+
+func (q *Queue) recordDepth() {
+	if q.metrics == nil {
+		return
+	}
+	q.metrics.SubmissionQueueDepth.Set(float64(q.store.Len()))
+}
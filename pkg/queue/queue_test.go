@@ -0,0 +1,280 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+	"github.com/rudderlabs/hopperbot/pkg/workers"
+	"go.uber.org/zap"
+)
+
+// TestFileStore_Persistence tests that jobs survive reopening the store
+func TestFileStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	now := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	for _, job := range []Job{
+		{ID: "retry-later", EnqueuedAt: now.Add(-2 * time.Hour), NextAttemptAt: now.Add(time.Hour)},
+		{ID: "second", EnqueuedAt: now.Add(-time.Minute), NextAttemptAt: now.Add(-time.Minute)},
+		{ID: "first", EnqueuedAt: now.Add(-time.Hour), NextAttemptAt: now, Submission: submission.Submission{
+			Title:          "Test Idea",
+			CustomerOrgIDs: []string{"page-a"},
+			Source:         submission.Source{Channel: submission.ChannelSlack, SlackUserID: "U123"},
+		}},
+	} {
+		if err := store.Add(job); err != nil {
+			t.Fatalf("Add(%s) error = %v", job.ID, err)
+		}
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopening store: %v", err)
+	}
+	if reopened.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", reopened.Len())
+	}
+
+	due, _ := reopened.Due(now)
+	if len(due) != 2 || due[0].ID != "first" || due[1].ID != "second" {
+		t.Fatalf("Due() = %v, want [first second]", due)
+	}
+	if sub := due[0].Submission; sub.Title != "Test Idea" || sub.CustomerOrgIDs[0] != "page-a" || sub.Source.SlackUserID != "U123" {
+		t.Errorf("submission not round-tripped: %+v", sub)
+	}
+}
+
+// TestFileStore_RequiresID tests that jobs without an ID are rejected
+func TestFileStore_RequiresID(t *testing.T) {
+	store, _ := NewFileStore("")
+	if err := store.Add(Job{}); err == nil {
+		t.Error("expected error for job without ID")
+	}
+}
+
+// TestQueue_RunDue tests success, retry with backoff, permanent failure, and giving up
+func TestQueue_RunDue(t *testing.T) {
+	now := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+	store, _ := NewFileStore("")
+	store.Add(Job{ID: "ok", NextAttemptAt: now.Add(-time.Minute)})
+	store.Add(Job{ID: "flaky", NextAttemptAt: now.Add(-time.Minute), Attempts: 2})
+	store.Add(Job{ID: "invalid", NextAttemptAt: now.Add(-time.Minute)})
+	store.Add(Job{ID: "exhausted", NextAttemptAt: now.Add(-time.Minute), Attempts: maxAttempts - 1})
+	store.Add(Job{ID: "future", NextAttemptAt: now.Add(time.Hour)})
+
+	var processed []string
+	process := func(_ context.Context, job Job) error {
+		switch job.ID {
+		case "ok":
+			processed = append(processed, job.ID)
+			return nil
+		case "invalid":
+			return Permanent(errors.New("validation failed"))
+		default:
+			return errors.New("notion unavailable")
+		}
+	}
+	failed := make(map[string]int)
+	fail := func(_ context.Context, job Job, err error) {
+		failed[job.ID] = job.Attempts
+	}
+
+	q := NewQueue(store, process, fail, nil, zap.NewNop(), time.Minute)
+	q.now = func() time.Time { return now }
+	q.RunDue()
+
+	if len(processed) != 1 || processed[0] != "ok" {
+		t.Errorf("processed = %v, want [ok]", processed)
+	}
+	if len(failed) != 2 || failed["invalid"] != 1 || failed["exhausted"] != maxAttempts {
+		t.Errorf("failed = %v, want invalid after 1 attempt and exhausted after %d", failed, maxAttempts)
+	}
+
+	// "ok" done, "invalid" and "exhausted" dropped; "flaky" rescheduled; "future" untouched
+	if store.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", store.Len())
+	}
+	if due, _ := store.Due(now); len(due) != 0 {
+		t.Errorf("expected nothing due immediately after retry scheduling, got %v", due)
+	}
+	due, _ := store.Due(now.Add(retryDelay(3)))
+	if len(due) != 1 || due[0].ID != "flaky" || due[0].Attempts != 3 || due[0].LastError != "notion unavailable" {
+		t.Errorf("Due(after retry delay) = %+v, want flaky with 3 attempts", due)
+	}
+}
+
+// TestRetryDelay tests exponential backoff with a cap
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: baseRetryDelay},
+		{attempts: 2, want: 2 * baseRetryDelay},
+		{attempts: 3, want: 4 * baseRetryDelay},
+		{attempts: 50, want: maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// TestQueue_EnqueueProcessesImmediately tests that the worker picks up new jobs without waiting for a tick
+func TestQueue_EnqueueProcessesImmediately(t *testing.T) {
+	store, _ := NewFileStore("")
+	done := make(chan Job, 1)
+	process := func(_ context.Context, job Job) error {
+		done <- job
+		return nil
+	}
+
+	q := NewQueue(store, process, func(context.Context, Job, error) {}, nil, zap.NewNop(), time.Hour)
+	q.Start()
+	defer q.Stop()
+
+	enqueued, err := q.Enqueue(Job{Submission: submission.Submission{Title: "Test Idea"}})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if enqueued.ID == "" || enqueued.EnqueuedAt.IsZero() {
+		t.Errorf("Enqueue() did not fill in ID and timestamps: %+v", enqueued)
+	}
+
+	select {
+	case job := <-done:
+		if job.ID != enqueued.ID || job.Submission.Title != "Test Idea" {
+			t.Errorf("processed %+v, want %+v", job, enqueued)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("job was not processed after Enqueue")
+	}
+}
+
+// TestQueue_Pool tests that jobs are processed concurrently on the pool up to
+// its number of workers, each exactly once
+func TestQueue_Pool(t *testing.T) {
+	store, _ := NewFileStore("")
+	for _, id := range []string{"a", "b", "c", "d"} {
+		store.Add(Job{ID: id})
+	}
+
+	var mu sync.Mutex
+	processed := make(map[string]int)
+	started := make(chan string, 4)
+	unblock := make(chan struct{})
+	process := func(_ context.Context, job Job) error {
+		started <- job.ID
+		<-unblock
+		mu.Lock()
+		processed[job.ID]++
+		mu.Unlock()
+		return nil
+	}
+
+	q := NewQueue(store, process, func(context.Context, Job, error) {}, nil, zap.NewNop(), time.Hour)
+	pool := workers.New("test", 3, 4, nil, zap.NewNop())
+	defer pool.Stop()
+	q.SetPool(pool)
+	done := make(chan struct{})
+	go func() {
+		q.RunDue()
+		close(done)
+	}()
+
+	// Three jobs start without waiting for each other; the fourth waits for a free worker
+	for range 3 {
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("jobs were not processed concurrently")
+		}
+	}
+	select {
+	case id := <-started:
+		t.Fatalf("job %s started with all workers busy", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(unblock)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunDue did not return")
+	}
+
+	if len(processed) != 4 {
+		t.Errorf("processed = %v, want all 4 jobs", processed)
+	}
+	for id, count := range processed {
+		if count != 1 {
+			t.Errorf("job %s processed %d times, want once", id, count)
+		}
+	}
+	if store.Len() != 0 {
+		t.Errorf("Len() = %d, want every job deleted", store.Len())
+	}
+}
+
+// TestQueue_StopLeavesWaitingJobs tests that jobs still waiting for a worker
+// when the queue stops stay in the store without spending an attempt
+func TestQueue_StopLeavesWaitingJobs(t *testing.T) {
+	store, _ := NewFileStore("")
+	store.Add(Job{ID: "a", EnqueuedAt: time.Now().Add(-2 * time.Minute)})
+	store.Add(Job{ID: "b", EnqueuedAt: time.Now().Add(-time.Minute)})
+
+	started := make(chan string, 2)
+	unblock := make(chan struct{})
+	process := func(_ context.Context, job Job) error {
+		started <- job.ID
+		<-unblock
+		return nil
+	}
+
+	q := NewQueue(store, process, func(context.Context, Job, error) {}, nil, zap.NewNop(), time.Hour)
+	pool := workers.New("test", 1, 4, nil, zap.NewNop())
+	defer pool.Stop()
+	q.SetPool(pool)
+	q.Start()
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("first job was not processed")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		q.Stop()
+		close(stopped)
+	}()
+	// Let Stop cancel the queue before the busy worker frees up
+	time.Sleep(50 * time.Millisecond)
+	close(unblock)
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+
+	select {
+	case id := <-started:
+		t.Errorf("job %s started after the queue stopped", id)
+	default:
+	}
+	due, _ := store.Due(time.Now())
+	if len(due) != 1 || due[0].ID != "b" || due[0].Attempts != 0 {
+		t.Errorf("pending = %+v, want job b kept with no attempts spent", due)
+	}
+}
@@ -0,0 +1,128 @@
+// Package redisstore is the Redis client for state shared between bot
+// replicas, such as the customer and user caches (CACHE_BACKEND=redis).
+//
+// It wraps a small go-redis client (github.com/redis/go-redis/v9), which takes
+// care of the protocol, authentication, TLS and reconnecting, behind the calls
+// the caches need: string keys with expiry through GET, SET (with PX and NX)
+// and PING. Replicas only touch Redis when their caches refresh, so the pool
+// is kept to a few connections.
+//
+// Connections are configured with a URL:
+//
+//	redis://[[username]:password@]host[:port][/db]
+//	rediss://...   (TLS)
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// defaultTimeout bounds a command when the context has no deadline.
+	defaultTimeout = 5 * time.Second
+
+	// poolSize caps the connections; only cache refreshes use them.
+	poolSize = 4
+)
+
+// Client is a Redis client. It is safe for concurrent use.
+type Client struct {
+	rdb    *redis.Client
+	prefix string // Prepended to every key
+}
+
+// New parses a redis:// or rediss:// URL. Keys are namespaced with prefix
+// (e.g. "hopperbot:"). It doesn't connect; the first command does.
+func New(rawURL, prefix string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("invalid Redis URL: scheme must be redis or rediss, got %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid Redis URL: missing host")
+	}
+
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	opts.PoolSize = poolSize
+	// Only the commands above are used; skip the HELLO/CLIENT SETINFO handshake
+	// extras so older servers and proxies work too
+	opts.Protocol = 2
+	opts.DisableIdentity = true
+
+	return &Client{rdb: redis.NewClient(opts), prefix: prefix}, nil
+}
+
+// Get returns the value of key, or nil if it doesn't exist.
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	value, err := c.rdb.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis GET: %w", err)
+	}
+	return value, nil
+}
+
+// Set stores value at key, expiring after ttl (0 keeps it forever).
+func (c *Client) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	if err := c.rdb.Set(ctx, c.prefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("redis SET: %w", err)
+	}
+	return nil
+}
+
+// SetNX stores value at key, expiring after ttl, only if key doesn't exist.
+// It reports whether the value was stored, so it doubles as a lock with expiry.
+func (c *Client) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	stored, err := c.rdb.SetNX(ctx, c.prefix+key, value, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis SET NX: %w", err)
+	}
+	return stored, nil
+}
+
+// Ping checks Redis is reachable, connecting if needed.
+func (c *Client) Ping(ctx context.Context) error {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	if err := c.rdb.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis PING: %w", err)
+	}
+	return nil
+}
+
+// Close closes the client's connections.
+func (c *Client) Close() error {
+	return c.rdb.Close()
+}
+
+// withDefaultTimeout bounds ctx by defaultTimeout unless it has a deadline.
+func withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, defaultTimeout)
+}
@@ -0,0 +1,275 @@
+// Package reminders schedules per-idea follow-up reminders.
+//
+// When submitting an idea, a user can ask to be reminded after a period (1 week,
+// 2 weeks, or 1 month). The reminder is persisted in a Store and a background
+// Scheduler delivers it once due, via a Notifier supplied by the Slack handler
+// (which looks up the idea's current status in Notion and DMs the submitter).
+//
+// Features:
+// - JSON file-backed store so pending reminders survive restarts (memory-only when no path is set)
+// - Periodic due-check in a background goroutine
+// - Bounded retry with linear backoff when delivery fails
+// - Graceful shutdown with context cancellation
+// - Metrics for scheduled, sent, retried, and failed reminders
+package reminders
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/jsonfile"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// Retry configuration for failed deliveries
+	retryDelay  = 15 * time.Minute // Multiplied by the attempt number
+	maxAttempts = 5
+)
+
+// Option is a reminder delay offered in the submission modal.
+type Option struct {
+	Value string        // Slack option value (e.g., "1w")
+	Label string        // Text shown in the modal
+	After time.Duration // Delay after submission
+}
+
+// Options lists the reminder delays users can choose from, in display order.
+var Options = []Option{
+	{Value: "1w", Label: "In 1 week", After: 7 * 24 * time.Hour},
+	{Value: "2w", Label: "In 2 weeks", After: 14 * 24 * time.Hour},
+	{Value: "1m", Label: "In 1 month", After: 30 * 24 * time.Hour},
+}
+
+// ParseOption returns the delay for an option value, or false if the value is unknown.
+func ParseOption(value string) (time.Duration, bool) {
+	for _, option := range Options {
+		if option.Value == value {
+			return option.After, true
+		}
+	}
+	return 0, false
+}
+
+// Reminder is a pending follow-up for one submitted idea.
+type Reminder struct {
+	ID          string    `json:"id"`                      // Unique per idea (the Notion page ID)
+	PageID      string    `json:"page_id"`                 // Notion page to report on
+	PageURL     string    `json:"page_url"`                // Fallback link if the status lookup omits it
+	Title       string    `json:"title"`                   // Title at submission time
+	SlackTeamID string    `json:"slack_team_id,omitempty"` // Workspace of the submitter (selects the bot token)
+	SlackUserID string    `json:"slack_user_id"`           // Submitter to DM
+	CreatedAt   time.Time `json:"created_at"`              // Submission time
+	DueAt       time.Time `json:"due_at"`                  // When the reminder should be delivered
+	Attempts    int       `json:"attempts"`                // Failed delivery attempts so far
+}
+
+// Store persists pending reminders.
+type Store interface {
+	// Add inserts or replaces a reminder (keyed by ID).
+	Add(reminder Reminder) error
+
+	// Due returns reminders with DueAt at or before now, oldest first.
+	Due(now time.Time) ([]Reminder, error)
+
+	// Delete removes a reminder. Deleting an unknown ID is not an error.
+	Delete(id string) error
+
+	// Len returns the number of pending reminders.
+	Len() int
+}
+
+// FileStore is a Store holding reminders by ID in a jsonfile.Map, saved in
+// due order. A few thousand reminders are expected at most. With an empty path
+// the store is memory-only and reminders are lost on restart.
+type FileStore struct {
+	*jsonfile.Map[string, Reminder]
+}
+
+// NewFileStore creates a store backed by path, loading any reminders already saved there.
+// A missing file is treated as empty; an empty path creates a memory-only store.
+func NewFileStore(path string) (*FileStore, error) {
+	reminders, err := jsonfile.NewMap(path, "reminders file",
+		func(r Reminder) string { return r.ID },
+		func(a, b Reminder) bool { return a.DueAt.Before(b.DueAt) })
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{reminders}, nil
+}
+
+// Add inserts or replaces a reminder.
+func (s *FileStore) Add(reminder Reminder) error {
+	if reminder.ID == "" {
+		return fmt.Errorf("reminder ID is required")
+	}
+	return s.Put(reminder)
+}
+
+// Due returns reminders that are due at now, oldest first.
+func (s *FileStore) Due(now time.Time) ([]Reminder, error) {
+	var due []Reminder
+	for _, reminder := range s.Values() {
+		if !reminder.DueAt.After(now) {
+			due = append(due, reminder)
+		}
+	}
+	return due, nil
+}
+
+// Notifier delivers a due reminder (e.g., by sending a Slack DM).
+// Returning an error schedules a retry.
+type Notifier func(ctx context.Context, reminder Reminder) error
+
+// Scheduler delivers due reminders in the background.
+//
+// On each tick it asks the store for due reminders and passes each to the
+// notifier. Delivered reminders are deleted; failed ones are rescheduled with
+// linear backoff and dropped after maxAttempts.
+type Scheduler struct {
+	store    Store
+	notify   Notifier
+	metrics  *metrics.Metrics
+	logger   *zap.Logger
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	now      func() time.Time // Overridable for tests
+}
+
+// NewScheduler creates a scheduler in a stopped state. Call Start() to begin delivering reminders.
+// metrics may be nil to disable metrics recording.
+func NewScheduler(store Store, notify Notifier, m *metrics.Metrics, logger *zap.Logger, interval time.Duration) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		store:    store,
+		notify:   notify,
+		metrics:  m,
+		logger:   logger,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+		now:      time.Now,
+	}
+}
+
+// Start begins the background delivery loop.
+func (s *Scheduler) Start() {
+	ticker := time.NewTicker(s.interval)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer ticker.Stop()
+
+		s.logger.Info("reminder scheduler started",
+			zap.Duration("check_interval", s.interval),
+			zap.Int("pending", s.store.Len()),
+		)
+
+		for {
+			select {
+			case <-ticker.C:
+				s.RunDue()
+			case <-s.ctx.Done():
+				s.logger.Info("reminder scheduler stopping due to context cancellation")
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the delivery loop and waits for any in-progress delivery to finish.
+// Undelivered reminders stay in the store.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// RunDue delivers all reminders that are currently due.
+func (s *Scheduler) RunDue() {
+	due, err := s.store.Due(s.now())
+	if err != nil {
+		s.logger.Error("failed to load due reminders", zap.Error(err))
+		return
+	}
+
+	for _, reminder := range due {
+		if s.ctx.Err() != nil {
+			return
+		}
+		s.deliver(reminder)
+	}
+
+	s.recordPending()
+}
+
+// deliver sends one reminder and updates the store with the outcome.
+func (s *Scheduler) deliver(reminder Reminder) {
+	err := s.notify(s.ctx, reminder)
+	if err == nil {
+		s.logger.Info("reminder delivered",
+			zap.String("reminder_id", reminder.ID),
+			zap.String("slack_user_id", reminder.SlackUserID),
+		)
+		s.record("sent")
+		if err := s.store.Delete(reminder.ID); err != nil {
+			s.logger.Error("failed to delete delivered reminder", zap.String("reminder_id", reminder.ID), zap.Error(err))
+		}
+		return
+	}
+
+	reminder.Attempts++
+	if reminder.Attempts >= maxAttempts {
+		s.logger.Error("giving up on reminder after repeated failures",
+			zap.String("reminder_id", reminder.ID),
+			zap.Int("attempts", reminder.Attempts),
+			zap.Error(err),
+		)
+		s.record("failed")
+		if err := s.store.Delete(reminder.ID); err != nil {
+			s.logger.Error("failed to delete reminder", zap.String("reminder_id", reminder.ID), zap.Error(err))
+		}
+		return
+	}
+
+	reminder.DueAt = s.now().Add(time.Duration(reminder.Attempts) * retryDelay)
+	s.logger.Warn("reminder delivery failed, will retry",
+		zap.String("reminder_id", reminder.ID),
+		zap.Int("attempts", reminder.Attempts),
+		zap.Time("retry_at", reminder.DueAt),
+		zap.Error(err),
+	)
+	s.record("retried")
+	if err := s.store.Add(reminder); err != nil {
+		s.logger.Error("failed to reschedule reminder", zap.String("reminder_id", reminder.ID), zap.Error(err))
+	}
+}
+
+// Schedule stores a new reminder and records it in metrics.
+func (s *Scheduler) Schedule(reminder Reminder) error {
+	if err := s.store.Add(reminder); err != nil {
+		return err
+	}
+	s.record("scheduled")
+	s.recordPending()
+	return nil
+}
+
+func (s *Scheduler) record(status string) {
+	if s.metrics == nil {
+		return
+	}
+	s.metrics.RemindersTotal.WithLabelValues(status).Inc()
+}
+
+func (s *Scheduler) recordPending() {
+	if s.metrics == nil {
+		return
+	}
+	s.metrics.RemindersPending.Set(float64(s.store.Len()))
+}
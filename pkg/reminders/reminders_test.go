@@ -0,0 +1,118 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TestParseOption tests mapping modal values to delays
+func TestParseOption(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{value: "1w", want: 7 * 24 * time.Hour, ok: true},
+		{value: "2w", want: 14 * 24 * time.Hour, ok: true},
+		{value: "1m", want: 30 * 24 * time.Hour, ok: true},
+		{value: "1y", ok: false},
+		{value: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := ParseOption(tt.value)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("ParseOption(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// TestFileStore_Persistence tests that reminders survive reopening the store
+func TestFileStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reminders.json")
+	now := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	for _, reminder := range []Reminder{
+		{ID: "later", DueAt: now.Add(time.Hour)},
+		{ID: "due-second", DueAt: now.Add(-time.Minute)},
+		{ID: "due-first", DueAt: now.Add(-time.Hour)},
+	} {
+		if err := store.Add(reminder); err != nil {
+			t.Fatalf("Add(%s) error = %v", reminder.ID, err)
+		}
+	}
+	if err := store.Delete("later"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopening store: %v", err)
+	}
+	if reopened.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", reopened.Len())
+	}
+
+	due, _ := reopened.Due(now)
+	if len(due) != 2 || due[0].ID != "due-first" || due[1].ID != "due-second" {
+		t.Errorf("Due() = %v, want [due-first due-second]", due)
+	}
+}
+
+// TestFileStore_RequiresID tests that reminders without an ID are rejected
+func TestFileStore_RequiresID(t *testing.T) {
+	store, _ := NewFileStore("")
+	if err := store.Add(Reminder{}); err == nil {
+		t.Error("expected error for reminder without ID")
+	}
+}
+
+// TestScheduler_RunDue tests delivery, retry, and giving up
+func TestScheduler_RunDue(t *testing.T) {
+	now := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+	store, _ := NewFileStore("")
+	store.Add(Reminder{ID: "ok", DueAt: now.Add(-time.Minute)})
+	store.Add(Reminder{ID: "flaky", DueAt: now.Add(-time.Minute)})
+	store.Add(Reminder{ID: "broken", DueAt: now.Add(-time.Minute), Attempts: maxAttempts - 1})
+	store.Add(Reminder{ID: "future", DueAt: now.Add(time.Hour)})
+
+	var delivered []string
+	notify := func(_ context.Context, reminder Reminder) error {
+		if reminder.ID != "ok" {
+			return errors.New("slack unavailable")
+		}
+		delivered = append(delivered, reminder.ID)
+		return nil
+	}
+
+	scheduler := NewScheduler(store, notify, nil, zap.NewNop(), time.Minute)
+	scheduler.now = func() time.Time { return now }
+	scheduler.RunDue()
+
+	if len(delivered) != 1 || delivered[0] != "ok" {
+		t.Errorf("delivered = %v, want [ok]", delivered)
+	}
+
+	// "ok" delivered and "broken" dropped; "flaky" rescheduled; "future" untouched
+	if store.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", store.Len())
+	}
+	if due, _ := store.Due(now); len(due) != 0 {
+		t.Errorf("expected nothing due immediately after retry scheduling, got %v", due)
+	}
+	due, _ := store.Due(now.Add(retryDelay))
+	if len(due) != 1 || due[0].ID != "flaky" || due[0].Attempts != 1 {
+		t.Errorf("Due(after retry delay) = %v, want flaky with 1 attempt", due)
+	}
+}
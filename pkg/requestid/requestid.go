@@ -0,0 +1,61 @@
+// Package requestid gives every inbound HTTP request an ID that follows it
+// through the logs, outbound Notion requests and the error messages shown in
+// Slack, so support can go from a user's "it failed" screenshot to the log
+// lines of that request.
+//
+// The ID is set by middleware.WithRequestID and carried in the request
+// context; code that calls out on behalf of a request reads it back with
+// FromContext. Logging goes through pkg/logging, whose request loggers carry
+// the ID as request_id.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header carries the request ID on responses and on outbound Notion requests.
+// With TRUST_PROXY_HEADERS, an ID a reverse proxy already set on the inbound
+// request is kept, so proxy and bot logs share it.
+const Header = "X-Request-ID"
+
+// maxLength bounds IDs taken from inbound headers.
+const maxLength = 64
+
+type contextKey struct{}
+
+// New returns a random 16-character hex ID.
+func New() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Valid reports whether an ID from an inbound header is safe to reuse: 1-64
+// letters, digits, dots, dashes or underscores, so it can't forge log lines
+// or Slack formatting.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID in ctx, or "" when there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
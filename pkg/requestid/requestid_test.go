@@ -0,0 +1,53 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	a, b := New(), New()
+	if len(a) != 16 || !Valid(a) {
+		t.Errorf("New() = %q, want 16 hex characters", a)
+	}
+	if a == b {
+		t.Errorf("New() returned %q twice", a)
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"3f2a9c1e7b6d4a05", true},
+		{"Root=1-67891233-abcdef012345678912345678", false},
+		{"req_01.abc-DEF", true},
+		{"", false},
+		{strings.Repeat("a", 64), true},
+		{strings.Repeat("a", 65), false},
+		{"id\nfake log line", false},
+		{"<!channel>", false},
+	}
+
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	if id := FromContext(context.Background()); id != "" {
+		t.Errorf("FromContext(empty) = %q, want \"\"", id)
+	}
+	ctx := NewContext(context.Background(), "abc")
+	if id := FromContext(ctx); id != "abc" {
+		t.Errorf("FromContext() = %q, want abc", id)
+	}
+	// Detached contexts, used for work that outlives the request, keep the ID
+	if id := FromContext(context.WithoutCancel(ctx)); id != "abc" {
+		t.Errorf("FromContext(WithoutCancel) = %q, want abc", id)
+	}
+}
@@ -0,0 +1,126 @@
+// Package responseurl posts delayed messages through the response_url Slack
+// sends with slash commands and interactions.
+//
+// Slack only waits 3 seconds for a command or interaction to be acknowledged.
+// Results that take longer (quick submissions, queued jobs, manual cache
+// refreshes, errors found after the acknowledgement) are posted to the
+// response_url instead, which needs no bot token and works in channels the
+// bot isn't a member of.
+//
+// Features:
+// - Ephemeral (only the user sees it) or in-channel messages
+// - Replacing or deleting the message the response_url belongs to
+// - Only https://hooks.slack.com URLs are accepted, so a forged URL can't make the bot post elsewhere
+// - Expired or used-up URLs (valid 30 minutes, 5 posts) are reported as ErrExpired, so callers can fall back to a DM
+package responseurl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Response types.
+const (
+	Ephemeral = "ephemeral"  // Only the user who ran the command sees the message
+	InChannel = "in_channel" // Everyone in the channel sees the message
+)
+
+// slackHost is the only host response URLs are posted to.
+const slackHost = "hooks.slack.com"
+
+var (
+	// ErrExpired is matched (with errors.Is) by errors for response URLs Slack
+	// no longer accepts: older than 30 minutes or already posted to 5 times.
+	ErrExpired = errors.New("response_url expired")
+
+	// ErrInvalidURL is returned for empty URLs and URLs that aren't Slack's.
+	ErrInvalidURL = errors.New("invalid response_url")
+)
+
+// Message is a delayed response. Text is required, even alongside
+// ReplaceOriginal, since Slack uses it for notifications.
+type Message struct {
+	Text            string `json:"text"`
+	ResponseType    string `json:"response_type,omitempty"`    // Ephemeral (Slack's default when empty) or InChannel
+	ReplaceOriginal bool   `json:"replace_original,omitempty"` // Replace the message the URL belongs to
+	DeleteOriginal  bool   `json:"delete_original,omitempty"`  // Delete the message the URL belongs to
+}
+
+// Error is a response URL post Slack rejected.
+type Error struct {
+	StatusCode int
+	Code       string // Slack's error, e.g. expired_url, used_url or no_text
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("response_url rejected: %d %s", e.StatusCode, e.Code)
+}
+
+// Is reports expired and used-up URLs as ErrExpired.
+func (e *Error) Is(target error) bool {
+	return target == ErrExpired && (e.Code == "expired_url" || e.Code == "used_url")
+}
+
+// Client posts messages to response URLs. It is safe for concurrent use.
+type Client struct {
+	httpClient *http.Client
+	checkHost  bool // Disabled by tests posting to an httptest server
+}
+
+// New creates a client sending through httpClient, normally the retrying
+// client shared with the Slack Web API (see pkg/httpclient).
+func New(httpClient *http.Client) *Client {
+	return &Client{httpClient: httpClient, checkHost: true}
+}
+
+// Post sends msg to responseURL.
+func (c *Client) Post(ctx context.Context, responseURL string, msg Message) error {
+	if err := c.validate(responseURL); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create response request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to response_url: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Slack answers "ok", or the error code as plain text
+	text, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode != http.StatusOK {
+		return &Error{StatusCode: resp.StatusCode, Code: strings.TrimSpace(string(text))}
+	}
+	return nil
+}
+
+// validate rejects URLs that aren't Slack response URLs.
+func (c *Client) validate(responseURL string) error {
+	if responseURL == "" {
+		return ErrInvalidURL
+	}
+	u, err := url.Parse(responseURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if c.checkHost && (u.Scheme != "https" || u.Host != slackHost) {
+		return fmt.Errorf("%w: host %q", ErrInvalidURL, u.Host)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package responseurl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestClient_Post tests that the message is posted as JSON
+func TestClient_Post(t *testing.T) {
+	var got Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %s, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := &Client{httpClient: server.Client()}
+	msg := Message{Text: "Caches refreshed", ResponseType: InChannel, ReplaceOriginal: true}
+	if err := client.Post(context.Background(), server.URL+"/commands/T1/1/abc", msg); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if got != msg {
+		t.Errorf("posted %+v, want %+v", got, msg)
+	}
+}
+
+// TestClient_PostRejected tests that Slack's error codes are returned, with
+// expired and used-up URLs matching ErrExpired
+func TestClient_PostRejected(t *testing.T) {
+	tests := []struct {
+		status  int
+		code    string
+		expired bool
+	}{
+		{http.StatusNotFound, "expired_url", true},
+		{http.StatusNotFound, "used_url", true},
+		{http.StatusBadRequest, "no_text", false},
+		{http.StatusInternalServerError, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.code + "\n"))
+			}))
+			defer server.Close()
+
+			client := &Client{httpClient: server.Client()}
+			err := client.Post(context.Background(), server.URL, Message{Text: "hi"})
+			var rejected *Error
+			if !errors.As(err, &rejected) || rejected.StatusCode != tt.status || rejected.Code != tt.code {
+				t.Fatalf("Post() error = %v, want %d %s", err, tt.status, tt.code)
+			}
+			if errors.Is(err, ErrExpired) != tt.expired {
+				t.Errorf("errors.Is(ErrExpired) = %v, want %v", !tt.expired, tt.expired)
+			}
+		})
+	}
+}
+
+// TestClient_PostInvalidURL tests that only Slack's response URLs are posted to
+func TestClient_PostInvalidURL(t *testing.T) {
+	client := New(http.DefaultClient)
+	for _, responseURL := range []string{
+		"",
+		"http://hooks.slack.com/commands/T1/1/abc",
+		"https://example.com/commands/T1/1/abc",
+		"https://hooks.slack.com.example.com/commands/T1/1/abc",
+	} {
+		if err := client.Post(context.Background(), responseURL, Message{Text: "hi"}); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("Post(%q) error = %v, want ErrInvalidURL", responseURL, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseCron_Next tests the next run time of cron expressions
+func TestParseCron_Next(t *testing.T) {
+	// Thursday 15 October 2026, 10:30 UTC
+	from := time.Date(2026, 10, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * MON", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"30 10 * * 4", time.Date(2026, 10, 22, 10, 30, 0, 0, time.UTC)}, // Strictly after from
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},     // 7 is Sunday
+		{"0 8-17/4 * * mon-fri", time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * SAT", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)}, // Either day field matches
+		{"0 0 29 FEB *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}}, // Never
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseCron_TimeZone tests that CRON_TZ evaluates the schedule in another zone
+func TestParseCron_TimeZone(t *testing.T) {
+	schedule, err := ParseCron("CRON_TZ=America/New_York 0 9 * * MON")
+	if err != nil {
+		t.Fatalf("ParseCron() error = %v", err)
+	}
+	got := schedule.Next(time.Date(2026, 10, 15, 10, 30, 0, 0, time.UTC))
+	if want := time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got.UTC(), want)
+	}
+}
+
+// TestParseCron_Invalid tests that malformed expressions are rejected
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"0 9 * *",
+		"60 * * * *",
+		"0 24 * * *",
+		"0 0 0 * *",
+		"0 0 * 13 *",
+		"0 0 * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"0 9 * * FUNDAY",
+		"CRON_TZ=Nowhere/City 0 9 * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) expected error", expr)
+		}
+	}
+}
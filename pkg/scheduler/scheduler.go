@@ -0,0 +1,163 @@
+// Package scheduler runs the bot's background jobs on a schedule: periodic
+// cache refreshes, status change polling and the weekly submissions digest.
+//
+// Jobs are registered with Add before Start, each with a Schedule: a Cron
+// expression or a fixed interval (Every). Every job runs in its own
+// goroutine, so a slow job only delays its own next run; runs of the same job
+// never overlap. A run that is missed while the previous one is still going is
+// skipped, not queued.
+//
+// Features:
+// - Standard five-field cron expressions with names, steps and time zones (see Cron)
+// - Fixed intervals measured from the end of the previous run (see Every, or EveryFunc to change them at runtime)
+// - Jobs receive a context that is cancelled on Stop, with a job-named logger (see pkg/logging)
+// - Failed runs are logged; the job runs again at its next scheduled time
+// - Per-job run counts, durations and last success time (job label = the name given to Add)
+// - Graceful shutdown waits for running jobs to return
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/logging"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// Job is a unit of scheduled work. The context is cancelled when the scheduler stops.
+type Job func(ctx context.Context) error
+
+// Every returns a schedule that runs a job at a fixed interval, measured from
+// the end of its previous run (or from Start for the first run).
+func Every(interval time.Duration) Schedule {
+	return every(interval)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time { return t.Add(time.Duration(e)) }
+
+// EveryFunc is Every with the interval read before each wait, so it can be
+// changed while the scheduler runs (e.g. on a configuration reload). A change
+// applies from the wait after the one in progress.
+func EveryFunc(interval func() time.Duration) Schedule {
+	return everyFunc(interval)
+}
+
+type everyFunc func() time.Duration
+
+func (e everyFunc) Next(t time.Time) time.Time { return t.Add(e()) }
+
+// entry is a registered job.
+type entry struct {
+	name     string
+	schedule Schedule
+	job      Job
+}
+
+// Scheduler runs registered jobs on their schedules.
+type Scheduler struct {
+	entries []entry
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	now     func() time.Time // Overridable for tests
+}
+
+// New creates a scheduler in a stopped state. Register jobs with Add, then call Start().
+// metrics may be nil to disable metrics recording.
+func New(m *metrics.Metrics, logger *zap.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		metrics: m,
+		logger:  logger,
+		ctx:     ctx,
+		cancel:  cancel,
+		now:     time.Now,
+	}
+}
+
+// Add registers a job. Jobs added after Start are not run.
+func (s *Scheduler) Add(name string, schedule Schedule, job Job) {
+	s.entries = append(s.entries, entry{name: name, schedule: schedule, job: job})
+}
+
+// Start begins running every registered job on its schedule.
+func (s *Scheduler) Start() {
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.loop(e)
+		}()
+	}
+	s.logger.Info("scheduler started", zap.Int("jobs", len(s.entries)))
+}
+
+// Stop cancels running jobs and waits for them to return.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// loop waits for each of a job's scheduled times and runs it, until the scheduler stops.
+func (s *Scheduler) loop(e entry) {
+	for {
+		next := e.schedule.Next(s.now())
+		if next.IsZero() {
+			s.logger.Warn("scheduled job has no future runs", zap.String("job", e.name))
+			return
+		}
+		s.logger.Debug("scheduled job waiting", zap.String("job", e.name), zap.Time("next_run", next))
+
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-timer.C:
+			s.run(e)
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// run runs a job once, logging and recording its outcome.
+func (s *Scheduler) run(e entry) {
+	logger := s.logger.With(zap.String("job", e.name))
+	start := time.Now()
+	err := e.job(logging.NewContext(s.ctx, logger))
+	duration := time.Since(start)
+	if err != nil && s.ctx.Err() != nil {
+		logger.Info("scheduled job cancelled by shutdown", zap.Duration("duration", duration))
+		return
+	}
+	s.recordRun(e.name, duration, err)
+
+	if err != nil {
+		logger.Error("scheduled job failed",
+			zap.Duration("duration", duration),
+			zap.Error(err),
+		)
+		return
+	}
+	logger.Debug("scheduled job completed", zap.Duration("duration", duration))
+}
+
+// recordRun records the metrics of a job run.
+func (s *Scheduler) recordRun(job string, duration time.Duration, err error) {
+	if s.metrics == nil {
+		return
+	}
+
+	s.metrics.ScheduledJobDuration.WithLabelValues(job).Observe(duration.Seconds())
+	if err != nil {
+		s.metrics.ScheduledJobRunsTotal.WithLabelValues(job, "failure").Inc()
+		return
+	}
+	s.metrics.ScheduledJobRunsTotal.WithLabelValues(job, "success").Inc()
+	s.metrics.ScheduledJobLastSuccessTimestamp.WithLabelValues(job).Set(float64(s.now().Unix()))
+}
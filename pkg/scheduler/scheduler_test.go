@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// TestScheduler_RunsJobs tests that jobs run repeatedly, keep running after failures, record metrics, and stop on Stop
+func TestScheduler_RunsJobs(t *testing.T) {
+	var runs, failures atomic.Int32
+	m := metrics.Init()
+	successesBefore := testutil.ToFloat64(m.ScheduledJobRunsTotal.WithLabelValues("counter", "success"))
+	failuresBefore := testutil.ToFloat64(m.ScheduledJobRunsTotal.WithLabelValues("failing", "failure"))
+	s := New(m, zap.NewNop())
+	s.Add("counter", Every(5*time.Millisecond), func(context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	s.Add("failing", Every(5*time.Millisecond), func(context.Context) error {
+		failures.Add(1)
+		return errors.New("job failed")
+	})
+
+	s.Start()
+	deadline := time.Now().Add(2 * time.Second)
+	for (runs.Load() < 3 || failures.Load() < 3) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	s.Stop()
+
+	if runs.Load() < 3 || failures.Load() < 3 {
+		t.Fatalf("runs = %d, failures = %d, want at least 3 each", runs.Load(), failures.Load())
+	}
+	// A run interrupted by Stop isn't counted
+	if got := testutil.ToFloat64(m.ScheduledJobRunsTotal.WithLabelValues("counter", "success")) - successesBefore; got < 3 {
+		t.Errorf("success runs metric = %v, want at least 3", got)
+	}
+	if got := testutil.ToFloat64(m.ScheduledJobRunsTotal.WithLabelValues("failing", "failure")) - failuresBefore; got < 3 {
+		t.Errorf("failure runs metric = %v, want at least 3", got)
+	}
+	if testutil.ToFloat64(m.ScheduledJobLastSuccessTimestamp.WithLabelValues("counter")) == 0 {
+		t.Error("last success timestamp not recorded")
+	}
+
+	stopped := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	if runs.Load() != stopped {
+		t.Error("job ran after Stop")
+	}
+}
+
+// TestScheduler_StopCancelsRunningJob tests that Stop cancels the context of a running job and waits for it
+func TestScheduler_StopCancelsRunningJob(t *testing.T) {
+	started := make(chan struct{})
+	var returned atomic.Bool
+	s := New(nil, zap.NewNop())
+	s.Add("slow", Every(time.Millisecond), func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		returned.Store(true)
+		return ctx.Err()
+	})
+
+	s.Start()
+	<-started
+	s.Stop()
+	if !returned.Load() {
+		t.Error("Stop returned before the running job")
+	}
+}
+
+// TestEveryFunc tests that the interval is read on every call
+func TestEveryFunc(t *testing.T) {
+	var interval atomic.Int64
+	interval.Store(int64(time.Hour))
+	schedule := EveryFunc(func() time.Duration { return time.Duration(interval.Load()) })
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if next := schedule.Next(start); !next.Equal(start.Add(time.Hour)) {
+		t.Errorf("Next() = %v, want %v", next, start.Add(time.Hour))
+	}
+	interval.Store(int64(15 * time.Minute))
+	if next := schedule.Next(start); !next.Equal(start.Add(15 * time.Minute)) {
+		t.Errorf("Next() after change = %v, want %v", next, start.Add(15*time.Minute))
+	}
+}
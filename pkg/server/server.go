@@ -0,0 +1,340 @@
+// Package server owns the bot's HTTP servers: their muxes, the middleware
+// stack of each kind of route and their start and stop.
+//
+// Routes are registered on the Server's own muxes rather than
+// http.DefaultServeMux, so nothing an imported package registers globally is
+// served, and tests can build a Server and call Handler without listening.
+//
+//	srv, err := server.New(cfg, m, logger)
+//	srv.HandleSlack(constants.RouteSlackCommand, handler.HandleSlashCommand, server.RateLimited())
+//	srv.HandleAdmin(constants.RouteAdminConfig, configHandler(cfg))
+//	srv.Start()
+//	defer srv.Shutdown(ctx)
+//
+// Routes are registered without BASE_PATH; Handler strips it. With ADMIN_PORT
+// the admin routes are served on their own mTLS listener instead of PORT.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/messages"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/middleware"
+	"go.uber.org/zap"
+)
+
+// Server serves the public routes on PORT and, with ADMIN_PORT, the admin
+// routes on a separate mTLS listener.
+type Server struct {
+	cfg     *config.Config
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+
+	mux      *http.ServeMux // Public routes, without BASE_PATH
+	adminMux *http.ServeMux // Admin routes; mux unless they have their own listener
+
+	rateLimiter *middleware.RateLimiter // Nil when RATE_LIMIT_PER_MINUTE is 0
+	formLimiter *middleware.RateLimiter // Per client IP, for HandleForm routes
+	catalog     *messages.Catalog
+
+	httpServer  *http.Server
+	adminServer *http.Server // Nil without ADMIN_PORT
+
+	mu        sync.Mutex
+	addr      net.Addr
+	adminAddr net.Addr
+}
+
+// RouteOption adds optional middleware to a Slack route.
+type RouteOption func(*routeOptions)
+
+type routeOptions struct {
+	rateLimited bool
+	compressed  bool
+}
+
+// RateLimited limits the route per Slack user (RATE_LIMIT_PER_MINUTE), counting
+// only requests signed with SLACK_SIGNING_SECRET. Use it on endpoints users
+// trigger; Events API callbacks come from Slack.
+func RateLimited() RouteOption {
+	return func(o *routeOptions) { o.rateLimited = true }
+}
+
+// Compressed gzips responses of at least COMPRESSION_MIN_BYTES.
+func Compressed() RouteOption {
+	return func(o *routeOptions) { o.compressed = true }
+}
+
+// New creates a server for cfg. It fails when the admin mTLS certificates
+// can't be loaded.
+func New(cfg *config.Config, m *metrics.Metrics, logger *zap.Logger) (*Server, error) {
+	s := &Server{
+		cfg:         cfg,
+		metrics:     m,
+		logger:      logger,
+		mux:         http.NewServeMux(),
+		catalog:     messages.Default(),
+		formLimiter: middleware.NewRateLimiter(constants.WebFormRateLimitPerMinute, constants.WebFormRateLimitBurst, m),
+	}
+	s.adminMux = s.mux
+	if cfg.RateLimitPerMinute > 0 {
+		s.rateLimiter = middleware.NewRateLimiter(cfg.RateLimitPerMinute, cfg.RateLimitBurst, m)
+	}
+
+	s.httpServer = &http.Server{
+		Addr:           ":" + cfg.Port,
+		Handler:        s.Handler(),
+		ReadTimeout:    constants.ServerReadTimeout,
+		WriteTimeout:   constants.ServerWriteTimeout,
+		IdleTimeout:    cfg.ServerIdleTimeout,
+		MaxHeaderBytes: cfg.ServerMaxHeaderBytes,
+		// Track connection state transitions to measure keep-alive reuse
+		ConnState: func(_ net.Conn, state http.ConnState) {
+			m.HTTPConnectionStates.WithLabelValues(state.String()).Inc()
+		},
+	}
+	s.httpServer.SetKeepAlivesEnabled(cfg.ServerKeepAlives)
+
+	if cfg.AdminMTLSEnabled() {
+		tlsConfig, err := adminTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.adminMux = http.NewServeMux()
+		s.adminServer = &http.Server{
+			Addr:         ":" + cfg.AdminPort,
+			Handler:      s.AdminHandler(),
+			TLSConfig:    tlsConfig,
+			ReadTimeout:  constants.ServerReadTimeout,
+			WriteTimeout: constants.ServerWriteTimeout,
+			IdleTimeout:  cfg.ServerIdleTimeout,
+		}
+	}
+	return s, nil
+}
+
+// SetMessageCatalog sets the catalog the rate limit message is taken from.
+// Call it before Start; defaults to messages.Default().
+func (s *Server) SetMessageCatalog(catalog *messages.Catalog) {
+	s.catalog = catalog
+}
+
+// rateLimitMessage is the ephemeral Slack message sent with a 429.
+func (s *Server) rateLimitMessage(retryAfter time.Duration) string {
+	return s.catalog.Format(messages.KeyRateLimited, messages.Params{"seconds": int(retryAfter.Seconds())})
+}
+
+// Handle registers a public route without middleware, e.g. /metrics or /health.
+func (s *Server) Handle(route string, handler http.Handler) {
+	s.mux.Handle(route, handler)
+}
+
+// HandleSlack registers a Slack endpoint with logging, a timeout, metrics and
+// panic recovery, plus the middleware opts ask for.
+func (s *Server) HandleSlack(route string, handler http.HandlerFunc, opts ...RouteOption) {
+	var o routeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	middlewares := []func(http.HandlerFunc) http.HandlerFunc{
+		func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithLogging(s.logger, next)
+		},
+		func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithTimeout(constants.HandlerTimeout, s.logger, s.metrics, next)
+		},
+		func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithMetrics(route, s.metrics, next)
+		},
+		func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithRecovery(s.logger, s.metrics, next)
+		},
+	}
+	if o.rateLimited {
+		middlewares = append(middlewares, func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithRateLimit(s.rateLimiter, route, s.cfg.SlackSigningSecret, s.rateLimitMessage, s.logger, next)
+		})
+	}
+	if o.compressed {
+		// Innermost so response size metrics record the compressed body
+		middlewares = append(middlewares, func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithCompression(s.cfg.CompressionMinBytes, next)
+		})
+	}
+	s.mux.HandleFunc(route, middleware.Chain(handler, middlewares...))
+}
+
+// HandleBrowser registers a route opened in a browser, such as the OAuth
+// install flow, with logging, metrics and panic recovery. These carry no Slack
+// signature and get no timeout or rate limit.
+func (s *Server) HandleBrowser(route string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(route, middleware.Chain(
+		handler,
+		func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithLogging(s.logger, next)
+		},
+		func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithMetrics(route, s.metrics, next)
+		},
+		func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithRecovery(s.logger, s.metrics, next)
+		},
+	))
+}
+
+// HandleForm registers a public browser form, such as the web intake form,
+// like HandleBrowser but with its submissions rate limited per client IP
+// (constants.WebFormRateLimitPerMinute) and checked by the CAPTCHA_* bot
+// protection (see middleware.WithCaptcha). Over-limit posts are turned away
+// before they reach the captcha provider.
+func (s *Server) HandleForm(route string, handler http.HandlerFunc) {
+	s.HandleBrowser(route, middleware.WithIPRateLimit(s.formLimiter, route, s.logger,
+		middleware.WithCaptcha(s.cfg.Captcha(), s.logger, handler)))
+}
+
+// HandleAdmin registers an admin route behind ADMIN_TOKEN bearer auth, on the
+// mTLS listener when ADMIN_PORT is set. Without either the route isn't
+// registered at all, so it can never be served unauthenticated.
+func (s *Server) HandleAdmin(route string, handler http.HandlerFunc) {
+	if !s.cfg.AdminEnabled() {
+		return
+	}
+
+	middlewares := []func(http.HandlerFunc) http.HandlerFunc{
+		func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithLogging(s.logger, next)
+		},
+	}
+	// Optional behind mTLS, where the client certificate already authenticates the caller
+	if s.cfg.AdminToken != "" {
+		middlewares = append(middlewares, func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithBearerAuth(s.cfg.AdminToken, next)
+		})
+	}
+	middlewares = append(middlewares,
+		func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithMetrics(route, s.metrics, next)
+		},
+		func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.WithRecovery(s.logger, s.metrics, next)
+		},
+	)
+	s.adminMux.HandleFunc(route, middleware.Chain(handler, middlewares...))
+}
+
+// Handler returns the handler served on PORT: it strips BASE_PATH and gives
+// each request the ID its logs, Notion calls and error messages share.
+func (s *Server) Handler() http.Handler {
+	return middleware.WithRequestID(s.cfg.TrustProxyHeaders, s.logger,
+		middleware.WithProxyHeaders(s.cfg.ProxyHops(),
+			middleware.WithBasePath(s.cfg.BasePath, s.mux.ServeHTTP)))
+}
+
+// AdminHandler returns the handler served on ADMIN_PORT. Its clients connect
+// directly, so proxy headers and BASE_PATH don't apply.
+func (s *Server) AdminHandler() http.Handler {
+	return middleware.WithRequestID(false, s.logger, s.adminMux.ServeHTTP)
+}
+
+// Start listens on PORT (and ADMIN_PORT) and serves in the background. It
+// returns an error when a port can't be bound.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", s.httpServer.Addr, err)
+	}
+
+	var adminListener net.Listener
+	if s.adminServer != nil {
+		adminListener, err = net.Listen("tcp", s.adminServer.Addr)
+		if err != nil {
+			listener.Close()
+			return fmt.Errorf("listening on admin port %s: %w", s.adminServer.Addr, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.addr = listener.Addr()
+	if adminListener != nil {
+		s.adminAddr = adminListener.Addr()
+	}
+	s.mu.Unlock()
+
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.logger.Fatal("server failed", zap.Error(err))
+		}
+	}()
+	if adminListener != nil {
+		s.logger.Info("starting admin server (mTLS)", zap.String("port", s.cfg.AdminPort))
+		go func() {
+			// The certificate is already in TLSConfig
+			if err := s.adminServer.ServeTLS(adminListener, "", ""); err != nil && err != http.ErrServerClosed {
+				s.logger.Fatal("admin server failed", zap.Error(err))
+			}
+		}()
+	}
+	return nil
+}
+
+// Addr returns the address PORT is bound to, or nil before Start. With PORT=0
+// it tells tests which port was picked.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
+
+// AdminAddr returns the address ADMIN_PORT is bound to, or nil before Start or
+// without ADMIN_PORT.
+func (s *Server) AdminAddr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.adminAddr
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to
+// complete, giving up when ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.httpServer.Shutdown(ctx)
+	if s.adminServer != nil {
+		err = errors.Join(err, s.adminServer.Shutdown(ctx))
+	}
+	return err
+}
+
+// adminTLSConfig builds the admin listener's TLS configuration, which
+// requires a client certificate signed by ADMIN_CLIENT_CA_FILE.
+func adminTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(cfg.AdminTLSCertFile, cfg.AdminTLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading ADMIN_TLS_CERT_FILE and ADMIN_TLS_KEY_FILE: %w", err)
+	}
+	caPEM, err := os.ReadFile(cfg.AdminClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading ADMIN_CLIENT_CA_FILE: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("ADMIN_CLIENT_CA_FILE contains no PEM certificates")
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/config"
+	"github.com/rudderlabs/hopperbot/pkg/constants"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/requestid"
+	"go.uber.org/zap"
+)
+
+func newTestServer(t *testing.T, cfg *config.Config) *Server {
+	t.Helper()
+	srv, err := New(cfg, metrics.Init(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return srv
+}
+
+func text(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}
+}
+
+func serve(handler http.Handler, method, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_Routes(t *testing.T) {
+	srv := newTestServer(t, &config.Config{BasePath: "/hopperbot", AdminToken: "secret"})
+	srv.Handle("/health", text("ok"))
+	srv.HandleSlack("/slack/commands", text("command"), RateLimited())
+	srv.HandleBrowser("/slack/install", text("install"))
+	srv.HandleAdmin("/admin/config", text("config"))
+	handler := srv.Handler()
+
+	tests := []struct {
+		name       string
+		target     string
+		header     http.Header
+		wantStatus int
+		wantBody   string
+	}{
+		{"bare route under base path", "/hopperbot/health", nil, http.StatusOK, "ok"},
+		{"slack route", "/hopperbot/slack/commands", nil, http.StatusOK, "command"},
+		{"browser route", "/hopperbot/slack/install", nil, http.StatusOK, "install"},
+		{"outside base path", "/health", nil, http.StatusNotFound, ""},
+		{"unregistered route", "/hopperbot/debug/vars", nil, http.StatusNotFound, ""},
+		{"admin without token", "/hopperbot/admin/config", nil, http.StatusUnauthorized, ""},
+		{"admin with token", "/hopperbot/admin/config", http.Header{"Authorization": {"Bearer secret"}}, http.StatusOK, "config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(handler, http.MethodGet, tt.target, tt.header)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if !requestid.Valid(rec.Header().Get(requestid.Header)) {
+				t.Errorf("%s = %q, want a request ID", requestid.Header, rec.Header().Get(requestid.Header))
+			}
+		})
+	}
+}
+
+func TestServer_DoesNotUseDefaultServeMux(t *testing.T) {
+	http.HandleFunc("/registered-globally", text("global"))
+
+	srv := newTestServer(t, &config.Config{})
+	if rec := serve(srv.Handler(), http.MethodGet, "/registered-globally", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 for a route registered on http.DefaultServeMux", rec.Code)
+	}
+}
+
+func TestServer_AdminDisabled(t *testing.T) {
+	srv := newTestServer(t, &config.Config{})
+	srv.HandleAdmin("/admin/config", text("config"))
+
+	// Without ADMIN_TOKEN or ADMIN_PORT the route must not be served unauthenticated
+	if rec := serve(srv.Handler(), http.MethodGet, "/admin/config", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestServer_AdminMTLSFilesMissing(t *testing.T) {
+	_, err := New(&config.Config{
+		AdminPort:         "0",
+		AdminTLSCertFile:  "/nonexistent/cert.pem",
+		AdminTLSKeyFile:   "/nonexistent/key.pem",
+		AdminClientCAFile: "/nonexistent/ca.pem",
+	}, metrics.Init(), zap.NewNop())
+	if err == nil || !strings.Contains(err.Error(), "ADMIN_TLS_CERT_FILE") {
+		t.Errorf("New() error = %v, want an ADMIN_TLS_CERT_FILE error", err)
+	}
+}
+
+func TestServer_RateLimited(t *testing.T) {
+	srv := newTestServer(t, &config.Config{RateLimitPerMinute: 1, RateLimitBurst: 1, SlackSigningSecret: "signing-secret"})
+	srv.HandleSlack("/slack/commands", text("command"), RateLimited())
+	srv.HandleSlack("/slack/events", text("event"))
+	handler := srv.Handler()
+
+	post := func(target, secret string) *httptest.ResponseRecorder {
+		body := "user_id=U1"
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Forged requests for U1 must not use up U1's requests
+	for i := 0; i < 3; i++ {
+		if rec := post("/slack/commands", "forged"); rec.Code != http.StatusOK {
+			t.Fatalf("forged command %d status = %d, want it passed to the handler", i, rec.Code)
+		}
+	}
+	if rec := post("/slack/commands", "signing-secret"); rec.Code != http.StatusOK {
+		t.Fatalf("first command status = %d, want 200", rec.Code)
+	}
+	rec := post("/slack/commands", "signing-secret")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second command status = %d, want 429", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "ephemeral") {
+		t.Errorf("body = %q, want an ephemeral Slack message", rec.Body.String())
+	}
+	// Routes registered without RateLimited aren't limited
+	for i := 0; i < 3; i++ {
+		if rec := post("/slack/events", "signing-secret"); rec.Code != http.StatusOK {
+			t.Errorf("event %d status = %d, want 200", i, rec.Code)
+		}
+	}
+}
+
+func TestServer_HandleForm(t *testing.T) {
+	verify := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"success":false}`)
+	}))
+	defer verify.Close()
+
+	srv := newTestServer(t, &config.Config{
+		CaptchaProvider:  "turnstile",
+		CaptchaSecret:    "secret",
+		CaptchaVerifyURL: verify.URL,
+	})
+	srv.HandleForm("/web/submit", text("form"))
+	handler := srv.Handler()
+
+	if rec := serve(handler, http.MethodGet, "/web/submit", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET status = %d, want 200 (rendering the form isn't checked)", rec.Code)
+	}
+	if rec := serve(handler, http.MethodPost, "/web/submit", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("POST without a token status = %d, want 403", rec.Code)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/web/submit", strings.NewReader("cf-turnstile-response=token"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("POST with a rejected token status = %d, want 403", rec.Code)
+	}
+
+	// Posts from one client IP are limited before reaching the captcha check
+	var status int
+	for range constants.WebFormRateLimitBurst {
+		status = serve(handler, http.MethodPost, "/web/submit", nil).Code
+	}
+	if status != http.StatusTooManyRequests {
+		t.Errorf("POST over the per-IP limit status = %d, want 429", status)
+	}
+}
+
+func TestServer_Compressed(t *testing.T) {
+	body := strings.Repeat("option ", 200)
+	srv := newTestServer(t, &config.Config{CompressionMinBytes: 100})
+	srv.HandleSlack("/slack/options", text(body), Compressed())
+	srv.HandleSlack("/slack/commands", text(body))
+	handler := srv.Handler()
+
+	gzip := http.Header{"Accept-Encoding": {"gzip"}}
+	if rec := serve(handler, http.MethodPost, "/slack/options", gzip); rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("options Content-Encoding = %q, want gzip", rec.Header().Get("Content-Encoding"))
+	}
+	if rec := serve(handler, http.MethodPost, "/slack/commands", gzip); rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("commands Content-Encoding = %q, want none", rec.Header().Get("Content-Encoding"))
+	}
+}
+
+func TestServer_StartShutdown(t *testing.T) {
+	srv := newTestServer(t, &config.Config{Port: "0", ServerKeepAlives: true})
+	srv.Handle("/health", text("ok"))
+
+	if srv.Addr() != nil {
+		t.Errorf("Addr() before Start = %v, want nil", srv.Addr())
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/health", srv.Addr()))
+	if err != nil {
+		t.Fatalf("GET /health error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Errorf("GET /health = %d %q, want 200 \"ok\"", resp.StatusCode, body)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if _, err := http.Get(fmt.Sprintf("http://%s/health", srv.Addr())); err == nil {
+		t.Error("GET /health after Shutdown succeeded, want a connection error")
+	}
+}
+
+func TestServer_StartPortInUse(t *testing.T) {
+	first := newTestServer(t, &config.Config{Port: "0"})
+	if err := first.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer first.Shutdown(context.Background())
+
+	port := strconv.Itoa(first.Addr().(*net.TCPAddr).Port)
+	second := newTestServer(t, &config.Config{Port: port})
+	if err := second.Start(); err == nil {
+		second.Shutdown(context.Background())
+		t.Error("Start() on a bound port succeeded, want an error")
+	}
+}
@@ -0,0 +1,264 @@
+// Package statuswatch tells submitters when the product team moves their idea
+// to a new status, closing the loop between triage in Notion and Slack.
+//
+// Each submitted idea is recorded in a Store with its submitter. The Watcher's
+// RunCheck, run periodically by pkg/scheduler, polls the status of watched
+// ideas through a StatusFunc supplied by the Slack handler and, when it
+// changed since the last poll, calls a NotifyFunc that DMs the submitter.
+//
+// Features:
+// - JSON file-backed store so watched ideas survive restarts (memory-only when no path is set)
+// - The first status seen is a baseline, so a default status set by Notion isn't reported
+// - A failed notification is retried on the next poll
+// - Ideas stop being watched once they reach a closed status, are deleted, or after MaxWatchAge
+// - Ideas are polled concurrently on a pkg/workers pool when one is set
+// - A check stops early when its context is cancelled
+package statuswatch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/jsonfile"
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"github.com/rudderlabs/hopperbot/pkg/workers"
+	"go.uber.org/zap"
+)
+
+// MaxWatchAge is how long an idea's status is watched after submission.
+const MaxWatchAge = 180 * 24 * time.Hour
+
+// Idea is a submitted idea whose status changes are reported to its submitter.
+type Idea struct {
+	PageID      string    `json:"page_id"`
+	PageURL     string    `json:"page_url"`
+	Title       string    `json:"title"`
+	SlackTeamID string    `json:"slack_team_id,omitempty"` // Selects the workspace and tenant database
+	SlackUserID string    `json:"slack_user_id"`           // Submitter to DM
+	SubmittedAt time.Time `json:"submitted_at"`
+
+	// Status is the last status reported (or the baseline); Observed is false
+	// until the first successful poll.
+	Status   string `json:"status,omitempty"`
+	Observed bool   `json:"observed,omitempty"`
+}
+
+// Store persists watched ideas.
+type Store interface {
+	// Save inserts or replaces an idea (keyed by page ID).
+	Save(idea Idea) error
+
+	// Delete removes an idea. Deleting an unknown idea is not an error.
+	Delete(pageID string) error
+
+	// List returns all ideas, oldest submission first (then by page ID).
+	List() ([]Idea, error)
+}
+
+// FileStore is a Store holding watched ideas by page ID in a jsonfile.Map.
+// With an empty path the store is memory-only and ideas submitted before a
+// restart are no longer watched.
+type FileStore struct {
+	*jsonfile.Map[string, Idea]
+}
+
+// NewFileStore creates a store backed by path, loading any ideas already saved there.
+// A missing file is treated as empty; an empty path creates a memory-only store.
+func NewFileStore(path string) (*FileStore, error) {
+	ideas, err := jsonfile.NewMap(path, "status watch file",
+		func(idea Idea) string { return idea.PageID },
+		func(a, b Idea) bool {
+			if !a.SubmittedAt.Equal(b.SubmittedAt) {
+				return a.SubmittedAt.Before(b.SubmittedAt)
+			}
+			return a.PageID < b.PageID
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{ideas}, nil
+}
+
+// Save inserts or replaces an idea.
+func (s *FileStore) Save(idea Idea) error {
+	if idea.PageID == "" || idea.SlackUserID == "" {
+		return fmt.Errorf("page ID and Slack user ID are required")
+	}
+	return s.Put(idea)
+}
+
+// List returns all ideas, oldest submission first.
+func (s *FileStore) List() ([]Idea, error) {
+	return s.Values(), nil
+}
+
+// Status is an idea's current state in Notion.
+type Status struct {
+	Value    string // Status property value; empty when not triaged
+	Archived bool   // Page was deleted
+}
+
+// StatusFunc looks up an idea's current status (e.g., from Notion).
+type StatusFunc func(ctx context.Context, idea Idea) (Status, error)
+
+// NotifyFunc tells the submitter that their idea moved from previous (empty
+// when it had no status) to idea.Status. Returning an error retries the
+// notification on the next poll.
+type NotifyFunc func(ctx context.Context, idea Idea, previous string) error
+
+// Watcher polls watched ideas' status and reports changes.
+type Watcher struct {
+	store   Store
+	status  StatusFunc
+	notify  NotifyFunc
+	closed  map[string]bool // Lowercased closed statuses
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+	pool    *workers.Pool    // Polls ideas concurrently; nil polls them one at a time
+	now     func() time.Time // Overridable for tests
+}
+
+// NewWatcher creates a watcher. Schedule RunCheck to poll watched ideas.
+// closedStatuses are the status values after which an idea is no longer watched
+// (matched case-insensitively). metrics may be nil to disable metrics recording.
+func NewWatcher(store Store, status StatusFunc, notify NotifyFunc, closedStatuses []string, m *metrics.Metrics, logger *zap.Logger) *Watcher {
+	closed := make(map[string]bool, len(closedStatuses))
+	for _, value := range closedStatuses {
+		closed[strings.ToLower(strings.TrimSpace(value))] = true
+	}
+
+	return &Watcher{
+		store:   store,
+		status:  status,
+		notify:  notify,
+		closed:  closed,
+		metrics: m,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// SetPool polls watched ideas on pool instead of one at a time.
+func (w *Watcher) SetPool(pool *workers.Pool) {
+	w.pool = pool
+}
+
+// Watch records a newly submitted idea.
+func (w *Watcher) Watch(idea Idea) error {
+	if idea.SubmittedAt.IsZero() {
+		idea.SubmittedAt = w.now().UTC()
+	}
+	return w.store.Save(idea)
+}
+
+// RunCheck polls the status of every watched idea, notifying submitters of
+// changes and dropping ideas that no longer need watching. Ideas whose status
+// can't be read are retried on the next check; the error is only returned if
+// the watched ideas can't be loaded.
+func (w *Watcher) RunCheck(ctx context.Context) error {
+	ideas, err := w.store.List()
+	if err != nil {
+		return fmt.Errorf("failed to load watched ideas: %w", err)
+	}
+
+	now := w.now()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for _, idea := range ideas {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if now.Sub(idea.SubmittedAt) > MaxWatchAge {
+			w.drop(idea, "expired")
+			continue
+		}
+
+		if w.pool == nil {
+			w.check(ctx, idea)
+			continue
+		}
+		wg.Add(1)
+		err := w.pool.Submit(ctx, "status_check", func() {
+			defer wg.Done()
+			w.check(ctx, idea)
+		})
+		if err != nil {
+			wg.Done()
+			return fmt.Errorf("failed to schedule status check: %w", err)
+		}
+	}
+	return nil
+}
+
+// check polls a watched idea's status and applies it.
+func (w *Watcher) check(ctx context.Context, idea Idea) {
+	status, err := w.status(ctx, idea)
+	if err != nil {
+		w.logger.Warn("failed to check idea status, will retry", zap.String("page_id", idea.PageID), zap.Error(err))
+		return
+	}
+	w.observe(ctx, idea, status)
+}
+
+// observe applies a status lookup to a watched idea.
+func (w *Watcher) observe(ctx context.Context, idea Idea, status Status) {
+	if status.Archived {
+		w.drop(idea, "removed")
+		return
+	}
+
+	value := strings.TrimSpace(status.Value)
+	previous := idea.Status
+	if idea.Observed && value == previous {
+		return
+	}
+
+	idea.Status = value
+	if !idea.Observed {
+		// Baseline: the status the page had when first seen (e.g. a default set by Notion)
+		idea.Observed = true
+	} else if value != "" {
+		if err := w.notify(ctx, idea, previous); err != nil {
+			w.logger.Warn("failed to notify status change, will retry",
+				zap.String("page_id", idea.PageID),
+				zap.String("status", value),
+				zap.Error(err),
+			)
+			w.recordNotification("failed")
+			return
+		}
+		w.logger.Info("notified submitter of status change",
+			zap.String("page_id", idea.PageID),
+			zap.String("previous", previous),
+			zap.String("status", value),
+		)
+		w.recordNotification("sent")
+	}
+
+	if w.closed[strings.ToLower(value)] {
+		w.drop(idea, "closed")
+		return
+	}
+	if err := w.store.Save(idea); err != nil {
+		w.logger.Error("failed to save watched idea status", zap.String("page_id", idea.PageID), zap.Error(err))
+	}
+}
+
+// drop stops watching an idea.
+func (w *Watcher) drop(idea Idea, reason string) {
+	if err := w.store.Delete(idea.PageID); err != nil {
+		w.logger.Error("failed to stop watching idea", zap.String("page_id", idea.PageID), zap.Error(err))
+		return
+	}
+	w.logger.Debug("stopped watching idea", zap.String("page_id", idea.PageID), zap.String("reason", reason))
+}
+
+// recordNotification counts a status change notification by outcome.
+func (w *Watcher) recordNotification(status string) {
+	if w.metrics != nil {
+		w.metrics.StatusNotificationsTotal.WithLabelValues(status).Inc()
+	}
+}
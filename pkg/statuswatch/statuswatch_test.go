@@ -0,0 +1,167 @@
+package statuswatch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/workers"
+	"go.uber.org/zap"
+)
+
+var submitted = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+// TestFileStore_Persistence tests that watched ideas survive reopening the store
+func TestFileStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status-watch.json")
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	for _, idea := range []Idea{
+		{PageID: "later", SlackUserID: "U1", SubmittedAt: submitted.Add(time.Hour)},
+		{PageID: "earlier", SlackUserID: "U1", SubmittedAt: submitted, Status: "Planned", Observed: true},
+		{PageID: "deleted", SlackUserID: "U1", SubmittedAt: submitted},
+	} {
+		if err := store.Save(idea); err != nil {
+			t.Fatalf("Save(%s) error = %v", idea.PageID, err)
+		}
+	}
+	if err := store.Save(Idea{PageID: "page"}); err == nil {
+		t.Error("expected error for idea without submitter")
+	}
+	if err := store.Delete("deleted"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopening store: %v", err)
+	}
+	ideas, _ := reopened.List()
+	if len(ideas) != 2 || ideas[0].PageID != "earlier" || ideas[1].PageID != "later" {
+		t.Fatalf("List() = %+v, want [earlier later]", ideas)
+	}
+	if ideas[0].Status != "Planned" || !ideas[0].Observed {
+		t.Errorf("earlier = %+v, want its observed status", ideas[0])
+	}
+}
+
+// TestWatcher_RunCheck tests baselines, notifications, retries and when ideas stop being watched
+func TestWatcher_RunCheck(t *testing.T) {
+	store, _ := NewFileStore("")
+	statuses := map[string]Status{
+		"defaulted": {Value: "Not started"}, // First seen: a baseline, not a change
+		"triaged":   {Value: "In Review"},
+		"shipped":   {Value: "shipped"}, // Closed statuses match case-insensitively
+		"deleted":   {Archived: true},
+		"flaky":     {Value: "Planned"},
+	}
+	status := func(_ context.Context, idea Idea) (Status, error) {
+		return statuses[idea.PageID], nil
+	}
+	var notified []string
+	failNotify := true
+	notify := func(_ context.Context, idea Idea, previous string) error {
+		if idea.PageID == "flaky" && failNotify {
+			return errors.New("slack unavailable")
+		}
+		notified = append(notified, idea.PageID+": "+previous+" -> "+idea.Status)
+		return nil
+	}
+
+	watcher := NewWatcher(store, status, notify, []string{"Shipped"}, nil, zap.NewNop())
+	watcher.now = func() time.Time { return submitted.Add(24 * time.Hour) }
+	for _, idea := range []Idea{
+		{PageID: "defaulted"},
+		{PageID: "triaged", Observed: true},
+		{PageID: "shipped", Status: "In Review", Observed: true},
+		{PageID: "deleted", Observed: true},
+		{PageID: "flaky", Observed: true},
+		{PageID: "expired", SubmittedAt: submitted.Add(-MaxWatchAge)},
+	} {
+		idea.SlackUserID = "U1"
+		if idea.SubmittedAt.IsZero() {
+			idea.SubmittedAt = submitted
+		}
+		if err := watcher.Watch(idea); err != nil {
+			t.Fatalf("Watch(%s) error = %v", idea.PageID, err)
+		}
+	}
+
+	if err := watcher.RunCheck(context.Background()); err != nil {
+		t.Fatalf("RunCheck() error = %v", err)
+	}
+	if len(notified) != 2 || notified[0] != "shipped: In Review -> shipped" || notified[1] != "triaged:  -> In Review" {
+		t.Fatalf("notified = %q, want triaged and shipped", notified)
+	}
+	ideas, _ := store.List()
+	var watched []string
+	for _, idea := range ideas {
+		watched = append(watched, idea.PageID)
+	}
+	if len(watched) != 3 || watched[0] != "defaulted" || watched[1] != "flaky" || watched[2] != "triaged" {
+		t.Fatalf("watched = %v, want closed, deleted and expired ideas dropped", watched)
+	}
+
+	// Unchanged statuses aren't reported again; the failed notification is retried
+	failNotify = false
+	statuses["defaulted"] = Status{Value: "Planned"}
+	notified = nil
+	if err := watcher.RunCheck(context.Background()); err != nil {
+		t.Fatalf("RunCheck() error = %v", err)
+	}
+	if len(notified) != 2 || notified[0] != "defaulted: Not started -> Planned" || notified[1] != "flaky:  -> Planned" {
+		t.Errorf("notified = %q, want defaulted and the retried flaky", notified)
+	}
+}
+
+// TestWatcher_RunCheckPool tests that ideas are polled concurrently on a pool
+// and that RunCheck waits for every poll
+func TestWatcher_RunCheckPool(t *testing.T) {
+	store, _ := NewFileStore("")
+	pool := workers.New("test", 3, 10, nil, zap.NewNop())
+	defer pool.Stop()
+
+	// Each poll waits until all three are running, so they can only finish concurrently
+	var started sync.WaitGroup
+	started.Add(3)
+	status := func(_ context.Context, idea Idea) (Status, error) {
+		started.Done()
+		started.Wait()
+		return Status{Value: "Planned"}, nil
+	}
+	var mu sync.Mutex
+	var notified []string
+	notify := func(_ context.Context, idea Idea, _ string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		notified = append(notified, idea.PageID)
+		return nil
+	}
+
+	watcher := NewWatcher(store, status, notify, nil, nil, zap.NewNop())
+	watcher.SetPool(pool)
+	watcher.now = func() time.Time { return submitted.Add(time.Hour) }
+	for _, id := range []string{"a", "b", "c"} {
+		watcher.Watch(Idea{PageID: id, SlackUserID: "U1", SubmittedAt: submitted, Observed: true})
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- watcher.RunCheck(context.Background()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("RunCheck() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunCheck() did not poll the ideas concurrently")
+	}
+	if len(notified) != 3 {
+		t.Errorf("notified = %v, want every idea by the time RunCheck returns", notified)
+	}
+}
@@ -0,0 +1,225 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/jsonfile"
+)
+
+// FileStore is a Store kept in memory and mirrored to a JSON Lines file.
+//
+// Each attempt is appended to the file as one line, so recording never
+// rewrites the log; only Prune does, atomically with jsonfile.Write. A torn
+// last line, left by a crash mid-write, is dropped when loading. With an empty
+// path the store is memory-only and the log is lost on restart.
+type FileStore struct {
+	path     string
+	mu       sync.Mutex
+	attempts []Attempt // In the order recorded
+}
+
+// NewFileStore creates a store backed by path, loading any attempts already
+// recorded there. A missing file is treated as empty; an empty path creates a
+// memory-only store.
+func NewFileStore(path string) (*FileStore, error) {
+	store := &FileStore{path: path}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	// Drop a torn last line so the next attempt starts on a line of its own
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		var attempt Attempt
+		if json.Unmarshal(data[end:], &attempt) != nil {
+			if err := os.Truncate(path, int64(end)); err != nil {
+				return nil, fmt.Errorf("failed to drop torn audit log line: %w", err)
+			}
+			data = data[:end]
+		}
+	}
+
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var attempt Attempt
+		if err := json.Unmarshal(line, &attempt); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log %s line %d: %w", path, i+1, err)
+		}
+		store.attempts = append(store.attempts, attempt)
+	}
+	return store, nil
+}
+
+// Record appends an attempt to the log.
+func (s *FileStore) Record(attempt Attempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts = append(s.attempts, attempt)
+	if s.path == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(attempt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal attempt: %w", err)
+	}
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to append to audit log: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to append to audit log: %w", err)
+	}
+	return nil
+}
+
+// Ideas returns the user's created ideas, newest first, titled by their
+// latest successful edit.
+func (s *FileStore) Ideas(teamID, userID string, limit int) ([]Idea, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ideas []Idea
+	index := make(map[string]int) // Page ID -> position in ideas
+	for _, a := range s.attempts {
+		if a.Outcome != OutcomeSuccess || a.PageID == "" {
+			continue
+		}
+		i, seen := index[a.PageID]
+		switch {
+		case a.Action == ActionCreate && !seen && a.TeamID == teamID && a.UserID == userID:
+			index[a.PageID] = len(ideas)
+			ideas = append(ideas, Idea{PageID: a.PageID, PageURL: a.PageURL, Title: a.Title, CreatedAt: a.Time})
+		case a.Action == ActionEdit && seen:
+			ideas[i].Title = a.Title
+			if a.PageURL != "" {
+				ideas[i].PageURL = a.PageURL
+			}
+		}
+	}
+
+	slices.SortStableFunc(ideas, func(a, b Idea) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	total := len(ideas)
+	if limit >= 0 && len(ideas) > limit {
+		ideas = ideas[:limit]
+	}
+	return ideas, total, nil
+}
+
+// Duplicate returns the user's latest successful creation with the hash.
+func (s *FileStore) Duplicate(teamID, userID, fieldsHash string, since time.Time) (Attempt, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range slices.Backward(s.attempts) {
+		if a.Action == ActionCreate && a.Outcome == OutcomeSuccess && a.TeamID == teamID && a.UserID == userID &&
+			a.FieldsHash == fieldsHash && !a.Time.Before(since) {
+			return a, true, nil
+		}
+	}
+	return Attempt{}, false, nil
+}
+
+// Stats summarizes the workspace's attempts since the given time.
+func (s *FileStore) Stats(teamID string, since time.Time) (Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stats Stats
+	var latency time.Duration
+	submitters := make(map[string]bool)
+	for _, a := range s.attempts {
+		if a.TeamID != teamID || a.Time.Before(since) {
+			continue
+		}
+		stats.Attempts++
+		latency += a.Latency
+		switch {
+		case a.Outcome != OutcomeSuccess:
+			stats.Failed++
+		case a.Action == ActionCreate:
+			stats.Created++
+			submitters[a.UserID] = true
+		case a.Action == ActionEdit:
+			stats.Edited++
+		}
+	}
+	stats.Submitters = len(submitters)
+	if stats.Attempts > 0 {
+		stats.AvgLatency = latency / time.Duration(stats.Attempts)
+	}
+	return stats, nil
+}
+
+// Prune deletes attempts made before the given time, rewriting the file.
+func (s *FileStore) Prune(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := slices.DeleteFunc(slices.Clone(s.attempts), func(a Attempt) bool {
+		return a.Time.Before(before)
+	})
+	pruned := len(s.attempts) - len(kept)
+	if pruned == 0 {
+		return 0, nil
+	}
+	if err := s.rewrite(kept); err != nil {
+		return 0, err
+	}
+	s.attempts = kept
+	return pruned, nil
+}
+
+// Len returns the number of recorded attempts.
+func (s *FileStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.attempts)
+}
+
+// rewrite replaces the file with the given attempts. Caller must hold s.mu.
+func (s *FileStore) rewrite(attempts []Attempt) error {
+	if s.path == "" {
+		return nil
+	}
+
+	err := jsonfile.Write(s.path, func(file io.Writer) error {
+		w := bufio.NewWriter(file)
+		encoder := json.NewEncoder(w)
+		for _, attempt := range attempts {
+			if err := encoder.Encode(attempt); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rewrite audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/pgstore"
+)
+
+// postgresTimeout bounds each statement; the log is read while answering
+// slash commands, which Slack gives three seconds.
+const postgresTimeout = 2 * time.Second
+
+// DB runs statements and queries. *pgstore.Client implements it.
+type DB interface {
+	Exec(ctx context.Context, query string, args ...any) (int64, error)
+	Query(ctx context.Context, query string, args ...any) ([][]string, error)
+}
+
+// PostgresStore is a Store keeping one row per attempt in a Postgres table,
+// created with its index on first use if missing.
+type PostgresStore struct {
+	db    DB
+	table string // Validated identifier (see config)
+
+	mu      sync.Mutex
+	created bool // The table was created (or found) by this process
+}
+
+// NewPostgresStore creates a store using table through db.
+func NewPostgresStore(db DB, table string) *PostgresStore {
+	return &PostgresStore{db: db, table: table}
+}
+
+// Record inserts the attempt's row.
+func (s *PostgresStore) Record(attempt Attempt) error {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+	if err := s.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(ctx, `INSERT INTO "`+s.table+`" (attempted_at, team_id, user_id, channel, action, title, fields_hash, outcome, page_id, page_url, latency_ms, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		attempt.Time,
+		attempt.TeamID,
+		attempt.UserID,
+		attempt.Channel,
+		attempt.Action,
+		attempt.Title,
+		attempt.FieldsHash,
+		attempt.Outcome,
+		attempt.PageID,
+		attempt.PageURL,
+		attempt.Latency.Milliseconds(),
+		attempt.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record attempt: %w", err)
+	}
+	return nil
+}
+
+// Ideas returns the user's created ideas, newest first, titled by their
+// latest successful edit.
+func (s *PostgresStore) Ideas(teamID, userID string, limit int) ([]Idea, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+	if err := s.ensureTable(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT c.page_id, COALESCE(NULLIF(e.page_url, ''), c.page_url), COALESCE(e.title, c.title), c.attempted_at, count(*) OVER ()
+FROM "`+s.table+`" c
+LEFT JOIN LATERAL (
+  SELECT page_url, title FROM "`+s.table+`"
+  WHERE page_id = c.page_id AND action = $4 AND outcome = $5
+  ORDER BY attempted_at DESC LIMIT 1
+) e ON true
+WHERE c.team_id = $1 AND c.user_id = $2 AND c.action = $3 AND c.outcome = $5 AND c.page_id <> ''
+ORDER BY c.attempted_at DESC
+LIMIT $6`, teamID, userID, ActionCreate, ActionEdit, OutcomeSuccess, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query ideas: %w", err)
+	}
+
+	var ideas []Idea
+	var total int
+	for _, row := range rows {
+		if len(row) != 5 {
+			return nil, 0, fmt.Errorf("failed to query ideas: got %d columns, want 5", len(row))
+		}
+		created, err := pgstore.ParseTime(row[3])
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to parse idea creation time: %w", err)
+		}
+		if total, err = strconv.Atoi(row[4]); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse idea count: %w", err)
+		}
+		ideas = append(ideas, Idea{PageID: row[0], PageURL: row[1], Title: row[2], CreatedAt: created})
+	}
+	return ideas, total, nil
+}
+
+// Duplicate returns the user's latest successful creation with the hash.
+func (s *PostgresStore) Duplicate(teamID, userID, fieldsHash string, since time.Time) (Attempt, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+	if err := s.ensureTable(ctx); err != nil {
+		return Attempt{}, false, err
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT attempted_at, channel, title, page_id, page_url, latency_ms FROM "`+s.table+`"
+WHERE team_id = $1 AND user_id = $2 AND fields_hash = $3 AND action = $4 AND outcome = $5 AND attempted_at >= $6
+ORDER BY attempted_at DESC LIMIT 1`, teamID, userID, fieldsHash, ActionCreate, OutcomeSuccess, since)
+	if err != nil {
+		return Attempt{}, false, fmt.Errorf("failed to query duplicates: %w", err)
+	}
+	if len(rows) == 0 {
+		return Attempt{}, false, nil
+	}
+	row := rows[0]
+	if len(row) != 6 {
+		return Attempt{}, false, fmt.Errorf("failed to query duplicates: got %d columns, want 6", len(row))
+	}
+	attempted, err := pgstore.ParseTime(row[0])
+	if err != nil {
+		return Attempt{}, false, fmt.Errorf("failed to parse attempt time: %w", err)
+	}
+	latency, _ := strconv.ParseInt(row[5], 10, 64)
+	return Attempt{
+		Time:       attempted,
+		TeamID:     teamID,
+		UserID:     userID,
+		Channel:    row[1],
+		Action:     ActionCreate,
+		Title:      row[2],
+		FieldsHash: fieldsHash,
+		Outcome:    OutcomeSuccess,
+		PageID:     row[3],
+		PageURL:    row[4],
+		Latency:    time.Duration(latency) * time.Millisecond,
+	}, true, nil
+}
+
+// Stats summarizes the workspace's attempts since the given time.
+func (s *PostgresStore) Stats(teamID string, since time.Time) (Stats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+	if err := s.ensureTable(ctx); err != nil {
+		return Stats{}, err
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT count(*),
+  count(*) FILTER (WHERE outcome = $3 AND action = $4),
+  count(*) FILTER (WHERE outcome = $3 AND action = $5),
+  count(*) FILTER (WHERE outcome <> $3),
+  count(DISTINCT user_id) FILTER (WHERE outcome = $3 AND action = $4),
+  COALESCE(round(avg(latency_ms)), 0)::bigint
+FROM "`+s.table+`" WHERE team_id = $1 AND attempted_at >= $2`, teamID, since, OutcomeSuccess, ActionCreate, ActionEdit)
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to query stats: %w", err)
+	}
+	if len(rows) != 1 || len(rows[0]) != 6 {
+		return Stats{}, fmt.Errorf("failed to query stats: unexpected result %q", rows)
+	}
+
+	var values [6]int64
+	for i, column := range rows[0] {
+		if values[i], err = strconv.ParseInt(column, 10, 64); err != nil {
+			return Stats{}, fmt.Errorf("failed to parse stats: %w", err)
+		}
+	}
+	return Stats{
+		Attempts:   int(values[0]),
+		Created:    int(values[1]),
+		Edited:     int(values[2]),
+		Failed:     int(values[3]),
+		Submitters: int(values[4]),
+		AvgLatency: time.Duration(values[5]) * time.Millisecond,
+	}, nil
+}
+
+// Prune deletes attempts made before the given time.
+func (s *PostgresStore) Prune(before time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+	if err := s.ensureTable(ctx); err != nil {
+		return 0, err
+	}
+
+	deleted, err := s.db.Exec(ctx, `DELETE FROM "`+s.table+`" WHERE attempted_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune audit log: %w", err)
+	}
+	return int(deleted), nil
+}
+
+// ensureTable creates the table and its index on first use.
+func (s *PostgresStore) ensureTable(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.created {
+		return nil
+	}
+	for _, statement := range []string{s.createStatement(), s.indexStatement()} {
+		if _, err := s.db.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create audit log table %s: %w", s.table, err)
+		}
+	}
+	s.created = true
+	return nil
+}
+
+func (s *PostgresStore) createStatement() string {
+	return `CREATE TABLE IF NOT EXISTS "` + s.table + `" (
+  id bigserial PRIMARY KEY,
+  attempted_at timestamptz NOT NULL,
+  team_id text NOT NULL,
+  user_id text NOT NULL,
+  channel text NOT NULL,
+  action text NOT NULL,
+  title text NOT NULL,
+  fields_hash text NOT NULL,
+  outcome text NOT NULL,
+  page_id text NOT NULL,
+  page_url text NOT NULL,
+  latency_ms bigint NOT NULL,
+  error text NOT NULL
+)`
+}
+
+// indexStatement indexes the per-user lookups (Ideas and Duplicate).
+func (s *PostgresStore) indexStatement() string {
+	return `CREATE INDEX IF NOT EXISTS "` + s.table + `_user_idx" ON "` + s.table + `" (team_id, user_id, attempted_at)`
+}
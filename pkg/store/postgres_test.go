@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDB records statements, answers queries with rows, and fails while err is set.
+type fakeDB struct {
+	err        error
+	rows       [][]string
+	statements []string
+	args       [][]any
+}
+
+func (db *fakeDB) Exec(_ context.Context, query string, args ...any) (int64, error) {
+	if db.err != nil {
+		return 0, db.err
+	}
+	db.statements = append(db.statements, query)
+	db.args = append(db.args, args)
+	return 3, nil
+}
+
+func (db *fakeDB) Query(ctx context.Context, query string, args ...any) ([][]string, error) {
+	if _, err := db.Exec(ctx, query, args...); err != nil {
+		return nil, err
+	}
+	return db.rows, nil
+}
+
+func TestPostgresStore_Record(t *testing.T) {
+	db := &fakeDB{}
+	store := NewPostgresStore(db, "audit_log")
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for range 2 {
+		if err := store.Record(attempt(now, "U1", ActionCreate, OutcomeSuccess, "page-1", "Dark mode")); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	// The table and index are created once
+	if len(db.statements) != 4 {
+		t.Fatalf("statements = %d, want CREATE TABLE, CREATE INDEX and two inserts", len(db.statements))
+	}
+	if !strings.HasPrefix(db.statements[0], `CREATE TABLE IF NOT EXISTS "audit_log"`) ||
+		!strings.HasPrefix(db.statements[1], `CREATE INDEX IF NOT EXISTS "audit_log_user_idx"`) {
+		t.Errorf("setup statements = %q", db.statements[:2])
+	}
+	if !strings.HasPrefix(db.statements[2], `INSERT INTO "audit_log"`) {
+		t.Errorf("insert = %q", db.statements[2])
+	}
+	args := db.args[2]
+	if args[1] != "T1" || args[2] != "U1" || args[4] != ActionCreate || args[8] != "page-1" || args[10] != int64(1000) {
+		t.Errorf("insert args = %v", args)
+	}
+}
+
+func TestPostgresStore_Queries(t *testing.T) {
+	db := &fakeDB{}
+	store := NewPostgresStore(db, "audit_log")
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	db.rows = [][]string{
+		{"page-3", "https://www.notion.so/page-3", "CSV export", "2026-05-01 12:03:00+00", "7"},
+		{"page-1", "https://www.notion.so/page-1", "Dark mode everywhere", "2026-05-01 12:00:00+00", "7"},
+	}
+	ideas, total, err := store.Ideas("T1", "U1", 2)
+	if err != nil {
+		t.Fatalf("Ideas() error = %v", err)
+	}
+	if total != 7 || len(ideas) != 2 || ideas[1].Title != "Dark mode everywhere" || !ideas[1].CreatedAt.Equal(now) {
+		t.Errorf("Ideas() = %+v, %d", ideas, total)
+	}
+	if query := db.statements[len(db.statements)-1]; !strings.Contains(query, "count(*) OVER ()") || !strings.Contains(query, "LEFT JOIN LATERAL") {
+		t.Errorf("ideas query = %q", query)
+	}
+
+	db.rows = nil
+	if ideas, total, err := store.Ideas("T1", "U2", 10); err != nil || total != 0 || len(ideas) != 0 {
+		t.Errorf("Ideas() without rows = %+v, %d, %v", ideas, total, err)
+	}
+
+	db.rows = [][]string{{"2026-05-01 12:00:00+00", "slack_modal", "Dark mode", "page-1", "https://www.notion.so/page-1", "850"}}
+	dup, found, err := store.Duplicate("T1", "U1", "hash", now.Add(-time.Hour))
+	if err != nil || !found || dup.PageID != "page-1" || dup.Latency != 850*time.Millisecond || !dup.Time.Equal(now) {
+		t.Errorf("Duplicate() = %+v, %v, %v", dup, found, err)
+	}
+
+	db.rows = [][]string{{"4", "2", "1", "1", "2", "2000"}}
+	stats, err := store.Stats("T1", now.Add(-time.Hour))
+	if want := (Stats{Attempts: 4, Created: 2, Edited: 1, Failed: 1, Submitters: 2, AvgLatency: 2 * time.Second}); err != nil || stats != want {
+		t.Errorf("Stats() = %+v, %v, want %+v", stats, err, want)
+	}
+
+	if pruned, err := store.Prune(now); err != nil || pruned != 3 {
+		t.Errorf("Prune() = %d, %v, want 3", pruned, err)
+	}
+}
+
+func TestPostgresStore_Errors(t *testing.T) {
+	db := &fakeDB{err: errors.New("connection refused")}
+	store := NewPostgresStore(db, "audit_log")
+
+	if err := store.Record(Attempt{}); err == nil || !strings.Contains(err.Error(), "create audit log table") {
+		t.Errorf("Record() error = %v, want a table creation error", err)
+	}
+
+	// Creation is retried by the next call
+	db.err = nil
+	db.rows = [][]string{{"not a number", "0", "0", "0", "0", "0"}}
+	if _, err := store.Stats("T1", time.Time{}); err == nil {
+		t.Error("Stats() with a malformed row error = nil, want error")
+	}
+	if !strings.HasPrefix(db.statements[0], "CREATE TABLE") {
+		t.Errorf("statements = %q, want the table created first", db.statements)
+	}
+}
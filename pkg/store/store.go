@@ -0,0 +1,112 @@
+// Package store keeps an audit log of submission attempts.
+//
+// Every time the bot asks the backend to create or edit an idea, an Attempt
+// is recorded: who made it, a hash of the submitted fields, the outcome, the
+// resulting page and how long the backend took. The log answers "what did
+// this user submit and when" without querying Notion, so it powers
+// /hopperbot list and the audit numbers in /hopperbot stats, and it catches a
+// user submitting the same idea twice in a row.
+//
+// Features:
+// - JSON Lines file store, appended to on every attempt (AUDIT_LOG_BACKEND=file)
+// - Postgres store, one row per attempt in a table created on first use (AUDIT_LOG_BACKEND=postgres)
+// - Attempts older than the retention period are pruned by a scheduled job
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+)
+
+// Actions an attempt can record.
+const (
+	ActionCreate = "create" // A new idea
+	ActionEdit   = "edit"   // An edit of an existing idea
+)
+
+// Outcomes of an attempt.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Attempt is one request to the backend to create or edit an idea.
+type Attempt struct {
+	Time       time.Time     `json:"time"`
+	TeamID     string        `json:"team_id"`
+	UserID     string        `json:"user_id"` // Slack user who submitted
+	Channel    string        `json:"channel"` // One of the submission.Channel* constants
+	Action     string        `json:"action"`  // ActionCreate or ActionEdit
+	Title      string        `json:"title"`
+	FieldsHash string        `json:"fields_hash"` // See FieldsHash
+	Outcome    string        `json:"outcome"`     // OutcomeSuccess or OutcomeError
+	PageID     string        `json:"page_id,omitempty"`
+	PageURL    string        `json:"page_url,omitempty"`
+	Latency    time.Duration `json:"latency"` // Time the backend took
+	Error      string        `json:"error,omitempty"`
+}
+
+// Idea is an idea a user created, with the title of its latest successful
+// edit, as listed by Store.Ideas.
+type Idea struct {
+	PageID    string
+	PageURL   string
+	Title     string
+	CreatedAt time.Time
+}
+
+// Stats summarizes the attempts in a workspace over a period.
+type Stats struct {
+	Attempts   int           // Create and edit attempts
+	Created    int           // Ideas created
+	Edited     int           // Ideas edited
+	Failed     int           // Attempts the backend rejected or didn't answer
+	Submitters int           // Users who created at least one idea
+	AvgLatency time.Duration // Mean backend latency over all attempts
+}
+
+// Store persists the audit log.
+type Store interface {
+	// Record appends an attempt.
+	Record(attempt Attempt) error
+
+	// Ideas returns up to limit ideas the user created in the workspace,
+	// newest first, and how many they created in total.
+	Ideas(teamID, userID string, limit int) ([]Idea, int, error)
+
+	// Duplicate returns the user's latest successful attempt to create an idea
+	// with the given fields hash at or after since, or false if there is none.
+	Duplicate(teamID, userID, fieldsHash string, since time.Time) (Attempt, bool, error)
+
+	// Stats summarizes the workspace's attempts at or after since.
+	Stats(teamID string, since time.Time) (Stats, error)
+
+	// Prune deletes attempts made before before and returns how many were deleted.
+	Prune(before time.Time) (int, error)
+}
+
+// FieldsHash returns a hash of the fields a user typed or picked: title,
+// theme, product area, comments and customers. Case, surrounding and repeated
+// whitespace, and the order of customers don't change it, so resubmitting
+// the same idea is recognized.
+func FieldsHash(sub submission.Submission) string {
+	customers := make([]string, len(sub.CustomerOrgs))
+	for i, org := range sub.CustomerOrgs {
+		customers[i] = normalize(org)
+	}
+	slices.Sort(customers)
+
+	fields := []string{normalize(sub.Title), normalize(sub.Theme), normalize(sub.ProductArea), normalize(sub.Comments)}
+	sum := sha256.Sum256([]byte(strings.Join(append(fields, customers...), "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalize lowercases a value and collapses its whitespace.
+func normalize(value string) string {
+	return strings.Join(strings.Fields(strings.ToLower(value)), " ")
+}
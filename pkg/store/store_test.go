@@ -0,0 +1,193 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/submission"
+)
+
+func attempt(at time.Time, userID, action, outcome, pageID, title string) Attempt {
+	return Attempt{
+		Time:       at,
+		TeamID:     "T1",
+		UserID:     userID,
+		Channel:    submission.ChannelSlackModal,
+		Action:     action,
+		Title:      title,
+		FieldsHash: "hash-" + title,
+		Outcome:    outcome,
+		PageID:     pageID,
+		PageURL:    "https://www.notion.so/" + pageID,
+		Latency:    time.Second,
+	}
+}
+
+func TestFieldsHash(t *testing.T) {
+	sub := submission.Submission{
+		Title:        "Dark mode",
+		Theme:        "New Feature",
+		ProductArea:  "Dashboard",
+		CustomerOrgs: []string{"Acme", "Globex"},
+	}
+	same := submission.Submission{
+		Title:        "  dark   MODE ",
+		Theme:        "new feature",
+		ProductArea:  "Dashboard",
+		CustomerOrgs: []string{"Globex", "acme"},
+		Source:       submission.Source{SlackUserID: "U2"},
+	}
+	if FieldsHash(sub) != FieldsHash(same) {
+		t.Error("FieldsHash() differs for the same idea typed differently")
+	}
+
+	different := sub
+	different.Comments = "For the admin console"
+	if FieldsHash(sub) == FieldsHash(different) {
+		t.Error("FieldsHash() is the same for different comments")
+	}
+	// Fields don't run into each other
+	if FieldsHash(submission.Submission{Title: "a b", Theme: "c"}) == FieldsHash(submission.Submission{Title: "a", Theme: "b c"}) {
+		t.Error("FieldsHash() is the same for fields split differently")
+	}
+}
+
+// TestFileStore_Persistence tests that attempts survive reopening the store
+// and that a torn last line is skipped
+func TestFileStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	for _, a := range []Attempt{
+		attempt(now, "U1", ActionCreate, OutcomeError, "", "Dark mode"),
+		attempt(now.Add(time.Minute), "U1", ActionCreate, OutcomeSuccess, "page-1", "Dark mode"),
+	} {
+		if err := store.Record(a); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	file.WriteString(`{"time":"2026-05-01T12:0`)
+	file.Close()
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopening store: %v", err)
+	}
+	if reopened.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", reopened.Len())
+	}
+	ideas, total, _ := reopened.Ideas("T1", "U1", 10)
+	if total != 1 || len(ideas) != 1 || ideas[0].PageID != "page-1" || !ideas[0].CreatedAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Ideas() = %+v, %d, want page-1", ideas, total)
+	}
+
+	// The torn line was dropped, so the next attempt starts a new line
+	if err := reopened.Record(attempt(now.Add(2*time.Minute), "U1", ActionCreate, OutcomeSuccess, "page-2", "CSV export")); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if again, err := NewFileStore(path); err != nil || again.Len() != 3 {
+		t.Fatalf("reopening after recording = %v, want 3 attempts", err)
+	}
+
+	// A malformed line before the last is an error
+	os.WriteFile(path, []byte("not json\n{}\n"), 0o600)
+	if _, err := NewFileStore(path); err == nil {
+		t.Error("NewFileStore() of a corrupt log error = nil, want error")
+	}
+}
+
+func TestFileStore_Ideas(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	store, _ := NewFileStore("")
+
+	store.Record(attempt(now, "U1", ActionCreate, OutcomeSuccess, "page-1", "Dark mode"))
+	store.Record(attempt(now.Add(time.Minute), "U1", ActionCreate, OutcomeError, "", "Bulk edit"))
+	store.Record(attempt(now.Add(2*time.Minute), "U2", ActionCreate, OutcomeSuccess, "page-2", "Someone else's"))
+	store.Record(attempt(now.Add(3*time.Minute), "U1", ActionCreate, OutcomeSuccess, "page-3", "CSV export"))
+	store.Record(attempt(now.Add(4*time.Minute), "U1", ActionEdit, OutcomeSuccess, "page-1", "Dark mode everywhere"))
+	store.Record(attempt(now.Add(5*time.Minute), "U1", ActionEdit, OutcomeError, "page-1", "Failed edit"))
+
+	ideas, total, err := store.Ideas("T1", "U1", 10)
+	if err != nil {
+		t.Fatalf("Ideas() error = %v", err)
+	}
+	if total != 2 || len(ideas) != 2 {
+		t.Fatalf("Ideas() = %+v, %d, want 2 ideas", ideas, total)
+	}
+	if ideas[0].PageID != "page-3" || ideas[1].PageID != "page-1" {
+		t.Errorf("Ideas() order = %s, %s, want newest first", ideas[0].PageID, ideas[1].PageID)
+	}
+	if ideas[1].Title != "Dark mode everywhere" || !ideas[1].CreatedAt.Equal(now) {
+		t.Errorf("edited idea = %+v, want the edited title and the creation time", ideas[1])
+	}
+
+	ideas, total, _ = store.Ideas("T1", "U1", 1)
+	if total != 2 || len(ideas) != 1 || ideas[0].PageID != "page-3" {
+		t.Errorf("Ideas() with limit 1 = %+v, %d", ideas, total)
+	}
+	if ideas, total, _ := store.Ideas("T2", "U1", 10); total != 0 || len(ideas) != 0 {
+		t.Errorf("Ideas() in another workspace = %+v, %d, want none", ideas, total)
+	}
+}
+
+func TestFileStore_Duplicate(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	store, _ := NewFileStore("")
+
+	store.Record(attempt(now, "U1", ActionCreate, OutcomeSuccess, "page-1", "Dark mode"))
+	store.Record(attempt(now.Add(time.Minute), "U1", ActionCreate, OutcomeError, "", "Dark mode"))
+
+	got, found, err := store.Duplicate("T1", "U1", "hash-Dark mode", now.Add(-time.Hour))
+	if err != nil || !found || got.PageID != "page-1" {
+		t.Errorf("Duplicate() = %+v, %v, %v, want page-1", got, found, err)
+	}
+	if _, found, _ := store.Duplicate("T1", "U1", "hash-Dark mode", now.Add(time.Second)); found {
+		t.Error("Duplicate() found an attempt older than since")
+	}
+	if _, found, _ := store.Duplicate("T1", "U2", "hash-Dark mode", now.Add(-time.Hour)); found {
+		t.Error("Duplicate() found another user's idea")
+	}
+}
+
+func TestFileStore_StatsAndPrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	store, _ := NewFileStore(path)
+
+	store.Record(attempt(now.Add(-48*time.Hour), "U3", ActionCreate, OutcomeSuccess, "old", "Old"))
+	store.Record(attempt(now, "U1", ActionCreate, OutcomeSuccess, "page-1", "Dark mode"))
+	store.Record(attempt(now, "U2", ActionCreate, OutcomeSuccess, "page-2", "CSV export"))
+	store.Record(attempt(now, "U1", ActionEdit, OutcomeSuccess, "page-1", "Dark mode everywhere"))
+	failed := attempt(now, "U2", ActionCreate, OutcomeError, "", "Bulk edit")
+	failed.Latency = 5 * time.Second
+	store.Record(failed)
+
+	stats, err := store.Stats("T1", now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	want := Stats{Attempts: 4, Created: 2, Edited: 1, Failed: 1, Submitters: 2, AvgLatency: 2 * time.Second}
+	if stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+
+	pruned, err := store.Prune(now.Add(-24 * time.Hour))
+	if err != nil || pruned != 1 {
+		t.Fatalf("Prune() = %d, %v, want 1", pruned, err)
+	}
+	reopened, _ := NewFileStore(path)
+	if reopened.Len() != 4 {
+		t.Errorf("Len() after pruning and reopening = %d, want 4", reopened.Len())
+	}
+	if pruned, _ := store.Prune(now.Add(-24 * time.Hour)); pruned != 0 {
+		t.Errorf("Prune() again = %d, want 0", pruned)
+	}
+}
@@ -0,0 +1,148 @@
+// Package submission defines the typed idea submission shared by frontends and backends.
+//
+// Frontends (the Slack modal) build a Submission from user input and backends
+// (Notion) convert it into their own representation. Using a struct instead of
+// a map of alias keys means adding a field is a compile-time change in every
+// place that has to handle it, rather than a new string key that some backend
+// silently ignores.
+package submission
+
+import (
+	"strings"
+	"time"
+)
+
+// Types of additional property values (Notion property types).
+const (
+	TypeSelect      = "select"
+	TypeMultiSelect = "multi_select"
+	TypeRichText    = "rich_text"
+	TypePeople      = "people"
+	TypeDate        = "date"
+	TypeRelation    = "relation"
+)
+
+// Source channels a submission can come from. Each intake path sets its own,
+// and the Notion backend records it in the optional Source property.
+const (
+	ChannelSlackModal   = "slack_modal"   // The /hopperbot modal
+	ChannelSlackCommand = "slack_command" // A slash command without the modal
+	ChannelAPI          = "api"           // The HTTP API
+	ChannelCSVImport    = "csv_import"    // A bulk CSV import
+	ChannelWeb          = "web"           // A browser form
+
+	// ChannelSlack is the channel of submissions queued before the Slack
+	// channels were split; it is recorded as ChannelSlackModal.
+	ChannelSlack = "slack"
+)
+
+// Submission is a single idea as entered by a user and validated by the frontend.
+//
+// Values are trimmed. Backends still validate them against their own limits,
+// since a Submission can be built outside the Slack handler. Submissions are
+// JSON-encoded when persisted (e.g., by the submission queue).
+type Submission struct {
+	Title       string `json:"title"`        // Required
+	Theme       string `json:"theme"`        // Required, one of the database's theme options
+	ProductArea string `json:"product_area"` // Required, one of the database's product area options
+	Comments    string `json:"comments,omitempty"`
+
+	// CustomerOrgs are the selected customer names, for display and analytics.
+	CustomerOrgs []string `json:"customer_orgs,omitempty"`
+
+	// CustomerOrgIDs are the Notion page IDs of CustomerOrgs, in the same order.
+	CustomerOrgIDs []string `json:"customer_org_ids,omitempty"`
+
+	// Artifacts are linked Slack canvases and huddles.
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+
+	// Attachments are Slack files added to the page body after it is created.
+	Attachments []Attachment `json:"attachments,omitempty"`
+
+	// SubmitterNotionID is the Notion user UUID of the submitter (required by the Notion backend).
+	SubmitterNotionID string `json:"submitter_notion_id"`
+
+	// Extra holds values for additional database properties beyond the fields
+	// above, keyed by property name. The Slack modal generates inputs for them
+	// from the database schema, so adding a column needs no code change.
+	Extra map[string]Value `json:"extra,omitempty"`
+
+	// Source describes where the submission came from.
+	Source Source `json:"source"`
+}
+
+// Artifact is a Slack canvas or huddle linked to a submission.
+type Artifact struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"` // Canvas title from the Slack API; empty if unknown
+}
+
+// Attachment is a file uploaded to Slack in the modal. Only its metadata is
+// kept: the content is downloaded from Slack when it is copied to the page.
+type Attachment struct {
+	SlackFileID string `json:"slack_file_id"`
+	Name        string `json:"name"`
+	MimeType    string `json:"mime_type,omitempty"`
+	Size        int64  `json:"size"`
+}
+
+// Value is the value of an additional property.
+type Value struct {
+	Type string `json:"type"` // One of the Type* constants
+
+	Text   string   `json:"text,omitempty"`   // TypeRichText
+	Values []string `json:"values,omitempty"` // Option names (TypeSelect, TypeMultiSelect), Notion user IDs (TypePeople), or page IDs (TypeRelation)
+	Date   string   `json:"date,omitempty"`   // TypeDate, formatted YYYY-MM-DD
+}
+
+// Source is metadata about where and by whom a submission was made. Only the
+// channel (when the database has a Source property), the Slack permalink
+// (when a source URL property is configured) and SlackSubmitter (when the
+// database has a Submitted by (Slack) property) are written to Notion; the
+// rest is used for logging, analytics, and follow-ups.
+type Source struct {
+	Channel        string    `json:"channel"`                   // One of the Channel* constants
+	SlackUserID    string    `json:"slack_user_id,omitempty"`   // Submitting Slack user
+	SlackTeamID    string    `json:"slack_team_id,omitempty"`   // Slack workspace
+	SubmitterEmail string    `json:"submitter_email,omitempty"` // Email used to map the submitter to Notion
+	SubmitterName  string    `json:"submitter_name,omitempty"`  // Submitter's real name in Slack
+	SlackPermalink string    `json:"slack_permalink,omitempty"` // Slack message the idea was submitted from
+	SubmittedAt    time.Time `json:"submitted_at"`              // When the frontend received the submission
+}
+
+// SlackSubmitter describes the Slack user who submitted the idea, e.g.
+// "Jane Doe <jane@example.com> (Slack U012AB3CD)", so the real submitter is
+// recorded even when the idea is attributed to someone else. Empty when no
+// Slack user submitted it.
+func (s Source) SlackSubmitter() string {
+	if s.SlackUserID == "" {
+		return ""
+	}
+	var parts []string
+	if s.SubmitterName != "" {
+		parts = append(parts, s.SubmitterName)
+	}
+	if s.SubmitterEmail != "" {
+		parts = append(parts, "<"+s.SubmitterEmail+">")
+	}
+	return strings.Join(append(parts, "(Slack "+s.SlackUserID+")"), " ")
+}
+
+// FromSlack reports whether the submission was made in Slack, e.g. to badge
+// other channels in messages posted about it.
+func (s Source) FromSlack() bool {
+	switch s.Channel {
+	case ChannelSlackModal, ChannelSlackCommand, ChannelSlack:
+		return true
+	}
+	return false
+}
+
+// ChannelName returns the channel recorded for the submission, mapping the
+// legacy ChannelSlack to ChannelSlackModal.
+func (s Source) ChannelName() string {
+	if s.Channel == ChannelSlack {
+		return ChannelSlackModal
+	}
+	return s.Channel
+}
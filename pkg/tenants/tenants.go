@@ -0,0 +1,65 @@
+// Package tenants maps Slack workspaces to their own Notion databases.
+//
+// By default every workspace submits to NOTION_DATABASE_ID and validates
+// customers against NOTION_CLIENTS_DB_ID. Organizations running several
+// workspaces against separate PM boards list them in a tenants file (TENANTS_FILE):
+//
+//	[
+//	  {"team_id": "T0123", "name": "EMEA", "notion_database_id": "...", "notion_clients_db_id": "..."},
+//	  {"team_id": "T0456", "notion_database_id": "...", "notion_clients_db_id": "...", "notion_api_key": "secret_..."}
+//	]
+//
+// Workspaces not listed keep using the default databases.
+package tenants
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Tenant is the Notion configuration of one Slack workspace.
+type Tenant struct {
+	TeamID            string `json:"team_id"`
+	Name              string `json:"name,omitempty"` // For logs only
+	NotionDatabaseID  string `json:"notion_database_id"`
+	NotionClientsDBID string `json:"notion_clients_db_id"`
+	NotionAPIKey      string `json:"notion_api_key,omitempty"` // Empty uses NOTION_API_KEY
+}
+
+// LoadFile reads and validates the tenants in a JSON file.
+func LoadFile(path string) ([]Tenant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+
+	var tenants []Tenant
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, fmt.Errorf("failed to parse tenants file %s: %w", path, err)
+	}
+	if err := Validate(tenants); err != nil {
+		return nil, fmt.Errorf("invalid tenants file %s: %w", path, err)
+	}
+	return tenants, nil
+}
+
+// Validate checks that every tenant has a team ID and both database IDs, and
+// that no team is listed twice.
+func Validate(tenants []Tenant) error {
+	seen := make(map[string]bool, len(tenants))
+	for i, tenant := range tenants {
+		switch {
+		case tenant.TeamID == "":
+			return fmt.Errorf("tenant %d: team_id is required", i)
+		case tenant.NotionDatabaseID == "":
+			return fmt.Errorf("tenant %s: notion_database_id is required", tenant.TeamID)
+		case tenant.NotionClientsDBID == "":
+			return fmt.Errorf("tenant %s: notion_clients_db_id is required", tenant.TeamID)
+		case seen[tenant.TeamID]:
+			return fmt.Errorf("tenant %s is listed more than once", tenant.TeamID)
+		}
+		seen[tenant.TeamID] = true
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package tenants
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadFile tests loading tenants from JSON
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	data := `[{"team_id": "T1", "name": "EMEA", "notion_database_id": "db-1", "notion_clients_db_id": "customers-1"},
+	          {"team_id": "T2", "notion_database_id": "db-2", "notion_clients_db_id": "customers-2", "notion_api_key": "secret_2"}]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write tenants file: %v", err)
+	}
+
+	tenants, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if len(tenants) != 2 {
+		t.Fatalf("got %d tenants, want 2", len(tenants))
+	}
+	if tenants[0].Name != "EMEA" || tenants[0].NotionAPIKey != "" || tenants[1].NotionAPIKey != "secret_2" {
+		t.Errorf("tenants = %+v", tenants)
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+// TestValidate tests the required fields and duplicate detection
+func TestValidate(t *testing.T) {
+	valid := Tenant{TeamID: "T1", NotionDatabaseID: "db", NotionClientsDBID: "customers"}
+
+	tests := []struct {
+		name    string
+		tenants []Tenant
+		wantErr string
+	}{
+		{name: "valid", tenants: []Tenant{valid}},
+		{name: "missing team", tenants: []Tenant{{NotionDatabaseID: "db", NotionClientsDBID: "customers"}}, wantErr: "tenant 0: team_id is required"},
+		{name: "missing database", tenants: []Tenant{{TeamID: "T1", NotionClientsDBID: "customers"}}, wantErr: "tenant T1: notion_database_id is required"},
+		{name: "missing customers database", tenants: []Tenant{{TeamID: "T1", NotionDatabaseID: "db"}}, wantErr: "tenant T1: notion_clients_db_id is required"},
+		{name: "duplicate", tenants: []Tenant{valid, valid}, wantErr: "tenant T1 is listed more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.tenants)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Package votes turns emoji reactions on idea cards into votes.
+//
+// A card is a Slack message about a created idea (its confirmation in the
+// confirmation channel). Each card is recorded in a Store with the idea's
+// Notion page; reactions on it then add and remove voters, and the Slack
+// handler writes the number of voters to the page's Votes property.
+//
+// Features:
+// - JSON file-backed store so cards and their voters survive restarts (memory-only when no path is set)
+// - Each user counts once per card, however many vote reactions they add
+// - Reactions are recorded idempotently, so retried Slack events don't double count
+// - Cards posted more than MaxCardAge ago are dropped
+package votes
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/jsonfile"
+)
+
+// MaxCardAge is how long reactions on a card are counted. Older cards are
+// dropped from the store, so the page keeps its last vote count.
+const MaxCardAge = 180 * 24 * time.Hour
+
+// Card is a message about an idea whose reactions count as votes.
+type Card struct {
+	Channel     string    `json:"channel"`
+	TS          string    `json:"ts"`
+	PageID      string    `json:"page_id"`
+	SlackTeamID string    `json:"slack_team_id,omitempty"` // Selects the tenant database to update
+	PostedAt    time.Time `json:"posted_at"`
+
+	// Reactions holds each voter's vote reactions on the card. Users are
+	// removed once their last vote reaction is.
+	Reactions map[string][]string `json:"reactions,omitempty"`
+}
+
+// Votes returns the number of users voting for the idea.
+func (c Card) Votes() int {
+	return len(c.Reactions)
+}
+
+// Store persists cards and their voters.
+type Store interface {
+	// Add records a new card (keyed by channel and message timestamp).
+	Add(card Card) error
+
+	// React records that a user added (or removed) a vote reaction on the
+	// message and returns the updated card. found is false when the message
+	// isn't a card.
+	React(channel, ts, userID, reaction string, added bool) (card Card, found bool, err error)
+}
+
+// FileStore is a Store holding cards by message in a jsonfile.Map. With an
+// empty path the store is memory-only and reactions on cards posted before a
+// restart are ignored.
+type FileStore struct {
+	*jsonfile.Map[string, Card]
+	now func() time.Time
+}
+
+// NewFileStore creates a store backed by path, loading any cards already saved there.
+// A missing file is treated as empty; an empty path creates a memory-only store.
+func NewFileStore(path string) (*FileStore, error) {
+	cards, err := jsonfile.NewMap(path, "votes file",
+		func(card Card) string { return cardKey(card.Channel, card.TS) },
+		func(a, b Card) bool { return a.PostedAt.Before(b.PostedAt) })
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{Map: cards, now: time.Now}, nil
+}
+
+// cardKey identifies a card by its message.
+func cardKey(channel, ts string) string {
+	return channel + "/" + ts
+}
+
+// Add records a new card, dropping cards older than MaxCardAge.
+func (s *FileStore) Add(card Card) error {
+	if card.Channel == "" || card.TS == "" || card.PageID == "" {
+		return fmt.Errorf("card must have a channel, message timestamp and page ID")
+	}
+
+	cutoff := s.now().Add(-MaxCardAge)
+	return s.Update(func(cards map[string]Card) bool {
+		for key, existing := range cards {
+			if existing.PostedAt.Before(cutoff) {
+				delete(cards, key)
+			}
+		}
+		cards[cardKey(card.Channel, card.TS)] = card
+		return true
+	})
+}
+
+// React adds or removes one of a user's vote reactions on a card.
+func (s *FileStore) React(channel, ts, userID, reaction string, added bool) (Card, bool, error) {
+	var card Card
+	found := false
+	cutoff := s.now().Add(-MaxCardAge)
+	err := s.Update(func(cards map[string]Card) bool {
+		key := cardKey(channel, ts)
+		card, found = cards[key]
+		if !found || card.PostedAt.Before(cutoff) {
+			found = false
+			return false
+		}
+
+		// Copy the map so cards returned earlier don't change
+		reactions := make(map[string][]string, len(card.Reactions)+1)
+		for user, names := range card.Reactions {
+			reactions[user] = names
+		}
+		names := slices.DeleteFunc(slices.Clone(reactions[userID]), func(name string) bool { return name == reaction })
+		if added {
+			names = append(names, reaction)
+		}
+		if len(names) > 0 {
+			reactions[userID] = names
+		} else {
+			delete(reactions, userID)
+		}
+		card.Reactions = reactions
+		cards[key] = card
+		return true
+	})
+	if !found {
+		return Card{}, false, nil
+	}
+	return card, true, err
+}
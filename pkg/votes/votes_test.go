@@ -0,0 +1,83 @@
+package votes
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var posted = time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+// TestFileStore_React tests counting voters idempotently and persisting them across reopening the store
+func TestFileStore_React(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "votes.json")
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	store.now = func() time.Time { return posted.Add(time.Hour) }
+	if err := store.Add(Card{Channel: "C1", TS: "1.1", PageID: "page-1", PostedAt: posted}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := store.Add(Card{Channel: "C1"}); err == nil {
+		t.Error("expected error for card without message and page")
+	}
+
+	for _, reaction := range []struct {
+		user, name string
+		added      bool
+	}{
+		{"U1", "+1", true},
+		{"U1", "+1", true}, // Retried event
+		{"U1", "heart", true},
+		{"U2", "+1", true},
+		{"U2", "+1", false},
+		{"U3", "+1", false}, // Added before the card was recorded
+	} {
+		if _, found, err := store.React("C1", "1.1", reaction.user, reaction.name, reaction.added); err != nil || !found {
+			t.Fatalf("React(%+v) = found %v, error %v", reaction, found, err)
+		}
+	}
+	card, _, _ := store.React("C1", "1.1", "U1", "heart", false)
+	if card.Votes() != 1 || len(card.Reactions["U1"]) != 1 {
+		t.Fatalf("card = %+v, want one voter with one reaction", card)
+	}
+
+	if _, found, _ := store.React("C1", "2.2", "U1", "+1", true); found {
+		t.Error("found a card for a message that isn't one")
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopening store: %v", err)
+	}
+	reopened.now = store.now
+	card, found, _ := reopened.React("C1", "1.1", "U2", "+1", true)
+	if !found || card.PageID != "page-1" || card.Votes() != 2 {
+		t.Errorf("reopened card = %+v, want page-1 with 2 votes", card)
+	}
+}
+
+// TestFileStore_MaxCardAge tests that reactions on old cards are ignored and old cards are dropped
+func TestFileStore_MaxCardAge(t *testing.T) {
+	store, err := NewFileStore("")
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	store.now = func() time.Time { return posted.Add(MaxCardAge + time.Hour) }
+
+	if err := store.Add(Card{Channel: "C1", TS: "1.1", PageID: "old", PostedAt: posted}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, found, _ := store.React("C1", "1.1", "U1", "+1", true); found {
+		t.Error("counted a reaction on a card older than MaxCardAge")
+	}
+
+	if err := store.Add(Card{Channel: "C1", TS: "2.2", PageID: "new", PostedAt: store.now()}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if store.Len() != 1 {
+		t.Errorf("store has %d cards, want the old one dropped", store.Len())
+	}
+}
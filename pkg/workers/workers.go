@@ -0,0 +1,151 @@
+// Package workers runs background tasks on a bounded pool of goroutines.
+//
+// Work that outlives a Slack request (creating pages for quick submissions,
+// posting confirmations and DMs, publishing App Home views), the jobs of
+// pkg/queue and the status polls of pkg/statuswatch are submitted to a Pool instead of each spawning a
+// goroutine, so a burst of submissions or a slow Slack or Notion API can't
+// pile up an unbounded number of them.
+//
+// Features:
+// - A fixed number of workers taking tasks from a bounded queue, oldest first
+// - Submit waits for room while the queue is full, up to the caller's context
+// - A panicking task is logged and counted without taking down its worker
+// - Graceful shutdown runs the tasks already queued before returning
+// - Metrics for queue depth, time waiting for a worker, and task duration and outcome, by pool
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/hopperbot/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// ErrStopped is returned by Submit once the pool has been stopped.
+var ErrStopped = errors.New("worker pool stopped")
+
+// Task is a unit of background work. Tasks that need a context capture their
+// own, usually one that outlives the request that submitted them.
+type Task func()
+
+// task is a submitted Task with what's needed to report on it.
+type task struct {
+	name     string
+	run      Task
+	queuedAt time.Time
+}
+
+// Pool runs submitted tasks on a fixed number of workers.
+type Pool struct {
+	name    string
+	tasks   chan task
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+	mu      sync.RWMutex // Held for reading while submitting, for writing while stopping
+	stopped bool
+	wg      sync.WaitGroup
+	now     func() time.Time // Overridable for tests
+}
+
+// New starts a pool of size workers with room for queueSize tasks waiting for
+// one. name labels the pool's metrics and logs. metrics may be nil to disable
+// metrics recording. Sizes below 1 are treated as 1.
+func New(name string, size, queueSize int, m *metrics.Metrics, logger *zap.Logger) *Pool {
+	p := &Pool{
+		name:    name,
+		tasks:   make(chan task, max(queueSize, 1)),
+		metrics: m,
+		logger:  logger.With(zap.String("pool", name)),
+		now:     time.Now,
+	}
+
+	for range max(size, 1) {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues a task, named for logs, to run on the next free worker. While
+// the queue is full it waits for room until ctx is done, returning ctx's error.
+// It returns ErrStopped once the pool is stopped.
+func (p *Pool) Submit(ctx context.Context, name string, run Task) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.stopped {
+		p.record("rejected")
+		return ErrStopped
+	}
+
+	select {
+	case p.tasks <- task{name: name, run: run, queuedAt: p.now()}:
+		p.recordDepth()
+		return nil
+	case <-ctx.Done():
+		p.logger.Warn("background task rejected, worker pool is full", zap.String("task", name), zap.Error(ctx.Err()))
+		p.record("rejected")
+		return ctx.Err()
+	}
+}
+
+// Stop stops accepting tasks and waits for the queued and running ones to
+// finish.
+func (p *Pool) Stop() {
+	p.mu.Lock()
+	if !p.stopped {
+		p.stopped = true
+		close(p.tasks)
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+// work runs queued tasks until the pool is stopped and the queue drained.
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for t := range p.tasks {
+		p.recordDepth()
+		p.run(t)
+	}
+}
+
+// run runs one task, recovering and logging a panic.
+func (p *Pool) run(t task) {
+	start := p.now()
+	if p.metrics != nil {
+		p.metrics.WorkerTaskWait.WithLabelValues(p.name).Observe(start.Sub(t.queuedAt).Seconds())
+	}
+
+	defer func() {
+		if p.metrics != nil {
+			p.metrics.WorkerTaskDuration.WithLabelValues(p.name).Observe(p.now().Sub(start).Seconds())
+		}
+		if r := recover(); r != nil {
+			p.logger.Error("background task panicked", zap.String("task", t.name), zap.Any("panic", r), zap.Stack("stack"))
+			p.record("panicked")
+			return
+		}
+		p.record("completed")
+	}()
+
+	t.run()
+}
+
+func (p *Pool) record(status string) {
+	if p.metrics == nil {
+		return
+	}
+	p.metrics.WorkerTasksTotal.WithLabelValues(p.name, status).Inc()
+}
+
+func (p *Pool) recordDepth() {
+	if p.metrics == nil {
+		return
+	}
+	p.metrics.WorkerQueueDepth.WithLabelValues(p.name).Set(float64(len(p.tasks)))
+}
@@ -0,0 +1,109 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TestPool_Bounded tests that no more tasks run at once than there are workers
+func TestPool_Bounded(t *testing.T) {
+	pool := New("test", 2, 10, nil, zap.NewNop())
+
+	var running, peak atomic.Int32
+	for range 6 {
+		err := pool.Submit(context.Background(), "task", func() {
+			n := running.Add(1)
+			for {
+				old := peak.Load()
+				if n <= old || peak.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+		})
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+	pool.Stop()
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak concurrent tasks = %d, want 2", got)
+	}
+}
+
+// TestPool_StopDrains tests that Stop runs the queued tasks and then rejects new ones
+func TestPool_StopDrains(t *testing.T) {
+	pool := New("test", 1, 10, nil, zap.NewNop())
+
+	var done atomic.Int32
+	for range 5 {
+		if err := pool.Submit(context.Background(), "task", func() { done.Add(1) }); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+	pool.Stop()
+
+	if got := done.Load(); got != 5 {
+		t.Errorf("tasks run = %d, want all 5 queued before Stop", got)
+	}
+	if err := pool.Submit(context.Background(), "late", func() {}); !errors.Is(err, ErrStopped) {
+		t.Errorf("Submit() after Stop error = %v, want ErrStopped", err)
+	}
+	pool.Stop() // Stopping twice is harmless
+}
+
+// TestPool_SubmitWaitsWhileFull tests that Submit waits for room and gives up when its context is done
+func TestPool_SubmitWaitsWhileFull(t *testing.T) {
+	pool := New("test", 1, 1, nil, zap.NewNop())
+	defer pool.Stop()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(context.Background(), "blocker", func() {
+		close(started)
+		<-release
+	})
+	<-started
+	pool.Submit(context.Background(), "queued", func() {}) // Fills the queue
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Submit(ctx, "rejected", func() {}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Submit() to a full pool error = %v, want the context's error", err)
+	}
+
+	submitted := make(chan error, 1)
+	go func() {
+		submitted <- pool.Submit(context.Background(), "waiting", func() {})
+	}()
+	close(release)
+	select {
+	case err := <-submitted:
+		if err != nil {
+			t.Errorf("Submit() once room was made error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Submit() did not return once the queue had room")
+	}
+}
+
+// TestPool_Panic tests that a panicking task doesn't stop its worker
+func TestPool_Panic(t *testing.T) {
+	pool := New("test", 1, 10, nil, zap.NewNop())
+
+	var done atomic.Bool
+	pool.Submit(context.Background(), "panics", func() { panic("boom") })
+	pool.Submit(context.Background(), "after", func() { done.Store(true) })
+	pool.Stop()
+
+	if !done.Load() {
+		t.Error("task after a panic did not run")
+	}
+}